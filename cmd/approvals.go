@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/approval"
+	"github.com/spf13/cobra"
+)
+
+var (
+	approvalsAll     bool
+	approvalsJSON    bool
+	approvalsComment string
+	approvalsKey     string
+)
+
+// approvalsCmd groups the approval commands.
+var approvalsCmd = &cobra.Command{
+	Use:   "approvals",
+	Short: "Review and approve held command runs",
+	Long: `Commands for the two-person rule.
+
+Runs of commands tagged requires_second_approval are held until two distinct
+operators approve them. Each operator signs their decisions with their own
+ed25519 key, created with "approvals keygen" and kept in their account, and
+lists its public key in approvals.operators; decisions without a valid
+signature are not counted. The approvals file (approvals.file) must be shared
+with the server.`,
+}
+
+// approvalsListCmd lists approval requests.
+var approvalsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List approval requests",
+	Long: `List pending approval requests, newest first. Use --all to include
+approved, rejected, expired and consumed requests.
+
+Example:
+  simple-mcp-runner approvals list --config config.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := loadApprovalStore()
+		if err != nil {
+			return err
+		}
+
+		requests, err := store.List()
+		if err != nil {
+			return err
+		}
+		if !approvalsAll {
+			pending := requests[:0]
+			for _, req := range requests {
+				if req.Status == approval.StatusPending {
+					pending = append(pending, req)
+				}
+			}
+			requests = pending
+		}
+
+		if approvalsJSON {
+			return printJSON(requests)
+		}
+
+		if len(requests) == 0 {
+			fmt.Printf("No approval requests in %s\n", store.Path())
+			return nil
+		}
+		for _, req := range requests {
+			fmt.Printf("%-14s %-9s %d/%d  %-20s %s  requested by %s\n",
+				req.ID, req.Status, len(req.ApprovedBy), approval.Required,
+				req.Command, req.Created.Format("2006-01-02 15:04"), requester(req))
+		}
+		return nil
+	},
+}
+
+// approvalsShowCmd prints a request and its audit trail.
+var approvalsShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show an approval request and its audit trail",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := loadApprovalStore()
+		if err != nil {
+			return err
+		}
+
+		req, err := store.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		if approvalsJSON {
+			return printJSON(req)
+		}
+		printApprovalRequest(req)
+		return nil
+	},
+}
+
+// approvalsKeygenCmd creates an operator key.
+var approvalsKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate an operator key for signing decisions",
+	Long: `Write a new ed25519 private key for signing approval decisions, by default
+to operator.pem under the user config directory (approvals.key_file), and
+print its public key, which goes into approvals.operators of the server's
+configuration.
+
+Example:
+  simple-mcp-runner approvals keygen`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadPolicyConfig()
+		if err != nil {
+			return err
+		}
+		path := approvalsKey
+		if path == "" {
+			path = approval.KeyFile(cfg)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return fmt.Errorf("failed to create key directory: %w", err)
+		}
+		public, err := writeSigningKey(path)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Wrote operator key to %s\n\nSave the public key to a file and list it in approvals.operators:\n%s", path, public)
+		return nil
+	},
+}
+
+// approvalsApproveCmd approves a request as the current user.
+var approvalsApproveCmd = &cobra.Command{
+	Use:   "approve <id>",
+	Short: "Approve a held command run",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return decideApproval(args[0], true)
+	},
+}
+
+// approvalsRejectCmd rejects a request as the current user.
+var approvalsRejectCmd = &cobra.Command{
+	Use:   "reject <id>",
+	Short: "Reject a held command run",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return decideApproval(args[0], false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(approvalsCmd)
+	approvalsCmd.AddCommand(approvalsListCmd, approvalsShowCmd, approvalsApproveCmd, approvalsRejectCmd, approvalsKeygenCmd)
+
+	approvalsListCmd.Flags().BoolVar(&approvalsAll, "all", false, "include requests that are no longer pending")
+	approvalsListCmd.Flags().BoolVar(&approvalsJSON, "json", false, "print the requests as JSON")
+	approvalsShowCmd.Flags().BoolVar(&approvalsJSON, "json", false, "print the request as JSON")
+	approvalsApproveCmd.Flags().StringVar(&approvalsComment, "comment", "", "comment recorded with the approval")
+	approvalsRejectCmd.Flags().StringVar(&approvalsComment, "comment", "", "comment recorded with the rejection")
+	for _, c := range []*cobra.Command{approvalsApproveCmd, approvalsRejectCmd} {
+		c.Flags().StringVar(&approvalsKey, "key", "", "operator key to sign the decision with (default approvals.key_file)")
+	}
+	approvalsKeygenCmd.Flags().StringVar(&approvalsKey, "out", "", "file to write the key to (default approvals.key_file)")
+}
+
+// loadApprovalStore opens the approvals file of the configuration.
+func loadApprovalStore() (*approval.Store, error) {
+	cfg, err := loadPolicyConfig()
+	if err != nil {
+		return nil, err
+	}
+	return approval.New(cfg), nil
+}
+
+// decideApproval records a decision on a request signed with the current
+// user's operator key.
+func decideApproval(id string, approve bool) error {
+	cfg, err := loadPolicyConfig()
+	if err != nil {
+		return err
+	}
+	if approvalsKey != "" {
+		cfg.Approvals.KeyFile = approvalsKey
+	}
+	key, err := approval.LoadKey(cfg)
+	if err != nil {
+		return err
+	}
+	store := approval.New(cfg)
+
+	decide := store.Reject
+	if approve {
+		decide = store.Approve
+	}
+	req, err := decide(id, key, approvalsComment)
+	if err != nil {
+		return err
+	}
+	operator := req.Events[len(req.Events)-1].By
+
+	switch req.Status {
+	case approval.StatusApproved:
+		fmt.Printf("Request %s approved by %s; the command can now run once with approval_id %s\n",
+			id, strings.Join(req.ApprovedBy, " and "), id)
+	case approval.StatusRejected:
+		fmt.Printf("Request %s rejected by %s\n", id, operator)
+	default:
+		fmt.Printf("Request %s approved by %s (%d of %d approvals); another operator must approve\n",
+			id, operator, len(req.ApprovedBy), approval.Required)
+	}
+	return nil
+}
+
+// printApprovalRequest prints a request for operators.
+func printApprovalRequest(req *approval.Request) {
+	fmt.Printf("Request:   %s\n", req.ID)
+	fmt.Printf("Status:    %s (%d of %d approvals)\n", req.Status, len(req.ApprovedBy), approval.Required)
+	fmt.Printf("Command:   %s %s\n", req.Command, strings.Join(req.Args, " "))
+	if req.WorkDir != "" {
+		fmt.Printf("Workdir:   %s\n", req.WorkDir)
+	}
+	fmt.Printf("Requested: %s by %s\n", req.Created.Format("2006-01-02 15:04:05"), requester(req))
+	fmt.Printf("Expires:   %s\n", req.Expires.Format("2006-01-02 15:04:05"))
+
+	fmt.Printf("\nAudit trail:\n")
+	for _, e := range req.Events {
+		line := fmt.Sprintf("  %s  %-9s", e.Time.Format("2006-01-02 15:04:05"), e.Type)
+		if e.By != "" {
+			line += " by " + e.By
+		}
+		if e.Client != "" {
+			line += " via " + e.Client
+		}
+		if e.ExitCode != nil {
+			line += fmt.Sprintf(" exit code %d", *e.ExitCode)
+		}
+		if e.Error != "" {
+			line += ": " + e.Error
+		}
+		if e.Comment != "" {
+			line += fmt.Sprintf(" (%s)", e.Comment)
+		}
+		fmt.Println(line)
+	}
+}
+
+// requester describes who requested a run.
+func requester(req *approval.Request) string {
+	who := req.RequestedBy
+	if who == "" {
+		who = "unknown"
+	}
+	if req.Client != "" {
+		who += " via " + req.Client
+	}
+	return who
+}
+
+// printJSON prints a value as indented JSON.
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
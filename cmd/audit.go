@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/audit"
+	"github.com/mjmorales/simple-mcp-runner/internal/history"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditFile       string
+	auditOutput     string
+	auditFormat     string
+	auditSince      string
+	auditCommand    string
+	auditSession    string
+	auditExitStatus string
+	auditDecision   string
+)
+
+// auditCmd groups the audit commands.
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Export the execution history for review",
+	Long: `Commands for reviewing what the server ran.
+
+Exports read the history file (history.path), so history must be persisted
+to a file for runs to be exported.`,
+}
+
+// auditExportCmd exports execution records.
+var auditExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export execution history as JSON, CSV or SARIF",
+	Long: `Export execution records, oldest first, for compliance review or ingestion
+by security tooling. SARIF reports policy denials as errors, failed runs as
+warnings and successful runs as notes.
+
+Records can be filtered by age, command, MCP session, exit status (success,
+failure, or an exit code) and policy decision (allowed or denied).
+
+Example:
+  simple-mcp-runner audit export --since 24h --format sarif > runs.sarif
+  simple-mcp-runner audit export --decision denied --format csv
+  simple-mcp-runner audit export --command git --exit-status failure`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := auditFile
+		if path == "" {
+			cfg, err := loadPolicyConfig()
+			if err != nil {
+				return err
+			}
+			path = cfg.History.Path
+		}
+		if path == "" {
+			return fmt.Errorf("history is only kept in memory; set history.path or pass --file")
+		}
+
+		since, err := audit.ParseSince(auditSince, time.Now())
+		if err != nil {
+			return err
+		}
+
+		records, err := history.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		records, err = audit.Select(records, audit.Filter{
+			Since:      since,
+			Command:    auditCommand,
+			Session:    auditSession,
+			ExitStatus: auditExitStatus,
+			Decision:   auditDecision,
+		})
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = os.Stdout
+		if auditOutput != "" {
+			f, err := os.OpenFile(auditOutput, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if err := audit.Write(out, auditFormat, records, Version); err != nil {
+			return err
+		}
+		if auditOutput != "" {
+			fmt.Fprintf(os.Stderr, "Exported %d records to %s\n", len(records), auditOutput)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditExportCmd)
+
+	auditExportCmd.Flags().StringVar(&auditFile, "file", "", "history file (default is history.path)")
+	auditExportCmd.Flags().StringVarP(&auditOutput, "output", "o", "", "file to write the export to (default is standard output)")
+	auditExportCmd.Flags().StringVar(&auditFormat, "format", audit.FormatJSON, "export format: json, csv, sarif")
+	auditExportCmd.Flags().StringVar(&auditSince, "since", "", "only records newer than a duration such as 24h, or an RFC 3339 time")
+	auditExportCmd.Flags().StringVar(&auditCommand, "command", "", "only runs of this command or configured command")
+	auditExportCmd.Flags().StringVar(&auditSession, "session", "", "only runs from this MCP session")
+	auditExportCmd.Flags().StringVar(&auditExitStatus, "exit-status", "", "only runs with this exit status: success, failure, or an exit code")
+	auditExportCmd.Flags().StringVar(&auditDecision, "decision", "", "only runs with this policy decision: allowed, denied")
+}
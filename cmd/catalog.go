@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/catalog"
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/internal/receipt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	catalogKey string
+	catalogOut string
+)
+
+// catalogCmd groups the remote command catalog commands.
+var catalogCmd = &cobra.Command{
+	Use:   "catalog",
+	Short: "Sign and check remote command catalogs",
+	Long: `Commands for remote command catalogs.
+
+An organization publishes a YAML document with a commands list, signed with an
+ed25519 key, at an https URL. Servers with catalog.url set add its commands to
+their own at startup and every catalog.refresh, once the signature is verified
+with catalog.public_key.`,
+}
+
+// catalogSignCmd signs a catalog for publishing.
+var catalogSignCmd = &cobra.Command{
+	Use:   "sign <file>",
+	Short: "Sign a command catalog",
+	Long: `Write the signature of a catalog file, to publish next to it. Keys are created
+with "receipts keygen".
+
+Example:
+  simple-mcp-runner catalog sign catalog.yaml --key catalog.pem`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if catalogKey == "" {
+			return fmt.Errorf("--key is required")
+		}
+		signer, err := receipt.LoadSigner(catalogKey)
+		if err != nil {
+			return err
+		}
+
+		body, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read catalog: %w", err)
+		}
+		cat, err := catalog.Parse(body)
+		if err != nil {
+			return err
+		}
+
+		out := catalogOut
+		if out == "" {
+			out = args[0] + ".sig"
+		}
+		if err := os.WriteFile(out, catalog.Sign(body, signer), 0o644); err != nil {
+			return fmt.Errorf("failed to write signature: %w", err)
+		}
+		fmt.Printf("Signed %d commands, signature written to %s\n", len(cat.Commands), out)
+		return nil
+	},
+}
+
+// catalogFetchCmd fetches the configured catalog.
+var catalogFetchCmd = &cobra.Command{
+	Use:   "fetch",
+	Short: "Fetch and verify the configured command catalog",
+	Long: `Fetch the catalog configured by catalog.url, verify its signature and list the
+commands it adds. The verified catalog is cached like the server does.
+
+Example:
+  simple-mcp-runner catalog fetch --config config.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadPolicyConfig()
+		if err != nil {
+			return err
+		}
+		if cfg.Catalog.URL == "" {
+			return fmt.Errorf("no catalog.url is configured")
+		}
+
+		fetcher, err := catalog.New(cfg, logger.Default())
+		if err != nil {
+			return err
+		}
+		cat, err := fetcher.Fetch(context.Background())
+		if err != nil {
+			return err
+		}
+		merged, shadowed, err := catalog.Merge(cfg, cfg.Commands, cat)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Catalog %s is valid: %d commands\n", cfg.Catalog.URL, len(cat.Commands))
+		added := merged[len(cfg.Commands):]
+		if len(added) > 0 {
+			fmt.Printf("\nAdded commands:\n")
+			for _, c := range added {
+				fmt.Printf("  - %s: %s\n", c.Name, c.Description)
+			}
+		}
+		if len(shadowed) > 0 {
+			fmt.Printf("\nShadowed by configured commands:\n")
+			for _, name := range shadowed {
+				fmt.Printf("  - %s\n", name)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(catalogCmd)
+	catalogCmd.AddCommand(catalogSignCmd, catalogFetchCmd)
+
+	catalogSignCmd.Flags().StringVar(&catalogKey, "key", "", "PEM encoded ed25519 private key")
+	catalogSignCmd.Flags().StringVarP(&catalogOut, "out", "o", "", "signature file (default <file>.sig)")
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/control"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configDiffAll      bool
+	configDiffJSON     bool
+	configDiffExitCode bool
+)
+
+// errConfigDiffers is returned with --exit-code when there are changes,
+// exiting with status 1 like git diff --exit-code.
+var errConfigDiffers = errors.New("configurations differ")
+
+// configCmd groups the configuration commands.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Compare configurations",
+	Long: `Commands for comparing configurations, to review what an edit changes
+before deploying it and to find servers running with an outdated one.`,
+}
+
+// configDiffCmd compares the configuration to another file.
+var configDiffCmd = &cobra.Command{
+	Use:   "diff <other.yaml>",
+	Short: "Show the settings another configuration file changes",
+	Long: `Show the settings that differ from the configuration (--config, the default
+configuration file, or the defaults when there is none) to another file.
+Settings are compared as they apply, after defaults and command_defaults, and
+entries of named lists such as commands are matched by name.
+
+Only security-relevant settings are shown unless --all is given: the security
+policy, commands, script tools, plugins, the catalog, execution limits, the
+control API and other settings deciding what clients can run and reach.
+
+Example:
+  simple-mcp-runner config diff --config config.yaml config.new.yaml
+  simple-mcp-runner config diff config.new.yaml --all --json
+  simple-mcp-runner config diff config.new.yaml --exit-code`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		base, err := loadPolicyConfig()
+		if err != nil {
+			return err
+		}
+		other, err := config.LoadFromFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", args[0], err)
+		}
+		changes, err := config.Diff(base, other)
+		if err != nil {
+			return err
+		}
+		changes = filterChanges(changes)
+
+		if configDiffJSON {
+			if err := printJSON(changes); err != nil {
+				return err
+			}
+		} else {
+			printChanges(changes, fmt.Sprintf("from %s to %s", baseConfigName(), args[0]))
+		}
+		if configDiffExitCode && len(changes) > 0 {
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			return errConfigDiffers
+		}
+		return nil
+	},
+}
+
+// configDriftCmd asks a running server how its configuration differs from
+// its file.
+var configDriftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Show how a running server's configuration differs from its file",
+	Long: `Ask a running server how the configuration it runs with differs from its
+configuration file on disk, e.g. after the file was edited, or when an edit
+no longer validates. The server must serve the control API (control.addr).
+Commands added by the catalog are left out; settings overridden on the command
+line show as drift.
+
+Example:
+  simple-mcp-runner config drift --config config.yaml
+  simple-mcp-runner config drift --all --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadPolicyConfig()
+		if err != nil {
+			return err
+		}
+		drift, err := fetchDrift(cfg)
+		if err != nil {
+			return err
+		}
+		drift.Changes = filterChanges(drift.Changes)
+
+		if configDiffJSON {
+			if err := printJSON(drift); err != nil {
+				return err
+			}
+		} else if drift.Error != "" {
+			fmt.Printf("%s cannot be loaded; the server keeps its configuration:\n  %s\n", drift.File, drift.Error)
+		} else {
+			printChanges(drift.Changes, "from the running configuration to "+drift.File)
+		}
+		if configDiffExitCode && (drift.Error != "" || len(drift.Changes) > 0) {
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			return errConfigDiffers
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configDiffCmd)
+	configCmd.AddCommand(configDriftCmd)
+
+	for _, c := range []*cobra.Command{configDiffCmd, configDriftCmd} {
+		c.Flags().BoolVar(&configDiffAll, "all", false, "include settings that are not security-relevant")
+		c.Flags().BoolVar(&configDiffJSON, "json", false, "print the changes as JSON")
+		c.Flags().BoolVar(&configDiffExitCode, "exit-code", false, "exit with an error when there are changes")
+	}
+}
+
+// fetchDrift asks the control API of the running server for its drift.
+func fetchDrift(cfg *config.Config) (*control.Drift, error) {
+	if cfg.Control.Addr == "" {
+		return nil, fmt.Errorf("control.addr is not set, so the server does not serve the control API")
+	}
+	token, err := os.ReadFile(control.TokenFile(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the control token (is the server running?): %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+cfg.Control.Addr+"/v1/config/drift", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	resp, err := (&http.Client{Timeout: 10 * time.Second}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the server: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the drift: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var drift control.Drift
+	if err := json.Unmarshal(body, &drift); err != nil {
+		return nil, fmt.Errorf("failed to parse the drift: %w", err)
+	}
+	return &drift, nil
+}
+
+// filterChanges keeps the security-relevant changes unless --all is given.
+func filterChanges(changes []config.Change) []config.Change {
+	if configDiffAll {
+		return changes
+	}
+	var kept []config.Change
+	for _, c := range changes {
+		if c.Security {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// baseConfigName names the configuration loadPolicyConfig loads.
+func baseConfigName() string {
+	if configFile != "" {
+		return configFile
+	}
+	if path := GetDefaultConfigPath(); path != "" && fileExists(path) {
+		return path
+	}
+	return "the defaults"
+}
+
+// printChanges prints changes one per line, security-relevant ones marked
+// with !.
+func printChanges(changes []config.Change, what string) {
+	if len(changes) == 0 {
+		fmt.Printf("No changes %s\n", what)
+		return
+	}
+	fmt.Printf("Changes %s:\n", what)
+	for _, c := range changes {
+		mark := " "
+		if c.Security {
+			mark = "!"
+		}
+		switch {
+		case c.Added != nil || c.Removed != nil:
+			var items []string
+			for _, v := range c.Added {
+				items = append(items, "+"+formatValue(v))
+			}
+			for _, v := range c.Removed {
+				items = append(items, "-"+formatValue(v))
+			}
+			fmt.Printf("%s %-8s %s: %s\n", mark, c.Kind, c.Path, strings.Join(items, " "))
+		case c.Kind == config.ChangeAdded:
+			fmt.Printf("%s %-8s %s: %s\n", mark, c.Kind, c.Path, formatValue(c.New))
+		case c.Kind == config.ChangeRemoved:
+			fmt.Printf("%s %-8s %s: %s\n", mark, c.Kind, c.Path, formatValue(c.Old))
+		default:
+			fmt.Printf("%s %-8s %s: %s -> %s\n", mark, c.Kind, c.Path, formatValue(c.Old), formatValue(c.New))
+		}
+	}
+}
+
+// formatValue formats a setting on one line.
+func formatValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/control"
+	"github.com/spf13/cobra"
+)
+
+var dashboardOpen bool
+
+// dashboardCmd prints the address of the web dashboard.
+var dashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "Open the web dashboard of a running server",
+	Long: `Print the address of the web dashboard of a running server, with the
+control token in its fragment so the browser can sign in. The dashboard is
+served by the control API, so control.addr must be set and the server
+running; it shows running commands, history, policy denials, pending
+approvals and the configuration summary, and can kill commands, approve or
+reject requests, flip the operator switches and drain the server.
+
+Approvals made from the dashboard are recorded as the user running the
+server, who counts as one of the two operators.
+
+Example:
+  simple-mcp-runner dashboard --config config.yaml
+  simple-mcp-runner dashboard --open`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadPolicyConfig()
+		if err != nil {
+			return err
+		}
+		if cfg.Control.Addr == "" {
+			return fmt.Errorf("control.addr is not set, so the server does not serve the dashboard")
+		}
+
+		tokenFile := control.TokenFile(cfg)
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the control token (is the server running?): %w", err)
+		}
+		url := fmt.Sprintf("http://%s/#token=%s", cfg.Control.Addr, strings.TrimSpace(string(data)))
+
+		if dashboardOpen {
+			return openBrowser(url)
+		}
+		fmt.Println(url)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dashboardCmd)
+
+	dashboardCmd.Flags().BoolVar(&dashboardOpen, "open", false, "open the dashboard in the default browser")
+}
+
+// openBrowser opens url in the default browser.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open the browser: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/policy"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	suggestionsFile string
+	suggestJSON     bool
+)
+
+// policyCmd groups the security policy commands.
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Inspect the security policy",
+	Long: `Commands for working with the security policy.
+
+With security.policy set to learn, the server records every command it denies
+instead of only rejecting it. Use these commands to review what was requested
+and tighten the policy progressively.`,
+}
+
+// policySuggestCmd summarizes commands denied in learn mode.
+var policySuggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Suggest policy changes from denied commands",
+	Long: `Summarize the commands denied while security.policy was set to learn, and
+what the configuration would need to allow to support them. Requests the
+current configuration already allows are left out.
+
+Example:
+  simple-mcp-runner policy suggest --config config.yaml
+  simple-mcp-runner policy suggest --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadPolicyConfig()
+		if err != nil {
+			return err
+		}
+
+		path := suggestionsFile
+		if path == "" {
+			path = policy.SuggestionsFile(cfg)
+		}
+
+		denials, err := policy.Load(path)
+		if err != nil {
+			return err
+		}
+		summary := policy.Summarize(denials, cfg)
+
+		if suggestJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(summary)
+		}
+
+		printSuggestions(path, summary)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.AddCommand(policySuggestCmd)
+
+	policySuggestCmd.Flags().StringVar(&suggestionsFile, "file", "", "suggestions file (default is security.suggestions_file)")
+	policySuggestCmd.Flags().BoolVar(&suggestJSON, "json", false, "print the summary as JSON")
+}
+
+// loadPolicyConfig loads the configuration the suggestions are compared
+// against, falling back to the defaults.
+func loadPolicyConfig() (*config.Config, error) {
+	cfgFile := configFile
+	if cfgFile == "" {
+		cfgFile = GetDefaultConfigPath()
+		if cfgFile == "" || !fileExists(cfgFile) {
+			return config.Default(), nil
+		}
+	}
+
+	cfg, err := config.LoadFromFile(cfgFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
+
+// printSuggestions prints a summary for operators.
+func printSuggestions(path string, summary *policy.Summary) {
+	fmt.Printf("Denied requests in %s: %d\n", path, summary.Total)
+	if summary.Total == 0 {
+		fmt.Println("\nNothing to suggest: the current configuration allows every recorded request.")
+		return
+	}
+
+	var allow, unblock []string
+	if len(summary.Commands) > 0 {
+		fmt.Printf("\nCommands:\n")
+		for _, s := range summary.Commands {
+			note := "not in allowed_commands"
+			if s.Blocked {
+				note = "in blocked_commands"
+				unblock = append(unblock, s.Command)
+			} else {
+				allow = append(allow, s.Command)
+			}
+			fmt.Printf("  %-20s %4d requests, last %s (%s)\n", s.Command, s.Count, s.LastSeen.Format("2006-01-02 15:04"), note)
+			for _, example := range s.Examples {
+				fmt.Printf("      e.g. %s %s\n", s.Command, example)
+			}
+		}
+	}
+
+	if len(summary.Paths) > 0 {
+		fmt.Printf("\nWorking directories outside allowed_paths:\n")
+		for _, s := range summary.Paths {
+			fmt.Printf("  %-40s %4d requests, last %s\n", s.Path, s.Count, s.LastSeen.Format("2006-01-02 15:04"))
+		}
+	}
+
+	fmt.Printf("\nTo allow these requests:\n\nsecurity:\n")
+	if len(allow) > 0 {
+		fmt.Printf("  allowed_commands:  # add, if allowed_commands is set\n")
+		for _, command := range allow {
+			fmt.Printf("    - %s\n", command)
+		}
+	}
+	if len(unblock) > 0 {
+		fmt.Printf("  # remove from blocked_commands: %v\n", unblock)
+	}
+	if len(summary.Paths) > 0 {
+		fmt.Printf("  allowed_paths:  # add\n")
+		for _, s := range summary.Paths {
+			fmt.Printf("    - %s\n", s.Path)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/receipt"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	receiptKeyOut    string
+	receiptPublicKey string
+)
+
+// receiptsCmd groups the execution receipt commands.
+var receiptsCmd = &cobra.Command{
+	Use:   "receipts",
+	Short: "Create signing keys and verify execution receipts",
+	Long: `Commands for signed execution receipts.
+
+With history.signing_key set, every execution record is signed with an ed25519
+key. The receipt is included in the result and the history, so systems
+downstream of an agent can verify the command, arguments and output it reports.`,
+}
+
+// receiptsKeygenCmd writes a new signing key.
+var receiptsKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate an ed25519 signing key",
+	Long: `Write a new PEM encoded ed25519 private key for history.signing_key and
+print its public key, which verifiers need.
+
+Example:
+  simple-mcp-runner receipts keygen --out ~/.config/simple-mcp-runner/receipts.pem`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if receiptKeyOut == "" {
+			return fmt.Errorf("--out is required")
+		}
+
+		public, err := writeSigningKey(receiptKeyOut)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Wrote signing key to %s\n\nPublic key for verifiers:\n%s", receiptKeyOut, public)
+		return nil
+	},
+}
+
+// writeSigningKey writes a new ed25519 private key to a file that must not
+// exist yet and returns its public key.
+func writeSigningKey(path string) ([]byte, error) {
+	private, public, err := receipt.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key file: %w", err)
+	}
+	if _, err := f.Write(private); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write key file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write key file: %w", err)
+	}
+	return public, nil
+}
+
+// receiptsVerifyCmd verifies a receipt.
+var receiptsVerifyCmd = &cobra.Command{
+	Use:   "verify [file]",
+	Short: "Verify an execution receipt",
+	Long: `Verify the signature of a receipt, read from a file or standard input. The
+input can be a receipt, a command result or a history record; for results and
+records the output and exit code are also checked against the receipt.
+
+Example:
+  simple-mcp-runner receipts verify result.json --public-key receipts.pub`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if receiptPublicKey == "" {
+			return fmt.Errorf("--public-key is required")
+		}
+		pub, err := receipt.LoadPublicKey(receiptPublicKey)
+		if err != nil {
+			return err
+		}
+
+		var data []byte
+		if len(args) == 1 {
+			data, err = os.ReadFile(args[0])
+		} else {
+			data, err = io.ReadAll(os.Stdin)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+
+		rcpt, result, err := parseReceiptInput(data)
+		if err != nil {
+			return err
+		}
+
+		payload, err := receipt.Verify(rcpt, pub)
+		if err != nil {
+			return err
+		}
+
+		if result != nil {
+			switch {
+			case receipt.Hash(result.Stdout) != payload.StdoutSHA256:
+				return fmt.Errorf("stdout does not match the receipt")
+			case receipt.Hash(result.Stderr) != payload.StderrSHA256:
+				return fmt.Errorf("stderr does not match the receipt")
+			case result.ExitCode != payload.ExitCode:
+				return fmt.Errorf("exit code %d does not match the receipt's %d", result.ExitCode, payload.ExitCode)
+			}
+		}
+
+		fmt.Printf("Receipt is valid (key %s)\n", rcpt.KeyID)
+		fmt.Printf("  Command:   %s\n", payload.Command)
+		fmt.Printf("  Exit code: %d\n", payload.ExitCode)
+		fmt.Printf("  Ran:       %s to %s\n", payload.StartTime.Format("2006-01-02 15:04:05"), payload.EndTime.Format("15:04:05 MST"))
+		fmt.Printf("  History:   %s\n", payload.HistoryID)
+		if result != nil {
+			fmt.Printf("  Output matches the receipt\n")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(receiptsCmd)
+	receiptsCmd.AddCommand(receiptsKeygenCmd, receiptsVerifyCmd)
+
+	receiptsKeygenCmd.Flags().StringVar(&receiptKeyOut, "out", "", "file to write the private key to")
+	receiptsVerifyCmd.Flags().StringVar(&receiptPublicKey, "public-key", "", "PEM encoded public (or private) key")
+}
+
+// parseReceiptInput finds the receipt in a receipt, result or history
+// record, returning the result when there is one.
+func parseReceiptInput(data []byte) (*types.Receipt, *types.CommandExecutionResult, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, nil, fmt.Errorf("input is not JSON: %w", err)
+	}
+
+	if raw, ok := fields["result"]; ok {
+		data, fields = raw, nil
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, nil, fmt.Errorf("record result is not JSON: %w", err)
+		}
+	}
+
+	if _, ok := fields["payload"]; ok {
+		var r types.Receipt
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, nil, fmt.Errorf("invalid receipt: %w", err)
+		}
+		return &r, nil, nil
+	}
+
+	var result types.CommandExecutionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, nil, fmt.Errorf("invalid result: %w", err)
+	}
+	if result.Receipt == nil {
+		return nil, nil, fmt.Errorf("input has no receipt")
+	}
+	return result.Receipt, &result, nil
+}
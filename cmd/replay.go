@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/recording"
+	"github.com/spf13/cobra"
+)
+
+var (
+	replayTimeout time.Duration
+	replayVerbose bool
+)
+
+// replayCmd replays a recorded session into a new server.
+var replayCmd = &cobra.Command{
+	Use:   "replay <file>",
+	Short: "Replay a recorded MCP session into a server",
+	Long: `Start a server and send it the client messages of a session recorded with
+"run --record-session", one request at a time, to reproduce bugs a client ran
+into. Each request's outcome is compared with the recording, and the command
+fails if any differ.
+
+The server is started with the configuration given by --config, so replay with
+the configuration the session was recorded with. Secrets masked in the
+recording are replayed masked.
+
+Example:
+  simple-mcp-runner replay session.jsonl --config config.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := recording.Read(args[0])
+		if err != nil {
+			return err
+		}
+
+		self, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find the server executable: %w", err)
+		}
+		serverArgs := []string{"run"}
+		if configFile != "" {
+			serverArgs = append(serverArgs, "--config", configFile)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		srv := exec.CommandContext(ctx, self, serverArgs...)
+		if replayVerbose {
+			srv.Stderr = os.Stderr
+		}
+		stdin, err := srv.StdinPipe()
+		if err != nil {
+			return err
+		}
+		stdout, err := srv.StdoutPipe()
+		if err != nil {
+			return err
+		}
+		if err := srv.Start(); err != nil {
+			return fmt.Errorf("failed to start server: %w", err)
+		}
+
+		exchanges, replayErr := recording.Replay(ctx, entries, stdin, stdout, replayTimeout)
+		stdin.Close()
+		waitServer(srv)
+		if replayErr != nil {
+			return replayErr
+		}
+
+		differ := printExchanges(os.Stdout, exchanges)
+		if differ > 0 {
+			return fmt.Errorf("%d of %d requests had a different outcome than recorded", differ, len(exchanges))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+
+	replayCmd.Flags().DurationVar(&replayTimeout, "timeout", 30*time.Second, "how long to wait for each response")
+	replayCmd.Flags().BoolVarP(&replayVerbose, "verbose", "v", false, "print the server log and every response")
+}
+
+// waitServer waits for the server to exit after its input is closed,
+// killing it if it does not.
+func waitServer(srv *exec.Cmd) {
+	done := make(chan struct{})
+	go func() {
+		_ = srv.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		_ = srv.Process.Kill()
+		<-done
+	}
+}
+
+// printExchanges prints each replayed request and returns how many had a
+// different outcome than recorded.
+func printExchanges(w io.Writer, exchanges []recording.Exchange) int {
+	differ := 0
+	for _, ex := range exchanges {
+		name := ex.Method
+		if ex.Tool != "" {
+			name += " " + ex.Tool
+		}
+
+		status := "matches recording"
+		switch {
+		case ex.RecordedOutcome == "":
+			status = "not in recording"
+		case ex.Differs():
+			status = "recorded " + ex.RecordedOutcome
+			differ++
+		}
+		fmt.Fprintf(w, "%-6s %-40s %-10s (%s)\n", ex.ID, name, ex.Outcome, status)
+
+		if replayVerbose && ex.Response != nil {
+			fmt.Fprintf(w, "       %s\n", ex.Response)
+		}
+	}
+	return differ
+}
@@ -3,17 +3,29 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/internal/instance"
 	"github.com/mjmorales/simple-mcp-runner/internal/logger"
 	"github.com/mjmorales/simple-mcp-runner/internal/server"
 	"github.com/spf13/cobra"
 )
 
 var (
-	logLevel  string
-	logFormat string
+	logLevel      string
+	logFormat     string
+	recordSession string
+	debugAddr     string
+	forceLock     bool
+	preset        string
+
+	// Fault injection, for testing clients
+	injectLatency     time.Duration
+	injectFailureRate float64
 )
 
 // runCmd represents the run command.
@@ -34,20 +46,48 @@ Example:
   # Run with custom configuration
   simple-mcp-runner run --config config.yaml
 
+  # Run a read-only server without a configuration file
+  simple-mcp-runner run --preset safe-default
+
   # Run with debug logging
   simple-mcp-runner run --log-level debug
 
   # Run with JSON logging
-  simple-mcp-runner run --log-format json`,
+  simple-mcp-runner run --log-format json
+
+  # Record the session to attach to a bug report
+  simple-mcp-runner run --record-session session.jsonl
+
+  # Serve profiles and runtime state on http://127.0.0.1:6060/debug/
+  simple-mcp-runner run --debug-addr 127.0.0.1:6060
+
+  # Take over the instance lock of a server that is stuck
+  simple-mcp-runner run --config config.yaml --force`,
 	RunE: runServer,
 }
 
 func init() {
 	rootCmd.AddCommand(runCmd)
 
+	// Configuration flags
+	runCmd.Flags().StringVar(&preset, "preset", "", "run with a built-in configuration instead of a config file ("+strings.Join(config.Presets, ", ")+")")
+
 	// Logging flags
 	runCmd.Flags().StringVar(&logLevel, "log-level", "info", "log level (debug, info, warn, error)")
 	runCmd.Flags().StringVar(&logFormat, "log-format", "text", "log format (text, json)")
+
+	// Debugging flags
+	runCmd.Flags().StringVar(&recordSession, "record-session", "", "record the MCP session to a file, with secrets masked, for replay")
+	runCmd.Flags().StringVar(&debugAddr, "debug-addr", "", "serve pprof, expvar and runtime state on a loopback address such as 127.0.0.1:6060")
+
+	// Instance lock flags
+	runCmd.Flags().BoolVar(&forceLock, "force", false, "start even if another server holds the instance lock of the configuration")
+
+	// Fault injection flags, for testing clients; hidden from help
+	runCmd.Flags().DurationVar(&injectLatency, "inject-latency", 0, "delay executions by a random duration up to this long")
+	runCmd.Flags().Float64Var(&injectFailureRate, "inject-failure-rate", 0, "fail this percentage of executions with an injected error")
+	_ = runCmd.Flags().MarkHidden("inject-latency")
+	_ = runCmd.Flags().MarkHidden("inject-failure-rate")
 }
 
 // runServer runs the MCP server.
@@ -68,11 +108,22 @@ func runServer(cmd *cobra.Command, args []string) error {
 
 	// Load configuration
 	var cfg *config.Config
-	if configFile != "" {
+	var cfgPath string
+	if preset != "" {
+		if configFile != "" {
+			return fmt.Errorf("--preset and --config cannot be combined")
+		}
+		cfg, err = config.Preset(preset)
+		if err != nil {
+			return fmt.Errorf("failed to load preset: %w", err)
+		}
+		log.Info("using preset configuration", "preset", preset)
+	} else if configFile != "" {
 		cfg, err = config.LoadFromFile(configFile)
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
+		cfgPath = configFile
 		log.Info("loaded configuration", "file", configFile)
 	} else {
 		// Try to load from default location
@@ -83,6 +134,7 @@ func runServer(cmd *cobra.Command, args []string) error {
 				if err != nil {
 					return fmt.Errorf("failed to load default config: %w", err)
 				}
+				cfgPath = defaultPath
 				log.Info("loaded default configuration", "file", defaultPath)
 			} else {
 				cfg = config.Default()
@@ -102,14 +154,66 @@ func runServer(cmd *cobra.Command, args []string) error {
 		cfg.Logging.Format = logFormat
 	}
 
+	// Override fault injection from CLI flags if provided
+	if cmd.Flags().Changed("inject-latency") || cmd.Flags().Changed("inject-failure-rate") {
+		if cmd.Flags().Changed("inject-latency") {
+			cfg.Chaos.Latency = config.Duration(injectLatency)
+		}
+		if cmd.Flags().Changed("inject-failure-rate") {
+			cfg.Chaos.FailureRate = injectFailureRate
+		}
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid fault injection flags: %w", err)
+		}
+	}
+
+	// Logging to stdout would corrupt the JSON-RPC stream of the stdio
+	// transport
+	stdoutFallback := cfg.Transport == "stdio" && cfg.Logging.Output == "stdout"
+	if stdoutFallback {
+		cfg.Logging.Output = "stderr"
+	}
+
+	// Apply the configured logging settings
+	logOpts, err = loggerOptions(&cfg.Logging)
+	if err != nil {
+		return err
+	}
+	log, err = logger.New(logOpts)
+	if err != nil {
+		return fmt.Errorf("failed to setup logger: %w", err)
+	}
+	logger.SetDefault(log)
+	if stdoutFallback {
+		log.Warn("logging.output stdout is not allowed with the stdio transport, logging to stderr")
+	}
+
+	// Keep a second server with this configuration from sharing its state
+	if cfg.Instance.Lock {
+		lock, err := instance.Acquire(cfg.Instance.LockDir, cfgPath, forceLock)
+		if err != nil {
+			return fmt.Errorf("failed to acquire instance lock: %w", err)
+		}
+		defer func() {
+			if err := lock.Release(); err != nil {
+				log.WithError(err).Warn("failed to release instance lock")
+			}
+		}()
+		log.Debug("acquired instance lock", "file", lock.Path())
+	}
+
 	// Create and run server
 	srv, err := server.New(server.Options{
-		Config: cfg,
-		Logger: log,
+		Config:        cfg,
+		Logger:        log,
+		RecordSession: recordSession,
+		DebugAddr:     debugAddr,
+		ConfigFile:    cfgPath,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create server: %w", err)
 	}
+	defer srv.Close()
 
 	// Run server with context
 	ctx := context.Background()
@@ -119,3 +223,37 @@ func runServer(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// loggerOptions returns the logger options for the logging configuration,
+// opening the log file if the output is one.
+func loggerOptions(cfg *config.LoggingConfig) (logger.Options, error) {
+	level := cfg.Level
+	if level == "" {
+		level = logLevel
+	}
+	repeatInterval := cfg.RepeatInterval.Std()
+
+	var output io.Writer
+	switch cfg.Output {
+	case "", "stderr":
+		output = os.Stderr
+	case "stdout":
+		output = os.Stdout
+	default:
+		f, err := os.OpenFile(cfg.Output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return logger.Options{}, fmt.Errorf("failed to open log file: %w", err)
+		}
+		output = f
+	}
+
+	return logger.Options{
+		Level:          level,
+		JSONOutput:     cfg.Format == "json",
+		Output:         output,
+		AddSource:      level == "debug" || cfg.IncludeSource,
+		SampleDebug:    cfg.DebugSampleRate,
+		RepeatInterval: repeatInterval,
+		MaxLineLength:  cfg.MaxLineLength,
+	}, nil
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/i18n"
+	"github.com/mjmorales/simple-mcp-runner/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsFile string
+	statsJSON bool
+	statsTop  int
+)
+
+// statsCmd groups the usage analytics commands.
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Review tool usage analytics",
+	Long: `Commands for reviewing how clients use the server's tools.
+
+The server counts tool calls, resource reads, command runs, validation
+failures and denial reasons, and the bytes returned to clients, in
+usage.file when usage.enabled is set.`,
+}
+
+// statsReportCmd summarizes usage.
+var statsReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize tool and command usage",
+	Long: `Summarize how often each tool and command was called, how often calls failed
+validation or were denied, and for which reasons, most frequent first.
+Tools and resources are listed with the bytes their results returned to
+clients, to find the ones slowing down remote clients.
+
+Configured commands that were never called are listed as unused, as
+candidates for removal; frequent denial reasons point at requests the
+security policy or tool descriptions should account for.
+
+Example:
+  simple-mcp-runner stats report --config config.yaml
+  simple-mcp-runner stats report --top 5
+  simple-mcp-runner stats report --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadPolicyConfig()
+		if err != nil {
+			return err
+		}
+
+		path := statsFile
+		if path == "" {
+			path = usage.File(cfg)
+		}
+
+		stats, err := usage.Load(path)
+		if err != nil {
+			return err
+		}
+		report := usage.Summarize(stats, cfg)
+
+		if statsJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(report)
+		}
+
+		p := i18n.New(cfg.Server.Locale)
+		if !cfg.Usage.Enabled && statsFile == "" {
+			fmt.Fprintln(os.Stderr, p.T("Warning: usage.enabled is not set, so the server does not record usage"))
+		}
+		printUsageReport(p, path, report)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.AddCommand(statsReportCmd)
+
+	statsReportCmd.Flags().StringVar(&statsFile, "file", "", "usage file (default is usage.file)")
+	statsReportCmd.Flags().BoolVar(&statsJSON, "json", false, "print the report as JSON")
+	statsReportCmd.Flags().IntVar(&statsTop, "top", 20, "rows per section, 0 for all")
+}
+
+// printUsageReport prints a usage report for operators.
+func printUsageReport(p *i18n.Printer, path string, r *usage.Report) {
+	if r.Since.IsZero() {
+		p.Printf("Usage in %s: nothing recorded\n", path)
+		return
+	}
+	p.Printf("Usage in %s from %s to %s\n", path, r.Since.Local().Format("2006-01-02 15:04"), r.Updated.Local().Format("2006-01-02 15:04"))
+
+	if len(r.Tools) > 0 {
+		p.Printf("\nTools:\n")
+		fmt.Printf("  %-28s %8s %8s %10s %10s  %s\n", p.T("TOOL"), p.T("CALLS"), p.T("FAILED"), p.T("AVG"), p.T("RETURNED"), p.T("LAST CALL"))
+		for _, t := range top(r.Tools) {
+			fmt.Printf("  %-28s %8d %8d %10s %10s  %s\n", t.Tool, t.Calls, t.Failures,
+				t.AverageDuration.Round(time.Millisecond), formatSize(t.Bytes), t.LastCall.Local().Format("2006-01-02 15:04"))
+		}
+	}
+
+	if len(r.Resources) > 0 {
+		p.Printf("\nResources:\n")
+		fmt.Printf("  %-28s %8s %10s  %s\n", p.T("RESOURCE"), p.T("READS"), p.T("RETURNED"), p.T("LAST READ"))
+		for _, res := range top(r.Resources) {
+			fmt.Printf("  %-28s %8d %10s  %s\n", res.URI, res.Reads, formatSize(res.Bytes), res.LastRead.Local().Format("2006-01-02 15:04"))
+		}
+	}
+
+	if len(r.Unused) > 0 {
+		p.Printf("\nConfigured commands never called:\n")
+		for _, name := range r.Unused {
+			fmt.Printf("  %s\n", name)
+		}
+	}
+
+	if len(r.Commands) > 0 {
+		p.Printf("\nCommands:\n")
+		fmt.Printf("  %-28s %8s %8s %8s %8s\n", p.T("COMMAND"), p.T("RUNS"), p.T("FAILED"), p.T("INVALID"), p.T("DENIED"))
+		for _, c := range top(r.Commands) {
+			fmt.Printf("  %-28s %8d %8d %8d %8d\n", c.Command, c.Runs, c.Failures, c.ValidationFailures, c.Denials)
+		}
+	}
+
+	printReasons(p, "Denial reasons", r.Denials)
+	printReasons(p, "Validation failures", r.Validations)
+}
+
+// printReasons prints rejection reasons with their most rejected commands.
+func printReasons(p *i18n.Printer, title string, reasons []usage.ReasonReport) {
+	if len(reasons) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n", p.T(title))
+	for _, r := range top(reasons) {
+		var commands []string
+		for _, c := range r.Commands {
+			if len(commands) == 5 {
+				commands = append(commands, "...")
+				break
+			}
+			commands = append(commands, fmt.Sprintf("%s %d", c.Command, c.Count))
+		}
+		fmt.Printf("  %-40s %8d  (%s)\n", r.Reason, r.Count, strings.Join(commands, ", "))
+	}
+}
+
+// formatSize formats a number of bytes with one decimal in the largest
+// binary unit below it, e.g. "1.5MiB".
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// top returns the first --top rows.
+func top[T any](rows []T) []T {
+	if statsTop > 0 && len(rows) > statsTop {
+		return rows[:statsTop]
+	}
+	return rows
+}
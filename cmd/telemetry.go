@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/telemetry"
+	"github.com/mjmorales/simple-mcp-runner/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+var telemetryFile string
+
+// telemetryCmd groups the telemetry commands.
+var telemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: "Inspect the anonymous usage reports",
+	Long: `Commands for inspecting the anonymous usage reports sent to the maintainers.
+
+Telemetry is off unless telemetry.enabled is set and telemetry.endpoint is
+configured. Setting DO_NOT_TRACK=1 or SIMPLE_MCP_RUNNER_TELEMETRY=off turns it
+off whatever the configuration says. Reports only hold counts: calls of the
+built-in tools, rejected and failed command runs by category, and the version,
+OS and architecture of the server.`,
+}
+
+// telemetryStatusCmd tells whether reports are sent.
+var telemetryStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether reports are sent, and where",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadPolicyConfig()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case telemetry.Enabled(cfg):
+			fmt.Printf("Telemetry: on, every %s to %s\n", telemetry.Interval(cfg), cfg.Telemetry.Endpoint)
+		case cfg.Telemetry.Enabled && telemetry.OptedOut() != "":
+			fmt.Printf("Telemetry: off (turned off by %s)\n", telemetry.OptedOut())
+		default:
+			fmt.Println("Telemetry: off")
+		}
+		fmt.Printf("Counts file: %s\n", telemetry.File(cfg))
+		return nil
+	},
+}
+
+// telemetryPreviewCmd prints the next report.
+var telemetryPreviewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Print the report that would be sent next",
+	Long: `Print the report that would be sent next, as JSON, without sending it.
+
+With --file, print the report the counts of a usage file would give, e.g. the
+usage.file of "stats report", to see what would be sent before enabling
+telemetry.
+
+Example:
+  simple-mcp-runner telemetry preview
+  simple-mcp-runner telemetry preview --file ~/.cache/simple-mcp-runner/usage.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadPolicyConfig()
+		if err != nil {
+			return err
+		}
+
+		var report *telemetry.Report
+		if telemetryFile != "" {
+			stats, err := usage.Load(telemetryFile)
+			if err != nil {
+				return err
+			}
+			report = telemetry.Build(stats, "")
+		} else if report, err = telemetry.Preview(cfg); err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(telemetryCmd)
+	telemetryCmd.AddCommand(telemetryStatusCmd)
+	telemetryCmd.AddCommand(telemetryPreviewCmd)
+
+	telemetryPreviewCmd.Flags().StringVar(&telemetryFile, "file", "", "usage file to build the report from")
+}
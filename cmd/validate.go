@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/mjmorales/simple-mcp-runner/internal/config"
+	"github.com/mjmorales/simple-mcp-runner/internal/cmdcheck"
+	"github.com/mjmorales/simple-mcp-runner/internal/i18n"
+	"github.com/mjmorales/simple-mcp-runner/internal/scheduler"
+	"github.com/mjmorales/simple-mcp-runner/internal/script"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
 	"github.com/spf13/cobra"
 )
 
@@ -39,43 +43,97 @@ Example:
 		}
 
 		// Load and validate configuration
-		cfg, err := config.Load(cfgFile)
+		cfg, err := config.LoadFromFile(cfgFile)
 		if err != nil {
 			return fmt.Errorf("configuration validation failed: %w", err)
 		}
 
+		for _, sched := range cfg.Schedules {
+			if _, err := scheduler.ParseCron(sched.Cron); err != nil {
+				return fmt.Errorf("configuration validation failed: schedule %s: %w", sched.Name, err)
+			}
+		}
+
+		for _, tool := range cfg.ScriptTools {
+			if _, err := script.Compile(tool); err != nil {
+				return fmt.Errorf("configuration validation failed: %w", err)
+			}
+		}
+
 		// Print validation results
-		fmt.Printf("✓ Configuration file is valid: %s\n", cfgFile)
-		fmt.Printf("\nConfiguration summary:\n")
-		fmt.Printf("  Application: %s\n", cfg.App)
-		fmt.Printf("  Transport: %s\n", cfg.Transport)
-		fmt.Printf("  Commands: %d defined\n", len(cfg.Commands))
+		p := i18n.New(cfg.Server.Locale)
+		p.Printf("✓ Configuration file is valid: %s\n", cfgFile)
+		p.Printf("\nConfiguration summary:\n")
+		p.Printf("  Application: %s\n", cfg.App)
+		p.Printf("  Transport: %s\n", cfg.Transport)
+		if cfg.Server.ToolPrefix != "" {
+			p.Printf("  Tool prefix: %s\n", cfg.Server.ToolPrefix)
+		}
+		p.Printf("  Commands: %d defined\n", len(cfg.Commands))
+		if cfg.Catalog.URL != "" {
+			p.Printf("  Command catalog: %s\n", cfg.Catalog.URL)
+		}
+		if len(cfg.ScriptTools) > 0 {
+			p.Printf("  Script tools: %d defined\n", len(cfg.ScriptTools))
+		}
 
 		if len(cfg.Commands) > 0 {
-			fmt.Printf("\n  Configured commands:\n")
+			p.Printf("\n  Configured commands:\n")
 			for _, cmd := range cfg.Commands {
-				fmt.Printf("    - %s: %s\n", cmd.Name, cmd.Description)
+				p.Printf("    - %s: %s\n", cmd.Name, cmd.Description)
 			}
 		}
 
-		fmt.Printf("\n  Security settings:\n")
-		fmt.Printf("    Max command length: %d\n", cfg.Security.MaxCommandLength)
-		fmt.Printf("    Shell expansion disabled: %v\n", cfg.Security.DisableShellExpansion)
+		p.Printf("\n  Security settings:\n")
+		p.Printf("    Max command length: %d\n", cfg.Security.MaxCommandLength)
+		p.Printf("    Shell expansion disabled: %v\n", cfg.Security.DisableShellExpansion)
 		if len(cfg.Security.BlockedCommands) > 0 {
-			fmt.Printf("    Blocked commands: %d\n", len(cfg.Security.BlockedCommands))
+			p.Printf("    Blocked commands: %d\n", len(cfg.Security.BlockedCommands))
 		}
 		if len(cfg.Security.AllowedCommands) > 0 {
-			fmt.Printf("    Allowed commands: %d\n", len(cfg.Security.AllowedCommands))
+			p.Printf("    Allowed commands: %d\n", len(cfg.Security.AllowedCommands))
 		}
 		if len(cfg.Security.AllowedPaths) > 0 {
-			fmt.Printf("    Allowed paths: %d\n", len(cfg.Security.AllowedPaths))
+			p.Printf("    Allowed paths: %d\n", len(cfg.Security.AllowedPaths))
+		}
+		if len(cfg.Security.DeniedPaths) > 0 {
+			p.Printf("    Denied paths: %d\n", len(cfg.Security.DeniedPaths))
 		}
 
-		fmt.Printf("\n  Execution limits:\n")
-		fmt.Printf("    Default timeout: %s\n", cfg.Execution.DefaultTimeout)
-		fmt.Printf("    Max timeout: %s\n", cfg.Execution.MaxTimeout)
-		fmt.Printf("    Max concurrent: %d\n", cfg.Execution.MaxConcurrent)
-		fmt.Printf("    Max output size: %d bytes\n", cfg.Execution.MaxOutputSize)
+		p.Printf("\n  Execution limits:\n")
+		p.Printf("    Default timeout: %s\n", cfg.Execution.DefaultTimeout)
+		p.Printf("    Max timeout: %s\n", cfg.Execution.MaxTimeout)
+		p.Printf("    Max concurrent: %d\n", cfg.Execution.MaxConcurrent)
+		p.Printf("    Max output size: %d bytes\n", cfg.Execution.MaxOutputSize)
+		if cfg.Execution.SpillThreshold > 0 {
+			p.Printf("    Spill threshold: %d bytes\n", cfg.Execution.SpillThreshold)
+		}
+
+		if len(cfg.Schedules) > 0 {
+			p.Printf("\n  Schedules:\n")
+			for _, sched := range cfg.Schedules {
+				p.Printf("    - %s: %s (%s)\n", sched.Name, sched.Command, sched.Cron)
+			}
+		}
+
+		header := false
+		for _, result := range cmdcheck.CheckAll(cfg.Commands) {
+			for _, problem := range result.Problems {
+				if !header {
+					p.Printf("\n  Warning: configured commands that cannot run here:\n")
+					header = true
+				}
+				if problem.Hint != "" {
+					p.Printf("    - %s: %s (%s)\n", result.Command, problem.Message, problem.Hint)
+				} else {
+					p.Printf("    - %s: %s\n", result.Command, problem.Message)
+				}
+			}
+		}
+
+		if cfg.Transport == "stdio" && cfg.Logging.Output == "stdout" {
+			p.Printf("\n  Warning: logging.output stdout would corrupt the stdio transport; the server logs to stderr instead\n")
+		}
 
 		return nil
 	},
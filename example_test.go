@@ -1,7 +1,9 @@
 package main
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mjmorales/simple-mcp-runner/pkg/config"
 	"github.com/mjmorales/simple-mcp-runner/pkg/discovery"
@@ -98,11 +100,11 @@ execution:
 	}
 
 	// Test security checks
-	if !cfg.IsCommandAllowed("ls") {
+	if !cfg.IsCommandAllowed("ls", "") {
 		t.Error("Expected ls command to be allowed")
 	}
 
-	if cfg.IsCommandAllowed("rm") {
+	if cfg.IsCommandAllowed("rm", "") {
 		t.Error("Expected rm command to be blocked")
 	}
 
@@ -123,7 +125,7 @@ func TestConfigurationTypes(t *testing.T) {
 				Description: "A test command",
 				Command:     "echo",
 				Args:        []string{"test"},
-				Timeout:     "30s",
+				Timeout:     config.Duration(30 * time.Second),
 				AllowArgs:   true,
 			},
 		},
@@ -133,8 +135,8 @@ func TestConfigurationTypes(t *testing.T) {
 			BlockedCommands:       []string{"rm", "dd"},
 		},
 		Execution: config.ExecutionConfig{
-			DefaultTimeout: "30s",
-			MaxTimeout:     "5m",
+			DefaultTimeout: config.Duration(30 * time.Second),
+			MaxTimeout:     config.Duration(5 * time.Minute),
 			MaxConcurrent:  5,
 			MaxOutputSize:  1024 * 1024,
 		},
@@ -180,4 +182,158 @@ func TestInterfaceUsage(t *testing.T) {
 	if discReq.Pattern != "*" {
 		t.Error("Discovery builder failed")
 	}
-}
\ No newline at end of file
+}
+
+// TestConfigUnits checks that durations and sizes are parsed at load time.
+func TestConfigUnits(t *testing.T) {
+	cfg, err := config.LoadFromBytes([]byte(`
+app: test-app
+execution:
+  default_timeout: 45s
+  max_timeout: 2m
+  max_output_size: 10MiB
+  spill_threshold: 512KB
+`))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Execution.DefaultTimeout.Std() != 45*time.Second || cfg.Execution.MaxTimeout.String() != "2m" {
+		t.Errorf("Unexpected timeouts %s and %s", cfg.Execution.DefaultTimeout, cfg.Execution.MaxTimeout)
+	}
+	if cfg.Execution.MaxOutputSize != 10<<20 || cfg.Execution.SpillThreshold != 512000 {
+		t.Errorf("Unexpected sizes %d and %d", cfg.Execution.MaxOutputSize, cfg.Execution.SpillThreshold)
+	}
+	if cfg.Execution.MaxOutputSize.String() != "10MiB" {
+		t.Errorf("Expected 10MiB, got %s", cfg.Execution.MaxOutputSize)
+	}
+
+	for _, bad := range []string{
+		"execution:\n  default_timeout: 30",
+		"execution:\n  default_timeout: soon",
+		"execution:\n  max_output_size: 10 parsecs",
+		"execution:\n  max_output_size: 99999999999TB",
+	} {
+		_, err := config.LoadFromBytes([]byte("app: test-app\n" + bad))
+		if err == nil || !strings.Contains(err.Error(), "line 3") {
+			t.Errorf("Expected an error at line 3 for %q, got %v", bad, err)
+		}
+	}
+}
+
+// TestCommandDefaults checks that commands inherit command_defaults.
+func TestCommandDefaults(t *testing.T) {
+	cfg, err := config.LoadFromBytes([]byte(`
+app: test-app
+command_defaults:
+  timeout: 2m
+  max_output_size: 1MiB
+  env:
+    CI: "1"
+    LANG: C
+  risky: true
+commands:
+  - name: build
+    description: Build
+    command: make
+  - name: status
+    description: Status
+    command: git
+    timeout: 5s
+    env:
+      LANG: en_US.UTF-8
+    risky: false
+`))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	build, status := cfg.FindCommand("build"), cfg.FindCommand("status")
+	if build.Timeout.String() != "2m" || build.MaxOutputSize != 1<<20 || !build.Risky {
+		t.Errorf("Expected build to inherit the defaults, got %+v", build)
+	}
+	if build.Env["CI"] != "1" || build.Env["LANG"] != "C" {
+		t.Errorf("Expected build to inherit the env, got %v", build.Env)
+	}
+	if status.Timeout.String() != "5s" || status.MaxOutputSize != 1<<20 || status.Risky {
+		t.Errorf("Expected status to override the defaults, got %+v", status)
+	}
+	if status.Env["CI"] != "1" || status.Env["LANG"] != "en_US.UTF-8" {
+		t.Errorf("Expected status to merge the env, got %v", status.Env)
+	}
+	if cfg.CommandDefaults.Env["LANG"] != "C" {
+		t.Errorf("Expected the default env to be kept, got %v", cfg.CommandDefaults.Env)
+	}
+}
+
+// TestWorkDirMode checks how configured commands infer their working
+// directory.
+func TestWorkDirMode(t *testing.T) {
+	cfg, err := config.LoadFromBytes([]byte(`
+app: test-app
+command_defaults:
+  workdir_mode: nearest_marker
+commands:
+  - name: test
+    description: Run tests
+    command: go
+    args: [test, ./...]
+  - name: status
+    description: Status
+    command: git
+    workdir_mode: git_root
+  - name: build
+    description: Build
+    command: make
+    workdir_markers: [Makefile]
+`))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if mode := cfg.FindCommand("test").WorkDirMode; mode != config.WorkDirModeNearestMarker {
+		t.Errorf("Expected test to inherit nearest_marker, got %q", mode)
+	}
+	if mode := cfg.FindCommand("status").WorkDirMode; mode != config.WorkDirModeGitRoot {
+		t.Errorf("Expected status to use git_root, got %q", mode)
+	}
+
+	_, err = config.LoadFromBytes([]byte(`
+app: test-app
+commands:
+  - {name: test, description: Test, command: go, workdir_mode: module_root}
+`))
+	if err == nil {
+		t.Error("Expected an unknown workdir_mode to be rejected")
+	}
+}
+
+// TestToolNames checks the names configured commands may have.
+func TestToolNames(t *testing.T) {
+	load := func(names ...string) error {
+		yamlConfig := "app: test-app\ncommands:\n"
+		for _, name := range names {
+			yamlConfig += "  - {name: " + name + ", description: Test, command: echo}\n"
+		}
+		_, err := config.LoadFromBytes([]byte(yamlConfig))
+		return err
+	}
+
+	if err := load("run-checks", "lint.go", "build_all"); err != nil {
+		t.Errorf("Expected hyphens and dots to be allowed, got %v", err)
+	}
+	for _, names := range [][]string{{"-tests"}, {"run tests"}, {"run/tests"}, {"run-checks", "run_checks"}, {"run-tests"}, {"a.b", "a-b"}, {"execute_command"}, {"discover-commands"}} {
+		if err := load(names...); err == nil {
+			t.Errorf("Expected %v to be rejected", names)
+		}
+	}
+
+	_, err := config.LoadFromBytes([]byte(`
+app: test-app
+script_tools:
+  - name: search_tools
+    description: Test
+    script: "def main(params): return 1"
+`))
+	if err == nil || !strings.Contains(err.Error(), "built-in tool") {
+		t.Errorf("Expected a script tool named like a built-in tool to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,485 @@
+// Package approval holds runs of commands requiring a second approval
+// until two distinct operators approve them. Requests and decisions are
+// appended to a log shared by the server and the approvals command, which
+// is also their audit trail. Decisions are signed with the operators'
+// keys, so they cannot be forged by writing to the log
+package approval
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/receipt"
+	"github.com/mjmorales/simple-mcp-runner/internal/security"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// Required is the number of distinct operators that must approve a run.
+const Required = 2
+
+// defaultExpiry applies when approvals.expiry is not set.
+const defaultExpiry = time.Hour
+
+// Event types.
+const (
+	EventRequested = "requested"
+	EventApproved  = "approved"
+	EventRejected  = "rejected"
+	EventConsumed  = "consumed" // The approved run started
+	EventExecuted  = "executed" // The approved run finished
+)
+
+// Request states.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	StatusExpired  = "expired"
+	StatusConsumed = "consumed"
+)
+
+// Event is an entry in the approval log.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	ID      string    `json:"id"`
+	By      string    `json:"by,omitempty"` // Operator or requesting principal
+	Client  string    `json:"client,omitempty"`
+	Command string    `json:"command,omitempty"`
+	Args    []string  `json:"args,omitempty"`
+	WorkDir string    `json:"workdir,omitempty"`
+	Comment string    `json:"comment,omitempty"`
+	Expires time.Time `json:"expires,omitempty"`
+
+	// ExitCode and Error describe the finished run
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Error    string `json:"error,omitempty"`
+
+	// Signature is the operator's base64 ed25519 signature of a decision
+	Signature string `json:"signature,omitempty"`
+
+	// Unverified marks a decision whose signature does not verify against
+	// the key of an operator; it is shown in the audit trail but not
+	// counted
+	Unverified bool `json:"unverified,omitempty"`
+}
+
+// Request is the state of an approval request, replayed from its events.
+type Request struct {
+	ID          string    `json:"id"`
+	Command     string    `json:"command"`
+	Args        []string  `json:"args,omitempty"`
+	WorkDir     string    `json:"workdir,omitempty"`
+	RequestedBy string    `json:"requested_by,omitempty"`
+	Client      string    `json:"client,omitempty"`
+	Created     time.Time `json:"created"`
+	Expires     time.Time `json:"expires"`
+	Status      string    `json:"status"`
+	ApprovedBy  []string  `json:"approved_by,omitempty"`
+	RejectedBy  string    `json:"rejected_by,omitempty"`
+	Events      []Event   `json:"events"`
+}
+
+// File returns the approval log of a configuration.
+func File(cfg *config.Config) string {
+	if cfg.Approvals.File != "" {
+		return cfg.Approvals.File
+	}
+	return filepath.Join(config.StateDir(), "approvals.jsonl")
+}
+
+// KeyFile returns the private key decisions are signed with.
+func KeyFile(cfg *config.Config) string {
+	if cfg.Approvals.KeyFile != "" {
+		return cfg.Approvals.KeyFile
+	}
+	return filepath.Join(config.KeyDir(), "operator.pem")
+}
+
+// LoadKey reads the private key decisions are signed with.
+func LoadKey(cfg *config.Config) (*receipt.Signer, error) {
+	key, err := receipt.LoadSigner(KeyFile(cfg))
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission,
+			"failed to load operator key; create one with \"simple-mcp-runner approvals keygen\"")
+	}
+	return key, nil
+}
+
+// Store reads and appends to the approval log.
+type Store struct {
+	path      string
+	expiry    time.Duration
+	operators map[string]ed25519.PublicKey
+	keyErr    error // Failure loading the keys of the operators
+	mu        sync.Mutex
+}
+
+// New creates a store for the configured approval log.
+func New(cfg *config.Config) *Store {
+	expiry := defaultExpiry
+	if cfg.Approvals.Expiry > 0 {
+		expiry = cfg.Approvals.Expiry.Std()
+	}
+	s := &Store{path: File(cfg), expiry: expiry, operators: make(map[string]ed25519.PublicKey)}
+	for _, op := range cfg.Approvals.Operators {
+		pub, err := receipt.LoadPublicKey(op.PublicKey)
+		if err != nil {
+			s.keyErr = apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to load the key of operator "+op.Name)
+			break
+		}
+		s.operators[op.Name] = pub
+	}
+	return s
+}
+
+// Path returns the approval log file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Request records a pending request to run a command.
+func (s *Store) Request(command string, args []string, workDir string, sc *security.Context) (*Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	event := Event{
+		Time:    now,
+		Type:    EventRequested,
+		ID:      newID(),
+		By:      sc.User(),
+		Client:  sc.Client(),
+		Command: command,
+		Args:    args,
+		WorkDir: workDir,
+		Expires: now.Add(s.expiry),
+	}
+	if err := s.append(event); err != nil {
+		return nil, err
+	}
+	return s.replay([]Event{event}, now), nil
+}
+
+// Approve records the approval of a pending request by the operator
+// whose key signs it.
+func (s *Store) Approve(id string, key *receipt.Signer, comment string) (*Request, error) {
+	return s.decide(id, EventApproved, key, comment)
+}
+
+// Reject records the rejection of a pending request by the operator whose
+// key signs it.
+func (s *Store) Reject(id string, key *receipt.Signer, comment string) (*Request, error) {
+	return s.decide(id, EventRejected, key, comment)
+}
+
+func (s *Store) decide(id, eventType string, key *receipt.Signer, comment string) (*Request, error) {
+	operator, err := s.operator(key)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != StatusPending {
+		return nil, apperrors.ValidationError(fmt.Sprintf("request %s is %s", id, req.Status), "id")
+	}
+	if slices.Contains(req.ApprovedBy, operator) {
+		return nil, apperrors.ValidationError(fmt.Sprintf("%s already approved request %s; a different operator must approve", operator, id), "id")
+	}
+
+	event := Event{Time: time.Now(), Type: eventType, ID: id, By: operator, Comment: comment}
+	event.Signature = base64.StdEncoding.EncodeToString(key.SignData(signedDecision(req, event)))
+	if err := s.append(event); err != nil {
+		return nil, err
+	}
+	req.Events = append(req.Events, event)
+	return s.replay(req.Events, event.Time), nil
+}
+
+// operator returns the operator a key belongs to.
+func (s *Store) operator(key *receipt.Signer) (string, error) {
+	if s.keyErr != nil {
+		return "", s.keyErr
+	}
+	if len(s.operators) == 0 {
+		return "", apperrors.PermissionError("no operators are configured in approvals.operators", "approvals.operators")
+	}
+	if key == nil {
+		return "", apperrors.PermissionError("an operator key is required", "key")
+	}
+	pub := key.PublicKey()
+	for name, opKey := range s.operators {
+		if opKey.Equal(pub) {
+			return name, nil
+		}
+	}
+	return "", apperrors.PermissionError(fmt.Sprintf("key %s is not the key of an operator in approvals.operators", receipt.KeyID(pub)), "key")
+}
+
+// verified reports whether a decision is signed by the operator it names.
+func (s *Store) verified(req *Request, e Event) bool {
+	pub, ok := s.operators[e.By]
+	if !ok {
+		return false
+	}
+	sig, err := base64.StdEncoding.DecodeString(e.Signature)
+	return err == nil && ed25519.Verify(pub, signedDecision(req, e), sig)
+}
+
+// signedDecision returns the data an operator signs for a decision, which
+// binds it to the command, arguments and workdir of the request.
+func signedDecision(req *Request, e Event) []byte {
+	data, _ := json.Marshal(struct {
+		Time    time.Time `json:"time"`
+		Type    string    `json:"type"`
+		ID      string    `json:"id"`
+		By      string    `json:"by"`
+		Comment string    `json:"comment,omitempty"`
+		Command string    `json:"command"`
+		Args    []string  `json:"args,omitempty"`
+		WorkDir string    `json:"workdir,omitempty"`
+	}{e.Time, e.Type, e.ID, e.By, e.Comment, req.Command, req.Args, req.WorkDir})
+	return data
+}
+
+// Check returns a request approved for running a command with arguments
+// in a working directory, without consuming it.
+func (s *Store) Check(id, command string, args []string, workDir string) (*Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.check(id, command, args, workDir)
+}
+
+// Consume starts the approved run of a request, which must be for the same
+// command, arguments and working directory. Each approval allows one run.
+func (s *Store) Consume(id, command string, args []string, workDir string, sc *security.Context) (*Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, err := s.check(id, command, args, workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	event := Event{Time: time.Now(), Type: EventConsumed, ID: id, By: sc.User(), Client: sc.Client()}
+	if err := s.append(event); err != nil {
+		return nil, err
+	}
+	req.Events = append(req.Events, event)
+	return s.replay(req.Events, event.Time), nil
+}
+
+func (s *Store) check(id, command string, args []string, workDir string) (*Request, error) {
+	req, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != StatusApproved {
+		return nil, apperrors.PermissionError(
+			fmt.Sprintf("request %s is %s (%d of %d approvals)", id, req.Status, len(req.ApprovedBy), Required),
+			id,
+		)
+	}
+	if req.Command != command || !slices.Equal(req.Args, args) || req.WorkDir != workDir {
+		return nil, apperrors.PermissionError(fmt.Sprintf("request %s was approved for a different command, arguments or workdir", id), id)
+	}
+	return req, nil
+}
+
+// Executed records the outcome of an approved run.
+func (s *Store) Executed(id string, exitCode int, runErr error) error {
+	event := Event{Time: time.Now(), Type: EventExecuted, ID: id, ExitCode: &exitCode}
+	if runErr != nil {
+		event.Error = runErr.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.append(event)
+}
+
+// Get returns a request and its audit trail.
+func (s *Store) Get(id string) (*Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(id)
+}
+
+// List returns all requests, newest first.
+func (s *Store) List() ([]*Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string][]Event)
+	for _, e := range events {
+		byID[e.ID] = append(byID[e.ID], e)
+	}
+
+	now := time.Now()
+	var requests []*Request
+	for _, events := range byID {
+		if req := s.replay(events, now); req != nil {
+			requests = append(requests, req)
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].Created.After(requests[j].Created)
+	})
+	return requests, nil
+}
+
+func (s *Store) get(id string) (*Request, error) {
+	events, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []Event
+	for _, e := range events {
+		if e.ID == id {
+			matching = append(matching, e)
+		}
+	}
+
+	req := s.replay(matching, time.Now())
+	if req == nil {
+		return nil, apperrors.NotFoundError("approval request not found: "+id, id)
+	}
+	return req, nil
+}
+
+// replay builds the state of a request from its events, or returns nil if
+// it was never requested. Decisions not signed by the operator they name
+// are marked unverified and not counted.
+func (s *Store) replay(events []Event, now time.Time) *Request {
+	var req *Request
+	for _, e := range events {
+		switch e.Type {
+		case EventRequested:
+			req = &Request{
+				ID:          e.ID,
+				Command:     e.Command,
+				Args:        e.Args,
+				WorkDir:     e.WorkDir,
+				RequestedBy: e.By,
+				Client:      e.Client,
+				Created:     e.Time,
+				Expires:     e.Expires,
+				Status:      StatusPending,
+			}
+		case EventApproved, EventRejected:
+			if req == nil {
+				break
+			}
+			if !s.verified(req, e) {
+				e.Unverified = true
+			} else if e.Type == EventRejected {
+				req.RejectedBy = e.By
+			} else if !slices.Contains(req.ApprovedBy, e.By) {
+				req.ApprovedBy = append(req.ApprovedBy, e.By)
+			}
+		}
+		if req != nil {
+			req.Events = append(req.Events, e)
+		}
+	}
+	if req == nil {
+		return nil
+	}
+
+	consumed := slices.ContainsFunc(req.Events, func(e Event) bool { return e.Type == EventConsumed })
+	switch {
+	case consumed:
+		req.Status = StatusConsumed
+	case req.RejectedBy != "":
+		req.Status = StatusRejected
+	case now.After(req.Expires):
+		req.Status = StatusExpired
+	case len(req.ApprovedBy) >= Required:
+		req.Status = StatusApproved
+	}
+	return req
+}
+
+// append writes an event to the log.
+func (s *Store) append(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to encode approval event")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to create approvals directory")
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to open approvals file")
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to record approval event")
+	}
+	return nil
+}
+
+// load reads every event in the log. Malformed lines are skipped.
+func (s *Store) load() ([]Event, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to open approvals file")
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read approvals file")
+	}
+	return events, nil
+}
+
+// newID returns a random request identifier.
+func newID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to time
+		return "a" + time.Now().Format("20060102150405.000000000")
+	}
+	return "a" + hex.EncodeToString(b)
+}
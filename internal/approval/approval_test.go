@@ -0,0 +1,167 @@
+package approval
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/receipt"
+	"github.com/mjmorales/simple-mcp-runner/internal/security"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStore(t *testing.T, expiry config.Duration) (*Store, map[string]*receipt.Signer) {
+	cfg := config.Default()
+	cfg.Approvals.File = filepath.Join(t.TempDir(), "approvals.jsonl")
+	cfg.Approvals.Expiry = expiry
+	keys := operatorKeys(t, cfg, "alice", "bob")
+	return New(cfg), keys
+}
+
+// operatorKeys configures operators with new keys and returns their
+// signing keys.
+func operatorKeys(t *testing.T, cfg *config.Config, names ...string) map[string]*receipt.Signer {
+	t.Helper()
+	dir := t.TempDir()
+	keys := make(map[string]*receipt.Signer)
+	for _, name := range names {
+		private, public, err := receipt.GenerateKey()
+		require.NoError(t, err)
+		keyFile := filepath.Join(dir, name+".pem")
+		require.NoError(t, os.WriteFile(keyFile, private, 0o600))
+		pubFile := filepath.Join(dir, name+".pub")
+		require.NoError(t, os.WriteFile(pubFile, public, 0o600))
+		cfg.Approvals.Operators = append(cfg.Approvals.Operators, config.ApprovalOperator{Name: name, PublicKey: pubFile})
+		keys[name], err = receipt.LoadSigner(keyFile)
+		require.NoError(t, err)
+	}
+	return keys
+}
+
+func TestStore_TwoPersonRule(t *testing.T) {
+	s, keys := testStore(t, config.Duration(time.Hour))
+	sc := &security.Context{Principal: "agent", ClientName: "editor"}
+
+	req, err := s.Request("deploy", []string{"prod"}, "/srv", sc)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, req.Status)
+	assert.Equal(t, "agent", req.RequestedBy)
+
+	// Not runnable before approval
+	_, err = s.Consume(req.ID, "deploy", []string{"prod"}, "/srv", sc)
+	assertType(t, err, apperrors.ErrorTypePermission)
+
+	// The same operator cannot approve twice
+	req, err = s.Approve(req.ID, keys["alice"], "looks good")
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, req.Status)
+	_, err = s.Approve(req.ID, keys["alice"], "")
+	assert.Error(t, err)
+	_, err = s.Approve(req.ID, nil, "")
+	assert.Error(t, err)
+
+	req, err = s.Approve(req.ID, keys["bob"], "")
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, req.Status)
+	assert.Equal(t, []string{"alice", "bob"}, req.ApprovedBy)
+
+	// The approval is bound to the command, arguments and workdir
+	_, err = s.Consume(req.ID, "deploy", []string{"staging"}, "/srv", sc)
+	assertType(t, err, apperrors.ErrorTypePermission)
+
+	_, err = s.Consume(req.ID, "deploy", []string{"prod"}, "/srv", sc)
+	require.NoError(t, err)
+	require.NoError(t, s.Executed(req.ID, 0, nil))
+
+	// Each approval allows one run
+	_, err = s.Consume(req.ID, "deploy", []string{"prod"}, "/srv", sc)
+	assertType(t, err, apperrors.ErrorTypePermission)
+
+	req, err = s.Get(req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusConsumed, req.Status)
+	var types []string
+	for _, e := range req.Events {
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []string{EventRequested, EventApproved, EventApproved, EventConsumed, EventExecuted}, types)
+}
+
+func TestStore_RejectAndExpire(t *testing.T) {
+	s, keys := testStore(t, config.Duration(time.Hour))
+
+	req, err := s.Request("deploy", nil, "", nil)
+	require.NoError(t, err)
+	req, err = s.Reject(req.ID, keys["alice"], "not today")
+	require.NoError(t, err)
+	assert.Equal(t, StatusRejected, req.Status)
+	_, err = s.Approve(req.ID, keys["bob"], "")
+	assert.Error(t, err)
+
+	s, _ = testStore(t, config.Duration(time.Nanosecond))
+	req, err = s.Request("deploy", nil, "", nil)
+	require.NoError(t, err)
+	req, err = s.Get(req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusExpired, req.Status)
+
+	_, err = s.Get("missing")
+	assertType(t, err, apperrors.ErrorTypeNotFound)
+}
+
+func TestStore_List(t *testing.T) {
+	s, _ := testStore(t, config.Duration(time.Hour))
+
+	first, err := s.Request("build", nil, "", nil)
+	require.NoError(t, err)
+	second, err := s.Request("deploy", nil, "", nil)
+	require.NoError(t, err)
+
+	requests, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, second.ID, requests[0].ID)
+	assert.Equal(t, first.ID, requests[1].ID)
+}
+
+func TestStore_SignedDecisions(t *testing.T) {
+	s, keys := testStore(t, config.Duration(time.Hour))
+
+	// Keys of people who are not operators are refused
+	cfg := config.Default()
+	outsider := operatorKeys(t, cfg, "mallory")["mallory"]
+	req, err := s.Request("deploy", []string{"prod"}, "", nil)
+	require.NoError(t, err)
+	_, err = s.Approve(req.ID, outsider, "")
+	assertType(t, err, apperrors.ErrorTypePermission)
+
+	// Decisions written to the log without a valid signature do not count
+	req, err = s.Approve(req.ID, keys["alice"], "")
+	require.NoError(t, err)
+	now := time.Now()
+	require.NoError(t, s.append(Event{Time: now, Type: EventApproved, ID: req.ID, By: "bob"}))
+	forged := req.Events[1]
+	forged.By, forged.Time = "bob", now
+	require.NoError(t, s.append(forged))
+
+	req, err = s.Get(req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, req.Status)
+	assert.Equal(t, []string{"alice"}, req.ApprovedBy)
+	require.Len(t, req.Events, 4)
+	assert.True(t, req.Events[2].Unverified)
+	assert.True(t, req.Events[3].Unverified)
+}
+
+func assertType(t *testing.T, err error, errType apperrors.ErrorType) {
+	t.Helper()
+	var appErr *apperrors.Error
+	if assert.True(t, errors.As(err, &appErr), "expected app error, got %v", err) {
+		assert.Equal(t, errType, appErr.Type)
+	}
+}
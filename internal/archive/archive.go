@@ -0,0 +1,150 @@
+// Package archive creates and extracts zip and tar.gz archives safely
+package archive
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// Supported archive formats.
+const (
+	FormatZip   = "zip"
+	FormatTarGz = "tar.gz"
+)
+
+// ExtractRequest describes an archive to extract.
+type ExtractRequest struct {
+	Archive   string
+	Dest      string
+	Format    string // Detected from the archive name when empty
+	Overwrite bool
+
+	// BeforeWrite, when set, is called with the target of each file
+	// before it is written; an error stops the extraction
+	BeforeWrite func(path string) error
+}
+
+// CreateRequest describes an archive to create.
+type CreateRequest struct {
+	Archive   string
+	Sources   []string // Files or directories, stored under their base names
+	Format    string   // Detected from the archive name when empty
+	Overwrite bool
+}
+
+// Result describes a created or extracted archive.
+type Result struct {
+	Archive string   `json:"archive"`
+	Dest    string   `json:"dest,omitempty"`
+	Format  string   `json:"format"`
+	Entries int      `json:"entries"`
+	Size    int64    `json:"size"`              // Uncompressed bytes
+	Skipped []string `json:"skipped,omitempty"` // Links and special files
+}
+
+// Archiver creates and extracts archives within the configured limits.
+type Archiver struct {
+	config *config.Config
+	logger *logger.Logger
+}
+
+// New creates an archiver.
+func New(cfg *config.Config, log *logger.Logger) *Archiver {
+	return &Archiver{
+		config: cfg,
+		logger: log,
+	}
+}
+
+// detectFormat returns the requested format or the one implied by name.
+func detectFormat(format, name string) (string, error) {
+	switch format {
+	case FormatZip, FormatTarGz:
+		return format, nil
+	case "tgz":
+		return FormatTarGz, nil
+	case "":
+	default:
+		return "", apperrors.ValidationError(
+			fmt.Sprintf("unsupported format %q (expected zip or tar.gz)", format), "format")
+	}
+
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return FormatZip, nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return FormatTarGz, nil
+	default:
+		return "", apperrors.ValidationError("cannot detect archive format from name; set format", "format")
+	}
+}
+
+// checkPath validates a local path against the security settings. Paths
+// written to are checked like every file change (see
+// config.CheckWritePath).
+func (a *Archiver) checkPath(path, field string, write bool) error {
+	if path == "" {
+		return apperrors.ValidationError(field+" is required", field)
+	}
+	if !filepath.IsAbs(path) {
+		return apperrors.ValidationError(field+" must be an absolute path", field)
+	}
+	if write {
+		return a.config.CheckWritePath(path)
+	}
+	if !a.config.IsPathAllowed(path) {
+		return apperrors.PermissionError("path not allowed: "+path, path)
+	}
+	return nil
+}
+
+// budget tracks the entry count and size limits of one archive.
+type budget struct {
+	maxEntries int
+	maxSize    int64
+	entries    int
+	size       int64
+}
+
+func (a *Archiver) newBudget() *budget {
+	return &budget{
+		maxEntries: a.config.Archive.MaxEntries,
+		maxSize:    int64(a.config.Archive.MaxSize),
+	}
+}
+
+// entry counts an entry against the limit.
+func (b *budget) entry() error {
+	b.entries++
+	if b.maxEntries > 0 && b.entries > b.maxEntries {
+		return apperrors.ValidationError(
+			fmt.Sprintf("archive exceeds the maximum of %d entries", b.maxEntries), "archive")
+	}
+	return nil
+}
+
+// copy copies r to w, failing once the total size exceeds the limit
+// regardless of the sizes recorded in the archive headers.
+func (b *budget) copy(w io.Writer, r io.Reader) error {
+	if b.maxSize > 0 {
+		r = io.LimitReader(r, b.maxSize-b.size+1)
+	}
+
+	n, err := io.Copy(w, r)
+	b.size += n
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to copy archive entry")
+	}
+	if b.maxSize > 0 && b.size > b.maxSize {
+		return apperrors.ValidationError(
+			fmt.Sprintf("archive exceeds the maximum size of %d bytes", b.maxSize), "archive")
+	}
+	return nil
+}
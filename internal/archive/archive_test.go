@@ -0,0 +1,278 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// testArchiver returns an archiver allowed to write in dir.
+func testArchiver(dir string, modify func(cfg *config.Config)) *Archiver {
+	cfg := config.Default()
+	cfg.Security.AllowedPaths = []string{dir}
+	if modify != nil {
+		modify(cfg)
+	}
+	return New(cfg, logger.Default())
+}
+
+// writeTree creates project/{a.txt,sub/b.txt} under dir.
+func writeTree(t *testing.T, dir string) string {
+	t.Helper()
+
+	root := filepath.Join(dir, "project")
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("alpha"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "sub", "b.txt"), []byte("bravo"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestArchiver_RoundTrip(t *testing.T) {
+	for _, name := range []string{"out.zip", "out.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			root := writeTree(t, dir)
+			a := testArchiver(dir, nil)
+
+			archivePath := filepath.Join(dir, name)
+			created, err := a.Create(CreateRequest{Archive: archivePath, Sources: []string{root}})
+			if err != nil {
+				t.Fatalf("create failed: %v", err)
+			}
+			if created.Entries != 4 || created.Size != 10 {
+				t.Errorf("unexpected create result: %+v", created)
+			}
+
+			dest := filepath.Join(dir, "dest")
+			extracted, err := a.Extract(ExtractRequest{Archive: archivePath, Dest: dest})
+			if err != nil {
+				t.Fatalf("extract failed: %v", err)
+			}
+			if extracted.Entries != 4 || extracted.Size != 10 {
+				t.Errorf("unexpected extract result: %+v", extracted)
+			}
+
+			data, err := os.ReadFile(filepath.Join(dest, "project", "sub", "b.txt"))
+			if err != nil || string(data) != "bravo" {
+				t.Errorf("unexpected extracted contents %q (%v)", data, err)
+			}
+
+			if _, err := a.Extract(ExtractRequest{Archive: archivePath, Dest: dest}); err == nil {
+				t.Error("expected error extracting over existing files")
+			}
+			var written []string
+			beforeWrite := func(path string) error {
+				written = append(written, path)
+				return nil
+			}
+			if _, err := a.Extract(ExtractRequest{Archive: archivePath, Dest: dest, Overwrite: true, BeforeWrite: beforeWrite}); err != nil {
+				t.Errorf("unexpected error with overwrite: %v", err)
+			}
+			if len(written) != 2 {
+				t.Errorf("expected BeforeWrite for 2 files, got %v", written)
+			}
+			if _, err := a.Create(CreateRequest{Archive: archivePath, Sources: []string{root}}); err == nil {
+				t.Error("expected error creating over existing archive")
+			}
+		})
+	}
+}
+
+func TestArchiver_Limits(t *testing.T) {
+	dir := t.TempDir()
+	root := writeTree(t, dir)
+	archivePath := filepath.Join(dir, "out.tar.gz")
+	if _, err := testArchiver(dir, nil).Create(CreateRequest{Archive: archivePath, Sources: []string{root}}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(cfg *config.Config)
+	}{
+		{"too many entries", func(cfg *config.Config) { cfg.Archive.MaxEntries = 2 }},
+		{"too large", func(cfg *config.Config) { cfg.Archive.MaxSize = 6 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := testArchiver(dir, tt.modify)
+			if _, err := a.Extract(ExtractRequest{Archive: archivePath, Dest: filepath.Join(dir, "x")}); err == nil {
+				t.Error("expected extract error")
+			}
+			if _, err := a.Create(CreateRequest{Archive: filepath.Join(dir, "y.zip"), Sources: []string{root}}); err == nil {
+				t.Error("expected create error")
+			}
+			if _, err := os.Stat(filepath.Join(dir, "y.zip")); !os.IsNotExist(err) {
+				t.Error("expected no archive after failed create")
+			}
+		})
+	}
+}
+
+func TestArchiver_ZipSlip(t *testing.T) {
+	dir := t.TempDir()
+
+	zipPath := filepath.Join(dir, "evil.zip")
+	f, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("../escaped.txt")
+	_, _ = w.Write([]byte("x"))
+	zw.Close()
+	f.Close()
+
+	tarPath := filepath.Join(dir, "evil.tar.gz")
+	f, err = os.Create(tarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	_ = tw.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc"})
+	_ = tw.WriteHeader(&tar.Header{Name: "/abs.txt", Typeflag: tar.TypeReg, Mode: 0o644, Size: 1})
+	_, _ = tw.Write([]byte("x"))
+	tw.Close()
+	gz.Close()
+	f.Close()
+
+	a := testArchiver(dir, nil)
+	dest := filepath.Join(dir, "dest")
+
+	if _, err := a.Extract(ExtractRequest{Archive: zipPath, Dest: dest}); err == nil || !strings.Contains(err.Error(), "escapes") {
+		t.Errorf("expected zip slip error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escaped.txt")); !os.IsNotExist(err) {
+		t.Error("file written outside destination")
+	}
+
+	if _, err := a.Extract(ExtractRequest{Archive: tarPath, Dest: dest}); err == nil || !strings.Contains(err.Error(), "absolute") {
+		t.Errorf("expected absolute path error, got %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(dest, "link")); !os.IsNotExist(err) {
+		t.Error("symlink entry should be skipped")
+	}
+
+	// Links already inside the destination must not redirect writes
+	outside := filepath.Join(dir, "outside")
+	if err := os.Mkdir(outside, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	linked := filepath.Join(dir, "linked")
+	if err := os.MkdirAll(linked, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(linked, "project")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	root := writeTree(t, filepath.Join(dir, "src"))
+	archivePath := filepath.Join(dir, "project.zip")
+	if _, err := a.Create(CreateRequest{Archive: archivePath, Sources: []string{root}}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if _, err := a.Extract(ExtractRequest{Archive: archivePath, Dest: linked}); err == nil {
+		t.Error("expected error extracting through a link")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "a.txt")); !os.IsNotExist(err) {
+		t.Error("file written through link")
+	}
+
+	// Nor create directories through them before the check
+	nestedPath := filepath.Join(dir, "nested.zip")
+	f, err = os.Create(nestedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw = zip.NewWriter(f)
+	w, _ = zw.Create("project/new/c.txt")
+	_, _ = w.Write([]byte("x"))
+	zw.Close()
+	f.Close()
+	if _, err := a.Extract(ExtractRequest{Archive: nestedPath, Dest: linked}); err == nil {
+		t.Error("expected error extracting through a link")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "new")); !os.IsNotExist(err) {
+		t.Error("directory created through link")
+	}
+}
+
+func TestArchiver_Validation(t *testing.T) {
+	dir := t.TempDir()
+	root := writeTree(t, dir)
+	a := testArchiver(dir, nil)
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"relative archive", func() error {
+			_, err := a.Create(CreateRequest{Archive: "out.zip", Sources: []string{root}})
+			return err
+		}},
+		{"unknown format", func() error {
+			_, err := a.Create(CreateRequest{Archive: filepath.Join(dir, "out.rar"), Sources: []string{root}})
+			return err
+		}},
+		{"no sources", func() error {
+			_, err := a.Create(CreateRequest{Archive: filepath.Join(dir, "out.zip")})
+			return err
+		}},
+		{"source not allowed", func() error {
+			_, err := a.Create(CreateRequest{Archive: filepath.Join(dir, "out.zip"), Sources: []string{os.TempDir()}})
+			return err
+		}},
+		{"dest not allowed", func() error {
+			_, err := a.Extract(ExtractRequest{Archive: filepath.Join(dir, "out.zip"), Dest: "/nonexistent"})
+			return err
+		}},
+		{"no allowed paths", func() error {
+			_, err := New(config.Default(), logger.Default()).Extract(ExtractRequest{Archive: filepath.Join(dir, "out.zip"), Dest: filepath.Join(dir, "dest")})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		name   string
+		want   string
+	}{
+		{"", "a.zip", FormatZip},
+		{"", "a.ZIP", FormatZip},
+		{"", "a.tar.gz", FormatTarGz},
+		{"", "a.tgz", FormatTarGz},
+		{"tgz", "a", FormatTarGz},
+		{"zip", "a.bin", FormatZip},
+	}
+
+	for _, tt := range tests {
+		got, err := detectFormat(tt.format, tt.name)
+		if err != nil || got != tt.want {
+			t.Errorf("detectFormat(%q, %q) = %q, %v; want %q", tt.format, tt.name, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// entryWriter adds entries to an archive.
+type entryWriter interface {
+	dir(name string, info fs.FileInfo) error
+	file(name string, info fs.FileInfo) (io.Writer, error)
+	close() error
+}
+
+// Create packs files and directories into a new archive. Links and
+// special files are skipped. The archive is written to a temporary file
+// and only moved into place when complete.
+func (a *Archiver) Create(req CreateRequest) (*Result, error) {
+	if err := a.checkPath(req.Archive, "archive", true); err != nil {
+		return nil, err
+	}
+	if len(req.Sources) == 0 {
+		return nil, apperrors.ValidationError("at least one source is required", "sources")
+	}
+	for _, src := range req.Sources {
+		if err := a.checkPath(src, "sources", false); err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(src); err != nil {
+			return nil, apperrors.NotFoundError("source not found: "+src, src)
+		}
+	}
+
+	format, err := detectFormat(req.Format, req.Archive)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(req.Archive); err == nil && !req.Overwrite {
+		return nil, apperrors.ValidationError("archive exists (set overwrite to replace it)", "archive")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(req.Archive), ".archive-*")
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to create archive")
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	var w entryWriter
+	if format == FormatZip {
+		w = &zipWriter{zw: zip.NewWriter(tmp)}
+	} else {
+		gz := gzip.NewWriter(tmp)
+		w = &tarWriter{gz: gz, tw: tar.NewWriter(gz)}
+	}
+
+	b := a.newBudget()
+	result := &Result{Archive: req.Archive, Format: format}
+	skip := map[string]bool{tmpName: true, filepath.Clean(req.Archive): true}
+
+	for _, src := range req.Sources {
+		if err := addSource(w, b, result, filepath.Clean(src), skip); err != nil {
+			tmp.Close()
+			return nil, err
+		}
+	}
+
+	err = w.close()
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to write archive")
+	}
+
+	if err := os.Chmod(tmpName, 0o644); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to set archive mode")
+	}
+	if err := os.Rename(tmpName, req.Archive); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to move archive into place")
+	}
+
+	result.Entries = b.entries
+	result.Size = b.size
+
+	a.logger.Info("created archive", "archive", req.Archive, "entries", result.Entries)
+
+	return result, nil
+}
+
+// addSource walks a source and adds its entries under its base name.
+func addSource(w entryWriter, b *budget, result *Result, src string, skip map[string]bool) error {
+	base := filepath.Dir(src)
+
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to read "+path)
+		}
+		if skip[path] {
+			return nil
+		}
+
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to name entry")
+		}
+		name := filepath.ToSlash(rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to stat "+path)
+		}
+
+		switch {
+		case info.IsDir():
+			if err := b.entry(); err != nil {
+				return err
+			}
+			return w.dir(name+"/", info)
+
+		case info.Mode().IsRegular():
+			if err := b.entry(); err != nil {
+				return err
+			}
+			out, err := w.file(name, info)
+			if err != nil {
+				return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to add "+name)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to open "+path)
+			}
+			defer f.Close()
+			return b.copy(out, f)
+
+		default:
+			result.Skipped = append(result.Skipped, name)
+			return nil
+		}
+	})
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (z *zipWriter) dir(name string, info fs.FileInfo) error {
+	hdr, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	_, err = z.zw.CreateHeader(hdr)
+	return err
+}
+
+func (z *zipWriter) file(name string, info fs.FileInfo) (io.Writer, error) {
+	hdr, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return nil, err
+	}
+	hdr.Name = name
+	hdr.Method = zip.Deflate
+	return z.zw.CreateHeader(hdr)
+}
+
+func (z *zipWriter) close() error {
+	return z.zw.Close()
+}
+
+type tarWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func (t *tarWriter) dir(name string, info fs.FileInfo) error {
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	return t.tw.WriteHeader(hdr)
+}
+
+func (t *tarWriter) file(name string, info fs.FileInfo) (io.Writer, error) {
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return nil, err
+	}
+	hdr.Name = name
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return nil, err
+	}
+	return t.tw, nil
+}
+
+func (t *tarWriter) close() error {
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
+	return t.gz.Close()
+}
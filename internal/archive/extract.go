@@ -0,0 +1,250 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// Extract unpacks an archive into a directory. Entries that would be
+// written outside the destination are rejected; links and special files
+// are skipped. Extraction stops at the first error.
+func (a *Archiver) Extract(req ExtractRequest) (*Result, error) {
+	if err := a.checkPath(req.Archive, "archive", false); err != nil {
+		return nil, err
+	}
+	if err := a.checkPath(req.Dest, "dest", true); err != nil {
+		return nil, err
+	}
+
+	format, err := detectFormat(req.Format, req.Archive)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(req.Dest, 0o755); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to create destination")
+	}
+	dest, err := config.ResolvePath(req.Dest)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to resolve destination")
+	}
+
+	x := &extractor{
+		dest:        dest.Resolved,
+		overwrite:   req.Overwrite,
+		beforeWrite: req.BeforeWrite,
+		budget:      a.newBudget(),
+		result:      &Result{Archive: req.Archive, Dest: req.Dest, Format: format},
+	}
+
+	switch format {
+	case FormatZip:
+		err = x.zip(req.Archive)
+	default:
+		err = x.tarGz(req.Archive)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	x.result.Entries = x.budget.entries
+	x.result.Size = x.budget.size
+
+	a.logger.Info("extracted archive", "archive", req.Archive, "dest", req.Dest, "entries", x.result.Entries)
+
+	return x.result, nil
+}
+
+// extractor holds the state of one extraction.
+type extractor struct {
+	dest        string // Destination with symlinks resolved
+	overwrite   bool
+	beforeWrite func(path string) error
+	budget      *budget
+	result      *Result
+}
+
+func (x *extractor) zip(path string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeValidation, "failed to open zip archive")
+	}
+	defer r.Close()
+
+	// The central directory lets oversized archives fail before writing
+	if max := x.budget.maxEntries; max > 0 && len(r.File) > max {
+		return apperrors.ValidationError(
+			fmt.Sprintf("archive has %d entries, more than the maximum of %d", len(r.File), max), "archive")
+	}
+	var declared uint64
+	for _, f := range r.File {
+		declared += f.UncompressedSize64
+	}
+	if max := x.budget.maxSize; max > 0 && declared > uint64(max) {
+		return apperrors.ValidationError(
+			fmt.Sprintf("archive expands to %d bytes, more than the maximum of %d", declared, max), "archive")
+	}
+
+	for _, f := range r.File {
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			if err := x.dir(f.Name); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			rc, err := f.Open()
+			if err != nil {
+				return apperrors.Wrap(err, apperrors.ErrorTypeValidation, "failed to read "+f.Name)
+			}
+			err = x.file(f.Name, mode, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			x.result.Skipped = append(x.result.Skipped, f.Name)
+		}
+	}
+
+	return nil
+}
+
+func (x *extractor) tarGz(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeNotFound, "failed to open archive")
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeValidation, "failed to open tar.gz archive")
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return apperrors.Wrap(err, apperrors.ErrorTypeValidation, "failed to read tar archive")
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := x.dir(hdr.Name); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := x.file(hdr.Name, hdr.FileInfo().Mode(), tr); err != nil {
+				return err
+			}
+		case tar.TypeXGlobalHeader:
+			// PAX metadata, not an entry
+		default:
+			x.result.Skipped = append(x.result.Skipped, hdr.Name)
+		}
+	}
+}
+
+// dir creates a directory entry.
+func (x *extractor) dir(name string) error {
+	if err := x.budget.entry(); err != nil {
+		return err
+	}
+
+	target, err := x.target(name)
+	if err != nil {
+		return err
+	}
+	return x.mkdir(target)
+}
+
+// file writes a regular file entry.
+func (x *extractor) file(name string, mode os.FileMode, r io.Reader) error {
+	if err := x.budget.entry(); err != nil {
+		return err
+	}
+
+	target, err := x.target(name)
+	if err != nil {
+		return err
+	}
+	if err := x.mkdir(filepath.Dir(target)); err != nil {
+		return err
+	}
+
+	if info, err := os.Lstat(target); err == nil {
+		if !x.overwrite {
+			return apperrors.ValidationError("file exists (set overwrite to replace it): "+name, "dest")
+		}
+		if !info.Mode().IsRegular() {
+			return apperrors.PermissionError("refusing to replace non-regular file: "+name, target)
+		}
+	}
+
+	if x.beforeWrite != nil {
+		if err := x.beforeWrite(target); err != nil {
+			return err
+		}
+	}
+
+	perm := mode.Perm()
+	if perm == 0 {
+		perm = 0o644
+	}
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to create "+name)
+	}
+	copyErr := x.budget.copy(out, r)
+	if err := out.Close(); copyErr == nil && err != nil {
+		copyErr = apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to write "+name)
+	}
+	return copyErr
+}
+
+// target resolves an entry name inside the destination, rejecting
+// absolute names and names that escape it (zip slip).
+func (x *extractor) target(name string) (string, error) {
+	clean := filepath.FromSlash(name)
+	if filepath.IsAbs(clean) || filepath.VolumeName(clean) != "" || strings.HasPrefix(name, "/") {
+		return "", apperrors.PermissionError("archive entry has an absolute path: "+name, name)
+	}
+
+	target := filepath.Join(x.dest, clean)
+	if !config.Within(x.dest, target) {
+		return "", apperrors.PermissionError("archive entry escapes the destination: "+name, name)
+	}
+	return target, nil
+}
+
+// mkdir creates a directory and its parents once the directory, with the
+// symlinks of its existing parents resolved, is known to be within the
+// destination, so links already on disk cannot redirect writes.
+func (x *extractor) mkdir(dir string) error {
+	resolved, err := config.ResolvePath(dir)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to resolve path")
+	}
+	if !config.Within(x.dest, resolved.Resolved) {
+		return apperrors.PermissionError("archive entry escapes the destination through a link", dir)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to create directory")
+	}
+	return nil
+}
@@ -0,0 +1,391 @@
+// Package audit exports the execution history for compliance review and
+// security tooling
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// Export formats.
+const (
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+	FormatSARIF = "sarif"
+)
+
+// Exit status filters besides an exit code.
+const (
+	ExitSuccess = "success"
+	ExitFailure = "failure"
+)
+
+// Filter selects the records to export. Empty fields match every record.
+type Filter struct {
+	Since      time.Time
+	Command    string // Command as requested, its base name, or a configured command name
+	Session    string
+	ExitStatus string // success, failure, or an exit code
+	Decision   string // allowed or denied
+}
+
+// validate checks the filter values.
+func (f Filter) validate() error {
+	switch f.ExitStatus {
+	case "", ExitSuccess, ExitFailure:
+	default:
+		if _, err := strconv.Atoi(f.ExitStatus); err != nil {
+			return apperrors.ValidationError(
+				fmt.Sprintf("invalid exit status %q (must be: success, failure, or an exit code)", f.ExitStatus), "exit-status")
+		}
+	}
+	switch f.Decision {
+	case "", types.PolicyDecisionAllowed, types.PolicyDecisionDenied:
+	default:
+		return apperrors.ValidationError(
+			fmt.Sprintf("invalid decision %q (must be: allowed, denied)", f.Decision), "decision")
+	}
+	return nil
+}
+
+// Matches reports whether a record passes the filter.
+func (f Filter) Matches(rec types.ExecutionRecord) bool {
+	if !f.Since.IsZero() && rec.Timestamp.Before(f.Since) {
+		return false
+	}
+	if f.Command != "" && rec.Request.Command != f.Command &&
+		filepath.Base(rec.Request.Command) != f.Command && rec.Tool != f.Command {
+		return false
+	}
+	if f.Session != "" && rec.Session != f.Session {
+		return false
+	}
+	if f.Decision != "" && Decision(rec) != f.Decision {
+		return false
+	}
+
+	switch f.ExitStatus {
+	case "":
+	case ExitSuccess:
+		return Succeeded(rec)
+	case ExitFailure:
+		return !Succeeded(rec)
+	default:
+		code, _ := strconv.Atoi(f.ExitStatus)
+		return rec.Result != nil && rec.Result.ExitCode == code
+	}
+	return true
+}
+
+// Select returns the records passing the filter, in their original order.
+func Select(records []types.ExecutionRecord, f Filter) ([]types.ExecutionRecord, error) {
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+
+	var out []types.ExecutionRecord
+	for _, rec := range records {
+		if f.Matches(rec) {
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
+
+// ParseSince parses a --since value, either a duration before now such as
+// 24h or an RFC 3339 time.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, apperrors.ValidationError(
+		fmt.Sprintf("invalid since %q (must be a duration such as 24h or an RFC 3339 time)", s), "since")
+}
+
+// Decision returns the policy decision of a record. Records written
+// before decisions were recorded count as allowed if the command ran.
+func Decision(rec types.ExecutionRecord) string {
+	if rec.Decision != "" {
+		return rec.Decision
+	}
+	if rec.Result != nil {
+		return types.PolicyDecisionAllowed
+	}
+	return ""
+}
+
+// Succeeded reports whether a record's command ran and exited zero.
+func Succeeded(rec types.ExecutionRecord) bool {
+	return rec.Error == "" && rec.Result != nil && rec.Result.ExitCode == 0 && !rec.Result.TimedOut
+}
+
+// Write exports records in the given format. Version is reported as the
+// tool version in SARIF.
+func Write(w io.Writer, format string, records []types.ExecutionRecord, version string) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, records)
+	case FormatCSV:
+		return writeCSV(w, records)
+	case FormatSARIF:
+		return writeSARIF(w, records, version)
+	default:
+		return apperrors.ValidationError(
+			fmt.Sprintf("invalid format %q (must be: json, csv, sarif)", format), "format")
+	}
+}
+
+// writeJSON writes the records as a JSON array.
+func writeJSON(w io.Writer, records []types.ExecutionRecord) error {
+	if records == nil {
+		records = []types.ExecutionRecord{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(records); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to write JSON export")
+	}
+	return nil
+}
+
+// csvHeader lists the columns of the CSV export.
+var csvHeader = []string{
+	"id", "timestamp", "source", "tool", "schedule", "session", "client", "user",
+	"command", "args", "workdir", "decision", "exit_code", "timed_out", "duration_ms", "error",
+	"tripwire", "screening",
+}
+
+// writeCSV writes one row per record. Arguments are a JSON array so they
+// survive the round trip; the exit code is empty for commands that did
+// not run.
+func writeCSV(w io.Writer, records []types.ExecutionRecord) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to write CSV export")
+	}
+
+	for _, rec := range records {
+		var args string
+		if len(rec.Request.Args) > 0 {
+			data, _ := json.Marshal(rec.Request.Args)
+			args = string(data)
+		}
+		var exitCode, timedOut, duration string
+		if r := rec.Result; r != nil {
+			exitCode = strconv.Itoa(r.ExitCode)
+			timedOut = strconv.FormatBool(r.TimedOut)
+			duration = strconv.FormatInt(r.Duration.Milliseconds(), 10)
+		}
+		row := []string{
+			rec.ID, rec.Timestamp.UTC().Format(time.RFC3339), rec.Source, rec.Tool, rec.Schedule,
+			rec.Session, rec.Client, rec.User, rec.Request.Command, args, rec.Request.WorkDir,
+			Decision(rec), exitCode, timedOut, duration, rec.Error,
+			rec.Tripwire, rec.Screening,
+		}
+		if err := cw.Write(row); err != nil {
+			return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to write CSV export")
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to write CSV export")
+	}
+	return nil
+}
+
+// SARIF rules records are reported under.
+const (
+	ruleTripwire  = "tripwire"
+	ruleScreening = "screening-flagged"
+	ruleDenied    = "policy-denied"
+	ruleFailed    = "execution-failed"
+	ruleSucceeded = "execution-succeeded"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string         `json:"id"`
+	ShortDescription sarifMessage   `json:"shortDescription"`
+	Properties       map[string]any `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	Level      string          `json:"level"`
+	Kind       string          `json:"kind"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations,omitempty"`
+	Properties map[string]any  `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// writeSARIF writes the records as SARIF 2.1.0 results: tripped
+// tripwires, requests flagged by screening and policy denials as errors,
+// failed runs as warnings and successful runs as notes, so security
+// tooling can triage them by level. Tripwires and flagged requests also
+// carry a security-severity.
+func writeSARIF(w io.Writer, records []types.ExecutionRecord, version string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "simple-mcp-runner",
+			Version:        version,
+			InformationURI: "https://github.com/mjmorales/simple-mcp-runner",
+			Rules: []sarifRule{
+				{
+					ID:               ruleTripwire,
+					ShortDescription: sarifMessage{Text: "A client requested a tripwire command"},
+					Properties:       map[string]any{"security-severity": "9.0"},
+				},
+				{
+					ID:               ruleScreening,
+					ShortDescription: sarifMessage{Text: "Screening flagged a request as a possible prompt injection"},
+					Properties:       map[string]any{"security-severity": "7.0"},
+				},
+				{ID: ruleDenied, ShortDescription: sarifMessage{Text: "The security policy denied a command"}},
+				{ID: ruleFailed, ShortDescription: sarifMessage{Text: "A command failed, timed out or exited non-zero"}},
+				{ID: ruleSucceeded, ShortDescription: sarifMessage{Text: "A command ran and exited zero"}},
+			},
+		}},
+		Results: []sarifResult{},
+	}
+
+	for _, rec := range records {
+		run.Results = append(run.Results, sarifRecord(rec))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to write SARIF export")
+	}
+	return nil
+}
+
+// sarifRecord describes one record as a SARIF result.
+func sarifRecord(rec types.ExecutionRecord) sarifResult {
+	commandLine := strings.TrimSpace(rec.Request.Command + " " + strings.Join(rec.Request.Args, " "))
+	result := sarifResult{
+		RuleID: ruleSucceeded,
+		Level:  "none",
+		Kind:   "informational",
+		Properties: map[string]any{
+			"historyId": rec.ID,
+			"timestamp": rec.Timestamp.UTC().Format(time.RFC3339),
+			"source":    rec.Source,
+			"command":   rec.Request.Command,
+			"args":      rec.Request.Args,
+			"decision":  Decision(rec),
+		},
+	}
+
+	switch {
+	case rec.Tripwire != "":
+		result.RuleID, result.Level, result.Kind = ruleTripwire, "error", "fail"
+		result.Message.Text = fmt.Sprintf("Tripwire %s: %s", rec.Tripwire, commandLine)
+		result.Properties["tripwire"] = rec.Tripwire
+	case rec.Screening != "":
+		result.RuleID, result.Level, result.Kind = ruleScreening, "error", "fail"
+		result.Message.Text = fmt.Sprintf("Flagged by %s: %s: %s", rec.Screening, commandLine, rec.Error)
+		result.Properties["screening"] = rec.Screening
+	case Decision(rec) == types.PolicyDecisionDenied:
+		result.RuleID, result.Level, result.Kind = ruleDenied, "error", "fail"
+		result.Message.Text = fmt.Sprintf("Denied: %s: %s", commandLine, rec.Error)
+	case !Succeeded(rec):
+		result.RuleID, result.Level, result.Kind = ruleFailed, "warning", "fail"
+		result.Message.Text = "Failed: " + commandLine
+		switch {
+		case rec.Error != "":
+			result.Message.Text += ": " + rec.Error
+		case rec.Result == nil:
+		case rec.Result.TimedOut:
+			result.Message.Text += ": timed out"
+		default:
+			result.Message.Text += fmt.Sprintf(": exit code %d", rec.Result.ExitCode)
+		}
+	default:
+		result.Message.Text = "Ran: " + commandLine
+	}
+
+	if rec.Result != nil {
+		result.Properties["exitCode"] = rec.Result.ExitCode
+		result.Properties["timedOut"] = rec.Result.TimedOut
+	}
+	for key, value := range map[string]string{
+		"tool": rec.Tool, "schedule": rec.Schedule, "session": rec.Session,
+		"client": rec.Client, "user": rec.User, "workdir": rec.Request.WorkDir,
+	} {
+		if value != "" {
+			result.Properties[key] = value
+		}
+	}
+
+	if rec.Request.WorkDir != "" {
+		uri := url.URL{Scheme: "file", Path: filepath.ToSlash(rec.Request.WorkDir)}
+		if !strings.HasPrefix(uri.Path, "/") {
+			// Windows drive paths
+			uri.Path = "/" + uri.Path
+		}
+		result.Locations = []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: uri.String()}},
+		}}
+	}
+	return result
+}
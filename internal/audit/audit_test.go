@@ -0,0 +1,221 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRecords(now time.Time) []types.ExecutionRecord {
+	return []types.ExecutionRecord{
+		{
+			ID: "h1", Source: types.ExecutionSourceTool, Tool: "execute_command",
+			Session: "s1", Client: "claude", Decision: types.PolicyDecisionAllowed,
+			Request:   types.CommandExecutionRequest{Command: "/usr/bin/git", Args: []string{"status"}, WorkDir: "/repo"},
+			Result:    &types.CommandExecutionResult{ExitCode: 0, Duration: 15 * time.Millisecond},
+			Timestamp: now.Add(-48 * time.Hour),
+		},
+		{
+			ID: "h2", Source: types.ExecutionSourceTool, Tool: "execute_command",
+			Session: "s1", Decision: types.PolicyDecisionDenied,
+			Request:   types.CommandExecutionRequest{Command: "rm", Args: []string{"-rf", "/"}},
+			Error:     "permission: command blocked: rm",
+			Timestamp: now.Add(-2 * time.Hour),
+		},
+		{
+			ID: "h3", Source: types.ExecutionSourceSchedule, Tool: "tests", Schedule: "nightly",
+			Request:   types.CommandExecutionRequest{Command: "go", Args: []string{"test", "./..."}},
+			Result:    &types.CommandExecutionResult{ExitCode: 1},
+			Timestamp: now.Add(-time.Hour),
+		},
+	}
+}
+
+func ids(records []types.ExecutionRecord) []string {
+	var out []string
+	for _, rec := range records {
+		out = append(out, rec.ID)
+	}
+	return out
+}
+
+func TestSelect(t *testing.T) {
+	now := time.Now()
+	records := testRecords(now)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"no filter", Filter{}, []string{"h1", "h2", "h3"}},
+		{"since", Filter{Since: now.Add(-24 * time.Hour)}, []string{"h2", "h3"}},
+		{"command base name", Filter{Command: "git"}, []string{"h1"}},
+		{"configured command name", Filter{Command: "tests"}, []string{"h3"}},
+		{"session", Filter{Session: "s1"}, []string{"h1", "h2"}},
+		{"success", Filter{ExitStatus: ExitSuccess}, []string{"h1"}},
+		{"failure", Filter{ExitStatus: ExitFailure}, []string{"h2", "h3"}},
+		{"exit code", Filter{ExitStatus: "1"}, []string{"h3"}},
+		{"denied", Filter{Decision: types.PolicyDecisionDenied}, []string{"h2"}},
+		{"allowed counts records without a decision", Filter{Decision: types.PolicyDecisionAllowed}, []string{"h1", "h3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Select(records, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ids(got))
+		})
+	}
+
+	_, err := Select(records, Filter{ExitStatus: "sometimes"})
+	assert.Error(t, err)
+	_, err = Select(records, Filter{Decision: "maybe"})
+	assert.Error(t, err)
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+
+	got, err := ParseSince("24h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-24*time.Hour), got)
+
+	got, err = ParseSince("2025-06-01T08:00:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC), got)
+
+	got, err = ParseSince("", now)
+	require.NoError(t, err)
+	assert.True(t, got.IsZero())
+
+	_, err = ParseSince("yesterday", now)
+	assert.Error(t, err)
+}
+
+func TestWrite(t *testing.T) {
+	records := testRecords(time.Now())
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatJSON, records, "dev"))
+
+		var got []types.ExecutionRecord
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, []string{"h1", "h2", "h3"}, ids(got))
+	})
+
+	t.Run("empty json is an array", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatJSON, nil, "dev"))
+		assert.Equal(t, "[]\n", buf.String())
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatCSV, records, "dev"))
+
+		rows, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 4)
+		assert.Equal(t, csvHeader, rows[0])
+
+		row := map[string]string{}
+		for i, column := range rows[0] {
+			row[column] = rows[2][i]
+		}
+		assert.Equal(t, "h2", row["id"])
+		assert.Equal(t, `["-rf","/"]`, row["args"])
+		assert.Equal(t, "denied", row["decision"])
+		assert.Equal(t, "", row["exit_code"])
+
+		for i, column := range rows[0] {
+			row[column] = rows[1][i]
+		}
+		assert.Equal(t, "0", row["exit_code"])
+		assert.Equal(t, "15", row["duration_ms"])
+	})
+
+	t.Run("sarif", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatSARIF, records, "dev"))
+
+		var log sarifLog
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+		assert.Equal(t, "2.1.0", log.Version)
+		require.Len(t, log.Runs, 1)
+		assert.Equal(t, "dev", log.Runs[0].Tool.Driver.Version)
+
+		results := log.Runs[0].Results
+		require.Len(t, results, 3)
+		assert.Equal(t, ruleSucceeded, results[0].RuleID)
+		assert.Equal(t, "none", results[0].Level)
+		assert.Equal(t, "file:///repo", results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		assert.Equal(t, ruleDenied, results[1].RuleID)
+		assert.Equal(t, "error", results[1].Level)
+		assert.Contains(t, results[1].Message.Text, "rm -rf /")
+		assert.Equal(t, ruleFailed, results[2].RuleID)
+		assert.Equal(t, "warning", results[2].Level)
+		assert.Contains(t, results[2].Message.Text, "exit code 1")
+		assert.Equal(t, "nightly", results[2].Properties["schedule"])
+	})
+
+	t.Run("tripwire", func(t *testing.T) {
+		rec := types.ExecutionRecord{
+			ID: "h4", Source: types.ExecutionSourceTool, Tool: "execute_command",
+			Decision: types.PolicyDecisionDenied, Tripwire: "shadow",
+			Request: types.CommandExecutionRequest{Command: "cat", Args: []string{"/etc/shadow"}},
+			Error:   "permission: command not allowed: cat",
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatSARIF, []types.ExecutionRecord{rec}, "dev"))
+		var log sarifLog
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+		result := log.Runs[0].Results[0]
+		assert.Equal(t, ruleTripwire, result.RuleID)
+		assert.Equal(t, "error", result.Level)
+		assert.Equal(t, "Tripwire shadow: cat /etc/shadow", result.Message.Text)
+		assert.Equal(t, "9.0", log.Runs[0].Tool.Driver.Rules[0].Properties["security-severity"])
+
+		buf.Reset()
+		require.NoError(t, Write(&buf, FormatCSV, []types.ExecutionRecord{rec}, "dev"))
+		rows, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, "shadow", rows[1][len(rows[1])-2])
+	})
+
+	t.Run("screening", func(t *testing.T) {
+		rec := types.ExecutionRecord{
+			ID: "h5", Source: types.ExecutionSourceTool, Tool: "execute_command",
+			Decision: types.PolicyDecisionDenied, Screening: "shell_rc_write",
+			Request: types.CommandExecutionRequest{Command: "tee", Args: []string{"-a", "/home/dev/.bashrc"}},
+			Error:   "permission: flagged by screening (shell_rc_write): may write to shell startup file /home/dev/.bashrc",
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatSARIF, []types.ExecutionRecord{rec}, "dev"))
+		var log sarifLog
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+		result := log.Runs[0].Results[0]
+		assert.Equal(t, ruleScreening, result.RuleID)
+		assert.Equal(t, "error", result.Level)
+		assert.Equal(t, "shell_rc_write", result.Properties["screening"])
+
+		buf.Reset()
+		require.NoError(t, Write(&buf, FormatCSV, []types.ExecutionRecord{rec}, "dev"))
+		rows, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, "shell_rc_write", rows[1][len(rows[1])-1])
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		assert.Error(t, Write(&bytes.Buffer{}, "xml", records, "dev"))
+	})
+}
@@ -0,0 +1,353 @@
+// Package backup keeps generational backups of files changed by tools so
+// the changes can be undone
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// manifestName is the file describing a generation.
+const manifestName = "manifest.json"
+
+// File describes one file saved in a generation.
+type File struct {
+	Path    string      `json:"path"`
+	Existed bool        `json:"existed"` // False when the change created the file
+	Mode    os.FileMode `json:"mode,omitempty"`
+	Skipped bool        `json:"skipped,omitempty"` // Too large to back up
+	Stored  string      `json:"stored,omitempty"`  // Name of the copy in the generation
+}
+
+// Change describes one generation: the files a tool call changed.
+type Change struct {
+	ID    string    `json:"id"`
+	Tool  string    `json:"tool"`
+	Time  time.Time `json:"time"`
+	Files []File    `json:"files"`
+}
+
+// UndoResult describes an undone change.
+type UndoResult struct {
+	ID       string    `json:"id"`
+	Tool     string    `json:"tool"`
+	Time     time.Time `json:"time"`
+	Restored []string  `json:"restored,omitempty"` // Files put back as they were
+	Removed  []string  `json:"removed,omitempty"`  // Files the change had created
+	Skipped  []string  `json:"skipped,omitempty"`  // Files without a backup
+}
+
+// Store keeps backups in a server-managed directory.
+type Store struct {
+	config *config.Config
+	logger *logger.Logger
+	mu     sync.Mutex
+}
+
+// New creates a backup store.
+func New(cfg *config.Config, log *logger.Logger) *Store {
+	return &Store{
+		config: cfg,
+		logger: log,
+	}
+}
+
+// Enabled reports whether backups are taken.
+func (s *Store) Enabled() bool {
+	return !s.config.Backup.Disabled
+}
+
+// dir returns the directory holding the generations.
+func (s *Store) dir() string {
+	if s.config.Backup.Dir != "" {
+		return s.config.Backup.Dir
+	}
+	return filepath.Join(config.StateDir(), "backups")
+}
+
+// Begin starts a generation for a tool call. Files are saved with Save
+// before they are changed, and the generation is kept with Commit or
+// dropped with Discard. When backups are disabled the generation does
+// nothing.
+func (s *Store) Begin(tool string) *Generation {
+	return &Generation{
+		store:  s,
+		change: Change{Tool: tool, Time: time.Now()},
+		saved:  make(map[string]bool),
+	}
+}
+
+// Generation collects the backups of one tool call.
+type Generation struct {
+	store  *Store
+	change Change
+	dir    string
+	saved  map[string]bool
+}
+
+// Save backs up a file before it is changed. Files that do not exist yet
+// are recorded so undoing the change removes them. Files larger than the
+// configured limit are recorded without a copy.
+func (g *Generation) Save(path string) error {
+	if !g.store.Enabled() {
+		return nil
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeValidation, "invalid path")
+	}
+	if g.saved[abs] {
+		return nil
+	}
+
+	file := File{Path: abs}
+
+	info, err := os.Lstat(abs)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to inspect file for backup")
+	case !info.Mode().IsRegular():
+		return apperrors.PermissionError("cannot back up non-regular file", abs)
+	case g.store.config.Backup.MaxFileSize > 0 && info.Size() > int64(g.store.config.Backup.MaxFileSize):
+		file.Existed = true
+		file.Mode = info.Mode().Perm()
+		file.Skipped = true
+		g.store.logger.Warn("file too large to back up", "path", abs, "size", info.Size())
+	default:
+		file.Existed = true
+		file.Mode = info.Mode().Perm()
+		file.Stored = strconv.Itoa(len(g.change.Files))
+		if err := g.copyIn(abs, file.Stored); err != nil {
+			return err
+		}
+	}
+
+	g.change.Files = append(g.change.Files, file)
+	g.saved[abs] = true
+	return nil
+}
+
+// ensureDir creates the generation directory on first use. Its name,
+// which starts with the time, is the generation ID.
+func (g *Generation) ensureDir() error {
+	if g.dir != "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(g.store.dir(), 0o700); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to create backup directory")
+	}
+	dir, err := os.MkdirTemp(g.store.dir(), g.change.Time.UTC().Format("20060102T150405.000000000")+"-")
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to create backup")
+	}
+	g.dir = dir
+	g.change.ID = filepath.Base(dir)
+	return nil
+}
+
+// copyIn copies a file into the generation directory.
+func (g *Generation) copyIn(path, name string) error {
+	if err := g.ensureDir(); err != nil {
+		return err
+	}
+
+	if err := copyFile(path, filepath.Join(g.dir, name), 0o600); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to back up "+path)
+	}
+	return nil
+}
+
+// Commit keeps the generation so it can be undone, then applies the
+// retention policy. A generation without files is dropped.
+func (g *Generation) Commit() error {
+	if len(g.change.Files) == 0 {
+		return nil
+	}
+
+	if err := g.ensureDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(g.change, "", "  ")
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to encode backup manifest")
+	}
+	if err := os.WriteFile(filepath.Join(g.dir, manifestName), data, 0o600); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to write backup manifest")
+	}
+
+	g.store.prune()
+	return nil
+}
+
+// Discard drops the generation, e.g. when the tool call changed nothing.
+func (g *Generation) Discard() {
+	if g.dir != "" {
+		os.RemoveAll(g.dir)
+	}
+}
+
+// List returns the retained changes, newest first.
+func (s *Store) List() ([]Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.list()
+}
+
+func (s *Store) list() ([]Change, error) {
+	entries, err := os.ReadDir(s.dir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read backup directory")
+	}
+
+	var changes []Change
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir(), entry.Name(), manifestName))
+		if err != nil {
+			// Generations still being written have no manifest yet
+			continue
+		}
+		var change Change
+		if err := json.Unmarshal(data, &change); err != nil {
+			s.logger.WithError(err).Warn("skipping unreadable backup", "id", entry.Name())
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].ID > changes[j].ID
+	})
+	return changes, nil
+}
+
+// prune removes generations beyond the configured count and age.
+func (s *Store) prune() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes, err := s.list()
+	if err != nil {
+		s.logger.WithError(err).Warn("failed to prune backups")
+		return
+	}
+
+	maxAge := s.config.Backup.MaxAge.Std()
+
+	for i, change := range changes {
+		tooMany := s.config.Backup.MaxGenerations > 0 && i >= s.config.Backup.MaxGenerations
+		tooOld := maxAge > 0 && time.Since(change.Time) > maxAge
+		if tooMany || tooOld {
+			s.remove(change.ID)
+		}
+	}
+}
+
+// remove deletes a generation.
+func (s *Store) remove(id string) {
+	if err := os.RemoveAll(filepath.Join(s.dir(), id)); err != nil {
+		s.logger.WithError(err).Warn("failed to remove backup", "id", id)
+	}
+}
+
+// UndoLast reverts the most recent change: changed files are restored,
+// files it created are removed, and the generation is dropped.
+func (s *Store) UndoLast() (*UndoResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes, err := s.list()
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, apperrors.NotFoundError("no change to undo", "backup")
+	}
+	change := changes[0]
+
+	result := &UndoResult{ID: change.ID, Tool: change.Tool, Time: change.Time}
+	dir := filepath.Join(s.dir(), change.ID)
+
+	for i := len(change.Files) - 1; i >= 0; i-- {
+		file := change.Files[i]
+		switch {
+		case file.Skipped:
+			result.Skipped = append(result.Skipped, file.Path)
+		case !file.Existed:
+			if err := os.Remove(file.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to remove "+file.Path)
+			}
+			result.Removed = append(result.Removed, file.Path)
+		default:
+			if err := restore(filepath.Join(dir, file.Stored), file.Path, file.Mode); err != nil {
+				return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to restore "+file.Path)
+			}
+			result.Restored = append(result.Restored, file.Path)
+		}
+	}
+
+	s.remove(change.ID)
+
+	s.logger.Info("undid change", "id", change.ID, "tool", change.Tool,
+		"restored", len(result.Restored), "removed", len(result.Removed))
+
+	return result, nil
+}
+
+// restore puts a backup back in place atomically.
+func restore(stored, path string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".restore")
+	if err := copyFile(stored, tmp, mode); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// copyFile copies src to dst, creating or truncating dst.
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,140 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStore(t *testing.T, modify func(cfg *config.Config)) *Store {
+	cfg := config.Default()
+	cfg.Backup.Dir = t.TempDir()
+	if modify != nil {
+		modify(cfg)
+	}
+	return New(cfg, logger.Default())
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestStore_UndoLast(t *testing.T) {
+	s := testStore(t, nil)
+	dir := t.TempDir()
+
+	existing := filepath.Join(dir, "existing.txt")
+	created := filepath.Join(dir, "created.txt")
+	require.NoError(t, os.WriteFile(existing, []byte("v1"), 0o640))
+
+	// First change replaces a file
+	gen := s.Begin("download_file")
+	require.NoError(t, gen.Save(existing))
+	require.NoError(t, os.WriteFile(existing, []byte("v2"), 0o640))
+	require.NoError(t, gen.Commit())
+
+	// Second change replaces it again and creates another
+	gen = s.Begin("extract_archive")
+	require.NoError(t, gen.Save(existing))
+	require.NoError(t, gen.Save(created))
+	require.NoError(t, gen.Save(existing)) // saved once
+	require.NoError(t, os.WriteFile(existing, []byte("v3"), 0o640))
+	require.NoError(t, os.WriteFile(created, []byte("new"), 0o644))
+	require.NoError(t, gen.Commit())
+
+	changes, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "extract_archive", changes[0].Tool)
+	assert.Len(t, changes[0].Files, 2)
+
+	result, err := s.UndoLast()
+	require.NoError(t, err)
+	assert.Equal(t, "extract_archive", result.Tool)
+	assert.Equal(t, []string{existing}, result.Restored)
+	assert.Equal(t, []string{created}, result.Removed)
+	assert.Equal(t, "v2", readFile(t, existing))
+	assert.NoFileExists(t, created)
+
+	result, err = s.UndoLast()
+	require.NoError(t, err)
+	assert.Equal(t, "download_file", result.Tool)
+	assert.Equal(t, "v1", readFile(t, existing))
+
+	info, err := os.Stat(existing)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	_, err = s.UndoLast()
+	var appErr *apperrors.Error
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrorTypeNotFound, appErr.Type)
+}
+
+func TestStore_Retention(t *testing.T) {
+	s := testStore(t, func(cfg *config.Config) {
+		cfg.Backup.MaxGenerations = 2
+	})
+	dir := t.TempDir()
+
+	for i := 0; i < 4; i++ {
+		gen := s.Begin("download_file")
+		require.NoError(t, gen.Save(filepath.Join(dir, "file.txt")))
+		require.NoError(t, gen.Commit())
+	}
+
+	changes, err := s.List()
+	require.NoError(t, err)
+	assert.Len(t, changes, 2)
+}
+
+func TestStore_SkipsLargeFiles(t *testing.T) {
+	s := testStore(t, func(cfg *config.Config) {
+		cfg.Backup.MaxFileSize = 4
+	})
+	path := filepath.Join(t.TempDir(), "big.bin")
+	require.NoError(t, os.WriteFile(path, []byte("too large"), 0o644))
+
+	gen := s.Begin("download_file")
+	require.NoError(t, gen.Save(path))
+	require.NoError(t, gen.Commit())
+
+	result, err := s.UndoLast()
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, result.Skipped)
+	assert.Equal(t, "too large", readFile(t, path))
+}
+
+func TestGeneration_DiscardAndDisabled(t *testing.T) {
+	s := testStore(t, nil)
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o644))
+
+	gen := s.Begin("create_archive")
+	require.NoError(t, gen.Save(path))
+	gen.Discard()
+
+	changes, err := s.List()
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	disabled := testStore(t, func(cfg *config.Config) {
+		cfg.Backup.Disabled = true
+	})
+	gen = disabled.Begin("create_archive")
+	require.NoError(t, gen.Save(path))
+	require.NoError(t, gen.Commit())
+
+	entries, err := os.ReadDir(disabled.dir())
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
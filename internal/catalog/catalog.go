@@ -0,0 +1,273 @@
+// Package catalog fetches the command catalog an organization publishes
+// for every server it manages, verifying its signature and caching it by
+// ETag
+package catalog
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/internal/outbound"
+	"github.com/mjmorales/simple-mcp-runner/internal/receipt"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// fetchTimeout bounds a fetch of the catalog and its signature,
+	// unless network.timeout is set.
+	fetchTimeout = 30 * time.Second
+
+	// maxCatalogSize limits the size of a catalog in bytes.
+	maxCatalogSize = 10 << 20
+)
+
+// Catalog is a published set of commands.
+type Catalog struct {
+	Commands []config.Command `yaml:"commands"`
+}
+
+// cacheEntry is the last verified catalog.
+type cacheEntry struct {
+	URL       string    `json:"url"`
+	ETag      string    `json:"etag,omitempty"`
+	Body      []byte    `json:"body"`
+	Signature []byte    `json:"signature"`
+	Fetched   time.Time `json:"fetched"`
+}
+
+// Fetcher fetches and verifies the configured catalog.
+type Fetcher struct {
+	url       string
+	sigURL    string
+	cacheFile string
+	key       ed25519.PublicKey
+	client    *http.Client
+	logger    *logger.Logger
+}
+
+// CacheFile returns the file the catalog is cached in.
+func CacheFile(cfg *config.Config) string {
+	if cfg.Catalog.CacheFile != "" {
+		return cfg.Catalog.CacheFile
+	}
+	return filepath.Join(config.StateDir(), "catalog.json")
+}
+
+// New creates a fetcher for the configured catalog.
+func New(cfg *config.Config, log *logger.Logger) (*Fetcher, error) {
+	key, err := receipt.LoadPublicKey(cfg.Catalog.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	sigURL := cfg.Catalog.SignatureURL
+	if sigURL == "" {
+		sigURL = cfg.Catalog.URL + ".sig"
+	}
+	client, err := outbound.New(cfg.Network, fetchTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &Fetcher{
+		url:       cfg.Catalog.URL,
+		sigURL:    sigURL,
+		cacheFile: CacheFile(cfg),
+		key:       key,
+		client:    client,
+		logger:    log,
+	}, nil
+}
+
+// Fetch returns the catalog. Unchanged catalogs are not downloaded again,
+// and the cached catalog is returned when the URL cannot be reached.
+func (f *Fetcher) Fetch(ctx context.Context) (*Catalog, error) {
+	cached := f.loadCache()
+
+	entry, err := f.download(ctx, cached)
+	if err != nil {
+		if cached == nil {
+			return nil, err
+		}
+		f.logger.WithError(err).Warn("failed to fetch catalog, using cached copy",
+			"url", f.url,
+			"fetched", cached.Fetched,
+		)
+		entry = cached
+	}
+
+	if err := Verify(entry.Body, entry.Signature, f.key); err != nil {
+		return nil, err
+	}
+	cat, err := Parse(entry.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry != cached {
+		if err := f.saveCache(entry); err != nil {
+			f.logger.WithError(err).Warn("failed to cache catalog", "file", f.cacheFile)
+		}
+	}
+	return cat, nil
+}
+
+// download fetches the catalog, or returns the cached entry if the server
+// reports it unchanged.
+func (f *Fetcher) download(ctx context.Context, cached *cacheEntry) (*cacheEntry, error) {
+	etag := ""
+	if cached != nil {
+		etag = cached.ETag
+	}
+	body, newETag, notModified, err := f.get(ctx, f.url, etag)
+	if err != nil {
+		return nil, err
+	}
+	if notModified {
+		return cached, nil
+	}
+
+	sig, _, _, err := f.get(ctx, f.sigURL, "")
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return nil, apperrors.ValidationError("catalog signature is not base64", "signature")
+	}
+
+	return &cacheEntry{
+		URL:       f.url,
+		ETag:      newETag,
+		Body:      body,
+		Signature: signature,
+		Fetched:   time.Now(),
+	}, nil
+}
+
+// get downloads a URL, conditionally on its ETag when one is given.
+func (f *Fetcher) get(ctx context.Context, url, etag string) (body []byte, newETag string, notModified bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", false, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "invalid catalog URL")
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, "", false, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to fetch "+url)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && etag != "":
+		return nil, etag, true, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", false, apperrors.New(apperrors.ErrorTypeExecution,
+			fmt.Sprintf("failed to fetch %s: %s", url, resp.Status))
+	}
+
+	body, err = io.ReadAll(io.LimitReader(resp.Body, maxCatalogSize+1))
+	if err != nil {
+		return nil, "", false, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to read "+url)
+	}
+	if len(body) > maxCatalogSize {
+		return nil, "", false, apperrors.ValidationError(
+			fmt.Sprintf("catalog exceeds %d bytes", maxCatalogSize), "catalog")
+	}
+	return body, resp.Header.Get("ETag"), false, nil
+}
+
+// Verify checks the signature of a catalog.
+func Verify(body, signature []byte, key ed25519.PublicKey) error {
+	if !ed25519.Verify(key, body, signature) {
+		return apperrors.ValidationError("catalog signature does not match the public key", "signature")
+	}
+	return nil
+}
+
+// Sign returns the signature file contents of a catalog.
+func Sign(body []byte, signer *receipt.Signer) []byte {
+	return []byte(base64.StdEncoding.EncodeToString(signer.SignData(body)) + "\n")
+}
+
+// Parse reads a catalog.
+func Parse(body []byte) (*Catalog, error) {
+	var cat Catalog
+	if err := yaml.Unmarshal(body, &cat); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeValidation, "failed to parse catalog")
+	}
+	return &cat, nil
+}
+
+// loadCache returns the cached catalog of the configured URL, if any.
+func (f *Fetcher) loadCache() *cacheEntry {
+	data, err := os.ReadFile(f.cacheFile)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			f.logger.WithError(err).Warn("failed to read catalog cache", "file", f.cacheFile)
+		}
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != f.url {
+		return nil
+	}
+	return &entry
+}
+
+// saveCache replaces the cached catalog.
+func (f *Fetcher) saveCache(entry *cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.cacheFile), 0o700); err != nil {
+		return err
+	}
+	tmp := f.cacheFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.cacheFile)
+}
+
+// Merge adds catalog commands to the configured ones and validates the
+// result. Configured commands take precedence over catalog commands of the
+// same name, which are returned as shadowed.
+func Merge(cfg *config.Config, local []config.Command, cat *Catalog) (merged []config.Command, shadowed []string, err error) {
+	merged = append([]config.Command(nil), local...)
+	names := make(map[string]bool, len(local))
+	for _, cmd := range local {
+		names[cmd.Name] = true
+	}
+	for _, cmd := range cat.Commands {
+		if names[cmd.Name] {
+			shadowed = append(shadowed, cmd.Name)
+			continue
+		}
+		names[cmd.Name] = true
+		merged = append(merged, cmd)
+	}
+
+	check := *cfg
+	check.Commands = merged
+	if err := check.Validate(); err != nil {
+		return nil, nil, apperrors.Wrap(err, apperrors.ErrorTypeValidation, "invalid catalog")
+	}
+	return merged, shadowed, nil
+}
@@ -0,0 +1,115 @@
+package catalog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/internal/receipt"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const body = `commands:
+  - name: lint
+    description: Lint the project
+    command: golangci-lint
+    args: ["run"]
+  - name: hello
+    description: Shadowed by the configured command
+    command: echo
+`
+
+// publish serves a signed catalog and counts full downloads.
+func publish(t *testing.T, signature []byte) (*httptest.Server, *atomic.Int32) {
+	var downloads atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/catalog.yaml", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(body))
+	})
+	mux.HandleFunc("/catalog.yaml.sig", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(signature)
+	})
+	srv := httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &downloads
+}
+
+func testConfig(t *testing.T) (*config.Config, *receipt.Signer) {
+	dir := t.TempDir()
+	private, public, err := receipt.GenerateKey()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key.pem"), private, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key.pub"), public, 0o600))
+	signer, err := receipt.LoadSigner(filepath.Join(dir, "key.pem"))
+	require.NoError(t, err)
+
+	cfg := config.Default()
+	cfg.Commands = []config.Command{{Name: "hello", Description: "Say hello", Command: "echo"}}
+	cfg.Catalog.PublicKey = filepath.Join(dir, "key.pub")
+	cfg.Catalog.CacheFile = filepath.Join(dir, "catalog.json")
+	return cfg, signer
+}
+
+func TestFetch(t *testing.T) {
+	cfg, signer := testConfig(t)
+	srv, downloads := publish(t, Sign([]byte(body), signer))
+	cfg.Catalog.URL = srv.URL + "/catalog.yaml"
+
+	f, err := New(cfg, logger.Default())
+	require.NoError(t, err)
+	f.client = srv.Client()
+	cat, err := f.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, cat.Commands, 2)
+
+	merged, shadowed, err := Merge(cfg, cfg.Commands, cat)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hello"}, shadowed)
+	require.Len(t, merged, 2)
+	assert.Equal(t, "Say hello", merged[0].Description)
+	assert.Equal(t, "lint", merged[1].Name)
+
+	// Unchanged catalogs are not downloaded again
+	_, err = f.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), downloads.Load())
+
+	// The cached catalog is used while the URL is unreachable
+	srv.Close()
+	cat, err = f.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, cat.Commands, 2)
+}
+
+func TestFetch_badSignature(t *testing.T) {
+	cfg, signer := testConfig(t)
+	srv, _ := publish(t, Sign([]byte("something else"), signer))
+	cfg.Catalog.URL = srv.URL + "/catalog.yaml"
+
+	f, err := New(cfg, logger.Default())
+	require.NoError(t, err)
+	f.client = srv.Client()
+	_, err = f.Fetch(context.Background())
+	assert.ErrorContains(t, err, "signature does not match")
+	assert.NoFileExists(t, cfg.Catalog.CacheFile)
+}
+
+func TestMerge_invalid(t *testing.T) {
+	cfg, _ := testConfig(t)
+	cat := &Catalog{Commands: []config.Command{{Name: "bad name", Description: "x", Command: "echo"}}}
+	_, _, err := Merge(cfg, cfg.Commands, cat)
+	assert.ErrorContains(t, err, "invalid catalog")
+}
@@ -0,0 +1,155 @@
+// Package cmdcheck verifies that configured commands can run: that their
+// binaries exist and are executable and their working directories exist
+package cmdcheck
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// Problem kinds.
+const (
+	ProblemMissingBinary  = "missing_binary"
+	ProblemNotExecutable  = "not_executable"
+	ProblemMissingWorkDir = "missing_workdir"
+)
+
+// Problem is a reason a configured command cannot run.
+type Problem struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"` // How to install a missing binary
+}
+
+// Result is the outcome of checking a configured command.
+type Result struct {
+	Command  string    `json:"command"` // Name of the configured command
+	Binary   string    `json:"binary"`
+	Path     string    `json:"path,omitempty"` // Where the binary was found
+	WorkDir  string    `json:"workdir,omitempty"`
+	OK       bool      `json:"ok"`
+	Problems []Problem `json:"problems,omitempty"`
+}
+
+// Check verifies a configured command. Relative binary paths are resolved
+// against its working directory, as they are when it runs.
+func Check(cmd config.Command) Result {
+	result := Result{Command: cmd.Name, Binary: cmd.Command, WorkDir: cmd.WorkDir}
+
+	binary := cmd.Command
+	if strings.ContainsAny(binary, `/\`) && !filepath.IsAbs(binary) && cmd.WorkDir != "" {
+		binary = filepath.Join(cmd.WorkDir, binary)
+	}
+	path, err := exec.LookPath(binary)
+	switch {
+	case err == nil || errors.Is(err, exec.ErrDot):
+		result.Path = path
+	case errors.Is(err, fs.ErrPermission):
+		result.Problems = append(result.Problems, Problem{
+			Kind:    ProblemNotExecutable,
+			Message: binary + " is not executable",
+		})
+	default:
+		result.Problems = append(result.Problems, Problem{
+			Kind:    ProblemMissingBinary,
+			Message: binary + " was not found",
+			Hint:    installHint(cmd, runtime.GOOS),
+		})
+	}
+
+	if cmd.WorkDir != "" {
+		info, err := os.Stat(cmd.WorkDir)
+		switch {
+		case err != nil:
+			result.Problems = append(result.Problems, Problem{
+				Kind:    ProblemMissingWorkDir,
+				Message: "workdir " + cmd.WorkDir + " does not exist",
+			})
+		case !info.IsDir():
+			result.Problems = append(result.Problems, Problem{
+				Kind:    ProblemMissingWorkDir,
+				Message: "workdir " + cmd.WorkDir + " is not a directory",
+			})
+		}
+	}
+
+	result.OK = len(result.Problems) == 0
+	return result
+}
+
+// CheckAll verifies configured commands.
+func CheckAll(cmds []config.Command) []Result {
+	results := make([]Result, 0, len(cmds))
+	for _, cmd := range cmds {
+		results = append(results, Check(cmd))
+	}
+	return results
+}
+
+// installSource says how a well-known binary is installed.
+type installSource struct {
+	brew   string // Homebrew formula
+	apt    string // Debian and Ubuntu package
+	winget string // winget package ID
+	url    string // Installation instructions
+}
+
+// installSources are the binaries configured commands most often run.
+var installSources = map[string]installSource{
+	"git":       {"git", "git", "Git.Git", "https://git-scm.com/downloads"},
+	"go":        {"go", "golang-go", "GoLang.Go", "https://go.dev/dl/"},
+	"node":      {"node", "nodejs", "OpenJS.NodeJS", "https://nodejs.org/"},
+	"npm":       {"node", "npm", "OpenJS.NodeJS", "https://nodejs.org/"},
+	"npx":       {"node", "npm", "OpenJS.NodeJS", "https://nodejs.org/"},
+	"python3":   {"python", "python3", "Python.Python.3.12", "https://www.python.org/downloads/"},
+	"python":    {"python", "python-is-python3", "Python.Python.3.12", "https://www.python.org/downloads/"},
+	"pip3":      {"python", "python3-pip", "Python.Python.3.12", "https://pip.pypa.io/en/stable/installation/"},
+	"pip":       {"python", "python3-pip", "Python.Python.3.12", "https://pip.pypa.io/en/stable/installation/"},
+	"make":      {"make", "make", "GnuWin32.Make", ""},
+	"cargo":     {"", "", "", "https://rustup.rs/"},
+	"rustc":     {"", "", "", "https://rustup.rs/"},
+	"docker":    {"--cask docker", "docker.io", "Docker.DockerDesktop", "https://docs.docker.com/get-docker/"},
+	"kubectl":   {"kubectl", "", "Kubernetes.kubectl", "https://kubernetes.io/docs/tasks/tools/"},
+	"terraform": {"hashicorp/tap/terraform", "", "Hashicorp.Terraform", "https://developer.hashicorp.com/terraform/install"},
+	"jq":        {"jq", "jq", "jqlang.jq", "https://jqlang.github.io/jq/download/"},
+	"gh":        {"gh", "gh", "GitHub.cli", "https://cli.github.com/"},
+	"aws":       {"awscli", "awscli", "Amazon.AWSCLI", "https://aws.amazon.com/cli/"},
+	"az":        {"azure-cli", "azure-cli", "Microsoft.AzureCLI", "https://learn.microsoft.com/cli/azure/install-azure-cli"},
+	"gcloud":    {"--cask google-cloud-sdk", "", "Google.CloudSDK", "https://cloud.google.com/sdk/docs/install"},
+	"yarn":      {"yarn", "", "Yarn.Yarn", "https://yarnpkg.com/getting-started/install"},
+	"pnpm":      {"pnpm", "", "pnpm.pnpm", "https://pnpm.io/installation"},
+	"tmux":      {"tmux", "tmux", "", ""},
+}
+
+// installHint says how to install the binary of a command on an operating
+// system: the command's install_hint, or the platform package manager for
+// well-known binaries.
+func installHint(cmd config.Command, goos string) string {
+	if cmd.InstallHint != "" {
+		return cmd.InstallHint
+	}
+	name := strings.ToLower(filepath.Base(cmd.Command))
+	name = strings.TrimSuffix(name, ".exe")
+	src, ok := installSources[name]
+	if !ok {
+		return ""
+	}
+	switch {
+	case goos == "darwin" && src.brew != "":
+		return "brew install " + src.brew
+	case goos == "linux" && src.apt != "":
+		return "apt install " + src.apt
+	case goos == "windows" && src.winget != "":
+		return "winget install --id " + src.winget
+	case src.url != "":
+		return "see " + src.url
+	}
+	return ""
+}
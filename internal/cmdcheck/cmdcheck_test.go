@@ -0,0 +1,74 @@
+package cmdcheck
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "build.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		cmd   config.Command
+		kinds []string
+	}{
+		{"found", config.Command{Name: "ok", Command: self, WorkDir: dir}, nil},
+		{"missing binary", config.Command{Name: "missing", Command: "no-such-binary-xyz"}, []string{ProblemMissingBinary}},
+		{"missing workdir", config.Command{Name: "nowd", Command: self, WorkDir: filepath.Join(dir, "gone")}, []string{ProblemMissingWorkDir}},
+		{"workdir is a file", config.Command{Name: "filewd", Command: self, WorkDir: script}, []string{ProblemMissingWorkDir}},
+	}
+	if runtime.GOOS != "windows" {
+		tests = append(tests, struct {
+			name  string
+			cmd   config.Command
+			kinds []string
+		}{"relative and not executable", config.Command{Name: "script", Command: "./build.sh", WorkDir: dir}, []string{ProblemNotExecutable}})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Check(tt.cmd)
+			if result.OK != (len(tt.kinds) == 0) || len(result.Problems) != len(tt.kinds) {
+				t.Fatalf("unexpected result %+v", result)
+			}
+			for i, kind := range tt.kinds {
+				if result.Problems[i].Kind != kind {
+					t.Errorf("problem %d = %s, want %s", i, result.Problems[i].Kind, kind)
+				}
+			}
+		})
+	}
+}
+
+func TestInstallHint(t *testing.T) {
+	tests := []struct {
+		cmd  config.Command
+		goos string
+		want string
+	}{
+		{config.Command{Command: "go"}, "darwin", "brew install go"},
+		{config.Command{Command: "/usr/bin/go"}, "linux", "apt install golang-go"},
+		{config.Command{Command: "git.exe"}, "windows", "winget install --id Git.Git"},
+		{config.Command{Command: "cargo"}, "linux", "see https://rustup.rs/"},
+		{config.Command{Command: "rg", InstallHint: "brew install ripgrep"}, "linux", "brew install ripgrep"},
+		{config.Command{Command: "unknown-tool"}, "linux", ""},
+	}
+	for _, tt := range tests {
+		if got := installHint(tt.cmd, tt.goos); got != tt.want {
+			t.Errorf("installHint(%s, %s) = %q, want %q", tt.cmd.Command, tt.goos, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+// Package container lists, reads the logs of and runs commands in the
+// Docker containers the configuration allows tools to touch
+package container
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	pkgtypes "github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// defaultMaxOutput limits exec output and logs when the configuration sets
+// no output limit.
+const defaultMaxOutput = 10 << 20
+
+// dockerClient is the part of the Docker client the tools use.
+type dockerClient interface {
+	ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
+	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
+	ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error)
+	ContainerExecCreate(ctx context.Context, containerID string, options container.ExecOptions) (container.ExecCreateResponse, error)
+	ContainerExecAttach(ctx context.Context, execID string, options container.ExecAttachOptions) (types.HijackedResponse, error)
+	ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error)
+	Close() error
+}
+
+// Info describes a container.
+type Info struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Image   string    `json:"image"`
+	State   string    `json:"state"`  // created, running, exited, ...
+	Status  string    `json:"status"` // Such as "Up 2 hours"
+	Created time.Time `json:"created"`
+}
+
+// LogsRequest selects the logs of a container.
+type LogsRequest struct {
+	Container  string
+	Tail       int    // Lines from the end; capped by the configuration
+	Since      string // Timestamp or duration, such as 10m
+	Timestamps bool
+}
+
+// Logs are the recent output of a container.
+type Logs struct {
+	Container string `json:"container"`
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+	Tail      int    `json:"tail"`                // Lines requested from each stream
+	Truncated bool   `json:"truncated,omitempty"` // Output exceeded the size limit
+}
+
+// ExecRequest is a command to run in a container.
+type ExecRequest struct {
+	Container string
+	Command   []string // Program and arguments, run without a shell
+	WorkDir   string
+	Env       []string // KEY=value
+	Timeout   string
+}
+
+// Manager runs the container tools against the Docker daemon.
+type Manager struct {
+	config *config.Config
+	client dockerClient
+	logger *logger.Logger
+}
+
+// New connects to the configured Docker daemon. The daemon is only
+// contacted when a tool is called.
+func New(cfg *config.Config, log *logger.Logger) (*Manager, error) {
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if cfg.Containers.Host != "" {
+		opts = append(opts, client.WithHost(cfg.Containers.Host))
+	}
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to create Docker client")
+	}
+	return &Manager{config: cfg, client: cli, logger: log}, nil
+}
+
+// Close releases the Docker client.
+func (m *Manager) Close() error {
+	if m == nil {
+		return nil
+	}
+	return m.client.Close()
+}
+
+// List returns the allowed containers, running ones only unless all is
+// set, sorted by name.
+func (m *Manager) List(ctx context.Context, all bool) ([]Info, error) {
+	summaries, err := m.client.ContainerList(ctx, container.ListOptions{All: all})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to list containers")
+	}
+
+	infos := []Info{}
+	for _, c := range summaries {
+		name := ""
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		if !m.allowed(name, c.Image) {
+			continue
+		}
+		infos = append(infos, Info{
+			ID:      shortID(c.ID),
+			Name:    name,
+			Image:   c.Image,
+			State:   c.State,
+			Status:  c.Status,
+			Created: time.Unix(c.Created, 0),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// Logs returns the most recent output of an allowed container.
+func (m *Manager) Logs(ctx context.Context, req LogsRequest) (*Logs, error) {
+	c, err := m.resolve(ctx, req.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	tail := req.Tail
+	if max := m.config.Containers.MaxLogLines; tail <= 0 || (max > 0 && tail > max) {
+		tail = max
+	}
+	opts := container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Since:      req.Since,
+		Timestamps: req.Timestamps,
+		Tail:       "all",
+	}
+	if tail > 0 {
+		opts.Tail = strconv.Itoa(tail)
+	}
+
+	rc, err := m.client.ContainerLogs(ctx, c.ID, opts)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to read logs of container "+req.Container)
+	}
+	defer rc.Close()
+
+	stdout, stderr := m.buffers()
+	if err := copyOutput(stdout, stderr, rc, c.Config != nil && c.Config.Tty); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to read logs of container "+req.Container)
+	}
+	return &Logs{
+		Container: strings.TrimPrefix(c.Name, "/"),
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Tail:      tail,
+		Truncated: stdout.exceeded || stderr.exceeded,
+	}, nil
+}
+
+// Exec runs a command in an allowed running container and waits for it.
+// A command that times out is detached from, but the daemon offers no way
+// to stop it, so it may keep running in the container.
+func (m *Manager) Exec(ctx context.Context, req ExecRequest) (*pkgtypes.CommandExecutionResult, error) {
+	if !m.config.Containers.AllowExec {
+		return nil, apperrors.PermissionError("running commands in containers is disabled by containers.allow_exec", "exec_in_container")
+	}
+	if len(req.Command) == 0 || req.Command[0] == "" {
+		return nil, apperrors.ValidationError("command is required", "command")
+	}
+	if entry := m.config.MatchBlockedCommand(req.Command[0], ""); entry != "" {
+		return nil, apperrors.PermissionError(
+			fmt.Sprintf("command %s is blocked by security.blocked_commands entry %q", req.Command[0], entry), req.Command[0])
+	}
+
+	c, err := m.resolve(ctx, req.Container)
+	if err != nil {
+		return nil, err
+	}
+	if c.State == nil || !c.State.Running {
+		return nil, apperrors.ValidationError("container "+req.Container+" is not running", "container")
+	}
+
+	timeout := m.timeout(req.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := &pkgtypes.CommandExecutionResult{StartTime: time.Now()}
+	created, err := m.client.ContainerExecCreate(ctx, c.ID, container.ExecOptions{
+		Cmd:          req.Command,
+		WorkingDir:   req.WorkDir,
+		Env:          req.Env,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to create exec in container "+req.Container)
+	}
+	attached, err := m.client.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to attach to exec in container "+req.Container)
+	}
+	defer attached.Close()
+
+	stdout, stderr := m.buffers()
+	done := make(chan error, 1)
+	go func() {
+		done <- copyOutput(stdout, stderr, attached.Reader, false)
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		attached.Close()
+		<-done
+		result.TimedOut = true
+	}
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+	result.Stdout, result.Stderr = stdout.String(), stderr.String()
+
+	if result.TimedOut {
+		result.ExitCode = -1
+		return result, apperrors.TimeoutError(
+			fmt.Sprintf("command in container %s timed out", req.Container), timeout.String())
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to read output of container "+req.Container)
+	}
+
+	inspect, err := m.client.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to inspect exec in container "+req.Container)
+	}
+	result.ExitCode = inspect.ExitCode
+	return result, nil
+}
+
+// resolve inspects a container by name or ID and checks that the tools
+// may touch it.
+func (m *Manager) resolve(ctx context.Context, ref string) (*container.InspectResponse, error) {
+	if ref == "" {
+		return nil, apperrors.ValidationError("container is required", "container")
+	}
+	c, err := m.client.ContainerInspect(ctx, ref)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return nil, apperrors.NotFoundError("no such container: "+ref, ref)
+		}
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to inspect container "+ref)
+	}
+
+	image := ""
+	if c.Config != nil {
+		image = c.Config.Image
+	}
+	if !m.allowed(strings.TrimPrefix(c.Name, "/"), image) {
+		return nil, apperrors.PermissionError(
+			"container "+ref+" is not allowed by containers.allowed_containers or containers.allowed_images", ref)
+	}
+	return &c, nil
+}
+
+// allowed reports whether the tools may touch a container.
+func (m *Manager) allowed(name, image string) bool {
+	for _, entry := range m.config.Containers.AllowedContainers {
+		if ok, _ := filepath.Match(entry, name); ok {
+			return true
+		}
+	}
+	for _, entry := range m.config.Containers.AllowedImages {
+		if imageMatches(entry, image) {
+			return true
+		}
+	}
+	return false
+}
+
+// imageMatches reports whether an allowed_images entry matches an image
+// reference. Entries without a tag or digest match every tag and digest.
+func imageMatches(entry, image string) bool {
+	if ok, _ := filepath.Match(entry, image); ok {
+		return true
+	}
+	if hasTag(entry) {
+		return false
+	}
+	ok, _ := filepath.Match(entry, repository(image))
+	return ok
+}
+
+// repository returns an image reference without its tag and digest.
+func repository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if hasTag(image) {
+		image = image[:strings.LastIndex(image, ":")]
+	}
+	return image
+}
+
+// hasTag reports whether an image reference has a tag or digest. A colon
+// before the last slash separates a registry port, not a tag.
+func hasTag(image string) bool {
+	return strings.Contains(image, "@") || strings.Contains(image[strings.LastIndex(image, "/")+1:], ":")
+}
+
+// timeout returns the limit of an exec, like the executor does for
+// commands.
+func (m *Manager) timeout(requested string) time.Duration {
+	maxTimeout := 5 * time.Minute
+	if m.config.Execution.MaxTimeout > 0 {
+		maxTimeout = m.config.Execution.MaxTimeout.Std()
+	}
+	if d, err := time.ParseDuration(requested); err == nil && d > 0 {
+		return min(d, maxTimeout)
+	}
+	if m.config.Execution.DefaultTimeout > 0 {
+		return m.config.Execution.DefaultTimeout.Std()
+	}
+	return 30 * time.Second
+}
+
+// buffers returns output buffers limited like command output.
+func (m *Manager) buffers() (stdout, stderr *limitedBuffer) {
+	limit := int(m.config.Execution.MaxOutputSize)
+	if limit <= 0 {
+		limit = defaultMaxOutput
+	}
+	return &limitedBuffer{limit: limit}, &limitedBuffer{limit: limit}
+}
+
+// copyOutput splits the multiplexed output of a container into stdout
+// and stderr. Containers with a TTY have a single raw stream.
+func copyOutput(stdout, stderr io.Writer, src io.Reader, tty bool) error {
+	var err error
+	if tty {
+		_, err = io.Copy(stdout, src)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, src)
+	}
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// shortID returns the short form of a container ID, as docker ps shows it.
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// limitedBuffer collects output up to a limit.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit    int
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); len(p) > room {
+		b.exceeded = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,208 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDocker serves containers from memory. Execs print their command to
+// stdout and "err" to stderr, and exit with code 3.
+type fakeDocker struct {
+	containers []container.InspectResponse
+	execs      [][]string
+	hang       bool // Execs never finish
+	lastLogs   container.LogsOptions
+}
+
+func (f *fakeDocker) ContainerList(_ context.Context, opts container.ListOptions) ([]container.Summary, error) {
+	var out []container.Summary
+	for _, c := range f.containers {
+		if !opts.All && !c.State.Running {
+			continue
+		}
+		out = append(out, container.Summary{ID: c.ID, Names: []string{c.Name}, Image: c.Config.Image, State: c.State.Status})
+	}
+	return out, nil
+}
+
+func (f *fakeDocker) ContainerInspect(_ context.Context, ref string) (container.InspectResponse, error) {
+	for _, c := range f.containers {
+		if c.ID == ref || c.Name == "/"+ref {
+			return c, nil
+		}
+	}
+	return container.InspectResponse{}, errdefs.ErrNotFound
+}
+
+func (f *fakeDocker) ContainerLogs(_ context.Context, _ string, opts container.LogsOptions) (io.ReadCloser, error) {
+	f.lastLogs = opts
+	var buf bytes.Buffer
+	stdcopy.NewStdWriter(&buf, stdcopy.Stdout).Write([]byte("started\n"))
+	stdcopy.NewStdWriter(&buf, stdcopy.Stderr).Write([]byte("warning\n"))
+	return io.NopCloser(&buf), nil
+}
+
+func (f *fakeDocker) ContainerExecCreate(_ context.Context, _ string, opts container.ExecOptions) (container.ExecCreateResponse, error) {
+	f.execs = append(f.execs, opts.Cmd)
+	return container.ExecCreateResponse{ID: "exec1"}, nil
+}
+
+func (f *fakeDocker) ContainerExecAttach(_ context.Context, _ string, _ container.ExecAttachOptions) (types.HijackedResponse, error) {
+	server, client := net.Pipe()
+	cmd := f.execs[len(f.execs)-1]
+	go func() {
+		if f.hang {
+			return
+		}
+		defer server.Close()
+		for _, arg := range cmd {
+			stdcopy.NewStdWriter(server, stdcopy.Stdout).Write([]byte(arg + " "))
+		}
+		stdcopy.NewStdWriter(server, stdcopy.Stderr).Write([]byte("err"))
+	}()
+	return types.NewHijackedResponse(client, ""), nil
+}
+
+func (f *fakeDocker) ContainerExecInspect(_ context.Context, _ string) (container.ExecInspect, error) {
+	return container.ExecInspect{ExitCode: 3}, nil
+}
+
+func (f *fakeDocker) Close() error { return nil }
+
+func newTestManager(t *testing.T, configure func(*config.ContainerConfig)) (*Manager, *fakeDocker) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Containers.Enabled = true
+	cfg.Containers.AllowedContainers = []string{"dev-*"}
+	cfg.Containers.AllowedImages = []string{"postgres"}
+	if configure != nil {
+		configure(&cfg.Containers)
+	}
+	log, err := logger.New(logger.Options{Level: "error", Output: io.Discard})
+	require.NoError(t, err)
+
+	running := &container.State{Running: true, Status: "running"}
+	fake := &fakeDocker{containers: []container.InspectResponse{
+		inspect("aaaaaaaaaaaaaaaa", "dev-api", "node:22", running),
+		inspect("bbbbbbbbbbbbbbbb", "db", "postgres:16", running),
+		inspect("cccccccccccccccc", "prod-api", "node:22", running),
+		inspect("dddddddddddddddd", "dev-old", "node:20", &container.State{Status: "exited"}),
+	}}
+	return &Manager{config: cfg, client: fake, logger: log}, fake
+}
+
+func inspect(id, name, image string, state *container.State) container.InspectResponse {
+	return container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{ID: id, Name: "/" + name, State: state},
+		Config:            &container.Config{Image: image},
+	}
+}
+
+func TestManager_List(t *testing.T) {
+	m, _ := newTestManager(t, nil)
+	ctx := context.Background()
+
+	infos, err := m.List(ctx, false)
+	require.NoError(t, err)
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	assert.Equal(t, []string{"db", "dev-api"}, names)
+	assert.Equal(t, "aaaaaaaaaaaa", infos[1].ID)
+
+	infos, err = m.List(ctx, true)
+	require.NoError(t, err)
+	assert.Len(t, infos, 3)
+}
+
+func TestManager_Logs(t *testing.T) {
+	m, fake := newTestManager(t, func(c *config.ContainerConfig) { c.MaxLogLines = 100 })
+	ctx := context.Background()
+
+	logs, err := m.Logs(ctx, LogsRequest{Container: "db", Tail: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, "started\n", logs.Stdout)
+	assert.Equal(t, "warning\n", logs.Stderr)
+	assert.Equal(t, 100, logs.Tail)
+	assert.Equal(t, "100", fake.lastLogs.Tail)
+
+	_, err = m.Logs(ctx, LogsRequest{Container: "prod-api"})
+	assertErrorType(t, err, apperrors.ErrorTypePermission)
+
+	_, err = m.Logs(ctx, LogsRequest{Container: "missing"})
+	assertErrorType(t, err, apperrors.ErrorTypeNotFound)
+}
+
+func TestManager_Exec(t *testing.T) {
+	ctx := context.Background()
+
+	m, _ := newTestManager(t, nil)
+	_, err := m.Exec(ctx, ExecRequest{Container: "dev-api", Command: []string{"ls"}})
+	assertErrorType(t, err, apperrors.ErrorTypePermission)
+
+	m, fake := newTestManager(t, func(c *config.ContainerConfig) { c.AllowExec = true })
+	result, err := m.Exec(ctx, ExecRequest{Container: "dev-api", Command: []string{"ls", "-l"}})
+	require.NoError(t, err)
+	assert.Equal(t, "ls -l ", result.Stdout)
+	assert.Equal(t, "err", result.Stderr)
+	assert.Equal(t, 3, result.ExitCode)
+
+	_, err = m.Exec(ctx, ExecRequest{Container: "dev-api", Command: []string{"rm", "-rf", "/"}})
+	assertErrorType(t, err, apperrors.ErrorTypePermission)
+
+	_, err = m.Exec(ctx, ExecRequest{Container: "dev-old", Command: []string{"ls"}})
+	assertErrorType(t, err, apperrors.ErrorTypeValidation)
+
+	_, err = m.Exec(ctx, ExecRequest{Container: "prod-api", Command: []string{"ls"}})
+	assertErrorType(t, err, apperrors.ErrorTypePermission)
+	assert.Len(t, fake.execs, 1)
+
+	fake.hang = true
+	start := time.Now()
+	result, err = m.Exec(ctx, ExecRequest{Container: "dev-api", Command: []string{"sleep", "60"}, Timeout: "50ms"})
+	assertErrorType(t, err, apperrors.ErrorTypeTimeout)
+	assert.True(t, result.TimedOut)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestImageMatches(t *testing.T) {
+	tests := []struct {
+		entry, image string
+		want         bool
+	}{
+		{"postgres", "postgres:16", true},
+		{"postgres", "postgres", true},
+		{"postgres", "postgres@sha256:abc", true},
+		{"postgres:16", "postgres:15", false},
+		{"postgres", "postgresql:16", false},
+		{"ghcr.io/acme/*", "ghcr.io/acme/api:1.2", true},
+		{"ghcr.io/acme/*", "ghcr.io/other/api:1.2", false},
+		{"localhost:5000/app", "localhost:5000/app:dev", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, imageMatches(tt.entry, tt.image), "%s matches %s", tt.entry, tt.image)
+	}
+}
+
+func assertErrorType(t *testing.T, err error, want apperrors.ErrorType) {
+	t.Helper()
+	var appErr *apperrors.Error
+	require.True(t, errors.As(err, &appErr), "error %v is not an AppError", err)
+	assert.Equal(t, want, appErr.Type, err.Error())
+}
@@ -0,0 +1,403 @@
+// Package control serves the operator control API: a local HTTP API,
+// separate from the MCP transport, for listing sessions, killing running
+// commands, flipping switches, draining the server and fetching stats,
+// and the web dashboard built on it
+package control
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/approval"
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+//go:embed dashboard
+var dashboardFS embed.FS
+
+// History limits.
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 1000
+)
+
+// Session is a connected client session.
+type Session struct {
+	ID            string    `json:"id"`
+	Client        string    `json:"client,omitempty"`
+	ClientVersion string    `json:"client_version,omitempty"`
+	Started       time.Time `json:"started"`
+	ToolGroups    []string  `json:"tool_groups,omitempty"`
+}
+
+// Stats describe the server at a glance.
+type Stats struct {
+	State          string           `json:"state"`
+	Uptime         string           `json:"uptime"`
+	Sessions       int              `json:"sessions"`
+	ActiveCommands int              `json:"active_commands"`
+	QueuedCommands int              `json:"queued_commands"`
+	Crashes        int64            `json:"crashes"`        // Panics recovered in request handlers
+	ReturnedBytes  int64            `json:"returned_bytes"` // Size of the tool results and resources returned to clients
+	Executions     map[string]int64 `json:"executions"`     // Execution counters, as at /debug/vars
+	Switches       map[string]bool  `json:"switches"`
+}
+
+// Drift compares the configuration a server runs with to its file on
+// disk.
+type Drift struct {
+	File    string          `json:"file"`
+	InSync  bool            `json:"in_sync"`
+	Changes []config.Change `json:"changes,omitempty"` // From the running configuration to the file
+
+	// Error is why the file could not be loaded, e.g. after an edit that
+	// does not validate; the server keeps running with its configuration
+	Error string `json:"error,omitempty"`
+}
+
+// Backend is the server the API operates.
+type Backend interface {
+	Sessions() []Session
+	ActiveCommands() []executor.ActiveCommand
+	KillCommand(id uint64) error
+	Switches() map[string]bool
+	SetSwitch(name string, on bool) error
+	Drain() // Starts draining; returns at once
+	Stats() Stats
+	History(decision string, limit int) []types.ExecutionRecord
+	ConfigSummary() types.ConfigSummary
+	ConfigDrift() (Drift, error)
+	Approvals() ([]*approval.Request, error)
+	DecideApproval(id string, approve bool, comment string) (*approval.Request, error)
+}
+
+// Server serves the control API.
+type Server struct {
+	http      *http.Server
+	listener  net.Listener
+	backend   Backend
+	token     string
+	tokenFile string
+	log       *logger.Logger
+}
+
+// TokenFile returns the file the bearer token of the API is written to.
+func TokenFile(cfg *config.Config) string {
+	if cfg.Control.TokenFile != "" {
+		return cfg.Control.TokenFile
+	}
+	return filepath.Join(config.StateDir(), "control-token")
+}
+
+// Start listens on the configured loopback address and serves:
+//
+//	GET  /v1/sessions               connected client sessions
+//	GET  /v1/commands               running commands
+//	POST /v1/commands/{id}/kill     kill a running command
+//	GET  /v1/switches               operator switches
+//	PUT  /v1/switches/{name}        turn a switch on or off: {"on": true}
+//	POST /v1/drain                  refuse new executions, wait for running
+//	                                ones and shut down
+//	GET  /v1/stats                  state, counters and switches
+//	GET  /v1/history                executions, newest first; ?decision=denied
+//	                                for policy denials, ?limit=N (default 50)
+//	GET  /v1/config                 configuration summary
+//	GET  /v1/config/drift           differences from the configuration
+//	                                file on disk
+//	GET  /v1/approvals              approval requests; ?all=true to include
+//	                                decided ones
+//	POST /v1/approvals/{id}/approve approve a request: {"comment": "..."}
+//	POST /v1/approvals/{id}/reject  reject a request
+//
+// Requests must carry the token written to TokenFile as a bearer token; a
+// new token is written every start. The dashboard is served at / without
+// the token, which it asks for and sends with its API requests.
+func Start(cfg *config.Config, backend Backend, log *logger.Logger) (*Server, error) {
+	addr := cfg.Control.Addr
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, apperrors.ValidationError("invalid control address: "+addr, "control.addr")
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, apperrors.ValidationError("control address must be a loopback address: "+addr, "control.addr")
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	tokenFile := TokenFile(cfg)
+	if err := os.MkdirAll(filepath.Dir(tokenFile), 0o700); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to create control token directory")
+	}
+	if err := os.WriteFile(tokenFile, []byte(token+"\n"), 0o600); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to write control token")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		os.Remove(tokenFile)
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to listen on control address")
+	}
+
+	s := &Server{
+		listener:  listener,
+		backend:   backend,
+		token:     token,
+		tokenFile: tokenFile,
+		log:       log,
+	}
+
+	api := http.NewServeMux()
+	api.HandleFunc("GET /v1/sessions", s.handleSessions)
+	api.HandleFunc("GET /v1/commands", s.handleCommands)
+	api.HandleFunc("POST /v1/commands/{id}/kill", s.handleKill)
+	api.HandleFunc("GET /v1/switches", s.handleSwitches)
+	api.HandleFunc("PUT /v1/switches/{name}", s.handleSetSwitch)
+	api.HandleFunc("POST /v1/drain", s.handleDrain)
+	api.HandleFunc("GET /v1/stats", s.handleStats)
+	api.HandleFunc("GET /v1/history", s.handleHistory)
+	api.HandleFunc("GET /v1/config", s.handleConfig)
+	api.HandleFunc("GET /v1/config/drift", s.handleConfigDrift)
+	api.HandleFunc("GET /v1/approvals", s.handleApprovals)
+	api.HandleFunc("POST /v1/approvals/{id}/{decision}", s.handleDecideApproval)
+
+	dashboard, _ := fs.Sub(dashboardFS, "dashboard")
+	mux := http.NewServeMux()
+	mux.Handle("/v1/", s.authenticate(api))
+	mux.Handle("/", dashboardHeaders(http.FileServerFS(dashboard)))
+
+	s.http = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := s.http.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("control server failed")
+		}
+	}()
+
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server and removes its token.
+func (s *Server) Close() error {
+	os.Remove(s.tokenFile)
+	return s.http.Close()
+}
+
+// newToken returns a random bearer token.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to generate control token")
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// authenticate rejects requests without the bearer token.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	sessions := s.backend.Sessions()
+	if sessions == nil {
+		sessions = []Session{}
+	}
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+func (s *Server) handleCommands(w http.ResponseWriter, r *http.Request) {
+	active := s.backend.ActiveCommands()
+	if active == nil {
+		active = []executor.ActiveCommand{}
+	}
+	writeJSON(w, http.StatusOK, active)
+}
+
+func (s *Server) handleKill(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid command id: "+r.PathValue("id"))
+		return
+	}
+	if err := s.backend.KillCommand(id); err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSwitches(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.backend.Switches())
+}
+
+func (s *Server) handleSetSwitch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		On *bool `json:"on"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&body); err != nil || body.On == nil {
+		writeError(w, http.StatusBadRequest, `body must be {"on": true} or {"on": false}`)
+		return
+	}
+	name := r.PathValue("name")
+	if err := s.backend.SetSwitch(name, *body.On); err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.backend.Switches())
+}
+
+func (s *Server) handleDrain(w http.ResponseWriter, r *http.Request) {
+	s.backend.Drain()
+	s.log.Info("control: draining server")
+	writeJSON(w, http.StatusAccepted, map[string]string{"state": "draining"})
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.backend.Stats())
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	limit := defaultHistoryLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxHistoryLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxHistoryLimit))
+			return
+		}
+		limit = n
+	}
+	decision := r.URL.Query().Get("decision")
+	if decision != "" && decision != types.PolicyDecisionAllowed && decision != types.PolicyDecisionDenied {
+		writeError(w, http.StatusBadRequest, "decision must be allowed or denied")
+		return
+	}
+	records := s.backend.History(decision, limit)
+	if records == nil {
+		records = []types.ExecutionRecord{}
+	}
+	writeJSON(w, http.StatusOK, records)
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.backend.ConfigSummary())
+}
+
+func (s *Server) handleConfigDrift(w http.ResponseWriter, r *http.Request) {
+	drift, err := s.backend.ConfigDrift()
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, drift)
+}
+
+func (s *Server) handleApprovals(w http.ResponseWriter, r *http.Request) {
+	requests, err := s.backend.Approvals()
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	all := r.URL.Query().Get("all") == "true"
+	out := []*approval.Request{}
+	for _, req := range requests {
+		if all || req.Status == approval.StatusPending {
+			out = append(out, req)
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleDecideApproval(w http.ResponseWriter, r *http.Request) {
+	var approve bool
+	switch r.PathValue("decision") {
+	case "approve":
+		approve = true
+	case "reject":
+	default:
+		writeError(w, http.StatusNotFound, "unknown decision: "+r.PathValue("decision"))
+		return
+	}
+	var body struct {
+		Comment string `json:"comment"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, `body must be {"comment": "..."}`)
+			return
+		}
+	}
+	req, err := s.backend.DecideApproval(r.PathValue("id"), approve, body.Comment)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, req)
+}
+
+// dashboardHeaders keeps the dashboard from being framed by other pages
+// or loading anything but its own files.
+func dashboardHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// writeAppError responds with the status an error's type calls for.
+func writeAppError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		switch appErr.Type {
+		case apperrors.ErrorTypeNotFound:
+			status = http.StatusNotFound
+		case apperrors.ErrorTypeValidation:
+			status = http.StatusBadRequest
+		case apperrors.ErrorTypePermission:
+			status = http.StatusForbidden
+		}
+	}
+	writeError(w, status, err.Error())
+}
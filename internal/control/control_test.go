@@ -0,0 +1,286 @@
+package control
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/approval"
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// fakeBackend records what the API asked of it.
+type fakeBackend struct {
+	mu       sync.Mutex
+	switches map[string]bool
+	killed   []uint64
+	drained  bool
+	approved []string
+}
+
+func (b *fakeBackend) Sessions() []Session {
+	return []Session{{ID: "s1", Client: "editor"}}
+}
+
+func (b *fakeBackend) ActiveCommands() []executor.ActiveCommand {
+	return []executor.ActiveCommand{{ID: 7, Command: "sleep"}}
+}
+
+func (b *fakeBackend) KillCommand(id uint64) error {
+	if id != 7 {
+		return apperrors.NotFoundError("no running command", "")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.killed = append(b.killed, id)
+	return nil
+}
+
+func (b *fakeBackend) Switches() map[string]bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make(map[string]bool)
+	for k, v := range b.switches {
+		out[k] = v
+	}
+	return out
+}
+
+func (b *fakeBackend) SetSwitch(name string, on bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.switches[name]; !ok {
+		return apperrors.NotFoundError("unknown switch: "+name, name)
+	}
+	b.switches[name] = on
+	return nil
+}
+
+func (b *fakeBackend) Drain() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.drained = true
+}
+
+func (b *fakeBackend) Stats() Stats {
+	return Stats{State: "running", Switches: b.Switches()}
+}
+
+func (b *fakeBackend) History(decision string, limit int) []types.ExecutionRecord {
+	records := []types.ExecutionRecord{
+		{ID: "h2", Decision: types.PolicyDecisionDenied, Request: types.CommandExecutionRequest{Command: "rm"}},
+		{ID: "h1", Decision: types.PolicyDecisionAllowed, Request: types.CommandExecutionRequest{Command: "ls"}},
+	}
+	var out []types.ExecutionRecord
+	for _, rec := range records {
+		if (decision == "" || rec.Decision == decision) && len(out) < limit {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+func (b *fakeBackend) ConfigSummary() types.ConfigSummary {
+	return types.ConfigSummary{Commands: []string{"build"}}
+}
+
+func (b *fakeBackend) ConfigDrift() (Drift, error) {
+	return Drift{File: "/etc/runner.yaml", Changes: []config.Change{{Path: "security.allowed_paths", Kind: config.ChangeChanged, Security: true}}}, nil
+}
+
+func (b *fakeBackend) Approvals() ([]*approval.Request, error) {
+	return []*approval.Request{
+		{ID: "a2", Command: "deploy", Status: approval.StatusPending},
+		{ID: "a1", Command: "deploy", Status: approval.StatusRejected},
+	}, nil
+}
+
+func (b *fakeBackend) DecideApproval(id string, approve bool, comment string) (*approval.Request, error) {
+	if id != "a2" {
+		return nil, apperrors.NotFoundError("approval request not found: "+id, id)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.approved = append(b.approved, id+" "+comment)
+	return &approval.Request{ID: id, Status: approval.StatusPending, ApprovedBy: []string{"alice"}}, nil
+}
+
+func TestServer(t *testing.T) {
+	cfg := config.Default()
+	cfg.Control.Addr = "127.0.0.1:0"
+	cfg.Control.TokenFile = filepath.Join(t.TempDir(), "token")
+	backend := &fakeBackend{switches: map[string]bool{"paused": false}}
+	s, err := Start(cfg, backend, logger.Default())
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer s.Close()
+
+	data, err := os.ReadFile(cfg.Control.TokenFile)
+	if err != nil {
+		t.Fatalf("token not written: %v", err)
+	}
+	token := strings.TrimSpace(string(data))
+
+	do := func(method, path, body, auth string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, "http://"+s.Addr()+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		out, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(out)
+	}
+
+	// Requests without the token are refused
+	for _, auth := range []string{"", "wrong"} {
+		if status, _ := do("GET", "/v1/stats", "", auth); status != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", auth, status)
+		}
+	}
+
+	if status, body := do("GET", "/v1/sessions", "", token); status != http.StatusOK || !strings.Contains(body, `"client": "editor"`) {
+		t.Errorf("sessions: %d %s", status, body)
+	}
+	if status, body := do("GET", "/v1/commands", "", token); status != http.StatusOK || !strings.Contains(body, `"command": "sleep"`) {
+		t.Errorf("commands: %d %s", status, body)
+	}
+
+	if status, _ := do("POST", "/v1/commands/7/kill", "", token); status != http.StatusNoContent {
+		t.Errorf("kill: status = %d, want 204", status)
+	}
+	if status, _ := do("POST", "/v1/commands/8/kill", "", token); status != http.StatusNotFound {
+		t.Errorf("kill of unknown command: status = %d, want 404", status)
+	}
+	if status, _ := do("POST", "/v1/commands/x/kill", "", token); status != http.StatusBadRequest {
+		t.Errorf("kill of invalid id: status = %d, want 400", status)
+	}
+
+	status, body := do("PUT", "/v1/switches/paused", `{"on": true}`, token)
+	var switches map[string]bool
+	if err := json.Unmarshal([]byte(body), &switches); status != http.StatusOK || err != nil || !switches["paused"] {
+		t.Errorf("set switch: %d %s", status, body)
+	}
+	if status, _ := do("PUT", "/v1/switches/paused", `{}`, token); status != http.StatusBadRequest {
+		t.Errorf("set switch without on: status = %d, want 400", status)
+	}
+	if status, _ := do("PUT", "/v1/switches/nope", `{"on": true}`, token); status != http.StatusNotFound {
+		t.Errorf("set unknown switch: status = %d, want 404", status)
+	}
+
+	if status, _ := do("POST", "/v1/drain", "", token); status != http.StatusAccepted {
+		t.Errorf("drain: status = %d, want 202", status)
+	}
+
+	var records []types.ExecutionRecord
+	status, body = do("GET", "/v1/history?decision=denied", "", token)
+	if err := json.Unmarshal([]byte(body), &records); status != http.StatusOK || err != nil || len(records) != 1 || records[0].ID != "h2" {
+		t.Errorf("denials: %d %s", status, body)
+	}
+	status, body = do("GET", "/v1/history?limit=1", "", token)
+	if err := json.Unmarshal([]byte(body), &records); status != http.StatusOK || err != nil || len(records) != 1 {
+		t.Errorf("history with limit: %d %s", status, body)
+	}
+	for _, query := range []string{"?limit=0", "?limit=x", "?decision=maybe"} {
+		if status, _ := do("GET", "/v1/history"+query, "", token); status != http.StatusBadRequest {
+			t.Errorf("history%s: status = %d, want 400", query, status)
+		}
+	}
+
+	if status, body := do("GET", "/v1/config", "", token); status != http.StatusOK || !strings.Contains(body, `"build"`) {
+		t.Errorf("config: %d %s", status, body)
+	}
+	if status, body := do("GET", "/v1/config/drift", "", token); status != http.StatusOK || !strings.Contains(body, `"security.allowed_paths"`) {
+		t.Errorf("config drift: %d %s", status, body)
+	}
+
+	var requests []approval.Request
+	status, body = do("GET", "/v1/approvals", "", token)
+	if err := json.Unmarshal([]byte(body), &requests); status != http.StatusOK || err != nil || len(requests) != 1 || requests[0].ID != "a2" {
+		t.Errorf("pending approvals: %d %s", status, body)
+	}
+	status, body = do("GET", "/v1/approvals?all=true", "", token)
+	if err := json.Unmarshal([]byte(body), &requests); status != http.StatusOK || err != nil || len(requests) != 2 {
+		t.Errorf("all approvals: %d %s", status, body)
+	}
+	if status, body := do("POST", "/v1/approvals/a2/approve", `{"comment": "ok"}`, token); status != http.StatusOK || !strings.Contains(body, "alice") {
+		t.Errorf("approve: %d %s", status, body)
+	}
+	if status, _ := do("POST", "/v1/approvals/a9/reject", "", token); status != http.StatusNotFound {
+		t.Errorf("reject of unknown request: status = %d, want 404", status)
+	}
+	if status, _ := do("POST", "/v1/approvals/a2/maybe", "", token); status != http.StatusNotFound {
+		t.Errorf("unknown decision: status = %d, want 404", status)
+	}
+
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if len(backend.killed) != 1 || !backend.drained {
+		t.Errorf("killed = %v, drained = %v", backend.killed, backend.drained)
+	}
+	if len(backend.approved) != 1 || backend.approved[0] != "a2 ok" {
+		t.Errorf("approved = %v", backend.approved)
+	}
+
+	// The token is removed on close
+	s.Close()
+	if _, err := os.Stat(cfg.Control.TokenFile); !os.IsNotExist(err) {
+		t.Errorf("token file left behind: %v", err)
+	}
+}
+
+func TestServer_dashboard(t *testing.T) {
+	cfg := config.Default()
+	cfg.Control.Addr = "127.0.0.1:0"
+	cfg.Control.TokenFile = filepath.Join(t.TempDir(), "token")
+	s, err := Start(cfg, &fakeBackend{}, logger.Default())
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer s.Close()
+
+	// The dashboard loads without the token, which it asks for
+	for _, path := range []string{"/", "/dashboard.js", "/dashboard.css"} {
+		resp, err := http.Get("http://" + s.Addr() + path)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || len(body) == 0 {
+			t.Errorf("GET %s: status = %d", path, resp.StatusCode)
+		}
+		if csp := resp.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'self'") {
+			t.Errorf("GET %s: Content-Security-Policy = %q", path, csp)
+		}
+	}
+}
+
+func TestStart_requiresLoopback(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:0", ":7070", "example.com:7070", "7070"} {
+		cfg := config.Default()
+		cfg.Control.Addr = addr
+		cfg.Control.TokenFile = filepath.Join(t.TempDir(), "token")
+		if s, err := Start(cfg, &fakeBackend{}, logger.Default()); err == nil {
+			s.Close()
+			t.Errorf("Start(%q) should be refused", addr)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+// Dashboard of the operator control API. The token is taken from the
+// #token= fragment of the URL, which browsers do not send to the server,
+// or asked for, and kept for the browser tab only.
+"use strict";
+
+const tokenKey = "simple-mcp-runner-token";
+const refreshInterval = 2000;
+
+let token = sessionStorage.getItem(tokenKey);
+
+const hash = new URLSearchParams(location.hash.slice(1));
+if (hash.has("token")) {
+  token = hash.get("token");
+  sessionStorage.setItem(tokenKey, token);
+  history.replaceState(null, "", location.pathname);
+}
+
+async function api(method, path, body) {
+  const resp = await fetch(path, {
+    method,
+    headers: {
+      "Authorization": "Bearer " + token,
+      "Content-Type": "application/json",
+    },
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  if (resp.status === 401) {
+    sessionStorage.removeItem(tokenKey);
+    showLogin();
+    throw new Error("the token was refused; the server writes a new one at every start");
+  }
+  if (!resp.ok) {
+    const err = await resp.json().catch(() => ({}));
+    throw new Error(err.error || resp.statusText);
+  }
+  return resp.status === 204 ? null : resp.json();
+}
+
+function el(tag, text, className) {
+  const e = document.createElement(tag);
+  if (text !== undefined) {
+    e.textContent = text;
+  }
+  if (className) {
+    e.className = className;
+  }
+  return e;
+}
+
+function button(label, onClick, className) {
+  const b = el("button", label, className);
+  b.addEventListener("click", () => act(onClick));
+  return b;
+}
+
+// fill replaces the rows of a table body; cells are strings or elements.
+function fill(id, rows, columns) {
+  const body = document.getElementById(id);
+  body.replaceChildren();
+  if (rows.length === 0) {
+    const td = el("td", "None", "empty");
+    td.colSpan = columns;
+    const tr = el("tr");
+    tr.append(td);
+    body.append(tr);
+    return;
+  }
+  for (const cells of rows) {
+    const tr = el("tr");
+    for (const cell of cells) {
+      const td = el("td");
+      td.append(cell ?? "");
+      tr.append(td);
+    }
+    body.append(tr);
+  }
+}
+
+function describe(id, entries) {
+  const dl = document.getElementById(id);
+  dl.replaceChildren();
+  for (const [term, value] of entries) {
+    dl.append(el("dt", term), el("dd", value));
+  }
+}
+
+function commandLine(command, args) {
+  return [command, ...(args || [])].join(" ");
+}
+
+function time(t) {
+  return t ? new Date(t).toLocaleString() : "";
+}
+
+function since(t) {
+  const s = Math.max(0, Math.round((Date.now() - new Date(t)) / 1000));
+  return s < 60 ? s + "s" : Math.floor(s / 60) + "m " + (s % 60) + "s";
+}
+
+function showError(err) {
+  const e = document.getElementById("error");
+  e.textContent = err ? err.message : "";
+  e.hidden = !err;
+}
+
+async function act(fn) {
+  try {
+    await fn();
+    showError(null);
+    await refresh();
+  } catch (err) {
+    showError(err);
+  }
+}
+
+function showLogin() {
+  document.getElementById("main").hidden = true;
+  document.getElementById("login").hidden = false;
+}
+
+async function refresh() {
+  const [stats, commands, approvals, denials, records, sessions] = await Promise.all([
+    api("GET", "/v1/stats"),
+    api("GET", "/v1/commands"),
+    api("GET", "/v1/approvals"),
+    api("GET", "/v1/history?decision=denied&limit=20"),
+    api("GET", "/v1/history?limit=50"),
+    api("GET", "/v1/sessions"),
+  ]);
+
+  const state = document.getElementById("state");
+  state.textContent = stats.switches.paused ? stats.state + ", paused" : stats.state;
+  state.className = "badge " + (stats.switches.paused ? "paused" : stats.state);
+  document.getElementById("uptime").textContent = stats.uptime ? "up " + stats.uptime : "";
+  for (const input of document.querySelectorAll("[data-switch]")) {
+    input.checked = !!stats.switches[input.dataset.switch];
+  }
+  const executions = stats.executions || {};
+  describe("stats", [
+    ["Sessions", String(stats.sessions)],
+    ["Running", String(stats.active_commands)],
+    ["Queued", String(stats.queued_commands)],
+    ["Executions", Object.keys(executions).sort().map((k) => k + " " + executions[k]).join(", ") || "none"],
+    ["Recovered panics", String(stats.crashes)],
+  ]);
+
+  fill("commands", commands.map((c) => [
+    String(c.id),
+    el("code", commandLine(c.command, c.args)),
+    c.workdir,
+    c.client,
+    since(c.started),
+    button("Kill", () => api("POST", "/v1/commands/" + c.id + "/kill"), "danger"),
+  ]), 6);
+
+  fill("approvals", approvals.map((r) => [
+    r.id,
+    el("code", commandLine(r.command, r.args)),
+    r.workdir,
+    [r.requested_by, r.client].filter(Boolean).join(" via "),
+    (r.approved_by || []).length + " of 2" + (r.approved_by ? " (" + r.approved_by.join(", ") + ")" : ""),
+    time(r.expires),
+    decisionButtons(r.id),
+  ]), 7);
+
+  fill("denials", denials.map((d) => [
+    time(d.timestamp),
+    el("code", commandLine(d.request.command, d.request.args)),
+    d.client,
+    d.tripwire ? "Tripwire " + d.tripwire : d.error,
+  ]), 4);
+
+  fill("history", records.map((h) => [
+    time(h.timestamp),
+    h.tool || h.schedule || h.source,
+    el("code", commandLine(h.request.command, h.request.args)),
+    h.client,
+    h.result ? String(h.result.exit_code) : h.error,
+    h.result ? Math.round(h.result.duration_ms / 1e6) + "ms" : "",
+  ]), 6);
+
+  fill("sessions", sessions.map((s) => [
+    s.id,
+    [s.client, s.client_version].filter(Boolean).join(" "),
+    time(s.started),
+    (s.tool_groups || []).join(", "),
+  ]), 4);
+}
+
+function decisionButtons(id) {
+  const span = el("span");
+  span.append(
+    button("Approve", () => api("POST", "/v1/approvals/" + encodeURIComponent(id) + "/approve", {})),
+    " ",
+    button("Reject", () => api("POST", "/v1/approvals/" + encodeURIComponent(id) + "/reject", {}), "danger"),
+  );
+  return span;
+}
+
+async function loadConfig() {
+  const cfg = await api("GET", "/v1/config");
+  const list = (values) => (values && values.length ? values.join(", ") : "none");
+  describe("config", [
+    ["Tools", list(cfg.tools)],
+    ["Commands", list(cfg.commands)],
+    ["Allowed paths", list(cfg.allowed_paths)],
+    ["Denied paths", list(cfg.denied_paths)],
+    ["Allowed commands", list(cfg.allowed_commands)],
+    ["Blocked commands", list(cfg.blocked_commands)],
+    ["Features", list(cfg.features)],
+  ]);
+}
+
+async function start() {
+  document.getElementById("login").hidden = true;
+  document.getElementById("main").hidden = false;
+  try {
+    await loadConfig();
+    await refresh();
+    showError(null);
+  } catch (err) {
+    showError(err);
+  }
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+  document.getElementById("login").addEventListener("submit", (event) => {
+    event.preventDefault();
+    token = document.getElementById("token").value.trim();
+    sessionStorage.setItem(tokenKey, token);
+    start();
+  });
+
+  for (const input of document.querySelectorAll("[data-switch]")) {
+    input.addEventListener("change", () =>
+      act(() => api("PUT", "/v1/switches/" + input.dataset.switch, { on: input.checked })));
+  }
+
+  document.getElementById("drain").addEventListener("click", () => {
+    if (confirm("Refuse new executions, wait for the running ones and stop the server?")) {
+      act(() => api("POST", "/v1/drain"));
+    }
+  });
+
+  setInterval(() => {
+    if (!document.getElementById("main").hidden) {
+      refresh().then(() => showError(null), showError);
+    }
+  }, refreshInterval);
+
+  if (token) {
+    start();
+  } else {
+    showLogin();
+  }
+});
@@ -0,0 +1,136 @@
+// Package debug serves profiling and runtime state over HTTP, so
+// performance problems can be investigated on a running server
+package debug
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// Server serves the debug endpoints.
+type Server struct {
+	http     *http.Server
+	listener net.Listener
+	executor *executor.Executor
+	started  time.Time
+}
+
+// State is the runtime state reported by /debug/state.
+type State struct {
+	Uptime         string                   `json:"uptime"`
+	Goroutines     int                      `json:"goroutines"`
+	HeapAllocBytes uint64                   `json:"heap_alloc_bytes"`
+	ActiveCommands []executor.ActiveCommand `json:"active_commands"`
+	QueuedCommands int                      `json:"queued_commands"`
+}
+
+// Start listens on addr, which must be a loopback address as the
+// endpoints expose internals, and serves:
+//
+//	/debug/pprof/  net/http/pprof profiles
+//	/debug/vars    expvar counters
+//	/debug/state   goroutine count, memory and running commands as JSON
+//	/debug/dump    running commands and every goroutine's stack as text
+func Start(addr string, exec *executor.Executor, log *logger.Logger) (*Server, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, apperrors.ValidationError("invalid debug address: "+addr, "debug-addr")
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, apperrors.ValidationError("debug address must be a loopback address: "+addr, "debug-addr")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to listen on debug address")
+	}
+
+	s := &Server{listener: listener, executor: exec, started: time.Now()}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/state", s.handleState)
+	mux.HandleFunc("/debug/dump", s.handleDump)
+
+	s.http = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := s.http.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("debug server failed")
+		}
+	}()
+
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	return s.http.Close()
+}
+
+// state returns the current runtime state.
+func (s *Server) state() State {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	active := s.executor.Active()
+	if active == nil {
+		active = []executor.ActiveCommand{}
+	}
+	return State{
+		Uptime:         time.Since(s.started).Round(time.Second).String(),
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		ActiveCommands: active,
+		QueuedCommands: s.executor.QueuedCount(),
+	}
+}
+
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(s.state())
+}
+
+func (s *Server) handleDump(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	state := s.state()
+	fmt.Fprintf(w, "Uptime: %s\nGoroutines: %d\nHeap: %d bytes\n\n", state.Uptime, state.Goroutines, state.HeapAllocBytes)
+	fmt.Fprintf(w, "Queued commands: %d\n", state.QueuedCommands)
+	fmt.Fprintf(w, "Active commands (%d):\n", len(state.ActiveCommands))
+	for _, c := range state.ActiveCommands {
+		fmt.Fprintf(w, "  #%d %s %v for %s", c.ID, c.Command, c.Args, time.Since(c.Started).Round(time.Millisecond))
+		if c.WorkDir != "" {
+			fmt.Fprintf(w, " in %s", c.WorkDir)
+		}
+		if c.Client != "" {
+			fmt.Fprintf(w, " via %s", c.Client)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "\nGoroutines:\n")
+	_ = runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+}
@@ -0,0 +1,72 @@
+package debug
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func startTestServer(t *testing.T) *Server {
+	t.Helper()
+	log, _ := logger.New(logger.DefaultOptions())
+	s, err := Start("127.0.0.1:0", executor.New(config.Default(), log), log)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func get(t *testing.T, s *Server, path string) string {
+	t.Helper()
+	resp, err := http.Get("http://" + s.Addr() + path)
+	if err != nil {
+		t.Fatalf("GET %s error = %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s status = %d", path, resp.StatusCode)
+	}
+	return string(body)
+}
+
+func TestServer_endpoints(t *testing.T) {
+	s := startTestServer(t)
+
+	var state State
+	if err := json.Unmarshal([]byte(get(t, s, "/debug/state")), &state); err != nil {
+		t.Fatalf("invalid state: %v", err)
+	}
+	if state.Goroutines == 0 || state.ActiveCommands == nil {
+		t.Errorf("unexpected state %+v", state)
+	}
+
+	if vars := get(t, s, "/debug/vars"); !strings.Contains(vars, `"executor"`) {
+		t.Errorf("expected the executor counters in /debug/vars, got %s", vars)
+	}
+	if dump := get(t, s, "/debug/dump"); !strings.Contains(dump, "Active commands (0)") || !strings.Contains(dump, "goroutine ") {
+		t.Errorf("unexpected dump %s", dump)
+	}
+	if index := get(t, s, "/debug/pprof/"); !strings.Contains(index, "heap") {
+		t.Errorf("expected the pprof index, got %s", index)
+	}
+}
+
+func TestStart_requiresLoopback(t *testing.T) {
+	log, _ := logger.New(logger.DefaultOptions())
+	exec := executor.New(config.Default(), log)
+
+	for _, addr := range []string{"0.0.0.0:0", ":6060", "example.com:6060", "6060"} {
+		if s, err := Start(addr, exec, log); err == nil {
+			s.Close()
+			t.Errorf("Start(%q) should be refused", addr)
+		}
+	}
+}
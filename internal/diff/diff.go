@@ -0,0 +1,239 @@
+// Package diff compares text line by line.
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// maxEdits bounds the work spent finding a minimal diff; beyond it the
+// lines between the common prefix and suffix are reported as replaced.
+var maxEdits = 2000
+
+// Operations of an edit script.
+const (
+	opEqual  = ' '
+	opDelete = '-'
+	opInsert = '+'
+)
+
+// edit is one line of an edit script.
+type edit struct {
+	op   byte
+	line string
+	a, b int // Line indexes in the base and target, of the next line for the other side
+}
+
+// Lines compares base and target line by line and returns the hunks
+// that differ, each with up to context unchanged lines around it. At most
+// maxLines changed and context lines are returned; zero means no limit.
+func Lines(base, target string, context, maxLines int) types.OutputDiff {
+	a, b := splitLines(base), splitLines(target)
+	edits := editScript(a, b)
+
+	d := types.OutputDiff{Hunks: []types.DiffHunk{}}
+	for _, e := range edits {
+		switch e.op {
+		case opEqual:
+			d.Unchanged++
+		case opDelete:
+			d.Removed++
+		case opInsert:
+			d.Added++
+		}
+	}
+	d.Identical = d.Added == 0 && d.Removed == 0
+
+	lines := 0
+	for _, h := range hunks(edits, context) {
+		if maxLines > 0 && lines+len(h.Lines) > maxLines {
+			d.Truncated = true
+			break
+		}
+		lines += len(h.Lines)
+		d.Hunks = append(d.Hunks, h)
+	}
+	return d
+}
+
+// Unified renders hunks in the unified diff format.
+func Unified(d types.OutputDiff) string {
+	var b strings.Builder
+	for _, h := range d.Hunks {
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", h.BaseStart, h.BaseLines, h.TargetStart, h.TargetLines)
+		for _, line := range h.Lines {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// splitLines splits text into lines, without a final empty line.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// editScript returns a shortest edit script turning a into b, found with
+// Myers' algorithm between their common prefix and suffix.
+func editScript(a, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, edit{op: opEqual, line: a[i], a: i, b: i})
+	}
+	middle, ok := myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	if !ok {
+		middle = middle[:0]
+		for i, line := range a[prefix : len(a)-suffix] {
+			middle = append(middle, edit{op: opDelete, line: line, a: i, b: 0})
+		}
+		for i, line := range b[prefix : len(b)-suffix] {
+			middle = append(middle, edit{op: opInsert, line: line, a: len(a) - suffix - prefix, b: i})
+		}
+	}
+	for _, e := range middle {
+		e.a += prefix
+		e.b += prefix
+		edits = append(edits, e)
+	}
+	for i := suffix; i > 0; i-- {
+		edits = append(edits, edit{op: opEqual, line: a[len(a)-i], a: len(a) - i, b: len(b) - i})
+	}
+	return edits
+}
+
+// myers returns a shortest edit script turning a into b, or false when
+// it takes more than maxEdits edits.
+func myers(a, b []string) ([]edit, bool) {
+	n, m := len(a), len(b)
+	max := min(n+m, maxEdits)
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int // Furthest x reached on each diagonal, after each step
+
+	found := false
+	for d := 0; d <= max && !found; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+	if !found {
+		return nil, false
+	}
+
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1] // Indexed by k + d - 1
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && prev[k-1+d-1] < prev[k+1+d-1]) {
+			prevK = k + 1
+		}
+		prevX := prev[prevK+d-1]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{op: opEqual, line: a[x], a: x, b: y})
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, edit{op: opInsert, line: b[y], a: x, b: y})
+		} else {
+			x--
+			edits = append(edits, edit{op: opDelete, line: a[x], a: x, b: y})
+		}
+	}
+	for x > 0 {
+		x--
+		y--
+		edits = append(edits, edit{op: opEqual, line: a[x], a: x, b: y})
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits, true
+}
+
+// hunks groups the changes of an edit script with up to context unchanged
+// lines around them; changes closer than twice that share a hunk.
+func hunks(edits []edit, context int) []types.DiffHunk {
+	var out []types.DiffHunk
+	for i := 0; i < len(edits); {
+		if edits[i].op == opEqual {
+			i++
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+		for end < len(edits) {
+			if edits[end].op != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].op == opEqual {
+				run++
+			}
+			if run == len(edits) || run-end > 2*context {
+				end = min(end+context, run)
+				break
+			}
+			end = run
+		}
+
+		h := types.DiffHunk{BaseStart: edits[start].a + 1, TargetStart: edits[start].b + 1}
+		for _, e := range edits[start:end] {
+			h.Lines = append(h.Lines, string(e.op)+e.line)
+			if e.op != opInsert {
+				h.BaseLines++
+			}
+			if e.op != opDelete {
+				h.TargetLines++
+			}
+		}
+		// Empty ranges start at the line before, as in diff -u
+		if h.BaseLines == 0 {
+			h.BaseStart--
+		}
+		if h.TargetLines == 0 {
+			h.TargetStart--
+		}
+		out = append(out, h)
+		i = end
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package diff
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	base := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	target := "a\nb\nC\nd\ne\nf\ng\nh\ni\nj\nk\n"
+
+	d := Lines(base, target, 1, 0)
+	if d.Identical || d.Added != 2 || d.Removed != 1 || d.Unchanged != 9 {
+		t.Errorf("Lines() = %+v", d)
+	}
+	want := "@@ -2,3 +2,3 @@\n b\n-c\n+C\n d\n@@ -10,1 +10,2 @@\n j\n+k\n"
+	if got := Unified(d); got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+
+	if d := Lines(base, target, 1, 5); !d.Truncated || len(d.Hunks) != 1 {
+		t.Errorf("Lines() with max 5 lines = %+v, want the first hunk only", d)
+	}
+	if d := Lines(base, base, 3, 0); !d.Identical || len(d.Hunks) != 0 {
+		t.Errorf("Lines() of equal text = %+v", d)
+	}
+	if got := Unified(Lines("", "x\n", 3, 0)); got != "@@ -0,0 +1,1 @@\n+x\n" {
+		t.Errorf("Unified() of added text = %q", got)
+	}
+}
+
+func TestLines_reconstruct(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	text := func() string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return strings.Join(lines, "\n")
+	}
+	check := func(base, target string) {
+		t.Helper()
+		d := Lines(base, target, 1<<20, 0)
+		var a, b []string
+		for _, h := range d.Hunks {
+			for _, line := range h.Lines {
+				if line[0] != '+' {
+					a = append(a, line[1:])
+				}
+				if line[0] != '-' {
+					b = append(b, line[1:])
+				}
+			}
+		}
+		if d.Identical {
+			return
+		}
+		if strings.Join(a, "\n") != base || strings.Join(b, "\n") != target {
+			t.Fatalf("hunks of %q -> %q do not reconstruct them: %v", base, target, d.Hunks)
+		}
+	}
+
+	for i := 0; i < 500; i++ {
+		check(text(), text())
+	}
+
+	defer func(n int) { maxEdits = n }(maxEdits)
+	maxEdits = 2
+	for i := 0; i < 100; i++ {
+		check(text(), text())
+	}
+}
+
+func TestMyers_minimal(t *testing.T) {
+	a := strings.Split("a b c a b b a", " ")
+	b := strings.Split("c b a b a c", " ")
+	edits, ok := myers(a, b)
+	if !ok {
+		t.Fatal("myers() gave up")
+	}
+	changes := 0
+	for _, e := range edits {
+		if e.op != opEqual {
+			changes++
+		}
+	}
+	if changes != 5 {
+		t.Errorf("myers() made %d changes, want 5", changes)
+	}
+}
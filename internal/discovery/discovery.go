@@ -9,39 +9,36 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/mjmorales/simple-mcp-runner/pkg/config"
 	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
 	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/internal/quarantine"
 	"github.com/mjmorales/simple-mcp-runner/pkg/types"
 )
 
 // Discoverer handles command discovery.
 type Discoverer struct {
-	config *config.Config
-	logger *logger.Logger
-	cache  *discoveryCache
+	config    *config.Config
+	logger    *logger.Logger
+	index     *index
+	watcher   *fsnotify.Watcher // Set while search paths are watched
+	watchDone chan struct{}
 }
 
-// discoveryCache caches discovery results.
-type discoveryCache struct {
-	mu      sync.RWMutex
-	entries map[string]*cacheEntry
-}
-
-type cacheEntry struct {
-	commands []types.CommandInfo
-	paths    []string
-}
-
-// New creates a new discoverer instance.
+// New creates a new discoverer instance, loading the persisted index
+// unless discovery.disable_index is set.
 func New(cfg *config.Config, log *logger.Logger) *Discoverer {
+	file := ""
+	if !cfg.Discovery.DisableIndex {
+		file = IndexFile(cfg)
+	}
 	return &Discoverer{
 		config: cfg,
 		logger: log,
-		cache: &discoveryCache{
-			entries: make(map[string]*cacheEntry),
-		},
+		index:  newIndex(file, log),
 	}
 }
 
@@ -59,31 +56,26 @@ func (d *Discoverer) Discover(ctx context.Context, req *types.CommandDiscoveryRe
 		}
 	}
 
-	// Check cache
-	cacheKey := d.getCacheKey(req)
-	if cached := d.cache.get(cacheKey); cached != nil {
-		return d.buildResult(cached.commands, cached.paths, req.MaxResults), nil
-	}
-
 	// Get search paths
 	searchPaths := d.getSearchPaths(req)
 
 	// Discover commands
-	commands, err := d.discoverInPaths(ctx, searchPaths, req)
+	commands, stale, err := d.discoverInPaths(ctx, searchPaths, req)
 	if err != nil {
 		return nil, err
 	}
 
+	// Revalidate the index in the background, and save what was scanned
+	if stale {
+		d.index.refresh(searchPaths, d.scanDir)
+	} else {
+		d.index.save()
+	}
+
 	// Sort by relevance
 	d.sortCommands(commands, req.Pattern)
 
-	// Cache results
-	d.cache.set(cacheKey, &cacheEntry{
-		commands: commands,
-		paths:    searchPaths,
-	})
-
-	return d.buildResult(commands, searchPaths, req.MaxResults), nil
+	return d.buildResult(commands, searchPaths, req)
 }
 
 // getSearchPaths returns the paths to search for commands.
@@ -133,108 +125,80 @@ func (d *Discoverer) isExcludedPath(path string) bool {
 	return false
 }
 
-// discoverInPaths discovers commands in the given paths.
-func (d *Discoverer) discoverInPaths(ctx context.Context, paths []string, req *types.CommandDiscoveryRequest) ([]types.CommandInfo, error) {
+// discoverInPaths discovers commands in the given paths, in order, and
+// reports whether the index of any of them should be revalidated.
+func (d *Discoverer) discoverInPaths(ctx context.Context, paths []string, req *types.CommandDiscoveryRequest) ([]types.CommandInfo, bool, error) {
 	var (
-		commands []types.CommandInfo
-		mu       sync.Mutex
-		wg       sync.WaitGroup
-		errChan  = make(chan error, len(paths))
+		found = make([][]types.CommandInfo, len(paths))
+		stale atomic.Bool
+		wg    sync.WaitGroup
 	)
 
 	// Use a semaphore to limit concurrent directory reads
 	sem := make(chan struct{}, 10)
 
-	for _, path := range paths {
+	for i, path := range paths {
 		// Check context
 		select {
 		case <-ctx.Done():
-			return nil, apperrors.TimeoutError("discovery cancelled", "")
+			return nil, false, apperrors.TimeoutError("discovery cancelled", "")
 		default:
 		}
 
 		wg.Add(1)
-		go func(p string) {
+		go func(i int, p string) {
 			defer wg.Done()
 
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			cmds := d.discoverInPath(p, req)
-
-			mu.Lock()
-			commands = append(commands, cmds...)
-			mu.Unlock()
-		}(path)
+			cmds, revalidate := d.discoverInPath(p, req)
+			found[i] = cmds
+			if revalidate {
+				stale.Store(true)
+			}
+		}(i, path)
 	}
 
 	wg.Wait()
-	close(errChan)
 
-	// Check for any critical errors
-	for err := range errChan {
-		if err != nil {
-			return nil, err
-		}
+	var commands []types.CommandInfo
+	for _, cmds := range found {
+		commands = append(commands, cmds...)
 	}
-
-	return d.deduplicateCommands(commands), nil
+	return d.deduplicateCommands(commands), stale.Load(), nil
 }
 
-// discoverInPath discovers commands in a single path.
-func (d *Discoverer) discoverInPath(path string, req *types.CommandDiscoveryRequest) []types.CommandInfo {
-	entries, err := os.ReadDir(path)
-	if err != nil {
-		// Path might not exist or be inaccessible
-		return nil
-	}
+// discoverInPath discovers commands in a single path from the index, and
+// reports whether its index should be revalidated.
+func (d *Discoverer) discoverInPath(path string, req *types.CommandDiscoveryRequest) ([]types.CommandInfo, bool) {
+	names, revalidate := d.index.commands(path, d.scanDir)
 
 	commands := make([]types.CommandInfo, 0)
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		name := entry.Name()
-
-		// Skip hidden files
-		if strings.HasPrefix(name, ".") {
-			continue
-		}
-
+	for _, name := range names {
 		// Check pattern match
 		if !d.matchesPattern(name, req.Pattern) {
 			continue
 		}
 
-		fullPath := filepath.Join(path, name)
-
-		// Check if executable
-		info, err := entry.Info()
-		if err != nil {
-			continue
-		}
-
-		if !d.isExecutable(info) {
-			continue
-		}
-
 		cmd := types.CommandInfo{
 			Name:       name,
-			Path:       fullPath,
+			Path:       filepath.Join(path, name),
 			Executable: true,
 		}
+		cmd.Quarantined = quarantine.Blocked(cmd.Path) != nil
 
 		// Add description if requested
 		if req.IncludeDesc {
 			cmd.Description = d.getCommandDescription(name)
+			d.addKnowledge(&cmd)
 		}
 
 		commands = append(commands, cmd)
 	}
 
-	return commands
+	return commands, revalidate
 }
 
 // matchesPattern checks if a command name matches the pattern.
@@ -372,86 +336,65 @@ func (d *Discoverer) deduplicateCommands(commands []types.CommandInfo) []types.C
 	return result
 }
 
-// sortCommands sorts commands by relevance.
+// sortCommands sorts commands by relevance. The order is total, as
+// commands are deduplicated by name, so pages of results are stable.
 func (d *Discoverer) sortCommands(commands []types.CommandInfo, pattern string) {
 	sort.Slice(commands, func(i, j int) bool {
-		// Exact matches first
-		if commands[i].Name == pattern && commands[j].Name != pattern {
-			return true
-		}
-		if commands[j].Name == pattern && commands[i].Name != pattern {
-			return false
-		}
-
-		// Common commands before others
-		iCommon := d.isCommonCommand(commands[i].Name)
-		jCommon := d.isCommonCommand(commands[j].Name)
-		if iCommon && !jCommon {
-			return true
-		}
-		if jCommon && !iCommon {
-			return false
-		}
-
-		// Alphabetical order
-		return commands[i].Name < commands[j].Name
+		return d.commandLess(commands[i].Name, commands[j].Name, pattern)
 	})
 }
 
-// buildResult builds the discovery result.
-func (d *Discoverer) buildResult(commands []types.CommandInfo, paths []string, maxResults int) *types.CommandDiscoveryResult {
-	totalFound := len(commands)
-	truncated := false
-
-	if maxResults > 0 && len(commands) > maxResults {
-		commands = commands[:maxResults]
-		truncated = true
+// commandLess reports whether the command named a sorts before the one
+// named b: exact matches of the pattern first, then common commands, then
+// alphabetical order.
+func (d *Discoverer) commandLess(a, b, pattern string) bool {
+	// Exact matches first
+	if a == pattern && b != pattern {
+		return true
 	}
-
-	return &types.CommandDiscoveryResult{
-		Commands:    commands,
-		TotalFound:  totalFound,
-		Truncated:   truncated,
-		SearchPaths: paths,
+	if b == pattern && a != pattern {
+		return false
 	}
-}
 
-// getCacheKey generates a cache key for the request.
-func (d *Discoverer) getCacheKey(req *types.CommandDiscoveryRequest) string {
-	parts := []string{
-		req.Pattern,
-		strings.Join(req.Paths, "|"),
+	// Common commands before others
+	aCommon := d.isCommonCommand(a)
+	bCommon := d.isCommonCommand(b)
+	if aCommon && !bCommon {
+		return true
+	}
+	if bCommon && !aCommon {
+		return false
 	}
-	return strings.Join(parts, ":")
-}
-
-// Cache methods
 
-func (c *discoveryCache) get(key string) *cacheEntry {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.entries[key]
+	// Alphabetical order
+	return a < b
 }
 
-func (c *discoveryCache) set(key string, entry *cacheEntry) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	// Simple cache eviction - limit to 100 entries
-	if len(c.entries) >= 100 {
-		// Remove a random entry
-		for k := range c.entries {
-			delete(c.entries, k)
-			break
-		}
+// buildResult builds the requested page of the discovery result.
+func (d *Discoverer) buildResult(commands []types.CommandInfo, paths []string, req *types.CommandDiscoveryRequest) (*types.CommandDiscoveryResult, error) {
+	start, err := d.pageStart(commands, req)
+	if err != nil {
+		return nil, err
+	}
+	end := len(commands)
+	if req.MaxResults > 0 {
+		end = min(start+req.MaxResults, end)
 	}
 
-	c.entries[key] = entry
+	result := &types.CommandDiscoveryResult{
+		Commands:    commands[start:end],
+		TotalFound:  len(commands),
+		Offset:      start,
+		Truncated:   end < len(commands),
+		SearchPaths: paths,
+	}
+	if result.Truncated && end > start {
+		result.NextCursor = encodeCursor(cursor{Pattern: req.Pattern, After: commands[end-1].Name})
+	}
+	return result, nil
 }
 
-// Clear clears the discovery cache.
+// ClearCache forgets the index, so search paths are scanned again.
 func (d *Discoverer) ClearCache() {
-	d.cache.mu.Lock()
-	defer d.cache.mu.Unlock()
-	d.cache.entries = make(map[string]*cacheEntry)
+	d.index.clear()
 }
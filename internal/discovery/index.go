@@ -0,0 +1,221 @@
+package discovery
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// indexVersion is the format of the index file; files of other versions
+// are ignored.
+const indexVersion = 1
+
+// revalidateAfter is how long indexed directories are trusted before they
+// are checked for changes again.
+var revalidateAfter = 30 * time.Second
+
+// IndexFile returns the file the discovery index is persisted in.
+func IndexFile(cfg *config.Config) string {
+	if cfg.Discovery.IndexFile != "" {
+		return cfg.Discovery.IndexFile
+	}
+	return filepath.Join(config.StateDir(), "discovery.json")
+}
+
+// index remembers the executables of each search path, keyed by the
+// modification time of the directory, which changes when files are added,
+// removed or renamed. It is loaded from disk at startup and served right
+// away; directories are revalidated in the background.
+type index struct {
+	mu         sync.Mutex
+	dirs       map[string]*indexedDir
+	watched    map[string]bool // Directories kept up to date by a watcher
+	file       string          // Empty when the index is kept in memory only
+	dirty      bool
+	refreshing atomic.Bool
+	logger     *logger.Logger
+}
+
+// indexedDir holds the executables found in a directory.
+type indexedDir struct {
+	ModTime  time.Time `json:"mod_time"`
+	Commands []string  `json:"commands"`
+
+	checked time.Time // When the directory was last compared with the disk
+}
+
+// indexData is the index file format.
+type indexData struct {
+	Version int                    `json:"version"`
+	Dirs    map[string]*indexedDir `json:"dirs"`
+}
+
+// newIndex returns an index persisted in file, loading it when it exists.
+// An empty file keeps the index in memory only.
+func newIndex(file string, log *logger.Logger) *index {
+	idx := &index{dirs: make(map[string]*indexedDir), watched: make(map[string]bool), file: file, logger: log}
+	if file == "" {
+		return idx
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.WithError(err).Warn("failed to read discovery index", "file", file)
+		}
+		return idx
+	}
+	var loaded indexData
+	if err := json.Unmarshal(data, &loaded); err != nil || loaded.Version != indexVersion {
+		log.Debug("ignoring discovery index", "file", file)
+		return idx
+	}
+	for dir, entry := range loaded.Dirs {
+		if entry != nil {
+			idx.dirs[dir] = entry
+		}
+	}
+	log.Debug("loaded discovery index", "file", file, "dirs", len(idx.dirs))
+	return idx
+}
+
+// commands returns the executables of a directory, scanning it when it is
+// not indexed yet, and whether the index of it should be revalidated.
+func (idx *index) commands(dir string, scan func(string) []string) ([]string, bool) {
+	idx.mu.Lock()
+	entry, ok := idx.dirs[dir]
+	watched := idx.watched[dir]
+	idx.mu.Unlock()
+	if ok {
+		return entry.Commands, !watched && time.Since(entry.checked) > revalidateAfter
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, false
+	}
+	entry = &indexedDir{ModTime: info.ModTime(), Commands: scan(dir), checked: time.Now()}
+	idx.mu.Lock()
+	idx.dirs[dir] = entry
+	idx.dirty = true
+	idx.mu.Unlock()
+	return entry.Commands, false
+}
+
+// refresh revalidates directories in the background, unless a refresh is
+// already running, and saves the index when it changed.
+func (idx *index) refresh(dirs []string, scan func(string) []string) {
+	if !idx.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer idx.refreshing.Store(false)
+		idx.revalidate(dirs, scan)
+		idx.save()
+	}()
+}
+
+// revalidate scans the directories that changed since they were indexed
+// and forgets those that are gone. Watched directories are up to date.
+func (idx *index) revalidate(dirs []string, scan func(string) []string) {
+	for _, dir := range dirs {
+		idx.mu.Lock()
+		entry, ok := idx.dirs[dir]
+		watched := idx.watched[dir]
+		idx.mu.Unlock()
+		if ok && (watched || time.Since(entry.checked) <= revalidateAfter) {
+			continue
+		}
+
+		info, err := os.Stat(dir)
+		idx.mu.Lock()
+		switch {
+		case err != nil:
+			if ok {
+				delete(idx.dirs, dir)
+				idx.dirty = true
+			}
+			idx.mu.Unlock()
+			continue
+		case ok && info.ModTime().Equal(entry.ModTime):
+			idx.dirs[dir] = &indexedDir{ModTime: entry.ModTime, Commands: entry.Commands, checked: time.Now()}
+			idx.mu.Unlock()
+			continue
+		}
+		idx.mu.Unlock()
+
+		updated := &indexedDir{ModTime: info.ModTime(), Commands: scan(dir), checked: time.Now()}
+		idx.mu.Lock()
+		idx.dirs[dir] = updated
+		idx.dirty = true
+		idx.mu.Unlock()
+	}
+}
+
+// save writes the index to its file if it changed since it was last
+// written.
+func (idx *index) save() {
+	idx.mu.Lock()
+	if idx.file == "" || !idx.dirty {
+		idx.mu.Unlock()
+		return
+	}
+	data, err := json.Marshal(indexData{Version: indexVersion, Dirs: idx.dirs})
+	idx.dirty = false
+	idx.mu.Unlock()
+	if err != nil {
+		idx.logger.WithError(err).Warn("failed to encode discovery index")
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(idx.file), 0o700); err != nil {
+		idx.logger.WithError(err).Warn("failed to save discovery index", "file", idx.file)
+		return
+	}
+	tmp := idx.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err == nil {
+		err = os.Rename(tmp, idx.file)
+	}
+	if err != nil {
+		idx.logger.WithError(err).Warn("failed to save discovery index", "file", idx.file)
+	}
+}
+
+// clear forgets every indexed directory, so they are scanned again.
+func (idx *index) clear() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.dirs = make(map[string]*indexedDir)
+	idx.dirty = true
+}
+
+// scanDir returns the executables in a directory, skipping hidden files.
+func (d *Discoverer) scanDir(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		// Path might not exist or be inaccessible
+		return nil
+	}
+
+	commands := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !d.isExecutable(info) {
+			continue
+		}
+		commands = append(commands, entry.Name())
+	}
+	return commands
+}
@@ -0,0 +1,83 @@
+package discovery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestDiscoverer_index(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "bin")
+	if err := os.Mkdir(bin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	name := "warmtool"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	tool := filepath.Join(bin, name)
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Discovery.IndexFile = filepath.Join(dir, "cache", "discovery.json")
+	log, _ := logger.New(logger.DefaultOptions())
+	req := func() *types.CommandDiscoveryRequest {
+		return &types.CommandDiscoveryRequest{Pattern: "warmtool", Paths: []string{bin}}
+	}
+	found := func(d *Discoverer) bool {
+		t.Helper()
+		result, err := d.Discover(context.Background(), req())
+		if err != nil {
+			t.Fatalf("Discover() error = %v", err)
+		}
+		for _, cmd := range result.Commands {
+			if cmd.Path == tool {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !found(New(cfg, log)) {
+		t.Fatal("expected to find the tool")
+	}
+	if _, err := os.Stat(cfg.Discovery.IndexFile); err != nil {
+		t.Fatalf("expected the index to be saved: %v", err)
+	}
+
+	// A new discoverer starts from the saved index, before revalidating it
+	if err := os.Remove(tool); err != nil {
+		t.Fatal(err)
+	}
+	warm := New(cfg, log)
+	if !found(warm) {
+		t.Error("expected the first call to be served from the index")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for warm.index.refreshing.Load() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if found(warm) {
+		t.Error("expected the removed tool to be gone once revalidated")
+	}
+
+	// Without the persisted index nothing is read or written
+	cfg.Discovery.DisableIndex = true
+	cfg.Discovery.IndexFile = filepath.Join(dir, "other.json")
+	if found(New(cfg, log)) {
+		t.Error("expected a fresh scan without the index")
+	}
+	if _, err := os.Stat(cfg.Discovery.IndexFile); !os.IsNotExist(err) {
+		t.Errorf("expected no index file, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package discovery
+
+import (
+	_ "embed"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// builtinKnowledgeYAML documents the invocation of commands whose flags
+// are easy to get wrong.
+//
+//go:embed knowledge.yaml
+var builtinKnowledgeYAML []byte
+
+// builtinKnowledge returns the embedded knowledge base, by command name.
+var builtinKnowledge = sync.OnceValue(func() map[string]config.CommandKnowledge {
+	var knowledge map[string]config.CommandKnowledge
+	if err := yaml.Unmarshal(builtinKnowledgeYAML, &knowledge); err != nil {
+		panic("discovery: invalid knowledge.yaml: " + err.Error())
+	}
+	return knowledge
+})
+
+// knowledge returns what is known of a command: discovery.knowledge, whose
+// fields replace those of the embedded knowledge base when set.
+func (d *Discoverer) knowledge(name string) (config.CommandKnowledge, bool) {
+	baseName := strings.TrimSuffix(name, filepath.Ext(name))
+	k, ok := builtinKnowledge()[baseName]
+	if override, found := d.config.Discovery.Knowledge[baseName]; found {
+		if override.UsageExamples != nil {
+			k.UsageExamples = override.UsageExamples
+		}
+		if override.CommonFlags != nil {
+			k.CommonFlags = override.CommonFlags
+		}
+		ok = true
+	}
+	return k, ok
+}
+
+// addKnowledge adds the usage examples and common flags of a command.
+func (d *Discoverer) addKnowledge(cmd *types.CommandInfo) {
+	k, ok := d.knowledge(cmd.Name)
+	if !ok {
+		return
+	}
+	cmd.UsageExamples = k.UsageExamples
+	for _, f := range k.CommonFlags {
+		cmd.CommonFlags = append(cmd.CommonFlags, types.CommandFlag{Flag: f.Flag, Description: f.Description})
+	}
+}
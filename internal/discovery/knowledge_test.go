@@ -0,0 +1,81 @@
+package discovery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestDiscoverer_knowledge(t *testing.T) {
+	bin := t.TempDir()
+	for _, name := range []string{"tar", "deploy", "plain"} {
+		if runtime.GOOS == "windows" {
+			name += ".exe"
+		}
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Discovery.DisableIndex = true
+	cfg.Discovery.Knowledge = map[string]config.CommandKnowledge{
+		"tar":    {UsageExamples: []string{"tar -xf release.tar"}},
+		"deploy": {CommonFlags: []config.CommandFlag{{Flag: "--env NAME", Description: "Target environment"}}},
+	}
+	log, _ := logger.New(logger.DefaultOptions())
+	d := New(cfg, log)
+
+	discover := func(pattern string, desc bool) types.CommandInfo {
+		t.Helper()
+		result, err := d.Discover(context.Background(), &types.CommandDiscoveryRequest{
+			Pattern: pattern, Paths: []string{bin}, IncludeDesc: desc,
+		})
+		if err != nil {
+			t.Fatalf("Discover() error = %v", err)
+		}
+		if len(result.Commands) == 0 || result.Commands[0].Name != filepath.Base(result.Commands[0].Path) {
+			t.Fatalf("Discover(%q) = %+v", pattern, result.Commands)
+		}
+		return result.Commands[0]
+	}
+
+	tar := discover("tar", true)
+	if len(tar.UsageExamples) != 1 || tar.UsageExamples[0] != "tar -xf release.tar" {
+		t.Errorf("tar usage examples = %v, want the configured one", tar.UsageExamples)
+	}
+	if len(tar.CommonFlags) == 0 {
+		t.Error("expected the built-in tar flags to be kept")
+	}
+
+	deploy := discover("deploy", true)
+	if len(deploy.CommonFlags) != 1 || deploy.CommonFlags[0].Flag != "--env NAME" {
+		t.Errorf("deploy common flags = %+v", deploy.CommonFlags)
+	}
+
+	if plain := discover("plain", true); plain.UsageExamples != nil || plain.CommonFlags != nil {
+		t.Errorf("plain = %+v, want no knowledge", plain)
+	}
+	if tar := discover("tar", false); tar.UsageExamples != nil || tar.CommonFlags != nil {
+		t.Errorf("tar without include_desc = %+v, want no knowledge", tar)
+	}
+}
+
+func TestBuiltinKnowledge(t *testing.T) {
+	for name, k := range builtinKnowledge() {
+		if len(k.UsageExamples) == 0 {
+			t.Errorf("%s has no usage examples", name)
+		}
+		for _, f := range k.CommonFlags {
+			if f.Flag == "" || f.Description == "" {
+				t.Errorf("%s has an incomplete flag: %+v", name, f)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package discovery
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// cursor marks where a page of discovery results ended. Pages continue
+// after the last command returned in sort order, so commands added or
+// removed between calls neither repeat nor shift other commands out of
+// the next page.
+type cursor struct {
+	Pattern string `json:"p"`
+	After   string `json:"a"` // Name of the last command returned
+}
+
+// encodeCursor returns the opaque form of a cursor.
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a cursor returned for the same pattern.
+func decodeCursor(s, pattern string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil || c.After == "" {
+		return c, apperrors.ValidationError("invalid cursor", "cursor")
+	}
+	if c.Pattern != pattern {
+		return c, apperrors.ValidationError("cursor was returned for another pattern", "cursor")
+	}
+	return c, nil
+}
+
+// pageStart returns the index of the first command of the requested page
+// of sorted commands.
+func (d *Discoverer) pageStart(commands []types.CommandInfo, req *types.CommandDiscoveryRequest) (int, error) {
+	if req.Offset < 0 {
+		return 0, apperrors.ValidationError("offset cannot be negative", "offset")
+	}
+	if req.Cursor == "" {
+		return min(req.Offset, len(commands)), nil
+	}
+	if req.Offset > 0 {
+		return 0, apperrors.ValidationError("offset and cursor are exclusive", "offset")
+	}
+	c, err := decodeCursor(req.Cursor, req.Pattern)
+	if err != nil {
+		return 0, err
+	}
+	return sort.Search(len(commands), func(i int) bool {
+		return d.commandLess(c.After, commands[i].Name, req.Pattern)
+	}), nil
+}
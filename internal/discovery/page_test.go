@@ -0,0 +1,86 @@
+package discovery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestDiscoverer_pagination(t *testing.T) {
+	bin := t.TempDir()
+	names := []string{"pgtool-a", "pgtool-b", "pgtool-c", "pgtool-d", "pgtool-e"}
+	for _, name := range names {
+		if runtime.GOOS == "windows" {
+			name += ".exe"
+		}
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Discovery.DisableIndex = true
+	log, _ := logger.New(logger.DefaultOptions())
+	d := New(cfg, log)
+	discover := func(req *types.CommandDiscoveryRequest) (*types.CommandDiscoveryResult, error) {
+		req.Pattern = "pgtool"
+		req.Paths = []string{bin}
+		req.MaxResults = 2
+		return d.Discover(context.Background(), req)
+	}
+
+	var seen []string
+	next := ""
+	for page := 0; page < len(names); page++ {
+		result, err := discover(&types.CommandDiscoveryRequest{Cursor: next})
+		if err != nil {
+			t.Fatalf("Discover() error = %v", err)
+		}
+		for _, cmd := range result.Commands {
+			seen = append(seen, cmd.Name)
+		}
+		if !result.Truncated {
+			if result.NextCursor != "" {
+				t.Errorf("NextCursor = %q on the last page", result.NextCursor)
+			}
+			break
+		}
+		next = result.NextCursor
+		if next == "" {
+			t.Fatal("expected a cursor for a truncated result")
+		}
+	}
+	if len(seen) != len(names) {
+		t.Fatalf("paged commands = %v, want %v", seen, names)
+	}
+	for i := range names {
+		if seen[i] != names[i] {
+			t.Fatalf("paged commands = %v, want %v", seen, names)
+		}
+	}
+
+	result, err := discover(&types.CommandDiscoveryRequest{Offset: 3})
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if result.Offset != 3 || len(result.Commands) != 2 || result.Commands[0].Name != "pgtool-d" {
+		t.Errorf("Discover(offset 3) = offset %d, %+v", result.Offset, result.Commands)
+	}
+
+	if _, err := discover(&types.CommandDiscoveryRequest{Offset: 1, Cursor: next}); err == nil {
+		t.Error("expected an error for offset with cursor")
+	}
+	if _, err := discover(&types.CommandDiscoveryRequest{Cursor: "not-a-cursor"}); err == nil {
+		t.Error("expected an error for an invalid cursor")
+	}
+	other := encodeCursor(cursor{Pattern: "git", After: "git"})
+	if _, err := discover(&types.CommandDiscoveryRequest{Cursor: other}); err == nil {
+		t.Error("expected an error for a cursor of another pattern")
+	}
+}
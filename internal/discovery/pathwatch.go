@@ -0,0 +1,156 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// WatchPaths watches the search paths, PATH and discovery.additional_paths,
+// and updates their index as executables are added and removed, so they
+// never need revalidating. Paths that cannot be watched are revalidated as
+// usual. It does nothing when discovery.disable_watch is set.
+func (d *Discoverer) WatchPaths() {
+	if d.config.Discovery.DisableWatch {
+		return
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		d.logger.WithError(err).Warn("failed to watch search paths")
+		return
+	}
+
+	var watched []string
+	for _, dir := range d.getSearchPaths(&types.CommandDiscoveryRequest{}) {
+		if err := w.Add(dir); err != nil {
+			d.logger.Debug("not watching search path", "path", dir, "error", err)
+			continue
+		}
+		watched = append(watched, dir)
+	}
+	if len(watched) == 0 {
+		w.Close()
+		return
+	}
+
+	d.watcher = w
+	d.watchDone = make(chan struct{})
+	go d.watchLoop(w, watched)
+	d.logger.Debug("watching search paths", "paths", len(watched))
+}
+
+// watchLoop brings the index of the watched directories up to date, then
+// applies filesystem events to it until the watcher is closed.
+func (d *Discoverer) watchLoop(w *fsnotify.Watcher, watched []string) {
+	defer close(d.watchDone)
+
+	// Events are queued meanwhile and apply to the complete index
+	d.index.revalidate(watched, d.scanDir)
+	d.index.watch(watched, true)
+
+	for {
+		select {
+		case event, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			d.applyEvent(event)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			// Events may have been lost, e.g. when the queue overflowed:
+			// fall back to revalidating
+			d.logger.WithError(err).Warn("search path watcher failed, revalidating instead")
+			d.index.watch(watched, false)
+		}
+	}
+}
+
+// applyEvent adds or removes the executable an event is about. Renames
+// report the old name, and the new one is created.
+func (d *Discoverer) applyEvent(event fsnotify.Event) {
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) && !event.Has(fsnotify.Chmod) {
+		return
+	}
+	dir, name := filepath.Split(event.Name)
+	dir = filepath.Clean(dir)
+	if name == "" || strings.HasPrefix(name, ".") {
+		return
+	}
+
+	// Files replaced right away, as package managers do, are still there
+	info, err := os.Lstat(event.Name)
+	present := err == nil && !info.IsDir() && d.isExecutable(info)
+
+	var modTime time.Time
+	if info, err := os.Stat(dir); err == nil {
+		modTime = info.ModTime()
+	}
+	d.index.update(dir, name, present, modTime)
+}
+
+// Close stops watching the search paths and saves the index.
+func (d *Discoverer) Close() error {
+	if d.watcher != nil {
+		err := d.watcher.Close()
+		<-d.watchDone
+		d.watcher = nil
+		if err != nil {
+			return err
+		}
+	}
+	d.index.save()
+	return nil
+}
+
+// watch marks directories as kept up to date by the watcher, or not.
+func (idx *index) watch(dirs []string, watched bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, dir := range dirs {
+		if watched {
+			idx.watched[dir] = true
+			continue
+		}
+		delete(idx.watched, dir)
+		if entry, ok := idx.dirs[dir]; ok {
+			updated := *entry
+			updated.checked = time.Time{}
+			idx.dirs[dir] = &updated
+		}
+	}
+}
+
+// update records that an executable was added to or removed from a
+// directory.
+func (idx *index) update(dir, name string, present bool, modTime time.Time) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	entry, ok := idx.dirs[dir]
+	if !ok {
+		return
+	}
+	i, found := slices.BinarySearch(entry.Commands, name)
+	if found == present {
+		return
+	}
+
+	updated := *entry
+	if present {
+		updated.Commands = slices.Insert(slices.Clone(entry.Commands), i, name)
+	} else {
+		updated.Commands = slices.Delete(slices.Clone(entry.Commands), i, i+1)
+	}
+	if !modTime.IsZero() {
+		updated.ModTime = modTime
+	}
+	updated.checked = time.Now()
+	idx.dirs[dir] = &updated
+	idx.dirty = true
+}
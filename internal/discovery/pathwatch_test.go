@@ -0,0 +1,83 @@
+package discovery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestDiscoverer_WatchPaths(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "bin")
+	if err := os.Mkdir(bin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	name := "watchedtool"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	tool := filepath.Join(bin, name)
+
+	cfg := config.Default()
+	cfg.Discovery.IndexFile = filepath.Join(dir, "discovery.json")
+	cfg.Discovery.AdditionalPaths = []string{bin}
+	log, _ := logger.New(logger.DefaultOptions())
+	d := New(cfg, log)
+	d.WatchPaths()
+	defer d.Close()
+
+	// waitFor polls discovery until the tool is found or not
+	waitFor := func(want bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			result, err := d.Discover(context.Background(), &types.CommandDiscoveryRequest{Pattern: "watchedtool"})
+			if err != nil {
+				t.Fatalf("Discover() error = %v", err)
+			}
+			found := false
+			for _, cmd := range result.Commands {
+				found = found || cmd.Path == tool
+			}
+			if found == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("found = %v, want %v", found, want)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		d.index.mu.Lock()
+		watched := d.index.watched[bin]
+		d.index.mu.Unlock()
+		if watched {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the additional path to be watched")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	waitFor(false)
+
+	// Changes show up without revalidating the directory
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(true)
+	if err := os.Remove(tool); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(false)
+}
@@ -0,0 +1,229 @@
+// Package dlp scans the text returned to clients for sensitive data, such
+// as card numbers, social security numbers and secrets, so deployments can
+// keep it from reaching the model
+package dlp
+
+import (
+	"expvar"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// metrics counts results by the action taken on them.
+var metrics = expvar.NewMap("dlp")
+
+var (
+	// cardNumber matches card numbers grouped by four, grouped like Amex
+	// or not grouped at all.
+	cardNumber = regexp.MustCompile(`\b(?:\d{4}[ -]){3}\d{1,7}\b|\b\d{4}[ -]\d{6}[ -]\d{5}\b|\b\d{13,19}\b`)
+
+	// ssnNumber matches social security numbers written with dashes;
+	// without them, nine digits are too often something else.
+	ssnNumber = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+
+	// token matches the words high_entropy findings are looked for in.
+	token = regexp.MustCompile(`[A-Za-z0-9+_-]{20,}={0,2}`)
+)
+
+// Finding is sensitive data found in a text.
+type Finding struct {
+	Detector   string
+	Action     string
+	Start, End int // Byte offsets of the data
+}
+
+// pattern is a configured detector.
+type pattern struct {
+	name   string
+	re     *regexp.Regexp
+	action string
+}
+
+// Scanner applies the configured detectors.
+type Scanner struct {
+	config     config.DLPConfig
+	patterns   []pattern
+	minEntropy float64
+}
+
+// New returns the configured scanner.
+func New(cfg *config.Config) *Scanner {
+	d := cfg.Security.DLP
+	s := &Scanner{config: d, minEntropy: d.MinEntropy}
+	if s.minEntropy == 0 {
+		s.minEntropy = config.DefaultMinEntropy
+	}
+	for _, p := range d.Patterns {
+		action := p.Action
+		if action == "" {
+			action = d.Action
+		}
+		if re, err := regexp.Compile(p.Pattern); err == nil {
+			s.patterns = append(s.patterns, pattern{name: p.Name, re: re, action: action})
+		}
+	}
+	return s
+}
+
+// Enabled reports whether results are scanned.
+func (s *Scanner) Enabled() bool {
+	return s.config.Enabled()
+}
+
+// Scan returns the findings in text, ordered by position.
+func (s *Scanner) Scan(text string) []Finding {
+	var findings []Finding
+	add := func(detector, action string, loc []int) {
+		findings = append(findings, Finding{Detector: detector, Action: action, Start: loc[0], End: loc[1]})
+	}
+
+	if s.config.Applies(config.DetectorCreditCard) {
+		for _, loc := range cardNumber.FindAllStringIndex(text, -1) {
+			if isCardNumber(text[loc[0]:loc[1]]) {
+				add(config.DetectorCreditCard, s.config.Action, loc)
+			}
+		}
+	}
+	if s.config.Applies(config.DetectorSSN) {
+		for _, loc := range ssnNumber.FindAllStringIndex(text, -1) {
+			if isSSN(text[loc[0]:loc[1]]) {
+				add(config.DetectorSSN, s.config.Action, loc)
+			}
+		}
+	}
+	if s.config.Applies(config.DetectorHighEntropy) {
+		for _, loc := range token.FindAllStringIndex(text, -1) {
+			if s.isSecret(text[loc[0]:loc[1]]) {
+				add(config.DetectorHighEntropy, s.config.Action, loc)
+			}
+		}
+	}
+	for _, p := range s.patterns {
+		for _, loc := range p.re.FindAllStringIndex(text, -1) {
+			if loc[1] > loc[0] {
+				add(p.name, p.action, loc)
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Start < findings[j].Start })
+	return findings
+}
+
+// Redact replaces the findings in text with the names of their detectors.
+// Findings must be ordered by position, as Scan returns them; overlapping
+// findings are replaced together.
+func Redact(text string, findings []Finding) string {
+	var b strings.Builder
+	end := 0
+	for _, f := range findings {
+		if f.Start < end {
+			end = max(end, f.End)
+			continue
+		}
+		b.WriteString(text[end:f.Start])
+		b.WriteString("[REDACTED:" + f.Detector + "]")
+		end = f.End
+	}
+	b.WriteString(text[end:])
+	return b.String()
+}
+
+// Strictest returns the strictest action of the findings: block, redact
+// or alert, or "" without findings.
+func Strictest(findings []Finding) string {
+	strictest := -1
+	for _, f := range findings {
+		strictest = max(strictest, slices.Index(config.DLPActions, f.Action))
+	}
+	if strictest < 0 {
+		return ""
+	}
+	return config.DLPActions[strictest]
+}
+
+// Detectors returns the names of the detectors with findings, sorted.
+func Detectors(findings []Finding) []string {
+	var names []string
+	for _, f := range findings {
+		if !slices.Contains(names, f.Detector) {
+			names = append(names, f.Detector)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Count counts a result the action was taken on.
+func Count(action string) {
+	metrics.Add(action, 1)
+}
+
+// isCardNumber reports whether s is a card number of a major network
+// with a valid Luhn check digit.
+func isCardNumber(s string) bool {
+	digits := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, s)
+	if len(digits) < 13 || len(digits) > 19 || !strings.ContainsAny(digits[:1], "3456") {
+		return false
+	}
+	if strings.Count(digits, digits[:1]) == len(digits) {
+		return false
+	}
+
+	sum := 0
+	for i := range len(digits) {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// isSSN reports whether s is a social security number that can be
+// issued: no area 000, 666 or 900-999, group 00 or serial 0000.
+func isSSN(s string) bool {
+	area, group, serial := s[0:3], s[4:6], s[7:11]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+// isSecret reports whether a token looks random: it mixes upper and
+// lower case letters and digits, and its characters are spread enough.
+func (s *Scanner) isSecret(tok string) bool {
+	tok = strings.TrimRight(tok, "=")
+	if !strings.ContainsFunc(tok, unicode.IsUpper) || !strings.ContainsFunc(tok, unicode.IsLower) ||
+		!strings.ContainsFunc(tok, unicode.IsDigit) {
+		return false
+	}
+	return entropy(tok) >= s.minEntropy
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	var h float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
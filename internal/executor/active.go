@@ -0,0 +1,73 @@
+package executor
+
+import (
+	"context"
+	"expvar"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/security"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// metrics are the execution counters published at /debug/vars.
+var metrics = expvar.NewMap("executor")
+
+// ActiveCommand is a command that is running.
+type ActiveCommand struct {
+	ID      uint64    `json:"id"`
+	Command string    `json:"command"`
+	Args    []string  `json:"args,omitempty"`
+	WorkDir string    `json:"workdir,omitempty"`
+	Client  string    `json:"client,omitempty"`
+	Started time.Time `json:"started"`
+
+	kill context.CancelCauseFunc // Cancels the command
+}
+
+// trackActive records a command as running until the returned function
+// is called. kill cancels the command.
+func (e *Executor) trackActive(ctx context.Context, req *types.CommandExecutionRequest, kill context.CancelCauseFunc) func() {
+	id := atomic.AddUint64(&e.nextActiveID, 1)
+	e.active.Store(id, ActiveCommand{
+		ID:      id,
+		Command: req.Command,
+		Args:    req.Args,
+		WorkDir: req.WorkDir,
+		Client:  security.FromContext(ctx).Client(),
+		Started: time.Now(),
+		kill:    kill,
+	})
+	atomic.AddInt32(&e.activeCommands, 1)
+	metrics.Add("started", 1)
+
+	return func() {
+		e.active.Delete(id)
+		atomic.AddInt32(&e.activeCommands, -1)
+	}
+}
+
+// recordMetrics counts the outcome of a command.
+func recordMetrics(result *types.CommandExecutionResult) {
+	switch {
+	case result.TimedOut:
+		metrics.Add("timed_out", 1)
+	case result.ExitCode != 0 || result.ErrorMessage != "":
+		metrics.Add("failed", 1)
+	default:
+		metrics.Add("succeeded", 1)
+	}
+	metrics.Add("output_bytes", int64(len(result.Stdout)+len(result.Stderr)))
+}
+
+// Active returns the running commands, oldest first.
+func (e *Executor) Active() []ActiveCommand {
+	var out []ActiveCommand
+	e.active.Range(func(_, v any) bool {
+		out = append(out, v.(ActiveCommand))
+		return true
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
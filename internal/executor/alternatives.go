@@ -0,0 +1,36 @@
+package executor
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// alternatives are built-in tools that do safely what commonly blocked
+// binaries do, suggested when those binaries are denied.
+var alternatives = map[string]string{
+	"rm":       "delete_path",
+	"rmdir":    "delete_path",
+	"unlink":   "delete_path",
+	"del":      "delete_path",
+	"erase":    "delete_path",
+	"rd":       "delete_path",
+	"kill":     "terminate_process",
+	"pkill":    "terminate_process",
+	"killall":  "terminate_process",
+	"taskkill": "terminate_process",
+	"chmod":    "change_permissions",
+	"du":       "analyze_disk_usage",
+	"tail":     "tail_file",
+}
+
+// alternative returns a hint naming the tool to use instead of a denied
+// command, or "" if there is none.
+func (e *Executor) alternative(command string) string {
+	name := strings.ToLower(filepath.Base(command))
+	name = strings.TrimSuffix(name, ".exe")
+	tool, ok := alternatives[name]
+	if !ok || (tool == "delete_path" && e.config.Trash.Disabled) {
+		return ""
+	}
+	return e.msg.Sprintf("; use the %s tool instead", e.config.Server.ToolPrefix+tool)
+}
@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/approval"
+	"github.com/mjmorales/simple-mcp-runner/internal/policy"
+	"github.com/mjmorales/simple-mcp-runner/internal/security"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// checkApproval holds runs of commands requiring a second approval, by
+// name, and why if not for requires_second_approval. Without an approval
+// ID a request is created and the run denied; with one, the request must be
+// approved for exactly this run. consumeApproval uses it up once the run
+// starts.
+func (e *Executor) checkApproval(ctx context.Context, name string, req *types.CommandExecutionRequest, id, reason string) error {
+	sc := security.FromContext(ctx)
+
+	if id == "" {
+		pending, err := e.approvals.Request(name, req.Args, req.WorkDir, sc)
+		if err != nil {
+			return err
+		}
+		e.logger.Info("approval requested",
+			"command", name,
+			"approval_id", pending.ID,
+			"reason", reason,
+			"user", sc.User(),
+			"client", sc.Client(),
+		)
+		msg := e.msg.Sprintf("command requires approval by %d operators; created approval request %s. "+
+			"Operators approve with: simple-mcp-runner approvals approve %s. "+
+			"Run again with approval_id %s once approved", approval.Required, pending.ID, pending.ID, pending.ID)
+		if reason != "" {
+			msg = reason + "; " + msg
+		}
+		return apperrors.PermissionError(msg, name)
+	}
+
+	_, err := e.approvals.Check(id, name, req.Args, req.WorkDir)
+	return err
+}
+
+// consumeApproval uses up the approval of a run about to start.
+func (e *Executor) consumeApproval(ctx context.Context, name string, req *types.CommandExecutionRequest, id string) error {
+	approved, err := e.approvals.Consume(id, name, req.Args, req.WorkDir, security.FromContext(ctx))
+	if err != nil {
+		return err
+	}
+	e.logger.Info("running approved command",
+		"command", name,
+		"approval_id", id,
+		"approved_by", approved.ApprovedBy,
+	)
+	return nil
+}
+
+// recordApprovedRun adds the outcome of an approved run to its audit trail.
+func (e *Executor) recordApprovedRun(id string, result *types.CommandExecutionResult, runErr error) {
+	exitCode := -1
+	if result != nil {
+		exitCode = result.ExitCode
+	}
+	if err := e.approvals.Executed(id, exitCode, runErr); err != nil {
+		e.logger.WithError(err).Warn("failed to record approved run", "approval_id", id)
+	}
+}
+
+// packageApproval describes why a package policy holds a run.
+func (e *Executor) packageApproval(held *policy.PackageApproval) string {
+	return e.msg.Sprintf("%s %s held by package policy: %s", held.Manager, held.Operation, held.Reason)
+}
+
+// holdReason explains why package policies or screening hold a request
+// for approval, or returns "" if nothing does.
+func (e *Executor) holdReason(req *types.CommandExecutionRequest, flag *policy.ScreeningFlag) string {
+	var reasons []string
+	if _, held := e.packages.Check(req.Command, req.Args); held != nil {
+		reasons = append(reasons, e.packageApproval(held))
+	}
+	if flag != nil {
+		reasons = append(reasons, e.screeningReason(flag))
+	}
+	return strings.Join(reasons, "; ")
+}
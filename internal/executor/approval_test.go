@@ -0,0 +1,172 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/approval"
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/internal/receipt"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestExecutor_ExecuteConfigCommandSecondApproval(t *testing.T) {
+	cfg := config.Default()
+	cfg.Approvals.File = filepath.Join(t.TempDir(), "approvals.jsonl")
+	keys := operatorKeys(t, cfg, "alice", "bob")
+	e := New(cfg, logger.Default())
+	ctx := context.Background()
+	cmd := &config.Command{Name: "deploy", Command: "echo", Args: []string{"deploying"}, RequiresSecondApproval: true}
+
+	// The first run is held and creates a request
+	if _, err := e.ExecuteConfigCommand(ctx, cmd, ""); err == nil || !strings.Contains(err.Error(), "approval request") {
+		t.Fatalf("expected run to be held for approval, got %v", err)
+	}
+	requests, err := e.approvals.List()
+	if err != nil || len(requests) != 1 {
+		t.Fatalf("expected one approval request, got %d (%v)", len(requests), err)
+	}
+	id := requests[0].ID
+
+	// One approval is not enough
+	if _, err := e.approvals.Approve(id, keys["alice"], ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.ExecuteConfigCommandWithOptions(ctx, cmd, "", ConfigCommandOptions{ApprovalID: id}); err == nil {
+		t.Fatal("expected run with one approval to be denied")
+	}
+
+	if _, err := e.approvals.Approve(id, keys["bob"], ""); err != nil {
+		t.Fatal(err)
+	}
+	result, err := e.ExecuteConfigCommandWithOptions(ctx, cmd, "", ConfigCommandOptions{ApprovalID: id})
+	if err != nil {
+		t.Fatalf("expected approved run to succeed, got %v", err)
+	}
+	if strings.TrimSpace(result.Stdout) != "deploying" {
+		t.Errorf("unexpected output %q", result.Stdout)
+	}
+
+	// The outcome is part of the audit trail, and the approval is used up
+	req, err := e.approvals.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := req.Events[len(req.Events)-1]
+	if req.Status != approval.StatusConsumed || last.Type != approval.EventExecuted || *last.ExitCode != 0 {
+		t.Errorf("unexpected request state %s, last event %+v", req.Status, last)
+	}
+	if _, err := e.ExecuteConfigCommandWithOptions(ctx, cmd, "", ConfigCommandOptions{ApprovalID: id}); err == nil {
+		t.Error("expected a consumed approval to be rejected")
+	}
+}
+
+func TestExecutor_ExecutePackageApproval(t *testing.T) {
+	cfg := config.Default()
+	cfg.Approvals.File = filepath.Join(t.TempDir(), "approvals.jsonl")
+	// echo stands in for npm so nothing is installed
+	cfg.Security.PackagePolicies = []config.PackagePolicy{{Manager: "npm", Commands: []string{"echo"}, Packages: []string{"typescript"}}}
+	keys := operatorKeys(t, cfg, "alice", "bob")
+	e := New(cfg, logger.Default())
+	ctx := context.Background()
+
+	if _, err := e.Execute(ctx, &types.CommandExecutionRequest{Command: "echo", Args: []string{"install", "typescript"}}); err != nil {
+		t.Fatalf("expected allowlisted install to run, got %v", err)
+	}
+
+	req := &types.CommandExecutionRequest{Command: "echo", Args: []string{"install", "left-pad"}}
+	_, err := e.Execute(ctx, req)
+	if err == nil || !strings.Contains(err.Error(), "held by package policy") || !strings.Contains(err.Error(), "left-pad") {
+		t.Fatalf("expected install to be held for approval, got %v", err)
+	}
+	requests, err := e.approvals.List()
+	if err != nil || len(requests) != 1 {
+		t.Fatalf("expected one approval request, got %d (%v)", len(requests), err)
+	}
+	id := requests[0].ID
+	for _, key := range keys {
+		if _, err := e.approvals.Approve(id, key, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req.ApprovalID = id
+	result, err := e.Execute(ctx, req)
+	if err != nil {
+		t.Fatalf("expected approved install to run, got %v", err)
+	}
+	if strings.TrimSpace(result.Stdout) != "install left-pad" {
+		t.Errorf("unexpected output %q", result.Stdout)
+	}
+	if approved, _ := e.approvals.Get(id); approved.Status != approval.StatusConsumed {
+		t.Errorf("expected the approval to be used up, got %s", approved.Status)
+	}
+}
+
+func TestExecutor_Admit(t *testing.T) {
+	cfg := config.Default()
+	cfg.Approvals.File = filepath.Join(t.TempDir(), "approvals.jsonl")
+	cfg.Security.BlockedCommands = []string{"rm"}
+	cfg.Security.PackagePolicies = []config.PackagePolicy{{Manager: "npm", Commands: []string{"echo"}, Packages: []string{"typescript"}}}
+	keys := operatorKeys(t, cfg, "alice", "bob")
+	e := New(cfg, logger.Default())
+	ctx := context.Background()
+
+	if _, err := e.Admit(ctx, &types.CommandExecutionRequest{Command: "rm", Args: []string{"-rf", "/"}}); err == nil {
+		t.Error("expected blocked program to be denied")
+	}
+
+	// Held programs need an approval, which admitting uses up
+	req := &types.CommandExecutionRequest{Command: "echo", Args: []string{"install", "left-pad"}}
+	if _, err := e.Admit(ctx, req); err == nil || !strings.Contains(err.Error(), "approval request") {
+		t.Fatalf("expected program to be held for approval, got %v", err)
+	}
+	requests, err := e.approvals.List()
+	if err != nil || len(requests) != 1 {
+		t.Fatalf("expected one approval request, got %d (%v)", len(requests), err)
+	}
+	id := requests[0].ID
+	for _, key := range keys {
+		if _, err := e.approvals.Approve(id, key, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req.ApprovalID = id
+	if got, err := e.Admit(ctx, req); err != nil || got != id {
+		t.Fatalf("Admit() = %q, %v; want %q", got, err, id)
+	}
+	if approved, _ := e.approvals.Get(id); approved.Status != approval.StatusConsumed {
+		t.Errorf("expected the approval to be used up, got %s", approved.Status)
+	}
+}
+
+// operatorKeys configures operators with new keys and returns their
+// signing keys.
+func operatorKeys(t *testing.T, cfg *config.Config, names ...string) map[string]*receipt.Signer {
+	t.Helper()
+	dir := t.TempDir()
+	keys := make(map[string]*receipt.Signer)
+	for _, name := range names {
+		private, public, err := receipt.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyFile := filepath.Join(dir, name+".pem")
+		pubFile := filepath.Join(dir, name+".pub")
+		if err := os.WriteFile(keyFile, private, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(pubFile, public, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cfg.Approvals.Operators = append(cfg.Approvals.Operators, config.ApprovalOperator{Name: name, PublicKey: pubFile})
+		if keys[name], err = receipt.LoadSigner(keyFile); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,261 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// MaxBatchSteps limits the number of steps accepted in a single batch.
+const MaxBatchSteps = 100
+
+// ExecuteBatch runs a set of commands as a dependency graph. Steps without
+// dependencies start immediately and run in parallel under the executor's
+// concurrency limit; a step waits for every step it depends on and is
+// skipped if any of them did not succeed. Values captured from a step's
+// stdout are substituted into the args of the steps that depend on it.
+func (e *Executor) ExecuteBatch(ctx context.Context, req *types.BatchExecutionRequest) (*types.BatchExecutionResult, error) {
+	levels, err := planBatch(req.Steps)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCaptures(req.Steps); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	results := make([]types.BatchStepResult, len(req.Steps))
+	done := make(map[string]chan struct{}, len(req.Steps))
+	index := make(map[string]int, len(req.Steps))
+	for i, step := range req.Steps {
+		done[step.ID] = make(chan struct{})
+		index[step.ID] = i
+	}
+
+	var wg sync.WaitGroup
+	for i := range req.Steps {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			step := req.Steps[i]
+			defer close(done[step.ID])
+
+			results[i] = types.BatchStepResult{
+				ID:        step.ID,
+				DependsOn: step.DependsOn,
+				Level:     levels[step.ID],
+			}
+
+			// Wait for dependencies. Results are written before a step's
+			// channel is closed, so reading them afterwards is safe.
+			for _, dep := range step.DependsOn {
+				select {
+				case <-done[dep]:
+				case <-ctx.Done():
+					results[i].Status = types.BatchStepSkipped
+					results[i].Error = "batch cancelled"
+					return
+				}
+				if results[index[dep]].Status != types.BatchStepSucceeded {
+					results[i].Status = types.BatchStepSkipped
+					results[i].Error = fmt.Sprintf("dependency %q did not succeed", dep)
+					return
+				}
+			}
+
+			resolveStepCaptures(&step, results, index)
+			e.runBatchStep(ctx, &step, &results[i])
+		}(i)
+	}
+	wg.Wait()
+
+	result := &types.BatchExecutionResult{
+		Steps:    results,
+		Levels:   groupLevels(req.Steps, levels),
+		Duration: time.Since(startTime),
+	}
+	for _, r := range results {
+		switch r.Status {
+		case types.BatchStepSucceeded:
+			result.Succeeded++
+		case types.BatchStepFailed:
+			result.Failed++
+		default:
+			result.Skipped++
+		}
+	}
+
+	e.logger.WithFields(map[string]any{
+		"steps":     len(results),
+		"succeeded": result.Succeeded,
+		"failed":    result.Failed,
+		"skipped":   result.Skipped,
+		"duration":  result.Duration.Milliseconds(),
+	}).Info("batch executed")
+
+	return result, nil
+}
+
+// runBatchStep executes a single step and records its outcome.
+func (e *Executor) runBatchStep(ctx context.Context, step *types.BatchStep, out *types.BatchStepResult) {
+	res, err := e.Execute(ctx, &types.CommandExecutionRequest{
+		Command: step.Command,
+		Args:    step.Args,
+		WorkDir: step.WorkDir,
+		Env:     step.Env,
+		Timeout: step.Timeout,
+	})
+	if err != nil {
+		var appErr *apperrors.Error
+		out.Status = types.BatchStepFailed
+		out.Error = err.Error()
+		out.Err = err
+		out.Denied = errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypePermission
+		return
+	}
+
+	out.Result = res
+	if res.ExitCode != 0 || res.TimedOut {
+		out.Status = types.BatchStepFailed
+		return
+	}
+
+	captures, err := extractCaptures(step.Capture, res.Stdout)
+	if err != nil {
+		out.Status = types.BatchStepFailed
+		out.Error = err.Error()
+		return
+	}
+
+	out.Captures = captures
+	out.Status = types.BatchStepSucceeded
+}
+
+// resolveStepCaptures substitutes {{step.name}} references in a step's args
+// and workdir with values captured by earlier steps. References were
+// validated to point at dependencies, whose results are already final.
+func resolveStepCaptures(step *types.BatchStep, results []types.BatchStepResult, index map[string]int) {
+	captures := make(map[string]map[string]string)
+	for _, field := range append([]string{step.WorkDir}, step.Args...) {
+		for _, ref := range captureRefPattern.FindAllStringSubmatch(field, -1) {
+			captures[ref[1]] = results[index[ref[1]]].Captures
+		}
+	}
+	if len(captures) == 0 {
+		return
+	}
+
+	args := make([]string, len(step.Args))
+	for i, arg := range step.Args {
+		args[i] = expandCaptures(arg, captures)
+	}
+	step.Args = args
+	step.WorkDir = expandCaptures(step.WorkDir, captures)
+}
+
+// planBatch validates the dependency graph and returns the depth of every
+// step. Steps without dependencies have depth 0.
+func planBatch(steps []types.BatchStep) (map[string]int, error) {
+	if len(steps) == 0 {
+		return nil, apperrors.ValidationError("batch must contain at least one step", "steps")
+	}
+	if len(steps) > MaxBatchSteps {
+		return nil, apperrors.ValidationError(
+			fmt.Sprintf("too many batch steps: %d > %d", len(steps), MaxBatchSteps),
+			"steps",
+		)
+	}
+
+	byID := make(map[string]*types.BatchStep, len(steps))
+	for i := range steps {
+		step := &steps[i]
+		if step.ID == "" {
+			return nil, apperrors.ValidationError(fmt.Sprintf("step %d is missing an id", i), "steps")
+		}
+		if _, exists := byID[step.ID]; exists {
+			return nil, apperrors.ValidationError("duplicate step id: "+step.ID, "steps")
+		}
+		byID[step.ID] = step
+	}
+
+	for _, step := range steps {
+		for _, dep := range step.DependsOn {
+			if _, ok := byID[dep]; !ok {
+				return nil, apperrors.ValidationError(
+					fmt.Sprintf("step %q depends on unknown step %q", step.ID, dep),
+					"depends_on",
+				)
+			}
+			if dep == step.ID {
+				return nil, apperrors.ValidationError(
+					fmt.Sprintf("step %q depends on itself", step.ID),
+					"depends_on",
+				)
+			}
+		}
+	}
+
+	// Compute depths with a depth-first walk, detecting cycles on the way.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(steps))
+	levels := make(map[string]int, len(steps))
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visiting:
+			return apperrors.ValidationError("dependency cycle detected at step "+id, "depends_on")
+		case visited:
+			return nil
+		}
+		state[id] = visiting
+
+		level := 0
+		for _, dep := range byID[id].DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+			if levels[dep]+1 > level {
+				level = levels[dep] + 1
+			}
+		}
+
+		levels[id] = level
+		state[id] = visited
+		return nil
+	}
+
+	for _, step := range steps {
+		if err := visit(step.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return levels, nil
+}
+
+// groupLevels groups step IDs by depth, preserving request order within a level.
+func groupLevels(steps []types.BatchStep, levels map[string]int) [][]string {
+	maxLevel := 0
+	for _, level := range levels {
+		if level > maxLevel {
+			maxLevel = level
+		}
+	}
+
+	grouped := make([][]string, maxLevel+1)
+	for _, step := range steps {
+		level := levels[step.ID]
+		grouped[level] = append(grouped[level], step.ID)
+	}
+	return grouped
+}
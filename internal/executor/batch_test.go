@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestPlanBatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []types.BatchStep
+		want    map[string]int
+		wantErr string
+	}{
+		{
+			name: "independent and dependent steps",
+			steps: []types.BatchStep{
+				{ID: "lint"},
+				{ID: "test"},
+				{ID: "build", DependsOn: []string{"lint", "test"}},
+				{ID: "package", DependsOn: []string{"build"}},
+			},
+			want: map[string]int{"lint": 0, "test": 0, "build": 1, "package": 2},
+		},
+		{
+			name:    "empty batch",
+			wantErr: "at least one step",
+		},
+		{
+			name:    "missing id",
+			steps:   []types.BatchStep{{Command: "echo"}},
+			wantErr: "missing an id",
+		},
+		{
+			name:    "duplicate id",
+			steps:   []types.BatchStep{{ID: "a"}, {ID: "a"}},
+			wantErr: "duplicate step id",
+		},
+		{
+			name:    "unknown dependency",
+			steps:   []types.BatchStep{{ID: "a", DependsOn: []string{"b"}}},
+			wantErr: "unknown step",
+		},
+		{
+			name:    "self dependency",
+			steps:   []types.BatchStep{{ID: "a", DependsOn: []string{"a"}}},
+			wantErr: "depends on itself",
+		},
+		{
+			name: "cycle",
+			steps: []types.BatchStep{
+				{ID: "a", DependsOn: []string{"c"}},
+				{ID: "b", DependsOn: []string{"a"}},
+				{ID: "c", DependsOn: []string{"b"}},
+			},
+			wantErr: "cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := planBatch(tt.steps)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("levels = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutor_ExecuteBatch(t *testing.T) {
+	cfg := config.Default()
+	log, _ := logger.New(logger.DefaultOptions())
+	exec := New(cfg, log)
+
+	req := &types.BatchExecutionRequest{
+		Steps: []types.BatchStep{
+			{ID: "first", Command: "echo", Args: []string{"one"}},
+			{ID: "second", Command: "echo", Args: []string{"two"}},
+			{ID: "joined", Command: "echo", Args: []string{"three"}, DependsOn: []string{"first", "second"}},
+			{ID: "broken", Command: "nonexistentcommand123"},
+			{ID: "after_broken", Command: "echo", DependsOn: []string{"broken"}},
+			{ID: "blocked", Command: "rm", Args: []string{"-rf", "/tmp/nothing"}},
+		},
+	}
+
+	result, err := exec.ExecuteBatch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	statuses := make(map[string]string)
+	for _, step := range result.Steps {
+		statuses[step.ID] = step.Status
+	}
+
+	want := map[string]string{
+		"first":        types.BatchStepSucceeded,
+		"second":       types.BatchStepSucceeded,
+		"joined":       types.BatchStepSucceeded,
+		"broken":       types.BatchStepFailed,
+		"after_broken": types.BatchStepSkipped,
+		"blocked":      types.BatchStepFailed,
+	}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+
+	if result.Succeeded != 3 || result.Failed != 2 || result.Skipped != 1 {
+		t.Errorf("unexpected counts: %+v", result)
+	}
+
+	wantLevels := [][]string{{"first", "second", "broken", "blocked"}, {"joined", "after_broken"}}
+	if !reflect.DeepEqual(result.Levels, wantLevels) {
+		t.Errorf("levels = %v, want %v", result.Levels, wantLevels)
+	}
+
+	if !strings.Contains(result.Steps[2].Result.Stdout, "three") {
+		t.Errorf("expected joined step output, got %q", result.Steps[2].Result.Stdout)
+	}
+}
@@ -0,0 +1,19 @@
+package executor
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// BinaryPath returns the file a command runs: a name is looked up in PATH
+// and a relative path is taken from the working directory.
+func BinaryPath(command, workDir string) (string, error) {
+	if !strings.ContainsAny(command, `/\`) {
+		return exec.LookPath(command)
+	}
+	if !filepath.IsAbs(command) && workDir != "" {
+		command = filepath.Join(workDir, command)
+	}
+	return filepath.Abs(command)
+}
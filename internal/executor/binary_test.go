@@ -0,0 +1,24 @@
+package executor
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBinaryPath(t *testing.T) {
+	dir := t.TempDir()
+
+	got, err := BinaryPath(filepath.Join("bin", "tool"), dir)
+	if err != nil || got != filepath.Join(dir, "bin", "tool") {
+		t.Errorf("relative path resolved to %q (%v)", got, err)
+	}
+
+	abs := filepath.Join(dir, "tool")
+	if got, err := BinaryPath(abs, "/elsewhere"); err != nil || got != abs {
+		t.Errorf("absolute path resolved to %q (%v)", got, err)
+	}
+
+	if _, err := BinaryPath("no-such-command-"+filepath.Base(dir), ""); err == nil {
+		t.Error("expected missing command to fail")
+	}
+}
@@ -0,0 +1,227 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// captureRefPattern matches {{step_id.name}} references in step arguments.
+var captureRefPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_-]+)\.([A-Za-z0-9_-]+)\s*\}\}`)
+
+// isJSONPath reports whether a capture expression is a JSON path rather than
+// a regular expression.
+func isJSONPath(expr string) bool {
+	return expr == "$" || strings.HasPrefix(expr, "$.") || strings.HasPrefix(expr, "$[")
+}
+
+// validateCaptures checks capture expressions and ensures every {{step.name}}
+// reference points at a capture declared by a step the referencing step
+// (transitively) depends on. It must run after planBatch has rejected cycles.
+func validateCaptures(steps []types.BatchStep) error {
+	byID := make(map[string]*types.BatchStep, len(steps))
+	for i := range steps {
+		byID[steps[i].ID] = &steps[i]
+	}
+
+	for _, step := range steps {
+		for name, expr := range step.Capture {
+			if isJSONPath(expr) {
+				if _, err := parseJSONPath(expr); err != nil {
+					return apperrors.ValidationError(
+						fmt.Sprintf("step %q capture %q: %v", step.ID, name, err),
+						"capture",
+					)
+				}
+				continue
+			}
+			if _, err := regexp.Compile(expr); err != nil {
+				return apperrors.ValidationError(
+					fmt.Sprintf("step %q capture %q: invalid regex: %v", step.ID, name, err),
+					"capture",
+				)
+			}
+		}
+	}
+
+	for _, step := range steps {
+		ancestors := make(map[string]bool)
+		collectAncestors(byID, step.ID, ancestors)
+
+		for _, field := range append([]string{step.WorkDir}, step.Args...) {
+			for _, ref := range captureRefPattern.FindAllStringSubmatch(field, -1) {
+				source, name := ref[1], ref[2]
+				if !ancestors[source] {
+					return apperrors.ValidationError(
+						fmt.Sprintf("step %q references %s but does not depend on step %q", step.ID, ref[0], source),
+						"args",
+					)
+				}
+				if _, ok := byID[source].Capture[name]; !ok {
+					return apperrors.ValidationError(
+						fmt.Sprintf("step %q references %s but step %q does not capture %q", step.ID, ref[0], source, name),
+						"args",
+					)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// collectAncestors adds every step that id transitively depends on.
+func collectAncestors(byID map[string]*types.BatchStep, id string, into map[string]bool) {
+	for _, dep := range byID[id].DependsOn {
+		if !into[dep] {
+			into[dep] = true
+			collectAncestors(byID, dep, into)
+		}
+	}
+}
+
+// extractCaptures evaluates a step's capture expressions against its stdout.
+func extractCaptures(capture map[string]string, stdout string) (map[string]string, error) {
+	if len(capture) == 0 {
+		return nil, nil
+	}
+
+	values := make(map[string]string, len(capture))
+	for name, expr := range capture {
+		var (
+			value string
+			err   error
+		)
+		if isJSONPath(expr) {
+			value, err = captureJSONPath(expr, stdout)
+		} else {
+			value, err = captureRegex(expr, stdout)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("capture %q: %w", name, err)
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+// captureRegex returns the first submatch of expr in output, or the whole
+// match when the expression has no groups. Expressions run in multi-line
+// mode so ^ and $ match at line boundaries.
+func captureRegex(expr, output string) (string, error) {
+	re, err := regexp.Compile("(?m)" + expr)
+	if err != nil {
+		return "", err
+	}
+
+	match := re.FindStringSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("pattern %q did not match output", expr)
+	}
+	if len(match) > 1 {
+		return match[1], nil
+	}
+	return match[0], nil
+}
+
+// captureJSONPath parses output as JSON and resolves the path against it.
+func captureJSONPath(path, output string) (string, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(output), &value); err != nil {
+		return "", fmt.Errorf("output is not valid JSON: %w", err)
+	}
+
+	for _, seg := range segments {
+		switch key := seg.(type) {
+		case string:
+			obj, ok := value.(map[string]any)
+			if !ok {
+				return "", fmt.Errorf("%s: cannot read field %q of non-object", path, key)
+			}
+			if value, ok = obj[key]; !ok {
+				return "", fmt.Errorf("%s: field %q not found", path, key)
+			}
+		case int:
+			arr, ok := value.([]any)
+			if !ok {
+				return "", fmt.Errorf("%s: cannot index non-array", path)
+			}
+			if key < 0 || key >= len(arr) {
+				return "", fmt.Errorf("%s: index %d out of range", path, key)
+			}
+			value = arr[key]
+		}
+	}
+
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// parseJSONPath splits a path like $.items[0].name into field names
+// (strings) and array indexes (ints).
+func parseJSONPath(path string) ([]any, error) {
+	if !isJSONPath(path) {
+		return nil, fmt.Errorf("JSON path must start with $")
+	}
+
+	var segments []any
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty field name in JSON path %q", path)
+			}
+			segments = append(segments, rest[:end])
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated index in JSON path %q", path)
+			}
+			idx, err := strconv.Atoi(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid index in JSON path %q", path)
+			}
+			segments = append(segments, idx)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in JSON path %q", rest[0], path)
+		}
+	}
+
+	return segments, nil
+}
+
+// expandCaptures replaces {{step.name}} references using captured values.
+func expandCaptures(s string, captures map[string]map[string]string) string {
+	return captureRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		m := captureRefPattern.FindStringSubmatch(ref)
+		if value, ok := captures[m[1]][m[2]]; ok {
+			return value
+		}
+		return ref
+	})
+}
@@ -0,0 +1,174 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestExtractCaptures(t *testing.T) {
+	tests := []struct {
+		name    string
+		capture map[string]string
+		stdout  string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "regex submatch",
+			capture: map[string]string{"sha": `^commit (\w+)`},
+			stdout:  "header\ncommit abc123\nAuthor: someone\n",
+			want:    map[string]string{"sha": "abc123"},
+		},
+		{
+			name:    "regex whole match",
+			capture: map[string]string{"version": `\d+\.\d+\.\d+`},
+			stdout:  "tool version 1.22.3 (linux)",
+			want:    map[string]string{"version": "1.22.3"},
+		},
+		{
+			name:    "json path",
+			capture: map[string]string{"name": "$.items[1].name", "count": "$.count"},
+			stdout:  `{"count": 2, "items": [{"name": "a"}, {"name": "b"}]}`,
+			want:    map[string]string{"name": "b", "count": "2"},
+		},
+		{
+			name:    "regex no match",
+			capture: map[string]string{"sha": `^commit (\w+)`},
+			stdout:  "nothing here",
+			wantErr: true,
+		},
+		{
+			name:    "json path on invalid json",
+			capture: map[string]string{"name": "$.name"},
+			stdout:  "not json",
+			wantErr: true,
+		},
+		{
+			name:    "json index out of range",
+			capture: map[string]string{"name": "$[3]"},
+			stdout:  `[1, 2]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractCaptures(tt.capture, tt.stdout)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractCaptures() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("capture %q = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateCaptures(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []types.BatchStep
+		wantErr string
+	}{
+		{
+			name: "transitive dependency reference",
+			steps: []types.BatchStep{
+				{ID: "a", Capture: map[string]string{"v": `(\d+)`}},
+				{ID: "b", DependsOn: []string{"a"}},
+				{ID: "c", DependsOn: []string{"b"}, Args: []string{"--value={{a.v}}"}},
+			},
+		},
+		{
+			name: "reference without dependency",
+			steps: []types.BatchStep{
+				{ID: "a", Capture: map[string]string{"v": `(\d+)`}},
+				{ID: "b", Args: []string{"{{a.v}}"}},
+			},
+			wantErr: "does not depend on",
+		},
+		{
+			name: "unknown capture name",
+			steps: []types.BatchStep{
+				{ID: "a", Capture: map[string]string{"v": `(\d+)`}},
+				{ID: "b", DependsOn: []string{"a"}, Args: []string{"{{a.other}}"}},
+			},
+			wantErr: "does not capture",
+		},
+		{
+			name:    "invalid regex",
+			steps:   []types.BatchStep{{ID: "a", Capture: map[string]string{"v": `(`}}},
+			wantErr: "invalid regex",
+		},
+		{
+			name:    "invalid json path",
+			steps:   []types.BatchStep{{ID: "a", Capture: map[string]string{"v": `$[x]`}}},
+			wantErr: "invalid index",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCaptures(tt.steps)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestExecutor_ExecuteBatchWithCaptures(t *testing.T) {
+	cfg := config.Default()
+	log, _ := logger.New(logger.DefaultOptions())
+	exec := New(cfg, log)
+
+	req := &types.BatchExecutionRequest{
+		Steps: []types.BatchStep{
+			{
+				ID:      "source",
+				Command: "echo",
+				Args:    []string{"branch: feature-x"},
+				Capture: map[string]string{"branch": `^branch: (\S+)`},
+			},
+			{
+				ID:        "use",
+				Command:   "echo",
+				Args:      []string{"pushing", "{{source.branch}}"},
+				DependsOn: []string{"source"},
+			},
+			{
+				ID:      "missing",
+				Command: "echo",
+				Args:    []string{"no match"},
+				Capture: map[string]string{"sha": `^commit (\w+)`},
+			},
+		},
+	}
+
+	result, err := exec.ExecuteBatch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := result.Steps[0].Captures["branch"]; got != "feature-x" {
+		t.Errorf("captured branch = %q, want feature-x", got)
+	}
+	if !strings.Contains(result.Steps[1].Result.Stdout, "pushing feature-x") {
+		t.Errorf("expected substituted arg, got %q", result.Steps[1].Result.Stdout)
+	}
+	if result.Steps[2].Status != types.BatchStepFailed {
+		t.Errorf("expected failed status for unmatched capture, got %s", result.Steps[2].Status)
+	}
+}
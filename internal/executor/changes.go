@@ -0,0 +1,118 @@
+package executor
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// skippedDirs are not descended into when tracking changes.
+var skippedDirs = map[string]bool{
+	".git": true,
+	".hg":  true,
+	".svn": true,
+}
+
+// fileState identifies a version of a file.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// snapshot records the regular files below a directory.
+type snapshot struct {
+	files     map[string]fileState
+	truncated bool
+}
+
+// takeSnapshot walks dir and records the size and modification time of up
+// to limit files. Files that vanish during the walk are ignored.
+func takeSnapshot(dir string, limit int) (*snapshot, error) {
+	snap := &snapshot{files: make(map[string]fileState)}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		if limit > 0 && len(snap.files) >= limit {
+			snap.truncated = true
+			return filepath.SkipAll
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+		snap.files[filepath.ToSlash(rel)] = fileState{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to snapshot working directory")
+	}
+
+	return snap, nil
+}
+
+// diffSnapshots reports the files created, modified and deleted between
+// two snapshots, keeping at most limit paths in total.
+func diffSnapshots(before, after *snapshot, limit int) *types.FileChanges {
+	changes := &types.FileChanges{
+		Truncated: before.truncated || after.truncated,
+	}
+
+	for path, state := range after.files {
+		prev, ok := before.files[path]
+		switch {
+		case !ok:
+			// A file beyond the limit of the first walk may be old
+			if !before.truncated {
+				changes.Created = append(changes.Created, path)
+			}
+		case prev.size != state.size || !prev.modTime.Equal(state.modTime):
+			changes.Modified = append(changes.Modified, path)
+		}
+	}
+	for path := range before.files {
+		if _, ok := after.files[path]; !ok && !after.truncated {
+			changes.Deleted = append(changes.Deleted, path)
+		}
+	}
+
+	sort.Strings(changes.Created)
+	sort.Strings(changes.Modified)
+	sort.Strings(changes.Deleted)
+
+	if limit > 0 {
+		remaining := limit
+		for _, list := range []*[]string{&changes.Created, &changes.Modified, &changes.Deleted} {
+			if len(*list) > remaining {
+				*list = (*list)[:remaining]
+				changes.Truncated = true
+			}
+			remaining -= len(*list)
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,118 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "keep.txt"), "keep")
+	writeTestFile(t, filepath.Join(dir, "edit.txt"), "old")
+	writeTestFile(t, filepath.Join(dir, "gone.txt"), "gone")
+	writeTestFile(t, filepath.Join(dir, ".git", "HEAD"), "ref")
+
+	before, err := takeSnapshot(dir, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "edit.txt"), "new content")
+	writeTestFile(t, filepath.Join(dir, "sub", "new.txt"), "new")
+	writeTestFile(t, filepath.Join(dir, ".git", "index"), "idx")
+	if err := os.Remove(filepath.Join(dir, "gone.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := takeSnapshot(dir, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	changes := diffSnapshots(before, after, 0)
+	if !reflect.DeepEqual(changes.Created, []string{"sub/new.txt"}) {
+		t.Errorf("unexpected created: %v", changes.Created)
+	}
+	if !reflect.DeepEqual(changes.Modified, []string{"edit.txt"}) {
+		t.Errorf("unexpected modified: %v", changes.Modified)
+	}
+	if !reflect.DeepEqual(changes.Deleted, []string{"gone.txt"}) {
+		t.Errorf("unexpected deleted: %v", changes.Deleted)
+	}
+	if changes.Truncated {
+		t.Error("expected untruncated changes")
+	}
+
+	// The report is capped
+	limited := diffSnapshots(before, after, 2)
+	if n := len(limited.Created) + len(limited.Modified) + len(limited.Deleted); n != 2 || !limited.Truncated {
+		t.Errorf("expected 2 truncated changes, got %d (truncated=%v)", n, limited.Truncated)
+	}
+}
+
+func TestTakeSnapshotLimit(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		writeTestFile(t, filepath.Join(dir, name), name)
+	}
+
+	snap, err := takeSnapshot(dir, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(snap.files) != 2 || !snap.truncated {
+		t.Errorf("expected 2 files and truncation, got %d (truncated=%v)", len(snap.files), snap.truncated)
+	}
+}
+
+func TestExecutor_ExecuteConfigCommandTrackChanges(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses touch")
+	}
+
+	e := New(config.Default(), logger.Default())
+	dir := t.TempDir()
+
+	cmd := &config.Command{Name: "touch", Command: "touch", Args: []string{"out.txt"}, TrackChanges: true}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := e.ExecuteConfigCommand(ctx, cmd, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Changes == nil {
+		t.Fatal("expected changes to be reported")
+	}
+	if !reflect.DeepEqual(result.Changes.Created, []string{"out.txt"}) {
+		t.Errorf("unexpected created: %v", result.Changes.Created)
+	}
+
+	// Untracked commands report nothing
+	cmd.TrackChanges = false
+	result, err = e.ExecuteConfigCommand(ctx, cmd, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Changes != nil {
+		t.Errorf("expected no changes for untracked command, got %+v", result.Changes)
+	}
+}
@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// injectFault delays or fails an execution as the chaos settings ask, so
+// clients can be tested against a misbehaving server. A delayed execution
+// holds its slot like a slow command would.
+func (e *Executor) injectFault(ctx context.Context, req *types.CommandExecutionRequest) error {
+	chaos := e.config.Chaos
+	if !chaos.Enabled() {
+		return nil
+	}
+
+	rate := chaos.LatencyRate
+	if rate == 0 {
+		rate = 100
+	}
+	if chaos.Latency > 0 && chance(rate) {
+		delay := rand.N(chaos.Latency.Std() + 1)
+		e.logger.Debug("injecting latency", "command", req.Command, "delay", delay)
+		metrics.Add("injected_delays", 1)
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return apperrors.TimeoutError("context cancelled during injected latency", delay.String())
+		}
+	}
+
+	if chance(chaos.FailureRate) {
+		e.logger.Debug("injecting failure", "command", req.Command)
+		metrics.Add("injected_failures", 1)
+		return apperrors.ExecutionError("injected failure (chaos.failure_rate)", req.Command)
+	}
+	return nil
+}
+
+// chance reports true with a probability of percent in 100.
+func chance(percent float64) bool {
+	return percent > 0 && rand.Float64()*100 < percent
+}
@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestExecutor_injectFault(t *testing.T) {
+	req := &types.CommandExecutionRequest{Command: "go", Args: []string{"version"}}
+
+	t.Run("disabled", func(t *testing.T) {
+		e := New(config.Default(), logger.Default())
+		if err := e.injectFault(context.Background(), req); err != nil {
+			t.Errorf("injectFault() error = %v", err)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Chaos.FailureRate = 100
+		e := New(cfg, logger.Default())
+		err := e.injectFault(context.Background(), req)
+		if !errors.Is(err, &apperrors.Error{Type: apperrors.ErrorTypeExecution}) {
+			t.Errorf("injectFault() error = %v, want an execution error", err)
+		}
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Chaos.Latency = config.Duration(20 * time.Millisecond)
+		e := New(cfg, logger.Default())
+		start := time.Now()
+		for range 5 {
+			if err := e.injectFault(context.Background(), req); err != nil {
+				t.Fatalf("injectFault() error = %v", err)
+			}
+		}
+		if elapsed := time.Since(start); elapsed > 5*20*time.Millisecond+time.Second {
+			t.Errorf("5 calls took %s, want at most 20ms each", elapsed)
+		}
+
+		// A cancelled call stops waiting
+		cfg.Chaos.Latency = config.Duration(time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := e.injectFault(ctx, req)
+		if !errors.Is(err, &apperrors.Error{Type: apperrors.ErrorTypeTimeout}) {
+			t.Errorf("injectFault() error = %v, want a timeout", err)
+		}
+	})
+}
+
+func TestChance(t *testing.T) {
+	for range 100 {
+		if chance(0) {
+			t.Fatal("chance(0) = true")
+		}
+		if !chance(100) {
+			t.Fatal("chance(100) = false")
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// devcontainerFiles are the files that define a devcontainer, relative to
+// the project directory.
+var devcontainerFiles = []string{
+	filepath.Join(".devcontainer", "devcontainer.json"),
+	".devcontainer.json",
+}
+
+// devEnvironment returns the project environment a request runs in under
+// execution.dev_environment, or nil to run it on the host. Requests for
+// nix or devcontainer themselves are not wrapped.
+func (e *Executor) devEnvironment(req *types.CommandExecutionRequest) *types.DevEnvironment {
+	mode := e.config.Execution.DevEnvironment
+	if mode == "" || mode == config.DevEnvOff {
+		return nil
+	}
+	switch strings.TrimSuffix(strings.ToLower(filepath.Base(req.Command)), ".exe") {
+	case "nix", "devcontainer":
+		return nil
+	}
+
+	dir := requestDir(req)
+	if dir == "" {
+		return nil
+	}
+	return findDevEnvironment(dir, mode)
+}
+
+// requestDir returns the absolute directory a request runs in, or "" if
+// it cannot be determined.
+func requestDir(req *types.CommandExecutionRequest) string {
+	dir := req.WorkDir
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return ""
+		}
+		dir = wd
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// findDevEnvironment looks for a flake.nix or devcontainer definition the
+// mode accepts in dir and its parents, up to the root of the git
+// repository dir is in. A flake.nix is preferred when both are in the
+// same directory.
+func findDevEnvironment(dir, mode string) *types.DevEnvironment {
+	for {
+		if mode != config.DevEnvDevcontainer && config.Exists(filepath.Join(dir, "flake.nix")) {
+			return &types.DevEnvironment{Kind: config.DevEnvNix, Root: dir}
+		}
+		if mode != config.DevEnvNix {
+			for _, name := range devcontainerFiles {
+				if config.Exists(filepath.Join(dir, name)) {
+					return &types.DevEnvironment{Kind: config.DevEnvDevcontainer, Root: dir}
+				}
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir || config.Exists(filepath.Join(dir, ".git")) {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// devEnvCommand returns the command line running a request in a project
+// environment. Devcontainers start commands in the workspace folder, so
+// requests for a subdirectory change to it first, and the request's
+// environment variables are passed with --remote-env.
+func devEnvCommand(env *types.DevEnvironment, req *types.CommandExecutionRequest) (string, []string) {
+	switch env.Kind {
+	case config.DevEnvNix:
+		return "nix", append([]string{"develop", env.Root, "-c", req.Command}, req.Args...)
+	case config.DevEnvDevcontainer:
+		args := []string{"exec", "--workspace-folder", env.Root}
+		for _, kv := range req.Env {
+			args = append(args, "--remote-env", kv)
+		}
+		dir := requestDir(req)
+		if rel, err := filepath.Rel(env.Root, dir); dir != "" && err == nil && rel != "." {
+			args = append(args, "sh", "-c", `cd "$1" && shift && exec "$@"`, "sh", filepath.ToSlash(rel))
+		}
+		return "devcontainer", append(append(args, req.Command), req.Args...)
+	}
+	return req.Command, req.Args
+}
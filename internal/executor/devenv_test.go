@@ -0,0 +1,110 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// writeFiles creates empty files under dir.
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindDevEnvironment(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root,
+		"nix/flake.nix",
+		"nix/pkg/src/main.go",
+		"container/.devcontainer/devcontainer.json",
+		"container/api/go.mod",
+		"both/flake.nix",
+		"both/.devcontainer.json",
+		"repo/.git/HEAD",
+		"repo/sub/file",
+	)
+	writeFiles(t, root, "flake.nix") // Above repo, whose .git stops the search
+
+	tests := []struct {
+		dir, mode string
+		want      *types.DevEnvironment
+	}{
+		{"nix/pkg/src", config.DevEnvAuto, &types.DevEnvironment{Kind: "nix", Root: filepath.Join(root, "nix")}},
+		{"nix/pkg/src", config.DevEnvNix, &types.DevEnvironment{Kind: "nix", Root: filepath.Join(root, "nix")}},
+		{"container/api", config.DevEnvAuto, &types.DevEnvironment{Kind: "devcontainer", Root: filepath.Join(root, "container")}},
+		{"container/api", config.DevEnvDevcontainer, &types.DevEnvironment{Kind: "devcontainer", Root: filepath.Join(root, "container")}},
+		{"both", config.DevEnvAuto, &types.DevEnvironment{Kind: "nix", Root: filepath.Join(root, "both")}},
+		{"both", config.DevEnvDevcontainer, &types.DevEnvironment{Kind: "devcontainer", Root: filepath.Join(root, "both")}},
+		{"container/api", config.DevEnvNix, &types.DevEnvironment{Kind: "nix", Root: root}},
+		{"repo/sub", config.DevEnvAuto, nil},
+	}
+	for _, tt := range tests {
+		got := findDevEnvironment(filepath.Join(root, tt.dir), tt.mode)
+		if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+			t.Errorf("findDevEnvironment(%s, %s) = %+v, want %+v", tt.dir, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestExecutor_devEnvironment(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, "flake.nix")
+
+	cfg := config.Default()
+	e := &Executor{config: cfg}
+	req := &types.CommandExecutionRequest{Command: "go", Args: []string{"test"}, WorkDir: root}
+	if env := e.devEnvironment(req); env != nil {
+		t.Errorf("devEnvironment() = %+v with dev_environment off", env)
+	}
+
+	cfg.Execution.DevEnvironment = config.DevEnvAuto
+	if env := e.devEnvironment(req); env == nil || env.Kind != "nix" {
+		t.Errorf("devEnvironment() = %+v, want nix", env)
+	}
+	req.Command = "nix"
+	if env := e.devEnvironment(req); env != nil {
+		t.Errorf("devEnvironment() = %+v for nix itself", env)
+	}
+}
+
+func TestDevEnvCommand(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "work", "proj")
+	req := &types.CommandExecutionRequest{
+		Command: "go",
+		Args:    []string{"test", "./..."},
+		WorkDir: filepath.Join(root, "api"),
+		Env:     []string{"CGO_ENABLED=0"},
+	}
+
+	name, args := devEnvCommand(&types.DevEnvironment{Kind: "nix", Root: root}, req)
+	if want := []string{"develop", root, "-c", "go", "test", "./..."}; name != "nix" || !slices.Equal(args, want) {
+		t.Errorf("devEnvCommand(nix) = %s %q, want nix %q", name, args, want)
+	}
+
+	name, args = devEnvCommand(&types.DevEnvironment{Kind: "devcontainer", Root: root}, req)
+	want := []string{"exec", "--workspace-folder", root, "--remote-env", "CGO_ENABLED=0",
+		"sh", "-c", `cd "$1" && shift && exec "$@"`, "sh", "api", "go", "test", "./..."}
+	if name != "devcontainer" || !slices.Equal(args, want) {
+		t.Errorf("devEnvCommand(devcontainer) = %s %q, want devcontainer %q", name, args, want)
+	}
+
+	// Commands in the workspace folder run as they are
+	req.WorkDir, req.Env = root, nil
+	_, args = devEnvCommand(&types.DevEnvironment{Kind: "devcontainer", Root: root}, req)
+	if want := []string{"exec", "--workspace-folder", root, "go", "test", "./..."}; !slices.Equal(args, want) {
+		t.Errorf("devEnvCommand(devcontainer) = %q, want %q", args, want)
+	}
+}
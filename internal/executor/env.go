@@ -0,0 +1,76 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// MaskedValue replaces the values of sensitive environment variables.
+const MaskedValue = "********"
+
+// sensitiveEnvPatterns are name fragments of variables that usually hold
+// secrets.
+var sensitiveEnvPatterns = []string{
+	"*SECRET*", "*TOKEN*", "*PASSWORD*", "*PASSWD*", "*PASSPHRASE*",
+	"*API_KEY*", "*APIKEY*", "*ACCESS_KEY*", "*PRIVATE_KEY*", "*_KEY",
+	"*CREDENTIAL*", "*AUTH*", "*COOKIE*", "*DSN*", "*CONNECTION_STRING*",
+}
+
+// InheritedEnv returns the server environment that executed commands
+// inherit, after applying the env_allow and env_deny policy. With
+// login_shell_env it is the environment of the user's login shell.
+func (e *Executor) InheritedEnv() []string {
+	environ := os.Environ()
+	if e.login != nil {
+		if env := e.loginShellEnv(); env != nil {
+			environ = env
+		}
+	}
+	env := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if e.envInherited(name) {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+// envInherited reports whether the env policy passes a variable to commands.
+func (e *Executor) envInherited(name string) bool {
+	if matchEnv(e.config.Security.EnvDeny, name) {
+		return false
+	}
+	return len(e.config.Security.EnvAllow) == 0 || matchEnv(e.config.Security.EnvAllow, name)
+}
+
+// IsSensitiveEnv reports whether a variable's value should be masked.
+func (e *Executor) IsSensitiveEnv(name string) bool {
+	return matchEnv(sensitiveEnvPatterns, strings.ToUpper(name)) ||
+		matchEnv(e.config.Security.SensitiveEnv, name)
+}
+
+// commandEnv returns the environment for a request: the inherited
+// environment followed by the request's own variables.
+func (e *Executor) commandEnv(extra []string) []string {
+	return append(e.InheritedEnv(), extra...)
+}
+
+// matchEnv reports whether name matches any of the glob patterns. Names
+// are case-insensitive on Windows.
+func matchEnv(patterns []string, name string) bool {
+	if runtime.GOOS == "windows" {
+		name = strings.ToUpper(name)
+	}
+	for _, pattern := range patterns {
+		if runtime.GOOS == "windows" {
+			pattern = strings.ToUpper(pattern)
+		}
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
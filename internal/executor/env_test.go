@@ -0,0 +1,111 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func hasEnv(env []string, name string) bool {
+	for _, kv := range env {
+		if strings.HasPrefix(kv, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+func TestExecutor_InheritedEnv(t *testing.T) {
+	t.Setenv("SMR_TEST_KEEP", "1")
+	t.Setenv("SMR_TEST_DROP", "1")
+	t.Setenv("SMR_OTHER", "1")
+
+	tests := []struct {
+		name  string
+		allow []string
+		deny  []string
+		want  map[string]bool
+	}{
+		{
+			name: "inherit everything by default",
+			want: map[string]bool{"SMR_TEST_KEEP": true, "SMR_TEST_DROP": true, "SMR_OTHER": true},
+		},
+		{
+			name: "deny pattern",
+			deny: []string{"SMR_TEST_DROP"},
+			want: map[string]bool{"SMR_TEST_KEEP": true, "SMR_TEST_DROP": false, "SMR_OTHER": true},
+		},
+		{
+			name:  "allow pattern",
+			allow: []string{"SMR_TEST_*"},
+			want:  map[string]bool{"SMR_TEST_KEEP": true, "SMR_TEST_DROP": true, "SMR_OTHER": false},
+		},
+		{
+			name:  "deny wins over allow",
+			allow: []string{"SMR_TEST_*"},
+			deny:  []string{"*_DROP"},
+			want:  map[string]bool{"SMR_TEST_KEEP": true, "SMR_TEST_DROP": false, "SMR_OTHER": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Security.EnvAllow = tt.allow
+			cfg.Security.EnvDeny = tt.deny
+			e := New(cfg, logger.Default())
+
+			env := e.InheritedEnv()
+			for name, want := range tt.want {
+				if got := hasEnv(env, name); got != want {
+					t.Errorf("%s inherited = %v, want %v", name, got, want)
+				}
+			}
+
+			cmdEnv := e.commandEnv([]string{"SMR_EXTRA=1"})
+			if !hasEnv(cmdEnv, "SMR_EXTRA") {
+				t.Error("expected request variables in command environment")
+			}
+		})
+	}
+}
+
+func TestExecutor_IsSensitiveEnv(t *testing.T) {
+	cfg := config.Default()
+	cfg.Security.SensitiveEnv = []string{"INTERNAL_*"}
+	e := New(cfg, logger.Default())
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"PATH", false},
+		{"GOPATH", false},
+		{"NODE_ENV", false},
+		{"PWD", false},
+		{"GITHUB_TOKEN", true},
+		{"AWS_SECRET_ACCESS_KEY", true},
+		{"DB_PASSWORD", true},
+		{"OPENAI_API_KEY", true},
+		{"npm_config_authtoken", true},
+		{"SENTRY_DSN", true},
+		{"INTERNAL_URL", true},
+	}
+
+	for _, tt := range tests {
+		if got := e.IsSensitiveEnv(tt.name); got != tt.want {
+			t.Errorf("IsSensitiveEnv(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseEnv(t *testing.T) {
+	out := "HOME=/home/user\nMULTI=first\nsecond line\nSHLVL=2\n_=/usr/bin/env\nPATH=/usr/bin:/bin\n"
+	got := parseEnv(out)
+	want := []string{"HOME=/home/user", "MULTI=first\nsecond line", "PATH=/usr/bin:/bin"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("parseEnv() = %q, want %q", got, want)
+	}
+}
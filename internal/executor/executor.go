@@ -6,17 +6,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+	"unsafe"
 
 	"github.com/mjmorales/simple-mcp-runner/pkg/config"
 	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/internal/approval"
+	"github.com/mjmorales/simple-mcp-runner/internal/i18n"
 	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/internal/plugin"
+	"github.com/mjmorales/simple-mcp-runner/internal/policy"
+	"github.com/mjmorales/simple-mcp-runner/internal/security"
+	"github.com/mjmorales/simple-mcp-runner/internal/tripwire"
 	"github.com/mjmorales/simple-mcp-runner/pkg/types"
 )
 
@@ -25,7 +33,24 @@ type Executor struct {
 	config         *config.Config
 	logger         *logger.Logger
 	activeCommands int32
-	semaphore      chan struct{}
+	active         sync.Map // ID to ActiveCommand
+	nextActiveID   uint64
+	panics         int64
+	slots          *slots
+	groups         groupLocks
+	workDirs       workDirCache
+	learner        *policy.Recorder // Set in learn mode
+	approvals      *approval.Store
+	conditions     *policy.Conditions
+	cliPolicies    *policy.CLIPolicies
+	packages       *policy.PackagePolicies
+	tripwires      *tripwire.Wires
+	screening      *policy.Screening
+	msg            *i18n.Printer // Translates denial messages
+	plugins        *plugin.Host  // Policy and output plugins of configured commands
+	login          *loginEnv     // Set with login_shell_env
+	paused         atomic.Bool   // Operator switch refusing executions
+	blockMutating  atomic.Bool   // Operator switch refusing mutating commands
 }
 
 // New creates a new executor instance.
@@ -35,66 +60,276 @@ func New(cfg *config.Config, log *logger.Logger) *Executor {
 		maxConcurrent = 10
 	}
 
-	return &Executor{
-		config:    cfg,
-		logger:    log,
-		semaphore: make(chan struct{}, maxConcurrent),
+	e := &Executor{
+		config:      cfg,
+		logger:      log,
+		slots:       newSlots(maxConcurrent, cfg.Execution.MaxQueue),
+		approvals:   approval.New(cfg),
+		conditions:  policy.NewConditions(cfg),
+		cliPolicies: policy.NewCLIPolicies(cfg),
+		packages:    policy.NewPackagePolicies(cfg),
+		tripwires:   tripwire.New(cfg, log),
+		screening:   policy.NewScreening(cfg),
+		msg:         i18n.New(cfg.Server.Locale),
 	}
+
+	// Record denied commands for policy suggestions
+	if cfg.Security.Policy == config.PolicyLearn {
+		e.learner = policy.NewRecorder(policy.SuggestionsFile(cfg))
+	}
+
+	if cfg.Chaos.Enabled() {
+		log.Warn("fault injection enabled: executions are delayed or failed on purpose",
+			"latency", cfg.Chaos.Latency.String(),
+			"latency_rate", cfg.Chaos.LatencyRate,
+			"failure_rate", cfg.Chaos.FailureRate,
+		)
+	}
+
+	// Run commands in the environment of the user's login shell
+	if cfg.Execution.LoginShellEnv {
+		if runtime.GOOS == "windows" {
+			log.Warn("login_shell_env is not supported on Windows")
+		} else {
+			e.login = &loginEnv{}
+			e.takeLoginEnv()
+		}
+	}
+
+	return e
+}
+
+// SetPlugins sets the plugins configured commands are run with.
+func (e *Executor) SetPlugins(h *plugin.Host) {
+	e.plugins = h
+}
+
+// WaitAlerts waits for tripwire alerts being delivered to the webhook.
+func (e *Executor) WaitAlerts() {
+	e.tripwires.Wait()
 }
 
 // Execute runs a command with safety checks and resource limits.
 func (e *Executor) Execute(ctx context.Context, req *types.CommandExecutionRequest) (*types.CommandExecutionResult, error) {
+	return e.execute(ctx, req, false)
+}
+
+// Admit evaluates the policy for a program the server runs outside
+// Execute, such as the program of a tmux session, exactly as Execute
+// would, and starts its run: its approval is used up and it is counted by
+// the conditions. It returns the approval the run goes ahead under.
+func (e *Executor) Admit(ctx context.Context, req *types.CommandExecutionRequest) (string, error) {
+	approvalID, err := e.admit(ctx, req.Command, req, req.ApprovalID, false)
+	if err != nil {
+		return "", err
+	}
+	if err := e.start(ctx, req.Command, req, approvalID); err != nil {
+		return "", err
+	}
+	return approvalID, nil
+}
+
+// AdmitInput refuses input typed into an interactive program admitted
+// with Admit, or a command run in a container, while the operator
+// switches stop executions. Either can run anything the program or
+// container allows, so it counts as mutating.
+func (e *Executor) AdmitInput(program string) error {
+	if err := e.checkSwitches(program, true); err != nil {
+		metrics.Add("denied", 1)
+		return err
+	}
+	return nil
+}
+
+// AdmitRead refuses a program the server runs itself to read, such as git
+// for the git tools, while an operator pauses executions. A repository can
+// make git run programs it configures, so reads stop with commands, but
+// they do not count as mutating.
+func (e *Executor) AdmitRead(program string) error {
+	if err := e.checkSwitches(program, false); err != nil {
+		metrics.Add("denied", 1)
+		return err
+	}
+	return nil
+}
+
+// execute runs a command, evaluating the policy and starting the run
+// unless the caller already admitted and started it.
+func (e *Executor) execute(ctx context.Context, req *types.CommandExecutionRequest, admitted bool) (result *types.CommandExecutionResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, e.recovered(r, req)
+		}
+	}()
+
 	e.logger.WithFields(map[string]any{
 		"command": req.Command,
 		"args":    req.Args,
 		"workdir": req.WorkDir,
 	}).Debug("executing command")
 
-	// Validate request
-	if err := e.validateRequest(req); err != nil {
-		return nil, err
+	var approvalID string
+	if !admitted {
+		if approvalID, err = e.admit(ctx, req.Command, req, req.ApprovalID, false); err != nil {
+			return nil, err
+		}
+		if err := e.start(ctx, req.Command, req, approvalID); err != nil {
+			return nil, err
+		}
 	}
 
-	// Check security constraints
-	if err := e.checkSecurity(req); err != nil {
+	// Wait for an execution slot
+	release, err := e.slots.acquire(ctx, queueFuncFrom(ctx))
+	if err != nil {
 		return nil, err
 	}
+	defer release()
 
-	// Acquire semaphore
-	select {
-	case e.semaphore <- struct{}{}:
-		defer func() { <-e.semaphore }()
-	case <-ctx.Done():
-		return nil, apperrors.TimeoutError("context cancelled while waiting for execution slot", "")
-	}
+	// Track active commands; operators may kill them
+	ctx, kill := context.WithCancelCause(ctx)
+	defer kill(nil)
+	defer e.trackActive(ctx, req, kill)()
 
-	// Track active commands
-	atomic.AddInt32(&e.activeCommands, 1)
-	defer atomic.AddInt32(&e.activeCommands, -1)
+	// Delay or fail the execution when fault injection is enabled
+	if err := e.injectFault(ctx, req); err != nil {
+		return nil, err
+	}
 
 	// Parse timeout
-	timeout := e.getTimeout(req.Timeout)
+	timeout := e.getTimeout(req)
 
 	// Create context with timeout
 	execCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	// Execute the command
-	result := e.executeCommand(execCtx, req)
+	result = e.executeCommand(execCtx, req)
+	if killed(ctx) {
+		result.TimedOut = false
+		result.ErrorMessage = e.msg.T("killed by an operator")
+	}
+	e.explainQuarantine(req, result)
+	if approvalID != "" {
+		e.recordApprovedRun(approvalID, result, nil)
+	}
 
 	// Log execution
 	e.logExecution(req, result)
+	recordMetrics(result)
 
 	return result, nil
 }
 
+// admit evaluates the whole policy for a request, by name, before anything
+// is done for its run: validation, tripwires, switches, security,
+// screening and package holds, approvals and conditions. Held requests,
+// and all with requireApproval, need an approval, which is returned for
+// start to consume.
+func (e *Executor) admit(ctx context.Context, name string, req *types.CommandExecutionRequest, approvalID string, requireApproval bool) (string, error) {
+	// Validate request
+	if err := e.validateRequest(req); err != nil {
+		return "", err
+	}
+
+	// Requests for tripwires are never run, whatever the rest of the policy
+	if err := e.checkTripwires(ctx, req); err != nil {
+		metrics.Add("denied", 1)
+		return "", err
+	}
+
+	// Refuse executions while an operator paused them
+	if err := e.checkSwitches(req.Command, false); err != nil {
+		metrics.Add("denied", 1)
+		return "", err
+	}
+
+	// Check security constraints
+	if err := e.checkSecurity(ctx, req); err != nil {
+		metrics.Add("denied", 1)
+		return "", err
+	}
+
+	// Hold package operations outside the allowlist and requests flagged
+	// by screening until operators approve them
+	flag, err := e.screen(ctx, name, req)
+	if err != nil {
+		metrics.Add("denied", 1)
+		return "", err
+	}
+	if reason := e.holdReason(req, flag); requireApproval || reason != "" {
+		if err := e.checkApproval(ctx, name, req, approvalID, reason); err != nil {
+			metrics.Add("denied", 1)
+			return "", withScreening(err, flag)
+		}
+	} else {
+		approvalID = ""
+	}
+
+	// Check time window and run count conditions; runs are counted once
+	// they start
+	if _, denial := e.conditions.Check(req.Command, req.Args); denial != nil {
+		metrics.Add("denied", 1)
+		return "", apperrors.PermissionError(e.conditionDenial(denial), req.Command)
+	}
+	return approvalID, nil
+}
+
+// start commits an admitted run about to start: it is counted by the
+// conditions and its approval is used up.
+func (e *Executor) start(ctx context.Context, name string, req *types.CommandExecutionRequest, approvalID string) error {
+	if denial := e.conditions.Admit(req.Command, req.Args); denial != nil {
+		metrics.Add("denied", 1)
+		return apperrors.PermissionError(e.conditionDenial(denial), req.Command)
+	}
+	if approvalID != "" {
+		if err := e.consumeApproval(ctx, name, req, approvalID); err != nil {
+			metrics.Add("denied", 1)
+			return err
+		}
+	}
+	return nil
+}
+
 // ExecuteConfigCommand executes a pre-configured command.
 func (e *Executor) ExecuteConfigCommand(ctx context.Context, cmd *config.Command, workDir string) (*types.CommandExecutionResult, error) {
+	return e.ExecuteConfigCommandWithOptions(ctx, cmd, workDir, ConfigCommandOptions{})
+}
+
+// ExecuteConfigCommandWithOptions executes a pre-configured command with
+// the given options.
+func (e *Executor) ExecuteConfigCommandWithOptions(ctx context.Context, cmd *config.Command, workDir string, opts ConfigCommandOptions) (*types.CommandExecutionResult, error) {
+	if opts.Force && !e.config.Security.AllowForceUnlock {
+		return nil, apperrors.PermissionError(e.msg.T("force requires security.allow_force_unlock"), cmd.Name)
+	}
+
+	if err := e.checkUser(ctx, cmd); err != nil {
+		return nil, err
+	}
+
+	if err := e.checkSwitches(cmd.Name, cmd.Mutating || cmd.Risky); err != nil {
+		metrics.Add("denied", 1)
+		return nil, err
+	}
+
+	workDir, err := e.configWorkDir(cmd, workDir)
+	if err != nil {
+		return nil, err
+	}
+
 	req := &types.CommandExecutionRequest{
 		Command: cmd.Command,
 		Args:    cmd.Args,
 		WorkDir: workDir,
-		Timeout: cmd.Timeout,
+		Timeout: cmd.Timeout.String(),
+
+		MaxTimeout:    cmd.MaxTimeout.Std(),
+		MaxOutputSize: int64(cmd.MaxOutputSize),
+		Priority:      cmd.Priority,
+		Isolation:     cmd.EffectiveIsolation(),
+		Category:      cmd.Category,
+
+		SummaryErrorPatterns: cmd.SummaryErrorPatterns,
+		SummaryWarnPatterns:  cmd.SummaryWarnPatterns,
 	}
 
 	// Add environment variables
@@ -106,12 +341,121 @@ func (e *Executor) ExecuteConfigCommand(ctx context.Context, cmd *config.Command
 		req.Env = env
 	}
 
-	// Override workdir if specified in command config
-	if cmd.WorkDir != "" {
-		req.WorkDir = cmd.WorkDir
+	// Ask the command's policy plugins
+	if name, reason := e.plugins.Policy(ctx, cmd, req); name != "" {
+		metrics.Add("denied", 1)
+		return nil, apperrors.PermissionError(e.msg.Sprintf("denied by plugin %s: %s", name, reason), cmd.Name)
+	}
+
+	// Evaluate the policy before waiting for, locking or changing anything.
+	// Skipping the workdir lock needs operators to approve the forced run
+	forced := cmd.Mutating && opts.Force
+	approvalName := cmd.Name
+	if forced {
+		approvalName = forcedRunName(cmd.Name)
+	}
+	approvalID, err := e.admit(ctx, approvalName, req, opts.ApprovalID, cmd.RequiresSecondApproval || forced)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for other commands in the same concurrency group
+	if cmd.ConcurrencyGroup != "" {
+		release, err := e.acquireGroup(ctx, cmd.ConcurrencyGroup)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	// Serialize mutating commands sharing a working directory
+	var lockWait time.Duration
+	if cmd.Mutating && !forced {
+		release, wait, err := e.lockWorkDir(ctx, req.WorkDir)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		lockWait = wait
+	}
+
+	// Check disk space and the binary once the command is about to start
+	if err := e.preflight(req); err != nil {
+		return nil, err
+	}
+
+	// The run goes ahead
+	if err := e.start(ctx, approvalName, req, approvalID); err != nil {
+		return nil, err
+	}
+	if forced {
+		sc := security.FromContext(ctx)
+		e.logger.Warn("running mutating command without workdir lock",
+			"command", cmd.Name,
+			"approval_id", approvalID,
+			"user", sc.User(),
+			"client", sc.Client(),
+		)
+	}
+
+	// Record the repository of risky commands so changes can be recovered
+	var snapshotRef string
+	if e.wantsSnapshot(cmd) {
+		dir, err := resolveWorkDir(req.WorkDir)
+		if err == nil {
+			snapshotRef, err = e.gitSnapshot(ctx, dir, cmd.Name)
+		}
+		if err != nil {
+			if approvalID != "" {
+				e.recordApprovedRun(approvalID, nil, err)
+			}
+			return nil, err
+		}
+	}
+
+	// Snapshot the working directory to report what the command touched
+	var trackDir string
+	var before *snapshot
+	if cmd.TrackChanges {
+		dir, err := resolveWorkDir(req.WorkDir)
+		if err == nil {
+			trackDir = dir
+			before, err = takeSnapshot(dir, e.config.Execution.MaxTrackedFiles)
+		}
+		if err != nil {
+			e.logger.WithError(err).Warn("change tracking disabled for run", "command", cmd.Name)
+		}
 	}
 
-	return e.Execute(ctx, req)
+	result, err := e.execute(ctx, req, true)
+	if approvalID != "" {
+		e.recordApprovedRun(approvalID, result, err)
+	}
+	if result != nil {
+		result.LockWait = lockWait
+		result.SnapshotRef = snapshotRef
+		if cmd.WorkDirMode != "" {
+			result.WorkDir = req.WorkDir
+		}
+
+		// Pass the output through the command's output plugins
+		if perr := e.plugins.Output(ctx, cmd, req, result); perr != nil {
+			e.logger.WithError(perr).Warn("output plugin failed, output withheld", "command", cmd.Name)
+			if err == nil {
+				err = perr
+			}
+		}
+
+		if before != nil {
+			after, snapErr := takeSnapshot(trackDir, e.config.Execution.MaxTrackedFiles)
+			if snapErr != nil {
+				e.logger.WithError(snapErr).Warn("failed to snapshot workdir after run", "command", cmd.Name)
+			} else {
+				result.Changes = diffSnapshots(before, after, e.config.Execution.MaxReportedChanges)
+			}
+		}
+	}
+	return result, err
 }
 
 // GetActiveCount returns the number of active command executions.
@@ -119,6 +463,18 @@ func (e *Executor) GetActiveCount() int {
 	return int(atomic.LoadInt32(&e.activeCommands))
 }
 
+// Approvals returns the store holding runs that require a second
+// approval.
+func (e *Executor) Approvals() *approval.Store {
+	return e.approvals
+}
+
+// QueuedCount returns the number of commands waiting for an execution
+// slot.
+func (e *Executor) QueuedCount() int {
+	return e.slots.length()
+}
+
 // validateRequest validates the execution request.
 func (e *Executor) validateRequest(req *types.CommandExecutionRequest) error {
 	if req.Command == "" {
@@ -136,19 +492,16 @@ func (e *Executor) validateRequest(req *types.CommandExecutionRequest) error {
 		}
 	}
 
-	// Validate workdir if specified
-	if req.WorkDir != "" {
-		if !filepath.IsAbs(req.WorkDir) {
-			return apperrors.ValidationError("workdir must be an absolute path", "workdir")
-		}
-
-		info, err := os.Stat(req.WorkDir)
-		if err != nil {
-			return apperrors.NotFoundError(fmt.Sprintf("workdir not found: %v", err), req.WorkDir)
+	if req.Timeout != "" {
+		if d, err := time.ParseDuration(req.Timeout); err != nil || d <= 0 {
+			return apperrors.ValidationError("timeout must be a positive duration such as 30s or 5m", "timeout")
 		}
+	}
 
-		if !info.IsDir() {
-			return apperrors.ValidationError("workdir is not a directory", "workdir")
+	// Validate workdir if specified
+	if req.WorkDir != "" {
+		if _, err := e.checkWorkDir(req.WorkDir); err != nil {
+			return err
 		}
 	}
 
@@ -156,76 +509,152 @@ func (e *Executor) validateRequest(req *types.CommandExecutionRequest) error {
 }
 
 // checkSecurity performs security checks on the command.
-func (e *Executor) checkSecurity(req *types.CommandExecutionRequest) error {
+func (e *Executor) checkSecurity(ctx context.Context, req *types.CommandExecutionRequest) error {
 	// Check if command is allowed
-	if !e.config.IsCommandAllowed(req.Command) {
+	if !e.config.IsCommandAllowed(req.Command, req.WorkDir) {
 		return apperrors.PermissionError(
-			fmt.Sprintf("command not allowed: %s", req.Command),
+			e.msg.Sprintf("command not allowed: %s", req.Command)+e.alternative(req.Command)+e.recordDenial(ctx, policy.ReasonCommand, req),
 			req.Command,
 		)
 	}
 
-	// Check if path is allowed
-	if req.WorkDir != "" && !e.config.IsPathAllowed(req.WorkDir) {
-		return apperrors.PermissionError(
-			fmt.Sprintf("path not allowed: %s", req.WorkDir),
-			req.WorkDir,
-		)
+	if req.WorkDir != "" {
+		workDir, err := e.checkWorkDir(req.WorkDir)
+		if err != nil {
+			return err
+		}
+
+		// Denied paths are deliberate, so they are not recorded for review
+		if e.config.MatchDeniedResolved(workDir) != "" {
+			return apperrors.PermissionError(e.msg.Sprintf("path denied: %s", req.WorkDir), req.WorkDir)
+		}
+
+		// Check if path is allowed
+		if len(e.config.Security.AllowedPaths) > 0 && e.config.MatchAllowedResolved(workDir) == "" {
+			return apperrors.PermissionError(
+				e.msg.Sprintf("path not allowed: %s", req.WorkDir)+e.recordDenial(ctx, policy.ReasonPath, req),
+				req.WorkDir,
+			)
+		}
+	}
+
+	// Check the paths arguments name
+	if e.config.Security.DenyPathArgs {
+		if arg, _ := e.deniedPathArg(req); arg != "" {
+			return apperrors.PermissionError(e.msg.Sprintf("path denied: %s", arg), arg)
+		}
+		if arg := e.outsidePathArg(req); arg != "" {
+			return apperrors.PermissionError(
+				e.msg.Sprintf("path not allowed: %s", arg)+e.recordDenial(ctx, policy.ReasonPath, req),
+				arg,
+			)
+		}
 	}
 
 	// Check for shell injection attempts if shell expansion is disabled
 	if e.config.Security.DisableShellExpansion {
-		dangerous := []string{";", "&&", "||", "|", "`", "$", "(", ")", "{", "}", "<", ">", "&"}
-		cmdStr := req.Command + " " + strings.Join(req.Args, " ")
-
-		for _, char := range dangerous {
-			if strings.Contains(cmdStr, char) {
-				return apperrors.PermissionError(
-					fmt.Sprintf("potentially dangerous character detected: %s", char),
-					"command",
-				)
-			}
+		if char := findShellMetacharacter(req); char != "" {
+			return apperrors.PermissionError(
+				e.msg.Sprintf("potentially dangerous character detected: %s", char),
+				"command",
+			)
 		}
 	}
 
+	// Check the operations of cloud command line tools
+	if _, denial := e.cliPolicies.Check(req.Command, req.Args); denial != nil {
+		return apperrors.PermissionError(e.msg.Sprintf("denied by cli policy %s: %s", denial.CLI, denial.Reason), req.Command)
+	}
+
 	return nil
 }
 
-// getTimeout determines the timeout for command execution.
-func (e *Executor) getTimeout(requested string) time.Duration {
-	// Parse requested timeout
-	if requested != "" {
-		if dur, err := time.ParseDuration(requested); err == nil {
-			// Check against max timeout
-			maxTimeout := e.parseTimeoutConfig(e.config.Execution.MaxTimeout, 5*time.Minute)
-			if dur > maxTimeout {
-				return maxTimeout
-			}
-			return dur
+// checkUser enforces a configured command's requires_auth and
+// allowed_users against the principal behind the request.
+func (e *Executor) checkUser(ctx context.Context, cmd *config.Command) error {
+	if !cmd.RequiresAuth && len(cmd.AllowedUsers) == 0 {
+		return nil
+	}
+
+	user := security.FromContext(ctx).User()
+	if user == "" {
+		return apperrors.PermissionError(e.msg.T("command requires an authenticated user"), cmd.Name)
+	}
+
+	if len(cmd.AllowedUsers) == 0 {
+		return nil
+	}
+	for _, allowed := range cmd.AllowedUsers {
+		if allowed == user {
+			return nil
 		}
 	}
+	return apperrors.PermissionError(e.msg.Sprintf("user %s may not run this command", user), cmd.Name)
+}
 
-	// Use default timeout
-	return e.parseTimeoutConfig(e.config.Execution.DefaultTimeout, 30*time.Second)
+// conditionDenial describes a denial by a policy condition and when the
+// command is next allowed.
+func (e *Executor) conditionDenial(d *policy.ConditionDenial) string {
+	msg := e.msg.Sprintf("denied by condition %s: %s", d.Condition, d.Reason)
+	if !d.NextAllowed.IsZero() {
+		msg += e.msg.Sprintf("; next allowed at %s", d.NextAllowed.Format("2006-01-02 15:04 MST"))
+	}
+	return msg
 }
 
-// parseTimeoutConfig parses a timeout configuration value.
-func (e *Executor) parseTimeoutConfig(value string, defaultValue time.Duration) time.Duration {
-	if value == "" {
-		return defaultValue
+// shellMetacharacters are rejected when shell expansion is disabled.
+var shellMetacharacters = []string{";", "&&", "||", "|", "`", "$", "(", ")", "{", "}", "<", ">", "&"}
+
+// findShellMetacharacter returns the first shell metacharacter in a
+// request's command line, or "" if there is none.
+func findShellMetacharacter(req *types.CommandExecutionRequest) string {
+	cmdStr := req.Command + " " + strings.Join(req.Args, " ")
+	for _, char := range shellMetacharacters {
+		if strings.Contains(cmdStr, char) {
+			return char
+		}
 	}
+	return ""
+}
 
-	dur, err := time.ParseDuration(value)
+// recordDenial records a denied request in learn mode and returns a note
+// for the error message.
+func (e *Executor) recordDenial(ctx context.Context, reason string, req *types.CommandExecutionRequest) string {
+	if e.learner == nil {
+		return ""
+	}
+
+	sc := security.FromContext(ctx)
+	err := e.learner.Record(policy.Denial{
+		Reason:  reason,
+		Command: req.Command,
+		Args:    req.Args,
+		WorkDir: req.WorkDir,
+		Client:  sc.Client(),
+		User:    sc.User(),
+	})
 	if err != nil {
-		e.logger.WithFields(map[string]any{
-			"value":         value,
-			"error":         err,
-			"using_default": defaultValue,
-		}).Warn("invalid timeout configuration")
-		return defaultValue
+		e.logger.WithError(err).Warn("failed to record denied command")
+		return ""
 	}
+	return e.msg.T(" (recorded for policy review)")
+}
 
-	return dur
+// getTimeout determines the timeout for command execution. The requested
+// timeout has been checked by validateRequest.
+func (e *Executor) getTimeout(req *types.CommandExecutionRequest) time.Duration {
+	maxTimeout := e.config.Execution.MaxTimeout.Std()
+	if req.MaxTimeout > 0 {
+		maxTimeout = min(maxTimeout, req.MaxTimeout)
+	}
+
+	// Use the requested timeout
+	if dur, err := time.ParseDuration(req.Timeout); err == nil && dur > 0 {
+		return min(dur, maxTimeout)
+	}
+
+	// Use default timeout
+	return min(e.config.Execution.DefaultTimeout.Std(), maxTimeout)
 }
 
 // executeCommand performs the actual command execution.
@@ -236,35 +665,69 @@ func (e *Executor) executeCommand(ctx context.Context, req *types.CommandExecuti
 		ExitCode:  -1,
 	}
 
-	// Create command
+	// Create command, in the project environment or with the toolchains
+	// pinned for the workdir when configured
+	name, args := req.Command, req.Args
+	env, cleanup, err := e.isolatedEnv(req)
+	if err != nil {
+		result.EndTime = time.Now()
+		result.Duration = result.EndTime.Sub(startTime)
+		result.ErrorMessage = fmt.Sprintf("failed to isolate command: %v", err)
+		return result
+	}
+	defer cleanup()
+	if devEnv := e.devEnvironment(req); devEnv != nil {
+		name, args = devEnvCommand(devEnv, req)
+		result.DevEnvironment = devEnv
+	} else if pins := e.config.PinnedToolchains(requestDir(req)); len(pins) > 0 {
+		name, env, result.Toolchains = pinToolchains(pins, req.Command, env)
+	}
 	// #nosec G204 - This tool's purpose is to execute user-provided commands
-	cmd := exec.CommandContext(ctx, req.Command, req.Args...)
+	cmd := exec.CommandContext(ctx, name, args...)
 
 	// Set working directory
 	if req.WorkDir != "" {
 		cmd.Dir = req.WorkDir
 	}
 
-	// Set environment
-	if len(req.Env) > 0 {
-		cmd.Env = append(os.Environ(), req.Env...)
+	// Set environment and isolation
+	cmd.Env = env
+	if err := isolate(cmd, req.Isolation); err != nil {
+		result.EndTime = time.Now()
+		result.Duration = result.EndTime.Sub(startTime)
+		result.ErrorMessage = fmt.Sprintf("failed to isolate command: %v", err)
+		return result
 	}
 
 	// Create buffers for output with size limits
-	stdout := &limitedBuffer{limit: e.config.Execution.MaxOutputSize}
-	stderr := &limitedBuffer{limit: e.config.Execution.MaxOutputSize}
+	stdout, stdoutSpill := e.newOutput("stdout", e.outputLimit(req))
+	stderr, stderrSpill := e.newOutput("stderr", e.outputLimit(req))
+	defer stdout.release()
+	defer stderr.release()
 
-	cmd.Stdout = stdout
-	cmd.Stderr = stderr
+	cmd.Stdout = outputWriter(stdout, stdoutSpill)
+	cmd.Stderr = outputWriter(stderr, stderrSpill)
+
+	// Digest the whole output, to summarize what may not fit in the
+	// result. Digests come first as they take every write
+	stdoutDigest, stderrDigest := e.newDigests(req)
+	if stdoutDigest != nil {
+		cmd.Stdout = io.MultiWriter(stdoutDigest, cmd.Stdout)
+		cmd.Stderr = io.MultiWriter(stderrDigest, cmd.Stderr)
+	}
 
 	// Start the command
-	err := cmd.Start()
+	err = cmd.Start()
 	if err != nil {
 		result.EndTime = time.Now()
 		result.Duration = result.EndTime.Sub(startTime)
 		result.ErrorMessage = fmt.Sprintf("failed to start command: %v", err)
 		return result
 	}
+	result.Priority = e.applyPriority(cmd.Process, req.Priority)
+	if req.Isolation != "" && req.Isolation != config.IsolationNone {
+		result.Isolation = req.Isolation
+	}
 
 	// Wait for completion
 	done := make(chan error, 1)
@@ -278,8 +741,11 @@ func (e *Executor) executeCommand(ctx context.Context, req *types.CommandExecuti
 		// Command completed
 		result.EndTime = time.Now()
 		result.Duration = result.EndTime.Sub(startTime)
-		result.Stdout = stdout.String()
-		result.Stderr = stderr.String()
+		result.Stdout = stdout.take()
+		result.Stderr = stderr.take()
+		result.StdoutFile = stdoutSpill.finish()
+		result.StderrFile = stderrSpill.finish()
+		result.Summary = e.summarize(stdoutDigest, stderrDigest)
 
 		if err != nil {
 			exitErr := &exec.ExitError{}
@@ -306,8 +772,7 @@ func (e *Executor) executeCommand(ctx context.Context, req *types.CommandExecuti
 			}
 
 			// Wait for kill timeout
-			killTimeout := e.parseTimeoutConfig(e.config.Execution.KillTimeout, 5*time.Second)
-			killTimer := time.NewTimer(killTimeout)
+			killTimer := time.NewTimer(e.config.Execution.KillTimeout.Std())
 
 			select {
 			case <-done:
@@ -322,8 +787,11 @@ func (e *Executor) executeCommand(ctx context.Context, req *types.CommandExecuti
 			}
 		}
 
-		result.Stdout = stdout.String()
-		result.Stderr = stderr.String()
+		result.Stdout = stdout.take()
+		result.Stderr = stderr.take()
+		result.StdoutFile = stdoutSpill.finish()
+		result.StderrFile = stderrSpill.finish()
+		result.Summary = e.summarize(stdoutDigest, stderrDigest)
 		result.ErrorMessage = "command timed out"
 	}
 
@@ -369,9 +837,22 @@ func (e *Executor) logExecution(req *types.CommandExecutionRequest, result *type
 	}
 }
 
-// limitedBuffer is a buffer that limits the amount of data stored.
+// maxPooledBuffer is the largest output buffer kept for reuse, so a few
+// huge outputs do not pin memory while the server is idle.
+const maxPooledBuffer = 4 << 20
+
+// outputBuffers pools output buffers across executions, so the copy of a
+// pooled buffer into the result is the only sizeable allocation once
+// buffers are warm.
+var outputBuffers = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// limitedBuffer is a buffer that limits the amount of data stored. Its
+// storage is taken from outputBuffers on the first write and returned by
+// release.
 type limitedBuffer struct {
-	buf   bytes.Buffer
+	buf   *bytes.Buffer
 	limit int64
 	size  int64
 	mu    sync.Mutex
@@ -381,6 +862,10 @@ func (b *limitedBuffer) Write(p []byte) (n int, err error) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	if b.buf == nil {
+		b.buf = outputBuffers.Get().(*bytes.Buffer)
+	}
+
 	if b.limit <= 0 {
 		return b.buf.Write(p)
 	}
@@ -402,9 +887,54 @@ func (b *limitedBuffer) Write(p []byte) (n int, err error) {
 func (b *limitedBuffer) String() string {
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	if b.buf == nil {
+		return ""
+	}
 	return b.buf.String()
 }
 
+// take returns the buffered output for the result. Storage too large to
+// be pooled is handed off to the string without a copy, as release would
+// drop it anyway; smaller output is copied so its storage can be reused.
+func (b *limitedBuffer) take() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.buf == nil {
+		return ""
+	}
+	if b.buf.Cap() <= maxPooledBuffer {
+		return b.buf.String()
+	}
+	data := b.buf.Bytes()
+	b.buf = nil
+	return unsafe.String(unsafe.SliceData(data), len(data))
+}
+
+// writeTo writes the buffered output to w.
+func (b *limitedBuffer) writeTo(w io.Writer) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.buf == nil {
+		return nil
+	}
+	_, err := w.Write(b.buf.Bytes())
+	return err
+}
+
+// release returns the storage to the pool once the output has been read.
+func (b *limitedBuffer) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.buf == nil {
+		return
+	}
+	if b.buf.Cap() <= maxPooledBuffer {
+		b.buf.Reset()
+		outputBuffers.Put(b.buf)
+	}
+	b.buf = nil
+}
+
 // truncateString truncates a string to the specified length.
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
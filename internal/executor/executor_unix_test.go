@@ -3,8 +3,16 @@
 package executor
 
 import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
 	"github.com/mjmorales/simple-mcp-runner/pkg/types"
 )
 
@@ -26,4 +34,311 @@ func getTimeoutTestCase() testCase {
 			}
 		},
 	}
-}
\ No newline at end of file
+}
+func TestExecutor_checkSecurityNormalizesCommands(t *testing.T) {
+	rm, err := exec.LookPath("rm")
+	if err != nil {
+		t.Skip("rm not installed")
+	}
+	echo, err := exec.LookPath("echo")
+	if err != nil {
+		t.Skip("echo not installed")
+	}
+
+	dir := t.TempDir()
+	alias := filepath.Join(dir, "cleanup")
+	if err := os.Symlink(rm, alias); err != nil {
+		t.Fatal(err)
+	}
+	fake := filepath.Join(dir, "echo")
+	if err := os.WriteFile(fake, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Security.AllowedCommands = []string{"echo", "cleanup"}
+	e := New(cfg, logger.Default())
+
+	tests := []struct {
+		command string
+		allowed bool
+	}{
+		{"rm", false},
+		{rm, false},    // full path of a blocked command
+		{alias, false}, // symlink to a blocked command
+		{"echo", true},
+		{echo, true},  // full path of an allowed command
+		{fake, false}, // another binary sharing an allowed name
+	}
+
+	for _, tt := range tests {
+		err := e.checkSecurity(context.Background(), &types.CommandExecutionRequest{Command: tt.command})
+		if (err == nil) != tt.allowed {
+			t.Errorf("checkSecurity(%q) error = %v, want allowed %v", tt.command, err, tt.allowed)
+		}
+	}
+}
+
+func TestExecutor_checkSecurityRelativeCommands(t *testing.T) {
+	rm, err := exec.LookPath("rm")
+	if err != nil {
+		t.Skip("rm not installed")
+	}
+	dir := t.TempDir()
+	if err := os.Symlink(rm, filepath.Join(dir, "del")); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New(config.Default(), logger.Default())
+
+	// A relative command is resolved from the workdir it runs in
+	req := &types.CommandExecutionRequest{Command: "./del", WorkDir: dir}
+	if err := e.checkSecurity(context.Background(), req); err == nil {
+		t.Error("expected ./del symlinked to rm in the workdir to be blocked")
+	}
+}
+
+func TestExecutor_checkSecuritySymlinkedPaths(t *testing.T) {
+	root := t.TempDir()
+	allowed := filepath.Join(root, "work")
+	outside := filepath.Join(root, "outside")
+	for _, dir := range []string{allowed, outside} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := filepath.Join(allowed, "escape")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Security.AllowedPaths = []string{allowed}
+	e := New(cfg, logger.Default())
+
+	// A symlink inside an allowed path cannot reach outside it
+	req := &types.CommandExecutionRequest{Command: "echo", WorkDir: link}
+	if err := e.checkSecurity(context.Background(), req); err == nil {
+		t.Error("expected symlink escaping the allowed path to be rejected")
+	}
+
+	// Nor can a symlink reach a denied path
+	cfg.Security.AllowedPaths = nil
+	cfg.Security.DeniedPaths = []string{outside}
+	if err := e.checkSecurity(context.Background(), req); err == nil {
+		t.Error("expected symlink into a denied path to be rejected")
+	}
+
+	// Without resolution paths are checked as given
+	cfg.Security.ResolveSymlinks = false
+	cfg.Security.AllowedPaths = []string{allowed}
+	cfg.Security.DeniedPaths = nil
+	if err := e.checkSecurity(context.Background(), req); err != nil {
+		t.Errorf("expected unresolved symlink to be allowed, got %v", err)
+	}
+}
+
+func TestExecutor_Active(t *testing.T) {
+	log, _ := logger.New(logger.DefaultOptions())
+	exec := New(config.Default(), log)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		exec.Execute(context.Background(), &types.CommandExecutionRequest{Command: "sleep", Args: []string{"0.3"}})
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	var active []ActiveCommand
+	for time.Now().Before(deadline) {
+		if active = exec.Active(); len(active) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(active) != 1 || active[0].Command != "sleep" || active[0].Started.IsZero() {
+		t.Fatalf("expected the running sleep, got %+v", active)
+	}
+
+	<-done
+	if active := exec.Active(); len(active) != 0 {
+		t.Errorf("expected no active commands after the run, got %+v", active)
+	}
+	if exec.GetActiveCount() != 0 {
+		t.Errorf("expected an active count of 0, got %d", exec.GetActiveCount())
+	}
+}
+
+func TestExecutor_priority(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "stat.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nsleep 0.2\ncat /proc/$$/stat 2>/dev/null\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	e := New(config.Default(), logger.Default())
+	cmd := &config.Command{Name: "niced", Command: script, Priority: config.PriorityLow}
+
+	result, err := e.ExecuteConfigCommand(context.Background(), cmd, "")
+	if err != nil {
+		t.Fatalf("ExecuteConfigCommand() error = %v", err)
+	}
+	if result.Priority != config.PriorityLow {
+		t.Errorf("priority = %q, want low", result.Priority)
+	}
+	// The nice value is the 19th field of /proc/<pid>/stat, after the
+	// parenthesized command name
+	if i := strings.LastIndex(result.Stdout, ") "); i >= 0 {
+		fields := strings.Fields(result.Stdout[i+2:])
+		if len(fields) > 16 && fields[16] != "10" {
+			t.Errorf("nice = %s, want 10", fields[16])
+		}
+	}
+
+	cmd.Priority = ""
+	if result, err := e.ExecuteConfigCommand(context.Background(), cmd, ""); err != nil || result.Priority != "" {
+		t.Errorf("priority = %q (%v), want none when not configured", result.Priority, err)
+	}
+}
+
+func TestExecutor_loginShellEnv(t *testing.T) {
+	home := t.TempDir()
+	profile := "echo welcome\nexport SMR_PROFILE_VAR=from-profile\n"
+	if err := os.WriteFile(filepath.Join(home, ".profile"), []byte(profile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/sh")
+	t.Setenv("SMR_SERVER_VAR", "from-server")
+
+	cfg := config.Default()
+	cfg.Execution.LoginShellEnv = true
+	e := New(cfg, logger.Default())
+	if e.loginShellEnv() == nil {
+		t.Skip("login shell did not report its environment")
+	}
+
+	result, err := e.Execute(context.Background(), &types.CommandExecutionRequest{Command: "env"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"SMR_PROFILE_VAR=from-profile", "SMR_SERVER_VAR=from-server"} {
+		if !strings.Contains(result.Stdout, want+"\n") {
+			t.Errorf("expected %s in the command environment", want)
+		}
+	}
+	if strings.Contains(result.Stdout, "welcome") || strings.Contains(result.Stdout, loginShellMarker) {
+		t.Errorf("profile output leaked into the environment: %q", result.Stdout)
+	}
+}
+
+func TestExecutor_ExecuteConfigCommandWorkDirMode(t *testing.T) {
+	root := projectTree(t)
+	e := New(config.Default(), logger.Default())
+	cmd := &config.Command{Name: "where", Command: "pwd", WorkDirMode: config.WorkDirModeGitRoot}
+
+	result, err := e.ExecuteConfigCommand(context.Background(), cmd, filepath.Join(root, "svc", "pkg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(result.Stdout) != root || result.WorkDir != root {
+		t.Errorf("expected run in %s, got stdout %q and workdir %q", root, result.Stdout, result.WorkDir)
+	}
+}
+
+func TestExecutor_Kill(t *testing.T) {
+	e := New(config.Default(), logger.Default())
+
+	done := make(chan *types.CommandExecutionResult, 1)
+	go func() {
+		result, err := e.Execute(context.Background(), &types.CommandExecutionRequest{Command: "sleep", Args: []string{"30"}})
+		if err != nil {
+			t.Error(err)
+		}
+		done <- result
+	}()
+
+	var active []ActiveCommand
+	for deadline := time.Now().Add(5 * time.Second); len(active) == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		active = e.Active()
+	}
+	if len(active) != 1 {
+		t.Fatalf("active commands = %v", active)
+	}
+	if err := e.Kill(active[0].ID); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+
+	select {
+	case result := <-done:
+		if result == nil || result.TimedOut || result.ErrorMessage != "killed by an operator" {
+			t.Errorf("result = %+v, want killed by an operator", result)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("killed command still running")
+	}
+	if err := e.Kill(active[0].ID); err == nil {
+		t.Error("expected killing a finished command to fail")
+	}
+}
+
+func TestExecutor_executeCommandDevEnvironment(t *testing.T) {
+	// A fake nix shows the command line it was given
+	bin := t.TempDir()
+	fake := filepath.Join(bin, "nix")
+	if err := os.WriteFile(fake, []byte("#!/bin/sh\necho \"$@\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, "flake.nix"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Execution.DevEnvironment = config.DevEnvNix
+	e := New(cfg, logger.Default())
+
+	result := e.executeCommand(context.Background(), &types.CommandExecutionRequest{
+		Command: "make",
+		Args:    []string{"test"},
+		WorkDir: project,
+	})
+	if want := "develop " + project + " -c make test"; strings.TrimSpace(result.Stdout) != want {
+		t.Errorf("stdout = %q, want %q", result.Stdout, want)
+	}
+	if result.DevEnvironment == nil || result.DevEnvironment.Root != project {
+		t.Errorf("DevEnvironment = %+v, want nix at %s", result.DevEnvironment, project)
+	}
+}
+
+func TestExecutor_executeCommandToolchains(t *testing.T) {
+	// The pinned go shadows any installed one
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "go"), []byte("#!/bin/sh\necho go1.21 \"$@\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+
+	cfg := config.Default()
+	cfg.Execution.Toolchains = []config.Toolchain{{Path: project, Name: "go1.21", Bin: bin}}
+	e := New(cfg, logger.Default())
+
+	result := e.executeCommand(context.Background(), &types.CommandExecutionRequest{
+		Command: "go",
+		Args:    []string{"version"},
+		WorkDir: project,
+	})
+	if strings.TrimSpace(result.Stdout) != "go1.21 version" {
+		t.Errorf("stdout = %q, want the pinned go", result.Stdout)
+	}
+	if len(result.Toolchains) != 1 || result.Toolchains[0].Name != "go1.21" {
+		t.Errorf("Toolchains = %+v, want go1.21", result.Toolchains)
+	}
+
+	// Commands elsewhere are not pinned
+	result = e.executeCommand(context.Background(), &types.CommandExecutionRequest{Command: "true", WorkDir: t.TempDir()})
+	if len(result.Toolchains) != 0 {
+		t.Errorf("Toolchains = %+v outside the pinned path", result.Toolchains)
+	}
+}
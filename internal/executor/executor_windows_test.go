@@ -3,8 +3,14 @@
 package executor
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
 	"github.com/mjmorales/simple-mcp-runner/pkg/types"
 )
 
@@ -28,3 +34,104 @@ func getTimeoutTestCase() testCase {
 		},
 	}
 }
+
+func TestExecutor_checkSecurityNormalizesCommands(t *testing.T) {
+	e := New(config.Default(), logger.Default())
+
+	for _, command := range []string{"RM", "rm.exe", `C:\tools\Rm.Exe`, "./rm"} {
+		if err := e.checkSecurity(context.Background(), &types.CommandExecutionRequest{Command: command}); err == nil {
+			t.Errorf("expected %q to be blocked", command)
+		}
+	}
+}
+
+func TestExecutor_checkSecurityWindowsPaths(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "Secret")
+	if err := os.Mkdir(secret, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	public := filepath.Join(dir, "Public")
+	if err := os.Mkdir(public, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Security.AllowedPaths = []string{strings.ReplaceAll(dir, `\`, "/")}
+	cfg.Security.DeniedPaths = []string{secret}
+	e := New(cfg, logger.Default())
+
+	denied := []string{
+		secret,
+		strings.ToUpper(secret),
+		strings.ReplaceAll(secret, `\`, "/"),
+		`\\?\` + secret,
+		secret + ".",
+		secret + ". .",
+		secret + "::$DATA",
+		filepath.Join(secret, "..", "Secret"),
+		filepath.VolumeName(dir) + `\Windows`,
+	}
+	for _, workDir := range denied {
+		if err := e.checkSecurity(context.Background(), &types.CommandExecutionRequest{Command: "cmd", WorkDir: workDir}); err == nil {
+			t.Errorf("expected workdir %q to be denied", workDir)
+		}
+	}
+
+	allowed := []string{
+		public,
+		strings.ToLower(public),
+		strings.ReplaceAll(public, `\`, "/"),
+		`\\?\` + public,
+		secret + "2",
+	}
+	if err := os.Mkdir(secret+"2", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, workDir := range allowed {
+		if err := e.checkSecurity(context.Background(), &types.CommandExecutionRequest{Command: "cmd", WorkDir: workDir}); err != nil {
+			t.Errorf("expected workdir %q to be allowed, got %v", workDir, err)
+		}
+	}
+}
+
+func TestConfig_windowsPathRules(t *testing.T) {
+	cfg := config.Default()
+	cfg.Security.ResolveSymlinks = false
+	cfg.Security.AllowedPaths = []string{`\\server\share\builds`, `D:/data`}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{`\\server\share\builds\app`, true},
+		{`\\SERVER\Share\Builds\app`, true},
+		{`\\?\UNC\server\share\builds\app`, true},
+		{`//server/share/builds/app`, true},
+		{`\\server\share\other`, false},
+		{`\\other\share\builds`, false},
+		{`d:\data\x`, true},
+		{`D:\Data`, true},
+		{`C:\data\x`, false},
+		{`D:\database`, false},
+	}
+	for _, tt := range tests {
+		if got := cfg.IsPathAllowed(tt.path); got != tt.want {
+			t.Errorf("IsPathAllowed(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestConfig_windowsBlockedDirectory(t *testing.T) {
+	cfg := config.Default()
+	cfg.Security.BlockedCommands = []string{`C:\Tools`}
+
+	for _, command := range []string{`C:\Tools\deploy.exe`, `c:/tools/deploy.exe`, `C:/TOOLS`} {
+		if cfg.IsCommandAllowed(command, "") {
+			t.Errorf("expected %q to be blocked", command)
+		}
+	}
+	if !cfg.IsCommandAllowed(`C:\Toolsbox\deploy.exe`, "") {
+		t.Error("expected a sibling directory not to be blocked")
+	}
+}
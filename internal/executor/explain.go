@@ -0,0 +1,197 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/approval"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// ExplainPolicy evaluates the security rules for a command without running
+// it. Rules are listed in the order Execute checks them; all rules are
+// evaluated, and the first that denies decides.
+func (e *Executor) ExplainPolicy(req *types.CommandExecutionRequest) *types.PolicyExplanation {
+	sec := e.config.Security
+	exp := &types.PolicyExplanation{
+		Command: req.Command,
+		Args:    req.Args,
+		WorkDir: req.WorkDir,
+	}
+
+	add := func(name, outcome, detail string) {
+		exp.Rules = append(exp.Rules, types.PolicyRule{Name: name, Outcome: outcome, Detail: detail})
+	}
+
+	// Command length
+	if sec.MaxCommandLength > 0 {
+		cmdLen := len(req.Command) + len(strings.Join(req.Args, " "))
+		if cmdLen > sec.MaxCommandLength {
+			add("max_command_length", types.PolicyRuleDeny, fmt.Sprintf("command is %d characters, limit is %d", cmdLen, sec.MaxCommandLength))
+		} else {
+			add("max_command_length", types.PolicyRulePass, fmt.Sprintf("command is %d characters, limit is %d", cmdLen, sec.MaxCommandLength))
+		}
+	} else {
+		add("max_command_length", types.PolicyRuleSkip, "no limit configured")
+	}
+
+	// Working directory
+	switch info, err := os.Stat(req.WorkDir); {
+	case req.WorkDir == "":
+		add("workdir", types.PolicyRuleSkip, "no workdir given; the server's directory is used")
+	case !filepath.IsAbs(req.WorkDir):
+		add("workdir", types.PolicyRuleDeny, "workdir must be an absolute path")
+	case err != nil:
+		add("workdir", types.PolicyRuleDeny, "workdir not found")
+	case !info.IsDir():
+		add("workdir", types.PolicyRuleDeny, "workdir is not a directory")
+	default:
+		add("workdir", types.PolicyRulePass, "workdir exists")
+	}
+
+	// Blocked commands
+	if entry := e.config.MatchBlockedCommand(req.Command, req.WorkDir); entry != "" {
+		if allowed := e.config.MatchAllowedCommand(req.Command, req.WorkDir); e.config.OverridesBlock(entry, allowed) {
+			add("blocked_commands", types.PolicyRulePass, fmt.Sprintf("matches blocked entry %q, overridden by explicit allowed entry %q (command_precedence: %s)", entry, allowed, sec.CommandPrecedence))
+		} else {
+			add("blocked_commands", types.PolicyRuleDeny, fmt.Sprintf("matches blocked entry %q", entry))
+		}
+	} else if len(sec.BlockedCommands) > 0 {
+		add("blocked_commands", types.PolicyRulePass, fmt.Sprintf("matches none of %d blocked entries", len(sec.BlockedCommands)))
+	} else {
+		add("blocked_commands", types.PolicyRuleSkip, "no blocked commands configured")
+	}
+
+	// Allowed commands
+	if len(sec.AllowedCommands) == 0 {
+		add("allowed_commands", types.PolicyRuleSkip, "no allowlist configured; commands not blocked are allowed")
+	} else if entry := e.config.MatchAllowedCommand(req.Command, req.WorkDir); entry != "" {
+		add("allowed_commands", types.PolicyRulePass, fmt.Sprintf("matches allowed entry %q", entry))
+	} else {
+		add("allowed_commands", types.PolicyRuleDeny, fmt.Sprintf("matches none of %d allowed entries", len(sec.AllowedCommands)))
+	}
+
+	// Denied paths
+	switch {
+	case req.WorkDir == "":
+		add("denied_paths", types.PolicyRuleSkip, "no workdir given")
+	case len(sec.DeniedPaths) == 0:
+		add("denied_paths", types.PolicyRuleSkip, "no denied paths configured")
+	default:
+		if entry := e.config.MatchDeniedPath(req.WorkDir); entry != "" {
+			add("denied_paths", types.PolicyRuleDeny, fmt.Sprintf("within denied path %q", entry))
+		} else {
+			add("denied_paths", types.PolicyRulePass, fmt.Sprintf("outside all %d denied paths", len(sec.DeniedPaths)))
+		}
+	}
+
+	// Allowed paths
+	switch {
+	case req.WorkDir == "":
+		add("allowed_paths", types.PolicyRuleSkip, "no workdir given")
+	case len(sec.AllowedPaths) == 0:
+		add("allowed_paths", types.PolicyRuleSkip, "no path restrictions configured")
+	default:
+		if entry := e.config.MatchAllowedPath(req.WorkDir); entry != "" {
+			add("allowed_paths", types.PolicyRulePass, fmt.Sprintf("within allowed path %q", entry))
+		} else {
+			add("allowed_paths", types.PolicyRuleDeny, fmt.Sprintf("outside all %d allowed paths", len(sec.AllowedPaths)))
+		}
+	}
+
+	// Paths named by arguments
+	switch {
+	case !sec.DenyPathArgs:
+		add("deny_path_args", types.PolicyRuleSkip, "arguments are not checked against denied or allowed paths")
+	case len(sec.DeniedPaths) == 0 && len(sec.AllowedPaths) == 0:
+		add("deny_path_args", types.PolicyRuleSkip, "no denied or allowed paths configured")
+	default:
+		if arg, entry := e.deniedPathArg(req); arg != "" {
+			add("deny_path_args", types.PolicyRuleDeny, fmt.Sprintf("argument or workdir %q is or contains denied path %q", arg, entry))
+		} else if arg := e.outsidePathArg(req); arg != "" {
+			add("deny_path_args", types.PolicyRuleDeny, fmt.Sprintf("argument %q is outside all %d allowed paths", arg, len(sec.AllowedPaths)))
+		} else {
+			add("deny_path_args", types.PolicyRulePass, "no argument names a denied path or one outside allowed paths")
+		}
+	}
+
+	// Shell metacharacters
+	if !sec.DisableShellExpansion {
+		add("disable_shell_expansion", types.PolicyRuleSkip, "shell metacharacters are not checked")
+	} else if char := findShellMetacharacter(req); char != "" {
+		add("disable_shell_expansion", types.PolicyRuleDeny, fmt.Sprintf("contains shell metacharacter %q", char))
+	} else {
+		add("disable_shell_expansion", types.PolicyRulePass, "no shell metacharacters")
+	}
+
+	// Operations of cloud command line tools
+	if e.cliPolicies.Len() == 0 {
+		add("cli_policies", types.PolicyRuleSkip, "no cli policies configured")
+	} else if cli, denial := e.cliPolicies.Check(req.Command, req.Args); denial != nil {
+		add("cli_policies", types.PolicyRuleDeny, denial.CLI+": "+denial.Reason)
+	} else if cli != "" {
+		add("cli_policies", types.PolicyRulePass, fmt.Sprintf("operation allowed by the %s policy", cli))
+	} else {
+		add("cli_policies", types.PolicyRulePass, "no cli policy applies")
+	}
+
+	// Requests flagged by screening are denied or wait for approval
+	switch flag := e.screening.Check(req.Command, req.Args); {
+	case !e.screening.Enabled():
+		add("screening", types.PolicyRuleSkip, "screening is off")
+	case flag == nil:
+		add("screening", types.PolicyRulePass, "not flagged by any heuristic")
+	case e.screening.Action() == config.ScreeningBlock:
+		add("screening", types.PolicyRuleDeny, fmt.Sprintf("flagged by %s: %s", flag.Heuristic, flag.Detail))
+	default:
+		add("screening", types.PolicyRulePass, fmt.Sprintf("flagged by %s, requires approval by %d operators: %s", flag.Heuristic, approval.Required, flag.Detail))
+	}
+
+	// Package operations outside the allowlist wait for approval rather
+	// than being denied
+	if e.packages.Len() == 0 {
+		add("package_policies", types.PolicyRuleSkip, "no package policies configured")
+	} else if manager, held := e.packages.Check(req.Command, req.Args); held != nil {
+		add("package_policies", types.PolicyRulePass, fmt.Sprintf("%s %s requires approval by %d operators: %s", held.Manager, held.Operation, approval.Required, held.Reason))
+	} else if manager != "" {
+		add("package_policies", types.PolicyRulePass, fmt.Sprintf("operation allowed by the %s policy", manager))
+	} else {
+		add("package_policies", types.PolicyRulePass, "no package policy applies")
+	}
+
+	// Time window and run count conditions
+	if e.conditions.Len() == 0 {
+		add("conditions", types.PolicyRuleSkip, "no conditions configured")
+	} else if matched, denial := e.conditions.Check(req.Command, req.Args); denial != nil {
+		add("conditions", types.PolicyRuleDeny, strings.TrimPrefix(denial.Error(), "denied by "))
+	} else if len(matched) > 0 {
+		add("conditions", types.PolicyRulePass, "allowed now by "+strings.Join(matched, ", "))
+	} else {
+		add("conditions", types.PolicyRulePass, fmt.Sprintf("matches none of %d conditions", e.conditions.Len()))
+	}
+
+	exp.Allowed = true
+	exp.Decision = "allowed: no rule denies the command"
+	for i := range exp.Rules {
+		if exp.Rules[i].Outcome == types.PolicyRuleDeny {
+			exp.Rules[i].Decisive = true
+			exp.Allowed = false
+			exp.Decision = fmt.Sprintf("denied by %s: %s", exp.Rules[i].Name, exp.Rules[i].Detail)
+			if sec.Policy == config.PolicyLearn && isLearnedRule(exp.Rules[i].Name) {
+				exp.Decision += " (would be recorded for policy review)"
+			}
+			break
+		}
+	}
+
+	return exp
+}
+
+// isLearnedRule reports whether denials by a rule are recorded in learn
+// mode.
+func isLearnedRule(name string) bool {
+	return name == "blocked_commands" || name == "allowed_commands" || name == "allowed_paths"
+}
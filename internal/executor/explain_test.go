@@ -0,0 +1,130 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestExecutor_ExplainPolicy(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg := config.Default()
+	cfg.Security.AllowedCommands = []string{"echo", "ls", "rm", "kubectl"}
+	cfg.Security.CLIPolicies = []config.CLIPolicy{{CLI: "kubectl"}}
+	cfg.Security.AllowedPaths = []string{dir}
+	cfg.Security.DeniedPaths = []string{filepath.Join(dir, "secrets")}
+	cfg.Security.DenyPathArgs = true
+	e := New(cfg, logger.Default())
+
+	work := filepath.Join(dir, "work")
+	for _, sub := range []string{"secrets", "work"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		req      *types.CommandExecutionRequest
+		decisive string
+	}{
+		{
+			name: "allowed",
+			req:  &types.CommandExecutionRequest{Command: "echo", Args: []string{"hi"}, WorkDir: work},
+		},
+		{
+			name:     "blocked beats allowed",
+			req:      &types.CommandExecutionRequest{Command: "rm"},
+			decisive: "blocked_commands",
+		},
+		{
+			name:     "not allowlisted",
+			req:      &types.CommandExecutionRequest{Command: "make"},
+			decisive: "allowed_commands",
+		},
+		{
+			name:     "path outside allowed paths",
+			req:      &types.CommandExecutionRequest{Command: "ls", WorkDir: t.TempDir()},
+			decisive: "allowed_paths",
+		},
+		{
+			name:     "denied path beats allowed path",
+			req:      &types.CommandExecutionRequest{Command: "ls", WorkDir: filepath.Join(dir, "secrets")},
+			decisive: "denied_paths",
+		},
+		{
+			name:     "argument in denied path",
+			req:      &types.CommandExecutionRequest{Command: "ls", Args: []string{"-la", "--color=never", "../secrets/key"}, WorkDir: work},
+			decisive: "deny_path_args",
+		},
+		{
+			name:     "argument outside allowed paths",
+			req:      &types.CommandExecutionRequest{Command: "ls", Args: []string{t.TempDir()}, WorkDir: work},
+			decisive: "deny_path_args",
+		},
+		{
+			name:     "workdir containing denied path",
+			req:      &types.CommandExecutionRequest{Command: "ls", WorkDir: dir},
+			decisive: "deny_path_args",
+		},
+		{
+			name:     "shell metacharacters",
+			req:      &types.CommandExecutionRequest{Command: "echo", Args: []string{"a; b"}},
+			decisive: "disable_shell_expansion",
+		},
+		{
+			name:     "mutating cli operation",
+			req:      &types.CommandExecutionRequest{Command: "kubectl", Args: []string{"delete", "pod", "api"}},
+			decisive: "cli_policies",
+		},
+		{
+			name: "read-only cli operation",
+			req:  &types.CommandExecutionRequest{Command: "kubectl", Args: []string{"get", "pods"}},
+		},
+		{
+			name:     "relative workdir",
+			req:      &types.CommandExecutionRequest{Command: "rm", WorkDir: "relative"},
+			decisive: "workdir",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp := e.ExplainPolicy(tt.req)
+
+			if len(exp.Rules) != 12 {
+				t.Errorf("expected all 12 rules to be evaluated, got %d", len(exp.Rules))
+			}
+
+			var decisive []string
+			for _, rule := range exp.Rules {
+				if rule.Decisive {
+					decisive = append(decisive, rule.Name)
+				}
+			}
+
+			if tt.decisive == "" {
+				if !exp.Allowed || len(decisive) != 0 {
+					t.Errorf("expected allowed with no decisive rule, got %+v", exp)
+				}
+			} else if exp.Allowed || len(decisive) != 1 || decisive[0] != tt.decisive {
+				t.Errorf("expected denial by %s, got allowed=%v decisive=%v", tt.decisive, exp.Allowed, decisive)
+			}
+
+			// The explanation agrees with the checks Execute runs
+			err := e.validateRequest(tt.req)
+			if err == nil {
+				err = e.checkSecurity(context.Background(), tt.req)
+			}
+			if (err == nil) != exp.Allowed {
+				t.Errorf("explanation allowed=%v disagrees with checks: %v", exp.Allowed, err)
+			}
+		})
+	}
+}
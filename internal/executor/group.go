@@ -0,0 +1,52 @@
+package executor
+
+import (
+	"context"
+	"sync"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// groupLocks holds one lock per concurrency group. The zero value is
+// ready to use.
+type groupLocks struct {
+	mu     sync.Mutex
+	groups map[string]chan struct{}
+}
+
+// lock returns the lock channel for a group, creating it on first use.
+func (g *groupLocks) lock(group string) chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.groups == nil {
+		g.groups = make(map[string]chan struct{})
+	}
+	ch, ok := g.groups[group]
+	if !ok {
+		ch = make(chan struct{}, 1)
+		g.groups[group] = ch
+	}
+	return ch
+}
+
+// acquireGroup waits until no other command of the group is running. The
+// returned function releases the group.
+func (e *Executor) acquireGroup(ctx context.Context, group string) (func(), error) {
+	ch := e.groups.lock(group)
+
+	select {
+	case ch <- struct{}{}:
+		return func() { <-ch }, nil
+	default:
+	}
+
+	e.logger.Debug("waiting for concurrency group", "group", group)
+
+	select {
+	case ch <- struct{}{}:
+		return func() { <-ch }, nil
+	case <-ctx.Done():
+		return nil, apperrors.TimeoutError("context cancelled while waiting for concurrency group "+group, "")
+	}
+}
@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func TestExecutor_acquireGroup(t *testing.T) {
+	e := New(config.Default(), logger.Default())
+
+	release, err := e.acquireGroup(context.Background(), "repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Another group is independent
+	other, err := e.acquireGroup(context.Background(), "npm")
+	if err != nil {
+		t.Fatalf("unexpected error for other group: %v", err)
+	}
+	other()
+
+	// The same group waits until released
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := e.acquireGroup(ctx, "repo"); err == nil {
+		t.Fatal("expected timeout while group is held")
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		r, err := e.acquireGroup(context.Background(), "repo")
+		if err == nil {
+			r()
+		}
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("group acquired while held")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release()
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("queued run did not acquire the group after release")
+	}
+}
+
+func TestExecutor_ExecuteConfigCommandGroup(t *testing.T) {
+	e := New(config.Default(), logger.Default())
+
+	release, err := e.acquireGroup(context.Background(), "repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+
+	cmd := &config.Command{Name: "status", Command: "echo", ConcurrencyGroup: "repo"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := e.ExecuteConfigCommand(ctx, cmd, ""); err == nil {
+		t.Error("expected command to wait for its concurrency group")
+	}
+}
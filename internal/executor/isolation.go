@@ -0,0 +1,78 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// cleanEnv are the variables env_clean keeps for every command: the
+// search path, user, locale, terminal and what Windows needs to start
+// processes.
+var cleanEnv = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "LANG", "LANGUAGE", "LC_*", "TERM", "TZ", "TMPDIR",
+	"SystemRoot", "SystemDrive", "windir", "ComSpec", "PATHEXT", "TEMP", "TMP", "USERPROFILE",
+	"USERNAME", "APPDATA", "LOCALAPPDATA", "ProgramData", "ProgramFiles", "ProgramFiles(x86)",
+	"NUMBER_OF_PROCESSORS", "PROCESSOR_ARCHITECTURE",
+}
+
+// categoryEnv are the variables env_clean also keeps for each category.
+var categoryEnv = map[string][]string{
+	config.CategoryBuild: {
+		"GO*", "CGO_*", "CARGO_*", "RUSTUP_*", "RUSTFLAGS", "NODE_*", "NPM_CONFIG_*", "npm_config_*",
+		"PYTHON*", "VIRTUAL_ENV", "PIP_*", "JAVA_HOME", "CC", "CXX", "CFLAGS", "CXXFLAGS", "LDFLAGS",
+		"PKG_CONFIG_PATH", "MAKEFLAGS",
+	},
+	config.CategoryVCS: {"GIT_*", "SSH_AUTH_SOCK", "GNUPGHOME", "GPG_TTY"},
+	config.CategoryNetwork: {
+		"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+		"SSL_CERT_FILE", "SSL_CERT_DIR", "REQUESTS_CA_BUNDLE", "CURL_CA_BUNDLE",
+	},
+}
+
+// homeEnv are the variables tmp_home points at the temporary home.
+var homeEnv = []string{"HOME", "USERPROFILE", "XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_DATA_HOME", "XDG_STATE_HOME"}
+
+// isolatedEnv returns the environment of a request under its isolation
+// preset, and a function removing the temporary home it created.
+func (e *Executor) isolatedEnv(req *types.CommandExecutionRequest) ([]string, func(), error) {
+	preset := req.Isolation
+	if !config.IsolationIncludes(preset, config.IsolationEnvClean) {
+		return e.commandEnv(req.Env), func() {}, nil
+	}
+
+	keep := append(append([]string(nil), cleanEnv...), categoryEnv[req.Category]...)
+	tmpHome := config.IsolationIncludes(preset, config.IsolationTmpHome)
+	env := make([]string, 0, len(req.Env)+len(homeEnv))
+	for _, kv := range e.InheritedEnv() {
+		name, _, _ := strings.Cut(kv, "=")
+		if matchEnv(keep, name) && !(tmpHome && matchEnv(homeEnv, name)) {
+			env = append(env, kv)
+		}
+	}
+	if !tmpHome {
+		return append(env, req.Env...), func() {}, nil
+	}
+
+	home, err := os.MkdirTemp("", "mcp-home-")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		if err := os.RemoveAll(home); err != nil {
+			e.logger.WithError(err).Warn("failed to remove temporary home", "path", home)
+		}
+	}
+	env = append(env,
+		"HOME="+home,
+		"USERPROFILE="+home,
+		"XDG_CONFIG_HOME="+filepath.Join(home, ".config"),
+		"XDG_CACHE_HOME="+filepath.Join(home, ".cache"),
+		"XDG_DATA_HOME="+filepath.Join(home, ".local", "share"),
+		"XDG_STATE_HOME="+filepath.Join(home, ".local", "state"),
+	)
+	return append(env, req.Env...), cleanup, nil
+}
@@ -0,0 +1,24 @@
+package executor
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// isolate runs a command with no_network in new user and network
+// namespaces, leaving it only a loopback interface that is down. The
+// command fails to start when unprivileged user namespaces are disabled.
+func isolate(cmd *exec.Cmd, preset string) error {
+	if !config.IsolationIncludes(preset, config.IsolationNoNetwork) {
+		return nil
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}},
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package executor
+
+import (
+	"errors"
+	"os/exec"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// isolate refuses no_network, which needs Linux network namespaces,
+// rather than run the command with network access.
+func isolate(cmd *exec.Cmd, preset string) error {
+	if config.IsolationIncludes(preset, config.IsolationNoNetwork) {
+		return errors.New("no_network isolation is only supported on Linux")
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestExecutor_isolatedEnv(t *testing.T) {
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GIT_AUTHOR_NAME", "smr")
+	t.Setenv("SMR_TEST_SECRET", "1")
+
+	e := New(config.Default(), logger.Default())
+	tests := []struct {
+		isolation string
+		category  string
+		want      map[string]bool
+	}{
+		{"", "", map[string]bool{"GOFLAGS": true, "GIT_AUTHOR_NAME": true, "SMR_TEST_SECRET": true}},
+		{config.IsolationEnvClean, config.CategoryBuild, map[string]bool{"PATH": true, "GOFLAGS": true, "GIT_AUTHOR_NAME": false, "SMR_TEST_SECRET": false}},
+		{config.IsolationEnvClean, config.CategoryVCS, map[string]bool{"GOFLAGS": false, "GIT_AUTHOR_NAME": true, "SMR_TEST_SECRET": false}},
+	}
+	for _, tt := range tests {
+		req := &types.CommandExecutionRequest{Isolation: tt.isolation, Category: tt.category, Env: []string{"SMR_OWN=1"}}
+		env, cleanup, err := e.isolatedEnv(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cleanup()
+		for name, want := range tt.want {
+			if hasEnv(env, name) != want {
+				t.Errorf("isolatedEnv(%q, %q) has %s = %v, want %v", tt.isolation, tt.category, name, !want, want)
+			}
+		}
+		if !hasEnv(env, "SMR_OWN") {
+			t.Errorf("isolatedEnv(%q, %q) dropped the command's own variables", tt.isolation, tt.category)
+		}
+	}
+
+	// tmp_home replaces HOME with a directory removed by cleanup
+	env, cleanup, err := e.isolatedEnv(&types.CommandExecutionRequest{Isolation: config.IsolationTmpHome})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var home string
+	for _, kv := range env {
+		if v, ok := strings.CutPrefix(kv, "HOME="); ok {
+			if home != "" {
+				t.Error("HOME set twice")
+			}
+			home = v
+		}
+	}
+	if home == "" || home == os.Getenv("HOME") {
+		t.Fatalf("HOME = %q, want a temporary directory", home)
+	}
+	if _, err := os.Stat(home); err != nil {
+		t.Fatalf("temporary home missing: %v", err)
+	}
+	cleanup()
+	if _, err := os.Stat(home); !os.IsNotExist(err) {
+		t.Errorf("temporary home %s not removed", home)
+	}
+}
+
+func TestExecutor_isolationNoNetwork(t *testing.T) {
+	e := New(config.Default(), logger.Default())
+	cmd := &config.Command{Name: "offline", Command: "cat", Args: []string{"/proc/net/dev"}, Isolation: config.IsolationNoNetwork}
+	if runtime.GOOS == "windows" {
+		cmd.Command, cmd.Args = "cmd", []string{"/c", "echo"}
+	}
+
+	result, err := e.ExecuteConfigCommand(context.Background(), cmd, "")
+	if err != nil {
+		t.Fatalf("ExecuteConfigCommand() error = %v", err)
+	}
+	if runtime.GOOS != "linux" {
+		if result.ErrorMessage == "" || result.ExitCode != -1 {
+			t.Errorf("no_network ran without network namespaces: %+v", result)
+		}
+		return
+	}
+	if strings.Contains(result.ErrorMessage, "failed to start") {
+		t.Skipf("user namespaces unavailable: %s", result.ErrorMessage)
+	}
+	if result.Isolation != config.IsolationNoNetwork {
+		t.Errorf("isolation = %q, want no_network", result.Isolation)
+	}
+	// Only the loopback interface is left, after two header lines
+	lines := strings.Split(strings.TrimSpace(result.Stdout), "\n")
+	if len(lines) < 3 {
+		t.Fatalf("unexpected /proc/net/dev: %q", result.Stdout)
+	}
+	for _, line := range lines[2:] {
+		if name, _, _ := strings.Cut(strings.TrimSpace(line), ":"); name != "lo" {
+			t.Errorf("interface %s visible with no_network", name)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// loginShellMarker separates what a login shell's profile prints from the
+// environment it reports.
+const loginShellMarker = "__SIMPLE_MCP_RUNNER_ENV__"
+
+// loginShellTimeout limits how long a login shell may take to start.
+const loginShellTimeout = 10 * time.Second
+
+// shellOnlyEnv are variables describing the login shell itself rather than
+// the user's environment.
+var shellOnlyEnv = map[string]bool{"_": true, "SHLVL": true, "PWD": true, "OLDPWD": true}
+
+// envAssignment matches the start of a variable in env output; other
+// lines continue the value of the previous variable.
+var envAssignment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// loginEnv is the environment of the user's login shell, taken at startup
+// and again in the background once older than login_shell_env_refresh.
+type loginEnv struct {
+	mu         sync.RWMutex
+	env        []string // Nil until a login shell reported its environment
+	taken      time.Time
+	refreshing atomic.Bool
+}
+
+// loginShellEnv returns the login shell environment, or nil when it could
+// not be taken. A stale environment is returned while it is retaken.
+func (e *Executor) loginShellEnv() []string {
+	l := e.login
+	l.mu.RLock()
+	env, taken := l.env, l.taken
+	l.mu.RUnlock()
+
+	refresh := e.config.Execution.LoginShellEnvRefresh.Std()
+	if refresh > 0 && time.Since(taken) > refresh && l.refreshing.CompareAndSwap(false, true) {
+		go func() {
+			defer l.refreshing.Store(false)
+			e.takeLoginEnv()
+		}()
+	}
+	return env
+}
+
+// takeLoginEnv takes the environment of a login shell. When that fails the
+// previous environment is kept.
+func (e *Executor) takeLoginEnv() {
+	shell := loginShell()
+	env, err := captureLoginEnv(context.Background(), shell)
+
+	l := e.login
+	l.mu.Lock()
+	l.taken = time.Now()
+	if err == nil {
+		l.env = env
+	}
+	l.mu.Unlock()
+
+	if err != nil {
+		e.logger.WithError(err).Warn("failed to take login shell environment", "shell", shell)
+		return
+	}
+	e.logger.Debug("took login shell environment", "shell", shell, "variables", len(env))
+}
+
+// loginShell returns the user's shell.
+func loginShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
+
+// captureLoginEnv starts shell as a login shell, so it reads the user's
+// profile, and returns the environment it ends up with.
+func captureLoginEnv(ctx context.Context, shell string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, loginShellTimeout)
+	defer cancel()
+
+	// #nosec G204 - The shell is the user's own
+	cmd := exec.CommandContext(ctx, shell, "-l", "-c", "echo "+loginShellMarker+"; env")
+	cmd.WaitDelay = time.Second
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	_, env, found := strings.Cut(string(out), loginShellMarker+"\n")
+	if !found {
+		return nil, errors.New("login shell did not report its environment")
+	}
+	return parseEnv(env), nil
+}
+
+// parseEnv parses the output of env, leaving out the variables of the
+// shell itself.
+func parseEnv(out string) []string {
+	var env []string
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if !envAssignment.MatchString(line) {
+			// A value spanning lines
+			if len(env) > 0 {
+				env[len(env)-1] += "\n" + line
+			}
+			continue
+		}
+		env = append(env, line)
+	}
+
+	kept := env[:0]
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if !shellOnlyEnv[name] {
+			kept = append(kept, kv)
+		}
+	}
+	return kept
+}
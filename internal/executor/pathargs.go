@@ -0,0 +1,90 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// deniedPathArg returns the first argument of a request naming a path
+// inside a denied path, or a directory containing one, with the denied
+// entry. Arguments, and the values of --flag=value arguments, are resolved
+// against the working directory; other flags are skipped. Commands such as
+// grep -r read their working directory without naming it, so a working
+// directory containing a denied path is returned too.
+func (e *Executor) deniedPathArg(req *types.CommandExecutionRequest) (string, string) {
+	base := argBase(req)
+	if entry := e.config.MatchDeniedBelow(base); entry != "" {
+		return base, entry
+	}
+	for _, arg := range req.Args {
+		path, _ := argPath(base, arg)
+		if path == "" {
+			continue
+		}
+		if entry := e.config.MatchDeniedPath(path); entry != "" {
+			return arg, entry
+		}
+		if entry := e.config.MatchDeniedBelow(path); entry != "" {
+			return arg, entry
+		}
+	}
+	return "", ""
+}
+
+// outsidePathArg returns the first argument of a request naming a path
+// outside all allowed paths, resolved like in deniedPathArg, or "" if none
+// does or no allowed paths are set. Relative arguments are only checked
+// when the working directory is inside an allowed path, so they cannot
+// climb out of it: in a working directory outside allowed paths, such as
+// the server's own, words like grep patterns would resolve outside too.
+func (e *Executor) outsidePathArg(req *types.CommandExecutionRequest) string {
+	if len(e.config.Security.AllowedPaths) == 0 {
+		return ""
+	}
+	base := argBase(req)
+	baseAllowed := e.config.MatchAllowedPath(base) != ""
+	for _, arg := range req.Args {
+		path, abs := argPath(base, arg)
+		if path == "" || (!abs && !baseAllowed) {
+			continue
+		}
+		if e.config.MatchAllowedPath(path) == "" {
+			return arg
+		}
+	}
+	return ""
+}
+
+// argBase returns the directory arguments of a request are resolved
+// against: its working directory, or the server's.
+func argBase(req *types.CommandExecutionRequest) string {
+	if req.WorkDir != "" {
+		return req.WorkDir
+	}
+	base, _ := os.Getwd()
+	return base
+}
+
+// argPath returns the path an argument names resolved against base, and
+// whether it was absolute. Flags name the values of --flag=value
+// arguments; other flags name none and return "".
+func argPath(base, arg string) (string, bool) {
+	path := arg
+	if strings.HasPrefix(arg, "-") {
+		_, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return "", false
+		}
+		path = value
+	}
+	if path == "" {
+		return "", false
+	}
+	if filepath.IsAbs(path) {
+		return path, true
+	}
+	return filepath.Join(base, path), false
+}
@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestExecutor_deniedPathArg(t *testing.T) {
+	dir := t.TempDir()
+	secrets := filepath.Join(dir, "secrets")
+	work := filepath.Join(dir, "work")
+
+	cfg := config.Default()
+	cfg.Security.DeniedPaths = []string{secrets}
+	cfg.Security.DenyPathArgs = true
+	e := New(cfg, logger.Default())
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-la", "."}, ""},
+		{[]string{"notes.txt"}, ""},
+		{[]string{"../secrets/key"}, "../secrets/key"},
+		{[]string{"-n", filepath.Join(secrets, "key")}, filepath.Join(secrets, "key")},
+		{[]string{"--file=../secrets"}, "--file=../secrets"},
+		{[]string{"-f../secrets"}, ""},       // Short flags with attached values are not parsed
+		{[]string{"-rn", "KEY", ".."}, ".."}, // A directory containing a denied path
+		{[]string{"--dir=" + dir}, "--dir=" + dir},
+	}
+	for _, tt := range tests {
+		req := &types.CommandExecutionRequest{Command: "cat", Args: tt.args, WorkDir: work}
+		if got, _ := e.deniedPathArg(req); got != tt.want {
+			t.Errorf("deniedPathArg(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	// So is a working directory containing a denied path
+	req := &types.CommandExecutionRequest{Command: "grep", Args: []string{"-rn", "KEY"}, WorkDir: dir}
+	if got, _ := e.deniedPathArg(req); got != dir {
+		t.Errorf("deniedPathArg() in %s = %q, want the workdir", dir, got)
+	}
+
+	// Execution is denied before the command runs
+	_, err := e.Execute(context.Background(), &types.CommandExecutionRequest{Command: "cat", Args: []string{filepath.Join(secrets, "key")}})
+	if !errors.Is(err, &apperrors.Error{Type: apperrors.ErrorTypePermission}) {
+		t.Errorf("Execute() error = %v, want a permission error", err)
+	}
+}
+
+func TestExecutor_outsidePathArg(t *testing.T) {
+	dir := t.TempDir()
+	work := filepath.Join(dir, "work")
+
+	cfg := config.Default()
+	cfg.Security.AllowedPaths = []string{dir}
+	cfg.Security.DenyPathArgs = true
+	e := New(cfg, logger.Default())
+
+	tests := []struct {
+		workDir string
+		args    []string
+		want    string
+	}{
+		{work, []string{"-la", "."}, ""},
+		{work, []string{"-rn", "KEY", ".."}, ""},
+		{work, []string{filepath.Join(dir, "notes.txt")}, ""},
+		{work, []string{"../.."}, "../.."},
+		{work, []string{"-rn", "KEY", "/etc"}, "/etc"},
+		{work, []string{"--file=/etc/passwd"}, "--file=/etc/passwd"},
+		// Relative arguments in a working directory outside allowed paths
+		// are not taken for paths, absolute ones are
+		{"", []string{"-rn", "KEY"}, ""},
+		{"", []string{"/"}, "/"},
+	}
+	for _, tt := range tests {
+		req := &types.CommandExecutionRequest{Command: "grep", Args: tt.args, WorkDir: tt.workDir}
+		if got := e.outsidePathArg(req); got != tt.want {
+			t.Errorf("outsidePathArg(%q) in %q = %q, want %q", tt.args, tt.workDir, got, tt.want)
+		}
+	}
+
+	// Execution is denied before the command runs
+	_, err := e.Execute(context.Background(), &types.CommandExecutionRequest{Command: "ls", Args: []string{"/"}, WorkDir: dir})
+	if !errors.Is(err, &apperrors.Error{Type: apperrors.ErrorTypePermission}) {
+		t.Errorf("Execute(ls /) error = %v, want a permission error", err)
+	}
+}
+
+func TestExecutor_safeDefaultHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		t.Skip("no home directory")
+	}
+	cfg, err := config.Preset(config.PresetSafeDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := New(cfg, logger.Default())
+
+	// Searching the home directory would reach the credential directories
+	req := &types.CommandExecutionRequest{Command: "grep", Args: []string{"-rnI", "KEY", home}}
+	if err := e.checkSecurity(context.Background(), req); !errors.Is(err, &apperrors.Error{Type: apperrors.ErrorTypePermission}) {
+		t.Errorf("checkSecurity(grep -rnI KEY %s) error = %v, want a permission error", home, err)
+	}
+
+	// and so would listing the root, outside the startup directory
+	req = &types.CommandExecutionRequest{Command: "ls", Args: []string{"-la", "/"}}
+	if err := e.checkSecurity(context.Background(), req); !errors.Is(err, &apperrors.Error{Type: apperrors.ErrorTypePermission}) {
+		t.Errorf("checkSecurity(ls -la /) error = %v, want a permission error", err)
+	}
+}
@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"errors"
+	"os"
+	"slices"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// Pre-flight checks.
+const (
+	PreflightDiskSpace = "disk_space"
+	PreflightBinary    = "binary"
+)
+
+// preflightContext is the error context key holding the failed check.
+const preflightContext = "preflight"
+
+// preflight checks a configured command before it starts when its
+// category is checked: the free space of its working directory and of the
+// temporary directory, and that its binary exists. Free space that cannot
+// be read is logged and not checked.
+func (e *Executor) preflight(req *types.CommandExecutionRequest) error {
+	cfg := e.config.Execution.Preflight
+	if req.Category == "" || !slices.Contains(cfg.Categories, req.Category) {
+		return nil
+	}
+
+	dir := req.WorkDir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	for _, check := range []struct {
+		dir string
+		min config.ByteSize
+	}{{dir, cfg.MinFreeDisk}, {os.TempDir(), cfg.MinFreeTemp}} {
+		if check.min <= 0 || check.dir == "" {
+			continue
+		}
+		free, err := freeSpace(check.dir)
+		if err != nil {
+			e.logger.WithError(err).Warn("failed to read free disk space", "path", check.dir)
+			continue
+		}
+		if free < uint64(check.min) {
+			available := int64(min(free, uint64(1<<63-1)))
+			msg := e.msg.Sprintf("not enough free disk space in %s: %s available, %s required",
+				check.dir, sizeString(available), check.min.String())
+			return preflightError(msg, types.PreflightFailure{
+				Check: PreflightDiskSpace, Path: check.dir, Available: available, Required: int64(check.min),
+			})
+		}
+	}
+
+	// Project environments, pinned toolchains and login shells find
+	// binaries in their own PATH
+	if e.login != nil || e.devEnvironment(req) != nil || len(e.config.PinnedToolchains(requestDir(req))) > 0 {
+		return nil
+	}
+	path, err := BinaryPath(req.Command, req.WorkDir)
+	if err == nil {
+		_, err = os.Stat(path)
+	}
+	if err != nil {
+		return preflightError(e.msg.Sprintf("command not found: %s", req.Command),
+			types.PreflightFailure{Check: PreflightBinary, Path: req.Command})
+	}
+	return nil
+}
+
+// preflightError returns the error of a failed pre-flight check, holding
+// the check for PreflightFailureOf.
+func preflightError(msg string, failure types.PreflightFailure) error {
+	return apperrors.New(apperrors.ErrorTypeExecution, msg).WithContext(preflightContext, &failure)
+}
+
+// PreflightFailureOf returns the pre-flight check an execution error
+// reports, or nil when the error is not a pre-flight failure.
+func PreflightFailureOf(err error) *types.PreflightFailure {
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) {
+		return nil
+	}
+	failure, _ := appErr.GetContext(preflightContext)
+	f, _ := failure.(*types.PreflightFailure)
+	return f
+}
+
+// sizeString formats a size in whole MiB, or the largest unit it is a
+// whole number of.
+func sizeString(n int64) string {
+	if n >= 1<<20 {
+		n -= n % (1 << 20)
+	}
+	return config.ByteSize(n).String()
+}
@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func TestExecutor_preflight(t *testing.T) {
+	echo := &config.Command{Name: "build", Command: "echo", Args: []string{"ok"}, Category: config.CategoryBuild}
+	if runtime.GOOS == "windows" {
+		echo.Command, echo.Args = "cmd", []string{"/c", "echo", "ok"}
+	}
+
+	cfg := config.Default()
+	cfg.Execution.Preflight.MinFreeDisk = 1 << 60
+	e := New(cfg, logger.Default())
+	dir := t.TempDir()
+	_, err := e.ExecuteConfigCommand(context.Background(), echo, dir)
+	if f := PreflightFailureOf(err); f == nil || f.Check != PreflightDiskSpace || f.Path != dir || f.Required != 1<<60 || f.Available <= 0 {
+		t.Fatalf("ExecuteConfigCommand() error = %v, failure = %+v, want a disk space failure", err, f)
+	}
+
+	// Commands of categories that are not checked run
+	vcs := *echo
+	vcs.Category = config.CategoryVCS
+	if result, err := e.ExecuteConfigCommand(context.Background(), &vcs, dir); err != nil || result.ExitCode != 0 {
+		t.Errorf("ExecuteConfigCommand(vcs) = %+v, %v", result, err)
+	}
+
+	cfg = config.Default()
+	cfg.Execution.Preflight.MinFreeDisk = 1
+	cfg.Execution.Preflight.MinFreeTemp = 1
+	e = New(cfg, logger.Default())
+	if result, err := e.ExecuteConfigCommand(context.Background(), echo, dir); err != nil || result.ExitCode != 0 {
+		t.Errorf("ExecuteConfigCommand() = %+v, %v, want the checks to pass", result, err)
+	}
+	missing := &config.Command{Name: "missing", Command: "smr-no-such-binary", Category: config.CategoryBuild}
+	_, err = e.ExecuteConfigCommand(context.Background(), missing, dir)
+	if f := PreflightFailureOf(err); f == nil || f.Check != PreflightBinary || f.Path != missing.Command {
+		t.Errorf("ExecuteConfigCommand(missing) error = %v, failure = %+v, want a binary failure", err, f)
+	}
+	if PreflightFailureOf(context.Canceled) != nil {
+		t.Error("PreflightFailureOf() reported a failure for another error")
+	}
+}
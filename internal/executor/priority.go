@@ -0,0 +1,25 @@
+package executor
+
+import (
+	"os"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// applyPriority sets the scheduling priority of a started process and
+// returns the priority it runs with, or "" when none was configured. A
+// priority that cannot be set, e.g. high without the privilege to raise
+// priorities, is logged and the process keeps running at normal priority.
+func (e *Executor) applyPriority(p *os.Process, priority string) string {
+	if priority == "" || priority == config.PriorityNormal {
+		return priority
+	}
+	if err := setPriority(p, priority); err != nil {
+		e.logger.WithError(err).Warn("failed to set command priority",
+			"pid", p.Pid,
+			"priority", priority,
+		)
+		return config.PriorityNormal
+	}
+	return priority
+}
@@ -0,0 +1,34 @@
+package executor
+
+import (
+	"os"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"golang.org/x/sys/unix"
+)
+
+// I/O scheduling, see ioprio_set(2).
+const (
+	ioprioWhoProcess     = 1
+	ioprioClassBE        = 2
+	ioprioClassShift     = 13
+	ioprioLowestBELevel  = 7
+	ioprioHighestBELevel = 0
+)
+
+// setPriority sets the nice value and best-effort I/O priority of a
+// process, like nice(1) and ionice(1).
+func setPriority(p *os.Process, priority string) error {
+	nice, level := 10, ioprioLowestBELevel
+	if priority == config.PriorityHigh {
+		nice, level = -5, ioprioHighestBELevel
+	}
+	if err := unix.Setpriority(unix.PRIO_PROCESS, p.Pid, nice); err != nil {
+		return err
+	}
+	ioprio := ioprioClassBE<<ioprioClassShift | level
+	if _, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(p.Pid), uintptr(ioprio)); errno != 0 {
+		return errno
+	}
+	return nil
+}
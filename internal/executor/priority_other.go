@@ -0,0 +1,20 @@
+//go:build !linux && !windows
+
+package executor
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// setPriority sets the nice value of a process, like nice(1). There is no
+// portable way to set its I/O priority.
+func setPriority(p *os.Process, priority string) error {
+	nice := 10
+	if priority == config.PriorityHigh {
+		nice = -5
+	}
+	return syscall.Setpriority(syscall.PRIO_PROCESS, p.Pid, nice)
+}
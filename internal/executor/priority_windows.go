@@ -0,0 +1,25 @@
+//go:build windows
+
+package executor
+
+import (
+	"os"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"golang.org/x/sys/windows"
+)
+
+// setPriority sets the priority class of a process. Windows has no
+// separate I/O priority for other processes.
+func setPriority(p *os.Process, priority string) error {
+	class := uint32(windows.BELOW_NORMAL_PRIORITY_CLASS)
+	if priority == config.PriorityHigh {
+		class = windows.ABOVE_NORMAL_PRIORITY_CLASS
+	}
+	h, err := windows.OpenProcess(windows.PROCESS_SET_INFORMATION, false, uint32(p.Pid))
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(h)
+	return windows.SetPriorityClass(h, class)
+}
@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/quarantine"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// explainQuarantine attributes a failed run to macOS quarantine when its
+// binary is quarantined and not approved, as Gatekeeper then kills it or
+// refuses to start it without saying why.
+func (e *Executor) explainQuarantine(req *types.CommandExecutionRequest, result *types.CommandExecutionResult) {
+	if !quarantine.Supported || result.TimedOut || (result.ExitCode == 0 && result.ErrorMessage == "") {
+		return
+	}
+	path, err := BinaryPath(req.Command, req.WorkDir)
+	if err != nil {
+		return
+	}
+	info := quarantine.Blocked(path)
+	if info == nil {
+		return
+	}
+
+	result.Quarantine = quarantineResult(info)
+	msg := e.msg.Sprintf("%s is quarantined by macOS and Gatekeeper may refuse to run it; remove the quarantine with: xattr -d %s %s", path, quarantine.Attribute, path)
+	if e.config.Security.AllowClearQuarantine {
+		msg = e.msg.Sprintf("%s is quarantined by macOS and Gatekeeper may refuse to run it; remove the quarantine with clear_quarantine", path)
+	}
+	if result.ErrorMessage != "" {
+		msg = result.ErrorMessage + "; " + msg
+	}
+	result.ErrorMessage = msg
+	e.logger.Warn("command binary is quarantined", "command", req.Command, "path", path, "agent", info.Agent)
+}
+
+// ClearQuarantine removes the macOS quarantine attribute of an allowed
+// command's binary, by name or path. It needs security.allow_clear_quarantine
+// and, like commands requiring a second approval, an approved request: without
+// an approval ID one is created and the call denied.
+func (e *Executor) ClearQuarantine(ctx context.Context, command, approvalID string) (*types.Quarantine, error) {
+	if !e.config.Security.AllowClearQuarantine {
+		return nil, apperrors.PermissionError(e.msg.T("clear_quarantine requires security.allow_clear_quarantine"), command)
+	}
+
+	path, err := BinaryPath(command, "")
+	if err != nil {
+		return nil, apperrors.NotFoundError(e.msg.Sprintf("command not found: %s", command), command)
+	}
+	if !e.config.IsCommandAllowed(path, "") {
+		return nil, apperrors.PermissionError(e.msg.Sprintf("command not allowed: %s", command), command)
+	}
+	if e.config.MatchDeniedPath(path) != "" {
+		return nil, apperrors.PermissionError(e.msg.Sprintf("path denied: %s", path), path)
+	}
+
+	info := quarantine.Check(path)
+	if info == nil {
+		return nil, apperrors.NotFoundError(e.msg.Sprintf("%s is not quarantined", path), path)
+	}
+
+	req := &types.CommandExecutionRequest{Command: path, Args: []string{path}}
+	if err := e.checkApproval(ctx, "clear_quarantine", req, approvalID, ""); err != nil {
+		return nil, err
+	}
+	if err := e.consumeApproval(ctx, "clear_quarantine", req, approvalID); err != nil {
+		return nil, err
+	}
+
+	err = quarantine.Clear(path)
+	result := &types.CommandExecutionResult{}
+	if err != nil {
+		result.ExitCode = -1
+	}
+	e.recordApprovedRun(approvalID, result, err)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, e.msg.Sprintf("failed to clear quarantine of %s", path))
+	}
+
+	e.logger.Info("cleared quarantine", "path", path, "agent", info.Agent, "approval_id", approvalID)
+	cleared := quarantineResult(info)
+	cleared.Cleared = true
+	return cleared, nil
+}
+
+// quarantineResult describes a quarantine attribute in results.
+func quarantineResult(info *quarantine.Info) *types.Quarantine {
+	return &types.Quarantine{
+		Path:         info.Path,
+		Agent:        info.Agent,
+		DownloadedAt: info.Time,
+	}
+}
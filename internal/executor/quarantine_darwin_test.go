@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/internal/quarantine"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"golang.org/x/sys/unix"
+)
+
+// quarantined writes an executable with a quarantine attribute the user
+// has not approved.
+func quarantined(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := unix.Setxattr(bin, quarantine.Attribute, []byte("0081;65a1b2c3;Safari;"), 0); err != nil {
+		t.Skipf("cannot set quarantine attribute: %v", err)
+	}
+	return bin
+}
+
+func TestExecutor_explainQuarantine(t *testing.T) {
+	bin := quarantined(t)
+	e := New(config.Default(), logger.Default())
+
+	result := &types.CommandExecutionResult{ExitCode: -1, ErrorMessage: "failed to start command: signal: killed"}
+	e.explainQuarantine(&types.CommandExecutionRequest{Command: bin}, result)
+	if result.Quarantine == nil || result.Quarantine.Path != bin || result.Quarantine.Agent != "Safari" {
+		t.Fatalf("expected quarantine of %s, got %+v", bin, result.Quarantine)
+	}
+	if !strings.Contains(result.ErrorMessage, "signal: killed; ") || !strings.Contains(result.ErrorMessage, "xattr -d com.apple.quarantine") {
+		t.Errorf("unexpected error message %q", result.ErrorMessage)
+	}
+
+	// Successful runs are not blamed on quarantine
+	result = &types.CommandExecutionResult{}
+	e.explainQuarantine(&types.CommandExecutionRequest{Command: bin}, result)
+	if result.Quarantine != nil {
+		t.Errorf("expected no quarantine for a successful run, got %+v", result.Quarantine)
+	}
+}
+
+func TestExecutor_ClearQuarantineApproved(t *testing.T) {
+	ctx := context.Background()
+	bin := quarantined(t)
+
+	cfg := config.Default()
+	cfg.Approvals.File = filepath.Join(t.TempDir(), "approvals.jsonl")
+	cfg.Security.AllowClearQuarantine = true
+	keys := operatorKeys(t, cfg, "alice", "bob")
+	e := New(cfg, logger.Default())
+
+	if _, err := e.ClearQuarantine(ctx, bin, ""); err == nil || !strings.Contains(err.Error(), "approval request") {
+		t.Fatalf("expected clear to be held for approval, got %v", err)
+	}
+	requests, err := e.approvals.List()
+	if err != nil || len(requests) != 1 {
+		t.Fatalf("expected one approval request, got %d (%v)", len(requests), err)
+	}
+	id := requests[0].ID
+	for _, key := range keys {
+		if _, err := e.approvals.Approve(id, key, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cleared, err := e.ClearQuarantine(ctx, bin, id)
+	if err != nil {
+		t.Fatalf("expected approved clear to succeed, got %v", err)
+	}
+	if !cleared.Cleared || cleared.Path != bin {
+		t.Errorf("unexpected result %+v", cleared)
+	}
+	if quarantine.Check(bin) != nil {
+		t.Error("expected quarantine attribute to be removed")
+	}
+}
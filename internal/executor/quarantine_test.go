@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func TestExecutor_ClearQuarantine(t *testing.T) {
+	ctx := context.Background()
+	bin := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Approvals.File = filepath.Join(t.TempDir(), "approvals.jsonl")
+	e := New(cfg, logger.Default())
+	if _, err := e.ClearQuarantine(ctx, bin, ""); err == nil || !strings.Contains(err.Error(), "allow_clear_quarantine") {
+		t.Errorf("expected clear to need allow_clear_quarantine, got %v", err)
+	}
+
+	cfg.Security.AllowClearQuarantine = true
+	cfg.Security.BlockedCommands = []string{"tool"}
+	e = New(cfg, logger.Default())
+	if _, err := e.ClearQuarantine(ctx, bin, ""); err == nil || !strings.Contains(err.Error(), "command not allowed") {
+		t.Errorf("expected blocked command to be denied, got %v", err)
+	}
+
+	// Files without the attribute are left alone, without asking operators
+	cfg.Security.BlockedCommands = nil
+	e = New(cfg, logger.Default())
+	if _, err := e.ClearQuarantine(ctx, bin, ""); err == nil || !strings.Contains(err.Error(), "not quarantined") {
+		t.Errorf("expected unquarantined file to be reported, got %v", err)
+	}
+	if requests, _ := e.approvals.List(); len(requests) != 0 {
+		t.Errorf("expected no approval request, got %d", len(requests))
+	}
+}
@@ -0,0 +1,186 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// QueueFunc receives the position of a command waiting for an execution
+// slot, 1 for the next to run, and the estimated wait, zero when unknown.
+// It is called when the command is queued and each time it moves up.
+type QueueFunc func(position int, wait time.Duration)
+
+type queueFuncKey struct{}
+
+// WithQueueFunc returns a context whose commands report their queue
+// position to fn while they wait for an execution slot.
+func WithQueueFunc(ctx context.Context, fn QueueFunc) context.Context {
+	return context.WithValue(ctx, queueFuncKey{}, fn)
+}
+
+// queueFuncFrom returns the queue function of a context, or nil.
+func queueFuncFrom(ctx context.Context) QueueFunc {
+	fn, _ := ctx.Value(queueFuncKey{}).(QueueFunc)
+	return fn
+}
+
+// slots limits the commands running at once, queueing the others in
+// arrival order.
+type slots struct {
+	mu       sync.Mutex
+	max      int
+	running  int
+	maxQueue int // Zero for no limit
+	queue    []*queued
+	avgRun   time.Duration // Moving average of how long slots are held
+}
+
+// queued is a command waiting for a slot.
+type queued struct {
+	ready chan struct{} // Closed when the slot is handed over
+	moved chan struct{} // Signaled when a command ahead left the queue
+}
+
+// newSlots returns slots for max concurrent commands and at most maxQueue
+// waiting ones.
+func newSlots(max, maxQueue int) *slots {
+	return &slots{max: max, maxQueue: maxQueue}
+}
+
+// acquire waits for a slot, reporting the queue position to notify, and
+// returns the function releasing it. It fails when the queue is full or
+// ctx is done first.
+func (s *slots) acquire(ctx context.Context, notify QueueFunc) (func(), error) {
+	s.mu.Lock()
+	if s.running < s.max && len(s.queue) == 0 {
+		s.running++
+		s.mu.Unlock()
+		return s.releaser(), nil
+	}
+	if s.maxQueue > 0 && len(s.queue) >= s.maxQueue {
+		s.mu.Unlock()
+		metrics.Add("rejected", 1)
+		return nil, apperrors.RateLimitedError(
+			fmt.Sprintf("too many commands waiting: %d running and %d queued", s.max, s.maxQueue), "execution.max_queue")
+	}
+	q := &queued{ready: make(chan struct{}), moved: make(chan struct{}, 1)}
+	s.queue = append(s.queue, q)
+	position, wait := len(s.queue), s.estimate(len(s.queue))
+	s.mu.Unlock()
+
+	metrics.Add("queued", 1)
+	metrics.Add("queue_length", 1)
+	start := time.Now()
+	defer func() {
+		metrics.Add("queue_length", -1)
+		metrics.Add("queue_wait_ms", time.Since(start).Milliseconds())
+	}()
+
+	for {
+		if notify != nil && position > 0 {
+			notify(position, wait)
+		}
+		select {
+		case <-q.ready:
+			return s.releaser(), nil
+		case <-q.moved:
+			s.mu.Lock()
+			position, wait = s.position(q), s.estimate(s.position(q))
+			s.mu.Unlock()
+		case <-ctx.Done():
+			s.mu.Lock()
+			if i := s.index(q); i >= 0 {
+				s.queue = append(s.queue[:i], s.queue[i+1:]...)
+				s.moveUp(i)
+				s.mu.Unlock()
+			} else {
+				// The slot was handed over as ctx ended
+				s.release()
+				s.mu.Unlock()
+			}
+			return nil, apperrors.TimeoutError("context cancelled while waiting for execution slot", "")
+		}
+	}
+}
+
+// releaser returns the function releasing a slot acquired now.
+func (s *slots) releaser() func() {
+	start := time.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			held := time.Since(start)
+			if s.avgRun == 0 {
+				s.avgRun = held
+			} else {
+				s.avgRun = (4*s.avgRun + held) / 5
+			}
+			s.release()
+		})
+	}
+}
+
+// release hands a slot to the first queued command, or frees it. The
+// caller holds s.mu.
+func (s *slots) release() {
+	if len(s.queue) == 0 {
+		s.running--
+		return
+	}
+	next := s.queue[0]
+	s.queue = s.queue[1:]
+	close(next.ready)
+	s.moveUp(0)
+}
+
+// moveUp tells the commands queued from index i on that they moved up.
+// The caller holds s.mu.
+func (s *slots) moveUp(i int) {
+	for _, q := range s.queue[i:] {
+		select {
+		case q.moved <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// index returns the index of a queued command, or -1. The caller holds
+// s.mu.
+func (s *slots) index(q *queued) int {
+	for i, other := range s.queue {
+		if other == q {
+			return i
+		}
+	}
+	return -1
+}
+
+// position returns the 1-based queue position of a command. The caller
+// holds s.mu.
+func (s *slots) position(q *queued) int {
+	return s.index(q) + 1
+}
+
+// estimate returns how long the command at a queue position will likely
+// wait: one average run for each round of slots ahead of it. The caller
+// holds s.mu.
+func (s *slots) estimate(position int) time.Duration {
+	if position <= 0 {
+		return 0
+	}
+	return time.Duration((position-1)/s.max+1) * s.avgRun
+}
+
+// length returns the number of queued commands.
+func (s *slots) length() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
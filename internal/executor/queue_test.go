@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+func TestSlots(t *testing.T) {
+	s := newSlots(1, 2)
+	release, err := s.acquire(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	// Two commands queue behind the running one
+	positions := make(chan int, 10)
+	acquired := make(chan func(), 2)
+	for range 2 {
+		go func() {
+			r, err := s.acquire(context.Background(), func(position int, wait time.Duration) {
+				positions <- position
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			acquired <- r
+		}()
+		<-positions
+	}
+	if n := s.length(); n != 2 {
+		t.Fatalf("queue length = %d, want 2", n)
+	}
+
+	// A third is refused
+	_, err = s.acquire(context.Background(), nil)
+	if !errors.Is(err, &apperrors.Error{Type: apperrors.ErrorTypeRateLimited}) {
+		t.Fatalf("acquire() error = %v, want rate limited", err)
+	}
+
+	// Releasing hands the slot over and moves the other command up
+	release()
+	next := <-acquired
+	select {
+	case position := <-positions:
+		if position != 1 {
+			t.Errorf("moved to position %d, want 1", position)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no position update")
+	}
+	next()
+	(<-acquired)()
+	if s.running != 0 || s.length() != 0 {
+		t.Errorf("running = %d, queued = %d after releasing all", s.running, s.length())
+	}
+}
+
+func TestSlots_canceled(t *testing.T) {
+	s := newSlots(1, 0)
+	release, err := s.acquire(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := s.acquire(ctx, nil); !errors.Is(err, &apperrors.Error{Type: apperrors.ErrorTypeTimeout}) {
+		t.Fatalf("acquire() error = %v, want timeout", err)
+	}
+	if n := s.length(); n != 0 {
+		t.Errorf("queue length = %d, want the canceled command removed", n)
+	}
+}
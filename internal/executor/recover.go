@@ -0,0 +1,34 @@
+package executor
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// recovered turns a panic during an execution into an internal error,
+// logging its stack trace, so one bad request does not take the server
+// down.
+func (e *Executor) recovered(r any, req *types.CommandExecutionRequest) error {
+	var command string
+	if req != nil {
+		command = req.Command
+	}
+
+	atomic.AddInt64(&e.panics, 1)
+	metrics.Add("panics", 1)
+	e.logger.Error("recovered from panic while executing command",
+		"command", command,
+		"panic", fmt.Sprint(r),
+		"stack", string(debug.Stack()),
+	)
+	return apperrors.InternalError("internal error executing command " + command)
+}
+
+// Panics returns the number of panics recovered during executions.
+func (e *Executor) Panics() int64 {
+	return atomic.LoadInt64(&e.panics)
+}
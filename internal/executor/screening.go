@@ -0,0 +1,59 @@
+package executor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/policy"
+	"github.com/mjmorales/simple-mcp-runner/internal/security"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// ScreeningContextKey is the error context key naming the screening
+// heuristic that flagged a denied or held request.
+const ScreeningContextKey = "screening"
+
+// screen applies the screening heuristics to a request, logging flagged
+// requests apart from other denials. Under action block it returns the
+// denial; under action approve it returns the flag, so the caller holds
+// the request for approval.
+func (e *Executor) screen(ctx context.Context, name string, req *types.CommandExecutionRequest) (*policy.ScreeningFlag, error) {
+	flag := e.screening.Check(req.Command, req.Args)
+	if flag == nil {
+		return nil, nil
+	}
+	metrics.Add("screened", 1)
+
+	sc := security.FromContext(ctx)
+	e.logger.WithFields(map[string]any{
+		"heuristic": flag.Heuristic,
+		"detail":    flag.Detail,
+		"command":   req.Command,
+		"args":      req.Args,
+		"action":    e.screening.Action(),
+		"client":    sc.Client(),
+		"user":      sc.User(),
+	}).Warn("request flagged by screening")
+
+	if e.screening.Action() == config.ScreeningBlock {
+		return flag, withScreening(apperrors.PermissionError(e.screeningReason(flag), name), flag)
+	}
+	return flag, nil
+}
+
+// screeningReason explains why screening flagged a request.
+func (e *Executor) screeningReason(flag *policy.ScreeningFlag) string {
+	return e.msg.Sprintf("flagged by screening (%s): %s", flag.Heuristic, flag.Detail)
+}
+
+// withScreening marks an error as caused by a flagged request, so the
+// history records the heuristic.
+func withScreening(err error, flag *policy.ScreeningFlag) error {
+	var appErr *apperrors.Error
+	if flag == nil || !errors.As(err, &appErr) {
+		return err
+	}
+	return appErr.WithContext(ScreeningContextKey, flag.Heuristic)
+}
@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestExecutor_screening(t *testing.T) {
+	flagged := []string{"fetch https://pastebin.com/raw/abc123"}
+
+	t.Run("block", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Security.Screening = config.ScreeningConfig{Action: config.ScreeningBlock}
+		e := New(cfg, logger.Default())
+
+		_, err := e.Execute(context.Background(), &types.CommandExecutionRequest{Command: "echo", Args: flagged})
+		var appErr *apperrors.Error
+		if !errors.As(err, &appErr) || !strings.Contains(err.Error(), "flagged by screening (paste_site)") {
+			t.Fatalf("Execute() error = %v, want flagged by screening", err)
+		}
+		if heuristic, _ := appErr.GetContext(ScreeningContextKey); heuristic != config.HeuristicPasteSite {
+			t.Errorf("screening context = %v, want paste_site", heuristic)
+		}
+
+		cmd := &config.Command{Name: "fetch", Command: "echo", Args: flagged}
+		if _, err := e.ExecuteConfigCommand(context.Background(), cmd, ""); err == nil {
+			t.Error("expected the configured command to be blocked too")
+		}
+
+		if _, err := e.Execute(context.Background(), &types.CommandExecutionRequest{Command: "echo", Args: []string{"hello"}}); err != nil {
+			t.Errorf("Execute(echo hello) error = %v", err)
+		}
+	})
+
+	t.Run("approve", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Approvals.File = filepath.Join(t.TempDir(), "approvals.jsonl")
+		cfg.Security.Screening = config.ScreeningConfig{Action: config.ScreeningApprove}
+		keys := operatorKeys(t, cfg, "alice", "bob")
+		e := New(cfg, logger.Default())
+		req := &types.CommandExecutionRequest{Command: "echo", Args: flagged}
+
+		_, err := e.Execute(context.Background(), req)
+		var appErr *apperrors.Error
+		if !errors.As(err, &appErr) || !strings.Contains(err.Error(), "approval request") {
+			t.Fatalf("Execute() error = %v, want held for approval", err)
+		}
+		if heuristic, _ := appErr.GetContext(ScreeningContextKey); heuristic != config.HeuristicPasteSite {
+			t.Errorf("screening context = %v, want paste_site", heuristic)
+		}
+
+		requests, err := e.approvals.List()
+		if err != nil || len(requests) != 1 {
+			t.Fatalf("expected one approval request, got %d (%v)", len(requests), err)
+		}
+		for _, key := range keys {
+			if _, err := e.approvals.Approve(requests[0].ID, key, ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		req.ApprovalID = requests[0].ID
+		if _, err := e.Execute(context.Background(), req); err != nil {
+			t.Errorf("expected approved request to run, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// snapshotRefPrefix is the namespace of snapshot refs. Refs sort by the
+// time they were taken.
+const snapshotRefPrefix = "refs/mcp-runner/snapshots/"
+
+// wantsSnapshot reports whether a command's working directory is
+// snapshotted before it runs.
+func (e *Executor) wantsSnapshot(cmd *config.Command) bool {
+	if !cmd.Risky {
+		return false
+	}
+	if cmd.GitSnapshot != nil {
+		return *cmd.GitSnapshot
+	}
+	return e.config.GitSnapshot.Enabled
+}
+
+// gitSnapshot records the working tree of the repository containing dir,
+// including untracked but not ignored files, as a commit under
+// refs/mcp-runner/snapshots/ and returns the ref. The user's index,
+// working tree and branches are left untouched. It returns an empty ref
+// when dir is not in a git repository or git is not installed.
+func (e *Executor) gitSnapshot(ctx context.Context, dir, command string) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", nil
+	}
+
+	top, err := runGit(ctx, dir, nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		// Not a repository
+		return "", nil
+	}
+
+	// Stage everything into a scratch index so the real one is untouched.
+	// git refuses an empty index file, so only the name is reserved.
+	index, err := os.CreateTemp("", "mcp-runner-index-*")
+	if err != nil {
+		return "", apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to create snapshot index")
+	}
+	index.Close()
+	os.Remove(index.Name())
+	defer os.Remove(index.Name())
+	env := []string{"GIT_INDEX_FILE=" + index.Name()}
+
+	head, err := runGit(ctx, top, nil, "rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		// Repository without commits
+		head = ""
+	}
+
+	if head != "" {
+		if _, err := runGit(ctx, top, env, "read-tree", head); err != nil {
+			return "", snapshotError(err)
+		}
+	}
+	if _, err := runGit(ctx, top, env, "add", "--all", "."); err != nil {
+		return "", snapshotError(err)
+	}
+	tree, err := runGit(ctx, top, env, "write-tree")
+	if err != nil {
+		return "", snapshotError(err)
+	}
+
+	args := []string{"commit-tree", tree, "-m", "mcp-runner snapshot before " + command}
+	if head != "" {
+		args = append(args, "-p", head)
+	}
+	commit, err := runGit(ctx, top, snapshotIdentity, args...)
+	if err != nil {
+		return "", snapshotError(err)
+	}
+
+	ref := snapshotRefPrefix + time.Now().UTC().Format("20060102T150405.000000000Z")
+	if _, err := runGit(ctx, top, nil, "update-ref", ref, commit); err != nil {
+		return "", snapshotError(err)
+	}
+
+	e.logger.Info("created git snapshot", "ref", ref, "repository", top, "command", command)
+
+	e.pruneSnapshots(ctx, top)
+
+	return ref, nil
+}
+
+// snapshotIdentity lets commit-tree work in repositories without a
+// configured user.
+var snapshotIdentity = []string{
+	"GIT_AUTHOR_NAME=simple-mcp-runner",
+	"GIT_AUTHOR_EMAIL=simple-mcp-runner@localhost",
+	"GIT_COMMITTER_NAME=simple-mcp-runner",
+	"GIT_COMMITTER_EMAIL=simple-mcp-runner@localhost",
+}
+
+// pruneSnapshots deletes the oldest snapshot refs beyond the configured
+// number.
+func (e *Executor) pruneSnapshots(ctx context.Context, top string) {
+	keep := e.config.GitSnapshot.Keep
+	if keep <= 0 {
+		return
+	}
+
+	out, err := runGit(ctx, top, nil, "for-each-ref", "--sort=-refname", "--format=%(refname)", snapshotRefPrefix)
+	if err != nil {
+		e.logger.WithError(err).Warn("failed to list git snapshots", "repository", top)
+		return
+	}
+
+	refs := strings.Fields(out)
+	for i := keep; i < len(refs); i++ {
+		if _, err := runGit(ctx, top, nil, "update-ref", "-d", refs[i]); err != nil {
+			e.logger.WithError(err).Warn("failed to delete git snapshot", "ref", refs[i])
+		}
+	}
+}
+
+// runGit runs a git command in dir and returns its trimmed stdout.
+func runGit(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", apperrors.Wrap(err, apperrors.ErrorTypeExecution, "git "+args[0]+": "+msg)
+		}
+		return "", apperrors.Wrap(err, apperrors.ErrorTypeExecution, "git "+args[0]+" failed")
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// snapshotError wraps a failure to snapshot a repository.
+func snapshotError(err error) error {
+	return apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to create git snapshot")
+}
@@ -0,0 +1,172 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/approval"
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	if _, err := runGit(context.Background(), dir, nil, "init", "-q"); err != nil {
+		t.Fatalf("git init failed: %v", err)
+	}
+	writeTestFile(t, filepath.Join(dir, "tracked.txt"), "v1")
+	writeTestFile(t, filepath.Join(dir, ".gitignore"), "ignored.txt\n")
+	if _, err := runGit(context.Background(), dir, nil, "add", "."); err != nil {
+		t.Fatalf("git add failed: %v", err)
+	}
+	if _, err := runGit(context.Background(), dir, snapshotIdentity, "commit", "-q", "-m", "initial"); err != nil {
+		t.Fatalf("git commit failed: %v", err)
+	}
+	return dir
+}
+
+func TestExecutor_gitSnapshot(t *testing.T) {
+	dir := initRepo(t)
+	ctx := context.Background()
+
+	writeTestFile(t, filepath.Join(dir, "tracked.txt"), "v2")
+	writeTestFile(t, filepath.Join(dir, "untracked.txt"), "new")
+	writeTestFile(t, filepath.Join(dir, "ignored.txt"), "secret")
+
+	cfg := config.Default()
+	cfg.GitSnapshot.Keep = 2
+	e := New(cfg, logger.Default())
+
+	ref, err := e.gitSnapshot(ctx, dir, "deploy")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(ref, snapshotRefPrefix) {
+		t.Fatalf("unexpected ref %q", ref)
+	}
+
+	// The snapshot holds the working tree, not just HEAD
+	if out, err := runGit(ctx, dir, nil, "show", ref+":tracked.txt"); err != nil || out != "v2" {
+		t.Errorf("unexpected tracked.txt in snapshot: %q (%v)", out, err)
+	}
+	if _, err := runGit(ctx, dir, nil, "show", ref+":untracked.txt"); err != nil {
+		t.Errorf("expected untracked file in snapshot: %v", err)
+	}
+	if _, err := runGit(ctx, dir, nil, "show", ref+":ignored.txt"); err == nil {
+		t.Error("expected ignored file to be left out of snapshot")
+	}
+
+	// The index and branch are untouched
+	if status, _ := runGit(ctx, dir, nil, "status", "--porcelain"); !strings.Contains(status, "?? untracked.txt") {
+		t.Errorf("expected untracked.txt to stay untracked, got status %q", status)
+	}
+
+	// Old snapshots are pruned
+	for i := 0; i < 2; i++ {
+		if _, err := e.gitSnapshot(ctx, dir, "deploy"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	refs, _ := runGit(ctx, dir, nil, "for-each-ref", "--format=%(refname)", snapshotRefPrefix)
+	if n := len(strings.Fields(refs)); n != 2 {
+		t.Errorf("expected 2 snapshots after pruning, got %d", n)
+	}
+
+	// Directories outside a repository are skipped
+	ref, err = e.gitSnapshot(ctx, t.TempDir(), "deploy")
+	if err != nil || ref != "" {
+		t.Errorf("expected no snapshot outside a repository, got %q (%v)", ref, err)
+	}
+}
+
+func TestExecutor_ExecuteConfigCommandRisky(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses touch")
+	}
+	dir := initRepo(t)
+
+	e := New(config.Default(), logger.Default())
+	cmd := &config.Command{Name: "touch", Command: "touch", Args: []string{"out.txt"}, Risky: true}
+
+	result, err := e.ExecuteConfigCommand(context.Background(), cmd, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.SnapshotRef == "" {
+		t.Error("expected a snapshot ref for a risky command")
+	}
+
+	// The command can opt out
+	disabled := false
+	cmd.GitSnapshot = &disabled
+	result, err = e.ExecuteConfigCommand(context.Background(), cmd, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.SnapshotRef != "" {
+		t.Errorf("expected no snapshot when disabled, got %q", result.SnapshotRef)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "out.txt")); err != nil {
+		t.Errorf("expected command to run: %v", err)
+	}
+}
+
+func TestExecutor_ExecuteConfigCommandDeniedFirst(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses touch")
+	}
+	dir := initRepo(t)
+	ctx := context.Background()
+
+	cfg := config.Default()
+	cfg.Approvals.File = filepath.Join(t.TempDir(), "approvals.jsonl")
+	cfg.Security.StateFile = filepath.Join(t.TempDir(), "policy-state.json")
+	cfg.Security.Conditions = []config.PolicyCondition{
+		{Name: "once", Commands: []string{"touch"}, MaxRuns: 1, Period: config.Duration(time.Hour)},
+	}
+	keys := operatorKeys(t, cfg, "alice", "bob")
+	e := New(cfg, logger.Default())
+	cmd := &config.Command{Name: "touch", Command: "touch", Args: []string{"out.txt"}, Risky: true, RequiresSecondApproval: true}
+
+	if _, err := e.ExecuteConfigCommand(ctx, cmd, dir); err == nil {
+		t.Fatal("expected the run to be held for approval")
+	}
+	requests, err := e.approvals.List()
+	if err != nil || len(requests) != 1 {
+		t.Fatalf("expected one approval request, got %d (%v)", len(requests), err)
+	}
+	id := requests[0].ID
+	for _, key := range keys {
+		if _, err := e.approvals.Approve(id, key, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Use up the run the condition allows, so the approved run is denied
+	if denial := e.conditions.Admit("touch", []string{"out.txt"}); denial != nil {
+		t.Fatalf("unexpected denial %+v", denial)
+	}
+	if _, err := e.ExecuteConfigCommandWithOptions(ctx, cmd, dir, ConfigCommandOptions{ApprovalID: id}); err == nil {
+		t.Fatal("expected the run to be denied by the condition")
+	}
+
+	// Nothing was done for the denied run
+	if refs, _ := runGit(ctx, dir, nil, "for-each-ref", "--format=%(refname)", snapshotRefPrefix); strings.TrimSpace(refs) != "" {
+		t.Errorf("expected no snapshot refs, got %q", refs)
+	}
+	if req, err := e.approvals.Get(id); err != nil || req.Status != approval.StatusApproved {
+		t.Errorf("expected the approval to be kept, got %+v, %v", req, err)
+	}
+}
@@ -0,0 +1,146 @@
+package executor
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// spillWriter streams the output of a command beyond the spill threshold
+// to a file, so large outputs do not have to be held in memory. The first
+// threshold bytes are kept in the head buffer, which is copied to the file
+// when it is created, so the file holds the whole output.
+type spillWriter struct {
+	head   *limitedBuffer
+	dir    string
+	stream string
+	limit  int64 // Max output size, zero for no limit
+	size   int64
+	file   *os.File
+	err    error
+	logger *logger.Logger
+}
+
+// SpillDir returns the directory holding spilled output files.
+func (e *Executor) SpillDir() string {
+	if e.config.Execution.SpillDir != "" {
+		return e.config.Execution.SpillDir
+	}
+	return filepath.Join(os.TempDir(), "simple-mcp-runner", "spill")
+}
+
+// outputLimit returns the output size limit of a request: the smaller of
+// max_output_size and the limit of its configured command, zero for none.
+func (e *Executor) outputLimit(req *types.CommandExecutionRequest) int64 {
+	limit := int64(e.config.Execution.MaxOutputSize)
+	if req.MaxOutputSize > 0 && (limit <= 0 || req.MaxOutputSize < limit) {
+		limit = req.MaxOutputSize
+	}
+	return limit
+}
+
+// newOutput returns the buffer a command's stream of at most limit bytes
+// is collected in, and the spill writer in front of it when
+// spill_threshold is set.
+func (e *Executor) newOutput(stream string, limit int64) (*limitedBuffer, *spillWriter) {
+	threshold := int64(e.config.Execution.SpillThreshold)
+	if threshold <= 0 || (limit > 0 && threshold >= limit) {
+		return &limitedBuffer{limit: limit}, nil
+	}
+
+	head := &limitedBuffer{limit: threshold}
+	return head, &spillWriter{
+		head:   head,
+		dir:    e.SpillDir(),
+		stream: stream,
+		limit:  limit,
+		logger: e.logger,
+	}
+}
+
+// outputWriter returns the writer a command's stream should be sent to.
+func outputWriter(head *limitedBuffer, spill *spillWriter) io.Writer {
+	if spill == nil {
+		return head
+	}
+	return spill
+}
+
+// Write never fails, so the command is not cut off when spilling does:
+// the output beyond the head is then discarded, and the failure is logged
+// by finish.
+func (s *spillWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if s.limit > 0 && s.size+int64(len(p)) > s.limit {
+		p = p[:max(s.limit-s.size, 0)]
+	}
+	if len(p) == 0 {
+		return n, nil
+	}
+
+	if s.file == nil && s.err == nil && s.size+int64(len(p)) > s.head.limit {
+		s.create()
+	}
+	if s.size < s.head.limit {
+		_, _ = s.head.Write(p)
+	}
+	if s.file != nil {
+		if _, err := s.file.Write(p); err != nil {
+			s.fail(err)
+		}
+	}
+	s.size += int64(len(p))
+	return n, nil
+}
+
+// create opens the spill file and writes the head to it.
+func (s *spillWriter) create() {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		s.err = err
+		return
+	}
+	f, err := os.CreateTemp(s.dir, s.stream+"-*.log")
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.file = f
+	if err := s.head.writeTo(f); err != nil {
+		s.fail(err)
+	}
+}
+
+// fail stops spilling and removes the incomplete file.
+func (s *spillWriter) fail(err error) {
+	s.err = err
+	s.file.Close()
+	os.Remove(s.file.Name())
+	s.file = nil
+}
+
+// finish closes the spill file and returns its path, or "" when the
+// output fit in memory or could not be spilled.
+func (s *spillWriter) finish() string {
+	if s == nil {
+		return ""
+	}
+	if s.err != nil {
+		s.logger.Warn("failed to spill command output to disk, output truncated",
+			"stream", s.stream,
+			"error", s.err,
+		)
+		return ""
+	}
+	if s.file == nil {
+		return ""
+	}
+	path := s.file.Name()
+	if err := s.file.Close(); err != nil {
+		s.logger.Warn("failed to close spilled output", "stream", s.stream, "error", err)
+	}
+	s.file = nil
+	return path
+}
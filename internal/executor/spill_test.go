@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func newSpillExecutor(t *testing.T, threshold, limit int64) *Executor {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Execution.SpillThreshold = config.ByteSize(threshold)
+	cfg.Execution.MaxOutputSize = config.ByteSize(limit)
+	cfg.Execution.SpillDir = t.TempDir()
+	log, _ := logger.New(logger.DefaultOptions())
+	return New(cfg, log)
+}
+
+func TestSpillWriter(t *testing.T) {
+	exec := newSpillExecutor(t, 10, 100)
+	head, spill := exec.newOutput("stdout", 100)
+	if spill == nil {
+		t.Fatal("expected a spill writer")
+	}
+	defer head.release()
+
+	output := strings.Repeat("0123456789", 15)
+	for _, chunk := range []string{output[:4], output[4:25], output[25:]} {
+		n, err := spill.Write([]byte(chunk))
+		if err != nil || n != len(chunk) {
+			t.Fatalf("Write() = %d, %v; want %d, nil", n, err, len(chunk))
+		}
+	}
+
+	if got := head.String(); got != output[:10] {
+		t.Errorf("head = %q, want %q", got, output[:10])
+	}
+	path := spill.finish()
+	if path == "" {
+		t.Fatal("expected a spill file")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read spill file: %v", err)
+	}
+	if string(data) != output[:100] {
+		t.Errorf("spill file = %q, want the first 100 bytes of output", data)
+	}
+}
+
+func TestSpillWriter_belowThreshold(t *testing.T) {
+	exec := newSpillExecutor(t, 10, 100)
+	head, spill := exec.newOutput("stderr", 100)
+	defer head.release()
+
+	if _, err := spill.Write([]byte("short")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if path := spill.finish(); path != "" {
+		t.Errorf("expected no spill file, got %s", path)
+	}
+	if got := head.String(); got != "short" {
+		t.Errorf("head = %q, want %q", got, "short")
+	}
+}
+
+func TestNewOutput_disabled(t *testing.T) {
+	for _, threshold := range []int64{0, 100, 200} {
+		head, spill := newSpillExecutor(t, threshold, 100).newOutput("stdout", 100)
+		if spill != nil {
+			t.Errorf("threshold %d: expected no spill writer when it does not apply", threshold)
+		}
+		if head.limit != 100 {
+			t.Errorf("threshold %d: head limit = %d, want the max output size", threshold, head.limit)
+		}
+	}
+}
+
+func TestExecutor_outputLimit(t *testing.T) {
+	exec := newSpillExecutor(t, 0, 100)
+	for _, tt := range []struct {
+		command, want int64
+	}{{0, 100}, {50, 50}, {500, 100}} {
+		if got := exec.outputLimit(&types.CommandExecutionRequest{MaxOutputSize: tt.command}); got != tt.want {
+			t.Errorf("outputLimit(%d) = %d, want %d", tt.command, got, tt.want)
+		}
+	}
+
+	exec.config.Execution.MaxOutputSize = 0
+	if got := exec.outputLimit(&types.CommandExecutionRequest{MaxOutputSize: 50}); got != 50 {
+		t.Errorf("outputLimit() = %d, want the command limit without a global one", got)
+	}
+}
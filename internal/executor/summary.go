@@ -0,0 +1,164 @@
+package executor
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// Patterns of error and warning lines used when none are configured.
+var (
+	defaultErrorPatterns = []string{`(?i)\b(error|errors|fatal|panic|exception|fail|failed|failure)\b`}
+	defaultWarnPatterns  = []string{`(?i)\b(warn|warning|warnings|deprecated)\b`}
+)
+
+// maxDigestLine is the length lines are cut to in digests.
+const maxDigestLine = 1024
+
+// digest follows an output stream line by line and keeps what a summary
+// of it needs, however large it is: counts, the first error and warning
+// lines, and the last lines.
+type digest struct {
+	errors, warns []*regexp.Regexp
+	tailLines     int
+	maxMatches    int
+
+	partial []byte // Start of the current line, cut to maxDigestLine
+	cut     bool   // The current line was cut
+	summary types.StreamSummary
+	tail    []types.OutputLine // Ring of the last lines
+	next    int                // Index of the oldest line in tail, once full
+}
+
+// newDigests returns the digests of the stdout and stderr of a request,
+// or nil ones when summaries are disabled.
+func (e *Executor) newDigests(req *types.CommandExecutionRequest) (*digest, *digest) {
+	cfg := e.config.Execution.Summary
+	if cfg.Threshold <= 0 {
+		return nil, nil
+	}
+	errorPatterns, warnPatterns := cfg.ErrorPatterns, cfg.WarnPatterns
+	if len(errorPatterns) == 0 {
+		errorPatterns = defaultErrorPatterns
+	}
+	if len(warnPatterns) == 0 {
+		warnPatterns = defaultWarnPatterns
+	}
+	if len(req.SummaryErrorPatterns) > 0 {
+		errorPatterns = req.SummaryErrorPatterns
+	}
+	if len(req.SummaryWarnPatterns) > 0 {
+		warnPatterns = req.SummaryWarnPatterns
+	}
+
+	// Patterns are validated with the configuration
+	errors, warns := compilePatterns(errorPatterns), compilePatterns(warnPatterns)
+	newDigest := func() *digest {
+		return &digest{errors: errors, warns: warns, tailLines: cfg.TailLines, maxMatches: cfg.MaxMatches}
+	}
+	return newDigest(), newDigest()
+}
+
+// compilePatterns compiles regular expressions, skipping invalid ones.
+func compilePatterns(patterns []string) []*regexp.Regexp {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			res = append(res, re)
+		}
+	}
+	return res
+}
+
+// Write digests the complete lines of p and keeps the start of the last
+// one. Like the spill writer, it never fails.
+func (d *digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.summary.Bytes += int64(n)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		chunk := p
+		if i >= 0 {
+			chunk = p[:i]
+		}
+		if room := maxDigestLine - len(d.partial); len(chunk) > room {
+			chunk = chunk[:max(room, 0)]
+			d.cut = true
+		}
+		d.partial = append(d.partial, chunk...)
+		if i < 0 {
+			break
+		}
+		d.line()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// line digests the current line.
+func (d *digest) line() {
+	text := strings.ToValidUTF8(strings.TrimSuffix(string(d.partial), "\r"), "�")
+	line := types.OutputLine{Number: d.summary.Lines, Text: text, Truncated: d.cut}
+	d.partial, d.cut = d.partial[:0], false
+	d.summary.Lines++
+
+	switch {
+	case matchesAny(d.errors, text):
+		d.summary.Errors++
+		if len(d.summary.ErrorLines) < d.maxMatches {
+			d.summary.ErrorLines = append(d.summary.ErrorLines, line)
+		}
+	case matchesAny(d.warns, text):
+		d.summary.Warnings++
+		if len(d.summary.WarningLines) < d.maxMatches {
+			d.summary.WarningLines = append(d.summary.WarningLines, line)
+		}
+	}
+
+	if d.tailLines <= 0 {
+		return
+	}
+	if len(d.tail) < d.tailLines {
+		d.tail = append(d.tail, line)
+		return
+	}
+	d.tail[d.next] = line
+	d.next = (d.next + 1) % d.tailLines
+}
+
+// matchesAny reports whether text matches one of the patterns.
+func matchesAny(patterns []*regexp.Regexp, text string) bool {
+	for _, re := range patterns {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// finish digests the last line, if it has no line ending, and returns the
+// summary of the stream when it exceeded threshold bytes, or nil.
+func (d *digest) finish(threshold int64) *types.StreamSummary {
+	if d == nil || d.summary.Bytes <= threshold {
+		return nil
+	}
+	if len(d.partial) > 0 || d.cut {
+		d.line()
+	}
+	summary := d.summary
+	summary.Tail = append(append([]types.OutputLine(nil), d.tail[d.next:]...), d.tail[:d.next]...)
+	return &summary
+}
+
+// summarize returns the summary of the streams of a command, or nil when
+// neither exceeded the summary threshold.
+func (e *Executor) summarize(stdout, stderr *digest) *types.OutputSummary {
+	threshold := int64(e.config.Execution.Summary.Threshold)
+	summary := &types.OutputSummary{Stdout: stdout.finish(threshold), Stderr: stderr.finish(threshold)}
+	if summary.Stdout == nil && summary.Stderr == nil {
+		return nil
+	}
+	return summary
+}
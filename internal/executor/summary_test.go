@@ -0,0 +1,102 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func newSummaryExecutor(threshold int64) *Executor {
+	cfg := config.Default()
+	cfg.Execution.Summary.Threshold = config.ByteSize(threshold)
+	cfg.Execution.Summary.TailLines = 3
+	cfg.Execution.Summary.MaxMatches = 2
+	log, _ := logger.New(logger.DefaultOptions())
+	return New(cfg, log)
+}
+
+func TestDigest(t *testing.T) {
+	exec := newSummaryExecutor(10)
+	stdout, _ := exec.newDigests(&types.CommandExecutionRequest{})
+
+	var b strings.Builder
+	for i := 0; i < 10; i++ {
+		switch i {
+		case 2, 5, 7:
+			fmt.Fprintf(&b, "ERROR: step %d failed\n", i)
+		case 3:
+			fmt.Fprintf(&b, "warning: step %d is deprecated\r\n", i)
+		default:
+			fmt.Fprintf(&b, "step %d ok\n", i)
+		}
+	}
+	b.WriteString(strings.Repeat("x", 2*maxDigestLine))
+	output := b.String()
+
+	// Lines are split across writes
+	for len(output) > 0 {
+		n := min(7, len(output))
+		stdout.Write([]byte(output[:n]))
+		output = output[n:]
+	}
+
+	s := stdout.finish(10)
+	if s == nil {
+		t.Fatal("expected a summary")
+	}
+	if s.Bytes != int64(b.Len()) || s.Lines != 11 || s.Errors != 3 || s.Warnings != 1 {
+		t.Errorf("summary = %+v", s)
+	}
+	if len(s.ErrorLines) != 2 || s.ErrorLines[1].Number != 5 || s.ErrorLines[1].Text != "ERROR: step 5 failed" {
+		t.Errorf("error lines = %+v, want the first two", s.ErrorLines)
+	}
+	if len(s.WarningLines) != 1 || s.WarningLines[0].Text != "warning: step 3 is deprecated" {
+		t.Errorf("warning lines = %+v", s.WarningLines)
+	}
+	if len(s.Tail) != 3 || s.Tail[0].Number != 8 || s.Tail[2].Number != 10 || !s.Tail[2].Truncated || len(s.Tail[2].Text) != maxDigestLine {
+		t.Errorf("tail = %+v, want lines 8 to 10 with the last cut", s.Tail)
+	}
+
+	if s := exec.summarize(stdout, nil); s == nil || s.Stderr != nil {
+		t.Errorf("summarize() = %+v", s)
+	}
+	small, _ := exec.newDigests(&types.CommandExecutionRequest{})
+	small.Write([]byte("ok\n"))
+	if s := exec.summarize(small, nil); s != nil {
+		t.Errorf("summarize() below the threshold = %+v, want nil", s)
+	}
+}
+
+func TestExecutor_summary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("seq is not available on Windows")
+	}
+	exec := newSummaryExecutor(100)
+	result, err := exec.Execute(context.Background(), &types.CommandExecutionRequest{
+		Command:              "seq",
+		Args:                 []string{"1", "100"},
+		SummaryErrorPatterns: []string{`^4\d$`},
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Summary == nil || result.Summary.Stdout == nil || result.Summary.Stderr != nil {
+		t.Fatalf("summary = %+v, want one of stdout", result.Summary)
+	}
+	s := result.Summary.Stdout
+	if s.Lines != 100 || s.Errors != 10 || len(s.ErrorLines) != 2 || s.ErrorLines[0].Text != "40" || s.Tail[2].Text != "100" {
+		t.Errorf("stdout summary = %+v", s)
+	}
+
+	exec.config.Execution.Summary.Threshold = 0
+	result, err = exec.Execute(context.Background(), &types.CommandExecutionRequest{Command: "seq", Args: []string{"1", "100"}})
+	if err != nil || result.Summary != nil {
+		t.Errorf("summary with summaries disabled = %+v, %v", result.Summary, err)
+	}
+}
@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"strconv"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// Operator switches, flipped at runtime through the control API.
+const (
+	SwitchPaused        = "paused"         // Refuse new executions
+	SwitchBlockMutating = "block_mutating" // Refuse configured commands marked mutating or risky
+)
+
+// Switches are the names of the operator switches.
+var Switches = []string{SwitchPaused, SwitchBlockMutating}
+
+// errKilled cancels commands killed by an operator.
+var errKilled = errors.New("killed by an operator")
+
+// SwitchStates returns whether each operator switch is on.
+func (e *Executor) SwitchStates() map[string]bool {
+	return map[string]bool{
+		SwitchPaused:        e.paused.Load(),
+		SwitchBlockMutating: e.blockMutating.Load(),
+	}
+}
+
+// SetSwitch turns an operator switch on or off.
+func (e *Executor) SetSwitch(name string, on bool) error {
+	switch name {
+	case SwitchPaused:
+		e.paused.Store(on)
+	case SwitchBlockMutating:
+		e.blockMutating.Store(on)
+	default:
+		return apperrors.NotFoundError("unknown switch: "+name, name)
+	}
+	e.logger.Info("operator switch changed", "switch", name, "on", on)
+	return nil
+}
+
+// Kill cancels a running command, which is interrupted and then killed
+// after execution.kill_timeout like a command that timed out.
+func (e *Executor) Kill(id uint64) error {
+	v, ok := e.active.Load(id)
+	if !ok {
+		return apperrors.NotFoundError("no running command #"+strconv.FormatUint(id, 10), strconv.FormatUint(id, 10))
+	}
+	cmd := v.(ActiveCommand)
+	cmd.kill(errKilled)
+	e.logger.Info("command killed by operator", "id", id, "command", cmd.Command)
+	return nil
+}
+
+// Counters returns the execution counters published at /debug/vars.
+func Counters() map[string]int64 {
+	counters := make(map[string]int64)
+	metrics.Do(func(kv expvar.KeyValue) {
+		if v, ok := kv.Value.(*expvar.Int); ok {
+			counters[kv.Key] = v.Value()
+		}
+	})
+	return counters
+}
+
+// checkSwitches refuses executions the operator switches stop.
+func (e *Executor) checkSwitches(command string, mutating bool) error {
+	if e.paused.Load() {
+		return apperrors.PermissionError(e.msg.T("executions are paused by an operator"), command)
+	}
+	if mutating && e.blockMutating.Load() {
+		return apperrors.PermissionError(e.msg.T("mutating commands are blocked by an operator"), command)
+	}
+	return nil
+}
+
+// killed reports whether an operator killed the command run with ctx.
+func killed(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errKilled)
+}
@@ -0,0 +1,65 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestExecutor_switches(t *testing.T) {
+	e := New(config.Default(), logger.Default())
+	ctx := context.Background()
+	denied := func(err error) bool {
+		return errors.Is(err, &apperrors.Error{Type: apperrors.ErrorTypePermission})
+	}
+
+	if err := e.SetSwitch("nope", true); !errors.Is(err, &apperrors.Error{Type: apperrors.ErrorTypeNotFound}) {
+		t.Errorf("SetSwitch(nope) error = %v, want not found", err)
+	}
+
+	// Pausing refuses every execution
+	if err := e.SetSwitch(SwitchPaused, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Execute(ctx, &types.CommandExecutionRequest{Command: "go", Args: []string{"version"}}); !denied(err) {
+		t.Errorf("Execute() while paused error = %v, want denied", err)
+	}
+	if err := e.AdmitInput("repl"); !denied(err) {
+		t.Errorf("AdmitInput() while paused error = %v, want denied", err)
+	}
+	if err := e.SetSwitch(SwitchPaused, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Blocking mutating commands refuses configured commands marked so
+	if err := e.SetSwitch(SwitchBlockMutating, true); err != nil {
+		t.Fatal(err)
+	}
+	if !e.SwitchStates()[SwitchBlockMutating] {
+		t.Errorf("SwitchStates() = %v", e.SwitchStates())
+	}
+	risky := &config.Command{Name: "deploy", Command: "go", Args: []string{"version"}, Risky: true}
+	if _, err := e.ExecuteConfigCommand(ctx, risky, ""); !denied(err) {
+		t.Errorf("risky command error = %v, want denied", err)
+	}
+	safe := &config.Command{Name: "version", Command: "go", Args: []string{"version"}}
+	if _, err := e.ExecuteConfigCommand(ctx, safe, ""); err != nil {
+		t.Errorf("read-only command error = %v", err)
+	}
+
+	// Input to interactive programs may do anything, so it counts as mutating
+	if err := e.AdmitInput("repl"); !denied(err) {
+		t.Errorf("AdmitInput() with mutating commands blocked error = %v, want denied", err)
+	}
+	if err := e.SetSwitch(SwitchBlockMutating, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.AdmitInput("repl"); err != nil {
+		t.Errorf("AdmitInput() error = %v", err)
+	}
+}
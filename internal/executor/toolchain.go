@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// pinToolchains puts the bin directories of pinned toolchains first in
+// the PATH of env, in the order given, and resolves a command named
+// without a directory in them, since commands are looked up in the
+// server's PATH rather than the one they get. It returns the command to
+// run, the environment and the pins for the result.
+func pinToolchains(pins []config.Toolchain, command string, env []string) (string, []string, []types.Toolchain) {
+	var dirs []string
+	recorded := make([]types.Toolchain, 0, len(pins))
+	for _, pin := range pins {
+		if !slices.Contains(dirs, pin.Bin) {
+			dirs = append(dirs, pin.Bin)
+		}
+		recorded = append(recorded, types.Toolchain{Name: pin.Name, Bin: pin.Bin})
+	}
+
+	// The last PATH entry is the one commands get
+	env = slices.Clone(env)
+	prefix := strings.Join(dirs, string(os.PathListSeparator))
+	i := len(env) - 1
+	for i >= 0 && !isPathVar(env[i]) {
+		i--
+	}
+	if i < 0 {
+		env = append(env, "PATH="+prefix)
+	} else if key, value, _ := strings.Cut(env[i], "="); value == "" {
+		env[i] = key + "=" + prefix
+	} else {
+		env[i] = key + "=" + prefix + string(os.PathListSeparator) + value
+	}
+
+	if !strings.ContainsAny(command, `/\`) {
+		for _, dir := range dirs {
+			if path, err := exec.LookPath(filepath.Join(dir, command)); err == nil {
+				return path, env, recorded
+			}
+		}
+	}
+	return command, env, recorded
+}
+
+// isPathVar reports whether an environment entry sets PATH, whose name is
+// case-insensitive on Windows.
+func isPathVar(kv string) bool {
+	name, _, _ := strings.Cut(kv, "=")
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(name, "PATH")
+	}
+	return name == "PATH"
+}
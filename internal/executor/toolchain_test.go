@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestPinToolchains(t *testing.T) {
+	sep := string(os.PathListSeparator)
+	goBin := filepath.Join(t.TempDir(), "go1.21", "bin")
+	nodeBin := filepath.Join(t.TempDir(), "node20", "bin")
+	pins := []config.Toolchain{
+		{Path: "/work/projA", Name: "go1.21", Bin: goBin},
+		{Path: "/work", Name: "node20", Bin: nodeBin},
+	}
+
+	tests := []struct {
+		env  []string
+		want string
+	}{
+		{[]string{"HOME=/home/u", "PATH=/usr/bin"}, "PATH=" + goBin + sep + nodeBin + sep + "/usr/bin"},
+		{[]string{"HOME=/home/u"}, "PATH=" + goBin + sep + nodeBin},
+		// A PATH set by the request is the one commands get
+		{[]string{"PATH=/usr/bin", "PATH=/opt/bin"}, "PATH=" + goBin + sep + nodeBin + sep + "/opt/bin"},
+	}
+	for _, tt := range tests {
+		_, env, recorded := pinToolchains(pins, "go", tt.env)
+		if !slices.Contains(env, tt.want) {
+			t.Errorf("pinToolchains(%q) env = %q, want %s", tt.env, env, tt.want)
+		}
+		want := []types.Toolchain{{Name: "go1.21", Bin: goBin}, {Name: "node20", Bin: nodeBin}}
+		if !slices.Equal(recorded, want) {
+			t.Errorf("pinToolchains() recorded %+v, want %+v", recorded, want)
+		}
+	}
+}
+
+func TestConfig_PinnedToolchains(t *testing.T) {
+	root := t.TempDir()
+	cfg := config.Default()
+	cfg.Execution.Toolchains = []config.Toolchain{
+		{Path: root, Name: "go1.22", Bin: "/opt/go1.22/bin"},
+		{Path: filepath.Join(root, "projA"), Name: "go1.21", Bin: "/opt/go1.21/bin"},
+		{Path: filepath.Join(root, "projB"), Name: "go1.20", Bin: "/opt/go1.20/bin"},
+	}
+
+	tests := map[string][]string{
+		"projA/cmd": {"go1.21", "go1.22"},
+		"projB":     {"go1.20", "go1.22"},
+		"projAB":    {"go1.22"},
+		"":          {"go1.22"},
+	}
+	for dir, want := range tests {
+		var names []string
+		for _, tc := range cfg.PinnedToolchains(filepath.Join(root, dir)) {
+			names = append(names, tc.Name)
+		}
+		if !slices.Equal(names, want) {
+			t.Errorf("PinnedToolchains(%s) = %v, want %v", dir, names, want)
+		}
+	}
+	if pins := cfg.PinnedToolchains(t.TempDir()); len(pins) != 0 {
+		t.Errorf("PinnedToolchains() = %+v outside the pinned paths", pins)
+	}
+}
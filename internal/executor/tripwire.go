@@ -0,0 +1,47 @@
+package executor
+
+import (
+	"context"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/security"
+	"github.com/mjmorales/simple-mcp-runner/internal/tripwire"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// TripwireContextKey is the error context key naming the tripwire a
+// denied request tripped.
+const TripwireContextKey = "tripwire"
+
+// checkTripwires denies requests for tripwire commands and raises their
+// alert. The denial reads like that of any command that is not allowed,
+// so whoever sent the request does not learn it was noticed; for the same
+// reason tripwires are left out of explain_policy and server events.
+func (e *Executor) checkTripwires(ctx context.Context, req *types.CommandExecutionRequest) error {
+	name := e.tripwires.Match(req.Command, req.Args)
+	if name == "" {
+		return nil
+	}
+	metrics.Add("tripwires", 1)
+
+	sc := security.FromContext(ctx)
+	alert := types.TripwireAlert{
+		Severity: tripwire.SeverityHigh,
+		Tripwire: name,
+		Command:  req.Command,
+		Args:     req.Args,
+		WorkDir:  req.WorkDir,
+		Client:   sc.Client(),
+		User:     sc.User(),
+		Time:     time.Now(),
+	}
+	if sc != nil {
+		alert.Session = sc.SessionID
+	}
+	alert.Text = tripwire.Text(alert)
+	e.tripwires.Alert(alert)
+
+	return apperrors.PermissionError(e.msg.Sprintf("command not allowed: %s", req.Command), req.Command).
+		WithContext(TripwireContextKey, name)
+}
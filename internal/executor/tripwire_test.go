@@ -0,0 +1,41 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/internal/security"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestExecutor_tripwires(t *testing.T) {
+	cfg := config.Default()
+	cfg.Security.AllowedCommands = []string{"go"}
+	cfg.Security.Tripwires = []config.Tripwire{{Name: "go_env", Commands: []string{"go"}, ArgsPattern: `^env\b`}}
+	e := New(cfg, logger.Default())
+	ctx := security.WithContext(context.Background(), &security.Context{SessionID: "s1", ClientName: "editor"})
+
+	// Allowed commands are still denied when they trip a tripwire, with
+	// the denial of a command that is not allowed
+	result, err := e.Execute(ctx, &types.CommandExecutionRequest{Command: "go", Args: []string{"env", "GOPATH"}})
+	var appErr *apperrors.Error
+	if result != nil || !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypePermission {
+		t.Fatalf("Execute() = %v, %v, want denied", result, err)
+	}
+	if !strings.Contains(err.Error(), "command not allowed: go") || strings.Contains(err.Error(), "tripwire") {
+		t.Errorf("Execute() error = %q, want the denial of a command that is not allowed", err)
+	}
+	if name, _ := appErr.GetContext(TripwireContextKey); name != "go_env" {
+		t.Errorf("tripwire context = %v, want go_env", name)
+	}
+
+	// Other arguments run
+	if _, err := e.Execute(ctx, &types.CommandExecutionRequest{Command: "go", Args: []string{"version"}}); err != nil {
+		t.Errorf("Execute(go version) error = %v", err)
+	}
+}
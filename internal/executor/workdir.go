@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// workDirCache remembers validated working directories for a short time,
+// as agents tend to run many commands in the same directory. Only valid
+// directories are cached, so a directory created right after a failed
+// check is found by the next one.
+type workDirCache struct {
+	mu      sync.Mutex
+	entries map[string]workDirEntry
+}
+
+type workDirEntry struct {
+	path    config.ResolvedPath
+	expires time.Time
+}
+
+// checkWorkDir validates a working directory in a single pass: it must be
+// an absolute path to an existing directory. It returns the directory
+// resolved for the path rules, so validation and the security checks see
+// the same directory.
+func (e *Executor) checkWorkDir(workDir string) (config.ResolvedPath, error) {
+	if !filepath.IsAbs(workDir) {
+		return config.ResolvedPath{}, apperrors.ValidationError("workdir must be an absolute path", "workdir")
+	}
+
+	ttl := e.config.Execution.WorkDirCacheTTL.Std()
+	now := time.Now()
+	if ttl > 0 {
+		e.workDirs.mu.Lock()
+		entry, ok := e.workDirs.entries[workDir]
+		e.workDirs.mu.Unlock()
+		if ok && now.Before(entry.expires) {
+			return entry.path, nil
+		}
+	}
+
+	info, err := os.Stat(workDir)
+	if err != nil {
+		return config.ResolvedPath{}, apperrors.NotFoundError(fmt.Sprintf("workdir not found: %v", err), workDir)
+	}
+	if !info.IsDir() {
+		return config.ResolvedPath{}, apperrors.ValidationError("workdir is not a directory", "workdir")
+	}
+	path, err := config.ResolvePath(workDir)
+	if err != nil {
+		return config.ResolvedPath{}, apperrors.Wrap(err, apperrors.ErrorTypeValidation, "invalid workdir")
+	}
+
+	if ttl > 0 {
+		e.workDirs.mu.Lock()
+		if e.workDirs.entries == nil {
+			e.workDirs.entries = make(map[string]workDirEntry)
+		}
+		// Drop expired entries so the cache stays small
+		for dir, entry := range e.workDirs.entries {
+			if !now.Before(entry.expires) {
+				delete(e.workDirs.entries, dir)
+			}
+		}
+		e.workDirs.entries[workDir] = workDirEntry{path: path, expires: now.Add(ttl)}
+		e.workDirs.mu.Unlock()
+	}
+	return path, nil
+}
@@ -0,0 +1,53 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func TestExecutor_checkWorkDirCache(t *testing.T) {
+	cfg := config.Default()
+	cfg.Execution.WorkDirCacheTTL = config.Duration(time.Minute)
+	e := New(cfg, logger.Default())
+
+	dir := filepath.Join(t.TempDir(), "work")
+
+	// Failures are not cached, so a directory created after a failed
+	// check is found
+	if _, err := e.checkWorkDir(dir); err == nil {
+		t.Fatal("expected missing workdir to be rejected")
+	}
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path, err := e.checkWorkDir(dir)
+	if err != nil {
+		t.Fatalf("checkWorkDir() error = %v", err)
+	}
+	if path.Abs != dir {
+		t.Errorf("Abs = %s, want %s", path.Abs, dir)
+	}
+
+	// Valid directories are remembered
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.checkWorkDir(dir); err != nil {
+		t.Errorf("expected cached workdir to pass, got %v", err)
+	}
+
+	// Without the cache every check looks at the directory
+	cfg.Execution.WorkDirCacheTTL = 0
+	if _, err := e.checkWorkDir(dir); err == nil {
+		t.Error("expected removed workdir to be rejected without the cache")
+	}
+
+	if _, err := e.checkWorkDir("relative/path"); err == nil {
+		t.Error("expected relative workdir to be rejected")
+	}
+}
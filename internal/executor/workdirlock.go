@@ -0,0 +1,118 @@
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// lockPollInterval is how often a busy workdir lock is retried.
+const lockPollInterval = 100 * time.Millisecond
+
+// ConfigCommandOptions adjusts how a configured command is executed.
+type ConfigCommandOptions struct {
+	// Force runs a mutating command without waiting for its workdir lock.
+	// It requires security.allow_force_unlock and an approval requested
+	// for the forced run (see forcedRunName).
+	Force bool
+
+	// ApprovalID is the approved request a command requiring a second
+	// approval runs under.
+	ApprovalID string
+}
+
+// forcedRunName is the name the approval of a forced run of a command is
+// requested under, so that approving a normal run cannot force one.
+func forcedRunName(name string) string {
+	return name + " (force unlock)"
+}
+
+// lockDir returns the directory holding workdir lock files.
+func (e *Executor) lockDir() string {
+	if e.config.Execution.LockDir != "" {
+		return e.config.Execution.LockDir
+	}
+	return filepath.Join(os.TempDir(), "simple-mcp-runner", "locks")
+}
+
+// lockWorkDir takes the advisory lock of a working directory, waiting
+// while another run in this or another server process holds it. It
+// returns a release function and the time spent waiting.
+func (e *Executor) lockWorkDir(ctx context.Context, workDir string) (func(), time.Duration, error) {
+	dir, err := resolveWorkDir(workDir)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := os.MkdirAll(e.lockDir(), 0o700); err != nil {
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to create lock directory")
+	}
+
+	sum := sha256.Sum256([]byte(dir))
+	path := filepath.Join(e.lockDir(), hex.EncodeToString(sum[:8])+".lock")
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to open lock file")
+	}
+
+	start := time.Now()
+	logged := false
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, 0, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to lock workdir")
+		}
+		if locked {
+			break
+		}
+
+		if !logged {
+			e.logger.Info("waiting for workdir lock", "workdir", dir)
+			logged = true
+		}
+
+		select {
+		case <-time.After(lockPollInterval):
+		case <-ctx.Done():
+			f.Close()
+			return nil, time.Since(start), apperrors.TimeoutError("context cancelled while waiting for workdir lock on "+dir, "")
+		}
+	}
+
+	release := func() {
+		if err := unlockFile(f); err != nil {
+			e.logger.WithError(err).Warn("failed to unlock workdir", "workdir", dir)
+		}
+		f.Close()
+	}
+
+	return release, time.Since(start), nil
+}
+
+// resolveWorkDir returns the absolute, symlink-free form of a working
+// directory, or of the current directory when empty.
+func resolveWorkDir(workDir string) (string, error) {
+	if workDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to get working directory")
+		}
+		workDir = wd
+	}
+
+	abs, err := filepath.Abs(workDir)
+	if err != nil {
+		return "", apperrors.Wrap(err, apperrors.ErrorTypeValidation, "invalid working directory")
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	return abs, nil
+}
@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+func lockTestExecutor(t *testing.T) *Executor {
+	cfg := config.Default()
+	cfg.Execution.LockDir = t.TempDir()
+	return New(cfg, logger.Default())
+}
+
+func TestExecutor_lockWorkDir(t *testing.T) {
+	e := lockTestExecutor(t)
+	dir := t.TempDir()
+
+	release, _, err := e.lockWorkDir(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A different directory is independent
+	other, _, err := e.lockWorkDir(context.Background(), t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error for other workdir: %v", err)
+	}
+	other()
+
+	// The same directory waits until released
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	if _, _, err := e.lockWorkDir(ctx, dir); err == nil {
+		t.Fatal("expected timeout while workdir is locked")
+	}
+
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		release()
+	}()
+
+	r, wait, err := e.lockWorkDir(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("unexpected error after release: %v", err)
+	}
+	r()
+	if wait < 100*time.Millisecond {
+		t.Errorf("expected reported wait of at least 100ms, got %s", wait)
+	}
+}
+
+func TestExecutor_ExecuteConfigCommandMutating(t *testing.T) {
+	e := lockTestExecutor(t)
+	dir := t.TempDir()
+
+	release, _, err := e.lockWorkDir(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+
+	cmd := &config.Command{Name: "build", Command: "echo", Mutating: true}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	if _, err := e.ExecuteConfigCommand(ctx, cmd, dir); err == nil {
+		t.Error("expected mutating command to wait for the workdir lock")
+	}
+
+	// Commands that are not mutating ignore the lock
+	plain := &config.Command{Name: "status", Command: "echo"}
+	if _, err := e.ExecuteConfigCommand(context.Background(), plain, dir); err != nil {
+		t.Errorf("unexpected error for non-mutating command: %v", err)
+	}
+}
+
+func TestExecutor_ExecuteConfigCommandForce(t *testing.T) {
+	cfg := config.Default()
+	cfg.Execution.LockDir = t.TempDir()
+	cfg.Approvals.File = filepath.Join(t.TempDir(), "approvals.jsonl")
+	keys := operatorKeys(t, cfg, "alice", "bob")
+	e := New(cfg, logger.Default())
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	release, _, err := e.lockWorkDir(ctx, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+
+	cmd := &config.Command{Name: "build", Command: "echo", Mutating: true}
+
+	// Force is refused unless allowed
+	_, err = e.ExecuteConfigCommandWithOptions(ctx, cmd, dir, ConfigCommandOptions{Force: true})
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypePermission {
+		t.Errorf("expected permission error for force, got %v", err)
+	}
+
+	// Once allowed, each forced run needs its own approval
+	e.config.Security.AllowForceUnlock = true
+	if _, err := e.ExecuteConfigCommandWithOptions(ctx, cmd, dir, ConfigCommandOptions{Force: true}); err == nil || !strings.Contains(err.Error(), "approval request") {
+		t.Fatalf("expected forced run to be held for approval, got %v", err)
+	}
+	requests, err := e.approvals.List()
+	if err != nil || len(requests) != 1 {
+		t.Fatalf("expected one approval request, got %d (%v)", len(requests), err)
+	}
+	if requests[0].Command != forcedRunName("build") {
+		t.Errorf("expected the request to name the forced run, got %q", requests[0].Command)
+	}
+	id := requests[0].ID
+	for _, key := range keys {
+		if _, err := e.approvals.Approve(id, key, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := e.ExecuteConfigCommandWithOptions(ctx, cmd, dir, ConfigCommandOptions{Force: true, ApprovalID: id})
+	if err != nil {
+		t.Fatalf("unexpected error with approved force: %v", err)
+	}
+	if result.LockWait != 0 {
+		t.Errorf("expected no lock wait with force, got %s", result.LockWait)
+	}
+}
@@ -0,0 +1,76 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// configWorkDir returns the working directory of a configured command run
+// with the workdir the client passed. Without workdir_mode the command's
+// own workdir wins; with it, the client's path, or the command's workdir
+// when it passed none, is where the directory is looked for.
+func (e *Executor) configWorkDir(cmd *config.Command, workDir string) (string, error) {
+	if cmd.WorkDirMode == "" {
+		if cmd.WorkDir != "" {
+			return cmd.WorkDir, nil
+		}
+		return workDir, nil
+	}
+
+	start := workDir
+	if start == "" {
+		start = cmd.WorkDir
+	}
+	if start != "" && !filepath.IsAbs(start) {
+		return "", apperrors.ValidationError("workdir must be an absolute path", "workdir")
+	}
+	return e.inferWorkDir(cmd, start)
+}
+
+// inferWorkDir walks up from start, a directory or a file in it, to the
+// directory cmd's workdir_mode selects.
+func (e *Executor) inferWorkDir(cmd *config.Command, start string) (string, error) {
+	dir, err := resolveWorkDir(start)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(dir); err != nil {
+		return "", apperrors.NotFoundError(e.msg.Sprintf("workdir not found: %s", start), start)
+	} else if !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	markers := []string{".git"}
+	if cmd.WorkDirMode == config.WorkDirModeNearestMarker {
+		markers = cmd.WorkDirMarkers
+		if len(markers) == 0 {
+			markers = config.DefaultWorkDirMarkers
+		}
+	}
+
+	for d := dir; ; {
+		for _, marker := range markers {
+			if _, err := os.Stat(filepath.Join(d, marker)); err == nil {
+				if d != dir {
+					e.logger.Debug("inferred workdir", "command", cmd.Name, "from", dir, "workdir", d)
+				}
+				return d, nil
+			}
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+
+	if cmd.WorkDirMode == config.WorkDirModeGitRoot {
+		return "", apperrors.NotFoundError(e.msg.Sprintf("%s is not inside a git repository", dir), dir)
+	}
+	return "", apperrors.NotFoundError(
+		e.msg.Sprintf("no project marker (%s) in %s or above", strings.Join(markers, ", "), dir), dir)
+}
@@ -0,0 +1,105 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// projectTree creates a repository root with a Go module in svc and
+// returns the root.
+func projectTree(t *testing.T) string {
+	t.Helper()
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{".git", filepath.Join("svc", "pkg", "x")} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{filepath.Join("svc", "go.mod"), filepath.Join("svc", "pkg", "x", "x.go")} {
+		if err := os.WriteFile(filepath.Join(root, file), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestExecutor_configWorkDir(t *testing.T) {
+	root := projectTree(t)
+	svc := filepath.Join(root, "svc")
+	pkg := filepath.Join(svc, "pkg", "x")
+	e := New(config.Default(), logger.Default())
+
+	tests := []struct {
+		name    string
+		cmd     config.Command
+		workDir string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "command workdir wins without a mode",
+			cmd:     config.Command{WorkDir: svc},
+			workDir: pkg,
+			want:    svc,
+		},
+		{
+			name:    "client workdir without a mode",
+			workDir: pkg,
+			want:    pkg,
+		},
+		{
+			name:    "git root from a file",
+			cmd:     config.Command{WorkDirMode: config.WorkDirModeGitRoot},
+			workDir: filepath.Join(pkg, "x.go"),
+			want:    root,
+		},
+		{
+			name:    "nearest marker",
+			cmd:     config.Command{WorkDirMode: config.WorkDirModeNearestMarker},
+			workDir: pkg,
+			want:    svc,
+		},
+		{
+			name: "nearest marker from the command workdir",
+			cmd:  config.Command{WorkDirMode: config.WorkDirModeNearestMarker, WorkDir: pkg},
+			want: svc,
+		},
+		{
+			name:    "configured markers",
+			cmd:     config.Command{WorkDirMode: config.WorkDirModeNearestMarker, WorkDirMarkers: []string{"smr-no-such-marker"}},
+			workDir: pkg,
+			wantErr: true,
+		},
+		{
+			name:    "relative path",
+			cmd:     config.Command{WorkDirMode: config.WorkDirModeGitRoot},
+			workDir: filepath.Join("svc", "pkg"),
+			wantErr: true,
+		},
+		{
+			name:    "missing path",
+			cmd:     config.Command{WorkDirMode: config.WorkDirModeGitRoot},
+			workDir: filepath.Join(root, "missing"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.configWorkDir(&tt.cmd, tt.workDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("configWorkDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("configWorkDir() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
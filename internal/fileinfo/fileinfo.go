@@ -0,0 +1,233 @@
+// Package fileinfo reports file metadata and checksums
+package fileinfo
+
+import (
+	"crypto/md5" // #nosec G501 - MD5 is reported for compatibility, not security
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// sniffLen is the number of bytes used for content type detection.
+const sniffLen = 512
+
+// Path types.
+const (
+	TypeFile    = "file"
+	TypeDir     = "directory"
+	TypeSymlink = "symlink"
+	TypeOther   = "other"
+)
+
+// extensionTypes refines generic sniffed content types. A fixed table
+// keeps results identical across platforms, unlike the system MIME
+// database.
+var extensionTypes = map[string]string{
+	".json": "application/json",
+	".yaml": "application/yaml",
+	".yml":  "application/yaml",
+	".toml": "application/toml",
+	".xml":  "application/xml",
+	".md":   "text/markdown; charset=utf-8",
+	".csv":  "text/csv; charset=utf-8",
+	".js":   "text/javascript; charset=utf-8",
+	".ts":   "text/typescript; charset=utf-8",
+	".css":  "text/css; charset=utf-8",
+	".go":   "text/x-go; charset=utf-8",
+	".py":   "text/x-python; charset=utf-8",
+	".sh":   "application/x-sh",
+	".tgz":  "application/gzip",
+	".gz":   "application/gzip",
+	".tar":  "application/x-tar",
+	".wasm": "application/wasm",
+}
+
+// Info describes a path.
+type Info struct {
+	Path     string    `json:"path"`
+	Type     string    `json:"type"` // file, directory, symlink or other
+	Size     int64     `json:"size"`
+	Mode     string    `json:"mode"` // e.g. -rw-r--r--
+	Perm     string    `json:"perm"` // Octal permissions, e.g. 0644
+	ModTime  time.Time `json:"mod_time"`
+	MimeType string    `json:"mime_type,omitempty"`
+	Target   string    `json:"target,omitempty"` // Symlink target
+}
+
+// Hashes are checksums of a file.
+type Hashes struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	MD5    string `json:"md5"`
+	SHA256 string `json:"sha256"`
+
+	// Matches reports whether an expected digest matched; nil when none
+	// was given
+	Matches *bool `json:"matches,omitempty"`
+}
+
+// Verify compares an expected MD5 or SHA-256 hex digest, optionally
+// prefixed with "md5:" or "sha256:", and records the outcome.
+func (h *Hashes) Verify(expected string) bool {
+	expected = strings.ToLower(strings.TrimSpace(expected))
+	var matches bool
+	switch {
+	case strings.HasPrefix(expected, "md5:"):
+		matches = strings.TrimPrefix(expected, "md5:") == h.MD5
+	case strings.HasPrefix(expected, "sha256:"):
+		matches = strings.TrimPrefix(expected, "sha256:") == h.SHA256
+	default:
+		matches = expected == h.MD5 || expected == h.SHA256
+	}
+	h.Matches = &matches
+	return matches
+}
+
+// Stat returns metadata for a path without following a final symlink.
+func Stat(cfg *config.Config, path string) (*Info, error) {
+	if err := checkPath(cfg, path); err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, apperrors.NotFoundError("path not found", path)
+		}
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to stat path")
+	}
+
+	info := &Info{
+		Path:    path,
+		Type:    pathType(fi.Mode()),
+		Size:    fi.Size(),
+		Mode:    fi.Mode().String(),
+		Perm:    fmt.Sprintf("%04o", fi.Mode().Perm()),
+		ModTime: fi.ModTime(),
+	}
+
+	switch info.Type {
+	case TypeSymlink:
+		if target, err := os.Readlink(path); err == nil {
+			info.Target = target
+		}
+	case TypeFile:
+		mimeType, err := detectMimeType(path)
+		if err != nil {
+			return nil, err
+		}
+		info.MimeType = mimeType
+	}
+
+	return info, nil
+}
+
+// Hash computes the MD5 and SHA-256 checksums of a regular file.
+func Hash(cfg *config.Config, path string) (*Hashes, error) {
+	if err := checkPath(cfg, path); err != nil {
+		return nil, err
+	}
+
+	f, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	md5Hash := md5.New() // #nosec G401
+	sha256Hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(md5Hash, sha256Hash), f)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read file")
+	}
+
+	return &Hashes{
+		Path:   path,
+		Size:   size,
+		MD5:    hex.EncodeToString(md5Hash.Sum(nil)),
+		SHA256: hex.EncodeToString(sha256Hash.Sum(nil)),
+	}, nil
+}
+
+// detectMimeType sniffs the content type of a file, refined by extension
+// when the content alone is not conclusive.
+func detectMimeType(path string) (string, error) {
+	f, err := open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read file")
+	}
+
+	detected := http.DetectContentType(buf[:n])
+	generic := detected == "application/octet-stream" || strings.HasPrefix(detected, "text/plain")
+	if ext, ok := extensionTypes[strings.ToLower(filepath.Ext(path))]; ok && generic {
+		return ext, nil
+	}
+	return detected, nil
+}
+
+// open opens a regular file for reading.
+func open(path string) (*os.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, apperrors.NotFoundError("file not found", path)
+		}
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to open file")
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to stat file")
+	}
+	if !fi.Mode().IsRegular() {
+		f.Close()
+		return nil, apperrors.ValidationError("not a regular file", "path")
+	}
+
+	return f, nil
+}
+
+func pathType(mode fs.FileMode) string {
+	switch {
+	case mode.IsRegular():
+		return TypeFile
+	case mode.IsDir():
+		return TypeDir
+	case mode&fs.ModeSymlink != 0:
+		return TypeSymlink
+	default:
+		return TypeOther
+	}
+}
+
+// checkPath validates a local path against the security settings.
+func checkPath(cfg *config.Config, path string) error {
+	if path == "" {
+		return apperrors.ValidationError("path is required", "path")
+	}
+	if !filepath.IsAbs(path) {
+		return apperrors.ValidationError("path must be absolute", "path")
+	}
+	if !cfg.IsPathAllowed(path) {
+		return apperrors.PermissionError("path not allowed: "+path, path)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package fileinfo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func TestStat(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Default()
+
+	files := map[string][]byte{
+		"notes.txt":   []byte("plain text"),
+		"config.json": []byte(`{"a": 1}`),
+		"image.png":   {0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'},
+		"page.html":   []byte("<!DOCTYPE html><html></html>"),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o640); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		mime string
+	}{
+		{"notes.txt", "text/plain; charset=utf-8"},
+		{"config.json", "application/json"},
+		{"image.png", "image/png"},
+		{"page.html", "text/html; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Stat(cfg, filepath.Join(dir, tt.name))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if info.Type != TypeFile || info.Size != int64(len(files[tt.name])) {
+				t.Errorf("unexpected info: %+v", info)
+			}
+			if info.MimeType != tt.mime {
+				t.Errorf("expected mime %q, got %q", tt.mime, info.MimeType)
+			}
+			if runtime.GOOS != "windows" && info.Perm != "0640" {
+				t.Errorf("expected perm 0640, got %s", info.Perm)
+			}
+		})
+	}
+
+	info, err := Stat(cfg, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Type != TypeDir || info.MimeType != "" {
+		t.Errorf("unexpected directory info: %+v", info)
+	}
+
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink("notes.txt", link); err == nil {
+		info, err := Stat(cfg, link)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if info.Type != TypeSymlink || info.Target != "notes.txt" {
+			t.Errorf("unexpected symlink info: %+v", info)
+		}
+	}
+
+	if _, err := Stat(cfg, filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error for missing path")
+	}
+	if _, err := Stat(cfg, "relative"); err == nil {
+		t.Error("expected error for relative path")
+	}
+
+	restricted := config.Default()
+	restricted.Security.AllowedPaths = []string{"/nonexistent"}
+	if _, err := Stat(restricted, dir); err == nil {
+		t.Error("expected error for disallowed path")
+	}
+}
+
+func TestHash(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.txt")
+	if err := os.WriteFile(path, []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := Hash(config.Default(), path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	const (
+		md5Hello    = "b1946ac92492d2347c6235b4d2611184"
+		sha256Hello = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+	)
+	if h.Size != 6 || h.MD5 != md5Hello || h.SHA256 != sha256Hello {
+		t.Errorf("unexpected hashes: %+v", h)
+	}
+
+	verify := []struct {
+		expected string
+		want     bool
+	}{
+		{md5Hello, true},
+		{sha256Hello, true},
+		{"SHA256:" + sha256Hello, true},
+		{"md5:" + sha256Hello, false},
+		{"deadbeef", false},
+	}
+	for _, tt := range verify {
+		if got := h.Verify(tt.expected); got != tt.want || h.Matches == nil || *h.Matches != tt.want {
+			t.Errorf("Verify(%q) = %v, want %v", tt.expected, got, tt.want)
+		}
+	}
+
+	if _, err := Hash(config.Default(), dir); err == nil {
+		t.Error("expected error hashing a directory")
+	}
+}
+
+func TestDiskUsage(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]int{
+		"small.txt":                 10,
+		"build/app.bin":             500,
+		"build/cache/blob":          300,
+		"node_modules/pkg/index.js": 200,
+	}
+	for name, size := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := config.Default()
+	cfg.Security.AllowedPaths = []string{dir, filepath.Join(dir, "build")}
+
+	usage, err := DiskUsage(context.Background(), cfg, "", UsageOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(usage.Roots) != 1 || usage.Roots[0].Size != 1010 || usage.Roots[0].Files != 4 || usage.Roots[0].Dirs != 4 {
+		t.Errorf("unexpected roots: %+v", usage.Roots)
+	}
+	if len(usage.LargestFiles) != 2 || usage.LargestFiles[0].Path != filepath.Join(dir, "build", "app.bin") {
+		t.Errorf("unexpected largest files: %+v", usage.LargestFiles)
+	}
+	if len(usage.LargestDirs) != 2 || usage.LargestDirs[0].Path != filepath.Join(dir, "build") || usage.LargestDirs[0].Size != 800 {
+		t.Errorf("unexpected largest directories: %+v", usage.LargestDirs)
+	}
+	if usage.Truncated != "" || usage.Entries != 9 {
+		t.Errorf("expected all 9 entries visited, got %d (%q)", usage.Entries, usage.Truncated)
+	}
+
+	// Limits stop the walk and say so
+	usage, err = DiskUsage(context.Background(), cfg, dir, UsageOptions{MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Truncated != TruncatedDepth || usage.Roots[0].Size != 10 {
+		t.Errorf("expected only the top level, got %+v", usage)
+	}
+	cfg.DiskUsage.MaxEntries = 3
+	usage, err = DiskUsage(context.Background(), cfg, dir, UsageOptions{MaxEntries: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Truncated != TruncatedEntries || usage.Entries != 3 {
+		t.Errorf("expected 3 entries, got %d (%q)", usage.Entries, usage.Truncated)
+	}
+
+	cfg.Security.DeniedPaths = []string{filepath.Join(dir, "node_modules")}
+	cfg.DiskUsage.MaxEntries = 0
+	usage, err = DiskUsage(context.Background(), cfg, dir, UsageOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Skipped != 1 || usage.Roots[0].Size != 810 {
+		t.Errorf("expected node_modules to be skipped, got %+v", usage.Roots)
+	}
+
+	if _, err := DiskUsage(context.Background(), cfg, filepath.Join(dir, "small.txt"), UsageOptions{}); err == nil {
+		t.Error("expected an error for a file")
+	}
+	if _, err := DiskUsage(context.Background(), config.Default(), "", UsageOptions{}); err == nil {
+		t.Error("expected a path to be required without allowed paths")
+	}
+}
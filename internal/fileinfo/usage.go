@@ -0,0 +1,270 @@
+package fileinfo
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// defaultUsageLimit is the number of largest files and directories
+// returned when a request does not say.
+const defaultUsageLimit = 20
+
+// Reasons a disk usage analysis stopped early.
+const (
+	TruncatedEntries = "max_entries"
+	TruncatedDepth   = "max_depth"
+)
+
+// UsageOptions limits a disk usage analysis.
+type UsageOptions struct {
+	MaxDepth   int // Directories deeper than this are not visited; 0 for no limit
+	MaxEntries int // Entries visited, capped by disk_usage.max_entries
+	Limit      int // Largest files and directories returned, capped by disk_usage.max_results
+}
+
+// FileUsage describes a file.
+type FileUsage struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// DirUsage describes a directory and everything visited under it.
+type DirUsage struct {
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`  // Bytes of the regular files under it
+	Files int    `json:"files"` // Regular files under it
+	Dirs  int    `json:"dirs"`  // Directories under it
+}
+
+// Usage is the result of a disk usage analysis.
+type Usage struct {
+	Roots        []DirUsage  `json:"roots"`
+	LargestFiles []FileUsage `json:"largest_files"`
+	LargestDirs  []DirUsage  `json:"largest_dirs"`
+	Entries      int         `json:"entries"`           // Files and directories visited
+	Skipped      int         `json:"skipped,omitempty"` // Unreadable or denied directories
+
+	// Truncated says why the analysis did not visit everything, in which
+	// case sizes are lower bounds
+	Truncated string `json:"truncated,omitempty"`
+}
+
+// DiskUsage walks a directory, or every allowed path when path is empty,
+// and reports the largest files and directories under it. Symlinks are not
+// followed, and directories in denied_paths are skipped.
+func DiskUsage(ctx context.Context, cfg *config.Config, path string, opts UsageOptions) (*Usage, error) {
+	candidates := []string{path}
+	if path == "" {
+		if len(cfg.Security.AllowedPaths) == 0 {
+			return nil, apperrors.ValidationError("path is required when allowed_paths is empty", "path")
+		}
+		candidates = cfg.Security.AllowedPaths
+	}
+	for _, root := range candidates {
+		if err := checkPath(cfg, root); err != nil {
+			return nil, err
+		}
+	}
+	// Allowed paths inside others are visited with them
+	var roots []string
+	for _, root := range candidates {
+		root = filepath.Clean(root)
+		nested := slices.ContainsFunc(candidates, func(other string) bool {
+			return depth(filepath.Clean(other), root) > 0
+		})
+		if !nested && !slices.Contains(roots, root) {
+			roots = append(roots, root)
+		}
+	}
+
+	if opts.MaxEntries <= 0 || (cfg.DiskUsage.MaxEntries > 0 && opts.MaxEntries > cfg.DiskUsage.MaxEntries) {
+		opts.MaxEntries = cfg.DiskUsage.MaxEntries
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultUsageLimit
+	}
+	if cfg.DiskUsage.MaxResults > 0 && opts.Limit > cfg.DiskUsage.MaxResults {
+		opts.Limit = cfg.DiskUsage.MaxResults
+	}
+
+	w := &usageWalk{
+		cfg:    cfg,
+		opts:   opts,
+		usage:  &Usage{LargestFiles: []FileUsage{}, LargestDirs: []DirUsage{}},
+		roots:  map[string]bool{},
+		byPath: map[string]*DirUsage{},
+	}
+	for _, root := range roots {
+		if err := w.walk(ctx, root); err != nil {
+			return nil, err
+		}
+		if w.usage.Truncated == TruncatedEntries {
+			break
+		}
+	}
+
+	w.usage.LargestFiles = append(w.usage.LargestFiles, topFiles(w.files, opts.Limit)...)
+	sort.Slice(w.dirs, func(i, j int) bool {
+		if w.dirs[i].Size != w.dirs[j].Size {
+			return w.dirs[i].Size > w.dirs[j].Size
+		}
+		return w.dirs[i].Path < w.dirs[j].Path
+	})
+	for _, dir := range w.dirs {
+		if len(w.usage.LargestDirs) == opts.Limit {
+			break
+		}
+		if !w.roots[dir.Path] {
+			w.usage.LargestDirs = append(w.usage.LargestDirs, *dir)
+		}
+	}
+	for _, root := range roots {
+		if dir, ok := w.byPath[root]; ok {
+			w.usage.Roots = append(w.usage.Roots, *dir)
+		}
+	}
+
+	return w.usage, nil
+}
+
+// usageWalk accumulates a disk usage analysis.
+type usageWalk struct {
+	cfg    *config.Config
+	opts   UsageOptions
+	usage  *Usage
+	roots  map[string]bool
+	files  []FileUsage
+	dirs   []*DirUsage
+	byPath map[string]*DirUsage
+}
+
+// walk visits one root directory.
+func (w *usageWalk) walk(ctx context.Context, root string) error {
+	info, err := os.Lstat(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return apperrors.NotFoundError("path not found", root)
+		}
+		return apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to stat path")
+	}
+	if !info.IsDir() {
+		return apperrors.ValidationError("not a directory: "+root, "path")
+	}
+	w.roots[root] = true
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if path == root {
+				return err
+			}
+			// Unreadable directories are reported once, after their entry
+			// was counted
+			w.usage.Skipped++
+			return nil
+		}
+		if path != root && w.opts.MaxEntries > 0 && w.usage.Entries >= w.opts.MaxEntries {
+			w.usage.Truncated = TruncatedEntries
+			return filepath.SkipAll
+		}
+
+		if d.IsDir() {
+			if path != root && len(w.cfg.Security.DeniedPaths) > 0 && w.cfg.MatchDeniedPath(path) != "" {
+				w.usage.Skipped++
+				return filepath.SkipDir
+			}
+			w.usage.Entries++
+			dir := &DirUsage{Path: path}
+			w.dirs = append(w.dirs, dir)
+			w.byPath[path] = dir
+			if path != root {
+				w.addUp(root, filepath.Dir(path), func(d *DirUsage) { d.Dirs++ })
+				if w.opts.MaxDepth > 0 && depth(root, path) >= w.opts.MaxDepth {
+					w.usage.Truncated = TruncatedDepth
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+
+		w.usage.Entries++
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		size := info.Size()
+		w.addUp(root, filepath.Dir(path), func(d *DirUsage) {
+			d.Size += size
+			d.Files++
+		})
+		w.files = append(w.files, FileUsage{Path: path, Size: size, ModTime: info.ModTime()})
+		if len(w.files) > 2*w.opts.Limit {
+			w.files = topFiles(w.files, w.opts.Limit)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return apperrors.Wrap(err, apperrors.ErrorTypeTimeout, "disk usage analysis canceled")
+		}
+		return apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to read directory")
+	}
+	return nil
+}
+
+// addUp applies fn to dir and each directory above it up to root.
+func (w *usageWalk) addUp(root, dir string, fn func(*DirUsage)) {
+	for {
+		if d, ok := w.byPath[dir]; ok {
+			fn(d)
+		}
+		if dir == root {
+			return
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return
+		}
+		dir = parent
+	}
+}
+
+// depth returns how many directories below root a path is, or 0 when it
+// is root or outside it.
+func depth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// topFiles returns the largest files, largest first.
+func topFiles(files []FileUsage, limit int) []FileUsage {
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Size != files[j].Size {
+			return files[i].Size > files[j].Size
+		}
+		return files[i].Path < files[j].Path
+	})
+	if len(files) > limit {
+		files = files[:limit]
+	}
+	return files
+}
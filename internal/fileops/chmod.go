@@ -0,0 +1,120 @@
+package fileops
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// PermissionChange describes a change of permissions.
+type PermissionChange struct {
+	Path   string `json:"path"`
+	Before string `json:"before"` // Octal permissions, e.g. 0644
+	After  string `json:"after"`
+}
+
+// Chmod changes the permissions of a file or directory to an octal mode
+// such as 0755, or by symbolic clauses such as u+x,go-w. Setuid, setgid
+// and sticky bits cannot be set, and symlinks are not followed.
+func (o *Ops) Chmod(path, mode string) (*PermissionChange, error) {
+	path, err := o.checkPath(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, apperrors.NotFoundError("path not found", path)
+		}
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to stat path")
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		return nil, apperrors.ValidationError("path is a symlink; change its target instead", "path")
+	}
+
+	before := info.Mode().Perm()
+	after, err := applyMode(before, info.IsDir(), mode)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, after); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to change permissions")
+	}
+
+	o.logger.Info("changed permissions", "path", path, "before", octal(before), "after", octal(after))
+	return &PermissionChange{Path: path, Before: octal(before), After: octal(after)}, nil
+}
+
+// applyMode computes the permissions a mode sets.
+func applyMode(perm fs.FileMode, dir bool, mode string) (fs.FileMode, error) {
+	mode = strings.TrimSpace(mode)
+	if mode == "" {
+		return 0, apperrors.ValidationError("mode is required", "mode")
+	}
+	if n, err := strconv.ParseUint(mode, 8, 32); err == nil {
+		if n&^0o777 != 0 {
+			return 0, apperrors.ValidationError("setuid, setgid and sticky bits are not allowed", "mode")
+		}
+		return fs.FileMode(n), nil
+	}
+
+	for _, clause := range strings.Split(mode, ",") {
+		i := strings.IndexAny(clause, "+-=")
+		if i < 0 {
+			return 0, apperrors.ValidationError(fmt.Sprintf("invalid mode clause %q", clause), "mode")
+		}
+		var who fs.FileMode
+		for _, c := range clause[:i] {
+			switch c {
+			case 'u':
+				who |= 0o700
+			case 'g':
+				who |= 0o070
+			case 'o':
+				who |= 0o007
+			case 'a':
+				who |= 0o777
+			default:
+				return 0, apperrors.ValidationError(fmt.Sprintf("invalid mode clause %q", clause), "mode")
+			}
+		}
+		if who == 0 {
+			who = 0o777
+		}
+		var bits fs.FileMode
+		for _, c := range clause[i+1:] {
+			switch c {
+			case 'r':
+				bits |= 0o444
+			case 'w':
+				bits |= 0o222
+			case 'x':
+				bits |= 0o111
+			case 'X':
+				if dir || perm&0o111 != 0 {
+					bits |= 0o111
+				}
+			default:
+				return 0, apperrors.ValidationError(fmt.Sprintf("invalid permission %q: only r, w, x and X can be set", c), "mode")
+			}
+		}
+		switch clause[i] {
+		case '+':
+			perm |= bits & who
+		case '-':
+			perm &^= bits & who
+		case '=':
+			perm = perm&^who | bits&who
+		}
+	}
+	return perm, nil
+}
+
+// octal formats permissions as four octal digits.
+func octal(perm fs.FileMode) string {
+	return fmt.Sprintf("%04o", perm)
+}
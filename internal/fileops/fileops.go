@@ -0,0 +1,63 @@
+// Package fileops changes files on behalf of agents without exposing rm or
+// chmod: deleted paths go to a trash, and permissions are only changed
+// within the allowed paths
+package fileops
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// Ops changes files within the allowed paths.
+type Ops struct {
+	config *config.Config
+	logger *logger.Logger
+	mu     sync.Mutex // Serializes trash changes
+}
+
+// New creates file operations for a configuration.
+func New(cfg *config.Config, log *logger.Logger) *Ops {
+	return &Ops{config: cfg, logger: log}
+}
+
+// TrashEnabled reports whether delete_path is available.
+func (o *Ops) TrashEnabled() bool {
+	return !o.config.Trash.Disabled
+}
+
+// trashDir returns the directory holding deleted paths.
+func (o *Ops) trashDir() string {
+	if o.config.Trash.Dir != "" {
+		return o.config.Trash.Dir
+	}
+	return filepath.Join(config.StateDir(), "trash")
+}
+
+// checkPath validates a path to change. Besides the checks of every file
+// change (see config.CheckWritePath), the allowed paths themselves, the
+// trash and what contains them cannot be changed.
+func (o *Ops) checkPath(path string) (string, error) {
+	if path == "" {
+		return "", apperrors.ValidationError("path is required", "path")
+	}
+	if !filepath.IsAbs(path) {
+		return "", apperrors.ValidationError("path must be absolute", "path")
+	}
+	path = filepath.Clean(path)
+	if err := o.config.CheckWritePath(path); err != nil {
+		return "", err
+	}
+	for _, protected := range append([]string{o.trashDir()}, o.config.Security.AllowedPaths...) {
+		if config.Within(path, protected) {
+			return "", apperrors.PermissionError("path contains an allowed path or the trash: "+path, path)
+		}
+	}
+	if config.Within(o.trashDir(), path) {
+		return "", apperrors.PermissionError("path is in the trash: "+path, path)
+	}
+	return path, nil
+}
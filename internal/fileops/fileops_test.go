@@ -0,0 +1,232 @@
+package fileops
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// newTestOps returns operations allowed in a temporary directory, with
+// the trash in another.
+func newTestOps(t *testing.T) (*Ops, string) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := config.Default()
+	cfg.Security.AllowedPaths = []string{dir}
+	cfg.Trash.Dir = filepath.Join(t.TempDir(), "trash")
+	log, err := logger.New(logger.Options{Level: "error", Output: io.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(cfg, log), dir
+}
+
+func TestOps_Delete(t *testing.T) {
+	ops, dir := newTestOps(t)
+	tree := filepath.Join(dir, "build")
+	if err := os.MkdirAll(filepath.Join(tree, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tree, "sub", "out.txt"), []byte("output"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := ops.Delete(tree)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Type != "directory" || entry.Size != 6 || entry.Path != tree {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if _, err := os.Lstat(tree); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be gone, got %v", tree, err)
+	}
+
+	// The trash keeps the tree and where it came from
+	stored := filepath.Join(ops.trashDir(), entry.ID)
+	if data, err := os.ReadFile(filepath.Join(stored, itemName, "sub", "out.txt")); err != nil || string(data) != "output" {
+		t.Errorf("expected the file in the trash, got %q (%v)", data, err)
+	}
+	var saved Entry
+	data, err := os.ReadFile(filepath.Join(stored, entryFile))
+	if err != nil || json.Unmarshal(data, &saved) != nil || saved.Path != tree {
+		t.Errorf("expected the entry to record the original path, got %s (%v)", data, err)
+	}
+
+	if _, err := ops.Delete(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected deleting a missing path to fail")
+	}
+}
+
+// writeFile writes a file in a test, failing it on error.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOps_Restore(t *testing.T) {
+	ops, dir := newTestOps(t)
+	path := filepath.Join(dir, "notes.txt")
+
+	writeFile(t, path, "first")
+	first, err := ops.Delete(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, "second")
+	if _, err := ops.Delete(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Existing paths are not replaced
+	writeFile(t, path, "third")
+	if _, err := ops.Restore("", path); err == nil {
+		t.Error("expected restoring over an existing path to fail")
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without an ID, the most recent delete from the path comes back
+	restored, err := ops.Restore("", path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "second" || restored.RestoredTo != path {
+		t.Errorf("expected the second version at %s, got %q (%+v)", path, data, restored)
+	}
+
+	// An entry can be restored elsewhere by ID
+	other := filepath.Join(dir, "old", "notes.txt")
+	if _, err := ops.Restore(first.ID, other); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(other); string(data) != "first" {
+		t.Errorf("expected the first version at %s, got %q", other, data)
+	}
+
+	entries, err := ops.List()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("expected an empty trash, got %v (%v)", entries, err)
+	}
+	if _, err := ops.Restore(first.ID, ""); err == nil {
+		t.Error("expected restoring a restored entry to fail")
+	}
+}
+
+func TestOps_prune(t *testing.T) {
+	ops, dir := newTestOps(t)
+	ops.config.Trash.MaxSize = 10
+
+	var ids []string
+	for _, name := range []string{"a", "b", "c"} {
+		path := filepath.Join(dir, name)
+		writeFile(t, path, "123456")
+		entry, err := ops.Delete(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, entry.ID)
+	}
+
+	// Only the newest entry fits in 10 bytes
+	entries, err := ops.List()
+	if err != nil || len(entries) != 1 || entries[0].ID != ids[2] {
+		t.Fatalf("expected only %s to be kept, got %v (%v)", ids[2], entries, err)
+	}
+
+	// The most recent entry is kept even when it is too large or too old
+	ops.config.Trash.MaxSize = 1
+	ops.config.Trash.MaxAge = config.Duration(time.Nanosecond)
+	if entries, _ := ops.List(); len(entries) != 1 {
+		t.Errorf("expected the most recent entry to be kept, got %v", entries)
+	}
+}
+
+func TestOps_checkPath(t *testing.T) {
+	ops, dir := newTestOps(t)
+	for _, path := range []string{
+		"relative/path",
+		dir,               // An allowed path itself
+		filepath.Dir(dir), // Contains an allowed path
+		t.TempDir(),       // Outside the allowed paths
+		ops.trashDir(),    // The trash
+		filepath.Join(ops.trashDir(), "entry"),
+	} {
+		if _, err := ops.checkPath(path); err == nil {
+			t.Errorf("expected %q to be refused", path)
+		}
+	}
+
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(t.TempDir(), link); err == nil {
+		if _, err := ops.checkPath(filepath.Join(link, "file")); err == nil {
+			t.Error("expected a path reached through a link out of the allowed paths to be refused")
+		}
+	}
+
+	// File systems of macOS and Windows find the trash in any case
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		ops.config.Trash.Dir = filepath.Join(dir, "Trash")
+		if _, err := ops.checkPath(filepath.Join(dir, "TRASH", "entry")); err == nil {
+			t.Error("expected a path in the trash written in another case to be refused")
+		}
+	}
+
+	ops.config.Security.AllowedPaths = nil
+	if _, err := ops.checkPath(filepath.Join(dir, "file")); err == nil {
+		t.Error("expected changes to need allowed paths")
+	}
+}
+
+func TestOps_Chmod(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not POSIX on Windows")
+	}
+	ops, dir := newTestOps(t)
+	path := filepath.Join(dir, "run.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mode, want string
+	}{
+		{"u+x", "0744"},
+		{"go-r,a+X", "0711"},
+		{"u=rw,g=r", "0641"},
+		{"600", "0600"},
+	}
+	for _, tt := range tests {
+		change, err := ops.Chmod(path, tt.mode)
+		if err != nil {
+			t.Fatalf("Chmod(%q) error = %v", tt.mode, err)
+		}
+		if change.After != tt.want {
+			t.Errorf("Chmod(%q) = %s, want %s", tt.mode, change.After, tt.want)
+		}
+	}
+
+	for _, mode := range []string{"4755", "u+s", "+t", "z+x", ""} {
+		if _, err := ops.Chmod(path, mode); err == nil {
+			t.Errorf("expected mode %q to be refused", mode)
+		}
+	}
+
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(path, link); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ops.Chmod(link, "777"); err == nil {
+		t.Error("expected symlinks to be refused")
+	}
+}
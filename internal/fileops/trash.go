@@ -0,0 +1,346 @@
+package fileops
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// Names of the files in a trash entry's directory.
+const (
+	entryFile = "entry.json"
+	itemName  = "item"
+)
+
+// Entry describes a path in the trash.
+type Entry struct {
+	ID      string    `json:"id"`
+	Path    string    `json:"path"` // Where the path was deleted from
+	Type    string    `json:"type"` // file, directory or symlink
+	Size    int64     `json:"size"` // Bytes of the files it contains
+	Deleted time.Time `json:"deleted"`
+}
+
+// Delete moves a path into the trash, recording where it came from.
+// Symlinks are moved themselves, not what they point to.
+func (o *Ops) Delete(path string) (*Entry, error) {
+	if !o.TrashEnabled() {
+		return nil, apperrors.ConfigurationError("the trash is disabled")
+	}
+	path, err := o.checkPath(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, apperrors.NotFoundError("path not found", path)
+		}
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to stat path")
+	}
+
+	entry := &Entry{ID: newID(), Path: path, Type: pathType(info.Mode()), Deleted: time.Now()}
+	entry.Size, _ = treeSize(path)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	dir := filepath.Join(o.trashDir(), entry.ID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to create trash entry")
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to encode trash entry")
+	}
+	if err := os.WriteFile(filepath.Join(dir, entryFile), data, 0o600); err != nil {
+		os.RemoveAll(dir)
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to write trash entry")
+	}
+	if err := move(path, filepath.Join(dir, itemName)); err != nil {
+		os.RemoveAll(dir)
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to move path to the trash")
+	}
+
+	o.logger.Info("moved path to trash", "path", path, "id", entry.ID, "size", entry.Size)
+
+	o.prune()
+	return entry, nil
+}
+
+// Restored describes a path moved back out of the trash.
+type Restored struct {
+	Entry
+	RestoredTo string `json:"restored_to"`
+}
+
+// Restore moves a trash entry back to where it was deleted from, or to
+// dest if given. The entry is the one with the given ID or, without an ID,
+// the most recent one deleted from dest. Existing paths are never replaced.
+func (o *Ops) Restore(id, dest string) (*Restored, error) {
+	if !o.TrashEnabled() {
+		return nil, apperrors.ConfigurationError("the trash is disabled")
+	}
+	if id == "" && dest == "" {
+		return nil, apperrors.ValidationError("id or path is required", "id")
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entry, err := o.find(id, dest)
+	if err != nil {
+		return nil, err
+	}
+	if dest == "" {
+		dest = entry.Path
+	}
+	dest, err = o.checkPath(dest)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(dest); err == nil {
+		return nil, apperrors.ValidationError("path already exists: "+dest, "path")
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to create parent directory")
+	}
+
+	dir := filepath.Join(o.trashDir(), entry.ID)
+	if err := move(filepath.Join(dir, itemName), dest); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to restore path from the trash")
+	}
+	o.remove(entry.ID)
+
+	o.logger.Info("restored path from trash", "path", dest, "id", entry.ID)
+	return &Restored{Entry: *entry, RestoredTo: dest}, nil
+}
+
+// find returns the entry with an ID or, without one, the most recent entry
+// deleted from path.
+func (o *Ops) find(id, path string) (*Entry, error) {
+	entries, err := o.list()
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if id != "" && entries[i].ID == id {
+			return &entries[i], nil
+		}
+		if id == "" && entries[i].Path == filepath.Clean(path) {
+			return &entries[i], nil
+		}
+	}
+	if id != "" {
+		return nil, apperrors.NotFoundError("no such trash entry: "+id, id)
+	}
+	return nil, apperrors.NotFoundError("nothing in the trash was deleted from "+path, path)
+}
+
+// List returns the entries in the trash, newest first, after applying the
+// retention policy.
+func (o *Ops) List() ([]Entry, error) {
+	if !o.TrashEnabled() {
+		return nil, apperrors.ConfigurationError("the trash is disabled")
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.prune()
+	return o.list()
+}
+
+func (o *Ops) list() ([]Entry, error) {
+	dirs, err := os.ReadDir(o.trashDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read trash directory")
+	}
+
+	var entries []Entry
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(o.trashDir(), dir.Name(), entryFile))
+		if err != nil {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			o.logger.WithError(err).Warn("skipping unreadable trash entry", "id", dir.Name())
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(o.trashDir(), dir.Name(), itemName)); err != nil {
+			// Interrupted while the path was moved in or out
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID > entries[j].ID
+	})
+	return entries, nil
+}
+
+// prune purges entries older than max_age, then the oldest entries until
+// the trash fits in max_size. The most recent entry is always kept, so a
+// delete is never purged right away. The caller holds o.mu.
+func (o *Ops) prune() {
+	entries, err := o.list()
+	if err != nil {
+		o.logger.WithError(err).Warn("failed to purge trash")
+		return
+	}
+
+	maxAge := o.config.Trash.MaxAge.Std()
+
+	var total int64
+	for i, entry := range entries {
+		total += entry.Size
+		if i == 0 {
+			continue
+		}
+		tooOld := maxAge > 0 && time.Since(entry.Deleted) > maxAge
+		tooBig := o.config.Trash.MaxSize > 0 && total > int64(o.config.Trash.MaxSize)
+		if tooOld || tooBig {
+			o.logger.Info("purged trash entry", "id", entry.ID, "path", entry.Path)
+			o.remove(entry.ID)
+			total -= entry.Size
+		}
+	}
+}
+
+// remove deletes a trash entry for good.
+func (o *Ops) remove(id string) {
+	if err := os.RemoveAll(filepath.Join(o.trashDir(), id)); err != nil {
+		o.logger.WithError(err).Warn("failed to remove trash entry", "id", id)
+	}
+}
+
+// move renames a path, copying it across file systems.
+func move(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	var linkErr *os.LinkError
+	if !errors.As(err, &linkErr) {
+		return err
+	}
+	if _, statErr := os.Lstat(src); statErr != nil {
+		return err
+	}
+	if err := copyTree(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// copyTree copies a file, symlink or directory tree, keeping modes and
+// modification times.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.IsDir():
+			if err := os.Mkdir(target, info.Mode().Perm()|0o700); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			if err := copyFile(path, target, info.Mode().Perm()); err != nil {
+				return err
+			}
+		default:
+			return apperrors.ValidationError("cannot move special file: "+path, "path")
+		}
+		return os.Chtimes(target, info.ModTime(), info.ModTime())
+	})
+}
+
+// copyFile copies a regular file.
+func copyFile(src, dst string, mode fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// treeSize returns the bytes of the regular files under a path.
+func treeSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// pathType names the type of a file mode.
+func pathType(mode fs.FileMode) string {
+	switch {
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	case mode.IsDir():
+		return "directory"
+	default:
+		return "file"
+	}
+}
+
+// newID returns a trash entry ID that sorts by deletion time.
+func newID() string {
+	b := make([]byte, 3)
+	rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405.000000000") + "-" + hex.EncodeToString(b)
+}
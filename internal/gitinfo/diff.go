@@ -0,0 +1,237 @@
+package gitinfo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// Defaults of diffs.
+const (
+	DefaultDiffContext  = 3
+	DefaultDiffMaxLines = 1000
+)
+
+// DiffOptions selects what a diff compares.
+type DiffOptions struct {
+	Staged   bool     // Compare the index instead of the working tree
+	Base     string   // Revision to compare with instead of the index, or HEAD when staged
+	Paths    []string // Limit the diff to these paths
+	Context  int      // Unchanged lines around changes; the default when negative
+	MaxLines int      // Hunk lines returned, over all files
+}
+
+// Diff is the diff of a repository's changes.
+type Diff struct {
+	Repo    string     `json:"repo"`
+	Files   []FileDiff `json:"files"`
+	Added   int        `json:"added"`
+	Deleted int        `json:"deleted"`
+
+	// Truncated is set when hunk lines were left out to stay within
+	// max_lines; the counts still cover every change
+	Truncated bool `json:"truncated,omitempty"`
+
+	// Scoped is set when only the working directory is reported
+	Scoped bool `json:"scoped,omitempty"`
+}
+
+// FileDiff is the diff of a file, its paths relative to the repository.
+type FileDiff struct {
+	Path     string `json:"path"`
+	OrigPath string `json:"orig_path,omitempty"` // Source of a rename or copy
+	Change   string `json:"change"`
+	Binary   bool   `json:"binary,omitempty"`
+	Added    int    `json:"added"`
+	Deleted  int    `json:"deleted"`
+	Hunks    []Hunk `json:"hunks"`
+}
+
+// Hunk is a changed region of a file. Lines keep their diff prefix: a
+// space for unchanged lines, + for added and - for deleted ones.
+type Hunk struct {
+	OldStart int      `json:"old_start"`
+	OldLines int      `json:"old_lines"`
+	NewStart int      `json:"new_start"`
+	NewLines int      `json:"new_lines"`
+	Section  string   `json:"section,omitempty"` // Enclosing function or section, as git finds it
+	Lines    []string `json:"lines"`
+}
+
+// hunkHeader matches a unified diff hunk header.
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+// GetDiff returns the changes of the repository dir is in. Files outside
+// allowed_paths are left out.
+func GetDiff(ctx context.Context, cfg *config.Config, dir string, opts DiffOptions) (*Diff, error) {
+	if err := checkRevision(opts.Base, "base"); err != nil {
+		return nil, err
+	}
+	r, err := open(ctx, cfg, dir)
+	if err != nil {
+		return nil, err
+	}
+	pathspec, err := r.pathspec(opts.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Context < 0 {
+		opts.Context = DefaultDiffContext
+	}
+	if opts.MaxLines <= 0 {
+		opts.MaxLines = DefaultDiffMaxLines
+	}
+	args := []string{"diff", "--no-ext-diff", "--no-textconv", "--no-color", "-M", fmt.Sprintf("-U%d", opts.Context)}
+	if opts.Staged {
+		args = append(args, "--cached")
+	}
+	if opts.Base != "" {
+		args = append(args, opts.Base)
+	}
+	args = append(append(args, "--"), pathspec...)
+	out, err := r.git(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := parseDiff(string(out), opts.MaxLines, r.allowed)
+	diff.Repo = r.top
+	diff.Scoped = r.scoped()
+	return diff, nil
+}
+
+// parseDiff parses a unified diff of git, keeping the files allowed
+// accepts and at most maxLines hunk lines.
+func parseDiff(out string, maxLines int, allowed func(path string) bool) *Diff {
+	diff := &Diff{Files: []FileDiff{}}
+	var files []*FileDiff
+	var file *FileDiff
+	var hunk *Hunk
+	var oldLeft, newLeft, lines int
+
+	for _, line := range strings.Split(out, "\n") {
+		// Hunk content first, as it may look like a header
+		if file != nil && (oldLeft > 0 || newLeft > 0) {
+			content := true
+			switch {
+			case strings.HasPrefix(line, " "):
+				oldLeft--
+				newLeft--
+			case strings.HasPrefix(line, "-"):
+				oldLeft--
+				file.Deleted++
+			case strings.HasPrefix(line, "+"):
+				newLeft--
+				file.Added++
+			case strings.HasPrefix(line, "\\"):
+			default:
+				content = false
+				oldLeft, newLeft = 0, 0
+			}
+			if content {
+				if lines < maxLines && hunk != nil {
+					hunk.Lines = append(hunk.Lines, line)
+					lines++
+				} else {
+					diff.Truncated = true
+				}
+				continue
+			}
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			file = &FileDiff{Path: headerPath(strings.TrimPrefix(line, "diff --git ")), Change: ChangeModified, Hunks: []Hunk{}}
+			files = append(files, file)
+			hunk = nil
+		case file == nil:
+		case strings.HasPrefix(line, "new file mode"):
+			file.Change = ChangeAdded
+		case strings.HasPrefix(line, "deleted file mode"):
+			file.Change = ChangeDeleted
+		case strings.HasPrefix(line, "rename from "):
+			file.Change, file.OrigPath = ChangeRenamed, unquote(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "rename to "):
+			file.Path = unquote(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "copy from "):
+			file.Change, file.OrigPath = ChangeCopied, unquote(strings.TrimPrefix(line, "copy from "))
+		case strings.HasPrefix(line, "copy to "):
+			file.Path = unquote(strings.TrimPrefix(line, "copy to "))
+		case strings.HasPrefix(line, "Binary files "):
+			file.Binary = true
+		case strings.HasPrefix(line, "--- "):
+			if name := unquote(strings.TrimPrefix(line, "--- ")); name != "/dev/null" && file.Path == "" {
+				file.Path = strings.TrimPrefix(name, "a/")
+			}
+		case strings.HasPrefix(line, "+++ "):
+			if name := unquote(strings.TrimPrefix(line, "+++ ")); name != "/dev/null" {
+				file.Path = strings.TrimPrefix(name, "b/")
+			}
+		case strings.HasPrefix(line, "@@ "):
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			h := Hunk{
+				OldStart: atoi(m[1]),
+				OldLines: countOr1(m[2]),
+				NewStart: atoi(m[3]),
+				NewLines: countOr1(m[4]),
+				Section:  m[5],
+				Lines:    []string{},
+			}
+			oldLeft, newLeft = h.OldLines, h.NewLines
+			if lines < maxLines {
+				file.Hunks = append(file.Hunks, h)
+				hunk = &file.Hunks[len(file.Hunks)-1]
+			} else {
+				hunk = nil
+				diff.Truncated = true
+			}
+		}
+	}
+
+	for _, f := range files {
+		if !allowed(f.Path) {
+			continue
+		}
+		diff.Files = append(diff.Files, *f)
+		diff.Added += f.Added
+		diff.Deleted += f.Deleted
+	}
+	return diff
+}
+
+// headerPath returns the path of a diff --git header naming the same
+// path twice, which is every header but those of renames and copies,
+// whose paths come from later lines.
+func headerPath(paths string) string {
+	n := (len(paths) - 1) / 2
+	if len(paths)%2 == 0 || paths[n] != ' ' {
+		return ""
+	}
+	a, b := unquote(paths[:n]), unquote(paths[n+1:])
+	if !strings.HasPrefix(a, "a/") || !strings.HasPrefix(b, "b/") || a[2:] != b[2:] {
+		return ""
+	}
+	return b[2:]
+}
+
+// atoi converts a number matched by hunkHeader.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// countOr1 converts a hunk line count, which is left out when it is 1.
+func countOr1(s string) int {
+	if s == "" {
+		return 1
+	}
+	return atoi(s)
+}
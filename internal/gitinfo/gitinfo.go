@@ -0,0 +1,186 @@
+// Package gitinfo reports the status, diffs and history of git
+// repositories as structured data, parsed from git's porcelain output.
+package gitinfo
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// safeConfig keeps git from running programs a repository configures
+// while it is only read: fsmonitor hooks, and external diff and textconv
+// drivers, which are disabled with flags on the diff commands. Clean and
+// smudge filters are disabled by name once the repository is opened (see
+// noFilters).
+var safeConfig = []string{
+	"-c", "core.fsmonitor=false",
+	"-c", "core.quotePath=false",
+	"-c", "color.ui=false",
+}
+
+// Change kinds of a file.
+const (
+	ChangeModified    = "modified"
+	ChangeTypeChanged = "type_changed"
+	ChangeAdded       = "added"
+	ChangeDeleted     = "deleted"
+	ChangeRenamed     = "renamed"
+	ChangeCopied      = "copied"
+	ChangeUnmerged    = "unmerged"
+)
+
+// changeKinds maps the status letters of git to change kinds.
+var changeKinds = map[byte]string{
+	'M': ChangeModified,
+	'T': ChangeTypeChanged,
+	'A': ChangeAdded,
+	'D': ChangeDeleted,
+	'R': ChangeRenamed,
+	'C': ChangeCopied,
+	'U': ChangeUnmerged,
+}
+
+// repo is a repository opened for a working directory.
+type repo struct {
+	cfg     *config.Config
+	dir     string   // Working directory, inside the repository
+	top     string   // Top-level directory of the repository
+	filters []string // Options disabling the repository's filter drivers
+}
+
+// open checks a working directory and finds the repository it is in.
+func open(ctx context.Context, cfg *config.Config, dir string) (*repo, error) {
+	if dir == "" {
+		return nil, apperrors.ValidationError("workdir is required", "workdir")
+	}
+	if !filepath.IsAbs(dir) {
+		return nil, apperrors.ValidationError("workdir must be an absolute path", "workdir")
+	}
+	if !cfg.IsPathAllowed(dir) {
+		return nil, apperrors.PermissionError("path not allowed: "+dir, dir)
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, apperrors.NotFoundError("git was not found", "git")
+	}
+
+	r := &repo{cfg: cfg, dir: dir}
+	out, err := r.git(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	r.top = filepath.FromSlash(strings.TrimSpace(string(out)))
+	if r.filters, err = r.noFilters(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// noFilters returns options emptying the commands of the filter drivers
+// the repository configures, which git status and diff would otherwise
+// run on changed files, as gitattributes select them. Drivers are found
+// by name, as no option disables them all. With no driver configured,
+// git config fails and none is returned.
+func (r *repo) noFilters(ctx context.Context) ([]string, error) {
+	out, err := r.git(ctx, "config", "--name-only", "--get-regexp", `^filter\.`)
+	if err != nil {
+		return nil, nil
+	}
+	var opts []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(string(out), "\n") {
+		rest := strings.TrimPrefix(name, "filter.")
+		i := strings.LastIndexByte(rest, '.')
+		if i <= 0 || seen[rest[:i]] {
+			continue
+		}
+		driver := rest[:i]
+		if strings.Contains(driver, "=") {
+			// -c would take the name for a value
+			return nil, apperrors.PermissionError("repository configures a filter driver that cannot be disabled: "+driver, r.top)
+		}
+		seen[driver] = true
+		for _, key := range []string{"clean", "smudge", "process"} {
+			opts = append(opts, "-c", "filter."+driver+"."+key+"=")
+		}
+		opts = append(opts, "-c", "filter."+driver+".required=false")
+	}
+	return opts, nil
+}
+
+// scoped reports whether results must be limited to the working
+// directory, as the rest of the repository is not allowed.
+func (r *repo) scoped() bool {
+	return !r.cfg.IsPathAllowed(r.top)
+}
+
+// allowed reports whether a path relative to the top-level directory may
+// be reported.
+func (r *repo) allowed(path string) bool {
+	return r.cfg.IsPathAllowed(filepath.Join(r.top, filepath.FromSlash(path)))
+}
+
+// pathspec returns the paths to pass after --: those given, checked to
+// stay in the working directory when results are scoped to it.
+func (r *repo) pathspec(paths []string) ([]string, error) {
+	if !r.scoped() {
+		return paths, nil
+	}
+	if len(paths) == 0 {
+		return []string{"."}, nil
+	}
+	for _, p := range paths {
+		abs := filepath.Clean(filepath.Join(r.dir, p))
+		if filepath.IsAbs(p) {
+			abs = filepath.Clean(p)
+		}
+		if rel, err := filepath.Rel(r.dir, abs); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, apperrors.PermissionError("path not allowed: "+p, p)
+		}
+	}
+	return paths, nil
+}
+
+// git runs git in the working directory and returns its stdout.
+func (r *repo) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", slices.Concat(safeConfig, r.filters, args)...)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0", "GIT_TERMINAL_PROMPT=0", "GIT_PAGER=cat")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "git "+args[0]+": "+msg)
+		}
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "git "+args[0]+" failed")
+	}
+	return stdout.Bytes(), nil
+}
+
+// checkRevision rejects revisions git would take for options.
+func checkRevision(rev, field string) error {
+	if strings.HasPrefix(rev, "-") {
+		return apperrors.ValidationError("invalid revision: "+rev, field)
+	}
+	return nil
+}
+
+// unquote decodes a path git quoted because of special characters.
+func unquote(path string) string {
+	if len(path) >= 2 && path[0] == '"' && path[len(path)-1] == '"' {
+		if s, err := strconv.Unquote(path); err == nil {
+			return s
+		}
+	}
+	return path
+}
@@ -0,0 +1,253 @@
+package gitinfo
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// newRepo creates a repository with two commits: the first adds
+// README.md, src/main.go and docs/old.md, the second renames docs/old.md
+// to docs/guide.md.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	write(t, dir, "README.md", "# Project\n")
+	write(t, dir, "src/main.go", "package main\n\nfunc main() {\n}\n")
+	write(t, dir, "docs/old.md", "guide\n")
+	git(t, dir, "init", "-q")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "Initial commit", "-m", "With a body.")
+	git(t, dir, "mv", "docs/old.md", "docs/guide.md")
+	git(t, dir, "commit", "-q", "-m", "Rename the guide")
+	return dir
+}
+
+func write(t *testing.T, dir, name, data string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+	dir := newRepo(t)
+	cfg := config.Default()
+	ctx := context.Background()
+
+	status, err := GetStatus(ctx, cfg, dir)
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if !status.Clean || status.Commit == "" || status.Branch == "" || len(status.Files) != 0 {
+		t.Errorf("GetStatus() = %+v, want a clean branch", status)
+	}
+
+	write(t, dir, "README.md", "# Project\n\nMore.\n")
+	write(t, dir, "src/util.go", "package main\n")
+	write(t, dir, "notes file.txt", "todo\n")
+	git(t, dir, "add", "src/util.go")
+	git(t, dir, "mv", "docs/guide.md", "docs/manual.md")
+
+	status, err = GetStatus(ctx, cfg, filepath.Join(dir, "src"))
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	want := map[string]FileStatus{
+		"README.md":      {Path: "README.md", Unstaged: ChangeModified},
+		"src/util.go":    {Path: "src/util.go", Staged: ChangeAdded},
+		"docs/manual.md": {Path: "docs/manual.md", OrigPath: "docs/guide.md", Staged: ChangeRenamed},
+		"notes file.txt": {Path: "notes file.txt", Untracked: true},
+	}
+	if status.Clean || len(status.Files) != len(want) {
+		t.Fatalf("GetStatus() files = %+v", status.Files)
+	}
+	for _, f := range status.Files {
+		if f != want[f.Path] {
+			t.Errorf("file %s = %+v, want %+v", f.Path, f, want[f.Path])
+		}
+	}
+
+	// Only the allowed part of the repository is reported
+	cfg.Security.AllowedPaths = []string{filepath.Join(dir, "src")}
+	status, err = GetStatus(ctx, cfg, filepath.Join(dir, "src"))
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if !status.Scoped || len(status.Files) != 1 || status.Files[0].Path != "src/util.go" {
+		t.Errorf("GetStatus() scoped = %v, files = %+v, want only src/util.go", status.Scoped, status.Files)
+	}
+	if _, err := GetStatus(ctx, cfg, dir); err == nil {
+		t.Error("GetStatus() accepted a workdir outside allowed_paths")
+	}
+}
+
+func TestGetDiff(t *testing.T) {
+	dir := newRepo(t)
+	cfg := config.Default()
+	ctx := context.Background()
+
+	write(t, dir, "src/main.go", "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n")
+	write(t, dir, "README.md", "# Project\n-- dashes\n")
+	git(t, dir, "add", "README.md")
+
+	diff, err := GetDiff(ctx, cfg, dir, DiffOptions{Context: DefaultDiffContext})
+	if err != nil {
+		t.Fatalf("GetDiff() error = %v", err)
+	}
+	if len(diff.Files) != 1 || diff.Added != 1 || diff.Deleted != 0 {
+		t.Fatalf("GetDiff() = %+v, want the unstaged change of src/main.go", diff)
+	}
+	f := diff.Files[0]
+	if f.Path != "src/main.go" || f.Change != ChangeModified || len(f.Hunks) != 1 {
+		t.Fatalf("file = %+v", f)
+	}
+	if h := f.Hunks[0]; h.OldStart != 1 || h.NewLines != 5 || !slices.Contains(h.Lines, "+\tprintln(\"hi\")") {
+		t.Errorf("hunk = %+v", h)
+	}
+
+	// Added lines looking like headers stay in their hunk
+	diff, err = GetDiff(ctx, cfg, dir, DiffOptions{Staged: true})
+	if err != nil {
+		t.Fatalf("GetDiff() error = %v", err)
+	}
+	if len(diff.Files) != 1 || diff.Files[0].Path != "README.md" || diff.Added != 1 || diff.Files[0].Hunks[0].Lines[0] != "+-- dashes" {
+		t.Errorf("GetDiff(staged) = %+v", diff)
+	}
+
+	diff, err = GetDiff(ctx, cfg, dir, DiffOptions{Base: "HEAD~1", Context: DefaultDiffContext, MaxLines: 2})
+	if err != nil {
+		t.Fatalf("GetDiff() error = %v", err)
+	}
+	var renamed bool
+	for _, f := range diff.Files {
+		renamed = renamed || f.Change == ChangeRenamed && f.OrigPath == "docs/old.md" && f.Path == "docs/guide.md"
+	}
+	if !renamed || !diff.Truncated || diff.Added != 2 {
+		t.Errorf("GetDiff(HEAD~1) = %+v, want the rename and truncated hunks", diff)
+	}
+
+	if _, err := GetDiff(ctx, cfg, dir, DiffOptions{Base: "--output=/tmp/x"}); err == nil {
+		t.Error("GetDiff() accepted an option as base")
+	}
+}
+
+func TestGetDiff_noFilters(t *testing.T) {
+	dir := newRepo(t)
+	cfg := config.Default()
+	ctx := context.Background()
+
+	// A filter the repository configures would run on changed files
+	marker := filepath.Join(t.TempDir(), "ran")
+	git(t, dir, "config", "filter.Evil.clean", "touch '"+marker+"'; cat")
+	git(t, dir, "config", "filter.Evil.required", "true")
+	write(t, dir, ".gitattributes", "*.md filter=Evil\n")
+	write(t, dir, "README.md", "# Project\nchanged\n")
+
+	diff, err := GetDiff(ctx, cfg, dir, DiffOptions{Context: DefaultDiffContext})
+	if err != nil {
+		t.Fatalf("GetDiff() error = %v", err)
+	}
+	if len(diff.Files) != 1 || diff.Files[0].Path != "README.md" {
+		t.Errorf("GetDiff() = %+v, want the change of README.md", diff)
+	}
+	if _, err := GetStatus(ctx, cfg, dir); err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("the repository's clean filter ran")
+	}
+}
+
+func TestGetLog(t *testing.T) {
+	dir := newRepo(t)
+	cfg := config.Default()
+	ctx := context.Background()
+
+	log, err := GetLog(ctx, cfg, dir, LogOptions{Files: true})
+	if err != nil {
+		t.Fatalf("GetLog() error = %v", err)
+	}
+	if len(log.Commits) != 2 || log.More {
+		t.Fatalf("GetLog() = %+v, want 2 commits", log)
+	}
+	latest, first := log.Commits[0], log.Commits[1]
+	if latest.Subject != "Rename the guide" || len(latest.Parents) != 1 || latest.Parents[0] != first.Hash {
+		t.Errorf("latest = %+v", latest)
+	}
+	if len(latest.Files) != 1 || latest.Files[0] != (CommitFile{Path: "docs/guide.md", OrigPath: "docs/old.md", Change: ChangeRenamed}) {
+		t.Errorf("latest files = %+v", latest.Files)
+	}
+	if first.Body != "With a body." || first.Author != "Test" || first.AuthorDate.IsZero() || len(first.Files) != 3 {
+		t.Errorf("first = %+v", first)
+	}
+
+	log, err = GetLog(ctx, cfg, dir, LogOptions{MaxCount: 1})
+	if err != nil {
+		t.Fatalf("GetLog() error = %v", err)
+	}
+	if len(log.Commits) != 1 || !log.More || log.Commits[0].Files != nil {
+		t.Errorf("GetLog(max_count 1) = %+v", log)
+	}
+
+	// Only commits changing the allowed part of the repository
+	cfg.Security.AllowedPaths = []string{filepath.Join(dir, "docs")}
+	log, err = GetLog(ctx, cfg, filepath.Join(dir, "docs"), LogOptions{Paths: []string{"guide.md"}})
+	if err != nil {
+		t.Fatalf("GetLog() error = %v", err)
+	}
+	if !log.Scoped || len(log.Commits) != 1 {
+		t.Errorf("GetLog(scoped) = %+v", log)
+	}
+	if _, err := GetLog(ctx, cfg, filepath.Join(dir, "docs"), LogOptions{Paths: []string{"../src"}}); err == nil {
+		t.Error("GetLog() accepted a path outside the allowed workdir")
+	}
+}
+
+func TestGetLog_noCommits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+
+	log, err := GetLog(context.Background(), config.Default(), dir, LogOptions{})
+	if err != nil || len(log.Commits) != 0 {
+		t.Errorf("GetLog() = %+v, %v, want no commits", log, err)
+	}
+}
+
+func TestParseDiff_quotedPaths(t *testing.T) {
+	out := strings.Join([]string{
+		`diff --git "a/tab\there.txt" "b/tab\there.txt"`,
+		"index 1111111..2222222 100644",
+		"Binary files \"a/tab\\there.txt\" and \"b/tab\\there.txt\" differ",
+		"",
+	}, "\n")
+	diff := parseDiff(out, 10, func(string) bool { return true })
+	if len(diff.Files) != 1 || diff.Files[0].Path != "tab\there.txt" || !diff.Files[0].Binary {
+		t.Errorf("parseDiff() = %+v", diff)
+	}
+}
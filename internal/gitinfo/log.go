@@ -0,0 +1,201 @@
+package gitinfo
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// Limits of logs.
+const (
+	DefaultLogCount = 20
+	MaxLogCount     = 500
+)
+
+// Separators of the fields and records of logFormat, which commit
+// messages do not contain.
+const (
+	fieldSep  = "\x1f"
+	recordSep = "\x1e"
+)
+
+// logFormat prints the fields of a Commit.
+var logFormat = recordSep + strings.Join([]string{"%H", "%P", "%an", "%ae", "%aI", "%cn", "%ce", "%cI", "%s", "%b"}, fieldSep)
+
+// LogOptions selects the commits of a log.
+type LogOptions struct {
+	Revision string   // Revision or range to list, HEAD by default
+	Paths    []string // Only commits changing these paths
+	MaxCount int      // Commits returned, newest first
+	Skip     int      // Commits skipped first, to page through history
+	Files    bool     // List the files each commit changed
+}
+
+// Log is a list of commits.
+type Log struct {
+	Repo    string   `json:"repo"`
+	Commits []Commit `json:"commits"`
+
+	// More is set when older commits were left out; pass skip to list
+	// them
+	More bool `json:"more,omitempty"`
+
+	// Scoped is set when only commits changing the working directory are
+	// listed
+	Scoped bool `json:"scoped,omitempty"`
+}
+
+// Commit is a commit of a log.
+type Commit struct {
+	Hash           string    `json:"hash"`
+	Parents        []string  `json:"parents"`
+	Author         string    `json:"author"`
+	AuthorEmail    string    `json:"author_email"`
+	AuthorDate     time.Time `json:"author_date"`
+	Committer      string    `json:"committer"`
+	CommitterEmail string    `json:"committer_email"`
+	CommitDate     time.Time `json:"commit_date"`
+	Subject        string    `json:"subject"`
+	Body           string    `json:"body,omitempty"`
+
+	// Files changed by the commit, when asked for; files outside
+	// allowed_paths are left out
+	Files []CommitFile `json:"files,omitempty"`
+}
+
+// CommitFile is a file a commit changed.
+type CommitFile struct {
+	Path     string `json:"path"`
+	OrigPath string `json:"orig_path,omitempty"`
+	Change   string `json:"change"`
+}
+
+// GetLog returns the commits of the repository dir is in. When the
+// repository is not within allowed_paths, only commits changing the
+// working directory are listed.
+func GetLog(ctx context.Context, cfg *config.Config, dir string, opts LogOptions) (*Log, error) {
+	if err := checkRevision(opts.Revision, "revision"); err != nil {
+		return nil, err
+	}
+	if opts.Skip < 0 {
+		return nil, apperrors.ValidationError("skip cannot be negative", "skip")
+	}
+	r, err := open(ctx, cfg, dir)
+	if err != nil {
+		return nil, err
+	}
+	pathspec, err := r.pathspec(opts.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.MaxCount <= 0 {
+		opts.MaxCount = DefaultLogCount
+	}
+	opts.MaxCount = min(opts.MaxCount, MaxLogCount)
+
+	// One more commit than asked tells whether there are more
+	args := []string{"log", "--no-color", "--no-show-signature", "--format=" + logFormat,
+		"--max-count=" + strconv.Itoa(opts.MaxCount+1), "--skip=" + strconv.Itoa(opts.Skip)}
+	if opts.Files {
+		args = append(args, "--name-status", "-z", "-M")
+	}
+	if opts.Revision != "" {
+		args = append(args, opts.Revision)
+	}
+	args = append(append(args, "--"), pathspec...)
+	out, err := r.git(ctx, args...)
+	if err != nil {
+		// A repository without commits has no log
+		if r.emptyRepo(ctx) {
+			return &Log{Repo: r.top, Commits: []Commit{}, Scoped: r.scoped()}, nil
+		}
+		return nil, err
+	}
+
+	log := &Log{Repo: r.top, Commits: parseLog(string(out), opts.Files, r.allowed), Scoped: r.scoped()}
+	if len(log.Commits) > opts.MaxCount {
+		log.Commits = log.Commits[:opts.MaxCount]
+		log.More = true
+	}
+	return log, nil
+}
+
+// emptyRepo reports whether the repository has no commits yet.
+func (r *repo) emptyRepo(ctx context.Context) bool {
+	_, err := r.git(ctx, "rev-parse", "--verify", "--quiet", "HEAD")
+	return err != nil
+}
+
+// parseLog parses the output of git log with logFormat, followed with
+// files by the output of --name-status -z.
+func parseLog(out string, files bool, allowed func(path string) bool) []Commit {
+	commits := []Commit{}
+	for _, record := range strings.Split(out, recordSep) {
+		fields := strings.SplitN(record, fieldSep, 10)
+		if len(fields) < 10 {
+			continue
+		}
+		c := Commit{
+			Hash:           fields[0],
+			Parents:        strings.Fields(fields[1]),
+			Author:         fields[2],
+			AuthorEmail:    fields[3],
+			Committer:      fields[5],
+			CommitterEmail: fields[6],
+			Subject:        fields[8],
+		}
+		c.AuthorDate, _ = time.Parse(time.RFC3339, fields[4])
+		c.CommitDate, _ = time.Parse(time.RFC3339, fields[7])
+
+		// With -z, the message ends with a NUL followed by the changed
+		// files
+		body := fields[9]
+		if files {
+			var names string
+			if i := strings.IndexByte(body, 0); i >= 0 {
+				body, names = body[:i], body[i+1:]
+			}
+			c.Files = parseNameStatus(names, allowed)
+		}
+		c.Body = strings.TrimSpace(body)
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+// parseNameStatus parses the output of --name-status -z: a status letter,
+// with a score for renames and copies, then the path, or the original and
+// new paths.
+func parseNameStatus(out string, allowed func(path string) bool) []CommitFile {
+	var files []CommitFile
+	entries := strings.Split(strings.TrimLeft(out, "\n"), "\x00")
+	for i := 0; i+1 < len(entries); i++ {
+		status := strings.TrimSpace(entries[i])
+		if status == "" {
+			continue
+		}
+		f := CommitFile{Change: changeKinds[status[0]]}
+		if status[0] == 'R' || status[0] == 'C' {
+			if i+2 >= len(entries) {
+				break
+			}
+			f.OrigPath, f.Path = entries[i+1], entries[i+2]
+			i += 2
+		} else {
+			f.Path = entries[i+1]
+			i++
+		}
+		if f.Change == "" {
+			f.Change = ChangeModified
+		}
+		if allowed(f.Path) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
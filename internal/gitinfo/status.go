@@ -0,0 +1,129 @@
+package gitinfo
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// Status is the state of a repository's working tree.
+type Status struct {
+	Repo     string `json:"repo"`               // Top-level directory
+	Branch   string `json:"branch,omitempty"`   // Empty when HEAD is detached
+	Commit   string `json:"commit,omitempty"`   // Empty before the first commit
+	Upstream string `json:"upstream,omitempty"` // Branch tracked, e.g. origin/main
+	Ahead    int    `json:"ahead"`              // Commits not in the upstream
+	Behind   int    `json:"behind"`             // Upstream commits not in the branch
+	Clean    bool   `json:"clean"`
+
+	Files []FileStatus `json:"files"`
+
+	// Scoped is set when only the working directory is reported, as the
+	// rest of the repository is outside allowed_paths
+	Scoped bool `json:"scoped,omitempty"`
+}
+
+// FileStatus is a changed file, its path relative to the repository.
+type FileStatus struct {
+	Path      string `json:"path"`
+	OrigPath  string `json:"orig_path,omitempty"` // Source of a rename or copy
+	Staged    string `json:"staged,omitempty"`    // Change in the index
+	Unstaged  string `json:"unstaged,omitempty"`  // Change in the working tree
+	Untracked bool   `json:"untracked,omitempty"`
+	Conflict  bool   `json:"conflict,omitempty"`
+}
+
+// GetStatus returns the status of the repository dir is in. Files outside
+// allowed_paths are left out.
+func GetStatus(ctx context.Context, cfg *config.Config, dir string) (*Status, error) {
+	r, err := open(ctx, cfg, dir)
+	if err != nil {
+		return nil, err
+	}
+	pathspec, err := r.pathspec(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"status", "--porcelain=v2", "--branch", "-z", "--untracked-files=all", "--"}, pathspec...)
+	out, err := r.git(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	status := parseStatus(string(out))
+	status.Repo = r.top
+	status.Scoped = r.scoped()
+	files := status.Files[:0]
+	for _, f := range status.Files {
+		if r.allowed(f.Path) {
+			files = append(files, f)
+		}
+	}
+	status.Files = files
+	status.Clean = len(files) == 0
+	return status, nil
+}
+
+// parseStatus parses the output of git status --porcelain=v2 --branch -z.
+func parseStatus(out string) *Status {
+	status := &Status{Files: []FileStatus{}}
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		switch {
+		case strings.HasPrefix(entry, "# branch.oid "):
+			if oid := strings.TrimPrefix(entry, "# branch.oid "); oid != "(initial)" {
+				status.Commit = oid
+			}
+		case strings.HasPrefix(entry, "# branch.head "):
+			if head := strings.TrimPrefix(entry, "# branch.head "); head != "(detached)" {
+				status.Branch = head
+			}
+		case strings.HasPrefix(entry, "# branch.upstream "):
+			status.Upstream = strings.TrimPrefix(entry, "# branch.upstream ")
+		case strings.HasPrefix(entry, "# branch.ab "):
+			fields := strings.Fields(strings.TrimPrefix(entry, "# branch.ab "))
+			if len(fields) == 2 {
+				status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				status.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+			}
+		case strings.HasPrefix(entry, "1 "):
+			// 1 XY sub mH mI mW hH hI path
+			if fields := strings.SplitN(entry, " ", 9); len(fields) == 9 {
+				status.Files = append(status.Files, changedFile(fields[1], fields[8]))
+			}
+		case strings.HasPrefix(entry, "2 "):
+			// 2 XY sub mH mI mW hH hI Xscore path, then the original path
+			if fields := strings.SplitN(entry, " ", 10); len(fields) == 10 {
+				f := changedFile(fields[1], fields[9])
+				if i+1 < len(entries) {
+					i++
+					f.OrigPath = entries[i]
+				}
+				status.Files = append(status.Files, f)
+			}
+		case strings.HasPrefix(entry, "u "):
+			// u XY sub m1 m2 m3 mW h1 h2 h3 path
+			if fields := strings.SplitN(entry, " ", 11); len(fields) == 11 {
+				status.Files = append(status.Files, FileStatus{Path: fields[10], Staged: ChangeUnmerged, Unstaged: ChangeUnmerged, Conflict: true})
+			}
+		case strings.HasPrefix(entry, "? "):
+			status.Files = append(status.Files, FileStatus{Path: strings.TrimPrefix(entry, "? "), Untracked: true})
+		}
+	}
+	status.Clean = len(status.Files) == 0
+	return status
+}
+
+// changedFile returns the status of a tracked file from its XY letters.
+func changedFile(xy, path string) FileStatus {
+	f := FileStatus{Path: path}
+	if len(xy) == 2 {
+		f.Staged = changeKinds[xy[0]]
+		f.Unstaged = changeKinds[xy[1]]
+	}
+	return f
+}
@@ -0,0 +1,286 @@
+// Package history stores records of executed commands
+package history
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/receipt"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// defaultMaxEntries is used when no limit is configured.
+const defaultMaxEntries = 1000
+
+// Store keeps the most recent execution records in memory and optionally
+// appends them to a JSON lines file so they survive restarts.
+type Store struct {
+	mu         sync.RWMutex
+	records    []types.ExecutionRecord
+	maxEntries int
+	file       *os.File
+	signer     *receipt.Signer
+}
+
+// Filter selects records when listing history.
+type Filter struct {
+	Source   string
+	Tool     string
+	Schedule string
+	Decision string // Policy decision, allowed or denied
+	Since    time.Time
+	Limit    int // Zero means no limit
+}
+
+// New creates an in-memory store.
+func New(maxEntries int) *Store {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+	return &Store{maxEntries: maxEntries}
+}
+
+// Open creates a store backed by the given file, loading existing records.
+// An empty path returns an in-memory store.
+func Open(path string, maxEntries int) (*Store, error) {
+	s := New(maxEntries)
+	if path == "" {
+		return s, nil
+	}
+
+	if err := s.load(path); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to create history directory")
+	}
+
+	// Rewrite the file so it only holds the retained records.
+	if err := s.compact(path); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to open history file")
+	}
+	s.file = f
+
+	return s, nil
+}
+
+// load reads existing records from path, keeping the newest maxEntries.
+func (s *Store) load(path string) error {
+	records, err := ReadFile(path)
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		s.append(rec)
+	}
+	return nil
+}
+
+// ReadFile reads every record in a history file, oldest first. A missing
+// file has no records.
+func ReadFile(path string) ([]types.ExecutionRecord, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to read history file")
+	}
+	defer f.Close()
+
+	var records []types.ExecutionRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var rec types.ExecutionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			// Skip corrupt lines, e.g. from an interrupted write
+			continue
+		}
+		records = append(records, rec)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to read history file")
+	}
+	return records, nil
+}
+
+// Decision returns the policy decision for an execution that ended with
+// err: denied when the security policy refused it, allowed otherwise.
+func Decision(err error) string {
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypePermission {
+		return types.PolicyDecisionDenied
+	}
+	return types.PolicyDecisionAllowed
+}
+
+// compact rewrites the history file with the retained records.
+func (s *Store) compact(path string) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to write history file")
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, rec := range s.records {
+		if err := enc.Encode(rec); err != nil {
+			f.Close()
+			return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to encode history record")
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to write history file")
+	}
+	if err := f.Close(); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to write history file")
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to replace history file")
+	}
+	return nil
+}
+
+// SetSigner signs records added from now on.
+func (s *Store) SetSigner(signer *receipt.Signer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signer = signer
+}
+
+// Add stores a record, assigning its ID and timestamp, and returns it.
+func (s *Store) Add(rec types.ExecutionRecord) types.ExecutionRecord {
+	rec.ID = newID()
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now()
+	}
+	if rec.Result != nil {
+		result := *rec.Result
+		result.HistoryID = rec.ID
+		rec.Result = &result
+	}
+
+	s.mu.RLock()
+	signer := s.signer
+	s.mu.RUnlock()
+	if signer != nil && rec.Result != nil {
+		rec.Result.Receipt = signer.Sign(rec)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.append(rec)
+
+	if s.file != nil {
+		// Persistence is best-effort; the in-memory copy is authoritative
+		// for the running server.
+		if data, err := json.Marshal(rec); err == nil {
+			_, _ = s.file.Write(append(data, '\n'))
+		}
+	}
+
+	return rec
+}
+
+// append adds a record and evicts the oldest ones beyond the limit.
+func (s *Store) append(rec types.ExecutionRecord) {
+	s.records = append(s.records, rec)
+	if over := len(s.records) - s.maxEntries; over > 0 {
+		s.records = append(s.records[:0:0], s.records[over:]...)
+	}
+}
+
+// Get returns the record with the given ID.
+func (s *Store) Get(id string) (types.ExecutionRecord, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := len(s.records) - 1; i >= 0; i-- {
+		if s.records[i].ID == id {
+			return s.records[i], true
+		}
+	}
+	return types.ExecutionRecord{}, false
+}
+
+// List returns records matching the filter, newest first.
+func (s *Store) List(f Filter) []types.ExecutionRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []types.ExecutionRecord
+	for i := len(s.records) - 1; i >= 0; i-- {
+		rec := s.records[i]
+		if f.Source != "" && rec.Source != f.Source {
+			continue
+		}
+		if f.Tool != "" && rec.Tool != f.Tool {
+			continue
+		}
+		if f.Schedule != "" && rec.Schedule != f.Schedule {
+			continue
+		}
+		if f.Decision != "" && rec.Decision != f.Decision {
+			continue
+		}
+		if !f.Since.IsZero() && rec.Timestamp.Before(f.Since) {
+			continue
+		}
+
+		out = append(out, rec)
+		if f.Limit > 0 && len(out) >= f.Limit {
+			break
+		}
+	}
+	return out
+}
+
+// Len returns the number of stored records.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.records)
+}
+
+// Close closes the backing file, if any.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// newID returns a random record identifier.
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to time
+		return "h" + time.Now().Format("20060102150405.000000000")
+	}
+	return "h" + hex.EncodeToString(b)
+}
@@ -0,0 +1,190 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/receipt"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestStore_AddAndGet(t *testing.T) {
+	s := New(10)
+
+	rec := s.Add(types.ExecutionRecord{
+		Source: types.ExecutionSourceTool,
+		Tool:   "execute_command",
+		Result: &types.CommandExecutionResult{Stdout: "hi"},
+	})
+
+	if rec.ID == "" || rec.Timestamp.IsZero() {
+		t.Fatalf("expected id and timestamp to be assigned, got %+v", rec)
+	}
+	if rec.Result.HistoryID != rec.ID {
+		t.Errorf("result history id = %q, want %q", rec.Result.HistoryID, rec.ID)
+	}
+
+	got, ok := s.Get(rec.ID)
+	if !ok || got.Result.Stdout != "hi" {
+		t.Errorf("Get() = %+v, %v", got, ok)
+	}
+
+	if _, ok := s.Get("missing"); ok {
+		t.Error("expected missing record")
+	}
+}
+
+func TestStore_Eviction(t *testing.T) {
+	s := New(3)
+	var ids []string
+	for i := 0; i < 5; i++ {
+		ids = append(ids, s.Add(types.ExecutionRecord{Tool: "t"}).ID)
+	}
+
+	if s.Len() != 3 {
+		t.Fatalf("expected 3 records, got %d", s.Len())
+	}
+	if _, ok := s.Get(ids[0]); ok {
+		t.Error("expected oldest record to be evicted")
+	}
+	if _, ok := s.Get(ids[4]); !ok {
+		t.Error("expected newest record to be retained")
+	}
+}
+
+func TestStore_List(t *testing.T) {
+	s := New(10)
+	old := time.Now().Add(-time.Hour)
+	s.Add(types.ExecutionRecord{Source: types.ExecutionSourceSchedule, Schedule: "a", Timestamp: old})
+	s.Add(types.ExecutionRecord{Source: types.ExecutionSourceSchedule, Schedule: "b"})
+	s.Add(types.ExecutionRecord{Source: types.ExecutionSourceTool, Tool: "execute_command", Decision: types.PolicyDecisionDenied})
+	s.Add(types.ExecutionRecord{Source: types.ExecutionSourceSchedule, Schedule: "a"})
+
+	if got := s.List(Filter{Source: types.ExecutionSourceSchedule}); len(got) != 3 {
+		t.Errorf("expected 3 scheduled records, got %d", len(got))
+	}
+	if got := s.List(Filter{Schedule: "a"}); len(got) != 2 || !got[0].Timestamp.After(got[1].Timestamp) {
+		t.Errorf("expected 2 records for schedule a, newest first, got %+v", got)
+	}
+	if got := s.List(Filter{Since: time.Now().Add(-time.Minute)}); len(got) != 3 {
+		t.Errorf("expected 3 recent records, got %d", len(got))
+	}
+	if got := s.List(Filter{Decision: types.PolicyDecisionDenied}); len(got) != 1 || got[0].Tool != "execute_command" {
+		t.Errorf("expected the denied record only, got %+v", got)
+	}
+	if got := s.List(Filter{Limit: 1}); len(got) != 1 || got[0].Schedule != "a" {
+		t.Errorf("expected newest record only, got %+v", got)
+	}
+}
+
+func TestOpen_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "runs.jsonl")
+
+	s, err := Open(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Add(types.ExecutionRecord{Tool: "one"})
+	s.Add(types.ExecutionRecord{Tool: "two"})
+	last := s.Add(types.ExecutionRecord{Tool: "three"})
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	if reopened.Len() != 2 {
+		t.Fatalf("expected 2 records after reload, got %d", reopened.Len())
+	}
+	if got, ok := reopened.Get(last.ID); !ok || got.Tool != "three" {
+		t.Errorf("expected last record after reload, got %+v", got)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs.jsonl")
+
+	records, err := ReadFile(path)
+	if err != nil || len(records) != 0 {
+		t.Fatalf("expected no records for a missing file, got %v, %v", records, err)
+	}
+
+	s, err := Open(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Add(types.ExecutionRecord{Tool: "one"})
+	s.Add(types.ExecutionRecord{Tool: "two"})
+	s.Close()
+
+	records, err = ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Tool != "one" || records[1].Tool != "two" {
+		t.Errorf("expected both records oldest first, got %+v", records)
+	}
+}
+
+func TestDecision(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, types.PolicyDecisionAllowed},
+		{apperrors.ExecutionError("exit status 1", "false"), types.PolicyDecisionAllowed},
+		{apperrors.PermissionError("command not allowed: rm", "rm"), types.PolicyDecisionDenied},
+		{fmt.Errorf("batch step: %w", apperrors.PermissionError("path denied: /etc", "/etc")), types.PolicyDecisionDenied},
+	}
+	for _, tt := range tests {
+		if got := Decision(tt.err); got != tt.want {
+			t.Errorf("Decision(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestStore_SignedRecords(t *testing.T) {
+	private, _, err := receipt.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(keyPath, private, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := receipt.LoadSigner(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := receipt.LoadPublicKey(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(10)
+	s.SetSigner(signer)
+	rec := s.Add(types.ExecutionRecord{
+		Source:  types.ExecutionSourceTool,
+		Request: types.CommandExecutionRequest{Command: "echo"},
+		Result:  &types.CommandExecutionResult{Stdout: "hi"},
+	})
+
+	if rec.Result.Receipt == nil {
+		t.Fatal("expected a receipt")
+	}
+	payload, err := receipt.Verify(rec.Result.Receipt, pub)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if payload.HistoryID != rec.ID {
+		t.Errorf("expected receipt for %s, got %s", rec.ID, payload.HistoryID)
+	}
+}
@@ -0,0 +1,196 @@
+package i18n
+
+// spanish is the Spanish catalog.
+var spanish = map[string]string{
+	// Tool descriptions
+	"Discover available system commands. Use pattern parameter to filter commands (e.g., 'git*', 'npm'). Returns command names, paths, and descriptions; with include_desc, also usage examples and common flags. Results are sorted stably; when truncated, pass next_cursor as cursor to get the next page.": "Descubre los comandos del sistema disponibles. Usa el parámetro pattern para filtrar comandos (p. ej., 'git*', 'npm'). Devuelve nombres, rutas y descripciones de los comandos; con include_desc, también ejemplos de uso y opciones habituales. El orden de los resultados es estable; si están truncados, pasa next_cursor como cursor para obtener la página siguiente.",
+	"Execute a system command with optional arguments and working directory. Returns stdout, stderr, and exit code.": "Ejecuta un comando del sistema con argumentos y directorio de trabajo opcionales. Devuelve stdout, stderr y el código de salida.",
+	"Execute several commands in one call. Each step has an id and may list depends_on step ids; independent steps run in parallel and a step is skipped if any dependency fails. A step may capture values from its stdout (capture: {name: regex or $.json.path}) that dependent steps reference in args as {{step_id.name}}. Returns per-step results grouped by dependency level.": "Ejecuta varios comandos en una sola llamada. Cada paso tiene un id y puede listar en depends_on los ids de otros pasos; los pasos independientes se ejecutan en paralelo y un paso se omite si falla alguna de sus dependencias. Un paso puede capturar valores de su stdout (capture: {name: regex o $.json.path}) que los pasos dependientes usan en args como {{step_id.name}}. Devuelve los resultados de cada paso agrupados por nivel de dependencia.",
+	"List configured command schedules with their next run time, and recent scheduled runs (newest first) with exit codes and output. Use schedule to filter by schedule name.":                                                                                                                                                                                                        "Lista las programaciones de comandos configuradas con su próxima ejecución, y las ejecuciones programadas recientes (las más nuevas primero) con sus códigos de salida y su salida. Usa schedule para filtrar por nombre de programación.",
+	"Watch a file or directory (absolute path) for changes. Changes are debounced and sent to the client as log notifications; if command names a configured command, it is run on each batch of changes, subject to a rate limit. Returns the watch id for list_watches and stop_watch.":                                                                                              "Vigila los cambios de un archivo o directorio (ruta absoluta). Los cambios se agrupan y se envían al cliente como notificaciones de registro; si command nombra un comando configurado, se ejecuta con cada lote de cambios, con un límite de frecuencia. Devuelve el id de la vigilancia para list_watches y stop_watch.",
+	"List active file watches with their recent change events and trigger counts.": "Lista las vigilancias de archivos activas con sus cambios recientes y el número de ejecuciones disparadas.",
+	"Stop an active file watch by id.":                                             "Detiene una vigilancia de archivos activa por su id.",
+	"Check that configured commands can run: that their binaries exist and are executable and their working directories exist. Reports each problem with a hint for installing missing binaries. Commands disabled because they could not run are registered again once they pass.":                                                                                                                                         "Comprueba que los comandos configurados pueden ejecutarse: que sus binarios existen y son ejecutables y que sus directorios de trabajo existen. Informa de cada problema con una sugerencia para instalar los binarios que faltan. Los comandos desactivados porque no podían ejecutarse se registran de nuevo cuando pasan la comprobación.",
+	"Read the last lines of a file by absolute path, such as an application log, without tail. Set follow to a duration such as 30s to keep watching: appended lines are sent to the client as log notifications and returned when following ends. Pass the returned offset back to continue where a previous call stopped.":                                                                                                "Lee las últimas líneas de un archivo por ruta absoluta, como el registro de una aplicación, sin tail. Establece follow en una duración como 30s para seguir observando: las líneas añadidas se envían al cliente como notificaciones de registro y se devuelven cuando termina el seguimiento. Pasa el offset devuelto para continuar donde se detuvo una llamada anterior.",
+	"List running processes with pid, parent pid, command line, CPU and memory usage, and start time. Only processes owned by the server's user are shown unless the configuration allows all users. Filter with name; order with sort_by (pid, cpu, memory or start).":                                                                                                                                                     "Lista los procesos en ejecución con pid, pid del padre, línea de comandos, uso de CPU y memoria, y hora de inicio. Solo se muestran los procesos del usuario del servidor, salvo que la configuración permita todos los usuarios. Filtra con name; ordena con sort_by (pid, cpu, memory o start).",
+	"Get details of a process by pid: name, command line, owner, status, parent pid, CPU and memory usage, and start time.":                                                                                                                                                                                                                                                                                                 "Obtiene los detalles de un proceso por su pid: nombre, línea de comandos, propietario, estado, pid del padre, uso de CPU y memoria, y hora de inicio.",
+	"List local listening TCP and UDP sockets with the owning process where permissions allow. Use port to find what holds an address that is already in use.":                                                                                                                                                                                                                                                              "Lista los sockets TCP y UDP locales a la escucha con el proceso propietario, cuando los permisos lo permiten. Usa port para averiguar qué ocupa una dirección que ya está en uso.",
+	"Get the environment variables executed commands inherit, after the server's environment policy. Values of sensitive-looking variables (tokens, passwords, keys) are masked. Set command to include a configured command's own variables; filter by name with a glob such as \"GO*\".":                                                                                                                                  "Obtiene las variables de entorno que heredan los comandos ejecutados, tras aplicar la política de entorno del servidor. Los valores de variables que parecen sensibles (tokens, contraseñas, claves) se enmascaran. Indica command para incluir las variables propias de un comando configurado; filtra por nombre con un patrón como \"GO*\".",
+	"Download a URL to an absolute local path without curl or wget. The URL scheme and host must be allowed by the configuration, the file size is limited, and the download is verified against sha256 when given. Existing files are only replaced with overwrite.":                                                                                                                                                       "Descarga una URL a una ruta local absoluta sin curl ni wget. El esquema y el host de la URL deben estar permitidos por la configuración, el tamaño del archivo está limitado y la descarga se verifica con sha256 si se indica. Los archivos existentes solo se reemplazan con overwrite.",
+	"Read part of a file by absolute path, starting at offset, up to length bytes (capped by the configuration). Text is returned as is and binary data as base64; eof tells whether the end was reached. Set checksum to get the whole file's SHA-256.":                                                                                                                                                                    "Lee parte de un archivo por ruta absoluta, desde offset y hasta length bytes (con el límite de la configuración). El texto se devuelve tal cual y los datos binarios en base64; eof indica si se llegó al final. Indica checksum para obtener el SHA-256 del archivo completo.",
+	"Extract a zip or tar.gz archive (absolute path) into a destination directory without tar or unzip. Entries that would land outside the destination are rejected, links are skipped, and entry count and size are limited. Existing files are only replaced with overwrite.":                                                                                                                                            "Extrae un archivo zip o tar.gz (ruta absoluta) en un directorio de destino sin tar ni unzip. Se rechazan las entradas que quedarían fuera del destino, se omiten los enlaces y se limitan el número y el tamaño de las entradas. Los archivos existentes solo se reemplazan con overwrite.",
+	"Create a zip or tar.gz archive at an absolute path from files and directories (stored under their base names) without tar or zip. Links are skipped, and entry count and size are limited.":                                                                                                                                                                                                                            "Crea un archivo zip o tar.gz en una ruta absoluta a partir de archivos y directorios (guardados con su nombre base) sin tar ni zip. Se omiten los enlaces y se limitan el número y el tamaño de las entradas.",
+	"Get metadata for an absolute path: type, size, mode, modification time, symlink target, and detected MIME type for files.":                                                                                                                                                                                                                                                                                             "Obtiene los metadatos de una ruta absoluta: tipo, tamaño, modo, fecha de modificación, destino del enlace simbólico y tipo MIME detectado para archivos.",
+	"Compute the MD5 and SHA-256 checksums of a file by absolute path without shasum or openssl. Set expected to verify a digest (optionally prefixed with md5: or sha256:).":                                                                                                                                                                                                                                               "Calcula las sumas MD5 y SHA-256 de un archivo por ruta absoluta sin shasum ni openssl. Indica expected para verificar un resumen (opcionalmente con el prefijo md5: o sha256:).",
+	"Find what takes up disk space under an absolute directory, or under every allowed path when path is omitted, without du or find. Returns the largest files and directories and totals. Symlinks are not followed; max_depth and max_entries bound the walk, and sizes are lower bounds when it stops early.":                                                                                                           "Encuentra qué ocupa espacio en disco bajo un directorio absoluto, o bajo cada ruta permitida si se omite path, sin du ni find. Devuelve los archivos y directorios más grandes y los totales. No se siguen los enlaces simbólicos; max_depth y max_entries limitan el recorrido, y los tamaños son cotas inferiores cuando se detiene antes.",
+	"Stop a process the server started, such as a command run by execute_command or one of its children, instead of running kill. Sends SIGTERM, or kills the process with force, and waits a few seconds for it to exit. Other processes cannot be signalled.":                                                                                                                                                             "Detiene un proceso que inició el servidor, como un comando ejecutado por execute_command o uno de sus hijos, en lugar de ejecutar kill. Envía SIGTERM, o mata el proceso con force, y espera unos segundos a que termine. No se pueden enviar señales a otros procesos.",
+	"Delete a file, directory or symlink by absolute path instead of running rm. The path is moved into the server's trash with a record of where it came from, so restore_path can bring it back until the trash purges it. Only paths inside the allowed paths can be deleted, not the allowed paths themselves.":                                                                                                         "Elimina un archivo, directorio o enlace simbólico por ruta absoluta en lugar de ejecutar rm. La ruta se mueve a la papelera del servidor con un registro de su origen, para que restore_path pueda devolverla hasta que la papelera la purgue. Solo se pueden eliminar rutas dentro de las rutas permitidas, no las rutas permitidas en sí.",
+	"Restore a path deleted with delete_path from the server's trash, by trash entry ID or by the path it was deleted from (the most recent delete of that path). It is restored where it was deleted from unless another absolute path is given, and never replaces an existing path.":                                                                                                                                     "Restaura una ruta eliminada con delete_path desde la papelera del servidor, por ID de entrada de la papelera o por la ruta de la que se eliminó (la eliminación más reciente de esa ruta). Se restaura donde se eliminó salvo que se indique otra ruta absoluta, y nunca reemplaza una ruta existente.",
+	"List the paths in the server's trash, newest first, with their trash entry IDs, original paths, sizes and deletion times.":                                                                                                                                                                                                                                                                                             "Lista las rutas de la papelera del servidor, de la más reciente a la más antigua, con sus ID de entrada, rutas originales, tamaños y fechas de eliminación.",
+	"Change the permissions of a file or directory by absolute path instead of running chmod, with an octal mode such as 0755 or symbolic clauses such as u+x,go-w. Only paths inside the allowed paths can be changed; setuid, setgid and sticky bits cannot be set and symlinks are not followed.":                                                                                                                        "Cambia los permisos de un archivo o directorio por ruta absoluta en lugar de ejecutar chmod, con un modo octal como 0755 o cláusulas simbólicas como u+x,go-w. Solo se pueden cambiar rutas dentro de las rutas permitidas; no se pueden establecer los bits setuid, setgid ni sticky y no se siguen los enlaces simbólicos.",
+	"Show a native desktop notification to the user, e.g. when a long-running task finishes or needs attention. Notifications are rate limited; use sparingly.":                                                                                                                                                                                                                                                             "Muestra una notificación nativa de escritorio al usuario, p. ej. cuando termina una tarea larga o requiere atención. Las notificaciones tienen un límite de frecuencia; úsalas con moderación.",
+	"Revert the most recent file change made by download_file, extract_archive or create_archive: replaced files are restored from the server's backups and created files are removed. Call repeatedly to step further back. Files too large to back up are reported as skipped.":                                                                                                                                           "Revierte el cambio de archivos más reciente hecho por download_file, extract_archive o create_archive: los archivos reemplazados se restauran desde las copias de seguridad del servidor y los archivos creados se eliminan. Llama varias veces para retroceder más. Los archivos demasiado grandes para copiarse se indican como omitidos.",
+	"Remove the macOS quarantine attribute (com.apple.quarantine) of a downloaded binary, by command name or path, so Gatekeeper stops refusing to run it. Only allowed commands can be cleared. Requires approval by two operators: the first call creates an approval request and fails with its ID; call again with approval_id once it is approved.":                                                                    "Elimina el atributo de cuarentena de macOS (com.apple.quarantine) de un binario descargado, por nombre de comando o ruta, para que Gatekeeper deje de negarse a ejecutarlo. Solo se pueden liberar comandos permitidos. Requiere la aprobación de dos operadores: la primera llamada crea una solicitud de aprobación y falla con su ID; vuelve a llamar con approval_id una vez aprobada.",
+	"List the Docker containers the configuration allows tools to touch, with ID, name, image, state and status. Only running containers are listed unless all is set.":                                                                                                                                                                                                                                                     "Lista los contenedores Docker que la configuración permite usar a las herramientas, con ID, nombre, imagen, estado y situación. Solo se listan los contenedores en ejecución salvo que se indique all.",
+	"Read the recent stdout and stderr of an allowed Docker container by name or ID: the last tail lines (capped by the configuration), optionally only since a timestamp or duration such as 10m, with timestamps if requested.":                                                                                                                                                                                           "Lee la salida estándar y de error recientes de un contenedor Docker permitido por nombre o ID: las últimas tail líneas (limitadas por la configuración), opcionalmente solo desde una marca de tiempo o duración como 10m, con marcas de tiempo si se solicitan.",
+	"Run a command in an allowed running Docker container and wait for it, returning stdout, stderr and the exit code. command is the program and its arguments, run without a shell and checked against the security policy like execute_command, including approvals (pass approval_id once approved); it counts as mutating for the operator switches. Only available when the configuration allows exec.":               "Ejecuta un comando en un contenedor Docker permitido y en ejecución y espera a que termine, devolviendo stdout, stderr y el código de salida. command es el programa y sus argumentos, ejecutados sin shell; comprobados con la política de seguridad como en execute_command, incluidas las aprobaciones (pasa approval_id una vez aprobado); cuenta como modificador para los interruptores del operador. Solo disponible cuando la configuración permite exec.",
+	"Start a long-lived interactive program, such as a REPL or dev server, in a new detached tmux session owned by the server. command is the program and its arguments, checked against the security policy like execute_command, including approvals (pass approval_id once approved). Type into it with send_tmux_keys and read its screen with capture_tmux_pane; the screen remains readable after the program exits.": "Inicia un programa interactivo de larga duración, como un REPL o un servidor de desarrollo, en una nueva sesión tmux desacoplada propiedad del servidor. command es el programa y sus argumentos, comprobados con la política de seguridad como en execute_command, incluidas las aprobaciones (pasa approval_id una vez aprobado). Escribe en él con send_tmux_keys y lee su pantalla con capture_tmux_pane; la pantalla sigue legible después de que el programa termine.",
+	"List the tmux sessions owned by the server with the program each runs, its pid, and whether it exited and with which status.":                                                                                                                                                                                                                                                                                          "Lista las sesiones tmux propiedad del servidor con el programa que ejecuta cada una, su pid y si terminó y con qué estado.",
+	"Type into a tmux session created by create_tmux_session: text is typed as is, then keys are pressed by tmux name (such as C-c, Up or Escape), then Enter if enter is set. With wait, such as 2s, the screen is captured after waiting and returned. Keys are not checked against the security policy: the session's program can do whatever it allows, so a shell in a session is an unrestricted shell.":              "Escribe en una sesión tmux creada por create_tmux_session: text se escribe tal cual, luego se pulsan keys por su nombre tmux (como C-c, Up o Escape) y después Enter si se indica enter. Con wait, como 2s, la pantalla se captura tras esperar y se devuelve. Las teclas no se comprueban con la política de seguridad: el programa de la sesión puede hacer todo lo que permita, así que un shell en una sesión es un shell sin restricciones.",
+	"Read the screen of a tmux session with up to lines lines of scrollback (capped by the configuration), and whether its program exited and with which status.":                                                                                                                                                                                                                                                           "Lee la pantalla de una sesión tmux con hasta lines líneas de historial (limitadas por la configuración) y si su programa terminó y con qué estado.",
+	"End a tmux session created by create_tmux_session and the program running in it.": "Termina una sesión tmux creada por create_tmux_session y el programa que se ejecuta en ella.",
+	"Start an interactive interpreter that keeps its state between calls, so data can be loaded once and explored over many send_to_repl calls instead of re-running scripts. interpreter names a configured interpreter (python, node and psql by default); args are appended to its configured arguments, and the interpreter is checked against the security policy like execute_command, including approvals (pass approval_id once approved). Returns a session ID and the banner; idle sessions are stopped after a while.": "Inicia un intérprete interactivo que conserva su estado entre llamadas, para cargar los datos una vez y explorarlos en muchas llamadas a send_to_repl en lugar de volver a ejecutar scripts. interpreter nombra un intérprete configurado (python, node y psql por defecto); args se añaden a sus argumentos configurados, y el intérprete se comprueba con la política de seguridad como en execute_command, incluidas las aprobaciones (pasa approval_id una vez aprobado). Devuelve un ID de sesión y el mensaje de bienvenida; las sesiones inactivas se detienen pasado un tiempo.",
+	"Send input to a session started by start_repl and return its output. Input is sent a line at a time, each waiting for the interpreter's prompt, so multi-line blocks work as typed; end Python blocks with an empty line. If no prompt comes within timeout (default from the configuration), the output so far is returned, the remaining lines are not sent, and the next call first waits for the prompt; interrupt sends Ctrl-C before the input.":                                                                       "Envía entrada a una sesión iniciada por start_repl y devuelve su salida. La entrada se envía línea a línea, esperando cada vez el prompt del intérprete, así que los bloques de varias líneas funcionan tal como se escriben; termina los bloques de Python con una línea vacía. Si no aparece un prompt dentro de timeout (por defecto el de la configuración), se devuelve la salida hasta ese momento, las líneas restantes no se envían y la siguiente llamada espera primero al prompt; interrupt envía Ctrl-C antes de la entrada.",
+	"Stop a session started by start_repl and its interpreter, discarding its state.": "Detiene una sesión iniciada por start_repl y su intérprete, descartando su estado.",
+	"Send an HTTP request to an allowed host instead of running curl, and return the status, headers and body. Only https is used unless the configuration allows http, certificates are always verified, and the method must be allowed. Credentials configured for the host are added by the server and redacted from the response, so never pass tokens in headers. Bodies are limited in size; binary responses are returned as base64.": "Envía una petición HTTP a un host permitido en lugar de ejecutar curl y devuelve el estado, las cabeceras y el cuerpo. Solo se usa https salvo que la configuración permita http, los certificados siempre se verifican y el método debe estar permitido. Las credenciales configuradas para el host las añade el servidor y se ocultan en la respuesta, así que nunca pases tokens en las cabeceras. El tamaño de los cuerpos está limitado; las respuestas binarias se devuelven en base64.",
+	"Explain whether the security policy would allow a command with the given args and workdir, without running it. Lists every rule in evaluation order (command length, workdir, blocked commands, allowed commands, denied paths, allowed paths, shell metacharacters, time window and run count conditions) with its outcome, and marks the first rule that denies.":                                                                     "Explica si la política de seguridad permitiría un comando con los args y el workdir indicados, sin ejecutarlo. Lista cada regla en orden de evaluación (longitud del comando, workdir, comandos bloqueados, comandos permitidos, rutas denegadas, rutas permitidas, metacaracteres de shell, condiciones de ventana horaria y de número de ejecuciones) con su resultado, y marca la primera regla que lo deniega.",
+	"Describe the limits of the server (default and maximum timeout, output size, concurrent runs, command length, batch steps, watches and downloads), a summary of its security policy and the optional features that are enabled, to plan commands within them.":                                                                                                                                                                          "Describe los límites del servidor (timeout por defecto y máximo, tamaño de salida, ejecuciones simultáneas, longitud del comando, pasos de lote, vigilancias y descargas), un resumen de su política de seguridad y las funciones opcionales habilitadas, para planificar comandos dentro de ellos.",
+	"Server events": "Eventos del servidor",
+	"Recent server lifecycle and policy events, oldest first: tools registered and removed, command catalog updates, executions denied and limits reached. New events are sent as info log messages from the events logger.": "Eventos recientes del ciclo de vida y de la política del servidor, del más antiguo al más reciente: herramientas registradas y eliminadas, actualizaciones del catálogo de comandos, ejecuciones denegadas y límites alcanzados. Los eventos nuevos se envían como mensajes de registro de nivel info del registrador events.",
+	"Server configuration summary": "Resumen de la configuración del servidor",
+	"What this server will and won't do: its tools, configured commands, security profile, allowed paths and limits, without secrets.":                                                                                                                                                                                                                                                                                                                                      "Lo que este servidor hará y no hará: sus herramientas, comandos configurados, perfil de seguridad, rutas permitidas y límites, sin secretos.",
+	"List the configured tool groups with their description and tools, marking the groups selected in this session. Tools of unselected groups are hidden from the tool list; use select_toolset to select groups.":                                                                                                                                                                                                                                                         "Lista los grupos de herramientas configurados con su descripción y herramientas, marcando los grupos seleccionados en esta sesión. Las herramientas de los grupos no seleccionados no aparecen en la lista de herramientas; usa select_toolset para seleccionar grupos.",
+	"Select the tool groups whose tools are listed in this session, replacing the current selection; an empty list hides all grouped tools. Clients are notified to list tools again. See list_tool_groups for the available groups.":                                                                                                                                                                                                                                       "Selecciona los grupos de herramientas cuyas herramientas se listan en esta sesión, reemplazando la selección actual; una lista vacía oculta todas las herramientas agrupadas. Se notifica a los clientes que vuelvan a listar las herramientas. Consulta list_tool_groups para ver los grupos disponibles.",
+	"Search the available tools by keywords matched against their names, descriptions and tool groups, e.g. 'integration tests'. Returns the best matching tools first, including those of unselected tool groups.":                                                                                                                                                                                                                                                         "Busca entre las herramientas disponibles por palabras clave que se comparan con sus nombres, descripciones y grupos de herramientas, p. ej. 'integration tests'. Devuelve primero las herramientas que mejor coinciden, incluidas las de grupos no seleccionados.",
+	"Compare two recorded executions by their history_id: returns the metadata that changed (command, args, exit code, duration, ...) and a line-based diff of their stdout and stderr, without resending both outputs. Useful to check what changed after a fix.":                                                                                                                                                                                                          "Compara dos ejecuciones registradas por su history_id: devuelve los metadatos que cambiaron (comando, argumentos, código de salida, duración, ...) y un diff por líneas de su stdout y stderr, sin reenviar ambas salidas. Útil para comprobar qué cambió tras una corrección.",
+	"Read the output of a recorded execution by its history_id one page of lines at a time, including output beyond what the result returned when it was spilled to a file. Start at a line offset, limit the page with max_lines and max_bytes, and pass a regular expression as pattern to only return matching lines, e.g. to jump to the errors of a huge log. Lines carry their numbers; pass next_offset as offset to continue.":                                      "Lee la salida de una ejecución registrada por su history_id, una página de líneas cada vez, incluida la salida que excede lo devuelto en el resultado cuando se volcó a un archivo. Empieza en una línea offset, limita la página con max_lines y max_bytes, y pasa una expresión regular como pattern para devolver solo las líneas que coinciden, p. ej. para saltar a los errores de un registro enorme. Las líneas llevan su número; pasa next_offset como offset para continuar.",
+	"Run an execution recorded in the history again by its history_id, optionally with extra_args appended, another workdir or a different timeout, e.g. to run a failed command again with -v. The re-run goes through the same policy as a new call and is recorded with its own history_id.":                                                                                                                                                                             "Ejecuta de nuevo una ejecución registrada en el historial por su history_id, opcionalmente con extra_args añadidos, otro workdir u otro timeout, p. ej. para repetir un comando fallido con -v. La nueva ejecución pasa por la misma política que una llamada nueva y se registra con su propio history_id.",
+	"Get the status of the git repository containing an absolute workdir as JSON: branch, commit, upstream with ahead and behind counts, and the changed files with their staged and unstaged change (added, modified, deleted, renamed, ...), untracked and conflicted files. Files outside the allowed paths are left out.":                                                                                                                                               "Obtiene el estado del repositorio git que contiene un workdir absoluto como JSON: rama, commit, upstream con los commits por delante y por detrás, y los archivos cambiados con su cambio preparado y no preparado (added, modified, deleted, renamed, ...), archivos sin seguimiento y en conflicto. Los archivos fuera de las rutas permitidas se omiten.",
+	"Get the changes of the git repository containing an absolute workdir as JSON: the changed files with their change kind, added and deleted line counts and hunks of lines. Diffs the working tree against the index by default; set staged to diff the index against HEAD, or base to compare with a revision. Untracked files are not included.":                                                                                                                       "Obtiene los cambios del repositorio git que contiene un workdir absoluto como JSON: los archivos cambiados con su tipo de cambio, el número de líneas añadidas y eliminadas, y los fragmentos de líneas. Por defecto compara el árbol de trabajo con el índice; usa staged para comparar el índice con HEAD, o base para comparar con una revisión. Los archivos sin seguimiento no se incluyen.",
+	"List the commits of the git repository containing an absolute workdir as JSON, newest first: hash, parents, author, dates, subject and body, and with files the files each commit changed. Set revision to a branch or range such as main..HEAD, paths to only list commits changing them, and skip to page through history.":                                                                                                                                          "Lista los commits del repositorio git que contiene un workdir absoluto como JSON, del más reciente al más antiguo: hash, padres, autor, fechas, asunto y cuerpo, y con files los archivos que cambió cada commit. Usa revision para una rama o un rango como main..HEAD, paths para listar solo los commits que los cambian, y skip para recorrer el historial.",
+	"Run the tests of the project containing an absolute workdir and get the failures as JSON: test name, file, line and message, with passed, failed and skipped counts. The framework (go, cargo, npm or pytest) is detected from go.mod, Cargo.toml, package.json or the pytest configuration unless given; set filter to a name pattern to run some of them. The run goes through the same policy as execute_command, and its raw output is recorded under history_id.": "Ejecuta las pruebas del proyecto que contiene un workdir absoluto y obtiene los fallos como JSON: nombre de la prueba, archivo, línea y mensaje, con el número de pruebas superadas, fallidas y omitidas. El framework (go, cargo, npm o pytest) se detecta a partir de go.mod, Cargo.toml, package.json o la configuración de pytest salvo que se indique; usa filter con un patrón de nombres para ejecutar solo algunas. La ejecución pasa por la misma política que execute_command y su salida sin procesar se registra con history_id.",
+	"Run the linter of the project containing an absolute workdir and get its diagnostics as JSON: file, line, column, severity, rule and message. The linter (golangci-lint, go-vet, clippy, eslint, ruff or flake8) is detected from the project's configuration unless given; set paths to lint some files or directories. The run goes through the same policy as execute_command, and its raw output is recorded under history_id.":                                    "Ejecuta el linter del proyecto que contiene un workdir absoluto y obtiene sus diagnósticos como JSON: archivo, línea, columna, severidad, regla y mensaje. El linter (golangci-lint, go-vet, clippy, eslint, ruff o flake8) se detecta a partir de la configuración del proyecto salvo que se indique; usa paths para analizar solo algunos archivos o directorios. La ejecución pasa por la misma política que execute_command y su salida sin procesar se registra con history_id.",
+	"Format the code of the project containing an absolute workdir and get the changes as unified diffs per file. The formatter (gofmt, rustfmt, prettier, ruff or black) is detected from the project's configuration unless given; set paths to format some files or directories, and check to only list the files that would change. Only files inside the allowed paths are changed, and the runs go through the same policy as execute_command.":                       "Formatea el código del proyecto que contiene un workdir absoluto y obtiene los cambios como diffs unificados por archivo. El formateador (gofmt, rustfmt, prettier, ruff o black) se detecta a partir de la configuración del proyecto salvo que se indique; usa paths para formatear solo algunos archivos o directorios, y check para listar solo los archivos que cambiarían. Solo se modifican archivos dentro de las rutas permitidas, y las ejecuciones pasan por la misma política que execute_command.",
+	" Requires approval by two operators: the first call creates an approval request and fails with its ID; call again with approval_id once it is approved.":                                                                                                                                                                                                                                                                                                               " Requiere la aprobación de dos operadores: la primera llamada crea una solicitud de aprobación y falla con su ID; vuelve a llamar con approval_id cuando esté aprobada.",
+
+	// Policy denials
+	"command not allowed: %s":                      "comando no permitido: %s",
+	"; use the %s tool instead":                    "; use la herramienta %s en su lugar",
+	"path denied: %s":                              "ruta denegada: %s",
+	"path not allowed: %s":                         "ruta no permitida: %s",
+	"potentially dangerous character detected: %s": "carácter potencialmente peligroso detectado: %s",
+	" (recorded for policy review)":                " (registrado para revisar la política)",
+	"force requires security.allow_force_unlock":   "force requiere security.allow_force_unlock",
+	"command requires an authenticated user":       "el comando requiere un usuario autenticado",
+	"user %s may not run this command":             "el usuario %s no puede ejecutar este comando",
+	"denied by condition %s: %s":                   "denegado por la condición %s: %s",
+	"denied by cli policy %s: %s":                  "denegado por la política de cli %s: %s",
+	"%s %s held by package policy: %s":             "%s %s retenido por la política de paquetes: %s",
+	"; next allowed at %s":                         "; se permite de nuevo el %s",
+	"denied by plugin %s: %s":                      "denegado por el plugin %s: %s",
+	"command requires approval by %d operators; created approval request %s. " +
+		"Operators approve with: simple-mcp-runner approvals approve %s. " +
+		"Run again with approval_id %s once approved": "el comando requiere la aprobación de %d operadores; se creó la solicitud de aprobación %s. " +
+		"Los operadores aprueban con: simple-mcp-runner approvals approve %s. " +
+		"Vuelve a ejecutarlo con approval_id %s cuando esté aprobada",
+	"%s is quarantined by macOS and Gatekeeper may refuse to run it; remove the quarantine with: xattr -d %s %s":  "%s está en cuarentena de macOS y Gatekeeper puede negarse a ejecutarlo; quita la cuarentena con: xattr -d %s %s",
+	"%s is quarantined by macOS and Gatekeeper may refuse to run it; remove the quarantine with clear_quarantine": "%s está en cuarentena de macOS y Gatekeeper puede negarse a ejecutarlo; quita la cuarentena con clear_quarantine",
+	"clear_quarantine requires security.allow_clear_quarantine":                                                   "clear_quarantine requiere security.allow_clear_quarantine",
+	"command not found: %s":                                       "comando no encontrado: %s",
+	"%s is not quarantined":                                       "%s no está en cuarentena",
+	"failed to clear quarantine of %s":                            "no se pudo quitar la cuarentena de %s",
+	"workdir not found: %s":                                       "directorio de trabajo no encontrado: %s",
+	"%s is not inside a git repository":                           "%s no está dentro de un repositorio git",
+	"no project marker (%s) in %s or above":                       "ningún marcador de proyecto (%s) en %s ni por encima",
+	"executions are paused by an operator":                        "las ejecuciones están pausadas por un operador",
+	"not enough free disk space in %s: %s available, %s required": "no hay suficiente espacio libre en disco en %s: %s disponibles, %s necesarios",
+	"mutating commands are blocked by an operator":                "los comandos que modifican están bloqueados por un operador",
+	"killed by an operator":                                       "terminado por un operador",
+	"flagged by screening (%s): %s":                               "marcado por el filtrado (%s): %s",
+
+	// Tool results
+	"Script failed: %s":            "Falló el script: %s",
+	"\n\nScript log:\n%s":          "\n\nRegistro del script:\n%s",
+	"Command execution failed: %s": "Falló la ejecución del comando: %s",
+	"Batch execution failed: %s":   "Falló la ejecución del lote: %s",
+	"Command executed successfully.\nStdout: %s\nStderr: %s\nExit Code: %d":        "Comando ejecutado correctamente.\nStdout: %s\nStderr: %s\nCódigo de salida: %d",
+	"Tool %s is not selected: call select_toolset with one of the groups %s first": "La herramienta %s no está seleccionada: llama primero a select_toolset con uno de los grupos %s",
+	"Unknown tool group: %s":                                         "Grupo de herramientas desconocido: %s",
+	"Unknown execution: %s":                                          "Ejecución desconocida: %s",
+	"Invalid timeout: %s":                                            "Timeout no válido: %s",
+	"Command %s changed since execution %s":                          "El comando %s ha cambiado desde la ejecución %s",
+	"Command %s does not accept arguments":                           "El comando %s no acepta argumentos",
+	"Execution %s cannot be re-run: %s is not enabled":               "La ejecución %s no se puede repetir: %s no está habilitado",
+	"Execution %s cannot be re-run":                                  "La ejecución %s no se puede repetir",
+	"Re-run of execution %s":                                         "Repetición de la ejecución %s",
+	"workdir must be an absolute path":                               "workdir debe ser una ruta absoluta",
+	"Could not detect the test framework: %s":                        "No se pudo detectar el framework de pruebas: %s",
+	"Tests timed out after %s":                                       "Las pruebas superaron el tiempo límite tras %s",
+	"Tests passed":                                                   "Las pruebas pasaron",
+	"Tests failed with exit code %d":                                 "Las pruebas fallaron con el código de salida %d",
+	"No failures could be parsed; the raw output is in execution %s": "No se pudo extraer ningún fallo; la salida sin procesar está en la ejecución %s",
+	"Only the start and end of the output were parsed; the whole output is in execution %s": "Solo se analizaron el principio y el final de la salida; la salida completa está en la ejecución %s",
+	"Invalid path: %s":                                       "Ruta no válida: %s",
+	"Could not detect the linter: %s":                        "No se pudo detectar el linter: %s",
+	"Could not detect the formatter: %s":                     "No se pudo detectar el formateador: %s",
+	"No problems found by %s":                                "%s no encontró ningún problema",
+	"%s failed with exit code %d":                            "%s falló con el código de salida %d",
+	"%d problems found by %s":                                "%d problemas encontrados por %s",
+	"%s failed":                                              "%s falló",
+	"All files are formatted according to %s":                "Todos los archivos están formateados según %s",
+	"%d files would be reformatted by %s":                    "%d archivos serían reformateados por %s",
+	"%d files reformatted by %s":                             "%d archivos reformateados por %s",
+	"Working directory to run the command in":                "Directorio de trabajo en el que ejecutar el comando",
+	"Arguments appended to the command, separated by spaces": "Argumentos añadidos al comando, separados por espacios",
+	"Call the %s tool with the arguments %s.":                "Llama a la herramienta %s con los argumentos %s.",
+	"Invalid stream %q: must be stdout or stderr":            "Flujo no válido %q: debe ser stdout o stderr",
+	"Invalid pattern: %s":                                    "Patrón no válido: %s",
+	"Execution %s has no output":                             "La ejecución %s no tiene salida",
+	"Failed to read output: %s":                              "No se pudo leer la salida: %s",
+	"No tools match %q":                                      "Ninguna herramienta coincide con %q",
+	"Found %d tools matching %q:":                            "Se encontraron %d herramientas que coinciden con %q:",
+	" (select_toolset with %s to use it)":                    " (usa select_toolset con %s para utilizarla)",
+	"Result withheld: it contains sensitive data (%s)":       "Resultado retenido: contiene datos sensibles (%s)",
+	"Output withheld: this session used up its output budget of %s. Read it with get_output_page and history_id %s": "Salida retenida: esta sesión agotó su presupuesto de salida de %s. Léela con get_output_page y history_id %s",
+
+	// validate
+	"✓ Configuration file is valid: %s\n": "✓ El archivo de configuración es válido: %s\n",
+	"\nConfiguration summary:\n":          "\nResumen de la configuración:\n",
+	"  Application: %s\n":                 "  Aplicación: %s\n",
+	"  Transport: %s\n":                   "  Transporte: %s\n",
+	"  Tool prefix: %s\n":                 "  Prefijo de herramientas: %s\n",
+	"  Commands: %d defined\n":            "  Comandos: %d definidos\n",
+	"  Script tools: %d defined\n":        "  Herramientas de script: %d definidas\n",
+	"  Command catalog: %s\n":             "  Catálogo de comandos: %s\n",
+	"\n  Configured commands:\n":          "\n  Comandos configurados:\n",
+	"\n  Security settings:\n":            "\n  Seguridad:\n",
+	"    Max command length: %d\n":        "    Longitud máxima del comando: %d\n",
+	"    Shell expansion disabled: %v\n":  "    Expansión de shell desactivada: %v\n",
+	"    Blocked commands: %d\n":          "    Comandos bloqueados: %d\n",
+	"    Allowed commands: %d\n":          "    Comandos permitidos: %d\n",
+	"    Allowed paths: %d\n":             "    Rutas permitidas: %d\n",
+	"    Denied paths: %d\n":              "    Rutas denegadas: %d\n",
+	"\n  Execution limits:\n":             "\n  Límites de ejecución:\n",
+	"    Default timeout: %s\n":           "    Tiempo límite predeterminado: %s\n",
+	"    Max timeout: %s\n":               "    Tiempo límite máximo: %s\n",
+	"    Max concurrent: %d\n":            "    Máximo de ejecuciones simultáneas: %d\n",
+	"    Max output size: %d bytes\n":     "    Tamaño máximo de salida: %d bytes\n",
+	"    Spill threshold: %d bytes\n":     "    Umbral de volcado a archivo: %d bytes\n",
+	"\n  Schedules:\n":                    "\n  Programaciones:\n",
+	"\n  Warning: logging.output stdout would corrupt the stdio transport; the server logs to stderr instead\n": "\n  Aviso: logging.output stdout corrompería el transporte stdio; el servidor registra en stderr en su lugar\n",
+	"\n  Warning: configured commands that cannot run here:\n":                                                  "\n  Advertencia: comandos configurados que no pueden ejecutarse aquí:\n",
+
+	// stats report
+	"Warning: usage.enabled is not set, so the server does not record usage": "Aviso: usage.enabled no está activado, así que el servidor no registra el uso",
+	"Usage in %s: nothing recorded\n":                                        "Uso en %s: no hay nada registrado\n",
+	"Usage in %s from %s to %s\n":                                            "Uso en %s del %s al %s\n",
+	"\nTools:\n":                                                             "\nHerramientas:\n",
+	"\nConfigured commands never called:\n":                                  "\nComandos configurados nunca llamados:\n",
+	"\nCommands:\n":                                                          "\nComandos:\n",
+	"Denial reasons":                                                         "Motivos de denegación",
+	"Validation failures":                                                    "Errores de validación",
+	"TOOL":                                                                   "HERRAMIENTA",
+	"CALLS":                                                                  "LLAMADAS",
+	"FAILED":                                                                 "FALLIDAS",
+	"AVG":                                                                    "MEDIA",
+	"LAST CALL":                                                              "ÚLTIMA LLAMADA",
+	"COMMAND":                                                                "COMANDO",
+	"RUNS":                                                                   "EJECUCIONES",
+	"INVALID":                                                                "INVÁLIDAS",
+	"DENIED":                                                                 "DENEGADAS",
+	"RETURNED":                                                               "DEVUELTO",
+	"\nResources:\n":                                                         "\nRecursos:\n",
+	"RESOURCE":                                                               "RECURSO",
+	"READS":                                                                  "LECTURAS",
+	"LAST READ":                                                              "ÚLTIMA LECTURA",
+}
@@ -0,0 +1,196 @@
+package i18n
+
+// japanese is the Japanese catalog.
+var japanese = map[string]string{
+	// Tool descriptions
+	"Discover available system commands. Use pattern parameter to filter commands (e.g., 'git*', 'npm'). Returns command names, paths, and descriptions; with include_desc, also usage examples and common flags. Results are sorted stably; when truncated, pass next_cursor as cursor to get the next page.": "利用可能なシステムコマンドを検出します。pattern パラメータでコマンドを絞り込めます（例: 'git*'、'npm'）。コマンド名、パス、説明を返します。include_desc を指定すると使用例とよく使うフラグも返します。結果の順序は安定しており、切り詰められた場合は next_cursor を cursor に渡すと次のページを取得できます。",
+	"Execute a system command with optional arguments and working directory. Returns stdout, stderr, and exit code.": "システムコマンドを実行します。引数と作業ディレクトリは省略できます。stdout、stderr、終了コードを返します。",
+	"Execute several commands in one call. Each step has an id and may list depends_on step ids; independent steps run in parallel and a step is skipped if any dependency fails. A step may capture values from its stdout (capture: {name: regex or $.json.path}) that dependent steps reference in args as {{step_id.name}}. Returns per-step results grouped by dependency level.": "複数のコマンドを 1 回の呼び出しで実行します。各ステップは id を持ち、depends_on に他のステップの id を指定できます。独立したステップは並列に実行され、依存先が失敗したステップはスキップされます。ステップは stdout から値を取り出せ（capture: {name: 正規表現または $.json.path}）、依存するステップは args で {{step_id.name}} として参照できます。ステップごとの結果を依存レベル別に返します。",
+	"List configured command schedules with their next run time, and recent scheduled runs (newest first) with exit codes and output. Use schedule to filter by schedule name.":                                                                                                                                                                                                        "設定されたコマンドのスケジュールと次回実行時刻、最近のスケジュール実行（新しい順）の終了コードと出力を一覧表示します。schedule でスケジュール名により絞り込めます。",
+	"Watch a file or directory (absolute path) for changes. Changes are debounced and sent to the client as log notifications; if command names a configured command, it is run on each batch of changes, subject to a rate limit. Returns the watch id for list_watches and stop_watch.":                                                                                              "ファイルまたはディレクトリ（絶対パス）の変更を監視します。変更はまとめられ、ログ通知としてクライアントに送られます。command に設定済みコマンドを指定すると、変更のまとまりごとに実行されます（頻度制限あり）。list_watches と stop_watch で使う監視 id を返します。",
+	"List active file watches with their recent change events and trigger counts.": "有効なファイル監視を、最近の変更イベントと実行回数とともに一覧表示します。",
+	"Stop an active file watch by id.":                                             "有効なファイル監視を id で停止します。",
+	"Check that configured commands can run: that their binaries exist and are executable and their working directories exist. Reports each problem with a hint for installing missing binaries. Commands disabled because they could not run are registered again once they pass.":                                                                                                                                         "設定されたコマンドが実行できるか、つまりバイナリが存在して実行可能であり、作業ディレクトリが存在するかを確認します。各問題を、不足しているバイナリのインストール方法のヒントとともに報告します。実行できないために無効化されたコマンドは、確認に合格すると再び登録されます。",
+	"Read the last lines of a file by absolute path, such as an application log, without tail. Set follow to a duration such as 30s to keep watching: appended lines are sent to the client as log notifications and returned when following ends. Pass the returned offset back to continue where a previous call stopped.":                                                                                                "tail を使わずに、アプリケーションログなどのファイルの最後の行を絶対パスで読み取ります。follow に 30s などの期間を指定すると監視を続け、追記された行はログ通知としてクライアントに送信され、監視の終了時に返されます。返された offset を渡すと、前回の呼び出しが停止した位置から続行します。",
+	"List running processes with pid, parent pid, command line, CPU and memory usage, and start time. Only processes owned by the server's user are shown unless the configuration allows all users. Filter with name; order with sort_by (pid, cpu, memory or start).":                                                                                                                                                     "実行中のプロセスを pid、親 pid、コマンドライン、CPU とメモリの使用量、開始時刻とともに一覧表示します。設定で全ユーザーが許可されていない限り、サーバーのユーザーが所有するプロセスのみ表示されます。name で絞り込み、sort_by（pid、cpu、memory、start）で並べ替えます。",
+	"Get details of a process by pid: name, command line, owner, status, parent pid, CPU and memory usage, and start time.":                                                                                                                                                                                                                                                                                                 "pid でプロセスの詳細を取得します: 名前、コマンドライン、所有者、状態、親 pid、CPU とメモリの使用量、開始時刻。",
+	"List local listening TCP and UDP sockets with the owning process where permissions allow. Use port to find what holds an address that is already in use.":                                                                                                                                                                                                                                                              "待ち受け中のローカル TCP・UDP ソケットを、権限が許す範囲で所有プロセスとともに一覧表示します。port を使うと、すでに使用中のアドレスを何が占有しているかを調べられます。",
+	"Get the environment variables executed commands inherit, after the server's environment policy. Values of sensitive-looking variables (tokens, passwords, keys) are masked. Set command to include a configured command's own variables; filter by name with a glob such as \"GO*\".":                                                                                                                                  "サーバーの環境ポリシー適用後に、実行されるコマンドが引き継ぐ環境変数を取得します。機密情報らしい変数（トークン、パスワード、鍵）の値はマスクされます。command を指定すると設定済みコマンド固有の変数も含めます。\"GO*\" のような glob で名前を絞り込めます。",
+	"Download a URL to an absolute local path without curl or wget. The URL scheme and host must be allowed by the configuration, the file size is limited, and the download is verified against sha256 when given. Existing files are only replaced with overwrite.":                                                                                                                                                       "curl や wget を使わずに URL をローカルの絶対パスへダウンロードします。URL のスキームとホストは設定で許可されている必要があり、ファイルサイズは制限され、sha256 を指定するとダウンロードを検証します。既存のファイルは overwrite を指定した場合のみ置き換えられます。",
+	"Read part of a file by absolute path, starting at offset, up to length bytes (capped by the configuration). Text is returned as is and binary data as base64; eof tells whether the end was reached. Set checksum to get the whole file's SHA-256.":                                                                                                                                                                    "絶対パスで指定したファイルの一部を offset から最大 length バイト（設定による上限あり）読み取ります。テキストはそのまま、バイナリデータは base64 で返します。eof は末尾に達したかを示します。checksum を指定するとファイル全体の SHA-256 を返します。",
+	"Extract a zip or tar.gz archive (absolute path) into a destination directory without tar or unzip. Entries that would land outside the destination are rejected, links are skipped, and entry count and size are limited. Existing files are only replaced with overwrite.":                                                                                                                                            "tar や unzip を使わずに zip または tar.gz アーカイブ（絶対パス）を展開先ディレクトリに展開します。展開先の外に出るエントリは拒否され、リンクはスキップされ、エントリ数とサイズは制限されます。既存のファイルは overwrite を指定した場合のみ置き換えられます。",
+	"Create a zip or tar.gz archive at an absolute path from files and directories (stored under their base names) without tar or zip. Links are skipped, and entry count and size are limited.":                                                                                                                                                                                                                            "tar や zip を使わずに、ファイルとディレクトリ（ベース名で格納）から絶対パスに zip または tar.gz アーカイブを作成します。リンクはスキップされ、エントリ数とサイズは制限されます。",
+	"Get metadata for an absolute path: type, size, mode, modification time, symlink target, and detected MIME type for files.":                                                                                                                                                                                                                                                                                             "絶対パスのメタデータを取得します: 種類、サイズ、モード、更新時刻、シンボリックリンクの参照先、ファイルの場合は検出された MIME タイプ。",
+	"Compute the MD5 and SHA-256 checksums of a file by absolute path without shasum or openssl. Set expected to verify a digest (optionally prefixed with md5: or sha256:).":                                                                                                                                                                                                                                               "shasum や openssl を使わずに、絶対パスで指定したファイルの MD5 と SHA-256 チェックサムを計算します。expected を指定するとダイジェストを検証します（md5: または sha256: の接頭辞も可）。",
+	"Find what takes up disk space under an absolute directory, or under every allowed path when path is omitted, without du or find. Returns the largest files and directories and totals. Symlinks are not followed; max_depth and max_entries bound the walk, and sizes are lower bounds when it stops early.":                                                                                                           "du や find を使わずに、絶対パスのディレクトリ配下（path を省略した場合は許可されたすべてのパス配下）でディスク容量を使っているものを調べます。最も大きいファイルとディレクトリ、および合計を返します。シンボリックリンクはたどらず、max_depth と max_entries で走査を制限します。途中で停止した場合、サイズは下限値です。",
+	"Stop a process the server started, such as a command run by execute_command or one of its children, instead of running kill. Sends SIGTERM, or kills the process with force, and waits a few seconds for it to exit. Other processes cannot be signalled.":                                                                                                                                                             "kill を実行する代わりに、サーバーが起動したプロセス（execute_command で実行したコマンドやその子プロセスなど）を停止します。SIGTERM を送信するか、force で強制終了し、終了するまで数秒待ちます。他のプロセスにはシグナルを送れません。",
+	"Delete a file, directory or symlink by absolute path instead of running rm. The path is moved into the server's trash with a record of where it came from, so restore_path can bring it back until the trash purges it. Only paths inside the allowed paths can be deleted, not the allowed paths themselves.":                                                                                                         "rm を実行する代わりに、絶対パスで指定したファイル、ディレクトリ、シンボリックリンクを削除します。パスは元の場所の記録とともにサーバーのゴミ箱に移動されるため、ゴミ箱から完全に削除されるまで restore_path で復元できます。削除できるのは許可されたパス内のパスのみで、許可されたパス自体は削除できません。",
+	"Restore a path deleted with delete_path from the server's trash, by trash entry ID or by the path it was deleted from (the most recent delete of that path). It is restored where it was deleted from unless another absolute path is given, and never replaces an existing path.":                                                                                                                                     "delete_path で削除したパスを、ゴミ箱のエントリ ID または削除元のパス（そのパスの最新の削除）を指定してサーバーのゴミ箱から復元します。別の絶対パスを指定しない限り削除元に復元され、既存のパスを置き換えることはありません。",
+	"List the paths in the server's trash, newest first, with their trash entry IDs, original paths, sizes and deletion times.":                                                                                                                                                                                                                                                                                             "サーバーのゴミ箱にあるパスを新しい順に、エントリ ID、元のパス、サイズ、削除日時とともに一覧表示します。",
+	"Change the permissions of a file or directory by absolute path instead of running chmod, with an octal mode such as 0755 or symbolic clauses such as u+x,go-w. Only paths inside the allowed paths can be changed; setuid, setgid and sticky bits cannot be set and symlinks are not followed.":                                                                                                                        "chmod を実行する代わりに、絶対パスで指定したファイルやディレクトリのパーミッションを、0755 のような 8 進モードや u+x,go-w のようなシンボリック指定で変更します。変更できるのは許可されたパス内のパスのみです。setuid、setgid、sticky ビットは設定できず、シンボリックリンクはたどりません。",
+	"Show a native desktop notification to the user, e.g. when a long-running task finishes or needs attention. Notifications are rate limited; use sparingly.":                                                                                                                                                                                                                                                             "長時間のタスクが終わったときや対応が必要なときなどに、ユーザーにデスクトップ通知を表示します。通知には頻度制限があるため、控えめに使ってください。",
+	"Revert the most recent file change made by download_file, extract_archive or create_archive: replaced files are restored from the server's backups and created files are removed. Call repeatedly to step further back. Files too large to back up are reported as skipped.":                                                                                                                                           "download_file、extract_archive、create_archive による直近のファイル変更を元に戻します。置き換えられたファイルはサーバーのバックアップから復元され、作成されたファイルは削除されます。繰り返し呼び出すとさらに前に戻ります。バックアップするには大きすぎたファイルはスキップとして報告されます。",
+	"Remove the macOS quarantine attribute (com.apple.quarantine) of a downloaded binary, by command name or path, so Gatekeeper stops refusing to run it. Only allowed commands can be cleared. Requires approval by two operators: the first call creates an approval request and fails with its ID; call again with approval_id once it is approved.":                                                                    "ダウンロードしたバイナリの macOS の隔離属性 (com.apple.quarantine) をコマンド名またはパスで削除し、Gatekeeper が実行を拒否しないようにします。許可されたコマンドのみ解除できます。2 人のオペレーターの承認が必要です。最初の呼び出しで承認リクエストが作成され、その ID とともに失敗します。承認後に approval_id を指定して再度呼び出してください。",
+	"List the Docker containers the configuration allows tools to touch, with ID, name, image, state and status. Only running containers are listed unless all is set.":                                                                                                                                                                                                                                                     "ツールによる操作が設定で許可された Docker コンテナを、ID、名前、イメージ、状態、ステータスとともに一覧表示します。all を指定しない限り、実行中のコンテナのみ表示します。",
+	"Read the recent stdout and stderr of an allowed Docker container by name or ID: the last tail lines (capped by the configuration), optionally only since a timestamp or duration such as 10m, with timestamps if requested.":                                                                                                                                                                                           "許可された Docker コンテナの最近の標準出力と標準エラーを名前または ID で読み取ります。末尾 tail 行（設定で上限あり）を返し、10m のようなタイムスタンプまたは期間以降に限定でき、要求に応じてタイムスタンプを付けます。",
+	"Run a command in an allowed running Docker container and wait for it, returning stdout, stderr and the exit code. command is the program and its arguments, run without a shell and checked against the security policy like execute_command, including approvals (pass approval_id once approved); it counts as mutating for the operator switches. Only available when the configuration allows exec.":               "許可された実行中の Docker コンテナでコマンドを実行して終了を待ち、stdout、stderr、終了コードを返します。command はプログラムとその引数で、シェルを介さずに実行され、execute_command と同様に承認を含めてセキュリティポリシーで検査されます（承認後は approval_id を渡します）。オペレーターのスイッチでは変更を伴うコマンドとして扱われます。設定で exec が許可されている場合のみ利用できます。",
+	"Start a long-lived interactive program, such as a REPL or dev server, in a new detached tmux session owned by the server. command is the program and its arguments, checked against the security policy like execute_command, including approvals (pass approval_id once approved). Type into it with send_tmux_keys and read its screen with capture_tmux_pane; the screen remains readable after the program exits.": "REPL や開発サーバーなどの長時間動作する対話型プログラムを、サーバーが所有する新しいデタッチされた tmux セッションで起動します。command はプログラムとその引数で、execute_command と同様に承認を含めてセキュリティポリシーで検査されます（承認後は approval_id を渡します）。send_tmux_keys で入力し、capture_tmux_pane で画面を読み取ります。プログラムの終了後も画面は読み取れます。",
+	"List the tmux sessions owned by the server with the program each runs, its pid, and whether it exited and with which status.":                                                                                                                                                                                                                                                                                          "サーバーが所有する tmux セッションを、それぞれが実行するプログラム、pid、終了したかどうかとその終了ステータスとともに一覧表示します。",
+	"Type into a tmux session created by create_tmux_session: text is typed as is, then keys are pressed by tmux name (such as C-c, Up or Escape), then Enter if enter is set. With wait, such as 2s, the screen is captured after waiting and returned. Keys are not checked against the security policy: the session's program can do whatever it allows, so a shell in a session is an unrestricted shell.":              "create_tmux_session で作成した tmux セッションに入力します。text をそのまま入力し、次に keys を tmux のキー名（C-c、Up、Escape など）で押し、enter を指定した場合は Enter を押します。2s のように wait を指定すると、待機後に画面をキャプチャして返します。キーはセキュリティポリシーで検査されません。セッションのプログラムは許す限り何でも実行できるため、セッション内のシェルは制限のないシェルです。",
+	"Read the screen of a tmux session with up to lines lines of scrollback (capped by the configuration), and whether its program exited and with which status.":                                                                                                                                                                                                                                                           "tmux セッションの画面を最大 lines 行のスクロールバック（設定で上限あり）とともに読み取り、プログラムが終了したかどうかとその終了ステータスを返します。",
+	"End a tmux session created by create_tmux_session and the program running in it.": "create_tmux_session で作成した tmux セッションと、その中で実行中のプログラムを終了します。",
+	"Start an interactive interpreter that keeps its state between calls, so data can be loaded once and explored over many send_to_repl calls instead of re-running scripts. interpreter names a configured interpreter (python, node and psql by default); args are appended to its configured arguments, and the interpreter is checked against the security policy like execute_command, including approvals (pass approval_id once approved). Returns a session ID and the banner; idle sessions are stopped after a while.": "呼び出し間で状態を保持する対話型インタープリターを起動します。データを一度読み込み、スクリプトを再実行する代わりに何度も send_to_repl で探索できます。interpreter は設定済みのインタープリター名（デフォルトでは python、node、psql）です。args は設定済みの引数の後に追加され、インタープリターは execute_command と同様に承認を含めてセキュリティポリシーで検査されます（承認後は approval_id を渡します）。セッション ID とバナーを返します。アイドル状態のセッションは一定時間後に停止されます。",
+	"Send input to a session started by start_repl and return its output. Input is sent a line at a time, each waiting for the interpreter's prompt, so multi-line blocks work as typed; end Python blocks with an empty line. If no prompt comes within timeout (default from the configuration), the output so far is returned, the remaining lines are not sent, and the next call first waits for the prompt; interrupt sends Ctrl-C before the input.":                                                                       "start_repl で開始したセッションに入力を送り、その出力を返します。入力は 1 行ずつ送られ、そのたびにインタープリターのプロンプトを待つため、複数行のブロックも入力どおりに動作します。Python のブロックは空行で終えてください。timeout（デフォルトは設定値）内にプロンプトが現れない場合は、それまでの出力を返し、残りの行は送らず、次の呼び出しはまずプロンプトを待ちます。interrupt を指定すると入力の前に Ctrl-C を送ります。",
+	"Stop a session started by start_repl and its interpreter, discarding its state.": "start_repl で開始したセッションとそのインタープリターを停止し、状態を破棄します。",
+	"Send an HTTP request to an allowed host instead of running curl, and return the status, headers and body. Only https is used unless the configuration allows http, certificates are always verified, and the method must be allowed. Credentials configured for the host are added by the server and redacted from the response, so never pass tokens in headers. Bodies are limited in size; binary responses are returned as base64.": "curl を実行する代わりに、許可されたホストへ HTTP リクエストを送り、ステータス、ヘッダー、本文を返します。設定で http が許可されていない限り https のみを使用し、証明書は常に検証され、メソッドは許可されたものである必要があります。ホスト用に設定された認証情報はサーバーが追加し、レスポンスから伏せられるため、ヘッダーでトークンを渡さないでください。本文のサイズは制限され、バイナリのレスポンスは base64 で返されます。",
+	"Explain whether the security policy would allow a command with the given args and workdir, without running it. Lists every rule in evaluation order (command length, workdir, blocked commands, allowed commands, denied paths, allowed paths, shell metacharacters, time window and run count conditions) with its outcome, and marks the first rule that denies.":                                                                     "指定した args と workdir のコマンドをセキュリティポリシーが許可するかを、実行せずに説明します。すべてのルールを評価順（コマンド長、workdir、ブロックされたコマンド、許可されたコマンド、拒否されたパス、許可されたパス、シェルのメタ文字、時間帯と実行回数の条件）に結果とともに列挙し、最初に拒否したルールを示します。",
+	"Describe the limits of the server (default and maximum timeout, output size, concurrent runs, command length, batch steps, watches and downloads), a summary of its security policy and the optional features that are enabled, to plan commands within them.":                                                                                                                                                                          "サーバーの制限（既定と最大のタイムアウト、出力サイズ、同時実行数、コマンド長、バッチのステップ数、監視数、ダウンロード）、セキュリティポリシーの概要、有効なオプション機能を説明し、その範囲内でコマンドを計画できるようにします。",
+	"Server events": "サーバーイベント",
+	"Recent server lifecycle and policy events, oldest first: tools registered and removed, command catalog updates, executions denied and limits reached. New events are sent as info log messages from the events logger.": "サーバーのライフサイクルとポリシーに関する最近のイベントを古い順に示します: 登録・削除されたツール、コマンドカタログの更新、拒否された実行、到達した制限。新しいイベントは events ロガーから info レベルのログメッセージとして送信されます。",
+	"Server configuration summary": "サーバー設定の概要",
+	"What this server will and won't do: its tools, configured commands, security profile, allowed paths and limits, without secrets.":                                                                                                                                                                                                                                                                                                                                      "このサーバーが行うことと行わないこと: ツール、設定済みコマンド、セキュリティプロファイル、許可されたパス、制限を、秘密情報を含めずに示します。",
+	"List the configured tool groups with their description and tools, marking the groups selected in this session. Tools of unselected groups are hidden from the tool list; use select_toolset to select groups.":                                                                                                                                                                                                                                                         "設定されたツールグループを説明とツールとともに一覧表示し、このセッションで選択されているグループに印を付けます。選択されていないグループのツールはツール一覧に表示されません。グループの選択には select_toolset を使います。",
+	"Select the tool groups whose tools are listed in this session, replacing the current selection; an empty list hides all grouped tools. Clients are notified to list tools again. See list_tool_groups for the available groups.":                                                                                                                                                                                                                                       "このセッションで一覧表示するツールのグループを選択し、現在の選択を置き換えます。空のリストはグループに属するすべてのツールを非表示にします。クライアントにはツールを再取得するよう通知されます。利用できるグループは list_tool_groups を参照してください。",
+	"Search the available tools by keywords matched against their names, descriptions and tool groups, e.g. 'integration tests'. Returns the best matching tools first, including those of unselected tool groups.":                                                                                                                                                                                                                                                         "名前、説明、ツールグループに対するキーワードで利用可能なツールを検索します（例: 'integration tests'）。最も一致するツールから順に返し、選択されていないツールグループのツールも含みます。",
+	"Compare two recorded executions by their history_id: returns the metadata that changed (command, args, exit code, duration, ...) and a line-based diff of their stdout and stderr, without resending both outputs. Useful to check what changed after a fix.":                                                                                                                                                                                                          "記録された 2 つの実行を history_id で比較します。変更されたメタデータ（コマンド、引数、終了コード、実行時間など）と、stdout と stderr の行単位の差分を、両方の出力を再送せずに返します。修正後に何が変わったかを確認するのに便利です。",
+	"Read the output of a recorded execution by its history_id one page of lines at a time, including output beyond what the result returned when it was spilled to a file. Start at a line offset, limit the page with max_lines and max_bytes, and pass a regular expression as pattern to only return matching lines, e.g. to jump to the errors of a huge log. Lines carry their numbers; pass next_offset as offset to continue.":                                      "記録された実行の出力を history_id で指定し、1 ページ分の行ずつ読み取ります。ファイルに退避された場合は、結果で返された範囲を超える出力も読み取れます。行の offset から開始し、max_lines と max_bytes でページを制限し、pattern に正規表現を渡すと一致する行だけを返します（例: 巨大なログのエラー部分へ移動する）。各行には行番号が付きます。続きを読むには next_offset を offset に渡してください。",
+	"Run an execution recorded in the history again by its history_id, optionally with extra_args appended, another workdir or a different timeout, e.g. to run a failed command again with -v. The re-run goes through the same policy as a new call and is recorded with its own history_id.":                                                                                                                                                                             "履歴に記録された実行を history_id で指定して再実行します。extra_args の追加、別の workdir、別の timeout を指定できます（例: 失敗したコマンドを -v 付きで再実行する）。再実行は新しい呼び出しと同じポリシーを通り、独自の history_id で記録されます。",
+	"Get the status of the git repository containing an absolute workdir as JSON: branch, commit, upstream with ahead and behind counts, and the changed files with their staged and unstaged change (added, modified, deleted, renamed, ...), untracked and conflicted files. Files outside the allowed paths are left out.":                                                                                                                                               "絶対パスの workdir を含む git リポジトリの状態を JSON で取得します: ブランチ、コミット、upstream と先行・遅れているコミット数、変更されたファイルとそのステージ済み・未ステージの変更（added、modified、deleted、renamed など）、未追跡およびコンフリクトしたファイル。許可されたパス外のファイルは除外されます。",
+	"Get the changes of the git repository containing an absolute workdir as JSON: the changed files with their change kind, added and deleted line counts and hunks of lines. Diffs the working tree against the index by default; set staged to diff the index against HEAD, or base to compare with a revision. Untracked files are not included.":                                                                                                                       "絶対パスの workdir を含む git リポジトリの変更を JSON で取得します: 変更されたファイルとその変更の種類、追加・削除された行数、行のハンク。既定では作業ツリーとインデックスを比較します。staged を指定するとインデックスと HEAD を、base を指定するとリビジョンと比較します。未追跡ファイルは含まれません。",
+	"List the commits of the git repository containing an absolute workdir as JSON, newest first: hash, parents, author, dates, subject and body, and with files the files each commit changed. Set revision to a branch or range such as main..HEAD, paths to only list commits changing them, and skip to page through history.":                                                                                                                                          "絶対パスの workdir を含む git リポジトリのコミットを新しい順に JSON で一覧表示します: ハッシュ、親、作成者、日付、件名、本文、files を指定すると各コミットが変更したファイル。revision でブランチや main..HEAD のような範囲を、paths でそれらを変更したコミットだけを、skip で履歴をページ送りします。",
+	"Run the tests of the project containing an absolute workdir and get the failures as JSON: test name, file, line and message, with passed, failed and skipped counts. The framework (go, cargo, npm or pytest) is detected from go.mod, Cargo.toml, package.json or the pytest configuration unless given; set filter to a name pattern to run some of them. The run goes through the same policy as execute_command, and its raw output is recorded under history_id.": "絶対パスの workdir を含むプロジェクトのテストを実行し、失敗を JSON で取得します: テスト名、ファイル、行、メッセージと、成功・失敗・スキップの件数。フレームワーク (go、cargo、npm、pytest) は指定しない限り go.mod、Cargo.toml、package.json または pytest の設定から検出されます。一部だけを実行するには filter に名前のパターンを指定します。実行は execute_command と同じポリシーを通り、生の出力は history_id で記録されます。",
+	"Run the linter of the project containing an absolute workdir and get its diagnostics as JSON: file, line, column, severity, rule and message. The linter (golangci-lint, go-vet, clippy, eslint, ruff or flake8) is detected from the project's configuration unless given; set paths to lint some files or directories. The run goes through the same policy as execute_command, and its raw output is recorded under history_id.":                                    "絶対パスの workdir を含むプロジェクトの linter を実行し、診断を JSON で取得します: ファイル、行、列、重大度、ルール、メッセージ。linter (golangci-lint、go-vet、clippy、eslint、ruff、flake8) は指定しない限りプロジェクトの設定から検出されます。一部のファイルやディレクトリだけを対象にするには paths を指定します。実行は execute_command と同じポリシーを通り、生の出力は history_id で記録されます。",
+	"Format the code of the project containing an absolute workdir and get the changes as unified diffs per file. The formatter (gofmt, rustfmt, prettier, ruff or black) is detected from the project's configuration unless given; set paths to format some files or directories, and check to only list the files that would change. Only files inside the allowed paths are changed, and the runs go through the same policy as execute_command.":                       "絶対パスの workdir を含むプロジェクトのコードを整形し、変更をファイルごとの unified diff で取得します。フォーマッター (gofmt、rustfmt、prettier、ruff、black) は指定しない限りプロジェクトの設定から検出されます。一部のファイルやディレクトリだけを整形するには paths を、変更されるファイルを一覧するだけなら check を指定します。変更されるのは許可されたパス内のファイルだけで、実行は execute_command と同じポリシーを通ります。",
+	" Requires approval by two operators: the first call creates an approval request and fails with its ID; call again with approval_id once it is approved.":                                                                                                                                                                                                                                                                                                               " 2 人のオペレーターによる承認が必要です。最初の呼び出しで承認リクエストが作成され、その ID とともに失敗します。承認されたら approval_id を指定して再度呼び出してください。",
+
+	// Policy denials
+	"command not allowed: %s":                      "許可されていないコマンド: %s",
+	"; use the %s tool instead":                    "。代わりに %s ツールを使用してください",
+	"path denied: %s":                              "拒否されたパス: %s",
+	"path not allowed: %s":                         "許可されていないパス: %s",
+	"potentially dangerous character detected: %s": "危険な可能性のある文字を検出: %s",
+	" (recorded for policy review)":                "（ポリシー見直し用に記録済み）",
+	"force requires security.allow_force_unlock":   "force には security.allow_force_unlock が必要です",
+	"command requires an authenticated user":       "このコマンドには認証済みユーザーが必要です",
+	"user %s may not run this command":             "ユーザー %s はこのコマンドを実行できません",
+	"denied by condition %s: %s":                   "条件 %s により拒否: %s",
+	"denied by cli policy %s: %s":                  "CLI ポリシー %s により拒否: %s",
+	"%s %s held by package policy: %s":             "%s %s はパッケージポリシーにより保留: %s",
+	"; next allowed at %s":                         "; 次に許可されるのは %s",
+	"denied by plugin %s: %s":                      "プラグイン %s により拒否されました: %s",
+	"command requires approval by %d operators; created approval request %s. " +
+		"Operators approve with: simple-mcp-runner approvals approve %s. " +
+		"Run again with approval_id %s once approved": "このコマンドには %d 人のオペレーターの承認が必要です。承認リクエスト %s を作成しました。" +
+		"オペレーターは simple-mcp-runner approvals approve %s で承認します。" +
+		"承認後に approval_id %s を指定して再実行してください",
+	"%s is quarantined by macOS and Gatekeeper may refuse to run it; remove the quarantine with: xattr -d %s %s":  "%s は macOS により隔離されており、Gatekeeper が実行を拒否する可能性があります。次のコマンドで隔離を解除してください: xattr -d %s %s",
+	"%s is quarantined by macOS and Gatekeeper may refuse to run it; remove the quarantine with clear_quarantine": "%s は macOS により隔離されており、Gatekeeper が実行を拒否する可能性があります。clear_quarantine で隔離を解除してください",
+	"clear_quarantine requires security.allow_clear_quarantine":                                                   "clear_quarantine には security.allow_clear_quarantine が必要です",
+	"command not found: %s":                                       "コマンドが見つかりません: %s",
+	"%s is not quarantined":                                       "%s は隔離されていません",
+	"failed to clear quarantine of %s":                            "%s の隔離を解除できませんでした",
+	"workdir not found: %s":                                       "作業ディレクトリが見つかりません: %s",
+	"%s is not inside a git repository":                           "%s は git リポジトリ内にありません",
+	"no project marker (%s) in %s or above":                       "プロジェクトマーカー (%s) が %s とその上位にありません",
+	"executions are paused by an operator":                        "実行はオペレーターによって一時停止されています",
+	"not enough free disk space in %s: %s available, %s required": "%s の空きディスク容量が不足しています: 空き %s、必要 %s",
+	"mutating commands are blocked by an operator":                "変更を伴うコマンドはオペレーターによってブロックされています",
+	"killed by an operator":                                       "オペレーターによって強制終了されました",
+	"flagged by screening (%s): %s":                               "スクリーニングによりフラグ付け (%s): %s",
+
+	// Tool results
+	"Script failed: %s":            "スクリプトが失敗しました: %s",
+	"\n\nScript log:\n%s":          "\n\nスクリプトのログ:\n%s",
+	"Command execution failed: %s": "コマンドの実行に失敗しました: %s",
+	"Batch execution failed: %s":   "バッチの実行に失敗しました: %s",
+	"Command executed successfully.\nStdout: %s\nStderr: %s\nExit Code: %d":        "コマンドを実行しました。\nStdout: %s\nStderr: %s\n終了コード: %d",
+	"Tool %s is not selected: call select_toolset with one of the groups %s first": "ツール %s は選択されていません。先に select_toolset をグループ %s のいずれかで呼び出してください",
+	"Unknown tool group: %s":                                         "不明なツールグループ: %s",
+	"Unknown execution: %s":                                          "不明な実行です: %s",
+	"Invalid timeout: %s":                                            "無効なタイムアウトです: %s",
+	"Command %s changed since execution %s":                          "コマンド %s は実行 %s 以降に変更されています",
+	"Command %s does not accept arguments":                           "コマンド %s は引数を受け付けません",
+	"Execution %s cannot be re-run: %s is not enabled":               "実行 %s は再実行できません: %s が有効ではありません",
+	"Execution %s cannot be re-run":                                  "実行 %s は再実行できません",
+	"Re-run of execution %s":                                         "実行 %s の再実行",
+	"workdir must be an absolute path":                               "workdir は絶対パスである必要があります",
+	"Could not detect the test framework: %s":                        "テストフレームワークを検出できませんでした: %s",
+	"Tests timed out after %s":                                       "テストは %s 後にタイムアウトしました",
+	"Tests passed":                                                   "テストに合格しました",
+	"Tests failed with exit code %d":                                 "テストは終了コード %d で失敗しました",
+	"No failures could be parsed; the raw output is in execution %s": "失敗を解析できませんでした。生の出力は実行 %s にあります",
+	"Only the start and end of the output were parsed; the whole output is in execution %s": "出力の先頭と末尾のみを解析しました。出力全体は実行 %s にあります",
+	"Invalid path: %s":                                       "無効なパスです: %s",
+	"Could not detect the linter: %s":                        "linter を検出できませんでした: %s",
+	"Could not detect the formatter: %s":                     "フォーマッターを検出できませんでした: %s",
+	"No problems found by %s":                                "%s は問題を検出しませんでした",
+	"%s failed with exit code %d":                            "%s は終了コード %d で失敗しました",
+	"%d problems found by %s":                                "%d 件の問題が %s で見つかりました",
+	"%s failed":                                              "%s が失敗しました",
+	"All files are formatted according to %s":                "すべてのファイルは %s に従って整形されています",
+	"%d files would be reformatted by %s":                    "%d 個のファイルが %s で再整形されます",
+	"%d files reformatted by %s":                             "%d 個のファイルを %s で再整形しました",
+	"Working directory to run the command in":                "コマンドを実行する作業ディレクトリ",
+	"Arguments appended to the command, separated by spaces": "コマンドに追加する引数（スペース区切り）",
+	"Call the %s tool with the arguments %s.":                "%s ツールを次の引数で呼び出してください: %s",
+	"Invalid stream %q: must be stdout or stderr":            "無効なストリーム %q です: stdout か stderr を指定してください",
+	"Invalid pattern: %s":                                    "無効なパターンです: %s",
+	"Execution %s has no output":                             "実行 %s には出力がありません",
+	"Failed to read output: %s":                              "出力を読み取れませんでした: %s",
+	"No tools match %q":                                      "%q に一致するツールはありません",
+	"Found %d tools matching %q:":                            "%d 個のツールが %q に一致しました:",
+	" (select_toolset with %s to use it)":                    "（使用するには select_toolset で %s を選択してください）",
+	"Result withheld: it contains sensitive data (%s)":       "結果を保留しました: 機密データが含まれています (%s)",
+	"Output withheld: this session used up its output budget of %s. Read it with get_output_page and history_id %s": "出力を保留しました: このセッションは出力予算 %s を使い切りました。get_output_page と history_id %s で読んでください",
+
+	// validate
+	"✓ Configuration file is valid: %s\n": "✓ 設定ファイルは有効です: %s\n",
+	"\nConfiguration summary:\n":          "\n設定の概要:\n",
+	"  Application: %s\n":                 "  アプリケーション: %s\n",
+	"  Transport: %s\n":                   "  トランスポート: %s\n",
+	"  Tool prefix: %s\n":                 "  ツール名の接頭辞: %s\n",
+	"  Commands: %d defined\n":            "  コマンド: %d 件定義\n",
+	"  Script tools: %d defined\n":        "  スクリプトツール: %d 件定義済み\n",
+	"  Command catalog: %s\n":             "  コマンドカタログ: %s\n",
+	"\n  Configured commands:\n":          "\n  設定済みコマンド:\n",
+	"\n  Security settings:\n":            "\n  セキュリティ設定:\n",
+	"    Max command length: %d\n":        "    コマンドの最大長: %d\n",
+	"    Shell expansion disabled: %v\n":  "    シェル展開の無効化: %v\n",
+	"    Blocked commands: %d\n":          "    ブロックされたコマンド: %d\n",
+	"    Allowed commands: %d\n":          "    許可されたコマンド: %d\n",
+	"    Allowed paths: %d\n":             "    許可されたパス: %d\n",
+	"    Denied paths: %d\n":              "    拒否されたパス: %d\n",
+	"\n  Execution limits:\n":             "\n  実行の制限:\n",
+	"    Default timeout: %s\n":           "    既定のタイムアウト: %s\n",
+	"    Max timeout: %s\n":               "    最大タイムアウト: %s\n",
+	"    Max concurrent: %d\n":            "    最大同時実行数: %d\n",
+	"    Max output size: %d bytes\n":     "    最大出力サイズ: %d バイト\n",
+	"    Spill threshold: %d bytes\n":     "    ファイル退避のしきい値: %d バイト\n",
+	"\n  Schedules:\n":                    "\n  スケジュール:\n",
+	"\n  Warning: logging.output stdout would corrupt the stdio transport; the server logs to stderr instead\n": "\n  警告: logging.output を stdout にすると stdio トランスポートが壊れるため、サーバーは代わりに stderr に記録します\n",
+	"\n  Warning: configured commands that cannot run here:\n":                                                  "\n  警告: ここでは実行できない設定済みコマンド:\n",
+
+	// stats report
+	"Warning: usage.enabled is not set, so the server does not record usage": "警告: usage.enabled が設定されていないため、サーバーは利用状況を記録しません",
+	"Usage in %s: nothing recorded\n":                                        "%s の利用状況: 記録なし\n",
+	"Usage in %s from %s to %s\n":                                            "%s の利用状況（%s から %s まで）\n",
+	"\nTools:\n":                                                             "\nツール:\n",
+	"\nConfigured commands never called:\n":                                  "\n一度も呼ばれていない設定済みコマンド:\n",
+	"\nCommands:\n":                                                          "\nコマンド:\n",
+	"Denial reasons":                                                         "拒否の理由",
+	"Validation failures":                                                    "検証エラー",
+	"TOOL":                                                                   "ツール",
+	"CALLS":                                                                  "呼び出し",
+	"FAILED":                                                                 "失敗",
+	"AVG":                                                                    "平均",
+	"LAST CALL":                                                              "最終呼び出し",
+	"COMMAND":                                                                "コマンド",
+	"RUNS":                                                                   "実行",
+	"INVALID":                                                                "無効",
+	"DENIED":                                                                 "拒否",
+	"RETURNED":                                                               "返却量",
+	"\nResources:\n":                                                         "\nリソース:\n",
+	"RESOURCE":                                                               "リソース",
+	"READS":                                                                  "読み取り",
+	"LAST READ":                                                              "最終読み取り",
+}
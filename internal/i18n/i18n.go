@@ -0,0 +1,86 @@
+// Package i18n translates tool descriptions, policy denial messages and
+// CLI output. Messages are looked up by their English text, so a message
+// missing from a catalog is shown in English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// EnvVar selects the locale, overriding the configuration.
+const EnvVar = "SIMPLE_MCP_RUNNER_LOCALE"
+
+// English is the default locale, which messages are written in.
+const English = "en"
+
+// catalogs map English messages to their translations, by locale.
+var catalogs = map[string]map[string]string{
+	"es": spanish,
+	"ja": japanese,
+}
+
+// Printer formats messages in a locale. A nil Printer prints English.
+type Printer struct {
+	locale  string
+	catalog map[string]string
+}
+
+// New returns a printer for the locale resolved from the configured one.
+func New(configured string) *Printer {
+	locale := Resolve(configured)
+	return &Printer{locale: locale, catalog: catalogs[locale]}
+}
+
+// Resolve returns the supported locale to use: the one named by EnvVar or
+// else configured, where "auto" follows LC_ALL, LC_MESSAGES and LANG.
+// Unsupported and unset locales resolve to English.
+func Resolve(configured string) string {
+	name := configured
+	if env := os.Getenv(EnvVar); env != "" {
+		name = env
+	}
+	if name == config.LocaleAuto {
+		name = ""
+		for _, v := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+			if name = os.Getenv(v); name != "" {
+				break
+			}
+		}
+	}
+	if locale, ok := config.SupportedLocale(name); ok {
+		return locale
+	}
+	return English
+}
+
+// Locale returns the locale of the printer.
+func (p *Printer) Locale() string {
+	if p == nil {
+		return English
+	}
+	return p.locale
+}
+
+// T returns the translation of msg, or msg if there is none.
+func (p *Printer) T(msg string) string {
+	if p == nil {
+		return msg
+	}
+	if t, ok := p.catalog[msg]; ok {
+		return t
+	}
+	return msg
+}
+
+// Sprintf formats the translation of format.
+func (p *Printer) Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(p.T(format), args...)
+}
+
+// Printf prints the translation of format to standard output.
+func (p *Printer) Printf(format string, args ...any) {
+	fmt.Print(p.Sprintf(format, args...))
+}
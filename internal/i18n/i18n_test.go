@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+var verbs = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestCatalogs(t *testing.T) {
+	for _, locale := range config.Locales {
+		if _, ok := catalogs[locale]; !ok && locale != English {
+			t.Errorf("no catalog for supported locale %s", locale)
+		}
+	}
+
+	for locale, catalog := range catalogs {
+		for msg, translation := range catalog {
+			if want, got := verbs.FindAllString(msg, -1), verbs.FindAllString(translation, -1); !slices.Equal(want, got) {
+				t.Errorf("%s translation of %q has verbs %v, want %v", locale, msg, got, want)
+			}
+			for other, otherCatalog := range catalogs {
+				if _, ok := otherCatalog[msg]; !ok {
+					t.Errorf("%q is translated to %s but not to %s", msg, locale, other)
+				}
+			}
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		env        string
+		lang       string
+		want       string
+	}{
+		{"default", "", "", "ja_JP.UTF-8", "en"},
+		{"configured", "es", "", "", "es"},
+		{"region", "es-MX", "", "", "es"},
+		{"env overrides config", "es", "ja", "", "ja"},
+		{"auto", "auto", "", "ja_JP.UTF-8", "ja"},
+		{"auto from env", "", "auto", "es_ES.UTF-8", "es"},
+		{"unsupported", "auto", "", "fr_FR.UTF-8", "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvVar, tt.env)
+			t.Setenv("LC_ALL", "")
+			t.Setenv("LC_MESSAGES", "")
+			t.Setenv("LANG", tt.lang)
+			if got := Resolve(tt.configured); got != tt.want {
+				t.Errorf("Resolve(%q) = %s, want %s", tt.configured, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	t.Setenv(EnvVar, "")
+	p := New("es")
+	if got := p.Sprintf("command not allowed: %s", "rm"); got != "comando no permitido: rm" {
+		t.Errorf("Sprintf() = %q", got)
+	}
+	if got := p.T("not in any catalog"); got != "not in any catalog" {
+		t.Errorf("T() = %q, want the message untranslated", got)
+	}
+
+	var nilPrinter *Printer
+	if got := nilPrinter.Sprintf("path denied: %s", "/etc"); got != "path denied: /etc" {
+		t.Errorf("nil Sprintf() = %q", got)
+	}
+}
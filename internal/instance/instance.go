@@ -0,0 +1,187 @@
+// Package instance keeps two servers using the same configuration from
+// running at once, as they would share audit logs, history and workspaces.
+package instance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// Owner describes the server holding a lock.
+type Owner struct {
+	PID     int       `json:"pid"`
+	Host    string    `json:"host,omitempty"`
+	Config  string    `json:"config"`
+	Started time.Time `json:"started"`
+}
+
+// Lock is an instance lock held by this process.
+type Lock struct {
+	path  string
+	owner Owner
+}
+
+// DefaultDir returns the directory of instance locks used when none is
+// configured.
+func DefaultDir() string {
+	return filepath.Join(os.TempDir(), "simple-mcp-runner", "instances")
+}
+
+// Path returns the lock file of the configuration at config, or of the
+// built-in configuration if config is empty.
+func Path(dir, config string) string {
+	key := "builtin"
+	if config != "" {
+		if abs, err := filepath.Abs(config); err == nil {
+			config = abs
+		}
+		key = config
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".lock")
+}
+
+// Acquire takes the lock of the configuration at config in dir. A lock
+// left by a process that no longer runs is replaced. If another server
+// holds the lock, Acquire fails unless force is set, in which case the
+// lock is taken over.
+func Acquire(dir, config string, force bool) (*Lock, error) {
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to create instance lock directory")
+	}
+
+	host, _ := os.Hostname()
+	l := &Lock{
+		path: Path(dir, config),
+		owner: Owner{
+			PID:     os.Getpid(),
+			Host:    host,
+			Config:  config,
+			Started: time.Now().UTC(),
+		},
+	}
+	data, err := json.Marshal(l.owner)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to encode instance lock")
+	}
+
+	// Retry once after removing a stale or overridden lock; losing that
+	// race to another server is reported as the lock being held
+	for attempt := 0; ; attempt++ {
+		f, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_, werr := f.Write(data)
+			cerr := f.Close()
+			if werr != nil || cerr != nil {
+				os.Remove(l.path)
+				return nil, apperrors.Wrap(errors.Join(werr, cerr), apperrors.ErrorTypeInternal, "failed to write instance lock")
+			}
+			return l, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to create instance lock")
+		}
+
+		owner, err := readOwner(l.path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if attempt > 0 || (err == nil && alive(owner, host) && !force) {
+			return nil, held(l.path, owner)
+		}
+		if err := os.Remove(l.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to remove stale instance lock")
+		}
+	}
+}
+
+// Path returns the lock file.
+func (l *Lock) Path() string {
+	return l.path
+}
+
+// Release removes the lock, unless another server has taken it over.
+func (l *Lock) Release() error {
+	owner, err := readOwner(l.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if owner.PID != l.owner.PID || !owner.Started.Equal(l.owner.Started) {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to remove instance lock")
+	}
+	return nil
+}
+
+// readOwner reads the owner of the lock at path. An unreadable lock, such
+// as one left half written by a crash, has a zero owner.
+func readOwner(path string) (Owner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Owner{}, err
+		}
+		return Owner{}, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read instance lock")
+	}
+	var owner Owner
+	_ = json.Unmarshal(data, &owner)
+	return owner, nil
+}
+
+// alive reports whether the owner of a lock still runs. Locks taken on
+// another host, through a shared directory, are assumed alive. A process
+// started after the lock was taken has reused the PID of its owner.
+func alive(owner Owner, host string) bool {
+	if owner.PID <= 0 {
+		return false
+	}
+	if owner.Host != "" && owner.Host != host {
+		return true
+	}
+	exists, err := process.PidExists(int32(owner.PID))
+	if err != nil || !exists {
+		return err != nil
+	}
+	p, err := process.NewProcess(int32(owner.PID))
+	if err != nil {
+		return true
+	}
+	created, err := p.CreateTime()
+	if err != nil || owner.Started.IsZero() {
+		return true
+	}
+	// Allow for the clock resolution of process start times
+	return time.UnixMilli(created).Before(owner.Started.Add(time.Second))
+}
+
+// held returns the error reporting that the lock at path is held.
+func held(path string, owner Owner) error {
+	msg := "another server is running with this configuration"
+	if owner.PID > 0 {
+		msg += fmt.Sprintf(" (pid %d", owner.PID)
+		if owner.Host != "" {
+			msg += " on " + owner.Host
+		}
+		msg += ", started " + owner.Started.Local().Format(time.RFC3339) + ")"
+	}
+	msg += "; stop it or run with --force to take over " + path
+	return apperrors.ConfigurationError(msg)
+}
@@ -0,0 +1,97 @@
+package instance
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	dir := t.TempDir()
+
+	lock, err := Acquire(dir, "config.yaml", false)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	if _, err := Acquire(dir, "config.yaml", false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected the held lock to be reported, got %v", err)
+	}
+
+	other, err := Acquire(dir, "other.yaml", false)
+	if err != nil {
+		t.Fatalf("expected another configuration to be unaffected, got %v", err)
+	}
+	defer other.Release()
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := os.Stat(lock.Path()); !os.IsNotExist(err) {
+		t.Fatalf("expected the lock to be removed, got %v", err)
+	}
+
+	again, err := Acquire(dir, "config.yaml", false)
+	if err != nil {
+		t.Fatalf("expected a released lock to be free, got %v", err)
+	}
+	again.Release()
+}
+
+func TestAcquire_force(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := Acquire(dir, "", false)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	// Take care the two owners differ in start time
+	time.Sleep(time.Millisecond)
+	second, err := Acquire(dir, "", true)
+	if err != nil {
+		t.Fatalf("Acquire(force) error = %v", err)
+	}
+
+	// Releasing the overridden lock leaves the new one in place
+	if err := first.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := os.Stat(second.Path()); err != nil {
+		t.Fatalf("expected the taken over lock to remain, got %v", err)
+	}
+	second.Release()
+}
+
+func TestAcquire_stale(t *testing.T) {
+	dir := t.TempDir()
+	host, _ := os.Hostname()
+
+	tests := []struct {
+		name  string
+		owner Owner
+	}{
+		// A process started after the lock was taken has reused its PID
+		{"reused pid", Owner{PID: os.Getpid(), Host: host, Started: time.Now().Add(-24 * time.Hour)}},
+		{"missing process", Owner{PID: 1 << 30, Host: host, Started: time.Now()}},
+		{"unreadable", Owner{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := json.Marshal(tt.owner)
+			if tt.owner.PID == 0 {
+				data = []byte("{")
+			}
+			if err := os.WriteFile(Path(dir, "stale.yaml"), data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			lock, err := Acquire(dir, "stale.yaml", false)
+			if err != nil {
+				t.Fatalf("expected the stale lock to be replaced, got %v", err)
+			}
+			lock.Release()
+		})
+	}
+}
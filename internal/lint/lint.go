@@ -0,0 +1,323 @@
+// Package lint detects the linters and formatters a project is configured
+// with, builds the commands running them and parses their output into
+// diagnostics and lists of files to format.
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// Linters.
+const (
+	LinterGolangci = "golangci-lint"
+	LinterGoVet    = "go-vet"
+	LinterClippy   = "clippy"
+	LinterESLint   = "eslint"
+	LinterRuff     = "ruff"
+	LinterFlake8   = "flake8"
+)
+
+// Formatters.
+const (
+	FormatterGofmt    = "gofmt"
+	FormatterRustfmt  = "rustfmt"
+	FormatterPrettier = "prettier"
+	FormatterRuff     = "ruff"
+	FormatterBlack    = "black"
+)
+
+// Linters are the supported linters.
+var Linters = []string{LinterGolangci, LinterGoVet, LinterClippy, LinterESLint, LinterRuff, LinterFlake8}
+
+// Formatters are the supported formatters.
+var Formatters = []string{FormatterGofmt, FormatterRustfmt, FormatterPrettier, FormatterRuff, FormatterBlack}
+
+var (
+	golangciConfigs = []string{".golangci.yml", ".golangci.yaml", ".golangci.toml", ".golangci.json"}
+	eslintConfigs   = []string{
+		"eslint.config.js", "eslint.config.mjs", "eslint.config.cjs", "eslint.config.ts",
+		".eslintrc", ".eslintrc.js", ".eslintrc.cjs", ".eslintrc.json", ".eslintrc.yml", ".eslintrc.yaml",
+	}
+	prettierConfigs = []string{
+		".prettierrc", ".prettierrc.json", ".prettierrc.yml", ".prettierrc.yaml", ".prettierrc.toml",
+		".prettierrc.js", ".prettierrc.cjs", ".prettierrc.mjs",
+		"prettier.config.js", "prettier.config.cjs", "prettier.config.mjs",
+	}
+
+	// cargoEdition matches the edition of a Cargo.toml.
+	cargoEdition = regexp.MustCompile(`(?m)^\s*edition\s*=\s*"(\d+)"`)
+)
+
+// Report is the outcome of a linter run.
+type Report struct {
+	Linter      string       `json:"linter"`
+	Command     string       `json:"command"`
+	Args        []string     `json:"args"`
+	WorkDir     string       `json:"workdir"`
+	Clean       bool         `json:"clean"`
+	ExitCode    int          `json:"exit_code"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+	Truncated   bool         `json:"truncated,omitempty"` // Set when diagnostics were left out
+
+	// Output is the end of the output of a run that failed without
+	// diagnostics, e.g. when the linter is misconfigured
+	Output string `json:"output,omitempty"`
+
+	// HistoryID is the execution holding the raw output
+	HistoryID string        `json:"history_id,omitempty"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// FormatReport is the outcome of a formatter run.
+type FormatReport struct {
+	Formatter string          `json:"formatter"`
+	WorkDir   string          `json:"workdir"`
+	Check     bool            `json:"check"` // Files were listed, not changed
+	Files     []FormattedFile `json:"files"`
+	Truncated bool            `json:"truncated,omitempty"` // Set when files were left out
+
+	// Output is the end of the output of a formatter that failed
+	Output string `json:"output,omitempty"`
+
+	// HistoryIDs are the executions of the check and format commands
+	HistoryIDs []string `json:"history_ids,omitempty"`
+}
+
+// FormattedFile is a file a formatter changed, or would change with
+// check.
+type FormattedFile struct {
+	Path    string `json:"path"` // Relative to the workdir when inside it
+	Diff    string `json:"diff,omitempty"`
+	Added   int    `json:"added,omitempty"`
+	Removed int    `json:"removed,omitempty"`
+	Skipped string `json:"skipped,omitempty"` // Why the file was not formatted
+}
+
+// Tool is a linter or formatter detected for a project.
+type Tool struct {
+	Name string
+	Root string // Directory holding the project or tool configuration
+}
+
+// DetectLinter returns the linter a project is configured with, looking
+// in dir and its parents up to the root of the git repository. Parents
+// outside allowed_paths are not looked in.
+func DetectLinter(cfg *config.Config, dir string) (Tool, error) {
+	tool, ok := detect(cfg, dir, linterIn)
+	if !ok {
+		return Tool{}, apperrors.NotFoundError("no golangci-lint, go, cargo, eslint, ruff or flake8 configuration found", dir)
+	}
+	return tool, nil
+}
+
+// DetectFormatter returns the formatter a project is configured with,
+// looking in dir and its parents like DetectLinter.
+func DetectFormatter(cfg *config.Config, dir string) (Tool, error) {
+	tool, ok := detect(cfg, dir, formatterIn)
+	if !ok {
+		return Tool{}, apperrors.NotFoundError("no go, cargo, prettier, ruff or black configuration found", dir)
+	}
+	return tool, nil
+}
+
+// Root returns the directory of the nearest configuration of a tool
+// given by name, or dir when there is none.
+func Root(cfg *config.Config, dir, name string, formatter bool) string {
+	markers := linterMarkers
+	if formatter {
+		markers = formatterMarkers
+	}
+	tool, ok := detect(cfg, dir, func(d string) string {
+		for _, m := range markers {
+			if m.name == name && m.in(d) {
+				return name
+			}
+		}
+		return ""
+	})
+	if !ok {
+		return dir
+	}
+	return tool.Root
+}
+
+// detect walks up from dir until in names a tool for a directory.
+func detect(cfg *config.Config, dir string, in func(string) string) (Tool, bool) {
+	for d := dir; ; {
+		if name := in(d); name != "" {
+			return Tool{Name: name, Root: d}, true
+		}
+		parent := filepath.Dir(d)
+		if parent == d || config.Exists(filepath.Join(d, ".git")) || !cfg.IsPathAllowed(parent) {
+			return Tool{}, false
+		}
+		d = parent
+	}
+}
+
+// marker tells whether a directory is configured for a tool.
+type marker struct {
+	name string
+	in   func(dir string) bool
+}
+
+// linterMarkers are the linters' configurations, in detection order.
+var linterMarkers = []marker{
+	{LinterGolangci, func(dir string) bool { return anyExists(dir, golangciConfigs) }},
+	{LinterGoVet, func(dir string) bool { return config.Exists(filepath.Join(dir, "go.mod")) }},
+	{LinterClippy, func(dir string) bool { return config.Exists(filepath.Join(dir, "Cargo.toml")) }},
+	{LinterESLint, func(dir string) bool { return anyExists(dir, eslintConfigs) }},
+	{LinterRuff, hasRuff},
+	{LinterFlake8, func(dir string) bool {
+		return config.Exists(filepath.Join(dir, ".flake8")) || contains(filepath.Join(dir, "setup.cfg"), "[flake8]") ||
+			contains(filepath.Join(dir, "tox.ini"), "[flake8]")
+	}},
+}
+
+// formatterMarkers are the formatters' configurations, in detection
+// order.
+var formatterMarkers = []marker{
+	{FormatterGofmt, func(dir string) bool { return config.Exists(filepath.Join(dir, "go.mod")) }},
+	{FormatterRustfmt, func(dir string) bool { return config.Exists(filepath.Join(dir, "Cargo.toml")) }},
+	{FormatterPrettier, func(dir string) bool {
+		return anyExists(dir, prettierConfigs) || contains(filepath.Join(dir, "package.json"), `"prettier"`)
+	}},
+	{FormatterRuff, hasRuff},
+	{FormatterBlack, func(dir string) bool { return contains(filepath.Join(dir, "pyproject.toml"), "[tool.black]") }},
+}
+
+// linterIn returns the linter configured in dir, or "".
+func linterIn(dir string) string {
+	return markedIn(linterMarkers, dir)
+}
+
+// formatterIn returns the formatter configured in dir, or "".
+func formatterIn(dir string) string {
+	return markedIn(formatterMarkers, dir)
+}
+
+// markedIn returns the first tool of markers configured in dir, or "".
+func markedIn(markers []marker, dir string) string {
+	for _, m := range markers {
+		if m.in(dir) {
+			return m.name
+		}
+	}
+	return ""
+}
+
+// hasRuff reports whether ruff is configured in dir.
+func hasRuff(dir string) bool {
+	return config.Exists(filepath.Join(dir, "ruff.toml")) || config.Exists(filepath.Join(dir, ".ruff.toml")) ||
+		contains(filepath.Join(dir, "pyproject.toml"), "[tool.ruff")
+}
+
+// anyExists reports whether one of names exists in dir.
+func anyExists(dir string, names []string) bool {
+	for _, name := range names {
+		if config.Exists(filepath.Join(dir, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether a file contains s.
+func contains(path, s string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(data), s)
+}
+
+// nodeBin returns the binary of a Node.js tool installed in the project,
+// or its name to find it in PATH.
+func nodeBin(root, name string) string {
+	if bin := filepath.Join(root, "node_modules", ".bin", name); config.Exists(bin) {
+		return bin
+	}
+	return name
+}
+
+// targets returns the paths to run on, or def when none are given.
+func targets(paths []string, def string) []string {
+	if len(paths) == 0 {
+		return []string{def}
+	}
+	return paths
+}
+
+// LintCommand returns the command line running a linter on paths, or on
+// the whole project when paths is empty.
+func LintCommand(tool Tool, paths []string) (string, []string, error) {
+	switch tool.Name {
+	case LinterGolangci:
+		return "golangci-lint", append([]string{"run"}, targets(paths, "./...")...), nil
+	case LinterGoVet:
+		return "go", append([]string{"vet"}, targets(paths, "./...")...), nil
+	case LinterClippy:
+		// Clippy lints whole packages
+		return "cargo", []string{"clippy", "--message-format", "short"}, nil
+	case LinterESLint:
+		return nodeBin(tool.Root, "eslint"), append([]string{"--format", "json"}, targets(paths, ".")...), nil
+	case LinterRuff:
+		return "ruff", append([]string{"check", "--output-format", "json"}, targets(paths, ".")...), nil
+	case LinterFlake8:
+		return "flake8", targets(paths, "."), nil
+	}
+	return "", nil, apperrors.ValidationError("unknown linter "+tool.Name+" (must be one of "+strings.Join(Linters, ", ")+")", "linter")
+}
+
+// CheckCommand returns the command line listing the files a formatter
+// would change under paths, or in the whole project when paths is empty.
+func CheckCommand(tool Tool, paths []string) (string, []string, error) {
+	switch tool.Name {
+	case FormatterGofmt:
+		return "gofmt", append([]string{"-l"}, targets(paths, ".")...), nil
+	case FormatterRustfmt:
+		// cargo fmt checks whole packages; ListFiles keeps those under
+		// paths
+		return "cargo", []string{"fmt", "--check"}, nil
+	case FormatterPrettier:
+		return nodeBin(tool.Root, "prettier"), append([]string{"--list-different"}, targets(paths, ".")...), nil
+	case FormatterRuff:
+		return "ruff", append([]string{"format", "--check"}, targets(paths, ".")...), nil
+	case FormatterBlack:
+		return "black", append([]string{"--check"}, targets(paths, ".")...), nil
+	}
+	return "", nil, apperrors.ValidationError("unknown formatter "+tool.Name+" (must be one of "+strings.Join(Formatters, ", ")+")", "formatter")
+}
+
+// FormatCommand returns the command line formatting files in place.
+func FormatCommand(tool Tool, files []string) (string, []string, error) {
+	switch tool.Name {
+	case FormatterGofmt:
+		return "gofmt", append([]string{"-w"}, files...), nil
+	case FormatterRustfmt:
+		return "rustfmt", append([]string{"--edition", edition(tool.Root)}, files...), nil
+	case FormatterPrettier:
+		return nodeBin(tool.Root, "prettier"), append([]string{"--write"}, files...), nil
+	case FormatterRuff:
+		return "ruff", append([]string{"format"}, files...), nil
+	case FormatterBlack:
+		return "black", files, nil
+	}
+	return "", nil, apperrors.ValidationError("unknown formatter "+tool.Name+" (must be one of "+strings.Join(Formatters, ", ")+")", "formatter")
+}
+
+// edition returns the Rust edition of the package in root, rustfmt
+// parsing files as 2015 code otherwise.
+func edition(root string) string {
+	data, err := os.ReadFile(filepath.Join(root, "Cargo.toml"))
+	if err == nil {
+		if m := cargoEdition.FindSubmatch(data); m != nil {
+			return string(m[1])
+		}
+	}
+	return "2021"
+}
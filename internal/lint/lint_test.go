@@ -0,0 +1,141 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func write(t *testing.T, dir, name, data string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "go/go.mod", "module example.com/m\n")
+	write(t, root, "go/.golangci.yml", "linters: {}\n")
+	write(t, root, "go/cmd/main.go", "package main\n")
+	write(t, root, "web/package.json", `{"devDependencies": {"prettier": "3"}}`)
+	write(t, root, "web/eslint.config.js", "export default [];\n")
+	write(t, root, "py/pyproject.toml", "[tool.ruff]\n")
+	write(t, root, "legacy/pyproject.toml", "[tool.black]\n")
+	write(t, root, "legacy/setup.cfg", "[flake8]\nmax-line-length = 100\n")
+	write(t, root, "rust/Cargo.toml", "[package]\nedition = \"2018\"\n")
+
+	cfg := config.Default()
+	tests := []struct {
+		dir       string
+		linter    string
+		formatter string
+	}{
+		{"go/cmd", LinterGolangci, FormatterGofmt},
+		{"web", LinterESLint, FormatterPrettier},
+		{"py", LinterRuff, FormatterRuff},
+		{"legacy", LinterFlake8, FormatterBlack},
+		{"rust", LinterClippy, FormatterRustfmt},
+	}
+	for _, tt := range tests {
+		dir := filepath.Join(root, tt.dir)
+		if tool, err := DetectLinter(cfg, dir); err != nil || tool.Name != tt.linter {
+			t.Errorf("DetectLinter(%s) = %+v, %v, want %s", tt.dir, tool, err, tt.linter)
+		}
+		if tool, err := DetectFormatter(cfg, dir); err != nil || tool.Name != tt.formatter {
+			t.Errorf("DetectFormatter(%s) = %+v, %v, want %s", tt.dir, tool, err, tt.formatter)
+		}
+	}
+
+	if got := Root(cfg, filepath.Join(root, "go", "cmd"), LinterGoVet, false); got != filepath.Join(root, "go") {
+		t.Errorf("Root() = %s, want the module root", got)
+	}
+	if _, args, _ := FormatCommand(Tool{Name: FormatterRustfmt, Root: filepath.Join(root, "rust")}, []string{"src/lib.rs"}); !slices.Equal(args, []string{"--edition", "2018", "src/lib.rs"}) {
+		t.Errorf("FormatCommand(rustfmt) args = %v", args)
+	}
+	if _, _, err := LintCommand(Tool{Name: "pylint"}, nil); err == nil {
+		t.Error("LintCommand() accepted an unknown linter")
+	}
+}
+
+func TestParseLint(t *testing.T) {
+	workdir := filepath.FromSlash("/src/app")
+	tests := []struct {
+		name   string
+		stdout string
+		stderr string
+		want   Diagnostic
+	}{
+		{
+			name:   LinterGoVet,
+			stderr: "# example.com/m\n# [example.com/m]\nvet: ./main.go:5:2: undefined: x\n",
+			want:   Diagnostic{File: "main.go", Line: 5, Column: 2, Severity: SeverityError, Message: "undefined: x"},
+		},
+		{
+			name:   LinterGolangci,
+			stdout: "main.go:12:5: Error return value is not checked (errcheck)\n\tf.Close()\n\t^\n1 issues:\n* errcheck: 1\n",
+			want:   Diagnostic{File: "main.go", Line: 12, Column: 5, Rule: "errcheck", Message: "Error return value is not checked"},
+		},
+		{
+			name:   LinterClippy,
+			stderr: "    Checking calc v0.1.0\nsrc/lib.rs:3:9: warning: unused variable: `x`\nwarning: `calc` (lib) generated 1 warning\n",
+			want:   Diagnostic{File: "src/lib.rs", Line: 3, Column: 9, Severity: SeverityWarning, Message: "unused variable: `x`"},
+		},
+		{
+			name:   LinterFlake8,
+			stdout: "./pkg/util.py:1:1: F401 'os' imported but unused\n",
+			want:   Diagnostic{File: filepath.FromSlash("pkg/util.py"), Line: 1, Column: 1, Rule: "F401", Message: "'os' imported but unused"},
+		},
+		{
+			name:   LinterESLint,
+			stdout: "\n> app@1.0.0 lint\n" + `[{"filePath":"` + strings.ReplaceAll(filepath.Join(workdir, "src", "a.js"), `\`, `\\`) + `","messages":[{"ruleId":"no-unused-vars","severity":2,"message":"'x' is assigned a value but never used.","line":1,"column":7}]}]`,
+			want:   Diagnostic{File: filepath.FromSlash("src/a.js"), Line: 1, Column: 7, Severity: SeverityError, Rule: "no-unused-vars", Message: "'x' is assigned a value but never used."},
+		},
+		{
+			name:   LinterRuff,
+			stdout: `[{"code":"F401","message":"` + "`os` imported but unused" + `","filename":"` + strings.ReplaceAll(filepath.Join(workdir, "a.py"), `\`, `\\`) + `","location":{"row":1,"column":8}}]`,
+			want:   Diagnostic{File: "a.py", Line: 1, Column: 8, Severity: SeverityError, Rule: "F401", Message: "`os` imported but unused"},
+		},
+	}
+	for _, tt := range tests {
+		got := ParseLint(tt.name, workdir, tt.stdout, tt.stderr)
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("ParseLint(%s) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	workdir := filepath.FromSlash("/src/app")
+	tests := []struct {
+		name   string
+		stdout string
+		stderr string
+		paths  []string
+		want   []string
+	}{
+		{FormatterGofmt, "main.go\npkg/a.go\n", "other.go:1:1: expected 'package'\n", nil, []string{"main.go", "pkg/a.go"}},
+		{FormatterGofmt, "main.go\npkg/a.go\n", "", []string{"pkg"}, []string{"pkg/a.go"}},
+		{FormatterPrettier, "src/a.ts\n", "[warn] Code style issues found\n", nil, []string{"src/a.ts"}},
+		{FormatterRuff, "Would reformat: a.py\n1 file would be reformatted\n", "", nil, []string{"a.py"}},
+		{FormatterBlack, "", "would reformat " + filepath.Join(workdir, "a.py") + "\nOh no!\n", nil, []string{"a.py"}},
+		{FormatterRustfmt, "Diff in " + filepath.Join(workdir, "src", "lib.rs") + ":1:\n-fn a(){}\n+fn a() {}\nDiff in " + filepath.Join(workdir, "src", "lib.rs") + " at line 9:\n", "", nil, []string{"src/lib.rs"}},
+	}
+	for _, tt := range tests {
+		var want []string
+		for _, f := range tt.want {
+			want = append(want, filepath.FromSlash(f))
+		}
+		if got := ListFiles(tt.name, workdir, tt.stdout, tt.stderr, tt.paths); !slices.Equal(got, want) {
+			t.Errorf("ListFiles(%s, %v) = %v, want %v", tt.name, tt.paths, got, want)
+		}
+	}
+}
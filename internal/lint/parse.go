@@ -0,0 +1,225 @@
+package lint
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Severities of diagnostics.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic is a problem a linter reported.
+type Diagnostic struct {
+	File     string `json:"file,omitempty"` // Relative to the workdir when inside it
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Rule     string `json:"rule,omitempty"` // Linter or rule reporting it
+	Message  string `json:"message"`
+}
+
+var (
+	// location matches the file:line[:col]: message lines of go vet,
+	// golangci-lint, clippy --message-format short and flake8.
+	location = regexp.MustCompile(`^(?:vet: )?((?:[A-Za-z]:[\\/])?[^\s:][^:]*):(\d+)(?::(\d+))?: (.*)$`)
+	// golangciRule matches the linter golangci-lint ends messages with.
+	golangciRule = regexp.MustCompile(`^(.*) \(([\w-]+)\)$`)
+	// clippySeverity matches the level clippy starts messages with.
+	clippySeverity = regexp.MustCompile(`^(error|warning)(?:\[(\w+)\])?: (.*)$`)
+	// flake8Code matches the code flake8 starts messages with.
+	flake8Code = regexp.MustCompile(`^([A-Z]+\d+) (.*)$`)
+
+	// rustfmtDiff matches the header of a file cargo fmt --check would
+	// change, in the formats of recent and older releases.
+	rustfmtDiff = regexp.MustCompile(`^Diff in (.+?\.rs)(?: at line \d+|:\d+)?:`)
+)
+
+// ParseLint parses the diagnostics of a linter's output.
+func ParseLint(name, workdir, stdout, stderr string) []Diagnostic {
+	var diags []Diagnostic
+	switch name {
+	case LinterESLint:
+		diags = parseESLint(stdout)
+	case LinterRuff:
+		diags = parseRuff(stdout)
+	default:
+		diags = parseLocations(name, stdout+"\n"+stderr)
+	}
+	for i := range diags {
+		diags[i].File = relative(workdir, diags[i].File)
+	}
+	return diags
+}
+
+// parseLocations parses diagnostics printed as file:line[:col]: message.
+func parseLocations(name, out string) []Diagnostic {
+	var diags []Diagnostic
+	for _, line := range strings.Split(out, "\n") {
+		m := location.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		d := Diagnostic{File: m[1], Message: m[4]}
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+
+		switch name {
+		case LinterGolangci:
+			if r := golangciRule.FindStringSubmatch(d.Message); r != nil {
+				d.Message, d.Rule = r[1], r[2]
+			}
+		case LinterGoVet:
+			d.Severity = SeverityError
+		case LinterClippy:
+			if r := clippySeverity.FindStringSubmatch(d.Message); r != nil {
+				d.Severity, d.Rule, d.Message = r[1], r[2], r[3]
+			}
+		case LinterFlake8:
+			if r := flake8Code.FindStringSubmatch(d.Message); r != nil {
+				d.Rule, d.Message = r[1], r[2]
+			}
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// parseESLint parses the output of eslint --format json.
+func parseESLint(out string) []Diagnostic {
+	var results []struct {
+		FilePath string `json:"filePath"`
+		Messages []struct {
+			RuleID   string `json:"ruleId"`
+			Severity int    `json:"severity"` // 1 warning, 2 error
+			Message  string `json:"message"`
+			Line     int    `json:"line"`
+			Column   int    `json:"column"`
+		} `json:"messages"`
+	}
+	if json.Unmarshal([]byte(jsonPart(out, "[")), &results) != nil {
+		return nil
+	}
+	var diags []Diagnostic
+	for _, r := range results {
+		for _, m := range r.Messages {
+			d := Diagnostic{File: r.FilePath, Line: m.Line, Column: m.Column, Rule: m.RuleID, Message: m.Message, Severity: SeverityWarning}
+			if m.Severity == 2 {
+				d.Severity = SeverityError
+			}
+			diags = append(diags, d)
+		}
+	}
+	return diags
+}
+
+// parseRuff parses the output of ruff check --output-format json.
+func parseRuff(out string) []Diagnostic {
+	var results []struct {
+		Code     string `json:"code"`
+		Message  string `json:"message"`
+		Filename string `json:"filename"`
+		Location struct {
+			Row    int `json:"row"`
+			Column int `json:"column"`
+		} `json:"location"`
+	}
+	if json.Unmarshal([]byte(jsonPart(out, "[")), &results) != nil {
+		return nil
+	}
+	var diags []Diagnostic
+	for _, r := range results {
+		diags = append(diags, Diagnostic{File: r.Filename, Line: r.Location.Row, Column: r.Location.Column, Rule: r.Code, Message: r.Message, Severity: SeverityError})
+	}
+	return diags
+}
+
+// jsonPart returns out from the first line starting with open, skipping
+// what npm scripts and warnings print before it.
+func jsonPart(out, open string) string {
+	for i := 0; i < len(out); {
+		if strings.HasPrefix(out[i:], open) {
+			return out[i:]
+		}
+		next := strings.IndexByte(out[i:], '\n')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return out
+}
+
+// ListFiles parses the files a formatter's check command would change,
+// relative to workdir, keeping those under paths when given.
+func ListFiles(name, workdir, stdout, stderr string, paths []string) []string {
+	out := stdout + "\n" + stderr
+	if name == FormatterGofmt || name == FormatterPrettier {
+		// Files are listed on stdout, errors printed on stderr
+		out = stdout
+	}
+	var files []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimRight(line, "\r")
+		var file string
+		switch name {
+		case FormatterGofmt, FormatterPrettier:
+			file = strings.TrimSpace(line)
+		case FormatterRustfmt:
+			if m := rustfmtDiff.FindStringSubmatch(line); m != nil {
+				file = m[1]
+			}
+		case FormatterRuff:
+			if f, ok := strings.CutPrefix(line, "Would reformat: "); ok {
+				file = f
+			}
+		case FormatterBlack:
+			if f, ok := strings.CutPrefix(line, "would reformat "); ok {
+				file = f
+			}
+		}
+		if file == "" {
+			continue
+		}
+		file = relative(workdir, file)
+		if !slices.Contains(files, file) && under(file, paths) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// under reports whether a relative file is under one of paths, or paths
+// is empty.
+func under(file string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		p = filepath.Clean(p)
+		if p == "." || file == p || strings.HasPrefix(file, p+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// relative returns a path relative to workdir when it is inside it.
+func relative(workdir, path string) string {
+	if path == "" {
+		return ""
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	if rel, err := filepath.Rel(workdir, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return rel
+	}
+	return path
+}
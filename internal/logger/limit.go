@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxRepeatKeys bounds the messages remembered for repeat suppression.
+const maxRepeatKeys = 1024
+
+// limitHandler keeps bursts of log lines from flooding the output: it logs
+// every Nth debug line and suppresses warnings and errors repeating the
+// same message within an interval.
+type limitHandler struct {
+	slog.Handler
+	limits *limits
+}
+
+// limits is shared by a handler and the handlers derived from it with
+// WithAttrs and WithGroup.
+type limits struct {
+	sampleDebug    uint64
+	debugCount     atomic.Uint64
+	repeatInterval time.Duration
+
+	mu      sync.Mutex
+	repeats map[string]*repeat
+}
+
+type repeat struct {
+	last       time.Time
+	suppressed int
+}
+
+func newLimitHandler(h slog.Handler, opts Options) slog.Handler {
+	if opts.SampleDebug <= 1 && opts.RepeatInterval <= 0 {
+		return h
+	}
+	return &limitHandler{
+		Handler: h,
+		limits: &limits{
+			sampleDebug:    uint64(max(opts.SampleDebug, 1)),
+			repeatInterval: opts.RepeatInterval,
+			repeats:        make(map[string]*repeat),
+		},
+	}
+}
+
+func (h *limitHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelInfo && h.limits.sampleDebug > 1 {
+		if (h.limits.debugCount.Add(1)-1)%h.limits.sampleDebug != 0 {
+			return nil
+		}
+	}
+
+	if r.Level >= slog.LevelWarn && h.limits.repeatInterval > 0 {
+		suppressed, ok := h.limits.admit(r.Level.String()+" "+r.Message, r.Time)
+		if !ok {
+			return nil
+		}
+		if suppressed > 0 {
+			r.AddAttrs(slog.Int("suppressed_repeats", suppressed))
+		}
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *limitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &limitHandler{Handler: h.Handler.WithAttrs(attrs), limits: h.limits}
+}
+
+func (h *limitHandler) WithGroup(name string) slog.Handler {
+	return &limitHandler{Handler: h.Handler.WithGroup(name), limits: h.limits}
+}
+
+// admit reports whether a message may be logged at t, and how many
+// repeats of it were suppressed since it last was.
+func (l *limits) admit(key string, t time.Time) (int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if rep, ok := l.repeats[key]; ok {
+		if t.Sub(rep.last) < l.repeatInterval {
+			rep.suppressed++
+			return 0, false
+		}
+		suppressed := rep.suppressed
+		rep.last, rep.suppressed = t, 0
+		return suppressed, true
+	}
+
+	if len(l.repeats) >= maxRepeatKeys {
+		for k, rep := range l.repeats {
+			if t.Sub(rep.last) >= l.repeatInterval {
+				delete(l.repeats, k)
+			}
+		}
+	}
+	if len(l.repeats) < maxRepeatKeys {
+		l.repeats[key] = &repeat{last: t}
+	}
+	return 0, true
+}
+
+// lineWriter cuts log lines longer than max bytes. Handlers write one
+// line per call, so each write is a line.
+type lineWriter struct {
+	w   io.Writer
+	max int
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	if len(p) <= lw.max {
+		return lw.w.Write(p)
+	}
+	line := fmt.Appendf(append([]byte(nil), p[:lw.max]...), "... (%d bytes truncated)\n", len(p)-lw.max)
+	if _, err := lw.w.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_sampleDebug(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(Options{Level: "debug", Output: &buf, SampleDebug: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 9; i++ {
+		log.Debug("tick")
+	}
+	log.Info("info lines are not sampled")
+
+	if got := strings.Count(buf.String(), "msg=tick"); got != 3 {
+		t.Errorf("logged %d of 9 debug lines, want 3", got)
+	}
+	if !strings.Contains(buf.String(), "info lines are not sampled") {
+		t.Error("expected info line to be logged")
+	}
+}
+
+func TestLogger_repeatInterval(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(Options{Level: "info", Output: &buf, RepeatInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		log.Warn("disk almost full", "attempt", i)
+	}
+	log.WithError(bytes.ErrTooLarge).Warn("another warning")
+	log.Info("info lines are not limited")
+	log.Info("info lines are not limited")
+
+	out := buf.String()
+	if got := strings.Count(out, "disk almost full"); got != 1 {
+		t.Errorf("logged the repeated warning %d times, want 1", got)
+	}
+	if !strings.Contains(out, "another warning") {
+		t.Error("expected a different warning to be logged")
+	}
+	if got := strings.Count(out, "info lines are not limited"); got != 2 {
+		t.Errorf("logged %d info lines, want 2", got)
+	}
+
+	// Once the interval passes, the suppressed repeats are reported
+	h := log.Handler().(*limitHandler)
+	h.limits.repeatInterval = time.Nanosecond
+	buf.Reset()
+	log.Warn("disk almost full")
+	if !strings.Contains(buf.String(), "suppressed_repeats=4") {
+		t.Errorf("expected suppressed repeats to be reported, got %q", buf.String())
+	}
+}
+
+func TestLogger_maxLineLength(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(Options{Level: "info", Output: &buf, MaxLineLength: 80})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log.Info("long", "output", strings.Repeat("x", 500))
+	log.Info("short")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", buf.String())
+	}
+	if !strings.HasSuffix(lines[0], "bytes truncated)") || len(lines[0]) > 120 {
+		t.Errorf("expected the long line to be cut, got %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "msg=short") {
+		t.Errorf("expected the short line whole, got %q", lines[1])
+	}
+}
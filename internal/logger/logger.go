@@ -24,6 +24,17 @@ type Options struct {
 	Output     io.Writer
 	JSONOutput bool
 	AddSource  bool
+
+	// SampleDebug logs only every Nth debug line; 0 or 1 logs all
+	SampleDebug int
+
+	// RepeatInterval suppresses warnings and errors repeating the same
+	// message within the interval; the next one logged reports how many
+	// were suppressed. Zero logs every repeat
+	RepeatInterval time.Duration
+
+	// MaxLineLength cuts longer log lines; 0 leaves them whole
+	MaxLineLength int
 }
 
 // DefaultOptions returns default logger options.
@@ -43,6 +54,11 @@ func New(opts Options) (*Logger, error) {
 		return nil, fmt.Errorf("invalid log level %q: %w", opts.Level, err)
 	}
 
+	output := opts.Output
+	if opts.MaxLineLength > 0 {
+		output = &lineWriter{w: output, max: opts.MaxLineLength}
+	}
+
 	var handler slog.Handler
 	handlerOpts := &slog.HandlerOptions{
 		Level:     level,
@@ -65,10 +81,11 @@ func New(opts Options) (*Logger, error) {
 	}
 
 	if opts.JSONOutput {
-		handler = slog.NewJSONHandler(opts.Output, handlerOpts)
+		handler = slog.NewJSONHandler(output, handlerOpts)
 	} else {
-		handler = slog.NewTextHandler(opts.Output, handlerOpts)
+		handler = slog.NewTextHandler(output, handlerOpts)
 	}
+	handler = newLimitHandler(handler, opts)
 
 	return &Logger{
 		Logger: slog.New(handler),
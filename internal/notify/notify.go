@@ -0,0 +1,217 @@
+// Package notify shows native desktop notifications
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+const (
+	// maxTitleLength and maxMessageLength bound the displayed text.
+	maxTitleLength   = 100
+	maxMessageLength = 500
+
+	// notifyTimeout limits how long the notification command may run.
+	notifyTimeout = 10 * time.Second
+)
+
+// Urgency levels.
+const (
+	UrgencyLow      = "low"
+	UrgencyNormal   = "normal"
+	UrgencyCritical = "critical"
+)
+
+// Notification is a message for the desktop user.
+type Notification struct {
+	Title   string
+	Message string
+	Urgency string // low, normal or critical; defaults to normal
+}
+
+// runFunc runs a notification command.
+type runFunc func(ctx context.Context, name string, args ...string) error
+
+// Notifier shows notifications subject to a rate limit.
+type Notifier struct {
+	app          string
+	disabled     bool
+	maxPerMinute int
+	logger       *logger.Logger
+	goos         string
+	run          runFunc
+
+	mu   sync.Mutex
+	sent []time.Time // Notification times within the rate window
+}
+
+// New creates a notifier.
+func New(cfg *config.Config, log *logger.Logger) *Notifier {
+	return &Notifier{
+		app:          cfg.App,
+		disabled:     cfg.Notifications.Disabled,
+		maxPerMinute: cfg.Notifications.MaxPerMinute,
+		logger:       log,
+		goos:         runtime.GOOS,
+		run: func(ctx context.Context, name string, args ...string) error {
+			// #nosec G204 - arguments are passed directly, never through a shell
+			out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+			if err != nil && len(out) > 0 {
+				return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+			}
+			return err
+		},
+	}
+}
+
+// Notify shows a desktop notification.
+func (n *Notifier) Notify(ctx context.Context, note Notification) error {
+	if n.disabled {
+		return apperrors.PermissionError("desktop notifications are disabled", "notifications")
+	}
+
+	note, err := n.normalize(note)
+	if err != nil {
+		return err
+	}
+
+	name, args, err := command(n.goos, note)
+	if err != nil {
+		return err
+	}
+
+	if err := n.allow(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	if err := n.run(ctx, name, args...); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to show notification")
+	}
+
+	n.logger.Debug("showed desktop notification", "title", note.Title)
+
+	return nil
+}
+
+// normalize applies defaults and length limits.
+func (n *Notifier) normalize(note Notification) (Notification, error) {
+	note.Message = strings.TrimSpace(note.Message)
+	if note.Message == "" {
+		return note, apperrors.ValidationError("message is required", "message")
+	}
+
+	note.Title = strings.TrimSpace(note.Title)
+	if note.Title == "" {
+		note.Title = n.app
+	}
+
+	switch note.Urgency {
+	case "":
+		note.Urgency = UrgencyNormal
+	case UrgencyLow, UrgencyNormal, UrgencyCritical:
+	default:
+		return note, apperrors.ValidationError(
+			fmt.Sprintf("invalid urgency %q (expected low, normal or critical)", note.Urgency), "urgency")
+	}
+
+	note.Title = truncate(note.Title, maxTitleLength)
+	note.Message = truncate(note.Message, maxMessageLength)
+
+	return note, nil
+}
+
+// allow applies the per-minute rate limit.
+func (n *Notifier) allow() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-time.Minute)
+	kept := n.sent[:0]
+	for _, t := range n.sent {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	n.sent = kept
+
+	if n.maxPerMinute > 0 && len(n.sent) >= n.maxPerMinute {
+		return apperrors.New(apperrors.ErrorTypeValidation,
+			fmt.Sprintf("rate limit of %d notifications per minute reached", n.maxPerMinute))
+	}
+
+	n.sent = append(n.sent, now)
+	return nil
+}
+
+// command returns the platform command that shows a notification.
+func command(goos string, note Notification) (string, []string, error) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s",
+			appleScriptString(note.Message), appleScriptString(note.Title))
+		return "osascript", []string{"-e", script}, nil
+
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return "notify-send", []string{"--urgency", note.Urgency, "--", note.Title, note.Message}, nil
+
+	case "windows":
+		script := strings.Join([]string{
+			"[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null",
+			"$t = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)",
+			"$x = $t.GetElementsByTagName('text')",
+			"$x.Item(0).AppendChild($t.CreateTextNode(" + powerShellString(note.Title) + ")) > $null",
+			"$x.Item(1).AppendChild($t.CreateTextNode(" + powerShellString(note.Message) + ")) > $null",
+			"[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier(" + powerShellString(note.Title) + ").Show([Windows.UI.Notifications.ToastNotification]::new($t))",
+		}, "; ")
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}, nil
+
+	default:
+		return "", nil, apperrors.New(apperrors.ErrorTypeConfiguration,
+			"desktop notifications are not supported on "+goos)
+	}
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// powerShellQuotes doubles the characters PowerShell takes for single
+// quotes: the ASCII one and U+2018 to U+201B, which would otherwise end a
+// single-quoted string.
+var powerShellQuotes = strings.NewReplacer(
+	"'", "''",
+	"\u2018", "\u2018\u2018",
+	"\u2019", "\u2019\u2019",
+	"\u201a", "\u201a\u201a",
+	"\u201b", "\u201b\u201b",
+)
+
+// powerShellString quotes s as a single-quoted PowerShell string literal.
+func powerShellString(s string) string {
+	return "'" + powerShellQuotes.Replace(s) + "'"
+}
+
+// truncate shortens s to at most max runes.
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+type call struct {
+	name string
+	args []string
+}
+
+func testNotifier(modify func(cfg *config.Config)) (*Notifier, *[]call) {
+	cfg := config.Default()
+	if modify != nil {
+		modify(cfg)
+	}
+
+	calls := &[]call{}
+	n := New(cfg, logger.Default())
+	n.goos = "linux"
+	n.run = func(ctx context.Context, name string, args ...string) error {
+		*calls = append(*calls, call{name: name, args: args})
+		return nil
+	}
+	return n, calls
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	n, calls := testNotifier(nil)
+
+	if err := n.Notify(context.Background(), Notification{Message: "Build finished"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*calls) != 1 {
+		t.Fatalf("expected 1 call, got %d", len(*calls))
+	}
+
+	c := (*calls)[0]
+	want := []string{"--urgency", "normal", "--", "simple-mcp-runner", "Build finished"}
+	if c.name != "notify-send" || strings.Join(c.args, "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected command: %s %v", c.name, c.args)
+	}
+
+	errorCases := []Notification{
+		{Message: "  "},
+		{Message: "hi", Urgency: "urgent"},
+	}
+	for _, note := range errorCases {
+		if err := n.Notify(context.Background(), note); err == nil {
+			t.Errorf("expected error for %+v", note)
+		}
+	}
+}
+
+func TestNotifier_RateLimit(t *testing.T) {
+	n, calls := testNotifier(func(cfg *config.Config) { cfg.Notifications.MaxPerMinute = 2 })
+
+	for i := 0; i < 2; i++ {
+		if err := n.Notify(context.Background(), Notification{Message: "ping"}); err != nil {
+			t.Fatalf("notification %d failed: %v", i, err)
+		}
+	}
+	if err := n.Notify(context.Background(), Notification{Message: "ping"}); err == nil {
+		t.Error("expected rate limit error")
+	}
+	if len(*calls) != 2 {
+		t.Errorf("expected 2 calls, got %d", len(*calls))
+	}
+}
+
+func TestNotifier_Disabled(t *testing.T) {
+	n, calls := testNotifier(func(cfg *config.Config) { cfg.Notifications.Disabled = true })
+
+	if err := n.Notify(context.Background(), Notification{Message: "ping"}); err == nil {
+		t.Error("expected error when disabled")
+	}
+	if len(*calls) != 0 {
+		t.Errorf("expected no calls, got %d", len(*calls))
+	}
+}
+
+func TestNotifier_RunError(t *testing.T) {
+	n, _ := testNotifier(nil)
+	n.run = func(ctx context.Context, name string, args ...string) error {
+		return errors.New("notify-send: not found")
+	}
+
+	if err := n.Notify(context.Background(), Notification{Message: "ping"}); err == nil {
+		t.Error("expected error when the notification command fails")
+	}
+}
+
+func TestCommand(t *testing.T) {
+	note := Notification{Title: `Say "hi"`, Message: `it's C:\done`, Urgency: UrgencyNormal}
+
+	name, args, err := command("darwin", note)
+	if err != nil || name != "osascript" {
+		t.Fatalf("unexpected darwin command: %s %v", name, err)
+	}
+	if want := `display notification "it's C:\\done" with title "Say \"hi\""`; args[1] != want {
+		t.Errorf("unexpected AppleScript: %s", args[1])
+	}
+
+	name, args, err = command("windows", note)
+	if err != nil || name != "powershell" {
+		t.Fatalf("unexpected windows command: %s %v", name, err)
+	}
+	if !strings.Contains(args[len(args)-1], `CreateTextNode('it''s C:\done')`) {
+		t.Errorf("unexpected PowerShell script: %s", args[len(args)-1])
+	}
+
+	// PowerShell also ends single-quoted strings at typographic quotes
+	_, args, _ = command("windows", Notification{Title: "t", Message: "it\u2019s\u2018; calc\u201b\u201a"})
+	if !strings.Contains(args[len(args)-1], "CreateTextNode('it\u2019\u2019s\u2018\u2018; calc\u201b\u201b\u201a\u201a')") {
+		t.Errorf("unexpected PowerShell script: %s", args[len(args)-1])
+	}
+
+	if _, _, err := command("plan9", note); err == nil {
+		t.Error("expected error for unsupported platform")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("unexpected truncation: %q", got)
+	}
+	if got := truncate("abcdefghijkl", 8); got != "abcde..." {
+		t.Errorf("unexpected truncation: %q", got)
+	}
+}
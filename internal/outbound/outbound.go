@@ -0,0 +1,224 @@
+// Package outbound builds the HTTP clients of features that reach the
+// network, from the network section of the configuration
+package outbound
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+const (
+	// defaultConnectTimeout limits establishing a connection when
+	// network.connect_timeout is not set.
+	defaultConnectTimeout = 10 * time.Second
+
+	// firstBackoff is the wait before the first retry; it doubles with
+	// every retry up to maxBackoff.
+	firstBackoff = 500 * time.Millisecond
+	maxBackoff   = 10 * time.Second
+)
+
+// New returns a client with the configured transport and retries. Its
+// timeout is network.timeout, or the feature's own when that is not set.
+func New(n config.NetworkConfig, timeout time.Duration) (*http.Client, error) {
+	transport, err := Transport(n)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Transport: WithRetries(n, transport),
+		Timeout:   Timeout(n, timeout),
+	}, nil
+}
+
+// Timeout returns network.timeout, or fallback when it is not set.
+func Timeout(n config.NetworkConfig, fallback time.Duration) time.Duration {
+	if n.Timeout > 0 {
+		return n.Timeout.Std()
+	}
+	return fallback
+}
+
+// Transport returns a transport going through the configured proxy,
+// trusting the configured certificate authorities and connecting to the
+// preferred IP version first.
+func Transport(n config.NetworkConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if n.Proxy != "" {
+		proxy, err := url.Parse(n.Proxy)
+		if err != nil {
+			return nil, apperrors.ValidationError("invalid proxy URL: "+n.Proxy, "network.proxy")
+		}
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if n.Bypasses(req.URL.Hostname()) {
+				return nil, nil
+			}
+			return proxy, nil
+		}
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if n.CABundle != "" {
+		pool, err := loadCABundle(n.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	connect := defaultConnectTimeout
+	if n.ConnectTimeout > 0 {
+		connect = n.ConnectTimeout.Std()
+	}
+	dialer := &net.Dialer{Timeout: connect, KeepAlive: 30 * time.Second}
+	transport.DialContext = dialPreferring(dialer, n.IPPreference)
+	transport.TLSHandshakeTimeout = connect
+
+	return transport, nil
+}
+
+// loadCABundle returns the system certificate authorities with those of a
+// PEM file added.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to read ca_bundle").
+			WithContext("path", path)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, apperrors.ValidationError("ca_bundle contains no PEM certificates: "+path, "network.ca_bundle")
+	}
+	return pool, nil
+}
+
+// dialPreferring dials TCP addresses of the preferred IP version first,
+// then of the other one. Without a preference, dialing is left to the
+// dialer, which races both.
+func dialPreferring(dialer *net.Dialer, preference string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	var first, second string
+	switch preference {
+	case config.IPv4:
+		first, second = "tcp4", "tcp6"
+	case config.IPv6:
+		first, second = "tcp6", "tcp4"
+	default:
+		return dialer.DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if network != "tcp" {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		conn, err := dialer.DialContext(ctx, first, addr)
+		if err == nil || ctx.Err() != nil {
+			return conn, err
+		}
+		if conn, fallbackErr := dialer.DialContext(ctx, second, addr); fallbackErr == nil {
+			return conn, nil
+		}
+		return nil, err
+	}
+}
+
+// WithRetries wraps a transport to retry requests network.retries times.
+func WithRetries(n config.NetworkConfig, next http.RoundTripper) http.RoundTripper {
+	if n.Retries == 0 {
+		return next
+	}
+	return &retrier{next: next, retries: n.Retries}
+}
+
+// retrier retries requests that failed to connect, and GET and HEAD
+// requests answered with a status meaning the server may answer later.
+type retrier struct {
+	next    http.RoundTripper
+	retries int
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *retrier) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := r.next.RoundTrip(req)
+		if attempt == r.retries || !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		// Requests with a body can only be sent again if it can be read
+		// again
+		retry := req.Clone(req.Context())
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			retry.Body = body
+		}
+
+		wait := backoff(attempt, resp)
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		req = retry
+	}
+}
+
+// retryable reports whether a request may succeed if sent again: it could
+// not connect, or a GET or HEAD was rate limited or hit an unavailable
+// upstream.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		if req.Context().Err() != nil {
+			return false
+		}
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns the wait before a retry: the Retry-After seconds of the
+// response, or an exponential backoff, at most maxBackoff.
+func backoff(attempt int, resp *http.Response) time.Duration {
+	wait := firstBackoff << attempt
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			wait = time.Duration(secs) * time.Second
+		}
+	}
+	return min(wait, maxBackoff)
+}
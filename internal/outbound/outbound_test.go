@@ -0,0 +1,165 @@
+package outbound
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// flakyServer answers 503 to the first failures requests and 200 after.
+func flakyServer(t *testing.T, failures int64) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestNew_retries(t *testing.T) {
+	srv, calls := flakyServer(t, 2)
+	client, err := New(config.NetworkConfig{Retries: 2}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Errorf("Get() = %d after %d calls, want 200 after 3", resp.StatusCode, calls.Load())
+	}
+
+	// Other methods may have had an effect, so they are not sent again
+	calls.Store(0)
+	resp, err = client.Post(srv.URL, "text/plain", strings.NewReader("data"))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("Post() = %d after %d calls, want 503 after 1", resp.StatusCode, calls.Load())
+	}
+
+	// Retries are bounded
+	srv, calls = flakyServer(t, 10)
+	resp, err = client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 3 {
+		t.Errorf("Get() = %d after %d calls, want 503 after 3", resp.StatusCode, calls.Load())
+	}
+}
+
+// dialFailure fails to connect the first time it is used.
+type dialFailure struct {
+	calls int
+}
+
+func (d *dialFailure) RoundTrip(req *http.Request) (*http.Response, error) {
+	d.calls++
+	if d.calls == 1 {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}
+	}
+	return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Request: req}, nil
+}
+
+func TestWithRetries_connectFailure(t *testing.T) {
+	next := &dialFailure{}
+	client := &http.Client{Transport: WithRetries(config.NetworkConfig{Retries: 1}, next)}
+
+	// Requests that never reached the server are sent again, body included
+	resp, err := client.Post("http://example.com", "text/plain", strings.NewReader("data"))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || next.calls != 2 {
+		t.Errorf("Post() = %d after %d calls, want 204 after 2", resp.StatusCode, next.calls)
+	}
+}
+
+func TestTransport_proxy(t *testing.T) {
+	transport, err := Transport(config.NetworkConfig{
+		Proxy:   "http://proxy.corp:3128",
+		NoProxy: []string{"internal.corp", "*.svc.corp"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"https://example.com/file":      "http://proxy.corp:3128",
+		"https://internal.corp/catalog": "",
+		"https://api.svc.corp/hook":     "",
+		"https://notinternal.corp/hook": "http://proxy.corp:3128",
+	}
+	for raw, want := range tests {
+		req, _ := http.NewRequest(http.MethodGet, raw, nil)
+		proxy, err := transport.Proxy(req)
+		if err != nil {
+			t.Fatalf("Proxy(%s) error = %v", raw, err)
+		}
+		got := ""
+		if proxy != nil {
+			got = proxy.String()
+		}
+		if got != want {
+			t.Errorf("Proxy(%s) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestTransport_caBundle(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Transport(config.NetworkConfig{CABundle: filepath.Join(dir, "missing.pem")}); err == nil {
+		t.Error("Transport() accepted a missing ca_bundle")
+	}
+
+	notPEM := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Transport(config.NetworkConfig{CABundle: notPEM}); err == nil {
+		t.Error("Transport() accepted a ca_bundle without certificates")
+	}
+}
+
+func TestTransport_ipPreference(t *testing.T) {
+	srv, _ := flakyServer(t, 0)
+
+	// The test server only listens on IPv4, so an IPv6 preference falls
+	// back to it
+	for _, pref := range config.IPPreferences {
+		client, err := New(config.NetworkConfig{IPPreference: pref}, 10*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Get() with ip_preference %s error = %v", pref, err)
+			continue
+		}
+		resp.Body.Close()
+	}
+}
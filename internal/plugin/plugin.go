@@ -0,0 +1,267 @@
+// Package plugin runs WebAssembly modules as sandboxed extensions that
+// decide whether commands may run and process their output
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+const (
+	// defaultTimeout limits a plugin call when the plugin sets no timeout.
+	defaultTimeout = time.Second
+
+	// defaultMaxMemory limits plugin memory when the plugin sets no limit.
+	defaultMaxMemory = 16 << 20
+
+	// pageSize is the size of a WebAssembly memory page.
+	pageSize = 64 << 10
+
+	// maxResponseSize limits the JSON a plugin may write.
+	maxResponseSize = 64 << 20
+)
+
+// Request is the JSON document a plugin reads from stdin.
+type Request struct {
+	Hook     string            `json:"hook"`
+	Command  string            `json:"command"` // Configured command name
+	Program  string            `json:"program"` // Executable the command runs
+	Args     []string          `json:"args,omitempty"`
+	WorkDir  string            `json:"workdir,omitempty"`
+	Config   map[string]string `json:"config,omitempty"`
+	ExitCode int               `json:"exit_code,omitempty"` // Output hook only
+	Stdout   string            `json:"stdout,omitempty"`    // Output hook only
+	Stderr   string            `json:"stderr,omitempty"`    // Output hook only
+}
+
+// Response is the JSON document a plugin writes to stdout. Fields a
+// plugin leaves out are unchanged.
+type Response struct {
+	Allow  *bool   `json:"allow,omitempty"`  // Policy hook: whether the command may run
+	Reason string  `json:"reason,omitempty"` // Policy hook: why it may not
+	Stdout *string `json:"stdout,omitempty"` // Output hook: replacement stdout
+	Stderr *string `json:"stderr,omitempty"` // Output hook: replacement stderr
+}
+
+// module is a compiled plugin.
+type module struct {
+	config   config.Plugin
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	timeout  time.Duration
+}
+
+// Host runs the configured plugins.
+type Host struct {
+	modules map[string]*module
+	logger  *logger.Logger
+}
+
+// New compiles the configured plugins.
+func New(ctx context.Context, cfg *config.Config, log *logger.Logger) (*Host, error) {
+	h := &Host{modules: make(map[string]*module), logger: log}
+	for _, p := range cfg.Plugins {
+		m, err := compile(ctx, p)
+		if err != nil {
+			h.Close(ctx)
+			return nil, err
+		}
+		h.modules[p.Name] = m
+	}
+	return h, nil
+}
+
+// compile compiles a plugin in a runtime of its own, which enforces its
+// memory limit.
+func compile(ctx context.Context, p config.Plugin) (*module, error) {
+	code, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to read plugin "+p.Name)
+	}
+
+	maxMemory := p.MaxMemory
+	if maxMemory == 0 {
+		maxMemory = defaultMaxMemory
+	}
+	pages := uint32((maxMemory + pageSize - 1) / pageSize)
+	r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(pages).
+		WithCloseOnContextDone(true))
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, r); err != nil {
+		r.Close(ctx)
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to provide WASI to plugin "+p.Name)
+	}
+	compiled, err := r.CompileModule(ctx, code)
+	if err != nil {
+		r.Close(ctx)
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to compile plugin "+p.Name)
+	}
+
+	timeout := defaultTimeout
+	if p.Timeout > 0 {
+		timeout = p.Timeout.Std()
+	}
+	return &module{config: p, runtime: r, compiled: compiled, timeout: timeout}, nil
+}
+
+// Close releases the compiled plugins.
+func (h *Host) Close(ctx context.Context) error {
+	if h == nil {
+		return nil
+	}
+	var errs []error
+	for _, m := range h.modules {
+		errs = append(errs, m.runtime.Close(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// call runs a plugin on a request in a fresh instance, so no state is
+// kept between calls.
+func (h *Host) call(ctx context.Context, m *module, req Request) (*Response, error) {
+	req.Config = m.config.Config
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to encode plugin request")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	var stdout, stderr limitedBuffer
+	stdout.limit, stderr.limit = maxResponseSize, 64<<10
+	cfg := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(m.config.Name).
+		WithStdin(bytes.NewReader(in)).
+		WithStdout(&stdout).
+		WithStderr(&stderr)
+
+	mod, err := m.runtime.InstantiateModule(ctx, m.compiled, cfg)
+	if mod != nil {
+		mod.Close(ctx)
+	}
+	if err != nil {
+		var exit *sys.ExitError
+		switch {
+		case ctx.Err() != nil:
+			return nil, apperrors.TimeoutError(fmt.Sprintf("plugin %s timed out", m.config.Name), m.timeout.String())
+		case errors.As(err, &exit):
+			return nil, apperrors.New(apperrors.ErrorTypeExecution,
+				fmt.Sprintf("plugin %s exited with code %d: %s", m.config.Name, exit.ExitCode(), strings.TrimSpace(stderr.String())))
+		default:
+			return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "plugin "+m.config.Name+" failed")
+		}
+	}
+	if stdout.exceeded {
+		return nil, apperrors.New(apperrors.ErrorTypeExecution,
+			fmt.Sprintf("plugin %s response exceeds %d bytes", m.config.Name, maxResponseSize))
+	}
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "plugin "+m.config.Name+" wrote an invalid response")
+	}
+	return &resp, nil
+}
+
+// hooked returns the plugins of a command called for a hook, in order.
+func (h *Host) hooked(cmd *config.Command, hook string) []*module {
+	if h == nil {
+		return nil
+	}
+	var mods []*module
+	for _, name := range cmd.Plugins {
+		if m := h.modules[name]; m != nil && slices.Contains(m.config.Hooks, hook) {
+			mods = append(mods, m)
+		}
+	}
+	return mods
+}
+
+// Policy asks the policy plugins of a command whether it may run, and
+// returns the name of the denying plugin and its reason, if one denies.
+// Plugins that fail deny the command.
+func (h *Host) Policy(ctx context.Context, cmd *config.Command, req *types.CommandExecutionRequest) (plugin, reason string) {
+	for _, m := range h.hooked(cmd, config.PluginHookPolicy) {
+		resp, err := h.call(ctx, m, Request{
+			Hook:    config.PluginHookPolicy,
+			Command: cmd.Name,
+			Program: req.Command,
+			Args:    req.Args,
+			WorkDir: req.WorkDir,
+		})
+		if err != nil {
+			h.logger.WithError(err).Warn("policy plugin failed", "plugin", m.config.Name, "command", cmd.Name)
+			return m.config.Name, err.Error()
+		}
+		if resp.Allow != nil && !*resp.Allow {
+			return m.config.Name, resp.Reason
+		}
+	}
+	return "", ""
+}
+
+// Output passes the output of a command through its output plugins in
+// order. If a plugin fails, the output is withheld, as it may hold what
+// the plugin was meant to redact.
+func (h *Host) Output(ctx context.Context, cmd *config.Command, req *types.CommandExecutionRequest, result *types.CommandExecutionResult) error {
+	for _, m := range h.hooked(cmd, config.PluginHookOutput) {
+		resp, err := h.call(ctx, m, Request{
+			Hook:     config.PluginHookOutput,
+			Command:  cmd.Name,
+			Program:  req.Command,
+			Args:     req.Args,
+			WorkDir:  req.WorkDir,
+			ExitCode: result.ExitCode,
+			Stdout:   result.Stdout,
+			Stderr:   result.Stderr,
+		})
+		if err != nil {
+			result.Stdout, result.Stderr = "", ""
+			result.StdoutFile, result.StderrFile = "", ""
+			return err
+		}
+		if resp.Stdout != nil {
+			result.Stdout = *resp.Stdout
+		}
+		if resp.Stderr != nil {
+			result.Stderr = *resp.Stderr
+		}
+	}
+	return nil
+}
+
+// limitedBuffer collects output up to a limit.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit    int
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); len(p) > room {
+		b.exceeded = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
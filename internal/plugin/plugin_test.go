@@ -0,0 +1,88 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildPlugin compiles the test plugin to a WASI module.
+func buildPlugin(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("building a WebAssembly module is slow")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not in PATH")
+	}
+	out := filepath.Join(t.TempDir(), "redact.wasm")
+	cmd := exec.Command(goTool, "build", "-o", out, "./testdata/redact")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return out
+}
+
+func TestHost(t *testing.T) {
+	path := buildPlugin(t)
+	ctx := context.Background()
+
+	cfg := config.Default()
+	cfg.Plugins = []config.Plugin{
+		{
+			Name:   "redact",
+			Path:   path,
+			Hooks:  []string{config.PluginHookPolicy, config.PluginHookOutput},
+			Config: map[string]string{"deny_arg": "--force", "secret": "hunter2"},
+		},
+		{Name: "loop", Path: path, Hooks: []string{config.PluginHookPolicy}, Timeout: config.Duration(200 * time.Millisecond), Config: map[string]string{"mode": "loop"}},
+		{Name: "crash", Path: path, Hooks: []string{config.PluginHookOutput}, Config: map[string]string{"mode": "crash"}},
+	}
+	h, err := New(ctx, cfg, logger.Default())
+	require.NoError(t, err)
+	defer h.Close(ctx)
+
+	cmd := &config.Command{Name: "show", Command: "cat", Plugins: []string{"redact"}}
+
+	name, reason := h.Policy(ctx, cmd, &types.CommandExecutionRequest{Command: "cat", Args: []string{"secrets.txt"}})
+	assert.Empty(t, name)
+	name, reason = h.Policy(ctx, cmd, &types.CommandExecutionRequest{Command: "cat", Args: []string{"--force"}})
+	assert.Equal(t, "redact", name)
+	assert.Equal(t, "argument --force is not allowed", reason)
+
+	result := &types.CommandExecutionResult{Stdout: "password: hunter2\n", Stderr: "warning"}
+	require.NoError(t, h.Output(ctx, cmd, &types.CommandExecutionRequest{Command: "cat"}, result))
+	assert.Equal(t, "password: [REDACTED]\n", result.Stdout)
+	assert.Equal(t, "warning", result.Stderr)
+
+	// Plugins that run too long deny the command
+	looping := &config.Command{Name: "looping", Command: "cat", Plugins: []string{"loop"}}
+	name, reason = h.Policy(ctx, looping, &types.CommandExecutionRequest{Command: "cat"})
+	assert.Equal(t, "loop", name)
+	assert.Contains(t, reason, "timed out")
+
+	// Output is withheld when an output plugin fails
+	crashing := &config.Command{Name: "crashing", Command: "cat", Plugins: []string{"crash"}}
+	result = &types.CommandExecutionResult{Stdout: "password: hunter2\n"}
+	err = h.Output(ctx, crashing, &types.CommandExecutionRequest{Command: "cat"}, result)
+	assert.ErrorContains(t, err, "exited with code 3: crashed")
+	assert.Empty(t, result.Stdout)
+}
+
+func TestHost_nil(t *testing.T) {
+	var h *Host
+	cmd := &config.Command{Name: "show", Command: "cat", Plugins: []string{"redact"}}
+	name, _ := h.Policy(context.Background(), cmd, &types.CommandExecutionRequest{Command: "cat"})
+	assert.Empty(t, name)
+	assert.NoError(t, h.Output(context.Background(), cmd, &types.CommandExecutionRequest{}, &types.CommandExecutionResult{}))
+}
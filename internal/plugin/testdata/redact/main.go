@@ -0,0 +1,46 @@
+// Command redact is a test plugin: it denies commands passed the
+// configured deny_arg and replaces the configured secret in output.
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+)
+
+type request struct {
+	Hook   string            `json:"hook"`
+	Args   []string          `json:"args"`
+	Config map[string]string `json:"config"`
+	Stdout string            `json:"stdout"`
+}
+
+func main() {
+	var req request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		os.Stderr.WriteString(err.Error())
+		os.Exit(2)
+	}
+
+	switch req.Config["mode"] {
+	case "loop":
+		for {
+		}
+	case "crash":
+		os.Stderr.WriteString("crashed")
+		os.Exit(3)
+	}
+
+	resp := map[string]any{}
+	switch req.Hook {
+	case "policy":
+		if slices.Contains(req.Args, req.Config["deny_arg"]) {
+			resp["allow"] = false
+			resp["reason"] = "argument " + req.Config["deny_arg"] + " is not allowed"
+		}
+	case "output":
+		resp["stdout"] = strings.ReplaceAll(req.Stdout, req.Config["secret"], "[REDACTED]")
+	}
+	json.NewEncoder(os.Stdout).Encode(resp)
+}
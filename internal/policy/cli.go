@@ -0,0 +1,281 @@
+package policy
+
+import (
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// CLIDenial explains why a CLI policy denies a run.
+type CLIDenial struct {
+	CLI       string
+	Operation string // Positional words of the command line
+	Reason    string
+}
+
+// Error describes the denial.
+func (d *CLIDenial) Error() string {
+	return "denied by cli policy " + d.CLI + ": " + d.Reason
+}
+
+// Operation is a command line as a CLI module understands it.
+type Operation struct {
+	Words    []string // Positional arguments, without flags and their values
+	ReadOnly bool
+}
+
+// CLIPolicies evaluates the CLI policies of the security policy.
+type CLIPolicies struct {
+	policies []config.CLIPolicy
+}
+
+// NewCLIPolicies returns the configured CLI policies.
+func NewCLIPolicies(cfg *config.Config) *CLIPolicies {
+	return &CLIPolicies{policies: cfg.Security.CLIPolicies}
+}
+
+// Len returns the number of CLI policies.
+func (c *CLIPolicies) Len() int {
+	return len(c.policies)
+}
+
+// Check returns the CLI whose policy applies to a run, or "" if none
+// does, and the denial if the policy refuses it. The first policy whose
+// commands match decides.
+func (c *CLIPolicies) Check(command string, args []string) (string, *CLIDenial) {
+	for _, p := range c.policies {
+		if !p.Matches(command) {
+			continue
+		}
+		op := Classify(p.CLI, args)
+		name := strings.Join(op.Words, " ")
+		switch {
+		case matchOperation(p.Deny, op.Words) != "":
+			return p.CLI, &CLIDenial{CLI: p.CLI, Operation: name,
+				Reason: "operation " + quote(name) + " matches denied entry " + quote(matchOperation(p.Deny, op.Words))}
+		case op.ReadOnly:
+			return p.CLI, nil
+		case matchOperation(p.Allow, op.Words) != "":
+			return p.CLI, nil
+		default:
+			return p.CLI, &CLIDenial{CLI: p.CLI, Operation: name,
+				Reason: "operation " + quote(name) + " is not read-only and matches no allowed entry"}
+		}
+	}
+	return "", nil
+}
+
+// Classify splits a command line of a CLI into its operation and tells
+// whether the operation only reads. Operations a module does not
+// recognize are not read-only.
+func Classify(cli string, args []string) Operation {
+	switch cli {
+	case "aws":
+		return classifyAWS(args)
+	case "kubectl":
+		return classifyKubectl(args)
+	case "gcloud", "az":
+		return classifyVerb(args)
+	}
+	return Operation{Words: positional(args, nil)}
+}
+
+// matchOperation returns the first entry whose words lead an operation.
+func matchOperation(entries []string, words []string) string {
+	for _, entry := range entries {
+		want := strings.Fields(entry)
+		if len(want) == 0 || len(want) > len(words) {
+			continue
+		}
+		matched := true
+		for i, w := range want {
+			if prefix, ok := strings.CutSuffix(w, "*"); ok && i == len(want)-1 {
+				matched = strings.HasPrefix(words[i], prefix)
+			} else {
+				matched = words[i] == w
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched {
+			return entry
+		}
+	}
+	return ""
+}
+
+// positional returns the arguments that are not flags or values of the
+// flags in valueFlags. Unknown flags are taken to have no separate value.
+func positional(args []string, valueFlags map[string]bool) []string {
+	var words []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return append(words, args[i+1:]...)
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			if !strings.Contains(arg, "=") && valueFlags[arg] {
+				i++
+			}
+		default:
+			words = append(words, arg)
+		}
+	}
+	return words
+}
+
+// flagSet builds a set of flag names.
+func flagSet(flags ...string) map[string]bool {
+	set := make(map[string]bool, len(flags))
+	for _, f := range flags {
+		set[f] = true
+	}
+	return set
+}
+
+// awsValueFlags are aws global options taking a value.
+var awsValueFlags = flagSet("--profile", "--region", "--output", "--endpoint-url", "--query",
+	"--color", "--ca-bundle", "--cli-read-timeout", "--cli-connect-timeout", "--cli-binary-format")
+
+// classifyAWS reads aws <service> <operation>: operations that describe,
+// list, get or head, and s3 ls, only read.
+func classifyAWS(args []string) Operation {
+	words := positional(args, awsValueFlags)
+	op := Operation{Words: words}
+	if len(words) < 2 {
+		op.ReadOnly = len(words) == 0 || words[0] == "help"
+		return op
+	}
+	name := words[1]
+	for _, prefix := range []string{"describe-", "list-", "get-", "head-", "batch-get-"} {
+		if strings.HasPrefix(name, prefix) {
+			op.ReadOnly = true
+		}
+	}
+	switch name {
+	case "ls", "list", "help":
+		op.ReadOnly = true
+	}
+	return op
+}
+
+// kubectlValueFlags are kubectl options taking a value.
+var kubectlValueFlags = flagSet("-n", "--namespace", "--context", "--cluster", "--kubeconfig",
+	"--user", "-s", "--server", "--token", "--as", "--as-group", "--request-timeout",
+	"-o", "--output", "-l", "--selector", "-c", "--container", "--field-selector", "--since", "--tail")
+
+// kubectlReadVerbs are kubectl commands that only read, and the read-only
+// subcommands of commands that also mutate.
+var (
+	kubectlReadVerbs = map[string]bool{
+		"get": true, "describe": true, "logs": true, "top": true, "explain": true,
+		"api-resources": true, "api-versions": true, "version": true, "cluster-info": true,
+		"events": true, "diff": true, "kustomize": true, "completion": true, "help": true, "wait": true,
+	}
+	kubectlReadSubcommands = map[string]map[string]bool{
+		"config":  {"view": true, "get-contexts": true, "get-clusters": true, "get-users": true, "current-context": true},
+		"auth":    {"can-i": true, "whoami": true},
+		"rollout": {"status": true, "history": true},
+		"plugin":  {"list": true},
+	}
+)
+
+// classifyKubectl reads kubectl <verb> [<subcommand>].
+func classifyKubectl(args []string) Operation {
+	words := positional(args, kubectlValueFlags)
+	op := Operation{Words: words}
+	switch {
+	case len(words) == 0:
+		op.ReadOnly = true
+	case kubectlReadVerbs[words[0]]:
+		op.ReadOnly = true
+	case kubectlReadSubcommands[words[0]] != nil:
+		op.ReadOnly = len(words) > 1 && kubectlReadSubcommands[words[0]][words[1]]
+	}
+	return op
+}
+
+// gcloudValueFlags are gcloud and az options taking a value.
+var gcloudValueFlags = flagSet("--project", "--account", "--configuration", "--format", "--filter",
+	"--verbosity", "--region", "--zone", "--impersonate-service-account", "--billing-project",
+	"--subscription", "-g", "--resource-group", "-o", "--output", "--query", "-n", "--name")
+
+// Verbs of gcloud and az commands, which end in a verb after their groups.
+var (
+	readVerbs = map[string]bool{
+		"list": true, "describe": true, "show": true, "get": true, "read": true, "info": true,
+		"version": true, "help": true, "ls": true, "cat": true, "query": true, "exists": true,
+		"get-iam-policy": true, "get-value": true, "tail": true, "wait": true,
+	}
+	mutatingVerbs = map[string]bool{
+		"create": true, "delete": true, "update": true, "set": true, "unset": true, "add": true,
+		"remove": true, "deploy": true, "start": true, "stop": true, "restart": true, "reset": true,
+		"resize": true, "patch": true, "import": true, "export": true, "run": true, "ssh": true,
+		"scp": true, "login": true, "logout": true, "submit": true, "cancel": true, "apply": true,
+		"attach": true, "detach": true, "enable": true, "disable": true, "move": true, "rename": true,
+		"copy": true, "cp": true, "mv": true, "rm": true, "upload": true, "download": true,
+		"invoke": true, "call": true, "connect": true, "purge": true, "suspend": true, "resume": true,
+		"promote": true, "rollback": true, "approve": true, "reject": true, "init": true,
+		"install": true, "uninstall": true, "upgrade": true, "scale": true, "assign": true,
+		"grant": true, "revoke": true, "rotate": true, "sign": true, "push": true, "pull": true,
+		"execute": true, "exec": true, "configure": true, "activate": true, "clear": true,
+	}
+)
+
+// classifyVerb reads gcloud and az command lines, which name groups then
+// a verb, such as gcloud compute instances list. Groups may be named like
+// verbs, as gcloud run is, so the first group after any alpha or beta
+// release track only counts as a verb when it is the whole operation.
+// Operations read if they contain a read verb and no mutating verb, so a
+// resource named like a mutating verb makes an operation mutating rather
+// than the reverse.
+func classifyVerb(args []string) Operation {
+	words := positional(args, gcloudValueFlags)
+	op := Operation{Words: words}
+	if len(words) == 0 {
+		op.ReadOnly = true
+		return op
+	}
+	verbs := words
+	if verbs[0] == "alpha" || verbs[0] == "beta" {
+		verbs = verbs[1:]
+	}
+	if len(verbs) > 1 {
+		verbs = verbs[1:]
+	}
+	for _, w := range verbs {
+		if isMutatingVerb(w) {
+			return op
+		}
+		if isReadVerb(w) {
+			op.ReadOnly = true
+		}
+	}
+	return op
+}
+
+// isReadVerb reports whether a gcloud or az word is a read verb.
+func isReadVerb(w string) bool {
+	for _, prefix := range []string{"list-", "describe-", "show-", "get-"} {
+		if strings.HasPrefix(w, prefix) {
+			return true
+		}
+	}
+	return readVerbs[w]
+}
+
+// isMutatingVerb reports whether a gcloud or az word is a mutating verb.
+func isMutatingVerb(w string) bool {
+	for _, prefix := range []string{"create-", "delete-", "update-", "set-", "add-", "remove-"} {
+		if strings.HasPrefix(w, prefix) {
+			return true
+		}
+	}
+	return mutatingVerbs[w]
+}
+
+// quote wraps s in double quotes.
+func quote(s string) string {
+	return `"` + s + `"`
+}
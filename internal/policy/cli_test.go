@@ -0,0 +1,94 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		cli      string
+		line     string
+		readOnly bool
+	}{
+		{"aws", "s3 ls s3://bucket", true},
+		{"aws", "--profile prod --region us-east-1 ec2 describe-instances", true},
+		{"aws", "sts get-caller-identity", true},
+		{"aws", "s3 rm s3://bucket/key", false},
+		{"aws", "ec2 terminate-instances --instance-ids i-1", false},
+		{"kubectl", "get pods -n kube-system", true},
+		{"kubectl", "-n prod logs deploy/api --tail 100", true},
+		{"kubectl", "config view", true},
+		{"kubectl", "config use-context prod", false},
+		{"kubectl", "rollout status deploy/api", true},
+		{"kubectl", "rollout restart deploy/api", false},
+		{"kubectl", "delete pod api-1", false},
+		{"kubectl", "exec -it api-1 -- sh", false},
+		{"gcloud", "compute instances list --project demo", true},
+		{"gcloud", "beta run services describe api", true},
+		{"gcloud", "compute instances delete vm-1", false},
+		{"gcloud", "auth print-access-token", false},
+		{"az", "vm list -g rg", true},
+		{"az", "account show", true},
+		{"az", "vm delete -n list", false},
+		{"az", "group create --name rg", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cli+" "+tt.line, func(t *testing.T) {
+			op := Classify(tt.cli, strings.Fields(tt.line))
+			assert.Equal(t, tt.readOnly, op.ReadOnly, "words %v", op.Words)
+		})
+	}
+}
+
+func TestCLIPolicies_Check(t *testing.T) {
+	cfg := config.Default()
+	cfg.Security.CLIPolicies = []config.CLIPolicy{
+		{CLI: "kubectl", Allow: []string{"rollout restart"}, Deny: []string{"get secret*"}},
+		{CLI: "aws", Commands: []string{"aws", "aws2"}, Allow: []string{"s3 cp"}},
+	}
+	require.NoError(t, cfg.Validate())
+	c := NewCLIPolicies(cfg)
+	assert.Equal(t, 2, c.Len())
+
+	check := func(command, line string) (string, *CLIDenial) {
+		return c.Check(command, strings.Fields(line))
+	}
+
+	cli, denial := check("kubectl", "get pods")
+	assert.Equal(t, "kubectl", cli)
+	assert.Nil(t, denial)
+
+	_, denial = check("kubectl", "rollout restart deploy/api")
+	assert.Nil(t, denial, "allowed entries override the classification")
+
+	_, denial = check("/usr/local/bin/kubectl", "-n prod get secrets")
+	require.NotNil(t, denial, "denied entries win over read-only operations")
+	assert.Contains(t, denial.Reason, `"get secret*"`)
+
+	_, denial = check("kubectl", "delete ns prod")
+	require.NotNil(t, denial)
+	assert.Equal(t, "delete ns prod", denial.Operation)
+
+	_, denial = check("aws2", "s3 cp a s3://bucket/a")
+	assert.Nil(t, denial)
+	_, denial = check("aws2", "s3 rb s3://bucket")
+	assert.NotNil(t, denial)
+
+	cli, denial = check("gcloud", "compute instances delete vm-1")
+	assert.Empty(t, cli, "no policy applies")
+	assert.Nil(t, denial)
+}
+
+func TestCLIPolicies_Validation(t *testing.T) {
+	cfg := config.Default()
+	cfg.Security.CLIPolicies = []config.CLIPolicy{{CLI: "terraform"}}
+	assert.ErrorContains(t, cfg.Validate(), "unknown cli")
+
+	cfg.Security.CLIPolicies = []config.CLIPolicy{{CLI: "aws", Allow: []string{"--force"}}}
+	assert.ErrorContains(t, cfg.Validate(), "invalid operation")
+}
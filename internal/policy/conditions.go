@@ -0,0 +1,317 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// maxNextAllowedSteps bounds the search for when a denied command is next
+// allowed by all its conditions.
+const maxNextAllowedSteps = 32
+
+// ConditionDenial explains why a condition denies a run.
+type ConditionDenial struct {
+	Condition   string
+	Reason      string
+	NextAllowed time.Time // Zero if never
+}
+
+// Error describes the denial and when the command is next allowed.
+func (d *ConditionDenial) Error() string {
+	msg := fmt.Sprintf("denied by condition %s: %s", d.Condition, d.Reason)
+	if !d.NextAllowed.IsZero() {
+		msg += "; next allowed at " + d.NextAllowed.Format("2006-01-02 15:04 MST")
+	}
+	return msg
+}
+
+// condition is a parsed policy condition.
+type condition struct {
+	config.PolicyCondition
+	loc     *time.Location
+	windows []window
+	period  time.Duration
+}
+
+type window struct {
+	days       [7]bool
+	start, end time.Duration
+}
+
+// Conditions evaluates the time window and run count conditions of the
+// security policy. Run counters are kept in a state file so limits hold
+// across restarts.
+type Conditions struct {
+	conditions []condition
+	path       string
+	now        func() time.Time
+	mu         sync.Mutex
+}
+
+// StateFile returns the file run counters are kept in.
+func StateFile(cfg *config.Config) string {
+	if cfg.Security.StateFile != "" {
+		return cfg.Security.StateFile
+	}
+	return filepath.Join(config.StateDir(), "policy-state.json")
+}
+
+// NewConditions parses the configured conditions. Conditions that fail to
+// parse are skipped; Validate rejects them at load.
+func NewConditions(cfg *config.Config) *Conditions {
+	c := &Conditions{path: StateFile(cfg), now: time.Now}
+	for _, pc := range cfg.Security.Conditions {
+		cond := condition{PolicyCondition: pc}
+		loc, err := pc.Location()
+		if err != nil {
+			continue
+		}
+		cond.loc = loc
+		for _, w := range pc.Windows {
+			days, start, end, err := w.Bounds()
+			if err != nil {
+				continue
+			}
+			cond.windows = append(cond.windows, window{days: days, start: start, end: end})
+		}
+		if pc.MaxRuns > 0 {
+			cond.period = pc.Period.Std()
+		}
+		c.conditions = append(c.conditions, cond)
+	}
+	return c
+}
+
+// Len returns the number of conditions.
+func (c *Conditions) Len() int {
+	return len(c.conditions)
+}
+
+// Check returns the names of the conditions matching a run and the first
+// that denies it, without counting the run.
+func (c *Conditions) Check(command string, args []string) ([]string, *ConditionDenial) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	runs, err := c.load()
+	if err != nil {
+		return nil, &ConditionDenial{Condition: "state", Reason: err.Error()}
+	}
+	matched, denial := c.evaluate(command, args, runs, c.now())
+	return names(matched), denial
+}
+
+// Admit checks a run and, if it is allowed, counts it against the run
+// limits of the matching conditions.
+func (c *Conditions) Admit(command string, args []string) *ConditionDenial {
+	if len(c.conditions) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	runs, err := c.load()
+	if err != nil {
+		return &ConditionDenial{Condition: "state", Reason: err.Error()}
+	}
+
+	now := c.now()
+	matched, denial := c.evaluate(command, args, runs, now)
+	if denial != nil {
+		return denial
+	}
+
+	counted := false
+	for _, cond := range matched {
+		if cond.MaxRuns > 0 {
+			runs[cond.Name] = append(recent(runs[cond.Name], now, cond.period), now)
+			counted = true
+		}
+	}
+	if counted {
+		if err := c.save(runs); err != nil {
+			return &ConditionDenial{Condition: "state", Reason: err.Error()}
+		}
+	}
+	return nil
+}
+
+// evaluate returns the conditions matching a run and the first denial.
+func (c *Conditions) evaluate(command string, args []string, runs map[string][]time.Time, now time.Time) ([]condition, *ConditionDenial) {
+	var matched []condition
+	var denial *ConditionDenial
+	for _, cond := range c.conditions {
+		if !cond.Matches(command, args) {
+			continue
+		}
+		matched = append(matched, cond)
+		if denial != nil {
+			continue
+		}
+
+		history := runs[cond.Name]
+		var reasons []string
+		if !cond.inWindow(now) {
+			var windows []string
+			for _, w := range cond.Windows {
+				windows = append(windows, w.String())
+			}
+			reasons = append(reasons, fmt.Sprintf("only allowed %s (%s)", strings.Join(windows, "; "), cond.loc))
+		}
+		if cond.MaxRuns > 0 {
+			if n := len(recent(history, now, cond.period)); n >= cond.MaxRuns {
+				reasons = append(reasons, fmt.Sprintf("%d of %d runs used in the last %s", n, cond.MaxRuns, cond.Period))
+			}
+		}
+		if len(reasons) > 0 {
+			denial = &ConditionDenial{
+				Condition:   cond.Name,
+				Reason:      strings.Join(reasons, " and "),
+				NextAllowed: cond.nextAllowed(history, now),
+			}
+		}
+	}
+	return matched, denial
+}
+
+// inWindow reports whether t falls in one of the condition's windows, or
+// the condition has none.
+func (c *condition) inWindow(t time.Time) bool {
+	if len(c.windows) == 0 {
+		return true
+	}
+
+	t = t.In(c.loc)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc)
+	offset := t.Sub(midnight)
+	today := t.Weekday()
+	yesterday := (today + 6) % 7
+
+	for _, w := range c.windows {
+		if w.start < w.end {
+			if w.days[today] && offset >= w.start && offset < w.end {
+				return true
+			}
+			continue
+		}
+		// The window runs past midnight
+		if (w.days[today] && offset >= w.start) || (w.days[yesterday] && offset < w.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextWindowStart returns the first window start after t.
+func (c *condition) nextWindowStart(t time.Time) time.Time {
+	t = t.In(c.loc)
+	var next time.Time
+	for i := 0; i <= 7; i++ {
+		day := time.Date(t.Year(), t.Month(), t.Day()+i, 0, 0, 0, 0, c.loc)
+		for _, w := range c.windows {
+			if !w.days[day.Weekday()] {
+				continue
+			}
+			start := day.Add(w.start)
+			if start.After(t) && (next.IsZero() || start.Before(next)) {
+				next = start
+			}
+		}
+		if !next.IsZero() {
+			return next
+		}
+	}
+	return next
+}
+
+// nextAllowed returns the first time both the windows and run limit of the
+// condition allow a run, given the runs so far.
+func (c *condition) nextAllowed(history []time.Time, now time.Time) time.Time {
+	t := now
+	for i := 0; i < maxNextAllowedSteps; i++ {
+		allowed := true
+		if !c.inWindow(t) {
+			t = c.nextWindowStart(t)
+			if t.IsZero() {
+				return t
+			}
+			allowed = false
+		}
+		if c.MaxRuns > 0 {
+			if r := recent(history, t, c.period); len(r) >= c.MaxRuns {
+				// The oldest run counted leaves the period
+				t = r[len(r)-c.MaxRuns].Add(c.period)
+				allowed = false
+			}
+		}
+		if allowed {
+			return t.In(c.loc)
+		}
+	}
+	return time.Time{}
+}
+
+// recent returns the runs within period before now.
+func recent(runs []time.Time, now time.Time, period time.Duration) []time.Time {
+	var kept []time.Time
+	for _, r := range runs {
+		if now.Sub(r) < period {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+func names(conditions []condition) []string {
+	var out []string
+	for _, c := range conditions {
+		out = append(out, c.Name)
+	}
+	return out
+}
+
+// load reads the run counters.
+func (c *Conditions) load() (map[string][]time.Time, error) {
+	runs := make(map[string][]time.Time)
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return runs, nil
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read policy state")
+	}
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to parse policy state")
+	}
+	return runs, nil
+}
+
+// save writes the run counters atomically.
+func (c *Conditions) save(runs map[string][]time.Time) error {
+	data, err := json.Marshal(runs)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to encode policy state")
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to create policy state directory")
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to write policy state")
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to write policy state")
+	}
+	return nil
+}
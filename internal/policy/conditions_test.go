@@ -0,0 +1,118 @@
+package policy
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConditions(t *testing.T, conditions ...config.PolicyCondition) (*Conditions, *time.Time) {
+	cfg := config.Default()
+	cfg.Security.StateFile = filepath.Join(t.TempDir(), "state.json")
+	cfg.Security.Conditions = conditions
+	require.NoError(t, cfg.Validate())
+
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC) // A Wednesday
+	c := NewConditions(cfg)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestConditions_Windows(t *testing.T) {
+	c, now := testConditions(t, config.PolicyCondition{
+		Name:     "deploy-hours",
+		Commands: []string{"re:^deploy"},
+		Windows:  []config.TimeWindow{{Days: []string{"weekdays"}, Start: "09:00", End: "17:00"}},
+		Timezone: "UTC",
+	})
+
+	assert.Nil(t, c.Admit("deploy.sh", nil))
+	assert.Nil(t, c.Admit("build", nil), "other commands are not restricted")
+
+	// After hours the next window is tomorrow morning
+	*now = time.Date(2026, 10, 14, 18, 30, 0, 0, time.UTC)
+	denial := c.Admit("deploy.sh", nil)
+	require.NotNil(t, denial)
+	assert.Equal(t, "deploy-hours", denial.Condition)
+	assert.Equal(t, time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC), denial.NextAllowed.UTC())
+	assert.Contains(t, denial.Error(), "weekdays 09:00-17:00")
+
+	// On Friday evening it is Monday morning
+	*now = time.Date(2026, 10, 16, 17, 0, 0, 0, time.UTC)
+	denial = c.Admit("deploy.sh", nil)
+	require.NotNil(t, denial)
+	assert.Equal(t, time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), denial.NextAllowed.UTC())
+}
+
+func TestConditions_OvernightWindow(t *testing.T) {
+	c, now := testConditions(t, config.PolicyCondition{
+		Name:     "maintenance",
+		Commands: []string{"migrate"},
+		Windows:  []config.TimeWindow{{Days: []string{"sat"}, Start: "22:00", End: "02:00"}},
+		Timezone: "UTC",
+	})
+
+	*now = time.Date(2026, 10, 18, 1, 0, 0, 0, time.UTC) // Sunday 01:00, inside Saturday's window
+	assert.Nil(t, c.Admit("migrate", nil))
+
+	*now = time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC)
+	denial := c.Admit("migrate", nil)
+	require.NotNil(t, denial)
+	assert.Equal(t, time.Date(2026, 10, 24, 22, 0, 0, 0, time.UTC), denial.NextAllowed.UTC())
+}
+
+func TestConditions_MaxRuns(t *testing.T) {
+	c, now := testConditions(t, config.PolicyCondition{
+		Name:     "terraform-apply",
+		Commands: []string{"terraform"},
+		Args:     []string{"apply"},
+		MaxRuns:  3,
+		Period:   config.Duration(24 * time.Hour),
+	})
+	start := *now
+
+	for i := 0; i < 3; i++ {
+		require.Nil(t, c.Admit("terraform", []string{"apply", "-auto-approve"}))
+		*now = now.Add(time.Hour)
+	}
+	assert.Nil(t, c.Admit("terraform", []string{"plan"}), "other arguments are not counted")
+
+	denial := c.Admit("terraform", []string{"apply"})
+	require.NotNil(t, denial)
+	assert.Contains(t, denial.Reason, "3 of 3 runs")
+	assert.Equal(t, start.Add(24*time.Hour), denial.NextAllowed.UTC())
+
+	// Denied runs are not counted, and counters survive a restart
+	c2, now2 := testConditions(t)
+	c2.conditions, c2.path = c.conditions, c.path
+	*now2 = start.Add(24 * time.Hour)
+	assert.Nil(t, c2.Admit("terraform", []string{"apply"}))
+	assert.NotNil(t, c2.Admit("terraform", []string{"apply"}))
+
+	// Check does not count runs
+	*now2 = start.Add(25 * time.Hour)
+	matched, denial := c2.Check("terraform", []string{"apply"})
+	assert.Equal(t, []string{"terraform-apply"}, matched)
+	assert.Nil(t, denial)
+	assert.Nil(t, c2.Admit("terraform", []string{"apply"}))
+}
+
+func TestConditions_Validation(t *testing.T) {
+	invalid := []config.PolicyCondition{
+		{Name: "no-limits", Commands: []string{"deploy"}},
+		{Name: "bad-day", Commands: []string{"deploy"}, Windows: []config.TimeWindow{{Days: []string{"funday"}, Start: "09:00", End: "17:00"}}},
+		{Name: "bad-time", Commands: []string{"deploy"}, Windows: []config.TimeWindow{{Start: "9am", End: "17:00"}}},
+		{Name: "bad-zone", Commands: []string{"deploy"}, Timezone: "Mars/Olympus", MaxRuns: 1, Period: config.Duration(time.Hour)},
+		{Name: "no-period", Commands: []string{"deploy"}, MaxRuns: 1},
+		{Name: "no-commands", MaxRuns: 1, Period: config.Duration(time.Hour)},
+	}
+	for _, cond := range invalid {
+		cfg := config.Default()
+		cfg.Security.Conditions = []config.PolicyCondition{cond}
+		assert.Error(t, cfg.Validate(), cond.Name)
+	}
+}
@@ -0,0 +1,308 @@
+package policy
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// Package operations.
+const (
+	PackageInstall   = "install"
+	PackageUpgrade   = "upgrade"
+	PackageUninstall = "uninstall"
+)
+
+// PackageOperation is a package manager command line as its module
+// understands it.
+type PackageOperation struct {
+	Operation string // install, upgrade or uninstall; empty for others
+	Packages  []string
+	Manifest  bool   // Installs a project's declared dependencies
+	Source    string // Option that can change where packages come from
+}
+
+// PackageApproval explains why a package operation needs approval.
+type PackageApproval struct {
+	Manager   string
+	Operation string
+	Reason    string
+}
+
+// PackagePolicies evaluates the package policies of the security policy.
+type PackagePolicies struct {
+	policies []config.PackagePolicy
+}
+
+// NewPackagePolicies returns the configured package policies.
+func NewPackagePolicies(cfg *config.Config) *PackagePolicies {
+	return &PackagePolicies{policies: cfg.Security.PackagePolicies}
+}
+
+// Len returns the number of package policies.
+func (p *PackagePolicies) Len() int {
+	return len(p.policies)
+}
+
+// Check returns the manager whose policy applies to a run, or "" if none
+// does, and why the run needs approval if it does. The first policy whose
+// commands match decides.
+func (p *PackagePolicies) Check(command string, args []string) (string, *PackageApproval) {
+	for _, pol := range p.policies {
+		if !pol.Matches(command) {
+			continue
+		}
+		op := ClassifyPackages(pol.Manager, command, args)
+		if op.Operation == "" {
+			return pol.Manager, nil
+		}
+		approval := &PackageApproval{Manager: pol.Manager, Operation: op.Operation}
+		var unlisted []string
+		for _, raw := range op.Packages {
+			spec := config.ParsePackageSpec(pol.Manager, raw)
+			if !pol.Allows(spec, op.Operation == PackageUninstall) {
+				unlisted = append(unlisted, raw)
+			}
+		}
+		switch {
+		case op.Source != "":
+			approval.Reason = "uses option " + op.Source + ", which can change where packages come from"
+		case op.Manifest && !pol.AllowManifest:
+			approval.Reason = "installs the project's declared dependencies"
+		case len(op.Packages) == 0 && op.Operation == PackageUpgrade:
+			approval.Reason = "upgrades all installed packages"
+		case len(op.Packages) == 0 && !op.Manifest:
+			approval.Reason = "names no packages"
+		case len(unlisted) > 0:
+			approval.Reason = "packages not allowlisted or not at their pinned version: " + strings.Join(unlisted, ", ")
+		default:
+			return pol.Manager, nil
+		}
+		return pol.Manager, approval
+	}
+	return "", nil
+}
+
+// ClassifyPackages reads a package manager command line. Operations other
+// than installs, upgrades and uninstalls, such as listing packages, have
+// no operation.
+func ClassifyPackages(manager, command string, args []string) PackageOperation {
+	switch manager {
+	case "npm":
+		return classifyNpm(args)
+	case "pip":
+		return classifyPip(command, args)
+	case "brew":
+		return classifyBrew(args)
+	case "apt":
+		return classifyApt(args)
+	case "winget":
+		return classifyWinget(args)
+	}
+	return PackageOperation{}
+}
+
+// packageWords splits arguments into positional words and the values of
+// flags in valueFlags, keyed by flag; a flag=value argument counts as the
+// flag with a value. Unknown flags are taken to have no separate value.
+func packageWords(args []string, valueFlags map[string]bool) ([]string, map[string][]string) {
+	var words []string
+	values := map[string][]string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return append(words, args[i+1:]...), values
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			if name, value, ok := strings.Cut(arg, "="); ok {
+				values[name] = append(values[name], value)
+			} else if valueFlags[arg] && i+1 < len(args) {
+				values[arg] = append(values[arg], args[i+1])
+				i++
+			} else {
+				values[arg] = append(values[arg], "")
+			}
+		default:
+			words = append(words, arg)
+		}
+	}
+	return words, values
+}
+
+// sourceFlag returns the first of flags that was given.
+func sourceFlag(values map[string][]string, flags ...string) string {
+	for _, f := range flags {
+		if _, ok := values[f]; ok {
+			return f
+		}
+	}
+	return ""
+}
+
+// has reports whether any of flags was given.
+func has(values map[string][]string, flags ...string) bool {
+	return sourceFlag(values, flags...) != ""
+}
+
+var npmValueFlags = flagSet("--registry", "--prefix", "-w", "--workspace", "--tag", "--cache", "--userconfig", "-C")
+
+// classifyNpm reads npm install, update and uninstall, and their aliases.
+func classifyNpm(args []string) PackageOperation {
+	words, values := packageWords(args, npmValueFlags)
+	if len(words) == 0 {
+		return PackageOperation{}
+	}
+	op := PackageOperation{Packages: words[1:], Source: sourceFlag(values, "--registry", "--userconfig")}
+	switch words[0] {
+	case "install", "i", "in", "ins", "inst", "insta", "instal", "isnt", "isnta", "isntal", "isntall", "add":
+		op.Operation = PackageInstall
+		op.Manifest = len(op.Packages) == 0
+	case "ci", "clean-install", "install-clean", "isntall-clean":
+		op.Operation = PackageInstall
+		op.Manifest = true
+	case "update", "up", "upgrade", "udpate":
+		op.Operation = PackageUpgrade
+	case "uninstall", "un", "unlink", "remove", "rm", "r":
+		op.Operation = PackageUninstall
+	default:
+		return PackageOperation{}
+	}
+	return op
+}
+
+var pipValueFlags = flagSet("-r", "--requirement", "-c", "--constraint", "-e", "--editable",
+	"-i", "--index-url", "--extra-index-url", "-f", "--find-links", "--trusted-host",
+	"-t", "--target", "--prefix", "--root", "--src", "--platform", "--python-version",
+	"--implementation", "--abi", "--upgrade-strategy", "--cache-dir", "--log", "--proxy",
+	"--timeout", "--retries", "--report", "-C", "--config-settings", "--progress-bar",
+	"--no-binary", "--only-binary")
+
+// classifyPip reads pip install and uninstall, including python -m pip.
+func classifyPip(command string, args []string) PackageOperation {
+	if len(args) >= 2 && args[0] == "-m" && args[1] == "pip" {
+		args = args[2:]
+	} else if strings.HasPrefix(strings.ToLower(filepath.Base(command)), "python") {
+		return PackageOperation{}
+	}
+	words, values := packageWords(args, pipValueFlags)
+	if len(words) == 0 {
+		return PackageOperation{}
+	}
+	op := PackageOperation{
+		Packages: words[1:],
+		Source:   sourceFlag(values, "-i", "--index-url", "--extra-index-url", "-f", "--find-links", "-e", "--editable"),
+	}
+	switch words[0] {
+	case "install", "download":
+		op.Operation = PackageInstall
+		if has(values, "-U", "--upgrade") {
+			op.Operation = PackageUpgrade
+		}
+		op.Manifest = has(values, "-r", "--requirement")
+	case "uninstall":
+		op.Operation = PackageUninstall
+	default:
+		return PackageOperation{}
+	}
+	return op
+}
+
+// classifyBrew reads brew install, upgrade, uninstall and bundle.
+func classifyBrew(args []string) PackageOperation {
+	words, _ := packageWords(args, nil)
+	if len(words) == 0 {
+		return PackageOperation{}
+	}
+	op := PackageOperation{Packages: words[1:]}
+	switch words[0] {
+	case "install", "reinstall", "tap":
+		op.Operation = PackageInstall
+	case "upgrade":
+		op.Operation = PackageUpgrade
+	case "uninstall", "remove", "rm", "untap":
+		op.Operation = PackageUninstall
+	case "bundle":
+		// Only brew bundle [install] installs the Brewfile
+		if len(words) > 1 && words[1] != "install" {
+			return PackageOperation{}
+		}
+		return PackageOperation{Operation: PackageInstall, Manifest: true}
+	default:
+		return PackageOperation{}
+	}
+	return op
+}
+
+var aptValueFlags = flagSet("-o", "--option", "-t", "--target-release", "-c", "--config-file")
+
+// classifyApt reads apt and apt-get install, upgrade and remove.
+func classifyApt(args []string) PackageOperation {
+	words, values := packageWords(args, aptValueFlags)
+	if len(words) == 0 {
+		return PackageOperation{}
+	}
+	op := PackageOperation{Packages: words[1:], Source: sourceFlag(values, "-o", "--option", "-c", "--config-file")}
+	switch words[0] {
+	case "install", "reinstall":
+		op.Operation = PackageInstall
+	case "upgrade", "full-upgrade", "dist-upgrade":
+		op.Operation = PackageUpgrade
+	case "remove", "purge", "autoremove", "autopurge":
+		op.Operation = PackageUninstall
+	default:
+		return PackageOperation{}
+	}
+	return op
+}
+
+var wingetValueFlags = flagSet("--id", "--name", "-q", "--query", "--moniker", "-v", "--version",
+	"-s", "--source", "--scope", "-l", "--location", "--override", "--custom", "-o", "--log",
+	"-m", "--manifest", "--header", "--locale", "-a", "--architecture", "--installer-type",
+	"-i", "--import-file")
+
+// classifyWinget reads winget install, upgrade, uninstall and import.
+func classifyWinget(args []string) PackageOperation {
+	words, values := packageWords(args, wingetValueFlags)
+	if len(words) == 0 {
+		return PackageOperation{}
+	}
+	names := append([]string{}, words[1:]...)
+	for _, f := range []string{"--id", "--name", "-q", "--query", "--moniker"} {
+		names = append(names, values[f]...)
+	}
+	if version := lastValue(values, "-v", "--version"); version != "" {
+		for i := range names {
+			names[i] += "==" + version
+		}
+	}
+	op := PackageOperation{Packages: names, Source: sourceFlag(values, "-s", "--source", "-m", "--manifest", "--override", "--custom")}
+	switch words[0] {
+	case "install", "add":
+		op.Operation = PackageInstall
+	case "upgrade", "update":
+		// Without packages or --all, upgrade lists available upgrades
+		if len(names) == 0 && !has(values, "--all", "-r", "--recurse") {
+			return PackageOperation{}
+		}
+		op.Operation = PackageUpgrade
+	case "uninstall", "remove", "rm":
+		op.Operation = PackageUninstall
+	case "import":
+		return PackageOperation{Operation: PackageInstall, Manifest: true}
+	default:
+		return PackageOperation{}
+	}
+	return op
+}
+
+// lastValue returns the last value given for any of flags.
+func lastValue(values map[string][]string, flags ...string) string {
+	var last string
+	for _, f := range flags {
+		if v := values[f]; len(v) > 0 {
+			last = v[len(v)-1]
+		}
+	}
+	return last
+}
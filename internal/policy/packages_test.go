@@ -0,0 +1,97 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPackagePolicies(t *testing.T, policies ...config.PackagePolicy) *PackagePolicies {
+	cfg := config.Default()
+	cfg.Security.PackagePolicies = policies
+	require.NoError(t, cfg.Validate())
+	return NewPackagePolicies(cfg)
+}
+
+func TestClassifyPackages(t *testing.T) {
+	tests := []struct {
+		manager, command, line string
+		want                   PackageOperation
+	}{
+		{"npm", "npm", "install -D eslint@8.57.0", PackageOperation{Operation: PackageInstall, Packages: []string{"eslint@8.57.0"}}},
+		{"npm", "npm", "ci", PackageOperation{Operation: PackageInstall, Manifest: true}},
+		{"npm", "npm", "i --registry https://evil.example lodash", PackageOperation{Operation: PackageInstall, Packages: []string{"lodash"}, Source: "--registry"}},
+		{"npm", "npm", "run build", PackageOperation{}},
+		{"pip", "pip3", "install -U requests", PackageOperation{Operation: PackageUpgrade, Packages: []string{"requests"}}},
+		{"pip", "python3", "-m pip install -r requirements.txt", PackageOperation{Operation: PackageInstall, Manifest: true}},
+		{"pip", "python3", "script.py install", PackageOperation{}},
+		{"pip", "pip", "list", PackageOperation{}},
+		{"brew", "brew", "upgrade", PackageOperation{Operation: PackageUpgrade}},
+		{"brew", "brew", "install --cask firefox", PackageOperation{Operation: PackageInstall, Packages: []string{"firefox"}}},
+		{"apt", "apt-get", "-y install curl=7.88.1-10", PackageOperation{Operation: PackageInstall, Packages: []string{"curl=7.88.1-10"}}},
+		{"apt", "apt", "purge vim", PackageOperation{Operation: PackageUninstall, Packages: []string{"vim"}}},
+		{"winget", "winget", "install --id Git.Git -v 2.44.0 -e", PackageOperation{Operation: PackageInstall, Packages: []string{"Git.Git==2.44.0"}}},
+		{"winget", "winget", "upgrade", PackageOperation{}},
+		{"winget", "winget", "upgrade --all", PackageOperation{Operation: PackageUpgrade, Packages: []string{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command+" "+tt.line, func(t *testing.T) {
+			got := ClassifyPackages(tt.manager, tt.command, strings.Fields(tt.line))
+			if len(got.Packages) == 0 && len(tt.want.Packages) == 0 {
+				got.Packages, tt.want.Packages = nil, nil
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPackagePolicies_Check(t *testing.T) {
+	p := testPackagePolicies(t,
+		config.PackagePolicy{Manager: "npm", Packages: []string{"eslint@8.57.0", "@types/*", "typescript"}, AllowManifest: true},
+		config.PackagePolicy{Manager: "pip", Commands: []string{"pip", "python3"}, Packages: []string{"requests==2.31.0", "Flask_Login"}},
+	)
+	assert.Equal(t, 2, p.Len())
+
+	check := func(command, line string) *PackageApproval {
+		_, held := p.Check(command, strings.Fields(line))
+		return held
+	}
+
+	assert.Nil(t, check("npm", "install -D eslint@8.57.0 @types/node typescript@^5"))
+	assert.Nil(t, check("npm", "ci"), "manifest installs are allowed")
+	assert.Nil(t, check("npm", "uninstall eslint"), "uninstalls ignore pins")
+	assert.Nil(t, check("npm", "test"))
+
+	held := check("npm", "install eslint@9.0.0 left-pad")
+	require.NotNil(t, held, "other versions of pinned packages need approval")
+	assert.Equal(t, PackageInstall, held.Operation)
+	assert.Contains(t, held.Reason, "eslint@9.0.0, left-pad")
+
+	assert.NotNil(t, check("npm", "install eslint"), "a pinned package needs its version")
+	assert.NotNil(t, check("npm", "install typescript@npm:evil"), "aliases install other packages")
+	assert.NotNil(t, check("npm", "install github:user/typescript"))
+	assert.NotNil(t, check("npm", "update"))
+
+	assert.Nil(t, check("python3", "-m pip install requests==2.31.0 flask-login"))
+	assert.NotNil(t, check("pip", "install requests>=2.31.0"))
+	assert.NotNil(t, check("pip", "install -r requirements.txt"), "manifest installs need allow_manifest")
+	held = check("pip", "install --index-url https://mirror.example requests==2.31.0")
+	require.NotNil(t, held)
+	assert.Contains(t, held.Reason, "--index-url")
+
+	manager, held := p.Check("brew", []string{"install", "anything"})
+	assert.Empty(t, manager, "no policy applies")
+	assert.Nil(t, held)
+}
+
+func TestPackagePolicies_Validation(t *testing.T) {
+	cfg := config.Default()
+	cfg.Security.PackagePolicies = []config.PackagePolicy{{Manager: "cargo"}}
+	assert.ErrorContains(t, cfg.Validate(), "unknown manager")
+
+	cfg.Security.PackagePolicies = []config.PackagePolicy{{Manager: "npm", Packages: []string{"./local"}}}
+	assert.ErrorContains(t, cfg.Validate(), "invalid package")
+}
@@ -0,0 +1,217 @@
+// Package policy records commands denied in learn mode and suggests the
+// configuration changes that would allow them
+package policy
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// Reasons a request was denied.
+const (
+	ReasonCommand = "command" // Not in allowed_commands or in blocked_commands
+	ReasonPath    = "path"    // Working directory outside allowed_paths
+)
+
+// maxExamples limits the argument examples kept per command.
+const maxExamples = 5
+
+// Denial is a request that the security policy refused.
+type Denial struct {
+	Time    time.Time `json:"time"`
+	Reason  string    `json:"reason"`
+	Command string    `json:"command"`
+	Args    []string  `json:"args,omitempty"`
+	WorkDir string    `json:"workdir,omitempty"`
+	Client  string    `json:"client,omitempty"` // Client name reported by the session
+	User    string    `json:"user,omitempty"`   // Authenticated principal
+}
+
+// SuggestionsFile returns the file denials are recorded to.
+func SuggestionsFile(cfg *config.Config) string {
+	if cfg.Security.SuggestionsFile != "" {
+		return cfg.Security.SuggestionsFile
+	}
+	return filepath.Join(config.StateDir(), "policy-suggestions.jsonl")
+}
+
+// Recorder appends denials to the suggestions file.
+type Recorder struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewRecorder creates a recorder writing to path.
+func NewRecorder(path string) *Recorder {
+	return &Recorder{path: path}
+}
+
+// Record appends a denial.
+func (r *Recorder) Record(d Denial) error {
+	if d.Time.IsZero() {
+		d.Time = time.Now()
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to encode denial")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o700); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to create suggestions directory")
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to open suggestions file")
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to record denial")
+	}
+	return nil
+}
+
+// Load reads the denials recorded in a suggestions file. Malformed lines
+// are skipped.
+func Load(path string) ([]Denial, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, apperrors.NotFoundError("no suggestions recorded yet: "+path, path)
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to open suggestions file")
+	}
+	defer f.Close()
+
+	var denials []Denial
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var d Denial
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			continue
+		}
+		denials = append(denials, d)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read suggestions file")
+	}
+	return denials, nil
+}
+
+// CommandSuggestion summarizes the denials of one command.
+type CommandSuggestion struct {
+	Command  string    `json:"command"`
+	Count    int       `json:"count"`
+	Blocked  bool      `json:"blocked"` // Listed in blocked_commands
+	LastSeen time.Time `json:"last_seen"`
+	Examples []string  `json:"examples,omitempty"` // Distinct argument lists
+}
+
+// PathSuggestion summarizes the denials of one working directory.
+type PathSuggestion struct {
+	Path     string    `json:"path"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Summary is what an operator would need to allow to support the
+// recorded requests.
+type Summary struct {
+	Total    int                 `json:"total"`
+	Commands []CommandSuggestion `json:"commands,omitempty"`
+	Paths    []PathSuggestion    `json:"paths,omitempty"`
+}
+
+// Summarize groups denials by command and path, most requested first.
+// Entries the configuration allows by now are left out.
+func Summarize(denials []Denial, cfg *config.Config) *Summary {
+	summary := &Summary{}
+	commands := make(map[string]*CommandSuggestion)
+	paths := make(map[string]*PathSuggestion)
+
+	for _, d := range denials {
+		switch d.Reason {
+		case ReasonCommand:
+			if cfg.IsCommandAllowed(d.Command, d.WorkDir) {
+				continue
+			}
+			s, ok := commands[d.Command]
+			if !ok {
+				s = &CommandSuggestion{Command: d.Command, Blocked: cfg.MatchBlockedCommand(d.Command, d.WorkDir) != ""}
+				commands[d.Command] = s
+			}
+			s.Count++
+			if d.Time.After(s.LastSeen) {
+				s.LastSeen = d.Time
+			}
+			example := strings.Join(d.Args, " ")
+			if example != "" && len(s.Examples) < maxExamples && !contains(s.Examples, example) {
+				s.Examples = append(s.Examples, example)
+			}
+		case ReasonPath:
+			if cfg.IsPathAllowed(d.WorkDir) {
+				continue
+			}
+			s, ok := paths[d.WorkDir]
+			if !ok {
+				s = &PathSuggestion{Path: d.WorkDir}
+				paths[d.WorkDir] = s
+			}
+			s.Count++
+			if d.Time.After(s.LastSeen) {
+				s.LastSeen = d.Time
+			}
+		default:
+			continue
+		}
+		summary.Total++
+	}
+
+	for _, s := range commands {
+		summary.Commands = append(summary.Commands, *s)
+	}
+	sort.Slice(summary.Commands, func(i, j int) bool {
+		a, b := summary.Commands[i], summary.Commands[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Command < b.Command
+	})
+
+	for _, s := range paths {
+		summary.Paths = append(summary.Paths, *s)
+	}
+	sort.Slice(summary.Paths, func(i, j int) bool {
+		a, b := summary.Paths[i], summary.Paths[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Path < b.Path
+	})
+
+	return summary
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_RecordAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "suggestions.jsonl")
+	r := NewRecorder(path)
+
+	require.NoError(t, r.Record(Denial{Reason: ReasonCommand, Command: "make", Args: []string{"build"}}))
+	require.NoError(t, r.Record(Denial{Reason: ReasonPath, Command: "ls", WorkDir: "/srv"}))
+
+	// Malformed lines are skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString("not json\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	denials, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, denials, 2)
+	assert.Equal(t, "make", denials[0].Command)
+	assert.False(t, denials[0].Time.IsZero())
+	assert.Equal(t, "/srv", denials[1].WorkDir)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.jsonl"))
+	assert.Error(t, err)
+}
+
+func TestSummarize(t *testing.T) {
+	cfg := config.Default()
+	cfg.Security.AllowedCommands = []string{"ls", "git"}
+	cfg.Security.AllowedPaths = []string{"/home"}
+
+	denials := []Denial{
+		{Reason: ReasonCommand, Command: "make", Args: []string{"build"}},
+		{Reason: ReasonCommand, Command: "make", Args: []string{"test"}},
+		{Reason: ReasonCommand, Command: "make", Args: []string{"build"}},
+		{Reason: ReasonCommand, Command: "rm", Args: []string{"-rf", "dist"}},
+		{Reason: ReasonCommand, Command: "git"}, // Allowed by now
+		{Reason: ReasonPath, Command: "ls", WorkDir: "/srv/app"},
+	}
+
+	summary := Summarize(denials, cfg)
+	assert.Equal(t, 5, summary.Total)
+
+	require.Len(t, summary.Commands, 2)
+	assert.Equal(t, "make", summary.Commands[0].Command)
+	assert.Equal(t, 3, summary.Commands[0].Count)
+	assert.Equal(t, []string{"build", "test"}, summary.Commands[0].Examples)
+	assert.False(t, summary.Commands[0].Blocked)
+	assert.Equal(t, "rm", summary.Commands[1].Command)
+	assert.True(t, summary.Commands[1].Blocked)
+
+	require.Len(t, summary.Paths, 1)
+	assert.Equal(t, "/srv/app", summary.Paths[0].Path)
+}
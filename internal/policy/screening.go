@@ -0,0 +1,264 @@
+package policy
+
+import (
+	"encoding/base64"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// pasteSites are paste and anonymous file sharing services, which
+// injected instructions fetch payloads from or send data to.
+var pasteSites = []string{
+	"pastebin.com", "paste.ee", "hastebin.com", "ghostbin.com", "rentry.co",
+	"rentry.org", "paste.rs", "dpaste.org", "dpaste.com", "termbin.com",
+	"transfer.sh", "0x0.st", "ix.io", "sprunge.us", "controlc.com",
+	"justpaste.it", "privatebin.net", "pastie.org", "paste.debian.net",
+	"katb.in", "bpa.st", "pastes.io", "file.io", "temp.sh",
+}
+
+// shellRCFiles are the base names of shell startup files; commands
+// written to them run in every new shell.
+var shellRCFiles = []string{
+	".bashrc", ".bash_profile", ".bash_login", ".bash_logout", ".profile",
+	".zshrc", ".zshenv", ".zprofile", ".zlogin", ".zlogout", ".cshrc",
+	".tcshrc", ".login", ".kshrc", ".mkshrc", "config.fish", "bash.bashrc",
+	"microsoft.powershell_profile.ps1", "profile.ps1",
+}
+
+// shellRCPaths are system-wide shell startup files whose base names are
+// too generic to match alone.
+var shellRCPaths = []string{
+	"/etc/profile", "/etc/zshrc", "/etc/zshenv", "/etc/zprofile",
+	"/etc/zsh/zshrc", "/etc/zsh/zshenv", "/etc/zsh/zprofile", "/etc/environment",
+}
+
+// rcReaders are commands that only read the files they name.
+var rcReaders = []string{
+	"cat", "less", "more", "head", "tail", "grep", "egrep", "fgrep", "rg",
+	"wc", "stat", "ls", "file", "diff", "md5sum", "sha1sum", "sha256sum",
+	"shasum", "bat",
+}
+
+// base64Token matches strings made of base64 characters.
+var base64Token = regexp.MustCompile(`^[A-Za-z0-9+/_-]+={0,2}$`)
+
+// minEncodedURLLength is the length from which base64 strings are decoded
+// to look for encoded paste site URLs.
+const minEncodedURLLength = 16
+
+// ScreeningFlag explains why a request was flagged.
+type ScreeningFlag struct {
+	Heuristic string
+	Detail    string
+}
+
+// Screening applies the configured screening heuristics.
+type Screening struct {
+	config    config.ScreeningConfig
+	pasteSite *regexp.Regexp
+	minBase64 int
+}
+
+// NewScreening returns the configured screening.
+func NewScreening(cfg *config.Config) *Screening {
+	sc := cfg.Security.Screening
+	hosts := make([]string, 0, len(pasteSites)+len(sc.PasteSites))
+	for _, host := range append(slices.Clone(pasteSites), sc.PasteSites...) {
+		hosts = append(hosts, regexp.QuoteMeta(strings.ToLower(host)))
+	}
+	minBase64 := sc.MinBase64Length
+	if minBase64 == 0 {
+		minBase64 = config.DefaultMinBase64Length
+	}
+	return &Screening{
+		config:    sc,
+		pasteSite: regexp.MustCompile(`(?:^|[^a-z0-9.-])((?:[a-z0-9-]+\.)*(?:` + strings.Join(hosts, "|") + `))(?:$|[^a-z0-9.-])`),
+		minBase64: minBase64,
+	}
+}
+
+// Enabled reports whether requests are screened.
+func (s *Screening) Enabled() bool {
+	return s.config.Enabled()
+}
+
+// Action returns what happens to flagged requests: block or approve.
+func (s *Screening) Action() string {
+	return s.config.Action
+}
+
+// Check returns why a request is flagged, or nil if it is not or
+// screening is off. Heuristics are applied from the most specific.
+func (s *Screening) Check(command string, args []string) *ScreeningFlag {
+	if !s.Enabled() {
+		return nil
+	}
+	if s.config.Applies(config.HeuristicShellRCWrite) {
+		if file := shellRCWrite(command, args); file != "" {
+			return &ScreeningFlag{config.HeuristicShellRCWrite, "may write to shell startup file " + file}
+		}
+	}
+	if s.config.Applies(config.HeuristicPasteSite) {
+		if host, how := s.findPasteSite(args); host != "" {
+			return &ScreeningFlag{config.HeuristicPasteSite, "names paste site " + host + how}
+		}
+	}
+	if s.config.Applies(config.HeuristicBase64Payload) {
+		if preview := s.findBase64Payload(args); preview != "" {
+			return &ScreeningFlag{config.HeuristicBase64Payload, "argument decodes from base64 to " + preview}
+		}
+	}
+	return nil
+}
+
+// tokens splits arguments into the words a shell would see, so strings
+// passed to sh -c are screened too, along with the values of key=value
+// words.
+func tokens(args []string) []string {
+	var out []string
+	for _, arg := range args {
+		words := strings.FieldsFunc(arg, func(r rune) bool {
+			return unicode.IsSpace(r) || strings.ContainsRune("\"'`|;&<>()", r)
+		})
+		for _, word := range words {
+			out = append(out, word)
+			if i := strings.Index(word, "="); i > 0 && i < len(word)-1 && strings.TrimRight(word[i:], "=") != "" {
+				out = append(out, word[i+1:])
+			}
+		}
+	}
+	return out
+}
+
+// shellRCWrite returns the shell startup file a request may write to: one
+// named by a command that does not only read files, or after a
+// redirection.
+func shellRCWrite(command string, args []string) string {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(command)), ".exe")
+	redirects := slices.ContainsFunc(args, func(arg string) bool { return strings.Contains(arg, ">") })
+	if slices.Contains(rcReaders, name) && !redirects {
+		return ""
+	}
+	for _, tok := range tokens(args) {
+		path := filepath.ToSlash(tok)
+		if slices.Contains(shellRCFiles, strings.ToLower(filepath.Base(path))) ||
+			slices.Contains(shellRCPaths, path) || strings.HasPrefix(path, "/etc/profile.d/") {
+			return tok
+		}
+	}
+	return ""
+}
+
+// findPasteSite returns the paste site an argument names, as is,
+// percent-encoded or base64-encoded, and how it was encoded.
+func (s *Screening) findPasteSite(args []string) (host, how string) {
+	for _, arg := range args {
+		if host := s.matchPasteSite(arg); host != "" {
+			return host, ""
+		}
+		if decoded, err := url.QueryUnescape(arg); err == nil && decoded != arg {
+			if host := s.matchPasteSite(decoded); host != "" {
+				return host, " (percent-encoded)"
+			}
+		}
+	}
+	for _, tok := range tokens(args) {
+		if len(tok) < minEncodedURLLength || !base64Token.MatchString(tok) {
+			continue
+		}
+		if decoded, ok := decodeBase64(tok); ok {
+			if host := s.matchPasteSite(string(decoded)); host != "" {
+				return host, " (base64-encoded)"
+			}
+		}
+	}
+	return "", ""
+}
+
+// matchPasteSite returns the paste site host in s, or "".
+func (s *Screening) matchPasteSite(text string) string {
+	if m := s.pasteSite.FindStringSubmatch(strings.ToLower(text)); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// findBase64Payload returns a preview of the first long argument word
+// that decodes from base64 to text or a known binary format. Strings of
+// one case, such as hex digests and git hashes, are not base64 payloads.
+func (s *Screening) findBase64Payload(args []string) string {
+	for _, tok := range tokens(args) {
+		if len(tok) < s.minBase64 || !base64Token.MatchString(tok) {
+			continue
+		}
+		if !strings.ContainsFunc(tok, unicode.IsUpper) || !strings.ContainsFunc(tok, unicode.IsLower) {
+			continue
+		}
+		decoded, ok := decodeBase64(tok)
+		if !ok {
+			continue
+		}
+		if kind := binaryKind(decoded); kind != "" {
+			return kind
+		}
+		if isText(decoded) {
+			return preview(decoded)
+		}
+	}
+	return ""
+}
+
+// decodeBase64 decodes standard or URL-safe base64, padded or not.
+func decodeBase64(s string) ([]byte, bool) {
+	s = strings.TrimRight(s, "=")
+	for _, enc := range []*base64.Encoding{base64.RawStdEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// binaryKind names the format of executables and archives by their
+// magic bytes.
+func binaryKind(b []byte) string {
+	switch {
+	case len(b) >= 2 && b[0] == 0x1f && b[1] == 0x8b:
+		return "gzip data"
+	case len(b) >= 4 && string(b[:4]) == "\x7fELF":
+		return "an ELF executable"
+	case len(b) >= 2 && string(b[:2]) == "MZ":
+		return "a Windows executable"
+	case len(b) >= 4 && string(b[:4]) == "PK\x03\x04":
+		return "a zip archive"
+	}
+	return ""
+}
+
+// isText reports whether nearly all of b is printable ASCII or
+// whitespace.
+func isText(b []byte) bool {
+	printable := 0
+	for _, c := range b {
+		if c >= 0x20 && c < 0x7f || c == '\n' || c == '\r' || c == '\t' {
+			printable++
+		}
+	}
+	return len(b) > 0 && printable*100 >= len(b)*95
+}
+
+// preview quotes the start of decoded text for logs and denials.
+func preview(b []byte) string {
+	const maxPreview = 60
+	text := strings.Join(strings.Fields(string(b)), " ")
+	if len(text) > maxPreview {
+		text = text[:maxPreview] + "..."
+	}
+	return `"` + text + `"`
+}
@@ -0,0 +1,130 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"syscall"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/shirou/gopsutil/v4/net"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// tcpListen is the status of a listening TCP socket.
+const tcpListen = "LISTEN"
+
+// Listener describes a local listening socket.
+type Listener struct {
+	Protocol string `json:"protocol"` // tcp, tcp6, udp or udp6
+	Address  string `json:"address"`
+	Port     uint32 `json:"port"`
+	PID      int32  `json:"pid,omitempty"`     // Zero when the owner cannot be determined
+	Process  string `json:"process,omitempty"` // Only set for processes the server may inspect
+}
+
+// PortOptions filters a listening port listing.
+type PortOptions struct {
+	Protocol string // tcp, udp or empty for both
+	Port     uint32 // Zero for all ports
+}
+
+// ListeningPorts returns local listening sockets. Owning processes are
+// named where permissions and the process settings allow.
+func (i *Inspector) ListeningPorts(ctx context.Context, opts PortOptions) ([]Listener, error) {
+	kind := "inet"
+	switch opts.Protocol {
+	case "":
+	case "tcp", "udp":
+		kind = opts.Protocol
+	default:
+		return nil, apperrors.ValidationError(
+			fmt.Sprintf("invalid protocol %q (expected tcp or udp)", opts.Protocol), "protocol")
+	}
+
+	conns, err := net.ConnectionsWithContext(ctx, kind)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to list sockets")
+	}
+
+	names := make(map[int32]string)
+	listeners := make([]Listener, 0)
+	for _, c := range conns {
+		protocol, ok := listening(c)
+		if !ok {
+			continue
+		}
+		if opts.Port != 0 && c.Laddr.Port != opts.Port {
+			continue
+		}
+
+		l := Listener{
+			Protocol: protocol,
+			Address:  c.Laddr.IP,
+			Port:     c.Laddr.Port,
+			PID:      c.Pid,
+		}
+		if c.Pid > 0 {
+			name, seen := names[c.Pid]
+			if !seen {
+				name = i.processName(ctx, c.Pid)
+				names[c.Pid] = name
+			}
+			l.Process = name
+		}
+		listeners = append(listeners, l)
+	}
+
+	sort.SliceStable(listeners, func(x, y int) bool {
+		if listeners[x].Port != listeners[y].Port {
+			return listeners[x].Port < listeners[y].Port
+		}
+		if listeners[x].Protocol != listeners[y].Protocol {
+			return listeners[x].Protocol < listeners[y].Protocol
+		}
+		return listeners[x].Address < listeners[y].Address
+	})
+
+	return listeners, nil
+}
+
+// listening reports whether the socket accepts connections or datagrams
+// and returns its protocol name.
+func listening(c net.ConnectionStat) (string, bool) {
+	var protocol string
+	switch c.Type {
+	case syscall.SOCK_STREAM:
+		if c.Status != tcpListen {
+			return "", false
+		}
+		protocol = "tcp"
+	case syscall.SOCK_DGRAM:
+		// Bound UDP sockets without a peer receive from anyone
+		if c.Raddr.Port != 0 {
+			return "", false
+		}
+		protocol = "udp"
+	default:
+		return "", false
+	}
+
+	if c.Family == syscall.AF_INET6 {
+		protocol += "6"
+	}
+
+	return protocol, true
+}
+
+// processName returns the name of a process the server may inspect.
+func (i *Inspector) processName(ctx context.Context, pid int32) string {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return ""
+	}
+	if !i.allUsers && !i.owner.owns(ctx, p) {
+		return ""
+	}
+
+	name, _ := p.NameWithContext(ctx)
+	return name
+}
@@ -0,0 +1,91 @@
+package process
+
+import (
+	"context"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	psnet "github.com/shirou/gopsutil/v4/net"
+)
+
+func TestInspector_ListeningPorts(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer ln.Close()
+
+	port := uint32(ln.Addr().(*net.TCPAddr).Port)
+	insp := New(config.Default())
+
+	listeners, err := insp.ListeningPorts(context.Background(), PortOptions{Protocol: "tcp", Port: port})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(listeners) != 1 {
+		t.Fatalf("expected 1 listener on port %d, got %+v", port, listeners)
+	}
+
+	l := listeners[0]
+	if l.Protocol != "tcp" || l.Address != "127.0.0.1" {
+		t.Errorf("unexpected listener: %+v", l)
+	}
+	if l.PID != 0 && l.PID != int32(os.Getpid()) {
+		t.Errorf("expected pid %d, got %d", os.Getpid(), l.PID)
+	}
+	if l.PID != 0 && l.Process == "" {
+		t.Error("expected process name for own process")
+	}
+
+	if _, err := insp.ListeningPorts(context.Background(), PortOptions{Protocol: "sctp"}); err == nil {
+		t.Error("expected error for invalid protocol")
+	}
+}
+
+func TestListening(t *testing.T) {
+	tests := []struct {
+		name     string
+		conn     psnet.ConnectionStat
+		protocol string
+		ok       bool
+	}{
+		{
+			name:     "tcp listener",
+			conn:     psnet.ConnectionStat{Family: syscall.AF_INET, Type: syscall.SOCK_STREAM, Status: "LISTEN"},
+			protocol: "tcp",
+			ok:       true,
+		},
+		{
+			name:     "tcp6 listener",
+			conn:     psnet.ConnectionStat{Family: syscall.AF_INET6, Type: syscall.SOCK_STREAM, Status: "LISTEN"},
+			protocol: "tcp6",
+			ok:       true,
+		},
+		{
+			name: "established tcp",
+			conn: psnet.ConnectionStat{Family: syscall.AF_INET, Type: syscall.SOCK_STREAM, Status: "ESTABLISHED"},
+		},
+		{
+			name:     "bound udp",
+			conn:     psnet.ConnectionStat{Family: syscall.AF_INET, Type: syscall.SOCK_DGRAM},
+			protocol: "udp",
+			ok:       true,
+		},
+		{
+			name: "connected udp",
+			conn: psnet.ConnectionStat{Family: syscall.AF_INET, Type: syscall.SOCK_DGRAM, Raddr: psnet.Addr{IP: "10.0.0.1", Port: 53}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protocol, ok := listening(tt.conn)
+			if ok != tt.ok || protocol != tt.protocol {
+				t.Errorf("listening() = %q, %v; want %q, %v", protocol, ok, tt.protocol, tt.ok)
+			}
+		})
+	}
+}
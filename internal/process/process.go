@@ -0,0 +1,244 @@
+// Package process provides structured process inspection
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// Sort orders for process listings.
+const (
+	SortByPID    = "pid"
+	SortByCPU    = "cpu"
+	SortByMemory = "memory"
+	SortByStart  = "start"
+)
+
+// Info describes a running process.
+type Info struct {
+	PID           int32     `json:"pid"`
+	PPID          int32     `json:"ppid"`
+	Name          string    `json:"name"`
+	Cmdline       string    `json:"cmdline,omitempty"`
+	Username      string    `json:"username,omitempty"`
+	Status        string    `json:"status,omitempty"`
+	CPUPercent    float64   `json:"cpu_percent"`
+	MemoryRSS     uint64    `json:"memory_rss_bytes"`
+	MemoryPercent float32   `json:"memory_percent"`
+	StartTime     time.Time `json:"start_time"`
+}
+
+// ListOptions filters and orders a process listing.
+type ListOptions struct {
+	Name   string // Case-insensitive substring of the name or command line
+	SortBy string // One of the SortBy constants; defaults to pid
+	Limit  int
+}
+
+// ListResult is a process listing.
+type ListResult struct {
+	Processes []Info `json:"processes"`
+	Total     int    `json:"total"`
+	Truncated bool   `json:"truncated"`
+	AllUsers  bool   `json:"all_users"`
+}
+
+// Inspector lists and inspects processes visible to the server.
+type Inspector struct {
+	allUsers   bool
+	maxResults int
+	owner      owner
+}
+
+// owner identifies the user running the server.
+type owner struct {
+	uid      int // -1 where user IDs are not supported
+	username string
+}
+
+// New creates an inspector from the process settings.
+func New(cfg *config.Config) *Inspector {
+	o := owner{uid: os.Getuid()}
+	if u, err := user.Current(); err == nil {
+		o.username = u.Username
+	}
+
+	return &Inspector{
+		allUsers:   cfg.Processes.AllUsers,
+		maxResults: cfg.Processes.MaxResults,
+		owner:      o,
+	}
+}
+
+// List returns the processes matching opts.
+func (i *Inspector) List(ctx context.Context, opts ListOptions) (*ListResult, error) {
+	if err := validateSort(opts.SortBy); err != nil {
+		return nil, err
+	}
+
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to list processes")
+	}
+
+	name := strings.ToLower(opts.Name)
+	infos := make([]Info, 0, len(procs))
+	for _, p := range procs {
+		if ctx.Err() != nil {
+			return nil, apperrors.Wrap(ctx.Err(), apperrors.ErrorTypeTimeout, "process listing cancelled")
+		}
+		if !i.allUsers && !i.owner.owns(ctx, p) {
+			continue
+		}
+
+		info, ok := collect(ctx, p)
+		if !ok {
+			// The process exited while listing
+			continue
+		}
+		if name != "" &&
+			!strings.Contains(strings.ToLower(info.Name), name) &&
+			!strings.Contains(strings.ToLower(info.Cmdline), name) {
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	sortInfos(infos, opts.SortBy)
+
+	result := &ListResult{Total: len(infos), AllUsers: i.allUsers}
+
+	limit := opts.Limit
+	if i.maxResults > 0 && (limit <= 0 || limit > i.maxResults) {
+		limit = i.maxResults
+	}
+	if limit > 0 && len(infos) > limit {
+		infos = infos[:limit]
+		result.Truncated = true
+	}
+	result.Processes = infos
+
+	return result, nil
+}
+
+// Get returns details of a single process.
+func (i *Inspector) Get(ctx context.Context, pid int32) (*Info, error) {
+	if pid <= 0 {
+		return nil, apperrors.ValidationError("pid must be positive", "pid")
+	}
+
+	resource := fmt.Sprintf("pid %d", pid)
+
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return nil, apperrors.NotFoundError("process not found", resource)
+	}
+
+	if !i.allUsers && !i.owner.owns(ctx, p) {
+		return nil, apperrors.PermissionError("process is owned by another user", resource)
+	}
+
+	info, ok := collect(ctx, p)
+	if !ok {
+		return nil, apperrors.NotFoundError("process not found", resource)
+	}
+
+	return &info, nil
+}
+
+// owns reports whether the process belongs to the owner.
+func (o owner) owns(ctx context.Context, p *process.Process) bool {
+	if o.uid >= 0 {
+		if uids, err := p.UidsWithContext(ctx); err == nil && len(uids) > 0 {
+			return int(uids[0]) == o.uid
+		}
+	}
+
+	name, err := p.UsernameWithContext(ctx)
+	return err == nil && o.username != "" && name == o.username
+}
+
+// collect gathers process fields. Fields that cannot be read, typically
+// for lack of permission, are left empty. It reports false when the
+// process no longer exists.
+func collect(ctx context.Context, p *process.Process) (Info, bool) {
+	name, err := p.NameWithContext(ctx)
+	if err != nil {
+		if exists, _ := process.PidExistsWithContext(ctx, p.Pid); !exists {
+			return Info{}, false
+		}
+	}
+
+	info := Info{PID: p.Pid, Name: name}
+
+	if ppid, err := p.PpidWithContext(ctx); err == nil {
+		info.PPID = ppid
+	}
+	if cmdline, err := p.CmdlineWithContext(ctx); err == nil {
+		info.Cmdline = cmdline
+	}
+	if username, err := p.UsernameWithContext(ctx); err == nil {
+		info.Username = username
+	}
+	if status, err := p.StatusWithContext(ctx); err == nil {
+		info.Status = strings.Join(status, ",")
+	}
+	if cpu, err := p.CPUPercentWithContext(ctx); err == nil {
+		info.CPUPercent = cpu
+	}
+	if mem, err := p.MemoryInfoWithContext(ctx); err == nil {
+		info.MemoryRSS = mem.RSS
+	}
+	if memPercent, err := p.MemoryPercentWithContext(ctx); err == nil {
+		info.MemoryPercent = memPercent
+	}
+	if created, err := p.CreateTimeWithContext(ctx); err == nil {
+		info.StartTime = time.UnixMilli(created)
+	}
+
+	return info, true
+}
+
+func validateSort(sortBy string) error {
+	switch sortBy {
+	case "", SortByPID, SortByCPU, SortByMemory, SortByStart:
+		return nil
+	default:
+		return apperrors.ValidationError(
+			fmt.Sprintf("invalid sort_by %q (expected pid, cpu, memory or start)", sortBy), "sort_by")
+	}
+}
+
+// sortInfos orders processes; cpu, memory and start sort newest or
+// busiest first.
+func sortInfos(infos []Info, sortBy string) {
+	less := func(a, b Info) bool { return a.PID < b.PID }
+
+	switch sortBy {
+	case SortByCPU:
+		less = func(a, b Info) bool { return a.CPUPercent > b.CPUPercent }
+	case SortByMemory:
+		less = func(a, b Info) bool { return a.MemoryRSS > b.MemoryRSS }
+	case SortByStart:
+		less = func(a, b Info) bool { return a.StartTime.After(b.StartTime) }
+	}
+
+	sort.SliceStable(infos, func(x, y int) bool {
+		if less(infos[x], infos[y]) {
+			return true
+		}
+		if less(infos[y], infos[x]) {
+			return false
+		}
+		return infos[x].PID < infos[y].PID
+	})
+}
@@ -0,0 +1,177 @@
+package process
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func TestInspector_List(t *testing.T) {
+	insp := New(config.Default())
+	ctx := context.Background()
+
+	result, err := insp.List(ctx, ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.AllUsers {
+		t.Error("expected listing restricted to the current user by default")
+	}
+
+	self := int32(os.Getpid())
+	found := false
+	for i, p := range result.Processes {
+		if p.PID == self {
+			found = true
+		}
+		if i > 0 && result.Processes[i-1].PID > p.PID {
+			t.Errorf("processes not sorted by pid: %d before %d", result.Processes[i-1].PID, p.PID)
+		}
+	}
+	if !found && !result.Truncated {
+		t.Errorf("expected own process %d in listing", self)
+	}
+
+	limited, err := insp.List(ctx, ListOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(limited.Processes) != 1 {
+		t.Errorf("expected 1 process, got %d", len(limited.Processes))
+	}
+	if limited.Total > 1 && !limited.Truncated {
+		t.Error("expected truncated listing")
+	}
+
+	if _, err := insp.List(ctx, ListOptions{SortBy: "name"}); err == nil {
+		t.Error("expected error for invalid sort order")
+	}
+}
+
+func TestInspector_ListByName(t *testing.T) {
+	insp := New(config.Default())
+
+	self, err := insp.Get(context.Background(), int32(os.Getpid()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := insp.List(context.Background(), ListOptions{Name: self.Name, SortBy: SortByMemory})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found := false
+	for _, p := range result.Processes {
+		if p.PID == self.PID {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected %s (%d) in filtered listing", self.Name, self.PID)
+	}
+}
+
+func TestInspector_Get(t *testing.T) {
+	insp := New(config.Default())
+	ctx := context.Background()
+
+	info, err := insp.Get(ctx, int32(os.Getpid()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Name == "" {
+		t.Error("expected process name")
+	}
+	if info.StartTime.IsZero() {
+		t.Error("expected start time")
+	}
+	if info.PPID != int32(os.Getppid()) {
+		t.Errorf("expected ppid %d, got %d", os.Getppid(), info.PPID)
+	}
+
+	tests := []struct {
+		name string
+		pid  int32
+	}{
+		{"zero pid", 0},
+		{"negative pid", -1},
+		{"missing process", 1<<22 + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := insp.Get(ctx, tt.pid); err == nil {
+				t.Errorf("expected error for pid %d", tt.pid)
+			}
+		})
+	}
+}
+
+func TestSortInfos(t *testing.T) {
+	infos := []Info{
+		{PID: 3, CPUPercent: 1, MemoryRSS: 300},
+		{PID: 1, CPUPercent: 5, MemoryRSS: 100},
+		{PID: 2, CPUPercent: 5, MemoryRSS: 200},
+	}
+
+	tests := []struct {
+		sortBy string
+		want   []int32
+	}{
+		{"", []int32{1, 2, 3}},
+		{SortByCPU, []int32{1, 2, 3}},
+		{SortByMemory, []int32{3, 2, 1}},
+	}
+	for _, tt := range tests {
+		sorted := append([]Info(nil), infos...)
+		sortInfos(sorted, tt.sortBy)
+		for i, pid := range tt.want {
+			if sorted[i].PID != pid {
+				t.Errorf("sort %q: position %d got pid %d, want %d", tt.sortBy, i, sorted[i].PID, pid)
+			}
+		}
+	}
+}
+
+func TestInspector_Terminate(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep not in PATH")
+	}
+	insp := New(config.Default())
+	ctx := context.Background()
+
+	cmd := exec.Command(sleep, "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+
+	result, err := insp.Terminate(ctx, int32(cmd.Process.Pid), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Signal != "SIGTERM" {
+		t.Errorf("expected SIGTERM, got %s", result.Signal)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the child to exit")
+	}
+
+	// Only processes the server started can be terminated
+	if _, err := insp.Terminate(ctx, int32(os.Getpid()), true); err == nil {
+		t.Error("expected terminating the server to be refused")
+	}
+	if _, err := insp.Terminate(ctx, int32(os.Getppid()), false); err == nil {
+		t.Error("expected terminating the parent to be refused")
+	}
+}
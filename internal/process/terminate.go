@@ -0,0 +1,114 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// terminateWait is how long Terminate waits for a process to exit.
+const terminateWait = 5 * time.Second
+
+// maxAncestors bounds the walk up a process tree.
+const maxAncestors = 64
+
+// Termination describes a signalled process.
+type Termination struct {
+	PID     int32  `json:"pid"`
+	Name    string `json:"name,omitempty"`
+	Signal  string `json:"signal"` // SIGTERM, or SIGKILL when forced
+	Exited  bool   `json:"exited"` // False if it still ran after the wait
+	Forced  bool   `json:"forced,omitempty"`
+	Cmdline string `json:"cmdline,omitempty"`
+}
+
+// Terminate asks a process the server started, directly or through the
+// commands it runs, to exit, or kills it when force is set, and waits
+// briefly for it to exit. Other processes, including the server itself,
+// cannot be signalled.
+func (i *Inspector) Terminate(ctx context.Context, pid int32, force bool) (*Termination, error) {
+	if pid <= 0 {
+		return nil, apperrors.ValidationError("pid must be positive", "pid")
+	}
+	resource := fmt.Sprintf("pid %d", pid)
+	self := int32(os.Getpid())
+	if pid == self {
+		return nil, apperrors.PermissionError("cannot terminate the server", resource)
+	}
+
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return nil, apperrors.NotFoundError("process not found", resource)
+	}
+	if !descends(ctx, p, self) {
+		return nil, apperrors.PermissionError("process was not started by the server", resource)
+	}
+
+	result := &Termination{PID: pid, Signal: "SIGTERM", Forced: force}
+	result.Name, _ = p.NameWithContext(ctx)
+	result.Cmdline, _ = p.CmdlineWithContext(ctx)
+	if force {
+		result.Signal = "SIGKILL"
+		err = p.KillWithContext(ctx)
+	} else {
+		err = p.TerminateWithContext(ctx)
+	}
+	if err != nil {
+		if exists, _ := process.PidExistsWithContext(ctx, pid); !exists {
+			result.Exited = true
+			return result, nil
+		}
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to signal process")
+	}
+
+	deadline := time.Now().Add(terminateWait)
+	for time.Now().Before(deadline) && ctx.Err() == nil {
+		if !running(ctx, pid) {
+			result.Exited = true
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return result, nil
+}
+
+// descends reports whether a process is a descendant of ancestor.
+func descends(ctx context.Context, p *process.Process, ancestor int32) bool {
+	for range maxAncestors {
+		ppid, err := p.PpidWithContext(ctx)
+		if err != nil || ppid <= 1 {
+			return false
+		}
+		if ppid == ancestor {
+			return true
+		}
+		if p, err = process.NewProcessWithContext(ctx, ppid); err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+// running reports whether a process exists and has not exited. Exited
+// children the server has not reaped yet count as exited.
+func running(ctx context.Context, pid int32) bool {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return false
+	}
+	status, err := p.StatusWithContext(ctx)
+	if err != nil {
+		exists, _ := process.PidExistsWithContext(ctx, pid)
+		return exists
+	}
+	for _, s := range status {
+		if s == process.Zombie {
+			return false
+		}
+	}
+	return true
+}
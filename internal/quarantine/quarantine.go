@@ -0,0 +1,77 @@
+// Package quarantine reads the quarantine attribute macOS sets on
+// downloaded files. Gatekeeper refuses to run quarantined binaries the
+// user has not approved, with errors that do not say why
+package quarantine
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Attribute is the name of the extended attribute marking quarantined
+// files.
+const Attribute = "com.apple.quarantine"
+
+// flagUserApproved is set once the user has allowed a quarantined file to
+// run.
+const flagUserApproved = 0x0040
+
+// Info is a parsed quarantine attribute.
+type Info struct {
+	Path  string    `json:"path"`
+	Flags uint32    `json:"flags"`
+	Time  time.Time `json:"time,omitzero"`   // When the file was downloaded
+	Agent string    `json:"agent,omitempty"` // Application that downloaded it
+}
+
+// Approved reports whether the user has allowed the file to run.
+func (i *Info) Approved() bool {
+	return i.Flags&flagUserApproved != 0
+}
+
+// Parse parses the value of a quarantine attribute, of the form
+// "flags;timestamp;agent;event", with hexadecimal flags and timestamp.
+// Missing or malformed fields are left zero.
+func Parse(path, value string) *Info {
+	info := &Info{Path: path}
+	fields := strings.Split(strings.TrimRight(value, "\x00"), ";")
+	if flags, err := strconv.ParseUint(fields[0], 16, 32); err == nil {
+		info.Flags = uint32(flags)
+	}
+	if len(fields) > 1 {
+		if ts, err := strconv.ParseInt(fields[1], 16, 64); err == nil && ts > 0 {
+			info.Time = time.Unix(ts, 0)
+		}
+	}
+	if len(fields) > 2 {
+		info.Agent = fields[2]
+	}
+	return info
+}
+
+// Check returns the quarantine attribute of path, or nil when the file is
+// not quarantined or quarantine does not exist on this system.
+func Check(path string) *Info {
+	value, err := read(path)
+	if err != nil || value == "" {
+		return nil
+	}
+	return Parse(path, value)
+}
+
+// Blocked returns the quarantine attribute of path when it keeps the file
+// from running, i.e. the user has not approved it.
+func Blocked(path string) *Info {
+	info := Check(path)
+	if info == nil || info.Approved() {
+		return nil
+	}
+	return info
+}
+
+// Clear removes the quarantine attribute of path. It is not an error if
+// the file is not quarantined.
+func Clear(path string) error {
+	return remove(path)
+}
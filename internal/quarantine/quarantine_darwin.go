@@ -0,0 +1,37 @@
+package quarantine
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// Supported reports whether the system quarantines downloaded files.
+const Supported = true
+
+// read returns the quarantine attribute of path, or "" if it has none.
+func read(path string) (string, error) {
+	buf := make([]byte, 256)
+	for {
+		n, err := unix.Getxattr(path, Attribute, buf)
+		switch {
+		case errors.Is(err, unix.ENOATTR):
+			return "", nil
+		case errors.Is(err, unix.ERANGE):
+			buf = make([]byte, len(buf)*2)
+			continue
+		case err != nil:
+			return "", err
+		}
+		return string(buf[:n]), nil
+	}
+}
+
+// remove removes the quarantine attribute of path.
+func remove(path string) error {
+	err := unix.Removexattr(path, Attribute)
+	if errors.Is(err, unix.ENOATTR) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package quarantine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestCheckAndClear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(path, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if Check(path) != nil {
+		t.Fatal("expected new file not to be quarantined")
+	}
+
+	if err := unix.Setxattr(path, Attribute, []byte("0081;65a1b2c3;curl;"), 0); err != nil {
+		t.Skipf("cannot set quarantine attribute: %v", err)
+	}
+	if info := Blocked(path); info == nil || info.Agent != "curl" {
+		t.Fatalf("expected quarantine by curl, got %+v", info)
+	}
+
+	if err := Clear(path); err != nil {
+		t.Fatal(err)
+	}
+	if Check(path) != nil {
+		t.Error("expected quarantine to be cleared")
+	}
+	if err := Clear(path); err != nil {
+		t.Errorf("clearing twice: %v", err)
+	}
+}
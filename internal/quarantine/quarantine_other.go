@@ -0,0 +1,18 @@
+//go:build !darwin
+
+package quarantine
+
+import "errors"
+
+// Supported reports whether the system quarantines downloaded files.
+const Supported = false
+
+// read returns the quarantine attribute of path; only macOS has one.
+func read(string) (string, error) {
+	return "", nil
+}
+
+// remove removes the quarantine attribute of path; only macOS has one.
+func remove(string) error {
+	return errors.New("quarantine attributes are only supported on macOS")
+}
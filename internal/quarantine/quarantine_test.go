@@ -0,0 +1,54 @@
+package quarantine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		flags    uint32
+		time     time.Time
+		agent    string
+		approved bool
+	}{
+		{
+			name:  "downloaded",
+			value: "0083;65a1b2c3;Safari;3F2504E0-4F89-11D3-9A0C-0305E82C3301",
+			flags: 0x83,
+			time:  time.Unix(0x65a1b2c3, 0),
+			agent: "Safari",
+		},
+		{
+			name:     "approved",
+			value:    "00c3;65a1b2c3;curl;",
+			flags:    0xc3,
+			time:     time.Unix(0x65a1b2c3, 0),
+			agent:    "curl",
+			approved: true,
+		},
+		{
+			name:  "flags only",
+			value: "0081\x00",
+			flags: 0x81,
+		},
+		{
+			name:  "malformed",
+			value: "zz;yy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := Parse("/tmp/tool", tt.value)
+			if info.Path != "/tmp/tool" || info.Flags != tt.flags || !info.Time.Equal(tt.time) || info.Agent != tt.agent {
+				t.Errorf("Parse(%q) = %+v", tt.value, info)
+			}
+			if info.Approved() != tt.approved {
+				t.Errorf("Approved() = %v, want %v", info.Approved(), tt.approved)
+			}
+		})
+	}
+}
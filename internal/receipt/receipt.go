@@ -0,0 +1,208 @@
+// Package receipt signs execution records with an ed25519 key, so
+// downstream systems can verify that a command ran with the output an
+// agent reports
+package receipt
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"time"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// Version is the receipt payload format.
+const Version = 1
+
+// Payload is what a receipt attests. Arguments and output are included as
+// SHA-256 hashes, so receipts can be shared without their content.
+type Payload struct {
+	Version      int       `json:"version"`
+	HistoryID    string    `json:"history_id"`
+	Source       string    `json:"source"`
+	Tool         string    `json:"tool,omitempty"`
+	Command      string    `json:"command"`
+	ArgsSHA256   string    `json:"args_sha256"`
+	WorkDir      string    `json:"workdir,omitempty"`
+	StdoutSHA256 string    `json:"stdout_sha256"`
+	StderrSHA256 string    `json:"stderr_sha256"`
+	ExitCode     int       `json:"exit_code"`
+	TimedOut     bool      `json:"timed_out,omitempty"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	SignedAt     time.Time `json:"signed_at"`
+}
+
+// Signer signs execution records.
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// LoadSigner reads a PEM encoded PKCS #8 ed25519 private key, as written
+// by "receipts keygen" or "openssl genpkey -algorithm ed25519".
+func LoadSigner(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to read signing key")
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, apperrors.ConfigurationError("signing key is not PEM encoded: " + path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to parse signing key")
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, apperrors.ConfigurationError("signing key is not an ed25519 key: " + path)
+	}
+
+	return &Signer{key: key, keyID: KeyID(key.Public().(ed25519.PublicKey))}, nil
+}
+
+// Sign returns a receipt for a stored record, or nil if it has no result.
+func (s *Signer) Sign(rec types.ExecutionRecord) *types.Receipt {
+	if rec.Result == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(NewPayload(rec, time.Now()))
+	if err != nil {
+		return nil
+	}
+	return &types.Receipt{
+		KeyID:     s.keyID,
+		Payload:   base64.StdEncoding.EncodeToString(data),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, data)),
+	}
+}
+
+// PublicKey returns the public key of the signer.
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// SignData returns the signature of arbitrary data, such as a command
+// catalog.
+func (s *Signer) SignData(data []byte) []byte {
+	return ed25519.Sign(s.key, data)
+}
+
+// NewPayload describes a record for signing.
+func NewPayload(rec types.ExecutionRecord, signedAt time.Time) Payload {
+	args, _ := json.Marshal(rec.Request.Args)
+	p := Payload{
+		Version:    Version,
+		HistoryID:  rec.ID,
+		Source:     rec.Source,
+		Tool:       rec.Tool,
+		Command:    rec.Request.Command,
+		ArgsSHA256: Hash(string(args)),
+		WorkDir:    rec.Request.WorkDir,
+		SignedAt:   signedAt.UTC(),
+	}
+	if r := rec.Result; r != nil {
+		p.StdoutSHA256 = Hash(r.Stdout)
+		p.StderrSHA256 = Hash(r.Stderr)
+		p.ExitCode = r.ExitCode
+		p.TimedOut = r.TimedOut
+		p.StartTime = r.StartTime.UTC()
+		p.EndTime = r.EndTime.UTC()
+	}
+	return p
+}
+
+// Verify checks a receipt's signature against a public key and returns its
+// payload.
+func Verify(r *types.Receipt, pub ed25519.PublicKey) (*Payload, error) {
+	data, err := base64.StdEncoding.DecodeString(r.Payload)
+	if err != nil {
+		return nil, apperrors.ValidationError("receipt payload is not base64", "payload")
+	}
+	sig, err := base64.StdEncoding.DecodeString(r.Signature)
+	if err != nil {
+		return nil, apperrors.ValidationError("receipt signature is not base64", "signature")
+	}
+	if !ed25519.Verify(pub, data, sig) {
+		return nil, apperrors.ValidationError("receipt signature does not match the public key", "signature")
+	}
+
+	var p Payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, apperrors.ValidationError("receipt payload is not valid JSON", "payload")
+	}
+	return &p, nil
+}
+
+// Hash returns the hex SHA-256 of s.
+func Hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyID identifies a public key by the first bytes of its hash.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// GenerateKey returns a new PEM encoded private key and its PEM encoded
+// public key.
+func GenerateKey() (private, public []byte, err error) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to generate key")
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to encode private key")
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to encode public key")
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}),
+		nil
+}
+
+// LoadPublicKey reads a PEM encoded PKIX ed25519 public key, or derives
+// it from a private key.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to read public key")
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, apperrors.ConfigurationError("public key is not PEM encoded: " + path)
+	}
+	if block.Type == "PRIVATE KEY" {
+		signer, err := LoadSigner(path)
+		if err != nil {
+			return nil, err
+		}
+		return signer.key.Public().(ed25519.PublicKey), nil
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to parse public key")
+	}
+	pub, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, apperrors.ConfigurationError("public key is not an ed25519 key: " + path)
+	}
+	return pub, nil
+}
@@ -0,0 +1,81 @@
+package receipt
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKey(t *testing.T) (privPath, pubPath string) {
+	dir := t.TempDir()
+	private, public, err := GenerateKey()
+	require.NoError(t, err)
+	privPath = filepath.Join(dir, "key.pem")
+	pubPath = filepath.Join(dir, "key.pub")
+	require.NoError(t, os.WriteFile(privPath, private, 0o600))
+	require.NoError(t, os.WriteFile(pubPath, public, 0o600))
+	return privPath, pubPath
+}
+
+func TestSignAndVerify(t *testing.T) {
+	privPath, pubPath := writeKey(t)
+	signer, err := LoadSigner(privPath)
+	require.NoError(t, err)
+
+	start := time.Now()
+	rec := types.ExecutionRecord{
+		ID:      "h1",
+		Source:  types.ExecutionSourceTool,
+		Tool:    "run_command",
+		Request: types.CommandExecutionRequest{Command: "echo", Args: []string{"hi"}},
+		Result:  &types.CommandExecutionResult{Stdout: "hi\n", StartTime: start, EndTime: start.Add(time.Second)},
+	}
+	r := signer.Sign(rec)
+	require.NotNil(t, r)
+
+	pub, err := LoadPublicKey(pubPath)
+	require.NoError(t, err)
+	assert.Equal(t, KeyID(pub), r.KeyID)
+
+	payload, err := Verify(r, pub)
+	require.NoError(t, err)
+	assert.Equal(t, "h1", payload.HistoryID)
+	assert.Equal(t, "echo", payload.Command)
+	assert.Equal(t, Hash("hi\n"), payload.StdoutSHA256)
+	assert.Equal(t, Hash(""), payload.StderrSHA256)
+
+	// The private key file also yields the public key
+	fromPrivate, err := LoadPublicKey(privPath)
+	require.NoError(t, err)
+	assert.Equal(t, pub, fromPrivate)
+
+	// Tampered payloads and other keys are rejected
+	tampered := *r
+	tampered.Payload = r.Payload[:len(r.Payload)-4] + "AAAA"
+	_, err = Verify(&tampered, pub)
+	assert.Error(t, err)
+
+	other, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, err = Verify(r, other)
+	assert.Error(t, err)
+
+	// Records without a result are not signed
+	assert.Nil(t, signer.Sign(types.ExecutionRecord{ID: "h2"}))
+}
+
+func TestLoadSigner_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, []byte("not a key"), 0o600))
+
+	_, err := LoadSigner(path)
+	assert.Error(t, err)
+	_, err = LoadSigner(filepath.Join(t.TempDir(), "missing.pem"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,220 @@
+// Package recording captures the MCP messages of a session to a file and
+// replays them into a server, so client-specific bugs can be reproduced
+package recording
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Directions of recorded messages.
+const (
+	DirectionIn  = "in"  // From the client
+	DirectionOut = "out" // To the client
+)
+
+// Entry is one recorded JSON-RPC message.
+type Entry struct {
+	Time      time.Time       `json:"time"`
+	Direction string          `json:"direction"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// wireMessage is the JSON-RPC wire form of a message.
+type wireMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      any             `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *wireError      `json:"error,omitempty"`
+}
+
+type wireError struct {
+	Code    int64           `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Transport records the messages of the connection it wraps to a file.
+type Transport struct {
+	delegate  mcp.Transport
+	sensitive func(name string) bool
+
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewTransport creates the recording file, replacing any previous one, and
+// wraps a transport. Values of keys and NAME=value strings for which
+// sensitive returns true are masked.
+func NewTransport(delegate mcp.Transport, path string, sensitive func(string) bool) (*Transport, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to create session recording")
+	}
+	return &Transport{delegate: delegate, sensitive: sensitive, file: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Connect connects the wrapped transport and records the connection.
+func (t *Transport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.delegate.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingConn{Connection: conn, t: t}, nil
+}
+
+// Close closes the recording file.
+func (t *Transport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.file.Close()
+}
+
+// record appends a message. Recording is best-effort and never fails the
+// session.
+func (t *Transport) record(direction string, msg jsonrpc.Message) {
+	data, err := json.Marshal(t.encode(msg))
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_ = t.enc.Encode(Entry{Time: time.Now().UTC(), Direction: direction, Message: data})
+}
+
+// encode returns the masked wire form of a message.
+func (t *Transport) encode(msg jsonrpc.Message) wireMessage {
+	w := wireMessage{JSONRPC: "2.0"}
+	switch m := msg.(type) {
+	case *jsonrpc.Request:
+		w.ID = m.ID.Raw()
+		w.Method = m.Method
+		w.Params = Mask(m.Params, t.sensitive)
+	case *jsonrpc.Response:
+		w.ID = m.ID.Raw()
+		w.Result = Mask(m.Result, t.sensitive)
+		if m.Error != nil {
+			w.Error = toWireError(m.Error)
+		}
+	}
+	return w
+}
+
+// toWireError converts an error to its JSON-RPC form. Errors from the
+// SDK marshal to their code and message; others are internal errors.
+func toWireError(err error) *wireError {
+	we := &wireError{}
+	if data, mErr := json.Marshal(err); mErr == nil {
+		_ = json.Unmarshal(data, we)
+	}
+	if we.Message == "" {
+		we.Code = -32603
+		we.Message = err.Error()
+	}
+	return we
+}
+
+// recordingConn records the messages of a connection.
+type recordingConn struct {
+	mcp.Connection
+	t *Transport
+}
+
+// Read records messages from the client.
+func (c *recordingConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	msg, err := c.Connection.Read(ctx)
+	if err == nil {
+		c.t.record(DirectionIn, msg)
+	}
+	return msg, err
+}
+
+// Write records messages to the client.
+func (c *recordingConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	err := c.Connection.Write(ctx, msg)
+	if err == nil {
+		c.t.record(DirectionOut, msg)
+	}
+	return err
+}
+
+// Read reads a session recording. Malformed lines are skipped.
+func Read(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeNotFound, "failed to open session recording")
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || len(e.Message) == 0 {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read session recording")
+	}
+	return entries, nil
+}
+
+// Mask replaces the values of sensitive keys, and of sensitive NAME=value
+// strings such as environment entries, in a JSON body.
+func Mask(raw json.RawMessage, sensitive func(string) bool) json.RawMessage {
+	if len(raw) == 0 {
+		return raw
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return raw
+	}
+	data, err := json.Marshal(maskValue(v, sensitive))
+	if err != nil {
+		return raw
+	}
+	return data
+}
+
+func maskValue(v any, sensitive func(string) bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if _, isString := value.(string); isString && sensitive(key) {
+				v[key] = executor.MaskedValue
+				continue
+			}
+			v[key] = maskValue(value, sensitive)
+		}
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = maskValue(value, sensitive)
+		}
+		return v
+	case string:
+		if name, _, ok := strings.Cut(v, "="); ok && name != "" && !strings.ContainsAny(name, " /") && sensitive(name) {
+			return name + "=" + executor.MaskedValue
+		}
+		return v
+	default:
+		return v
+	}
+}
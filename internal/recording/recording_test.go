@@ -0,0 +1,154 @@
+package recording
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sensitiveToken(name string) bool {
+	return strings.Contains(strings.ToUpper(name), "TOKEN")
+}
+
+func TestMask(t *testing.T) {
+	got := string(Mask(json.RawMessage(`{"token":"a","nested":{"env":["GH_TOKEN=b","HOME=/home/user"]},"count":1,"token_count":2}`), sensitiveToken))
+
+	assert.NotContains(t, got, `"a"`)
+	assert.NotContains(t, got, "=b")
+	assert.Contains(t, got, "HOME=/home/user")
+	assert.Contains(t, got, `"count":1`)
+	assert.Contains(t, got, `"token_count":2`)
+
+	assert.Equal(t, "not json", string(Mask(json.RawMessage("not json"), sensitiveToken)))
+}
+
+func TestTransport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "probe"}, func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[struct{}], error) {
+		return &mcp.CallToolResultFor[struct{}]{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil
+	})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	rec, err := NewTransport(serverTransport, path, sensitiveToken)
+	require.NoError(t, err)
+	ss, err := server.Connect(ctx, rec)
+	require.NoError(t, err)
+
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil).Connect(ctx, clientTransport)
+	require.NoError(t, err)
+	_, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "probe", Arguments: map[string]any{"api_token": "hunter2"}})
+	require.NoError(t, err)
+	cs.Close()
+	ss.Wait()
+	require.NoError(t, rec.Close())
+
+	entries, err := Read(path)
+	require.NoError(t, err)
+
+	var methods []string
+	var call, result json.RawMessage
+	for _, e := range entries {
+		m := parseMessage(e.Message)
+		if e.Direction == DirectionIn && m.Method != "" {
+			methods = append(methods, m.Method)
+		}
+		if m.Method == "tools/call" {
+			call = e.Message
+		}
+		if e.Direction == DirectionOut && string(m.ID) == "2" {
+			result = e.Message
+		}
+	}
+
+	assert.Equal(t, []string{"initialize", "notifications/initialized", "tools/call"}, methods)
+	assert.NotContains(t, string(call), "hunter2")
+	assert.Contains(t, string(result), "done")
+}
+
+// fakeServer answers requests on stdio: tools/call fails for the tool
+// "broken", and the first tools/call asks the client for its roots.
+func fakeServer(t *testing.T, stdin io.Reader, stdout io.Writer) {
+	t.Helper()
+	go func() {
+		asked := false
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			m := parseMessage(scanner.Bytes())
+			if !m.isRequest() {
+				continue
+			}
+			if m.Method == "tools/call" && !asked {
+				asked = true
+				fmt.Fprintln(stdout, `{"jsonrpc":"2.0","id":"s1","method":"roots/list"}`)
+				if !scanner.Scan() || !strings.Contains(scanner.Text(), `"roots"`) {
+					fmt.Fprintf(stdout, `{"jsonrpc":"2.0","id":%s,"error":{"code":-1,"message":"no roots"}}`+"\n", m.ID)
+					continue
+				}
+			}
+			isError := m.Params.Name == "broken"
+			fmt.Fprintf(stdout, `{"jsonrpc":"2.0","id":%s,"result":{"isError":%t}}`+"\n", m.ID, isError)
+		}
+	}()
+}
+
+func TestReplay(t *testing.T) {
+	entries := []Entry{
+		{Direction: DirectionIn, Message: json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)},
+		{Direction: DirectionOut, Message: json.RawMessage(`{"jsonrpc":"2.0","id":1,"result":{}}`)},
+		{Direction: DirectionIn, Message: json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)},
+		{Direction: DirectionIn, Message: json.RawMessage(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"ok"}}`)},
+		{Direction: DirectionOut, Message: json.RawMessage(`{"jsonrpc":"2.0","id":"s1","method":"roots/list"}`)},
+		{Direction: DirectionIn, Message: json.RawMessage(`{"jsonrpc":"2.0","id":"s1","result":{"roots":[]}}`)},
+		{Direction: DirectionOut, Message: json.RawMessage(`{"jsonrpc":"2.0","id":2,"result":{}}`)},
+		{Direction: DirectionIn, Message: json.RawMessage(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"broken"}}`)},
+		{Direction: DirectionOut, Message: json.RawMessage(`{"jsonrpc":"2.0","id":3,"result":{}}`)},
+	}
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	fakeServer(t, serverIn, serverOut)
+	defer clientOut.Close()
+
+	exchanges, err := Replay(context.Background(), entries, clientOut, clientIn, 5*time.Second)
+	require.NoError(t, err)
+	require.Len(t, exchanges, 3)
+
+	assert.Equal(t, "initialize", exchanges[0].Method)
+	assert.False(t, exchanges[0].Differs())
+
+	assert.Equal(t, "ok", exchanges[1].Tool)
+	assert.Equal(t, OutcomeOK, exchanges[1].Outcome, "the server request should be answered from the recording")
+	assert.False(t, exchanges[1].Differs())
+
+	assert.Equal(t, OutcomeToolError, exchanges[2].Outcome)
+	assert.Equal(t, OutcomeOK, exchanges[2].RecordedOutcome)
+	assert.True(t, exchanges[2].Differs())
+}
+
+func TestReplay_timeout(t *testing.T) {
+	entries := []Entry{
+		{Direction: DirectionIn, Message: json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`)},
+		{Direction: DirectionOut, Message: json.RawMessage(`{"jsonrpc":"2.0","id":1,"result":{}}`)},
+	}
+
+	// A server that reads but never answers
+	stdout, _ := io.Pipe()
+	exchanges, err := Replay(context.Background(), entries, io.Discard, stdout, 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, exchanges, 1)
+	assert.Equal(t, OutcomeNone, exchanges[0].Outcome)
+	assert.True(t, exchanges[0].Differs())
+}
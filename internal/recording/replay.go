@@ -0,0 +1,187 @@
+package recording
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// Outcomes of a replayed request.
+const (
+	OutcomeOK        = "ok"
+	OutcomeError     = "error"      // JSON-RPC error
+	OutcomeToolError = "tool error" // Tool result with isError set
+	OutcomeNone      = "none"       // No response
+)
+
+// Exchange is a replayed request and the responses it got then and now.
+type Exchange struct {
+	ID              string          `json:"id"`
+	Method          string          `json:"method"`
+	Tool            string          `json:"tool,omitempty"`
+	Outcome         string          `json:"outcome"`
+	RecordedOutcome string          `json:"recorded_outcome,omitempty"`
+	Response        json.RawMessage `json:"response,omitempty"`
+	Recorded        json.RawMessage `json:"recorded,omitempty"`
+}
+
+// Differs reports whether the replayed request had a different outcome
+// than when it was recorded. Results are not compared in full, as they
+// hold times and durations.
+func (e Exchange) Differs() bool {
+	return e.RecordedOutcome != "" && e.Outcome != e.RecordedOutcome
+}
+
+// message is the part of a JSON-RPC message replay looks at.
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params struct {
+		Name string `json:"name"`
+	} `json:"params"`
+	Result struct {
+		IsError bool `json:"isError"`
+	} `json:"result"`
+	Error json.RawMessage `json:"error,omitempty"`
+}
+
+func parseMessage(raw json.RawMessage) message {
+	var m message
+	_ = json.Unmarshal(raw, &m)
+	return m
+}
+
+// isRequest reports whether a message is a request expecting a response.
+func (m message) isRequest() bool {
+	return m.Method != "" && len(m.ID) > 0 && string(m.ID) != "null"
+}
+
+// isResponse reports whether a message is a response.
+func (m message) isResponse() bool {
+	return m.Method == "" && len(m.ID) > 0
+}
+
+// outcome classifies a response.
+func outcome(raw json.RawMessage) string {
+	if raw == nil {
+		return OutcomeNone
+	}
+	m := parseMessage(raw)
+	switch {
+	case len(m.Error) > 0 && string(m.Error) != "null":
+		return OutcomeError
+	case m.Result.IsError:
+		return OutcomeToolError
+	default:
+		return OutcomeOK
+	}
+}
+
+// Replay sends the client messages of a recording to a server over its
+// stdio, waiting up to timeout for the response to each request, and
+// returns the exchanges. Requests the server sends meanwhile are answered
+// with the recorded client response, if there is one.
+func Replay(ctx context.Context, entries []Entry, stdin io.Writer, stdout io.Reader, timeout time.Duration) ([]Exchange, error) {
+	recorded := make(map[string]json.RawMessage) // Server responses by request ID
+	answers := make(map[string]json.RawMessage)  // Client responses by request ID
+	for _, e := range entries {
+		m := parseMessage(e.Message)
+		if !m.isResponse() {
+			continue
+		}
+		if e.Direction == DirectionOut {
+			recorded[string(m.ID)] = e.Message
+		} else {
+			answers[string(m.ID)] = e.Message
+		}
+	}
+
+	responses := make(chan json.RawMessage)
+	go func() {
+		defer close(responses)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+		for scanner.Scan() {
+			line := append(json.RawMessage(nil), scanner.Bytes()...)
+			select {
+			case responses <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	send := func(raw json.RawMessage) error {
+		if _, err := stdin.Write(append(append([]byte(nil), raw...), '\n')); err != nil {
+			return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to send message to server")
+		}
+		return nil
+	}
+
+	answered := make(map[string]bool)
+	var exchanges []Exchange
+	for _, e := range entries {
+		if e.Direction != DirectionIn {
+			continue
+		}
+		m := parseMessage(e.Message)
+		if m.isResponse() && answered[string(m.ID)] {
+			continue
+		}
+		if err := send(e.Message); err != nil {
+			return exchanges, err
+		}
+		if !m.isRequest() {
+			continue
+		}
+
+		ex := Exchange{ID: string(m.ID), Method: m.Method, Tool: m.Params.Name, Recorded: recorded[string(m.ID)]}
+		if ex.Recorded != nil {
+			ex.RecordedOutcome = outcome(ex.Recorded)
+		}
+
+		timer := time.NewTimer(timeout)
+	wait:
+		for {
+			select {
+			case raw, ok := <-responses:
+				if !ok {
+					break wait
+				}
+				reply := parseMessage(raw)
+				if reply.isRequest() {
+					// A request from the server, such as roots/list
+					answer, ok := answers[string(reply.ID)]
+					if !ok {
+						answer = json.RawMessage(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"not in the recording"}}`, reply.ID))
+					}
+					answered[string(reply.ID)] = true
+					if err := send(answer); err != nil {
+						timer.Stop()
+						return exchanges, err
+					}
+					continue
+				}
+				if reply.isResponse() && string(reply.ID) == ex.ID {
+					ex.Response = raw
+					break wait
+				}
+			case <-timer.C:
+				break wait
+			case <-ctx.Done():
+				timer.Stop()
+				return exchanges, ctx.Err()
+			}
+		}
+		timer.Stop()
+
+		ex.Outcome = outcome(ex.Response)
+		exchanges = append(exchanges, ex)
+	}
+	return exchanges, nil
+}
@@ -0,0 +1,11 @@
+package repl
+
+import "golang.org/x/sys/unix"
+
+// limitMemory caps the data segment of a running process, which bounds
+// the heap of interpreters without breaking the large address space
+// reservations of runtimes such as V8.
+func limitMemory(pid int, max int64) error {
+	limit := &unix.Rlimit{Cur: uint64(max), Max: uint64(max)}
+	return unix.Prlimit(pid, unix.RLIMIT_DATA, limit, nil)
+}
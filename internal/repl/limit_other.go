@@ -0,0 +1,9 @@
+//go:build !linux
+
+package repl
+
+// limitMemory does nothing: memory limits of running processes are only
+// supported on Linux.
+func limitMemory(pid int, max int64) error {
+	return nil
+}
@@ -0,0 +1,484 @@
+// Package repl keeps interpreters such as python, node and psql running
+// under a pseudo terminal between calls, sending them input a line at a
+// time and reading their output up to the next prompt.
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+const (
+	// startTimeout bounds how long an interpreter may take to show its
+	// first prompt.
+	startTimeout = 30 * time.Second
+
+	// defaultReadTimeout is how long input waits for a prompt when the
+	// configuration does not say.
+	defaultReadTimeout = 30 * time.Second
+
+	// maxInputSize limits the input sent to a session in one call.
+	maxInputSize = 64 << 10
+
+	// terminalColumns is wide enough that interpreters do not wrap or
+	// scroll long input lines.
+	terminalColumns = 1000
+)
+
+// escapes matches terminal control sequences, which are dropped from
+// output.
+var escapes = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[()][A-Za-z0-9]|\x1b[=>]`)
+
+// Info describes a session.
+type Info struct {
+	ID          string    `json:"id"`
+	Interpreter string    `json:"interpreter"`
+	PID         int       `json:"pid"`
+	Started     time.Time `json:"started"`
+	LastUsed    time.Time `json:"last_used"`
+}
+
+// StartRequest launches an interpreter.
+type StartRequest struct {
+	Interpreter string
+	Args        []string // Appended to the configured arguments
+	WorkDir     string
+}
+
+// SendRequest sends input to a session.
+type SendRequest struct {
+	Session   string
+	Input     string        // Sent a line at a time, each awaiting a prompt
+	Timeout   time.Duration // Defaults to the configured read timeout
+	Interrupt bool          // Send Ctrl-C before the input
+}
+
+// Output is what a session printed in response to input.
+type Output struct {
+	Session   string `json:"session"`
+	Output    string `json:"output"`
+	Prompt    string `json:"prompt,omitempty"`    // Prompt the interpreter waits at
+	TimedOut  bool   `json:"timed_out,omitempty"` // No prompt came within the timeout
+	Unsent    int    `json:"unsent,omitempty"`    // Input lines not sent after a timeout
+	Truncated bool   `json:"truncated,omitempty"` // The start of the output was dropped
+	Exited    bool   `json:"exited,omitempty"`    // The interpreter exited
+	ExitCode  *int   `json:"exit_code,omitempty"` // Set once the interpreter exited
+}
+
+// Manager runs the sessions.
+type Manager struct {
+	config *config.Config
+	env    []string // Environment of the interpreters
+	logger *logger.Logger
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	counter  int // Numbers session IDs
+	closed   bool
+}
+
+// New creates a manager whose interpreters inherit env.
+func New(cfg *config.Config, env []string, log *logger.Logger) *Manager {
+	return &Manager{config: cfg, env: env, logger: log, sessions: make(map[string]*session)}
+}
+
+// Start launches an interpreter and returns its banner up to the first
+// prompt. The caller checks the command against the security policy.
+func (m *Manager) Start(ctx context.Context, req StartRequest) (*Info, *Output, error) {
+	interp := m.Interpreter(req.Interpreter)
+	if interp == nil {
+		return nil, nil, apperrors.NotFoundError("unknown interpreter: "+req.Interpreter, req.Interpreter)
+	}
+	prompt, err := regexp.Compile(interp.Prompt)
+	if err != nil {
+		return nil, nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "invalid prompt pattern")
+	}
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil, nil, apperrors.New(apperrors.ErrorTypeInternal, "REPL sessions are shut down")
+	}
+	if max := m.config.REPL.MaxSessions; max > 0 && len(m.sessions) >= max {
+		m.mu.Unlock()
+		return nil, nil, apperrors.New(apperrors.ErrorTypeValidation,
+			fmt.Sprintf("session limit reached (%d); stop a session first", max))
+	}
+	m.counter++
+	id := fmt.Sprintf("%s-%d", interp.Name, m.counter)
+	m.mu.Unlock()
+
+	cmd := exec.Command(interp.Command, append(append([]string{}, interp.Args...), req.Args...)...)
+	cmd.Dir = req.WorkDir
+	cmd.Env = append(append(append([]string{}, m.env...), "TERM=dumb"), interp.Env...)
+	tty, err := pty.StartWithSize(cmd, &pty.Winsize{Rows: 50, Cols: terminalColumns})
+	if err != nil {
+		if errors.Is(err, pty.ErrUnsupported) {
+			return nil, nil, apperrors.ConfigurationError("REPL sessions are not supported on this platform")
+		}
+		return nil, nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to start "+interp.Command)
+	}
+
+	if max := m.config.REPL.MaxMemory; max > 0 {
+		if err := limitMemory(cmd.Process.Pid, int64(max)); err != nil {
+			tty.Close()
+			cmd.Process.Kill()
+			cmd.Wait()
+			return nil, nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to limit interpreter memory")
+		}
+	}
+
+	now := time.Now()
+	s := &session{
+		info:      Info{ID: id, Interpreter: interp.Name, PID: cmd.Process.Pid, Started: now, LastUsed: now},
+		cmd:       cmd,
+		tty:       tty,
+		prompt:    prompt,
+		maxOutput: int(m.config.REPL.MaxOutputSize),
+		changed:   make(chan struct{}),
+		readDone:  make(chan struct{}),
+		exited:    make(chan struct{}),
+	}
+	go s.read()
+	go s.wait()
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		s.kill()
+		return nil, nil, apperrors.New(apperrors.ErrorTypeInternal, "REPL sessions are shut down")
+	}
+	m.sessions[id] = s
+	m.mu.Unlock()
+
+	if d := m.config.REPL.MaxLifetime.Std(); d > 0 {
+		s.lifetime = time.AfterFunc(d, func() { m.expire(id, "lifetime") })
+	}
+	if d := m.config.REPL.IdleTimeout.Std(); d > 0 {
+		s.idle = time.AfterFunc(d, func() { m.expire(id, "idle") })
+	}
+
+	s.mu.Lock()
+	out := s.collect(ctx, startTimeout)
+	s.mu.Unlock()
+	out.Session = id
+	if out.Exited {
+		m.remove(id)
+	}
+	m.logger.Info("REPL session started", "session", id, "command", interp.Command, "pid", cmd.Process.Pid)
+
+	info := Info{ID: id, Interpreter: interp.Name, PID: cmd.Process.Pid, Started: now, LastUsed: now}
+	return &info, out, nil
+}
+
+// Send types input into a session a line at a time, waiting for a prompt
+// after each line, and returns the output without the echoed input. If no
+// prompt comes within the timeout, the remaining lines are not sent and
+// the next call first waits for the prompt.
+func (m *Manager) Send(ctx context.Context, req SendRequest) (*Output, error) {
+	if len(req.Input) > maxInputSize {
+		return nil, apperrors.ValidationError(fmt.Sprintf("input exceeds %d bytes", maxInputSize), "input")
+	}
+	s, err := m.get(req.Session)
+	if err != nil {
+		return nil, err
+	}
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = m.config.REPL.ReadTimeout.Std()
+	}
+	if timeout <= 0 {
+		timeout = defaultReadTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m.touch(s)
+	defer m.touch(s)
+
+	var lines []string
+	if req.Input != "" {
+		lines = strings.Split(strings.TrimSuffix(strings.ReplaceAll(req.Input, "\r\n", "\n"), "\n"), "\n")
+	}
+	result := &Output{Session: req.Session}
+	var output strings.Builder
+	add := func(out *Output) {
+		output.WriteString(out.Output)
+		result.Prompt, result.TimedOut, result.Exited, result.ExitCode = out.Prompt, out.TimedOut, out.Exited, out.ExitCode
+		result.Truncated = result.Truncated || out.Truncated
+	}
+
+	if req.Interrupt {
+		if _, err := s.tty.Write([]byte{0x03}); err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to interrupt session")
+		}
+		s.busy = true
+	}
+	if s.busy {
+		add(s.collect(ctx, time.Until(deadline)))
+	}
+
+	for i, line := range lines {
+		if result.TimedOut || result.Exited || ctx.Err() != nil {
+			result.Unsent = len(lines) - i
+			break
+		}
+		if _, err := s.tty.Write([]byte(line + "\n")); err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to write to session")
+		}
+		out := s.collect(ctx, time.Until(deadline))
+		out.Output = stripEcho(out.Output, line)
+		add(out)
+	}
+	result.Output = output.String()
+	s.busy = result.TimedOut
+
+	if result.Exited {
+		m.remove(req.Session)
+	}
+	return result, nil
+}
+
+// Stop ends a session and its interpreter.
+func (m *Manager) Stop(id string) error {
+	if _, err := m.get(id); err != nil {
+		return err
+	}
+	m.remove(id)
+	return nil
+}
+
+// List returns the sessions, sorted by ID.
+func (m *Manager) List() []Info {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	infos := make([]Info, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		infos = append(infos, s.info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// Interpreter returns the configured interpreter called name.
+func (m *Manager) Interpreter(name string) *config.Interpreter {
+	for i := range m.config.REPL.Interpreters {
+		if m.config.REPL.Interpreters[i].Name == name {
+			return &m.config.REPL.Interpreters[i]
+		}
+	}
+	return nil
+}
+
+// Close stops every session.
+func (m *Manager) Close() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.closed = true
+	sessions := m.sessions
+	m.sessions = make(map[string]*session)
+	m.mu.Unlock()
+	for _, s := range sessions {
+		s.kill()
+	}
+}
+
+// get returns a session by ID.
+func (m *Manager) get(id string) (*session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, apperrors.NotFoundError("no such REPL session (it may have expired): "+id, id)
+	}
+	return s, nil
+}
+
+// touch records the use of a session and restarts its idle timer.
+func (m *Manager) touch(s *session) {
+	m.mu.Lock()
+	s.info.LastUsed = time.Now()
+	m.mu.Unlock()
+	if s.idle != nil {
+		s.idle.Reset(m.config.REPL.IdleTimeout.Std())
+	}
+}
+
+// expire stops a session that was idle or ran too long. A session busy
+// with input is idle again once the input returns.
+func (m *Manager) expire(id, reason string) {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+	if reason == "idle" && !s.mu.TryLock() {
+		return
+	} else if reason == "idle" {
+		s.mu.Unlock()
+	}
+	m.logger.Info("REPL session expired", "session", id, "reason", reason)
+	m.remove(id)
+}
+
+// remove forgets a session and kills its interpreter.
+func (m *Manager) remove(id string) {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	delete(m.sessions, id)
+	m.mu.Unlock()
+	if ok {
+		s.kill()
+	}
+}
+
+// session is a running interpreter.
+type session struct {
+	info      Info // Guarded by the manager's lock
+	cmd       *exec.Cmd
+	tty       *os.File
+	prompt    *regexp.Regexp
+	maxOutput int
+	idle      *time.Timer
+	lifetime  *time.Timer
+
+	mu   sync.Mutex // Serializes input
+	busy bool       // The last input timed out before a prompt
+
+	outMu     sync.Mutex
+	out       []byte        // Output not yet returned
+	truncated bool          // The start of out was dropped
+	changed   chan struct{} // Closed and replaced when out grows
+	readDone  chan struct{} // Closed when the terminal closes
+
+	exited   chan struct{} // Closed when the interpreter exits
+	exitCode int
+}
+
+// read collects the interpreter's output until the terminal closes.
+func (s *session) read() {
+	defer close(s.readDone)
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := s.tty.Read(buf)
+		if n > 0 {
+			s.outMu.Lock()
+			s.out = append(s.out, buf[:n]...)
+			if s.maxOutput > 0 && len(s.out) > s.maxOutput {
+				s.out = append(s.out[:0], s.out[len(s.out)-s.maxOutput:]...)
+				s.truncated = true
+			}
+			close(s.changed)
+			s.changed = make(chan struct{})
+			s.outMu.Unlock()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// wait records the interpreter's exit.
+func (s *session) wait() {
+	err := s.cmd.Wait()
+	s.exitCode = 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		s.exitCode = exitErr.ExitCode()
+	} else if err != nil {
+		s.exitCode = -1
+	}
+	close(s.exited)
+}
+
+// kill ends the interpreter and closes its terminal.
+func (s *session) kill() {
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+	if s.lifetime != nil {
+		s.lifetime.Stop()
+	}
+	s.cmd.Process.Kill()
+	s.tty.Close()
+}
+
+// collect waits up to timeout for the interpreter to show a prompt and
+// returns the output before it.
+func (s *session) collect(ctx context.Context, timeout time.Duration) *Output {
+	timer := time.NewTimer(max(timeout, 0))
+	defer timer.Stop()
+
+	for {
+		s.outMu.Lock()
+		text := clean(s.out)
+		changed := s.changed
+		lastLine := text[strings.LastIndexByte(text, '\n')+1:]
+		if s.prompt.MatchString(lastLine) {
+			out := &Output{Output: text[:len(text)-len(lastLine)], Prompt: lastLine, Truncated: s.truncated}
+			s.out, s.truncated = s.out[:0], false
+			s.outMu.Unlock()
+			return out
+		}
+		s.outMu.Unlock()
+
+		select {
+		case <-changed:
+			continue
+		case <-s.exited:
+			// Let the reader drain what the interpreter printed last,
+			// unless a child it left behind holds the terminal open
+			select {
+			case <-s.readDone:
+			case <-time.After(time.Second):
+			}
+			s.outMu.Lock()
+			out := &Output{Output: clean(s.out), Truncated: s.truncated, Exited: true, ExitCode: &s.exitCode}
+			s.out, s.truncated = s.out[:0], false
+			s.outMu.Unlock()
+			return out
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+
+		// Return what was printed so far, keeping it from the next call
+		s.outMu.Lock()
+		out := &Output{Output: clean(s.out), TimedOut: true, Truncated: s.truncated}
+		s.out, s.truncated = s.out[:0], false
+		s.outMu.Unlock()
+		return out
+	}
+}
+
+// clean drops terminal control sequences and carriage returns.
+func clean(b []byte) string {
+	text := escapes.ReplaceAllString(string(b), "")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "")
+}
+
+// stripEcho drops the terminal's echo of line from the start of output.
+func stripEcho(output, line string) string {
+	first, rest, _ := strings.Cut(output, "\n")
+	if strings.TrimSpace(first) == strings.TrimSpace(line) {
+		return rest
+	}
+	return output
+}
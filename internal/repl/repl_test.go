@@ -0,0 +1,125 @@
+package repl
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestManager returns a manager whose sessions are stopped when the
+// test ends, skipping the test if interpreter is not installed.
+func newTestManager(t *testing.T, interpreter string, configure func(*config.REPLConfig)) *Manager {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("pseudo terminals are not supported on Windows")
+	}
+	cfg := config.Default()
+	cfg.REPL.Enabled = true
+	if configure != nil {
+		configure(&cfg.REPL)
+	}
+	log, err := logger.New(logger.Options{Level: "error", Output: io.Discard})
+	require.NoError(t, err)
+
+	m := New(cfg, os.Environ(), log)
+	if _, err := exec.LookPath(m.Interpreter(interpreter).Command); err != nil {
+		t.Skipf("%s not in PATH", interpreter)
+	}
+	t.Cleanup(m.Close)
+	return m
+}
+
+func TestManager_Python(t *testing.T) {
+	m := newTestManager(t, "python", nil)
+	ctx := context.Background()
+
+	info, banner, err := m.Start(ctx, StartRequest{Interpreter: "python", WorkDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.Equal(t, "python-1", info.ID)
+	assert.Equal(t, ">>> ", banner.Prompt)
+
+	out, err := m.Send(ctx, SendRequest{Session: info.ID, Input: "x = 20\nfor i in range(2):\n    print(x + i)\n\n"})
+	require.NoError(t, err)
+	assert.Equal(t, "20\n21\n", out.Output)
+	assert.False(t, out.TimedOut)
+
+	// State survives between calls
+	out, err = m.Send(ctx, SendRequest{Session: info.ID, Input: "x * 2"})
+	require.NoError(t, err)
+	assert.Equal(t, "40\n", out.Output)
+
+	// A slow statement times out, and the next call picks up its output
+	out, err = m.Send(ctx, SendRequest{Session: info.ID, Input: "import time; time.sleep(0.5); print('late')\nprint('unsent')", Timeout: 100 * time.Millisecond})
+	require.NoError(t, err)
+	assert.True(t, out.TimedOut)
+	assert.Equal(t, 1, out.Unsent)
+	out, err = m.Send(ctx, SendRequest{Session: info.ID, Input: "print('next')"})
+	require.NoError(t, err)
+	assert.Equal(t, "late\nnext\n", out.Output)
+
+	out, err = m.Send(ctx, SendRequest{Session: info.ID, Input: "exit(4)"})
+	require.NoError(t, err)
+	assert.True(t, out.Exited)
+	require.NotNil(t, out.ExitCode)
+	assert.Equal(t, 4, *out.ExitCode)
+
+	_, err = m.Send(ctx, SendRequest{Session: info.ID, Input: "1"})
+	assert.ErrorContains(t, err, "no such REPL session")
+}
+
+func TestManager_Node(t *testing.T) {
+	m := newTestManager(t, "node", nil)
+	ctx := context.Background()
+
+	info, _, err := m.Start(ctx, StartRequest{Interpreter: "node"})
+	require.NoError(t, err)
+
+	out, err := m.Send(ctx, SendRequest{Session: info.ID, Input: "let x = 20\nx + 1"})
+	require.NoError(t, err)
+	assert.Equal(t, "undefined\n21\n", out.Output)
+	assert.Equal(t, "> ", out.Prompt)
+}
+
+func TestManager_Limits(t *testing.T) {
+	m := newTestManager(t, "python", func(c *config.REPLConfig) {
+		c.MaxSessions = 1
+		c.IdleTimeout = config.Duration(200 * time.Millisecond)
+		c.MaxOutputSize = 1024
+	})
+	ctx := context.Background()
+
+	info, _, err := m.Start(ctx, StartRequest{Interpreter: "python"})
+	require.NoError(t, err)
+
+	_, _, err = m.Start(ctx, StartRequest{Interpreter: "python"})
+	assert.ErrorContains(t, err, "session limit reached")
+	_, _, err = m.Start(ctx, StartRequest{Interpreter: "ruby"})
+	assert.ErrorContains(t, err, "unknown interpreter")
+
+	out, err := m.Send(ctx, SendRequest{Session: info.ID, Input: "print('a' * 5000)"})
+	require.NoError(t, err)
+	assert.True(t, out.Truncated)
+	assert.LessOrEqual(t, len(out.Output), 1024)
+	assert.Equal(t, ">>> ", out.Prompt)
+
+	// Idle sessions expire
+	require.Eventually(t, func() bool { return len(m.List()) == 0 }, 5*time.Second, 20*time.Millisecond)
+	_, err = m.Send(ctx, SendRequest{Session: info.ID, Input: "1"})
+	assert.ErrorContains(t, err, "may have expired")
+}
+
+func TestStripEcho(t *testing.T) {
+	assert.Equal(t, "2\n", stripEcho("1+1\n2\n", "1+1"))
+	assert.Equal(t, "2\n", stripEcho("\n2\n", ""))
+	assert.Equal(t, "other\n", stripEcho("other\n", "1+1"))
+	assert.Equal(t, "text", clean([]byte("\x1b[1mtext\x1b[0m\r")))
+}
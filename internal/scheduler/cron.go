@@ -0,0 +1,209 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed five-field cron expression. Each field is a
+// bitset of the values it matches.
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domStar and dowStar record whether the day fields were unrestricted;
+	// when both are restricted, a day matches if either field matches.
+	domStar, dowStar bool
+}
+
+// cronField describes the value range of a cron field.
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// cronMacros maps the supported shorthand expressions to their expansion.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a standard five-field cron expression
+// (minute hour day-of-month month day-of-week). Fields accept *, values,
+// ranges (1-5), steps (*/15, 1-30/5), lists (1,15) and, for month and day
+// of week, three-letter names.
+func ParseCron(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expanded, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = expanded
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields, got %d", expr, len(fields))
+	}
+
+	sched := &CronSchedule{}
+	var err error
+
+	if sched.minute, err = parseCronField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if sched.hour, err = parseCronField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if sched.dom, err = parseCronField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if sched.month, err = parseCronField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if sched.dow, err = parseCronField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+
+	// Sunday may be written as 0 or 7.
+	if sched.dow&(1<<7) != 0 {
+		sched.dow |= 1
+	}
+
+	sched.domStar = fields[2] == "*" || fields[2] == "?"
+	sched.dowStar = fields[4] == "*" || fields[4] == "?"
+
+	return sched, nil
+}
+
+// parseCronField parses a comma-separated cron field into a bitset.
+func parseCronField(value string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		b, err := parseCronRange(part, field)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+// parseCronRange parses a single range term such as "*", "5", "1-5" or "*/10".
+func parseCronRange(term string, field cronField) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(term, "/")
+
+	start, end := field.min, field.max
+	switch {
+	case rangePart == "*" || rangePart == "?":
+	case strings.Contains(rangePart, "-"):
+		lo, hi, _ := strings.Cut(rangePart, "-")
+		var err error
+		if start, err = parseCronValue(lo, field); err != nil {
+			return 0, err
+		}
+		if end, err = parseCronValue(hi, field); err != nil {
+			return 0, err
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid %s range %q", field.name, term)
+		}
+	default:
+		v, err := parseCronValue(rangePart, field)
+		if err != nil {
+			return 0, err
+		}
+		start = v
+		if !hasStep {
+			end = v
+		}
+	}
+
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepPart)
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid %s step %q", field.name, term)
+		}
+	}
+
+	var bits uint64
+	for v := start; v <= end; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+// parseCronValue parses a numeric or named field value.
+func parseCronValue(value string, field cronField) (int, error) {
+	if v, ok := field.names[strings.ToLower(value)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %q", field.name, value)
+	}
+	if v < field.min || v > field.max {
+		return 0, fmt.Errorf("%s value %d out of range %d-%d", field.name, v, field.min, field.max)
+	}
+	return v, nil
+}
+
+// Next returns the first time after t that matches the schedule, or the
+// zero time if none exists within the next five years.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches applies cron's day-of-month/day-of-week semantics.
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "* * * * *"},
+		{expr: "*/15 9-17 * * mon-fri"},
+		{expr: "0 0 1,15 jan,jul *"},
+		{expr: "30 2 * * 7"},
+		{expr: "@daily"},
+		{expr: "@hourly"},
+		{expr: "* * * *", wantErr: true},
+		{expr: "60 * * * *", wantErr: true},
+		{expr: "* 24 * * *", wantErr: true},
+		{expr: "* * 0 * *", wantErr: true},
+		{expr: "5-1 * * * *", wantErr: true},
+		{expr: "*/0 * * * *", wantErr: true},
+		{expr: "* * * foo *", wantErr: true},
+		{expr: "@sometimes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseCron(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCron(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	// Wednesday, 2024-01-10 10:07:30 UTC
+	base := time.Date(2024, 1, 10, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{expr: "* * * * *", want: time.Date(2024, 1, 10, 10, 8, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", want: time.Date(2024, 1, 10, 10, 15, 0, 0, time.UTC)},
+		{expr: "0 9 * * *", want: time.Date(2024, 1, 11, 9, 0, 0, 0, time.UTC)},
+		{expr: "30 8 * * mon", want: time.Date(2024, 1, 15, 8, 30, 0, 0, time.UTC)},
+		{expr: "0 0 1 * *", want: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 12 29 2 *", want: time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{expr: "0 0 * * 0", want: time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 * * 7", want: time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		// Day of month and day of week both restricted: either matches
+		{expr: "0 0 13 * fri", want: time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC)},
+		{expr: "@weekly", want: time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			sched, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error = %v", tt.expr, err)
+			}
+			if got := sched.Next(base); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronSchedule_NextImpossible(t *testing.T) {
+	sched, err := ParseCron("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sched.Next(time.Now()); !got.IsZero() {
+		t.Errorf("expected zero time for impossible schedule, got %v", got)
+	}
+}
@@ -0,0 +1,262 @@
+// Package scheduler runs configured commands on cron schedules
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/history"
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// Runner executes configured commands.
+type Runner interface {
+	ExecuteConfigCommand(ctx context.Context, cmd *config.Command, workDir string) (*types.CommandExecutionResult, error)
+}
+
+// Scheduler triggers configured commands according to their schedules and
+// records every run in the history store.
+type Scheduler struct {
+	runner  Runner
+	history *history.Store
+	logger  *logger.Logger
+	jobs    []*job
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	nowFunc func() time.Time
+}
+
+// job is a parsed schedule.
+type job struct {
+	schedule config.Schedule
+	command  config.Command
+	cron     *CronSchedule
+
+	mu      sync.Mutex
+	running bool
+	next    time.Time
+	lastRun time.Time
+}
+
+// ScheduleInfo describes a schedule and its next run time.
+type ScheduleInfo struct {
+	Name    string    `json:"name"`
+	Cron    string    `json:"cron"`
+	Command string    `json:"command"`
+	NextRun time.Time `json:"next_run"`
+	LastRun time.Time `json:"last_run,omitempty"`
+	Running bool      `json:"running"`
+}
+
+// New creates a scheduler for the schedules defined in cfg.
+func New(cfg *config.Config, runner Runner, hist *history.Store, log *logger.Logger) (*Scheduler, error) {
+	s := &Scheduler{
+		runner:  runner,
+		history: hist,
+		logger:  log,
+		nowFunc: time.Now,
+	}
+
+	for _, sched := range cfg.Schedules {
+		cron, err := ParseCron(sched.Cron)
+		if err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "invalid schedule "+sched.Name)
+		}
+
+		cmd := cfg.FindCommand(sched.Command)
+		if cmd == nil {
+			return nil, apperrors.ConfigurationError("schedule " + sched.Name + " references unknown command: " + sched.Command)
+		}
+
+		s.jobs = append(s.jobs, &job{
+			schedule: sched,
+			command:  *cmd,
+			cron:     cron,
+		})
+	}
+
+	return s, nil
+}
+
+// Start begins triggering schedules until Stop is called or ctx is done.
+func (s *Scheduler) Start(ctx context.Context) {
+	if len(s.jobs) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	now := s.nowFunc()
+	for _, j := range s.jobs {
+		j.mu.Lock()
+		j.next = j.cron.Next(now)
+		j.mu.Unlock()
+	}
+
+	s.wg.Add(1)
+	go s.loop(ctx)
+
+	s.logger.Info("scheduler started", "schedules", len(s.jobs))
+}
+
+// Stop stops the scheduler and waits for in-flight runs to finish.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+}
+
+// Schedules returns information about every configured schedule.
+func (s *Scheduler) Schedules() []ScheduleInfo {
+	infos := make([]ScheduleInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		j.mu.Lock()
+		infos = append(infos, ScheduleInfo{
+			Name:    j.schedule.Name,
+			Cron:    j.schedule.Cron,
+			Command: j.schedule.Command,
+			NextRun: j.next,
+			LastRun: j.lastRun,
+			Running: j.running,
+		})
+		j.mu.Unlock()
+	}
+	return infos
+}
+
+// loop waits for the earliest due job and triggers it.
+func (s *Scheduler) loop(ctx context.Context) {
+	defer s.wg.Done()
+
+	for {
+		next := s.nextDue()
+		if next.IsZero() {
+			s.logger.Warn("no schedules have an upcoming run time")
+			<-ctx.Done()
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		now := s.nowFunc()
+		for _, j := range s.jobs {
+			j.mu.Lock()
+			due := !j.next.IsZero() && !j.next.After(now)
+			if due {
+				j.next = j.cron.Next(now)
+			}
+			j.mu.Unlock()
+
+			if due {
+				s.trigger(ctx, j)
+			}
+		}
+	}
+}
+
+// nextDue returns the earliest upcoming run time across all jobs.
+func (s *Scheduler) nextDue() time.Time {
+	var next time.Time
+	for _, j := range s.jobs {
+		j.mu.Lock()
+		if !j.next.IsZero() && (next.IsZero() || j.next.Before(next)) {
+			next = j.next
+		}
+		j.mu.Unlock()
+	}
+	return next
+}
+
+// trigger runs a job in the background unless its previous run is still
+// in progress, in which case the run is skipped.
+func (s *Scheduler) trigger(ctx context.Context, j *job) {
+	j.mu.Lock()
+	if j.running {
+		j.mu.Unlock()
+		s.logger.Warn("skipping scheduled run, previous run still in progress",
+			"schedule", j.schedule.Name,
+		)
+		return
+	}
+	j.running = true
+	j.lastRun = s.nowFunc()
+	j.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			j.mu.Lock()
+			j.running = false
+			j.mu.Unlock()
+		}()
+
+		s.run(ctx, j)
+	}()
+}
+
+// run executes a job once and records the outcome.
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	s.logger.Info("running scheduled command",
+		"schedule", j.schedule.Name,
+		"command", j.command.Name,
+	)
+
+	cmd := j.command
+	result, err := s.runner.ExecuteConfigCommand(ctx, &cmd, j.schedule.WorkDir)
+
+	workDir := j.schedule.WorkDir
+	if cmd.WorkDir != "" {
+		workDir = cmd.WorkDir
+	}
+
+	rec := types.ExecutionRecord{
+		Source:   types.ExecutionSourceSchedule,
+		Tool:     cmd.Name,
+		Schedule: j.schedule.Name,
+		Request: types.CommandExecutionRequest{
+			Command: cmd.Command,
+			Args:    cmd.Args,
+			WorkDir: workDir,
+			Timeout: cmd.Timeout.String(),
+		},
+		Result:   result,
+		Decision: history.Decision(err),
+	}
+	if err != nil {
+		rec.Error = err.Error()
+		s.logger.WithError(err).Error("scheduled command failed",
+			"schedule", j.schedule.Name,
+		)
+	}
+
+	if s.history != nil {
+		s.history.Add(rec)
+	}
+}
@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/history"
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+type fakeRunner struct {
+	calls   []string
+	workDir string
+	err     error
+}
+
+func (f *fakeRunner) ExecuteConfigCommand(ctx context.Context, cmd *config.Command, workDir string) (*types.CommandExecutionResult, error) {
+	f.calls = append(f.calls, cmd.Name)
+	f.workDir = workDir
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &types.CommandExecutionResult{Stdout: "ok", ExitCode: 0}, nil
+}
+
+func testConfig() *config.Config {
+	cfg := config.Default()
+	cfg.Commands = []config.Command{
+		{Name: "backup", Description: "Back up", Command: "echo", Args: []string{"backup"}},
+	}
+	cfg.Schedules = []config.Schedule{
+		{Name: "nightly", Cron: "0 2 * * *", Command: "backup", WorkDir: "/tmp"},
+	}
+	return cfg
+}
+
+func TestNew(t *testing.T) {
+	cfg := testConfig()
+	if _, err := New(cfg, &fakeRunner{}, history.New(10), logger.Default()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg.Schedules[0].Cron = "not a cron"
+	if _, err := New(cfg, &fakeRunner{}, history.New(10), logger.Default()); err == nil {
+		t.Error("expected error for invalid cron expression")
+	}
+
+	cfg = testConfig()
+	cfg.Schedules[0].Command = "missing"
+	if _, err := New(cfg, &fakeRunner{}, history.New(10), logger.Default()); err == nil {
+		t.Error("expected error for unknown command")
+	}
+}
+
+func TestScheduler_RunRecordsHistory(t *testing.T) {
+	runner := &fakeRunner{}
+	hist := history.New(10)
+	s, err := New(testConfig(), runner, hist, logger.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.run(context.Background(), s.jobs[0])
+
+	runner.err = errors.New("boom")
+	s.run(context.Background(), s.jobs[0])
+
+	if len(runner.calls) != 2 || runner.workDir != "/tmp" {
+		t.Errorf("unexpected runner calls: %v (workdir %q)", runner.calls, runner.workDir)
+	}
+
+	runs := hist.List(history.Filter{Source: types.ExecutionSourceSchedule, Schedule: "nightly"})
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 recorded runs, got %d", len(runs))
+	}
+	if runs[0].Error != "boom" || runs[0].Result != nil {
+		t.Errorf("expected newest run to record the error, got %+v", runs[0])
+	}
+	if runs[1].Result == nil || runs[1].Result.HistoryID != runs[1].ID {
+		t.Errorf("expected result annotated with history id, got %+v", runs[1].Result)
+	}
+}
+
+func TestScheduler_StartStop(t *testing.T) {
+	s, err := New(testConfig(), &fakeRunner{}, history.New(10), logger.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.Start(context.Background())
+	infos := s.Schedules()
+	if len(infos) != 1 || infos[0].NextRun.IsZero() {
+		t.Errorf("expected next run to be computed, got %+v", infos)
+	}
+	s.Stop()
+	s.Stop() // Stopping twice is a no-op
+}
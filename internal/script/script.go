@@ -0,0 +1,284 @@
+// Package script runs tools defined in the configuration as Starlark
+// scripts, which branch on their arguments and compose configured commands
+// and file reads without the weight of a plugin
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/transfer"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	starjson "go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+const (
+	// DefaultTimeout limits a call when the tool sets no timeout.
+	DefaultTimeout = 5 * time.Minute
+
+	// defaultMaxSteps limits the computation of a call when the tool sets
+	// no limit.
+	defaultMaxSteps = 1_000_000
+
+	// envKey is the thread local holding the Env of a call.
+	envKey = "env"
+)
+
+// Env provides the primitives scripts may call.
+type Env struct {
+	// Run runs the configured command called name. Args are only appended
+	// if the command allows arguments.
+	Run func(ctx context.Context, name string, args []string, workDir string) (*types.CommandExecutionResult, error)
+
+	// ReadFile reads a range of a file within the transfer limits.
+	ReadFile func(path string, offset, length int64) (*transfer.Chunk, error)
+}
+
+// Program is a compiled script tool.
+type Program struct {
+	tool     config.ScriptTool
+	prog     *starlark.Program
+	timeout  time.Duration
+	maxSteps uint64
+}
+
+// predeclared are the names scripts may use besides the universe.
+var predeclared = starlark.StringDict{
+	"run":       starlark.NewBuiltin("run", run),
+	"read_file": starlark.NewBuiltin("read_file", readFile),
+	"json":      starjson.Module,
+	"struct":    starlark.NewBuiltin("struct", starlarkstruct.Make),
+}
+
+// Compile parses the script of a tool and checks that it defines main.
+func Compile(tool config.ScriptTool) (*Program, error) {
+	_, prog, err := starlark.SourceProgramOptions(&syntax.FileOptions{}, tool.Name+".star", tool.Script, predeclared.Has)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeValidation, "invalid script of tool "+tool.Name)
+	}
+
+	p := &Program{tool: tool, prog: prog, timeout: DefaultTimeout, maxSteps: defaultMaxSteps}
+	if tool.Timeout > 0 {
+		p.timeout = tool.Timeout.Std()
+	}
+	if tool.MaxSteps > 0 {
+		p.maxSteps = tool.MaxSteps
+	}
+
+	// Top-level statements have no Env, so they cannot run commands.
+	globals, err := p.init(&starlark.Thread{Name: tool.Name})
+	if err != nil {
+		return nil, err
+	}
+	if fn, ok := globals["main"].(*starlark.Function); !ok || fn.NumParams() != 1 {
+		return nil, apperrors.ValidationError("script of tool "+tool.Name+" must define main(params)", "script")
+	}
+	return p, nil
+}
+
+// Tool returns the definition of the tool.
+func (p *Program) Tool() config.ScriptTool {
+	return p.tool
+}
+
+// Timeout returns the limit of a call.
+func (p *Program) Timeout() time.Duration {
+	return p.timeout
+}
+
+// init runs the top level of the script.
+func (p *Program) init(thread *starlark.Thread) (starlark.StringDict, error) {
+	thread.SetMaxExecutionSteps(p.maxSteps)
+	globals, err := p.prog.Init(thread, predeclared)
+	if err != nil {
+		return nil, scriptError(p.tool.Name, err)
+	}
+	return globals, nil
+}
+
+// Result is the outcome of a call.
+type Result struct {
+	// Text is what main returned: strings as they are, other values as
+	// JSON
+	Text string
+
+	// Log holds what the script printed
+	Log []string
+}
+
+// Call runs main with the tool arguments. Every call starts from a fresh
+// copy of the script's globals, so no state is kept between calls.
+func (p *Program) Call(ctx context.Context, env Env, args map[string]any) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	result := &Result{}
+	thread := &starlark.Thread{
+		Name:  p.tool.Name,
+		Print: func(_ *starlark.Thread, msg string) { result.Log = append(result.Log, msg) },
+	}
+	thread.SetLocal(envKey, &callEnv{Env: env, ctx: ctx})
+
+	stop := context.AfterFunc(ctx, func() { thread.Cancel(ctx.Err().Error()) })
+	defer stop()
+
+	globals, err := p.init(thread)
+	if err != nil {
+		return nil, p.callError(ctx, err)
+	}
+	params, err := toStarlark(thread, args)
+	if err != nil {
+		return nil, err
+	}
+	value, err := starlark.Call(thread, globals["main"], starlark.Tuple{params}, nil)
+	if err != nil {
+		return nil, p.callError(ctx, scriptError(p.tool.Name, err))
+	}
+
+	if s, ok := value.(starlark.String); ok {
+		result.Text = string(s)
+	} else if value != starlark.None {
+		text, err := encode(thread, value)
+		if err != nil {
+			return nil, scriptError(p.tool.Name, err)
+		}
+		result.Text = text
+	}
+	return result, nil
+}
+
+// callError reports calls cancelled by their timeout as timeouts.
+func (p *Program) callError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return apperrors.TimeoutError(fmt.Sprintf("script tool %s timed out", p.tool.Name), p.timeout.String())
+	}
+	return err
+}
+
+// scriptError wraps a Starlark error, keeping its backtrace out of the
+// message.
+func scriptError(name string, err error) error {
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		err = errors.New(evalErr.Msg)
+	}
+	return apperrors.Wrap(err, apperrors.ErrorTypeExecution, "script tool "+name+" failed")
+}
+
+// callEnv is the Env of a call with its context.
+type callEnv struct {
+	Env
+	ctx context.Context
+}
+
+// envOf returns the Env of a call, which top-level statements lack.
+func envOf(thread *starlark.Thread, b *starlark.Builtin) (*callEnv, error) {
+	env, _ := thread.Local(envKey).(*callEnv)
+	if env == nil {
+		return nil, fmt.Errorf("%s: only available in main", b.Name())
+	}
+	return env, nil
+}
+
+// run is run(command, args=[], workdir=""). It returns a struct of ok,
+// exit_code, stdout, stderr and error instead of failing, so scripts can
+// branch on the outcome.
+func run(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name, workDir string
+	var list *starlark.List
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "command", &name, "args?", &list, "workdir?", &workDir); err != nil {
+		return nil, err
+	}
+	env, err := envOf(thread, b)
+	if err != nil {
+		return nil, err
+	}
+
+	var cmdArgs []string
+	if list != nil {
+		for i := range list.Len() {
+			s, ok := starlark.AsString(list.Index(i))
+			if !ok {
+				return nil, fmt.Errorf("%s: args[%d] is %s, want string", b.Name(), i, list.Index(i).Type())
+			}
+			cmdArgs = append(cmdArgs, s)
+		}
+	}
+
+	result, err := env.Run(env.ctx, name, cmdArgs, workDir)
+	fields := starlark.StringDict{
+		"ok":        starlark.False,
+		"exit_code": starlark.MakeInt(-1),
+		"stdout":    starlark.String(""),
+		"stderr":    starlark.String(""),
+		"error":     starlark.String(""),
+	}
+	if result != nil {
+		fields["exit_code"] = starlark.MakeInt(result.ExitCode)
+		fields["stdout"] = starlark.String(result.Stdout)
+		fields["stderr"] = starlark.String(result.Stderr)
+	}
+	if err != nil {
+		fields["error"] = starlark.String(err.Error())
+	} else {
+		fields["ok"] = starlark.Bool(result.ExitCode == 0)
+	}
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, fields), nil
+}
+
+// readFile is read_file(path, offset=0, length=0). It returns a struct of
+// data, encoding, size and eof, and fails if the file cannot be read.
+func readFile(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path string
+	var offset, length int64
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "path", &path, "offset?", &offset, "length?", &length); err != nil {
+		return nil, err
+	}
+	env, err := envOf(thread, b)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk, err := env.ReadFile(path, offset, length)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"data":     starlark.String(chunk.Data),
+		"encoding": starlark.String(chunk.Encoding),
+		"size":     starlark.MakeInt64(chunk.Size),
+		"eof":      starlark.Bool(chunk.EOF),
+	}), nil
+}
+
+// toStarlark converts tool arguments by way of JSON.
+func toStarlark(thread *starlark.Thread, args map[string]any) (starlark.Value, error) {
+	if args == nil {
+		args = map[string]any{}
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeValidation, "invalid tool arguments")
+	}
+	decode := starjson.Module.Members["decode"]
+	return starlark.Call(thread, decode, starlark.Tuple{starlark.String(data)}, nil)
+}
+
+// encode converts a value main returned to JSON.
+func encode(thread *starlark.Thread, value starlark.Value) (string, error) {
+	encode := starjson.Module.Members["encode"]
+	out, err := starlark.Call(thread, encode, starlark.Tuple{value}, nil)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out.(starlark.String))), nil
+}
@@ -0,0 +1,154 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/transfer"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEnv records the commands run and serves files from memory.
+type fakeEnv struct {
+	runs  [][]string
+	files map[string]string
+}
+
+func (f *fakeEnv) env() Env {
+	return Env{
+		Run: func(_ context.Context, name string, args []string, _ string) (*types.CommandExecutionResult, error) {
+			f.runs = append(f.runs, append([]string{name}, args...))
+			switch name {
+			case "test":
+				return &types.CommandExecutionResult{ExitCode: 1, Stdout: "FAIL"}, nil
+			case "lint":
+				return &types.CommandExecutionResult{Stdout: "ok"}, nil
+			}
+			return nil, errors.New("unknown command: " + name)
+		},
+		ReadFile: func(path string, _, _ int64) (*transfer.Chunk, error) {
+			data, ok := f.files[path]
+			if !ok {
+				return nil, errors.New("no such file")
+			}
+			return &transfer.Chunk{Path: path, Data: data, Encoding: "utf-8", Size: int64(len(data)), EOF: true}, nil
+		},
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		errMsg string
+	}{
+		{name: "valid", script: "def main(params):\n    return 'ok'\n"},
+		{name: "syntax error", script: "def main(params)\n", errMsg: "invalid script"},
+		{name: "undefined name", script: "def main(params):\n    return exec('x')\n", errMsg: "undefined: exec"},
+		{name: "no main", script: "x = 1\n", errMsg: "must define main(params)"},
+		{name: "main without params", script: "def main():\n    return 1\n", errMsg: "must define main(params)"},
+		{name: "run at top level", script: "r = run('test')\ndef main(params):\n    return r\n", errMsg: "only available in main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(config.ScriptTool{Name: "tool", Script: tt.script})
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestProgram_Call(t *testing.T) {
+	prog, err := Compile(config.ScriptTool{
+		Name: "check",
+		Script: `
+def main(params):
+    target = params.get("target", "all")
+    if target == "lint":
+        return run("lint", ["./..."]).stdout
+    if target == "version":
+        return read_file("/repo/VERSION").data.strip()
+    if target == "missing":
+        return run("deploy").error
+    r = run("test")
+    print("tests exited", r.exit_code)
+    return {"ok": r.ok, "output": r.stdout}
+`,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	fake := &fakeEnv{files: map[string]string{"/repo/VERSION": "1.2.3\n"}}
+
+	result, err := prog.Call(ctx, fake.env(), map[string]any{"target": "lint"})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result.Text)
+	assert.Equal(t, [][]string{{"lint", "./..."}}, fake.runs)
+
+	result, err = prog.Call(ctx, fake.env(), map[string]any{"target": "version"})
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", result.Text)
+
+	result, err = prog.Call(ctx, fake.env(), map[string]any{"target": "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, "unknown command: deploy", result.Text)
+
+	result, err = prog.Call(ctx, fake.env(), nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ok": false, "output": "FAIL"}`, result.Text)
+	assert.Equal(t, []string{"tests exited 1"}, result.Log)
+}
+
+func TestProgram_CallFailures(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeEnv{}
+
+	prog, err := Compile(config.ScriptTool{Name: "fails", Script: "def main(params):\n    fail('bad input')\n"})
+	require.NoError(t, err)
+	_, err = prog.Call(ctx, fake.env(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad input")
+
+	prog, err = Compile(config.ScriptTool{Name: "reads", Script: "def main(params):\n    return read_file('/missing').data\n"})
+	require.NoError(t, err)
+	_, err = prog.Call(ctx, fake.env(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no such file")
+
+	prog, err = Compile(config.ScriptTool{
+		Name:     "loops",
+		Script:   "def main(params):\n    n = 0\n    for i in range(1000000):\n        n += i\n    return n\n",
+		MaxSteps: 1000,
+	})
+	require.NoError(t, err)
+	_, err = prog.Call(ctx, fake.env(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too many steps")
+}
+
+func TestProgram_CallTimeout(t *testing.T) {
+	prog, err := Compile(config.ScriptTool{
+		Name:     "spins",
+		Script:   "def main(params):\n    for i in range(100000000):\n        pass\n",
+		Timeout:  config.Duration(50 * time.Millisecond),
+		MaxSteps: 1 << 40,
+	})
+	require.NoError(t, err)
+
+	_, err = prog.Call(context.Background(), (&fakeEnv{}).env(), nil)
+	require.Error(t, err)
+	var appErr *apperrors.Error
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.ErrorTypeTimeout, appErr.Type)
+}
@@ -0,0 +1,66 @@
+// Package security carries the identity of the client behind a request
+// from the MCP handlers to the executor and policy engine
+package security
+
+import (
+	"context"
+	"os/user"
+)
+
+// Context identifies who a request comes from.
+type Context struct {
+	// ClientName and ClientVersion are what the client reported when it
+	// initialized the session; they are not verified
+	ClientName    string
+	ClientVersion string
+
+	// SessionID identifies the MCP session the request arrived on
+	SessionID string
+
+	// Principal is the authenticated identity behind the session. Over
+	// stdio it is the local user running the server, whom the operating
+	// system authenticated; network transports set it from their own
+	// authentication
+	Principal string
+}
+
+// User returns the identity user-scoped rules match against, or "" when
+// the request is unauthenticated.
+func (c *Context) User() string {
+	if c == nil {
+		return ""
+	}
+	return c.Principal
+}
+
+// Client returns the client name, or "" when unknown.
+func (c *Context) Client() string {
+	if c == nil {
+		return ""
+	}
+	return c.ClientName
+}
+
+type contextKey struct{}
+
+// WithContext returns a context carrying sc.
+func WithContext(ctx context.Context, sc *Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, sc)
+}
+
+// FromContext returns the security context carried by ctx, or nil for
+// requests that did not come from a client, such as scheduled runs.
+func FromContext(ctx context.Context) *Context {
+	sc, _ := ctx.Value(contextKey{}).(*Context)
+	return sc
+}
+
+// LocalPrincipal returns the name of the user running the server, the
+// principal of stdio sessions.
+func LocalPrincipal() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/archive"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ExtractArchiveParams represents parameters for extracting an archive.
+type ExtractArchiveParams struct {
+	Archive   string `json:"archive"`
+	Dest      string `json:"dest"`
+	Format    string `json:"format,omitempty"` // zip or tar.gz; detected from the name
+	Overwrite bool   `json:"overwrite,omitempty"`
+}
+
+// CreateArchiveParams represents parameters for creating an archive.
+type CreateArchiveParams struct {
+	Archive   string   `json:"archive"`
+	Sources   []string `json:"sources"`
+	Format    string   `json:"format,omitempty"` // zip or tar.gz; detected from the name
+	Overwrite bool     `json:"overwrite,omitempty"`
+}
+
+// registerArchiveTools registers the archive tools.
+func (s *Server) registerArchiveTools() error {
+	s.registerExtractArchiveTool()
+	s.registerCreateArchiveTool()
+
+	s.logger.Debug("registered archive tools")
+
+	return nil
+}
+
+func (s *Server) registerExtractArchiveTool() {
+	tool := &mcp.Tool{
+		Name:        "extract_archive",
+		Description: "Extract a zip or tar.gz archive (absolute path) into a destination directory without tar or unzip. Entries that would land outside the destination are rejected, links are skipped, and entry count and size are limited. Existing files are only replaced with overwrite.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ExtractArchiveParams]) (*mcp.CallToolResultFor[archive.Result], error) {
+		args := params.Arguments
+
+		// Failed extractions may have written some files, so keep them
+		gen := s.backups.Begin("extract_archive")
+		result, err := s.archiver.Extract(archive.ExtractRequest{
+			Archive:     args.Archive,
+			Dest:        args.Dest,
+			Format:      args.Format,
+			Overwrite:   args.Overwrite,
+			BeforeWrite: gen.Save,
+		})
+		s.finishBackup(gen, true)
+		if err != nil {
+			s.logger.WithError(err).Error("archive extraction failed", "archive", args.Archive)
+			return archiveErrorResult("Extraction", err), nil
+		}
+
+		text := fmt.Sprintf("Extracted %d entries (%d bytes) from %s to %s", result.Entries, result.Size, result.Archive, result.Dest)
+		if len(result.Skipped) > 0 {
+			text += fmt.Sprintf("; skipped %d links or special files", len(result.Skipped))
+		}
+
+		return &mcp.CallToolResultFor[archive.Result]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+			StructuredContent: *result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerCreateArchiveTool() {
+	tool := &mcp.Tool{
+		Name:        "create_archive",
+		Description: "Create a zip or tar.gz archive at an absolute path from files and directories (stored under their base names) without tar or zip. Links are skipped, and entry count and size are limited.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[CreateArchiveParams]) (*mcp.CallToolResultFor[archive.Result], error) {
+		args := params.Arguments
+
+		gen := s.backups.Begin("create_archive")
+		err := gen.Save(args.Archive)
+
+		var result *archive.Result
+		if err == nil {
+			result, err = s.archiver.Create(archive.CreateRequest{
+				Archive:   args.Archive,
+				Sources:   args.Sources,
+				Format:    args.Format,
+				Overwrite: args.Overwrite,
+			})
+		}
+		s.finishBackup(gen, err == nil)
+		if err != nil {
+			s.logger.WithError(err).Error("archive creation failed", "archive", args.Archive)
+			return archiveErrorResult("Archive creation", err), nil
+		}
+
+		text := fmt.Sprintf("Created %s with %d entries (%d bytes)", result.Archive, result.Entries, result.Size)
+		if len(result.Skipped) > 0 {
+			text += fmt.Sprintf("; skipped %d links or special files", len(result.Skipped))
+		}
+
+		return &mcp.CallToolResultFor[archive.Result]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+			StructuredContent: *result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+// archiveErrorResult converts an error into a tool error result.
+func archiveErrorResult(operation string, err error) *mcp.CallToolResultFor[archive.Result] {
+	return &mcp.CallToolResultFor[archive.Result]{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("%s failed: %s", operation, err.Error())},
+		},
+		IsError: true,
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/backup"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// UndoLastChangeParams represents parameters for undoing a change.
+type UndoLastChangeParams struct{}
+
+// registerBackupTool registers the undo tool unless backups are disabled.
+func (s *Server) registerBackupTool() error {
+	if !s.backups.Enabled() {
+		s.logger.Debug("backups disabled")
+		return nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "undo_last_change",
+		Description: "Revert the most recent file change made by download_file, extract_archive or create_archive: replaced files are restored from the server's backups and created files are removed. Call repeatedly to step further back. Files too large to back up are reported as skipped.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[UndoLastChangeParams]) (*mcp.CallToolResultFor[backup.UndoResult], error) {
+		result, err := s.backups.UndoLast()
+		if err != nil {
+			s.logger.WithError(err).Error("undo failed")
+			return &mcp.CallToolResultFor[backup.UndoResult]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Undo failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Undid %s change from %s: %d restored, %d removed",
+			result.Tool, result.Time.Format("2006-01-02 15:04:05"), len(result.Restored), len(result.Removed))
+		for _, path := range result.Restored {
+			fmt.Fprintf(&b, "\n  restored %s", path)
+		}
+		for _, path := range result.Removed {
+			fmt.Fprintf(&b, "\n  removed %s", path)
+		}
+		for _, path := range result.Skipped {
+			fmt.Fprintf(&b, "\n  not backed up %s", path)
+		}
+
+		return &mcp.CallToolResultFor[backup.UndoResult]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: b.String()}},
+			StructuredContent: *result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered undo tool")
+
+	return nil
+}
+
+// finishBackup keeps the backups of a tool call that changed files so it
+// can be undone, and drops them otherwise.
+func (s *Server) finishBackup(gen *backup.Generation, changed bool) {
+	if !changed {
+		gen.Discard()
+		return
+	}
+	if err := gen.Commit(); err != nil {
+		s.logger.WithError(err).Warn("failed to keep backup")
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerBatchTool registers the batch execution tool.
+func (s *Server) registerBatchTool() error {
+	tool := &mcp.Tool{
+		Name:        "execute_batch",
+		Description: "Execute several commands in one call. Each step has an id and may list depends_on step ids; independent steps run in parallel and a step is skipped if any dependency fails. A step may capture values from its stdout (capture: {name: regex or $.json.path}) that dependent steps reference in args as {{step_id.name}}. Returns per-step results grouped by dependency level.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[types.BatchExecutionRequest]) (*mcp.CallToolResultFor[types.BatchExecutionResult], error) {
+		s.logger.Info("executing batch", "steps", len(params.Arguments.Steps))
+
+		result, err := s.executor.ExecuteBatch(ctx, &params.Arguments)
+		if err != nil {
+			s.logger.WithError(err).Error("batch execution failed")
+
+			return &mcp.CallToolResultFor[types.BatchExecutionResult]{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: s.msg.Sprintf("Batch execution failed: %s", err.Error()),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		// Record every step that ran
+		for i, step := range result.Steps {
+			if step.Status == types.BatchStepSkipped {
+				continue
+			}
+			stepErr := step.Err
+			if stepErr == nil && step.Result == nil && step.Error != "" {
+				stepErr = errors.New(step.Error)
+			}
+			decision := types.PolicyDecisionAllowed
+			if step.Denied {
+				decision = types.PolicyDecisionDenied
+			}
+			req := params.Arguments.Steps[i]
+			result.Steps[i].Result = s.recordDecision(ctx, "execute_batch", types.CommandExecutionRequest{
+				Command: req.Command,
+				Args:    req.Args,
+				WorkDir: req.WorkDir,
+				Env:     req.Env,
+				Timeout: req.Timeout,
+			}, step.Result, stepErr, decision)
+		}
+
+		return &mcp.CallToolResultFor[types.BatchExecutionResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: formatBatchResult(result)},
+			},
+			StructuredContent: *result,
+			IsError:           result.Failed > 0,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered batch tool")
+
+	return nil
+}
+
+// formatBatchResult renders a batch result as text, one line per step.
+func formatBatchResult(result *types.BatchExecutionResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Batch finished: %d succeeded, %d failed, %d skipped\n",
+		result.Succeeded, result.Failed, result.Skipped)
+
+	for _, step := range result.Steps {
+		fmt.Fprintf(&b, "\n[%s] %s (level %d)", step.ID, step.Status, step.Level)
+		if len(step.DependsOn) > 0 {
+			fmt.Fprintf(&b, " after %s", strings.Join(step.DependsOn, ", "))
+		}
+		b.WriteString("\n")
+		for name, value := range step.Captures {
+			fmt.Fprintf(&b, "Captured %s = %s\n", name, value)
+		}
+		if step.Error != "" {
+			fmt.Fprintf(&b, "Error: %s\n", step.Error)
+		}
+		if step.Result != nil {
+			fmt.Fprintf(&b, "Stdout: %s\nStderr: %s\nExit Code: %d%s\n",
+				step.Result.Stdout, step.Result.Stderr, step.Result.ExitCode, formatSpilledOutput(step.Result)+formatOutputSummary(step.Result.Summary)+formatQuarantine(step.Result))
+		}
+	}
+
+	return b.String()
+}
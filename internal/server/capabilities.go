@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/internal/quarantine"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// GetCapabilitiesParams represents parameters for describing capabilities.
+type GetCapabilitiesParams struct{}
+
+// securityProfile summarizes the security policy of a configuration.
+func securityProfile(cfg *config.Config) types.SecurityProfile {
+	sec := cfg.Security
+	profile := types.SecurityProfile{
+		Policy:             sec.Policy,
+		ShellExpansion:     !sec.DisableShellExpansion,
+		RestrictedCommands: len(sec.AllowedCommands) > 0,
+		BlockedCommands:    len(sec.BlockedCommands),
+		RestrictedPaths:    len(sec.AllowedPaths) > 0,
+		Conditions:         len(sec.Conditions),
+		ForceUnlock:        sec.AllowForceUnlock,
+	}
+	if profile.Policy == "" {
+		profile.Policy = config.PolicyEnforce
+	}
+	return profile
+}
+
+// capabilities describes the limits, security profile and features of a
+// configuration.
+func capabilities(cfg *config.Config) types.Capabilities {
+	sec := cfg.Security
+	caps := types.Capabilities{
+		App: cfg.App,
+		OS:  runtime.GOOS,
+		Limits: types.CapabilityLimits{
+			DefaultTimeout:   cfg.Execution.DefaultTimeout.String(),
+			MaxTimeout:       cfg.Execution.MaxTimeout.String(),
+			MaxOutputSize:    int64(cfg.Execution.MaxOutputSize),
+			MaxConcurrent:    cfg.Execution.MaxConcurrent,
+			MaxQueue:         cfg.Execution.MaxQueue,
+			MaxCommandLength: sec.MaxCommandLength,
+			MaxBatchSteps:    executor.MaxBatchSteps,
+			MaxWatches:       cfg.Watch.MaxWatches,
+			MaxDownloadSize:  int64(cfg.Transfer.MaxDownloadSize),
+			MaxChunkSize:     int64(cfg.Transfer.MaxChunkSize),
+		},
+		Security: securityProfile(cfg),
+		Features: []string{},
+	}
+
+	feature := func(name string, enabled bool) {
+		if enabled {
+			caps.Features = append(caps.Features, name)
+		}
+	}
+	feature("persistent_history", cfg.History.Path != "")
+	feature("signed_receipts", cfg.History.SigningKey != "")
+	feature("output_spill", cfg.Execution.SpillThreshold > 0)
+	feature("login_shell_env", cfg.Execution.LoginShellEnv)
+	feature("dev_environment", cfg.Execution.DevEnvironment != "" && cfg.Execution.DevEnvironment != config.DevEnvOff)
+	feature("pinned_toolchains", len(cfg.Execution.Toolchains) > 0)
+	feature("command_prompts", cfg.Server.CommandPrompts)
+	feature("fault_injection", cfg.Chaos.Enabled())
+	feature("schedules", len(cfg.Schedules) > 0)
+	feature("notifications", !cfg.Notifications.Disabled)
+	feature("undo", !cfg.Backup.Disabled)
+	feature("trash", !cfg.Trash.Disabled)
+	feature("git_snapshots", cfg.GitSnapshot.Enabled)
+	feature("containers", cfg.Containers.Enabled)
+	feature("tmux", cfg.Tmux.Enabled)
+	feature("repl", cfg.REPL.Enabled)
+	feature("http_requests", cfg.HTTP.Enabled)
+	feature("clear_quarantine", quarantine.Supported && sec.AllowClearQuarantine)
+	return caps
+}
+
+// capabilitiesText renders capabilities for the text content of results
+// and the instructions sent to clients.
+func capabilitiesText(caps types.Capabilities) string {
+	l, sec := caps.Limits, caps.Security
+	var b strings.Builder
+	fmt.Fprintf(&b, "Commands time out after %s by default and at most %s; at most %d run at once.\n",
+		l.DefaultTimeout, l.MaxTimeout, l.MaxConcurrent)
+	fmt.Fprintf(&b, "Output is limited to %d bytes per stream and commands to %d characters; batches take at most %d steps.\n",
+		l.MaxOutputSize, l.MaxCommandLength, l.MaxBatchSteps)
+	fmt.Fprintf(&b, "Security policy: %s", sec.Policy)
+	if sec.RestrictedCommands {
+		b.WriteString(", only allowed commands")
+	}
+	if sec.RestrictedPaths {
+		b.WriteString(", only allowed paths")
+	}
+	if !sec.ShellExpansion {
+		b.WriteString(", no shell metacharacters")
+	}
+	b.WriteString(".")
+	if len(caps.Features) > 0 {
+		fmt.Fprintf(&b, "\nFeatures: %s.", strings.Join(caps.Features, ", "))
+	}
+	return b.String()
+}
+
+// registerCapabilitiesTool registers the capabilities tool.
+func (s *Server) registerCapabilitiesTool() error {
+	tool := &mcp.Tool{
+		Name:        "get_capabilities",
+		Description: "Describe the limits of the server (default and maximum timeout, output size, concurrent runs, command length, batch steps, watches and downloads), a summary of its security policy and the optional features that are enabled, to plan commands within them.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[GetCapabilitiesParams]) (*mcp.CallToolResultFor[types.Capabilities], error) {
+		caps := capabilities(s.config)
+		return &mcp.CallToolResultFor[types.Capabilities]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: capabilitiesText(caps)}},
+			StructuredContent: caps,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered capabilities tool")
+
+	return nil
+}
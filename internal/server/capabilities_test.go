@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_getCapabilities(t *testing.T) {
+	cfg := config.Default()
+	cfg.Execution.MaxTimeout = config.Duration(2 * time.Minute)
+	cfg.Security.AllowedCommands = []string{"echo"}
+	cfg.Backup.Disabled = true
+	_, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "get_capabilities"})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	data, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var caps types.Capabilities
+	if err := json.Unmarshal(data, &caps); err != nil {
+		t.Fatal(err)
+	}
+	if caps.Limits.MaxTimeout != "2m" || caps.Limits.MaxBatchSteps == 0 {
+		t.Errorf("limits = %+v", caps.Limits)
+	}
+	if !caps.Security.RestrictedCommands || caps.Security.Policy != config.PolicyEnforce {
+		t.Errorf("security = %+v", caps.Security)
+	}
+	if text := capabilitiesText(caps); !strings.Contains(text, "at most 2m") || !strings.Contains(text, "only allowed commands") {
+		t.Errorf("capabilitiesText() = %q", text)
+	}
+	for _, feature := range caps.Features {
+		if feature == "undo" {
+			t.Errorf("features = %v, want undo disabled", caps.Features)
+		}
+	}
+}
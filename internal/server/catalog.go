@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/catalog"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// loadCatalog adds the commands of the configured catalog to the
+// configured ones before tools are registered. A catalog that cannot be
+// fetched or is invalid leaves the configured commands alone; refreshes
+// try again.
+func (s *Server) loadCatalog() error {
+	fetcher, err := catalog.New(s.config, s.logger)
+	if err != nil {
+		return err
+	}
+	s.catalog = fetcher
+	s.localCommands = slices.Clone(s.config.Commands)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	merged, err := s.fetchCatalog(ctx)
+	if err != nil {
+		s.logger.WithError(err).Warn("failed to load command catalog", "url", s.config.Catalog.URL)
+		return nil
+	}
+	s.config.Commands = merged
+	return nil
+}
+
+// fetchCatalog fetches the catalog and returns the configured commands
+// merged with its commands.
+func (s *Server) fetchCatalog(ctx context.Context) ([]config.Command, error) {
+	cat, err := s.catalog.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	merged, shadowed, err := catalog.Merge(s.config, s.localCommands, cat)
+	if err != nil {
+		return nil, err
+	}
+	if len(shadowed) > 0 {
+		s.logger.Warn("catalog commands shadowed by configured commands", "commands", shadowed)
+	}
+	return merged, nil
+}
+
+// refreshCatalog fetches the catalog on the configured interval until ctx
+// is done, and returns a function that stops it.
+func (s *Server) refreshCatalog(ctx context.Context) func() {
+	interval := s.config.Catalog.Refresh.Std()
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				merged, err := s.fetchCatalog(ctx)
+				if err != nil {
+					s.logger.WithError(err).Warn("failed to refresh command catalog", "url", s.config.Catalog.URL)
+					continue
+				}
+				s.updateCommands(merged)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// updateCommands replaces the configured commands, registering added and
+// changed commands and removing the tools of dropped ones.
+func (s *Server) updateCommands(commands []config.Command) {
+	s.commandsMu.Lock()
+	old := s.config.Commands
+	s.config.Commands = commands
+	s.commandsMu.Unlock()
+
+	s.revisionMu.Lock()
+	s.revision = ""
+	s.revisionMu.Unlock()
+
+	previous := make(map[string]config.Command, len(old))
+	for _, cmd := range old {
+		previous[cmd.Name] = cmd
+	}
+
+	var added, changed []string
+	for _, cmd := range commands {
+		prev, ok := previous[cmd.Name]
+		delete(previous, cmd.Name)
+		switch {
+		case !ok:
+			added = append(added, cmd.Name)
+		case !reflect.DeepEqual(prev, cmd):
+			changed = append(changed, cmd.Name)
+		default:
+			continue
+		}
+		if err := s.registerConfigCommand(cmd); err != nil {
+			s.logger.WithError(err).Error("failed to register catalog command", "command", cmd.Name)
+		}
+	}
+
+	var removed []string
+	for name := range previous {
+		removed = append(removed, s.config.Server.ToolPrefix+name)
+	}
+	if len(removed) > 0 {
+		s.removeTools(removed...)
+	}
+
+	if len(added)+len(changed)+len(removed) > 0 {
+		slices.Sort(removed)
+		s.logger.Info("command catalog updated",
+			"added", added,
+			"changed", changed,
+			"removed", removed,
+		)
+		s.emit(types.EventCommandsUpdated, "command catalog updated", map[string]any{
+			"added":   added,
+			"changed": changed,
+			"removed": removed,
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func TestServer_updateCommands(t *testing.T) {
+	cfg := config.Default()
+	cfg.Commands = []config.Command{
+		{Name: "keep", Description: "Kept", Command: "echo"},
+		{Name: "drop", Description: "Dropped", Command: "echo"},
+	}
+	srv := newTestServer(t, cfg)
+
+	srv.updateCommands([]config.Command{
+		{Name: "keep", Description: "Kept", Command: "echo"},
+		{Name: "added", Description: "Added", Command: "echo"},
+	})
+
+	cs := connectClient(t, srv, nil)
+	ctx := context.Background()
+
+	res, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	var names []string
+	for _, tool := range res.Tools {
+		names = append(names, tool.Name)
+	}
+	if !slices.Contains(names, "keep") || !slices.Contains(names, "added") || slices.Contains(names, "drop") {
+		t.Errorf("tools = %v, want keep and added but not drop", names)
+	}
+	if srv.findCommand("added") == nil || srv.findCommand("drop") != nil {
+		t.Error("findCommand() does not reflect the updated commands")
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/cmdcheck"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CheckCommandsParams represents parameters for checking configured
+// commands.
+type CheckCommandsParams struct {
+	Command string `json:"command,omitempty"` // Only check this configured command
+}
+
+// CheckCommandsResult lists the outcome of checking configured commands.
+type CheckCommandsResult struct {
+	Results  []cmdcheck.Result `json:"results"`
+	Broken   int               `json:"broken"`
+	Disabled []string          `json:"disabled,omitempty"` // Broken commands not registered as tools
+}
+
+// checkCommand checks that a configured command can run before it is
+// registered, logging its problems. It reports false when the command is
+// broken and command_checks.disable_broken is set, after removing its tool.
+func (s *Server) checkCommand(cmd config.Command) bool {
+	if s.config.CommandChecks.Disabled {
+		return true
+	}
+
+	result := cmdcheck.Check(cmd)
+	s.brokenMu.Lock()
+	defer s.brokenMu.Unlock()
+	if result.OK {
+		delete(s.broken, cmd.Name)
+		return true
+	}
+
+	for _, p := range result.Problems {
+		s.logger.Warn("configured command cannot run", "command", cmd.Name, "problem", p.Message, "hint", p.Hint)
+	}
+	if !s.config.CommandChecks.DisableBroken {
+		return true
+	}
+
+	if s.broken == nil {
+		s.broken = map[string]bool{}
+	}
+	s.broken[cmd.Name] = true
+	s.removeTools(s.config.Server.ToolPrefix + cmd.Name)
+	s.logger.Warn("not registering configured command that cannot run", "command", cmd.Name)
+	return false
+}
+
+// disabledCommands returns the broken commands not registered as tools.
+func (s *Server) disabledCommands() map[string]bool {
+	s.brokenMu.Lock()
+	defer s.brokenMu.Unlock()
+
+	disabled := make(map[string]bool, len(s.broken))
+	for name := range s.broken {
+		disabled[name] = true
+	}
+	return disabled
+}
+
+func (s *Server) registerCheckCommandsTool() error {
+	tool := &mcp.Tool{
+		Name:        "check_commands",
+		Description: "Check that configured commands can run: that their binaries exist and are executable and their working directories exist. Reports each problem with a hint for installing missing binaries. Commands disabled because they could not run are registered again once they pass.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[CheckCommandsParams]) (*mcp.CallToolResultFor[CheckCommandsResult], error) {
+		s.commandsMu.RLock()
+		commands := append([]config.Command(nil), s.config.Commands...)
+		s.commandsMu.RUnlock()
+
+		if name := params.Arguments.Command; name != "" {
+			cmd := s.findCommand(name)
+			if cmd == nil {
+				return &mcp.CallToolResultFor[CheckCommandsResult]{
+					Content: []mcp.Content{&mcp.TextContent{Text: "Configured command not found: " + name}},
+					IsError: true,
+				}, nil
+			}
+			commands = []config.Command{*cmd}
+		}
+
+		// Commands fixed since they were disabled get their tools back
+		disabled := s.disabledCommands()
+		for _, cmd := range commands {
+			if disabled[cmd.Name] && cmdcheck.Check(cmd).OK {
+				if err := s.registerConfigCommand(cmd); err != nil {
+					s.logger.WithError(err).Error("failed to register configured command", "command", cmd.Name)
+				}
+			}
+		}
+
+		result := CheckCommandsResult{Results: cmdcheck.CheckAll(commands)}
+		disabled = s.disabledCommands()
+		var b strings.Builder
+		for _, r := range result.Results {
+			if r.OK {
+				fmt.Fprintf(&b, "ok       %s (%s)\n", r.Command, r.Path)
+				continue
+			}
+			result.Broken++
+			state := "broken  "
+			if disabled[r.Command] {
+				state = "disabled"
+				result.Disabled = append(result.Disabled, r.Command)
+			}
+			fmt.Fprintf(&b, "%s %s\n", state, r.Command)
+			for _, p := range r.Problems {
+				fmt.Fprintf(&b, "  - %s", p.Message)
+				if p.Hint != "" {
+					fmt.Fprintf(&b, " (%s)", p.Hint)
+				}
+				b.WriteByte('\n')
+			}
+		}
+		fmt.Fprintf(&b, "%d of %d configured commands cannot run\n", result.Broken, len(result.Results))
+
+		return &mcp.CallToolResultFor[CheckCommandsResult]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: b.String()}},
+			StructuredContent: result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered command check tool")
+
+	return nil
+}
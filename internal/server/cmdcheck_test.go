@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_checkCommands(t *testing.T) {
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "project")
+	cfg := config.Default()
+	cfg.CommandChecks.DisableBroken = true
+	cfg.Commands = []config.Command{
+		{Name: "works", Description: "Works", Command: "go"},
+		{Name: "later", Description: "Fixed later", Command: "go", WorkDir: workDir},
+		{Name: "missing", Description: "Missing", Command: "no-such-binary-xyz"},
+	}
+	_, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	toolNames := func() []string {
+		res, err := cs.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListTools() error = %v", err)
+		}
+		var names []string
+		for _, tool := range res.Tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+	if names := toolNames(); !slices.Contains(names, "works") || slices.Contains(names, "later") || slices.Contains(names, "missing") {
+		t.Fatalf("tools = %v, want works but not the broken commands", names)
+	}
+
+	// Fixed commands are registered again by check_commands
+	if err := os.Mkdir(workDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "check_commands"})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("check_commands failed: %v", res.Content)
+	}
+	result, ok := res.StructuredContent.(map[string]any)
+	if !ok || result["broken"] != float64(1) {
+		t.Errorf("expected one broken command, got %v", res.StructuredContent)
+	}
+	if names := toolNames(); !slices.Contains(names, "later") || slices.Contains(names, "missing") {
+		t.Errorf("tools = %v, want later registered again", names)
+	}
+}
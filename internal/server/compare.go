@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/diff"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CompareExecutionsParams represents parameters for comparing executions.
+type CompareExecutionsParams struct {
+	BaseID   string `json:"base_id"`
+	TargetID string `json:"target_id"`
+	Context  *int   `json:"context,omitempty"`   // Unchanged lines around changes
+	MaxLines int    `json:"max_lines,omitempty"` // Diff lines returned per stream
+}
+
+// Defaults of the comparison tool.
+const (
+	defaultDiffContext  = 3
+	defaultDiffMaxLines = 200
+)
+
+// compareExecutions compares two execution records.
+func compareExecutions(base, target types.ExecutionRecord, contextLines, maxLines int) types.ExecutionComparison {
+	c := types.ExecutionComparison{
+		BaseID:   base.ID,
+		TargetID: target.ID,
+		Changes:  []types.FieldChange{},
+	}
+	baseFields, targetFields := recordFields(base), recordFields(target)
+	for i, field := range baseFields {
+		if field.value != targetFields[i].value {
+			c.Changes = append(c.Changes, types.FieldChange{Field: field.name, Base: field.value, Target: targetFields[i].value})
+		}
+	}
+
+	var baseResult, targetResult types.CommandExecutionResult
+	if base.Result != nil {
+		baseResult = *base.Result
+	}
+	if target.Result != nil {
+		targetResult = *target.Result
+	}
+	c.Stdout = diff.Lines(baseResult.Stdout, targetResult.Stdout, contextLines, maxLines)
+	c.Stderr = diff.Lines(baseResult.Stderr, targetResult.Stderr, contextLines, maxLines)
+	return c
+}
+
+// recordField is a metadata field of an execution record, as text.
+type recordField struct {
+	name, value string
+}
+
+// recordFields returns the metadata of an execution record compared by
+// compare_executions, in a fixed order.
+func recordFields(rec types.ExecutionRecord) []recordField {
+	fields := []recordField{
+		{"tool", rec.Tool},
+		{"command", rec.Request.Command},
+		{"args", strings.Join(rec.Request.Args, " ")},
+		{"workdir", rec.Request.WorkDir},
+		{"decision", rec.Decision},
+		{"error", rec.Error},
+	}
+	if rec.Result == nil {
+		return append(fields, recordField{"exit_code", ""}, recordField{"timed_out", ""}, recordField{"duration", ""},
+			recordField{"config_revision", ""}, recordField{"binary_path", ""}, recordField{"binary_version", ""})
+	}
+	fields = append(fields,
+		recordField{"exit_code", strconv.Itoa(rec.Result.ExitCode)},
+		recordField{"timed_out", strconv.FormatBool(rec.Result.TimedOut)},
+		recordField{"duration", rec.Result.Duration.String()},
+	)
+	p := rec.Result.Provenance
+	if p == nil {
+		p = &types.Provenance{}
+	}
+	return append(fields,
+		recordField{"config_revision", p.ConfigRevision},
+		recordField{"binary_path", p.BinaryPath},
+		recordField{"binary_version", p.BinaryVersion},
+	)
+}
+
+// formatComparison renders a comparison as text.
+func formatComparison(c *types.ExecutionComparison) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Comparing %s with %s\n", c.BaseID, c.TargetID)
+	for _, change := range c.Changes {
+		fmt.Fprintf(&b, "%s: %q -> %q\n", change.Field, change.Base, change.Target)
+	}
+	for _, stream := range []struct {
+		name string
+		diff *types.OutputDiff
+	}{{"stdout", &c.Stdout}, {"stderr", &c.Stderr}} {
+		if stream.diff.Identical {
+			fmt.Fprintf(&b, "%s: identical (%d lines)\n", stream.name, stream.diff.Unchanged)
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %d added, %d removed\n", stream.name, stream.diff.Added, stream.diff.Removed)
+		b.WriteString(diff.Unified(*stream.diff))
+		if stream.diff.Truncated {
+			b.WriteString("... (more changes not shown)\n")
+		}
+	}
+	return b.String()
+}
+
+// registerCompareTool registers the execution comparison tool.
+func (s *Server) registerCompareTool() error {
+	tool := &mcp.Tool{
+		Name:        "compare_executions",
+		Description: "Compare two recorded executions by their history_id: returns the metadata that changed (command, args, exit code, duration, ...) and a line-based diff of their stdout and stderr, without resending both outputs. Useful to check what changed after a fix.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[CompareExecutionsParams]) (*mcp.CallToolResultFor[types.ExecutionComparison], error) {
+		args := params.Arguments
+		records := make([]types.ExecutionRecord, 2)
+		for i, id := range []string{args.BaseID, args.TargetID} {
+			rec, ok := s.history.Get(id)
+			if !ok {
+				return &mcp.CallToolResultFor[types.ExecutionComparison]{
+					Content: []mcp.Content{&mcp.TextContent{Text: s.msg.Sprintf("Unknown execution: %s", id)}},
+					IsError: true,
+				}, nil
+			}
+			records[i] = rec
+		}
+
+		contextLines := defaultDiffContext
+		if args.Context != nil && *args.Context >= 0 {
+			contextLines = *args.Context
+		}
+		maxLines := args.MaxLines
+		if maxLines <= 0 {
+			maxLines = defaultDiffMaxLines
+		}
+
+		result := compareExecutions(records[0], records[1], contextLines, maxLines)
+		return &mcp.CallToolResultFor[types.ExecutionComparison]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatComparison(&result)}},
+			StructuredContent: result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered compare tool")
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_compareExecutions(t *testing.T) {
+	cfg := config.Default()
+	_, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	run := func(args ...string) string {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "execute_command", Arguments: map[string]any{"command": "echo", "args": args}})
+		if err != nil || res.IsError {
+			t.Fatalf("execute_command = %v, %v", res, err)
+		}
+		result, _ := res.StructuredContent.(map[string]any)
+		id, _ := result["history_id"].(string)
+		if id == "" {
+			t.Fatalf("no history_id in %v", res.StructuredContent)
+		}
+		return id
+	}
+	compare := func(base, target string) (*mcp.CallToolResult, types.ExecutionComparison) {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "compare_executions", Arguments: map[string]any{"base_id": base, "target_id": target}})
+		if err != nil {
+			t.Fatalf("compare_executions error = %v", err)
+		}
+		var c types.ExecutionComparison
+		if data, err := json.Marshal(res.StructuredContent); err == nil {
+			json.Unmarshal(data, &c)
+		}
+		return res, c
+	}
+
+	first, second := run("hello"), run("world")
+	res, c := compare(first, second)
+	if res.IsError {
+		t.Fatalf("compare_executions = %v", res.Content)
+	}
+	if c.Stdout.Identical || c.Stdout.Added != 1 || c.Stdout.Removed != 1 || !c.Stderr.Identical {
+		t.Errorf("stdout = %+v, stderr = %+v", c.Stdout, c.Stderr)
+	}
+	args := false
+	for _, change := range c.Changes {
+		if change.Field == "args" && change.Base == "hello" && change.Target == "world" {
+			args = true
+		}
+		if change.Field == "command" {
+			t.Errorf("unexpected command change: %+v", change)
+		}
+	}
+	if !args {
+		t.Errorf("changes = %+v, want the args", c.Changes)
+	}
+
+	if res, _ := compare(first, "missing"); !res.IsError {
+		t.Error("expected an error result for an unknown execution")
+	}
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxCompletions is the most values a completion returns, the limit MCP
+// sets.
+const maxCompletions = 100
+
+// gitCompletionTimeout bounds the git command listing branches for a
+// completion.
+const gitCompletionTimeout = 2 * time.Second
+
+// registerCommandPrompt exposes a configured command as a prompt under
+// server.command_prompts. MCP only completes the arguments of prompts and
+// resource templates, not of tools, so the prompt is the command's
+// template: its workdir and args can be completed, and getting it returns
+// a message asking to call the command's tool with them.
+func (s *Server) registerCommandPrompt(cmd config.Command) {
+	if !s.config.Server.CommandPrompts {
+		return
+	}
+
+	name := s.config.Server.ToolPrefix + cmd.Name
+	prompt := &mcp.Prompt{
+		Name:        name,
+		Title:       cmd.DisplayName,
+		Description: s.describeCommand(cmd),
+		Arguments: []*mcp.PromptArgument{
+			{Name: "workdir", Description: s.msg.T("Working directory to run the command in")},
+		},
+	}
+	if cmd.AllowArgs {
+		prompt.Arguments = append(prompt.Arguments, &mcp.PromptArgument{
+			Name:        "args",
+			Description: s.msg.T("Arguments appended to the command, separated by spaces"),
+		})
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.GetPromptParams) (*mcp.GetPromptResult, error) {
+		args := ConfigCommandParams{WorkDir: params.Arguments["workdir"]}
+		if cmd.AllowArgs {
+			args.Args = strings.Fields(params.Arguments["args"])
+		}
+		data, err := json.Marshal(args)
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.GetPromptResult{
+			Description: prompt.Description,
+			Messages: []*mcp.PromptMessage{{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: s.msg.Sprintf("Call the %s tool with the arguments %s.", name, data)},
+			}},
+		}, nil
+	}
+
+	s.mcpServer.AddPrompt(prompt, handler)
+}
+
+// completionMiddleware answers completion requests with complete.
+func (s *Server) completionMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, ss *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		p, ok := params.(*mcp.CompleteParams)
+		if method != "completion/complete" || !ok {
+			return next(ctx, ss, method, params)
+		}
+		return s.complete(ctx, p), nil
+	}
+}
+
+// complete suggests values for an argument of a command prompt: the
+// directories of a workdir, and for args the command's arg_values and, for
+// git commands, the branches of the repository. Other references get no
+// suggestions.
+func (s *Server) complete(ctx context.Context, params *mcp.CompleteParams) *mcp.CompleteResult {
+	var values []string
+	if params.Ref != nil && params.Ref.Type == "ref/prompt" && s.config.Server.CommandPrompts {
+		if cmd := s.findCommand(params.Ref.Name); cmd != nil {
+			var workDir string
+			if params.Context != nil {
+				workDir = params.Context.Arguments["workdir"]
+			}
+			switch params.Argument.Name {
+			case "workdir":
+				values = s.completeWorkDir(cmd, params.Argument.Value)
+			case "args":
+				if cmd.AllowArgs {
+					values = s.completeArgs(ctx, cmd, workDir, params.Argument.Value)
+				}
+			}
+		}
+	}
+
+	result := &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: []string{}, Total: len(values)}}
+	if len(values) > maxCompletions {
+		values = values[:maxCompletions]
+		result.Completion.HasMore = true
+	}
+	result.Completion.Values = append(result.Completion.Values, values...)
+	return result
+}
+
+// completeWorkDir suggests the command's workdir, the allowed paths and
+// the subdirectories of the directory typed so far that start with value.
+// Only directories that are allowed, or lead to an allowed path, are
+// suggested.
+func (s *Server) completeWorkDir(cmd *config.Command, value string) []string {
+	var candidates []string
+	if cmd.WorkDir != "" {
+		candidates = append(candidates, cmd.WorkDir)
+	}
+	candidates = append(candidates, s.config.Security.AllowedPaths...)
+	if dir, base := filepath.Split(value); dir != "" {
+		if entries, err := os.ReadDir(dir); err == nil {
+			for _, entry := range entries {
+				// Hidden directories only when asked for
+				if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") && !strings.HasPrefix(base, ".") {
+					continue
+				}
+				candidates = append(candidates, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	var values []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, value) && !slices.Contains(values, candidate) && s.leadsToAllowedPath(candidate) {
+			values = append(values, candidate)
+		}
+	}
+	return values
+}
+
+// leadsToAllowedPath reports whether a directory is allowed, or contains
+// an allowed path.
+func (s *Server) leadsToAllowedPath(dir string) bool {
+	if s.config.MatchDeniedPath(dir) != "" {
+		return false
+	}
+	if s.config.IsPathAllowed(dir) {
+		return true
+	}
+	for _, allowed := range s.config.Security.AllowedPaths {
+		if rel, err := filepath.Rel(dir, allowed); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// completeArgs suggests values for the last of the args typed so far,
+// keeping the ones before it.
+func (s *Server) completeArgs(ctx context.Context, cmd *config.Command, workDir, value string) []string {
+	i := strings.LastIndexAny(value, " \t") + 1
+	head, word := value[:i], value[i:]
+
+	candidates := slices.Clone(cmd.ArgValues)
+	if strings.TrimSuffix(strings.ToLower(filepath.Base(cmd.Command)), ".exe") == "git" {
+		candidates = append(candidates, s.gitBranches(ctx, cmd, workDir)...)
+	}
+
+	var values []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) && !slices.Contains(values, head+candidate) {
+			values = append(values, head+candidate)
+		}
+	}
+	return values
+}
+
+// gitBranches returns the local branches of the repository a git command
+// runs in, or nil if it is not in an allowed repository.
+func (s *Server) gitBranches(ctx context.Context, cmd *config.Command, workDir string) []string {
+	dir := workDir
+	if dir == "" || cmd.WorkDirMode == "" && cmd.WorkDir != "" {
+		dir = cmd.WorkDir
+	}
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil
+		}
+		dir = wd
+	}
+	if !filepath.IsAbs(dir) || !s.config.IsPathAllowed(dir) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, gitCompletionTimeout)
+	defer cancel()
+	git := exec.CommandContext(ctx, "git", "for-each-ref", "--format=%(refname:short)", "refs/heads")
+	git.Dir = dir
+	out, err := git.Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(out))
+}
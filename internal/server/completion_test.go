@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_commandPrompts(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"api", "app", ".cache", "docs"} {
+		if err := os.Mkdir(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Server.CommandPrompts = true
+	cfg.Security.AllowedPaths = []string{root}
+	cfg.Commands = []config.Command{
+		{Name: "build", Command: "echo", AllowArgs: true, ArgValues: []string{"all", "api", "app"}},
+		{Name: "status", Command: "echo", Args: []string{"status"}},
+	}
+	srv, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	prompts, err := cs.ListPrompts(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListPrompts() error = %v", err)
+	}
+	var names []string
+	for _, p := range prompts.Prompts {
+		names = append(names, p.Name)
+		if p.Name == "status" && len(p.Arguments) != 1 {
+			t.Errorf("status arguments = %d, want only workdir as it takes no args", len(p.Arguments))
+		}
+	}
+	if !slices.Equal(names, []string{"build", "status"}) {
+		t.Errorf("prompts = %v, want build and status", names)
+	}
+
+	got, err := cs.GetPrompt(ctx, &mcp.GetPromptParams{Name: "build", Arguments: map[string]string{"workdir": root, "args": "api  -v"}})
+	if err != nil {
+		t.Fatalf("GetPrompt() error = %v", err)
+	}
+	if text := got.Messages[0].Content.(*mcp.TextContent).Text; !strings.Contains(text, `"args":["api","-v"]`) {
+		t.Errorf("prompt message = %q", text)
+	}
+
+	// The client of this SDK version cannot send completion requests, so
+	// they are answered directly
+	complete := func(prompt, arg, value string) []string {
+		t.Helper()
+		res := srv.complete(ctx, &mcp.CompleteParams{
+			Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: prompt},
+			Argument: mcp.CompleteParamsArgument{Name: arg, Value: value},
+		})
+		return res.Completion.Values
+	}
+
+	sep := string(filepath.Separator)
+	if values := complete("build", "workdir", root+sep+"a"); !slices.Equal(values, []string{filepath.Join(root, "api"), filepath.Join(root, "app")}) {
+		t.Errorf("workdir completions = %v", values)
+	}
+	if values := complete("build", "workdir", root+sep+"."); !slices.Equal(values, []string{filepath.Join(root, ".cache")}) {
+		t.Errorf("hidden workdir completions = %v", values)
+	}
+	// Directories outside allowed_paths are not suggested, except those
+	// leading to them
+	if values := complete("build", "workdir", filepath.Dir(root)+sep); !slices.Contains(values, root) || len(values) != 1 {
+		t.Errorf("parent workdir completions = %v, want only %s", values, root)
+	}
+
+	if values := complete("build", "args", "-v a"); !slices.Equal(values, []string{"-v all", "-v api", "-v app"}) {
+		t.Errorf("args completions = %v", values)
+	}
+	if values := complete("status", "args", ""); len(values) != 0 {
+		t.Errorf("args completions of a command without allow_args = %v", values)
+	}
+}
+
+func TestServer_completeGitBranches(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"branch", "feature/login"},
+		{"branch", "fix-build"},
+	} {
+		git := exec.Command("git", args...)
+		git.Dir = repo
+		if out, err := git.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Server.CommandPrompts = true
+	srv, err := New(Options{Config: cfg})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	cmd := &config.Command{Name: "checkout", Command: "git", Args: []string{"checkout"}, AllowArgs: true}
+	values := srv.completeArgs(context.Background(), cmd, repo, "f")
+	if !slices.Equal(values, []string{"feature/login", "fix-build"}) {
+		t.Errorf("completeArgs() = %v, want the branches starting with f", values)
+	}
+
+	// Branches are not listed outside allowed_paths
+	cfg.Security.AllowedPaths = []string{t.TempDir()}
+	if values := srv.completeArgs(context.Background(), cmd, repo, "f"); len(values) != 0 {
+		t.Errorf("completeArgs() = %v outside allowed_paths", values)
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Encodings of compressed results, in order of preference. Clients offer
+// them as experimental capabilities named "compression/" + encoding.
+const (
+	encodingZstd = "zstd"
+	encodingGzip = "gzip"
+)
+
+// compressionCapability prefixes the experimental client capabilities
+// that offer an encoding.
+const compressionCapability = "compression/"
+
+// compressedURI is the URI of the embedded resource a compressed tool
+// result is sent as.
+const compressedURI = "runner://compressed-result"
+
+// zstdEncoder is shared by all sessions; EncodeAll is safe for concurrent
+// use.
+var zstdEncoder = sync.OnceValue(func() *zstd.Encoder {
+	enc, _ := zstd.NewWriter(nil)
+	return enc
+})
+
+// negotiateEncoding returns the preferred encoding the client offers, or
+// "" when it offers none.
+func negotiateEncoding(params *mcp.InitializeParams) string {
+	if params.Capabilities == nil {
+		return ""
+	}
+	for _, enc := range []string{encodingZstd, encodingGzip} {
+		if _, ok := params.Capabilities.Experimental[compressionCapability+enc]; ok {
+			return enc
+		}
+	}
+	return ""
+}
+
+// compressMiddleware compresses tool results and resource contents over
+// server.compress_results bytes for sessions whose client offered an
+// encoding. The JSON of the result is compressed and sent as a blob, with
+// the encoding and the original size in _meta.compression, and clients
+// decompress it to get the result back. Results that would not get
+// smaller are sent as they are.
+func (s *Server) compressMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, ss *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		result, err := next(ctx, ss, method, params)
+		threshold := int64(s.config.Server.CompressResults)
+		if err != nil || threshold == 0 {
+			return result, err
+		}
+		v, ok := s.sessions.Load(ss)
+		if !ok || v.(*sessionInfo).encoding == "" {
+			return result, err
+		}
+		encoding := v.(*sessionInfo).encoding
+
+		switch res := result.(type) {
+		case *mcp.CallToolResult:
+			if res == nil {
+				return result, err
+			}
+			data, meta, ok := s.compressResult(res, encoding, threshold)
+			if !ok {
+				return result, err
+			}
+			return &mcp.CallToolResult{
+				Meta: meta,
+				Content: []mcp.Content{&mcp.EmbeddedResource{
+					Resource: &mcp.ResourceContents{URI: compressedURI, MIMEType: "application/json", Blob: data},
+				}},
+				IsError: res.IsError,
+			}, nil
+		case *mcp.ReadResourceResult:
+			p, isRead := params.(*mcp.ReadResourceParams)
+			if res == nil || !isRead {
+				return result, err
+			}
+			data, meta, ok := s.compressResult(res, encoding, threshold)
+			if !ok {
+				return result, err
+			}
+			return &mcp.ReadResourceResult{
+				Meta:     meta,
+				Contents: []*mcp.ResourceContents{{URI: p.URI, MIMEType: "application/json", Blob: data}},
+			}, nil
+		}
+		return result, err
+	}
+}
+
+// compressResult compresses the JSON of a result over threshold bytes. It
+// reports false when the result is smaller than that, or when compressing
+// it, encoded as base64 as blobs are, would not save anything.
+func (s *Server) compressResult(res mcp.Result, encoding string, threshold int64) ([]byte, mcp.Meta, bool) {
+	data, err := json.Marshal(res)
+	if err != nil || int64(len(data)) <= threshold {
+		return nil, nil, false
+	}
+	compressed, err := compress(encoding, data)
+	if err != nil {
+		s.logger.Warn("failed to compress result", "encoding", encoding, "error", err)
+		return nil, nil, false
+	}
+	if (len(compressed)+2)/3*4 >= len(data) {
+		return nil, nil, false
+	}
+	meta := mcp.Meta{"compression": map[string]any{"encoding": encoding, "size": len(data)}}
+	return compressed, meta, true
+}
+
+// compress compresses data with an encoding.
+func compress(encoding string, data []byte) ([]byte, error) {
+	if encoding == encodingZstd {
+		return zstdEncoder().EncodeAll(data, nil), nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// offerEncodings makes a client offer encodings of compressed results in
+// its initialize request.
+func offerEncodings(client *mcp.Client, encodings ...string) {
+	client.AddSendingMiddleware(func(next mcp.MethodHandler[*mcp.ClientSession]) mcp.MethodHandler[*mcp.ClientSession] {
+		return func(ctx context.Context, cs *mcp.ClientSession, method string, params mcp.Params) (mcp.Result, error) {
+			if p, ok := params.(*mcp.InitializeParams); ok {
+				p.Capabilities.Experimental = map[string]struct{}{}
+				for _, enc := range encodings {
+					p.Capabilities.Experimental[compressionCapability+enc] = struct{}{}
+				}
+			}
+			return next(ctx, cs, method, params)
+		}
+	})
+}
+
+// decompress returns the JSON of a compressed result.
+func decompress(t *testing.T, meta mcp.Meta, blob []byte) []byte {
+	t.Helper()
+	info, _ := meta["compression"].(map[string]any)
+	var data []byte
+	var err error
+	switch info["encoding"] {
+	case encodingZstd:
+		var dec *zstd.Decoder
+		dec, err = zstd.NewReader(nil)
+		if err == nil {
+			data, err = dec.DecodeAll(blob, nil)
+			dec.Close()
+		}
+	case encodingGzip:
+		var zr *gzip.Reader
+		zr, err = gzip.NewReader(bytes.NewReader(blob))
+		if err == nil {
+			data, err = io.ReadAll(zr)
+		}
+	default:
+		t.Fatalf("unexpected compression metadata: %v", meta)
+	}
+	if err != nil {
+		t.Fatalf("decompress: %v", err)
+	}
+	if size, _ := info["size"].(float64); int(size) != len(data) {
+		t.Errorf("size = %v, want %d", info["size"], len(data))
+	}
+	return data
+}
+
+func TestServer_compressResults(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.CompressResults = 1 << 10
+
+	connect := func(t *testing.T, encodings ...string) *mcp.ClientSession {
+		t.Helper()
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+		offerEncodings(client, encodings...)
+		return connectClient(t, newTestServer(t, cfg), client)
+	}
+	seq := func(t *testing.T, cs *mcp.ClientSession, n string) *mcp.CallToolResult {
+		t.Helper()
+		res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "execute_command", Arguments: map[string]any{"command": "seq", "args": []string{"1", n}}})
+		if err != nil || res.IsError {
+			t.Fatalf("execute_command = %v, %v", res, err)
+		}
+		return res
+	}
+
+	for _, enc := range []string{encodingZstd, encodingGzip} {
+		t.Run(enc, func(t *testing.T) {
+			cs := connect(t, encodingGzip, enc)
+
+			// Large results are compressed with the preferred encoding
+			res := seq(t, cs, "2000")
+			if len(res.Content) != 1 {
+				t.Fatalf("content = %v", res.Content)
+			}
+			embedded, ok := res.Content[0].(*mcp.EmbeddedResource)
+			if !ok || embedded.Resource.URI != compressedURI {
+				t.Fatalf("content = %#v, want the compressed result", res.Content[0])
+			}
+			if got := res.Meta["compression"].(map[string]any)["encoding"]; got != enc {
+				t.Errorf("encoding = %v, want %s", got, enc)
+			}
+			var original mcp.CallToolResult
+			if err := json.Unmarshal(decompress(t, res.Meta, embedded.Resource.Blob), &original); err != nil {
+				t.Fatal(err)
+			}
+			result, _ := original.StructuredContent.(map[string]any)
+			if stdout, _ := result["stdout"].(string); !strings.HasPrefix(stdout, "1\n2\n") || !strings.HasSuffix(stdout, "2000\n") {
+				t.Errorf("stdout = %.40q..., want the whole output", stdout)
+			}
+
+			// Small results are sent as they are
+			res = seq(t, cs, "3")
+			if res.Meta["compression"] != nil {
+				t.Errorf("small result compressed: %v", res.Meta)
+			}
+
+			// Resource contents are compressed too
+			read, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "runner://config-summary"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(read.Contents) != 1 || read.Contents[0].URI != "runner://config-summary" {
+				t.Fatalf("contents = %v", read.Contents)
+			}
+			var contents mcp.ReadResourceResult
+			if err := json.Unmarshal(decompress(t, read.Meta, read.Contents[0].Blob), &contents); err != nil {
+				t.Fatal(err)
+			}
+			if len(contents.Contents) != 1 || !strings.Contains(contents.Contents[0].Text, `"tools"`) {
+				t.Errorf("contents = %v", contents.Contents)
+			}
+		})
+	}
+
+	// Clients that offer no encoding get results as they are
+	res := seq(t, connect(t), "2000")
+	if res.Meta["compression"] != nil {
+		t.Errorf("result compressed for a client without encodings: %v", res.Meta)
+	}
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/container"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ListContainersParams represents parameters for listing containers.
+type ListContainersParams struct {
+	All bool `json:"all,omitempty"` // Include stopped containers
+}
+
+// ContainerLogsParams represents parameters for reading container logs.
+type ContainerLogsParams struct {
+	Container  string `json:"container"`
+	Tail       int    `json:"tail,omitempty"`
+	Since      string `json:"since,omitempty"`
+	Timestamps bool   `json:"timestamps,omitempty"`
+}
+
+// ExecInContainerParams represents parameters for running a command in a
+// container.
+type ExecInContainerParams struct {
+	Container string   `json:"container"`
+	Command   []string `json:"command"`
+	WorkDir   string   `json:"workdir,omitempty"`
+	Env       []string `json:"env,omitempty"`
+	Timeout   string   `json:"timeout,omitempty"`
+
+	// ApprovalID runs a command held for approval under an approved
+	// request
+	ApprovalID string `json:"approval_id,omitempty"`
+}
+
+// ContainerList lists containers.
+type ContainerList struct {
+	Containers []container.Info `json:"containers"`
+}
+
+// registerContainerTools registers the Docker container tools unless
+// they are disabled.
+func (s *Server) registerContainerTools() error {
+	if s.containers == nil {
+		s.logger.Debug("container tools disabled")
+		return nil
+	}
+
+	s.registerListContainersTool()
+	s.registerContainerLogsTool()
+	s.registerExecInContainerTool()
+
+	s.logger.Debug("registered container tools")
+
+	return nil
+}
+
+func (s *Server) registerListContainersTool() {
+	tool := &mcp.Tool{
+		Name:        "list_containers",
+		Description: "List the Docker containers the configuration allows tools to touch, with ID, name, image, state and status. Only running containers are listed unless all is set.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ListContainersParams]) (*mcp.CallToolResultFor[ContainerList], error) {
+		infos, err := s.containers.List(ctx, params.Arguments.All)
+		if err != nil {
+			s.logger.WithError(err).Error("container listing failed")
+			return &mcp.CallToolResultFor[ContainerList]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Container listing failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[ContainerList]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatContainerList(infos)}},
+			StructuredContent: ContainerList{Containers: infos},
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerContainerLogsTool() {
+	tool := &mcp.Tool{
+		Name:        "container_logs",
+		Description: "Read the recent stdout and stderr of an allowed Docker container by name or ID: the last tail lines (capped by the configuration), optionally only since a timestamp or duration such as 10m, with timestamps if requested.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ContainerLogsParams]) (*mcp.CallToolResultFor[container.Logs], error) {
+		args := params.Arguments
+
+		logs, err := s.containers.Logs(ctx, container.LogsRequest{
+			Container:  args.Container,
+			Tail:       args.Tail,
+			Since:      args.Since,
+			Timestamps: args.Timestamps,
+		})
+		if err != nil {
+			s.logger.WithError(err).Debug("container logs failed", "container", args.Container)
+			return &mcp.CallToolResultFor[container.Logs]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Reading container logs failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		text := fmt.Sprintf("Logs of %s (last %d lines)\nStdout: %s\nStderr: %s", logs.Container, logs.Tail, logs.Stdout, logs.Stderr)
+		if logs.Truncated {
+			text += "\nOutput was truncated"
+		}
+		return &mcp.CallToolResultFor[container.Logs]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+			StructuredContent: *logs,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerExecInContainerTool() {
+	tool := &mcp.Tool{
+		Name:        "exec_in_container",
+		Description: "Run a command in an allowed running Docker container and wait for it, returning stdout, stderr and the exit code. command is the program and its arguments, run without a shell and checked against the security policy like execute_command, including approvals (pass approval_id once approved); it counts as mutating for the operator switches. Only available when the configuration allows exec.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ExecInContainerParams]) (*mcp.CallToolResultFor[types.CommandExecutionResult], error) {
+		args := params.Arguments
+
+		req := types.CommandExecutionRequest{WorkDir: args.WorkDir, Env: args.Env, Timeout: args.Timeout, ApprovalID: args.ApprovalID}
+		if len(args.Command) > 0 {
+			req.Command, req.Args = args.Command[0], args.Command[1:]
+		}
+
+		// The command goes through the whole policy, as if run by
+		// execute_command; its workdir is a path in the container, not
+		// one the path policy covers. It can change anything in the
+		// container, so it counts as mutating for the switches.
+		admitted := req
+		admitted.WorkDir = ""
+		_, err := s.executor.Admit(ctx, &admitted)
+		if err == nil {
+			err = s.executor.AdmitInput(req.Command)
+		}
+		var result *types.CommandExecutionResult
+		if err == nil {
+			result, err = s.containers.Exec(ctx, container.ExecRequest{
+				Container: args.Container,
+				Command:   args.Command,
+				WorkDir:   args.WorkDir,
+				Env:       args.Env,
+				Timeout:   args.Timeout,
+			})
+		}
+		result = s.recordExecution(ctx, "exec_in_container", req, result, err)
+		if err != nil {
+			s.logger.WithError(err).Error("container exec failed", "container", args.Container)
+			structured := types.CommandExecutionResult{ExitCode: -1, ErrorMessage: err.Error(), StartTime: time.Now(), EndTime: time.Now()}
+			if result != nil {
+				structured = *result
+				structured.ErrorMessage = err.Error()
+			}
+			return &mcp.CallToolResultFor[types.CommandExecutionResult]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Container exec failed: %s", err.Error())},
+				},
+				StructuredContent: structured,
+				IsError:           true,
+			}, nil
+		}
+
+		text := fmt.Sprintf("Command executed in %s.\nStdout: %s\nStderr: %s\nExit Code: %d",
+			args.Container, result.Stdout, result.Stderr, result.ExitCode)
+		return &mcp.CallToolResultFor[types.CommandExecutionResult]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+			StructuredContent: *result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+// formatContainerList renders containers one per line.
+func formatContainerList(infos []container.Info) string {
+	if len(infos) == 0 {
+		return "No allowed containers"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d containers", len(infos))
+	for _, c := range infos {
+		fmt.Fprintf(&b, "\n  %s %s (%s) %s", c.ID, c.Name, c.Image, c.Status)
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_execInContainerPolicy(t *testing.T) {
+	cfg := config.Default()
+	cfg.Containers.Enabled = true
+	cfg.Containers.AllowExec = true
+	cfg.Containers.AllowedContainers = []string{"dev-*"}
+	// No Docker daemon is needed: every call below is refused before one
+	// is reached
+	cfg.Containers.Host = "unix://" + filepath.Join(t.TempDir(), "docker.sock")
+	cfg.Security.AllowedCommands = []string{"ls"}
+	srv, cs := newTestSession(t, cfg)
+	ctx := context.Background()
+
+	exec := func(command ...string) string {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "exec_in_container", Arguments: map[string]any{"container": "dev-api", "command": command}})
+		if err != nil {
+			t.Fatalf("exec_in_container error = %v", err)
+		}
+		if !res.IsError {
+			t.Fatalf("exec_in_container %v succeeded, want it refused", command)
+		}
+		return res.Content[0].(*mcp.TextContent).Text
+	}
+
+	// Commands outside allowed_commands are refused like execute_command's
+	if text := exec("cat", "/etc/passwd"); !strings.Contains(text, "not allowed") {
+		t.Errorf("exec_in_container of a command not allowed = %q", text)
+	}
+
+	// So are all commands while an operator blocks mutating ones
+	if err := srv.executor.SetSwitch(executor.SwitchBlockMutating, true); err != nil {
+		t.Fatal(err)
+	}
+	if text := exec("ls"); !strings.Contains(text, "mutating") {
+		t.Errorf("exec_in_container with mutating commands blocked = %q", text)
+	}
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/approval"
+	"github.com/mjmorales/simple-mcp-runner/internal/control"
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/internal/history"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// controlBackend is the server as the control API operates it.
+type controlBackend struct {
+	s *Server
+}
+
+// Sessions returns the connected client sessions.
+func (b controlBackend) Sessions() []control.Session {
+	var sessions []control.Session
+	for ss := range b.s.mcpServer.Sessions() {
+		v, ok := b.s.sessions.Load(ss)
+		if !ok {
+			// Not initialized yet
+			continue
+		}
+		info := v.(*sessionInfo)
+		info.mu.Lock()
+		groups := slices.Clone(info.groups)
+		info.mu.Unlock()
+		sessions = append(sessions, control.Session{
+			ID:            info.id,
+			Client:        info.clientName,
+			ClientVersion: info.clientVersion,
+			Started:       info.started,
+			ToolGroups:    groups,
+		})
+	}
+	return sessions
+}
+
+// ActiveCommands returns the running commands.
+func (b controlBackend) ActiveCommands() []executor.ActiveCommand {
+	return b.s.executor.Active()
+}
+
+// KillCommand kills a running command.
+func (b controlBackend) KillCommand(id uint64) error {
+	if err := b.s.executor.Kill(id); err != nil {
+		return err
+	}
+	b.s.emit(types.EventCommandKilled, "command #"+strconv.FormatUint(id, 10)+" killed by an operator", map[string]any{"id": id})
+	return nil
+}
+
+// Switches returns the operator switches.
+func (b controlBackend) Switches() map[string]bool {
+	return b.s.executor.SwitchStates()
+}
+
+// SetSwitch turns an operator switch on or off.
+func (b controlBackend) SetSwitch(name string, on bool) error {
+	if err := b.s.executor.SetSwitch(name, on); err != nil {
+		return err
+	}
+	b.s.emit(types.EventSwitchChanged, "switch "+name+" turned "+onOff(on), map[string]any{"switch": name, "on": on})
+	return nil
+}
+
+// Drain drains the server in the background, giving running commands
+// until the maximum timeout to finish.
+func (b controlBackend) Drain() {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), b.s.config.Execution.MaxTimeout.Std()+time.Minute)
+		defer cancel()
+		if err := b.s.Drain(ctx); err != nil {
+			b.s.logger.WithError(err).Warn("drain did not finish")
+		}
+	}()
+}
+
+// Stats describe the server.
+func (b controlBackend) Stats() control.Stats {
+	b.s.mu.RLock()
+	state, since := b.s.state, b.s.since
+	b.s.mu.RUnlock()
+
+	stats := control.Stats{
+		State:          state.String(),
+		Sessions:       len(b.Sessions()),
+		ActiveCommands: b.s.executor.GetActiveCount(),
+		QueuedCommands: b.s.executor.QueuedCount(),
+		Crashes:        b.s.crashes.Load(),
+		ReturnedBytes:  b.s.returned.Load(),
+		Executions:     executor.Counters(),
+		Switches:       b.s.executor.SwitchStates(),
+	}
+	if state == StateRunning || state == StateStopping {
+		stats.Uptime = time.Since(since).Round(time.Second).String()
+	}
+	return stats
+}
+
+// History returns the most recent executions with a policy decision, or
+// all of them when decision is empty.
+func (b controlBackend) History(decision string, limit int) []types.ExecutionRecord {
+	return b.s.history.List(history.Filter{Decision: decision, Limit: limit})
+}
+
+// ConfigSummary describes the configuration.
+func (b controlBackend) ConfigSummary() types.ConfigSummary {
+	return b.s.configSummary()
+}
+
+// ConfigDrift compares the configuration to its file on disk.
+func (b controlBackend) ConfigDrift() (control.Drift, error) {
+	return b.s.configDrift()
+}
+
+// Approvals returns the approval requests, newest first.
+func (b controlBackend) Approvals() ([]*approval.Request, error) {
+	return b.s.executor.Approvals().List()
+}
+
+// DecideApproval approves or rejects a request with the operator key of
+// the user running the server (approvals.key_file), who counts as one
+// operator like with the approvals command.
+func (b controlBackend) DecideApproval(id string, approve bool, comment string) (*approval.Request, error) {
+	key, err := approval.LoadKey(b.s.config)
+	if err != nil {
+		return nil, err
+	}
+	store := b.s.executor.Approvals()
+	decide := store.Reject
+	if approve {
+		decide = store.Approve
+	}
+	req, err := decide(id, key, comment)
+	if err != nil {
+		return nil, err
+	}
+	operator := req.Events[len(req.Events)-1].By
+	b.s.logger.Info("approval request decided from the control API", "id", id, "operator", operator, "approve", approve, "status", req.Status)
+	return req, nil
+}
+
+// Drain stops the server gracefully: new executions are refused, and
+// once the running and queued commands finish, or ctx is done, the server
+// shuts down. Executions are allowed again once it has, so the server can
+// be run again.
+func (s *Server) Drain(ctx context.Context) error {
+	if err := s.executor.SetSwitch(executor.SwitchPaused, true); err != nil {
+		return err
+	}
+	defer s.executor.SetSwitch(executor.SwitchPaused, false)
+	s.emit(types.EventServerDraining, "server draining", map[string]any{
+		"active_commands": s.executor.GetActiveCount(),
+		"queued_commands": s.executor.QueuedCount(),
+	})
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for s.executor.GetActiveCount() > 0 || s.executor.QueuedCount() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			s.logger.Warn("drain timed out, shutting down with commands running",
+				"active_commands", s.executor.GetActiveCount())
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			return s.Shutdown(shutdownCtx)
+		}
+	}
+	return s.Shutdown(ctx)
+}
+
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
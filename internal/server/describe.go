@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// versionTimeout bounds how long a command may take to print its version.
+const versionTimeout = 3 * time.Second
+
+var versionNumber = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// describeCommand returns the description of a configured command with
+// its template variables resolved. If the template cannot be resolved,
+// the description is returned as configured.
+func (s *Server) describeCommand(cmd config.Command) string {
+	if !cmd.IsTemplate() {
+		return cmd.Description
+	}
+	desc, err := cmd.RenderDescription(commandFacts(cmd))
+	if err != nil {
+		s.logger.WithError(err).Warn("failed to render command description", "command", cmd.Name)
+		return cmd.Description
+	}
+	return desc
+}
+
+// commandFacts collects the facts a command description can refer to.
+func commandFacts(cmd config.Command) config.DescriptionFacts {
+	workDir := cmd.WorkDir
+	if workDir == "" {
+		workDir, _ = os.Getwd()
+	}
+	path, err := exec.LookPath(cmd.Command)
+	if err != nil {
+		path = ""
+	}
+	version := sync.OnceValue(func() string {
+		if path == "" {
+			return ""
+		}
+		return commandVersion(path, cmd.VersionArgs)
+	})
+	return config.NewDescriptionFacts(runtime.GOOS, runtime.GOARCH, workDir, path, version)
+}
+
+// commandVersion runs a command to print its version and returns the
+// version number in the output, or the first line if there is none.
+func commandVersion(path string, args []string) string {
+	if len(args) == 0 {
+		args = []string{"--version"}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, args...).Output()
+	if err != nil && len(out) == 0 {
+		return ""
+	}
+	if v := versionNumber.Find(out); v != nil {
+		return string(v)
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func TestServer_describeCommand(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not in PATH")
+	}
+	srv := &Server{logger: logger.Default()}
+	dir := t.TempDir()
+	desc := srv.describeCommand(config.Command{
+		Name:        "test",
+		Description: "Runs tests with go {{.Version}} on {{.OS}} in {{.WorkDir}}",
+		Command:     "go",
+		WorkDir:     dir,
+		VersionArgs: []string{"version"},
+	})
+	if !regexp.MustCompile(`^Runs tests with go \d+\.\d+`).MatchString(desc) {
+		t.Errorf("describeCommand() = %q, want the go version", desc)
+	}
+	if !strings.HasSuffix(desc, " on "+runtime.GOOS+" in "+dir) {
+		t.Errorf("describeCommand() = %q, want the OS and workdir", desc)
+	}
+
+	plain := config.Command{Name: "plain", Description: "Plain {{ text", Command: "go"}
+	if got := srv.describeCommand(plain); got != plain.Description {
+		t.Errorf("describeCommand() = %q, want the description as configured", got)
+	}
+}
@@ -0,0 +1,275 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/dlp"
+	"github.com/mjmorales/simple-mcp-runner/internal/security"
+	"github.com/mjmorales/simple-mcp-runner/internal/transfer"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// dlpMiddleware scans tool results and resources for sensitive data
+// before they reach the client, and logs, redacts or withholds them as
+// security.dlp says.
+func (s *Server) dlpMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, ss *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		result, err := next(ctx, ss, method, params)
+		if err != nil || !s.dlp.Enabled() {
+			return result, err
+		}
+
+		switch res := result.(type) {
+		case *mcp.CallToolResult:
+			p, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+			if !ok || res == nil {
+				return result, err
+			}
+			switch action, detectors := s.checkDLP(ctx, "tool", p.Name, toolResultTexts(res)); action {
+			case config.DLPBlock:
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{Text: s.dlpWithheld(detectors)}},
+					IsError: true,
+				}, nil
+			case config.DLPRedact:
+				s.redactToolResult(res)
+			}
+
+		case *mcp.ReadResourceResult:
+			p, ok := params.(*mcp.ReadResourceParams)
+			if !ok || res == nil {
+				return result, err
+			}
+			var texts []string
+			for _, c := range res.Contents {
+				texts = append(texts, c.Text, string(c.Blob))
+			}
+			switch action, detectors := s.checkDLP(ctx, "resource", p.URI, texts); action {
+			case config.DLPBlock:
+				return nil, apperrors.PermissionError(s.dlpWithheld(detectors), p.URI)
+			case config.DLPRedact:
+				for _, c := range res.Contents {
+					s.redactResource(c)
+				}
+			}
+		}
+		return result, err
+	}
+}
+
+// logToolOutput sends output of a tool outside its result, such as
+// followed lines or watch changes, to the client as a log notification.
+// Like results, it is scanned for sensitive data, counted against the
+// session's output budget and counted in usage stats.
+func (s *Server) logToolOutput(ctx context.Context, ss *mcp.ServerSession, tool, logger string, data any) error {
+	value := jsonValue(data)
+	if s.dlp.Enabled() {
+		switch action, detectors := s.checkDLP(ctx, "tool", tool, jsonTexts(value)); action {
+		case config.DLPBlock:
+			value = map[string]any{"withheld": s.dlpWithheld(detectors)}
+		case config.DLPRedact:
+			value, _ = s.redactJSON(value)
+		}
+	}
+
+	params := &mcp.LoggingMessageParams{Level: "info", Logger: logger, Data: value}
+	s.budgetNotification(ss, params)
+	size := jsonSize(params)
+	s.returned.Add(size)
+	s.usage.ToolOutput(tool, size)
+	return ss.Log(ctx, params)
+}
+
+// checkDLP scans the texts of a result, logs findings and returns the
+// action to take on the result, or "" if there are none, and the
+// detectors with findings.
+func (s *Server) checkDLP(ctx context.Context, kind, name string, texts []string) (string, []string) {
+	var findings []dlp.Finding
+	for _, text := range texts {
+		findings = append(findings, s.dlp.Scan(text)...)
+	}
+	action := dlp.Strictest(findings)
+	if action == "" {
+		return "", nil
+	}
+	dlp.Count(action)
+	detectors := dlp.Detectors(findings)
+
+	sc := security.FromContext(ctx)
+	log := s.logger.WithFields(map[string]any{
+		kind:        name,
+		"detectors": detectors,
+		"findings":  len(findings),
+		"action":    action,
+		"session":   sc.SessionID,
+		"client":    sc.Client(),
+		"user":      sc.User(),
+	})
+	if action == config.DLPAlert {
+		log.Error("sensitive data returned to client")
+	} else {
+		log.Warn("sensitive data in result")
+	}
+	return action, detectors
+}
+
+// dlpWithheld explains why a result was withheld, naming the detectors
+// but not the data.
+func (s *Server) dlpWithheld(detectors []string) string {
+	return s.msg.Sprintf("Result withheld: it contains sensitive data (%s)", strings.Join(detectors, ", "))
+}
+
+// redact replaces the findings in a text.
+func (s *Server) redact(text string) string {
+	findings := s.dlp.Scan(text)
+	if len(findings) == 0 {
+		return text
+	}
+	return dlp.Redact(text, findings)
+}
+
+// redactResource replaces the findings in resource contents.
+func (s *Server) redactResource(c *mcp.ResourceContents) {
+	c.Text = s.redact(c.Text)
+	if c.Blob != nil {
+		c.Blob = []byte(s.redact(string(c.Blob)))
+	}
+}
+
+// toolResultTexts returns the texts of a tool result: its text content,
+// embedded resources and the strings of its structured content, with
+// base64 data decoded.
+func toolResultTexts(res *mcp.CallToolResult) []string {
+	var texts []string
+	for _, c := range res.Content {
+		switch c := c.(type) {
+		case *mcp.TextContent:
+			texts = append(texts, c.Text)
+		case *mcp.EmbeddedResource:
+			if c.Resource != nil {
+				texts = append(texts, c.Resource.Text, string(c.Resource.Blob))
+			}
+		}
+	}
+	if res.StructuredContent != nil {
+		texts = append(texts, jsonTexts(jsonValue(res.StructuredContent))...)
+	}
+	return texts
+}
+
+// redactToolResult replaces the findings in the texts of a tool result.
+// Structured content is replaced by its JSON form, which clients receive
+// anyway. Base64 data redacted in structured content is replaced in text
+// content too, which repeats it, as for read_file_chunk.
+func (s *Server) redactToolResult(res *mcp.CallToolResult) {
+	var encoded *strings.Replacer
+	if res.StructuredContent != nil {
+		var changed []string
+		res.StructuredContent, changed = s.redactJSON(jsonValue(res.StructuredContent))
+		encoded = strings.NewReplacer(changed...)
+	}
+	for _, c := range res.Content {
+		switch c := c.(type) {
+		case *mcp.TextContent:
+			if encoded != nil {
+				c.Text = encoded.Replace(c.Text)
+			}
+			c.Text = s.redact(c.Text)
+		case *mcp.EmbeddedResource:
+			if c.Resource != nil {
+				s.redactResource(c.Resource)
+			}
+		}
+	}
+}
+
+// jsonTexts returns the strings of a JSON value, with base64 data decoded.
+func jsonTexts(v any) []string {
+	var texts []string
+	collect := func(s string) string {
+		texts = append(texts, s)
+		return s
+	}
+	mapBase64(v, collect)
+	mapStrings(v, collect)
+	return texts
+}
+
+// redactJSON replaces the findings in the strings of a JSON value and in
+// its base64 data. It returns the value and the base64 strings it changed,
+// old and new in turn.
+func (s *Server) redactJSON(v any) (any, []string) {
+	changed := mapBase64(v, s.redact)
+	return mapStrings(v, s.redact), changed
+}
+
+// mapBase64 applies fn to the decoded data of the base64-encoded objects of
+// a JSON value, such as binary read_file_chunk and http_request results,
+// and encodes what fn returns in their place. It returns the strings it
+// changed, old and new in turn.
+func mapBase64(v any, fn func(string) string) []string {
+	var changed []string
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			changed = append(changed, mapBase64(item, fn)...)
+		}
+	case map[string]any:
+		if v["encoding"] != transfer.EncodingBase64 {
+			for _, item := range v {
+				changed = append(changed, mapBase64(item, fn)...)
+			}
+			return changed
+		}
+		for k, item := range v {
+			encoded, ok := item.(string)
+			if !ok || k == "encoding" {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				continue
+			}
+			if out := fn(string(data)); out != string(data) {
+				v[k] = base64.StdEncoding.EncodeToString([]byte(out))
+				changed = append(changed, encoded, v[k].(string))
+			}
+		}
+	}
+	return changed
+}
+
+// jsonValue returns the JSON form of v as maps, slices and scalars.
+func jsonValue(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// mapStrings applies fn to the strings of a JSON value, keys excepted.
+func mapStrings(v any, fn func(string) string) any {
+	switch v := v.(type) {
+	case string:
+		return fn(v)
+	case []any:
+		for i := range v {
+			v[i] = mapStrings(v[i], fn)
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = mapStrings(v[k], fn)
+		}
+	}
+	return v
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_dlp(t *testing.T) {
+	call := func(t *testing.T, action string) *mcp.CallToolResult {
+		t.Helper()
+		cfg := config.Default()
+		cfg.Security.DLP = config.DLPConfig{Action: action}
+		_, cs := newTestSession(t, cfg)
+
+		ctx := context.Background()
+
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "execute_command", Arguments: map[string]any{"command": "echo", "args": []string{"card", "4111-1111-1111-1111"}}})
+		if err != nil {
+			t.Fatalf("CallTool() error = %v", err)
+		}
+		return res
+	}
+	text := func(res *mcp.CallToolResult) string {
+		var b strings.Builder
+		for _, c := range res.Content {
+			if tc, ok := c.(*mcp.TextContent); ok {
+				b.WriteString(tc.Text)
+			}
+		}
+		return b.String()
+	}
+
+	t.Run("block", func(t *testing.T) {
+		res := call(t, config.DLPBlock)
+		if !res.IsError || !strings.Contains(text(res), "sensitive data (credit_card)") {
+			t.Errorf("expected the result to be withheld, got %q", text(res))
+		}
+		if strings.Contains(text(res), "4111") || res.StructuredContent != nil {
+			t.Error("withheld result reveals the card number")
+		}
+	})
+
+	t.Run("redact", func(t *testing.T) {
+		res := call(t, config.DLPRedact)
+		if res.IsError || !strings.Contains(text(res), "card [REDACTED:credit_card]") {
+			t.Errorf("expected the card number to be redacted, got %q", text(res))
+		}
+		structured, ok := res.StructuredContent.(map[string]any)
+		if !ok || !strings.Contains(structured["stdout"].(string), "[REDACTED:credit_card]") {
+			t.Errorf("expected the structured result to be redacted, got %v", res.StructuredContent)
+		}
+	})
+
+	t.Run("alert", func(t *testing.T) {
+		res := call(t, config.DLPAlert)
+		if res.IsError || !strings.Contains(text(res), "4111-1111-1111-1111") {
+			t.Errorf("expected the result as is, got %q", text(res))
+		}
+	})
+}
+
+func TestServer_dlpBase64(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dump.bin")
+	if err := os.WriteFile(path, []byte("\xff\xfecard 4111-1111-1111-1111\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Default()
+	cfg.Security.AllowedPaths = []string{dir}
+	cfg.Security.DLP = config.DLPConfig{Action: config.DLPRedact}
+	cfg.Transfer.DownloadEnabled = true
+	_, cs := newTestSession(t, cfg)
+
+	// Binary data is decoded before it is scanned
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "read_file_chunk", Arguments: map[string]any{"path": path}})
+	if err != nil || res.IsError {
+		t.Fatalf("read_file_chunk = %v, %v", res, err)
+	}
+	structured, _ := res.StructuredContent.(map[string]any)
+	encoded, _ := structured["data"].(string)
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || structured["encoding"] != "base64" {
+		t.Fatalf("structured result = %v", res.StructuredContent)
+	}
+	if string(data) != "\xff\xfecard [REDACTED:credit_card]\n" {
+		t.Errorf("decoded data = %q, want the card number redacted", data)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; text != encoded {
+		t.Errorf("text = %q, want the redacted data %q", text, encoded)
+	}
+}
+
+func TestServer_dlpNotifications(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("started\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Default()
+	cfg.Security.AllowedPaths = []string{dir}
+	cfg.Security.DLP = config.DLPConfig{Action: config.DLPRedact}
+	srv := newTestServer(t, cfg)
+
+	var mu sync.Mutex
+	var lines []string
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(ctx context.Context, cs *mcp.ClientSession, params *mcp.LoggingMessageParams) {
+			if params.Logger != "tail" {
+				return
+			}
+			data, _ := params.Data.(map[string]any)
+			followed, _ := data["lines"].([]any)
+			mu.Lock()
+			defer mu.Unlock()
+			for _, line := range followed {
+				lines = append(lines, line.(string))
+			}
+		},
+	})
+	cs := connectClient(t, srv, client)
+	ctx := context.Background()
+	if err := cs.SetLevel(ctx, &mcp.SetLevelParams{Level: "info"}); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		_, _ = f.WriteString("charged card 4111-1111-1111-1111\n")
+	}()
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "tail_file", Arguments: map[string]any{"path": path, "follow": "1s"}})
+	if err != nil || res.IsError {
+		t.Fatalf("tail_file = %v, %v", res, err)
+	}
+
+	// Followed lines sent as notifications are redacted like the result
+	mu.Lock()
+	defer mu.Unlock()
+	if len(lines) != 1 || lines[0] != "charged card [REDACTED:credit_card]" {
+		t.Errorf("notified lines = %q, want the card number redacted", lines)
+	}
+	if got := srv.returned.Load(); got < jsonSize(res) {
+		t.Errorf("returned bytes = %d, want the notification counted besides the result", got)
+	}
+}
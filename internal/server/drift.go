@@ -0,0 +1,38 @@
+package server
+
+import (
+	"github.com/mjmorales/simple-mcp-runner/internal/control"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// configDrift compares the configuration the server runs with to its
+// file on disk, which may have been edited since the server started.
+// Commands added by the catalog are left out, and settings overridden on
+// the command line show as drift.
+func (s *Server) configDrift() (control.Drift, error) {
+	if s.configFile == "" {
+		return control.Drift{}, apperrors.NotFoundError("the server was started without a configuration file", "config")
+	}
+	drift := control.Drift{File: s.configFile}
+
+	disk, err := config.LoadFromFile(s.configFile)
+	if err != nil {
+		drift.Error = err.Error()
+		return drift, nil
+	}
+
+	running := *s.config
+	s.commandsMu.RLock()
+	if s.catalog != nil {
+		running.Commands = s.localCommands
+	}
+	changes, err := config.Diff(&running, disk)
+	s.commandsMu.RUnlock()
+	if err != nil {
+		return control.Drift{}, err
+	}
+	drift.Changes = changes
+	drift.InSync = len(changes) == 0
+	return drift, nil
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func TestServer_configDrift(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("app: test\ntransport: stdio\nsecurity:\n  allowed_commands: [ls]\n")
+
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	srv, err := New(Options{Config: cfg, ConfigFile: path})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	drift, err := srv.configDrift()
+	if err != nil {
+		t.Fatalf("configDrift() error = %v", err)
+	}
+	if !drift.InSync || len(drift.Changes) != 0 {
+		t.Fatalf("configDrift() = %+v, want in sync", drift)
+	}
+
+	write("app: test\ntransport: stdio\nsecurity:\n  allowed_commands: [ls, rm]\n")
+	drift, err = srv.configDrift()
+	if err != nil {
+		t.Fatalf("configDrift() error = %v", err)
+	}
+	if drift.InSync || len(drift.Changes) != 1 {
+		t.Fatalf("configDrift() = %+v, want one change", drift)
+	}
+	change := drift.Changes[0]
+	if change.Path != "security.allowed_commands" || !change.Security || len(change.Added) != 1 || change.Added[0] != "rm" {
+		t.Errorf("change = %+v, want rm added to security.allowed_commands", change)
+	}
+
+	write("security: [")
+	drift, err = srv.configDrift()
+	if err != nil {
+		t.Fatalf("configDrift() error = %v", err)
+	}
+	if drift.Error == "" || drift.InSync {
+		t.Errorf("configDrift() = %+v, want a load error", drift)
+	}
+
+	srv.configFile = ""
+	if _, err := srv.configDrift(); err == nil {
+		t.Error("configDrift() without a configuration file should fail")
+	}
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Environment variable sources.
+const (
+	envSourceInherited = "inherited"
+	envSourceCommand   = "command"
+)
+
+// GetEnvironmentParams represents parameters for inspecting the environment.
+type GetEnvironmentParams struct {
+	Command string `json:"command,omitempty"` // Include this configured command's env
+	Filter  string `json:"filter,omitempty"`  // Glob pattern for variable names
+}
+
+// EnvVar is a single environment variable.
+type EnvVar struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Masked bool   `json:"masked,omitempty"`
+	Source string `json:"source"` // inherited or command
+}
+
+// EnvironmentResult lists the environment commands run with.
+type EnvironmentResult struct {
+	Variables []EnvVar `json:"variables"`
+	Masked    int      `json:"masked"`
+}
+
+// registerEnvironmentTool registers the environment inspection tool.
+func (s *Server) registerEnvironmentTool() error {
+	tool := &mcp.Tool{
+		Name:        "get_environment",
+		Description: "Get the environment variables executed commands inherit, after the server's environment policy. Values of sensitive-looking variables (tokens, passwords, keys) are masked. Set command to include a configured command's own variables; filter by name with a glob such as \"GO*\".",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[GetEnvironmentParams]) (*mcp.CallToolResultFor[EnvironmentResult], error) {
+		result, err := s.environment(&params.Arguments)
+		if err != nil {
+			return &mcp.CallToolResultFor[EnvironmentResult]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Environment inspection failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[EnvironmentResult]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatEnvironment(result)}},
+			StructuredContent: *result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered environment tool")
+
+	return nil
+}
+
+// environment builds the redacted environment for the given parameters.
+func (s *Server) environment(args *GetEnvironmentParams) (*EnvironmentResult, error) {
+	if args.Filter != "" {
+		if _, err := filepath.Match(args.Filter, ""); err != nil {
+			return nil, apperrors.ValidationError("invalid filter pattern: "+args.Filter, "filter")
+		}
+	}
+
+	vars := make(map[string]EnvVar)
+	for _, kv := range s.executor.InheritedEnv() {
+		name, value, _ := strings.Cut(kv, "=")
+		vars[name] = EnvVar{Name: name, Value: value, Source: envSourceInherited}
+	}
+
+	if args.Command != "" {
+		cmd := s.findCommand(args.Command)
+		if cmd == nil {
+			return nil, apperrors.NotFoundError("configured command not found: "+args.Command, args.Command)
+		}
+		for name, value := range cmd.Env {
+			vars[name] = EnvVar{Name: name, Value: value, Source: envSourceCommand}
+		}
+	}
+
+	result := &EnvironmentResult{Variables: make([]EnvVar, 0, len(vars))}
+	for name, v := range vars {
+		if args.Filter != "" {
+			if matched, _ := filepath.Match(args.Filter, name); !matched {
+				continue
+			}
+		}
+		if s.executor.IsSensitiveEnv(name) {
+			v.Value = executor.MaskedValue
+			v.Masked = true
+			result.Masked++
+		}
+		result.Variables = append(result.Variables, v)
+	}
+
+	sort.Slice(result.Variables, func(i, j int) bool {
+		return result.Variables[i].Name < result.Variables[j].Name
+	})
+
+	return result, nil
+}
+
+// formatEnvironment renders environment variables as text.
+func formatEnvironment(result *EnvironmentResult) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Environment (%d variables, %d masked):\n", len(result.Variables), result.Masked)
+	for _, v := range result.Variables {
+		fmt.Fprintf(&b, "%s=%s", v.Name, v.Value)
+		if v.Source == envSourceCommand {
+			b.WriteString(" (command)")
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func TestServer_environment(t *testing.T) {
+	t.Setenv("SMR_ENV_VISIBLE", "visible")
+	t.Setenv("SMR_ENV_TOKEN", "secret")
+	t.Setenv("SMR_ENV_DENIED", "denied")
+
+	cfg := config.Default()
+	cfg.Security.EnvDeny = []string{"SMR_ENV_DENIED"}
+	cfg.Commands = []config.Command{
+		{
+			Name:        "build",
+			Description: "Build",
+			Command:     "go",
+			Env:         map[string]string{"SMR_ENV_VISIBLE": "override", "SMR_ENV_PASSWORD": "hunter2"},
+		},
+	}
+
+	srv, err := New(Options{Config: cfg})
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	result, err := srv.environment(&GetEnvironmentParams{Filter: "SMR_ENV_*"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := make(map[string]EnvVar)
+	for _, v := range result.Variables {
+		got[v.Name] = v
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 variables, got %+v", result.Variables)
+	}
+	if got["SMR_ENV_VISIBLE"].Value != "visible" {
+		t.Errorf("unexpected value: %+v", got["SMR_ENV_VISIBLE"])
+	}
+	if v := got["SMR_ENV_TOKEN"]; !v.Masked || v.Value != executor.MaskedValue {
+		t.Errorf("expected masked token, got %+v", v)
+	}
+	if result.Masked != 1 {
+		t.Errorf("expected 1 masked variable, got %d", result.Masked)
+	}
+
+	result, err = srv.environment(&GetEnvironmentParams{Command: "build", Filter: "SMR_ENV_*"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got = make(map[string]EnvVar)
+	for _, v := range result.Variables {
+		got[v.Name] = v
+	}
+	if v := got["SMR_ENV_VISIBLE"]; v.Value != "override" || v.Source != envSourceCommand {
+		t.Errorf("expected command override, got %+v", v)
+	}
+	if v := got["SMR_ENV_PASSWORD"]; !v.Masked {
+		t.Errorf("expected masked command variable, got %+v", v)
+	}
+
+	if _, err := srv.environment(&GetEnvironmentParams{Command: "missing"}); err == nil {
+		t.Error("expected error for unknown command")
+	}
+	if _, err := srv.environment(&GetEnvironmentParams{Filter: "["}); err == nil {
+		t.Error("expected error for invalid filter")
+	}
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// eventsURI is the resource holding the recent server events.
+const eventsURI = "runner://events"
+
+// eventsLogger is the logger of the log messages events are sent as.
+const eventsLogger = "events"
+
+// defaultEventLogSize is how many events are kept unless configured.
+const defaultEventLogSize = 200
+
+// eventLog keeps the most recent server events.
+type eventLog struct {
+	mu     sync.Mutex
+	events []types.ServerEvent // Ring of events; the oldest is at next once full
+	next   int
+	seq    int64
+}
+
+// newEventLog returns a log keeping size events.
+func newEventLog(size int) *eventLog {
+	if size <= 0 {
+		size = defaultEventLogSize
+	}
+	return &eventLog{events: make([]types.ServerEvent, 0, size)}
+}
+
+// add records an event, dropping the oldest once the log is full.
+func (l *eventLog) add(typ, message string, fields map[string]any) types.ServerEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.seq++
+	event := types.ServerEvent{
+		Seq:     l.seq,
+		Time:    time.Now(),
+		Type:    typ,
+		Message: message,
+		Fields:  fields,
+	}
+	if len(l.events) < cap(l.events) {
+		l.events = append(l.events, event)
+		return event
+	}
+	l.events[l.next] = event
+	l.next = (l.next + 1) % len(l.events)
+	return event
+}
+
+// list returns the events kept, oldest first.
+func (l *eventLog) list() []types.ServerEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	events := make([]types.ServerEvent, 0, len(l.events))
+	events = append(events, l.events[l.next:]...)
+	return append(events, l.events[:l.next]...)
+}
+
+// emit records a server event and sends it to the sessions that enabled
+// logging, as an info message from the events logger. The MCP SDK does
+// not support resource subscriptions, so log messages are how clients
+// follow runner://events as it changes.
+func (s *Server) emit(typ, message string, fields map[string]any) {
+	event := s.events.add(typ, message, fields)
+	for ss := range s.mcpServer.Sessions() {
+		if err := ss.Log(context.Background(), &mcp.LoggingMessageParams{
+			Level:  "info",
+			Logger: eventsLogger,
+			Data:   event,
+		}); err != nil {
+			s.logger.WithError(err).Debug("failed to send server event", "type", typ)
+		}
+	}
+}
+
+// emitDecision records the policy events of an execution: denied by the
+// security policy, or refused because a limit was reached.
+func (s *Server) emitDecision(tool string, req types.CommandExecutionRequest, err error, decision string, session string) {
+	fields := map[string]any{"tool": tool, "command": req.Command}
+	if session != "" {
+		fields["session"] = session
+	}
+
+	var appErr *apperrors.Error
+	switch {
+	case decision == types.PolicyDecisionDenied:
+		if err != nil {
+			fields["error"] = err.Error()
+		}
+		s.emit(types.EventExecutionDenied, "execution of "+req.Command+" denied", fields)
+	case errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeRateLimited:
+		if limit, ok := appErr.GetContext("limit"); ok {
+			fields["limit"] = limit
+		}
+		fields["error"] = err.Error()
+		s.emit(types.EventBudgetExceeded, "execution of "+req.Command+" refused: limit reached", fields)
+	}
+}
+
+// registerEventsResource registers the server event resource.
+func (s *Server) registerEventsResource() {
+	resource := &mcp.Resource{
+		URI:         eventsURI,
+		Name:        "events",
+		Title:       s.msg.T("Server events"),
+		Description: s.msg.T("Recent server lifecycle and policy events, oldest first: tools registered and removed, command catalog updates, executions denied and limits reached. New events are sent as info log messages from the events logger."),
+		MIMEType:    "application/json",
+	}
+
+	s.mcpServer.AddResource(resource, func(ctx context.Context, ss *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+		data, err := json.MarshalIndent(s.events.list(), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: eventsURI, MIMEType: "application/json", Text: string(data)}},
+		}, nil
+	})
+
+	s.logger.Debug("registered server events resource")
+}
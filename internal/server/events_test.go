@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestEventLog(t *testing.T) {
+	log := newEventLog(3)
+	for _, typ := range []string{"a", "b", "c", "d", "e"} {
+		log.add(typ, typ, nil)
+	}
+
+	events := log.list()
+	if len(events) != 3 {
+		t.Fatalf("kept %d events, want 3", len(events))
+	}
+	for i, want := range []string{"c", "d", "e"} {
+		if events[i].Type != want || events[i].Seq != int64(i+3) {
+			t.Errorf("events[%d] = %s #%d, want %s #%d", i, events[i].Type, events[i].Seq, want, i+3)
+		}
+	}
+}
+
+func TestServer_events(t *testing.T) {
+	cfg := config.Default()
+	cfg.Security.BlockedCommands = []string{"rm"}
+	srv := newTestServer(t, cfg)
+
+	logs := make(chan *mcp.LoggingMessageParams, 16)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(ctx context.Context, cs *mcp.ClientSession, params *mcp.LoggingMessageParams) {
+			logs <- params
+		},
+	})
+	cs := connectClient(t, srv, client)
+	ctx := context.Background()
+	if err := cs.SetLevel(ctx, &mcp.SetLevelParams{Level: "info"}); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "execute_command",
+		Arguments: map[string]any{"command": "rm", "args": []string{"-rf", "build"}},
+	}); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+
+	// The denial is sent to the session as it happens
+	timeout := time.After(5 * time.Second)
+	for denied := false; !denied; {
+		select {
+		case msg := <-logs:
+			if msg.Logger != eventsLogger {
+				continue
+			}
+			data, err := json.Marshal(msg.Data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var event types.ServerEvent
+			if err := json.Unmarshal(data, &event); err != nil {
+				t.Fatal(err)
+			}
+			denied = event.Type == types.EventExecutionDenied && event.Fields["command"] == "rm"
+		case <-timeout:
+			t.Fatal("no execution_denied event sent")
+		}
+	}
+
+	// and kept with the earlier events
+	res, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: eventsURI})
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	var events []types.ServerEvent
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &events); err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for i, event := range events {
+		seen[event.Type] = true
+		if i > 0 && event.Seq <= events[i-1].Seq {
+			t.Errorf("events out of order: #%d after #%d", event.Seq, events[i-1].Seq)
+		}
+	}
+	for _, typ := range []string{types.EventToolRegistered, types.EventSessionStarted, types.EventExecutionDenied} {
+		if !seen[typ] {
+			t.Errorf("no %s event in %s", typ, res.Contents[0].Text)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/fileinfo"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// StatPathParams represents parameters for inspecting a path.
+type StatPathParams struct {
+	Path string `json:"path"`
+}
+
+// HashFileParams represents parameters for hashing a file.
+type HashFileParams struct {
+	Path     string `json:"path"`
+	Expected string `json:"expected,omitempty"` // MD5 or SHA-256 digest to verify
+}
+
+// AnalyzeDiskUsageParams represents parameters for analyzing disk usage.
+type AnalyzeDiskUsageParams struct {
+	Path       string `json:"path,omitempty"`        // Directory to analyze; every allowed path by default
+	MaxDepth   int    `json:"max_depth,omitempty"`   // Directories deeper than this are not visited
+	MaxEntries int    `json:"max_entries,omitempty"` // Capped by disk_usage.max_entries
+	Limit      int    `json:"limit,omitempty"`       // Largest files and directories returned
+}
+
+// registerFileInfoTools registers the file metadata tools.
+func (s *Server) registerFileInfoTools() error {
+	s.registerStatPathTool()
+	s.registerHashFileTool()
+	s.registerAnalyzeDiskUsageTool()
+
+	s.logger.Debug("registered file info tools")
+
+	return nil
+}
+
+func (s *Server) registerStatPathTool() {
+	tool := &mcp.Tool{
+		Name:        "stat_path",
+		Description: "Get metadata for an absolute path: type, size, mode, modification time, symlink target, and detected MIME type for files.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[StatPathParams]) (*mcp.CallToolResultFor[fileinfo.Info], error) {
+		info, err := fileinfo.Stat(s.config, params.Arguments.Path)
+		if err != nil {
+			s.logger.WithError(err).Debug("stat failed", "path", params.Arguments.Path)
+			return &mcp.CallToolResultFor[fileinfo.Info]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Stat failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[fileinfo.Info]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatFileInfo(info)}},
+			StructuredContent: *info,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerHashFileTool() {
+	tool := &mcp.Tool{
+		Name:        "hash_file",
+		Description: "Compute the MD5 and SHA-256 checksums of a file by absolute path without shasum or openssl. Set expected to verify a digest (optionally prefixed with md5: or sha256:).",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[HashFileParams]) (*mcp.CallToolResultFor[fileinfo.Hashes], error) {
+		args := params.Arguments
+
+		hashes, err := fileinfo.Hash(s.config, args.Path)
+		if err != nil {
+			s.logger.WithError(err).Debug("hash failed", "path", args.Path)
+			return &mcp.CallToolResultFor[fileinfo.Hashes]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Hash failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		text := fmt.Sprintf("%s (%d bytes)\nmd5: %s\nsha256: %s\n", hashes.Path, hashes.Size, hashes.MD5, hashes.SHA256)
+		isError := false
+		if args.Expected != "" {
+			if hashes.Verify(args.Expected) {
+				text += "Checksum matches\n"
+			} else {
+				text += "Checksum does not match\n"
+				isError = true
+			}
+		}
+
+		return &mcp.CallToolResultFor[fileinfo.Hashes]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+			StructuredContent: *hashes,
+			IsError:           isError,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerAnalyzeDiskUsageTool() {
+	tool := &mcp.Tool{
+		Name:        "analyze_disk_usage",
+		Description: "Find what takes up disk space under an absolute directory, or under every allowed path when path is omitted, without du or find. Returns the largest files and directories and totals. Symlinks are not followed; max_depth and max_entries bound the walk, and sizes are lower bounds when it stops early.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[AnalyzeDiskUsageParams]) (*mcp.CallToolResultFor[fileinfo.Usage], error) {
+		args := params.Arguments
+
+		usage, err := fileinfo.DiskUsage(ctx, s.config, args.Path, fileinfo.UsageOptions{
+			MaxDepth:   args.MaxDepth,
+			MaxEntries: args.MaxEntries,
+			Limit:      args.Limit,
+		})
+		if err != nil {
+			s.logger.WithError(err).Debug("disk usage analysis failed", "path", args.Path)
+			return &mcp.CallToolResultFor[fileinfo.Usage]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Disk usage analysis failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[fileinfo.Usage]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatDiskUsage(usage)}},
+			StructuredContent: *usage,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+// formatDiskUsage renders a disk usage analysis as text.
+func formatDiskUsage(usage *fileinfo.Usage) string {
+	var b strings.Builder
+
+	for _, root := range usage.Roots {
+		fmt.Fprintf(&b, "%s: %d bytes in %d files and %d directories\n", root.Path, root.Size, root.Files, root.Dirs)
+	}
+	if usage.Truncated != "" {
+		fmt.Fprintf(&b, "Stopped at %s after %d entries; sizes are lower bounds\n", usage.Truncated, usage.Entries)
+	}
+	if usage.Skipped > 0 {
+		fmt.Fprintf(&b, "Skipped %d unreadable or denied directories\n", usage.Skipped)
+	}
+	if len(usage.LargestDirs) > 0 {
+		b.WriteString("\nLargest directories:\n")
+		for _, dir := range usage.LargestDirs {
+			fmt.Fprintf(&b, "%12d  %s\n", dir.Size, dir.Path)
+		}
+	}
+	if len(usage.LargestFiles) > 0 {
+		b.WriteString("\nLargest files:\n")
+		for _, file := range usage.LargestFiles {
+			fmt.Fprintf(&b, "%12d  %s\n", file.Size, file.Path)
+		}
+	}
+
+	return b.String()
+}
+
+// formatFileInfo renders path metadata as text.
+func formatFileInfo(info *fileinfo.Info) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Path: %s\n", info.Path)
+	fmt.Fprintf(&b, "Type: %s\n", info.Type)
+	fmt.Fprintf(&b, "Size: %d bytes\n", info.Size)
+	fmt.Fprintf(&b, "Mode: %s (%s)\n", info.Mode, info.Perm)
+	fmt.Fprintf(&b, "Modified: %s\n", info.ModTime.Format(time.RFC3339))
+	if info.Target != "" {
+		fmt.Fprintf(&b, "Target: %s\n", info.Target)
+	}
+	if info.MimeType != "" {
+		fmt.Fprintf(&b, "MIME type: %s\n", info.MimeType)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/fileops"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DeletePathParams represents parameters for deleting a path.
+type DeletePathParams struct {
+	Path string `json:"path"`
+}
+
+// RestorePathParams represents parameters for restoring a path.
+type RestorePathParams struct {
+	ID   string `json:"id,omitempty"`   // Trash entry ID
+	Path string `json:"path,omitempty"` // Where to restore it; the original path by default
+}
+
+// ListTrashParams represents parameters for listing the trash.
+type ListTrashParams struct{}
+
+// TrashListResult lists the trash.
+type TrashListResult struct {
+	Entries []fileops.Entry `json:"entries"`
+}
+
+// ChangePermissionsParams represents parameters for changing permissions.
+type ChangePermissionsParams struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"` // Octal, such as 0755, or symbolic, such as u+x,go-w
+}
+
+// registerFileOpsTools registers the tools that change files in place of
+// rm and chmod.
+func (s *Server) registerFileOpsTools() error {
+	if s.fileOps.TrashEnabled() {
+		s.registerDeletePathTool()
+		s.registerRestorePathTool()
+		s.registerListTrashTool()
+	}
+	s.registerChangePermissionsTool()
+
+	s.logger.Debug("registered file operation tools")
+
+	return nil
+}
+
+func (s *Server) registerDeletePathTool() {
+	tool := &mcp.Tool{
+		Name:        "delete_path",
+		Description: "Delete a file, directory or symlink by absolute path instead of running rm. The path is moved into the server's trash with a record of where it came from, so restore_path can bring it back until the trash purges it. Only paths inside the allowed paths can be deleted, not the allowed paths themselves.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[DeletePathParams]) (*mcp.CallToolResultFor[fileops.Entry], error) {
+		entry, err := s.fileOps.Delete(params.Arguments.Path)
+		if err != nil {
+			s.logger.WithError(err).Warn("delete failed", "path", params.Arguments.Path)
+			return &mcp.CallToolResultFor[fileops.Entry]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Delete failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[fileops.Entry]{
+			Content: []mcp.Content{&mcp.TextContent{
+				Text: fmt.Sprintf("Moved %s %s (%d bytes) to the trash as %s", entry.Type, entry.Path, entry.Size, entry.ID),
+			}},
+			StructuredContent: *entry,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerRestorePathTool() {
+	tool := &mcp.Tool{
+		Name:        "restore_path",
+		Description: "Restore a path deleted with delete_path from the server's trash, by trash entry ID or by the path it was deleted from (the most recent delete of that path). It is restored where it was deleted from unless another absolute path is given, and never replaces an existing path.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[RestorePathParams]) (*mcp.CallToolResultFor[fileops.Restored], error) {
+		args := params.Arguments
+
+		restored, err := s.fileOps.Restore(args.ID, args.Path)
+		if err != nil {
+			s.logger.WithError(err).Warn("restore failed", "id", args.ID, "path", args.Path)
+			return &mcp.CallToolResultFor[fileops.Restored]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Restore failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[fileops.Restored]{
+			Content: []mcp.Content{&mcp.TextContent{
+				Text: fmt.Sprintf("Restored %s %s from the trash to %s", restored.Type, restored.Path, restored.RestoredTo),
+			}},
+			StructuredContent: *restored,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerListTrashTool() {
+	tool := &mcp.Tool{
+		Name:        "list_trash",
+		Description: "List the paths in the server's trash, newest first, with their trash entry IDs, original paths, sizes and deletion times.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ListTrashParams]) (*mcp.CallToolResultFor[TrashListResult], error) {
+		entries, err := s.fileOps.List()
+		if err != nil {
+			s.logger.WithError(err).Warn("failed to list trash")
+			return &mcp.CallToolResultFor[TrashListResult]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Listing the trash failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+		if entries == nil {
+			entries = []fileops.Entry{}
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d entries in the trash", len(entries))
+		for _, e := range entries {
+			fmt.Fprintf(&b, "\n%s  %s  %s (%d bytes)  %s", e.ID, e.Deleted.Format(time.RFC3339), e.Type, e.Size, e.Path)
+		}
+
+		return &mcp.CallToolResultFor[TrashListResult]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: b.String()}},
+			StructuredContent: TrashListResult{Entries: entries},
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerChangePermissionsTool() {
+	tool := &mcp.Tool{
+		Name:        "change_permissions",
+		Description: "Change the permissions of a file or directory by absolute path instead of running chmod, with an octal mode such as 0755 or symbolic clauses such as u+x,go-w. Only paths inside the allowed paths can be changed; setuid, setgid and sticky bits cannot be set and symlinks are not followed.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ChangePermissionsParams]) (*mcp.CallToolResultFor[fileops.PermissionChange], error) {
+		args := params.Arguments
+
+		change, err := s.fileOps.Chmod(args.Path, args.Mode)
+		if err != nil {
+			s.logger.WithError(err).Warn("permission change failed", "path", args.Path)
+			return &mcp.CallToolResultFor[fileops.PermissionChange]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Permission change failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[fileops.PermissionChange]{
+			Content: []mcp.Content{&mcp.TextContent{
+				Text: fmt.Sprintf("Changed permissions of %s from %s to %s", change.Path, change.Before, change.After),
+			}},
+			StructuredContent: *change,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
@@ -0,0 +1,273 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/gitinfo"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// GitStatusParams represents parameters for reading a repository's status.
+type GitStatusParams struct {
+	WorkDir string `json:"workdir"` // Absolute directory inside the repository
+}
+
+// GitDiffParams represents parameters for diffing a repository's changes.
+type GitDiffParams struct {
+	WorkDir  string   `json:"workdir"`
+	Staged   bool     `json:"staged,omitempty"`    // Diff the index instead of the working tree
+	Base     string   `json:"base,omitempty"`      // Revision to compare with, e.g. HEAD or main
+	Paths    []string `json:"paths,omitempty"`     // Limit the diff to these paths
+	Context  *int     `json:"context,omitempty"`   // Unchanged lines around changes
+	MaxLines int      `json:"max_lines,omitempty"` // Hunk lines returned over all files
+}
+
+// GitLogParams represents parameters for listing a repository's commits.
+type GitLogParams struct {
+	WorkDir  string   `json:"workdir"`
+	Revision string   `json:"revision,omitempty"`  // Revision or range, e.g. main..HEAD
+	Paths    []string `json:"paths,omitempty"`     // Only commits changing these paths
+	MaxCount int      `json:"max_count,omitempty"` // Commits returned
+	Skip     int      `json:"skip,omitempty"`      // Commits skipped, to page through history
+	Files    bool     `json:"files,omitempty"`     // List the files each commit changed
+}
+
+// registerGitTools registers the git tools when git is installed.
+func (s *Server) registerGitTools() error {
+	if _, err := exec.LookPath("git"); err != nil {
+		s.logger.Debug("git not found, git tools not registered")
+		return nil
+	}
+
+	s.registerGitStatusTool()
+	s.registerGitDiffTool()
+	s.registerGitLogTool()
+
+	s.logger.Debug("registered git tools")
+
+	return nil
+}
+
+// gitContext bounds a git tool call by the default command timeout.
+func (s *Server) gitContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, s.config.Execution.DefaultTimeout.Std())
+}
+
+func (s *Server) registerGitStatusTool() {
+	tool := &mcp.Tool{
+		Name:        "git_status",
+		Description: "Get the status of the git repository containing an absolute workdir as JSON: branch, commit, upstream with ahead and behind counts, and the changed files with their staged and unstaged change (added, modified, deleted, renamed, ...), untracked and conflicted files. Files outside the allowed paths are left out.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[GitStatusParams]) (*mcp.CallToolResultFor[gitinfo.Status], error) {
+		ctx, cancel := s.gitContext(ctx)
+		defer cancel()
+
+		var status *gitinfo.Status
+		err := s.executor.AdmitRead("git")
+		if err == nil {
+			status, err = gitinfo.GetStatus(ctx, s.config, params.Arguments.WorkDir)
+		}
+		if err != nil {
+			s.logger.WithError(err).Debug("git status failed", "workdir", params.Arguments.WorkDir)
+			return &mcp.CallToolResultFor[gitinfo.Status]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Git status failed: %s", err.Error())}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[gitinfo.Status]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatGitStatus(status)}},
+			StructuredContent: *status,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerGitDiffTool() {
+	tool := &mcp.Tool{
+		Name:        "git_diff",
+		Description: "Get the changes of the git repository containing an absolute workdir as JSON: the changed files with their change kind, added and deleted line counts and hunks of lines. Diffs the working tree against the index by default; set staged to diff the index against HEAD, or base to compare with a revision. Untracked files are not included.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[GitDiffParams]) (*mcp.CallToolResultFor[gitinfo.Diff], error) {
+		args := params.Arguments
+		ctx, cancel := s.gitContext(ctx)
+		defer cancel()
+
+		opts := gitinfo.DiffOptions{
+			Staged:   args.Staged,
+			Base:     args.Base,
+			Paths:    args.Paths,
+			Context:  gitinfo.DefaultDiffContext,
+			MaxLines: args.MaxLines,
+		}
+		if args.Context != nil && *args.Context >= 0 {
+			opts.Context = *args.Context
+		}
+		var diff *gitinfo.Diff
+		err := s.executor.AdmitRead("git")
+		if err == nil {
+			diff, err = gitinfo.GetDiff(ctx, s.config, args.WorkDir, opts)
+		}
+		if err != nil {
+			s.logger.WithError(err).Debug("git diff failed", "workdir", args.WorkDir)
+			return &mcp.CallToolResultFor[gitinfo.Diff]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Git diff failed: %s", err.Error())}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[gitinfo.Diff]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatGitDiff(diff)}},
+			StructuredContent: *diff,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerGitLogTool() {
+	tool := &mcp.Tool{
+		Name:        "git_log",
+		Description: "List the commits of the git repository containing an absolute workdir as JSON, newest first: hash, parents, author, dates, subject and body, and with files the files each commit changed. Set revision to a branch or range such as main..HEAD, paths to only list commits changing them, and skip to page through history.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[GitLogParams]) (*mcp.CallToolResultFor[gitinfo.Log], error) {
+		args := params.Arguments
+		ctx, cancel := s.gitContext(ctx)
+		defer cancel()
+
+		var log *gitinfo.Log
+		err := s.executor.AdmitRead("git")
+		if err == nil {
+			log, err = gitinfo.GetLog(ctx, s.config, args.WorkDir, gitinfo.LogOptions{
+				Revision: args.Revision,
+				Paths:    args.Paths,
+				MaxCount: args.MaxCount,
+				Skip:     args.Skip,
+				Files:    args.Files,
+			})
+		}
+		if err != nil {
+			s.logger.WithError(err).Debug("git log failed", "workdir", args.WorkDir)
+			return &mcp.CallToolResultFor[gitinfo.Log]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Git log failed: %s", err.Error())}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[gitinfo.Log]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatGitLog(log)}},
+			StructuredContent: *log,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+// formatGitStatus summarizes a repository's status.
+func formatGitStatus(status *gitinfo.Status) string {
+	var b strings.Builder
+	switch {
+	case status.Branch != "":
+		fmt.Fprintf(&b, "On branch %s", status.Branch)
+	default:
+		b.WriteString("HEAD detached")
+	}
+	if status.Commit != "" {
+		fmt.Fprintf(&b, " at %s", shortHash(status.Commit))
+	}
+	if status.Upstream != "" {
+		fmt.Fprintf(&b, ", tracking %s (ahead %d, behind %d)", status.Upstream, status.Ahead, status.Behind)
+	}
+	b.WriteString("\n")
+	if status.Scoped {
+		b.WriteString("Only the allowed part of the repository is shown\n")
+	}
+	if status.Clean {
+		b.WriteString("Nothing to commit, working tree clean\n")
+		return b.String()
+	}
+	for _, f := range status.Files {
+		switch {
+		case f.Untracked:
+			fmt.Fprintf(&b, "untracked: %s\n", f.Path)
+		case f.Conflict:
+			fmt.Fprintf(&b, "conflict: %s\n", f.Path)
+		default:
+			path := f.Path
+			if f.OrigPath != "" {
+				path = f.OrigPath + " -> " + f.Path
+			}
+			var changes []string
+			if f.Staged != "" {
+				changes = append(changes, "staged "+f.Staged)
+			}
+			if f.Unstaged != "" {
+				changes = append(changes, "unstaged "+f.Unstaged)
+			}
+			fmt.Fprintf(&b, "%s: %s\n", strings.Join(changes, ", "), path)
+		}
+	}
+	return b.String()
+}
+
+// formatGitDiff renders a diff as a unified diff.
+func formatGitDiff(diff *gitinfo.Diff) string {
+	if len(diff.Files) == 0 {
+		return "No changes\n"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d files changed, %d insertions(+), %d deletions(-)\n", len(diff.Files), diff.Added, diff.Deleted)
+	for _, f := range diff.Files {
+		path := f.Path
+		if f.OrigPath != "" {
+			path = f.OrigPath + " -> " + f.Path
+		}
+		fmt.Fprintf(&b, "\n%s (%s, +%d -%d)\n", path, f.Change, f.Added, f.Deleted)
+		if f.Binary {
+			b.WriteString("Binary file\n")
+		}
+		for _, h := range f.Hunks {
+			fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@ %s\n", h.OldStart, h.OldLines, h.NewStart, h.NewLines, h.Section)
+			for _, line := range h.Lines {
+				b.WriteString(line + "\n")
+			}
+		}
+	}
+	if diff.Truncated {
+		b.WriteString("... (more changes not shown)\n")
+	}
+	return b.String()
+}
+
+// formatGitLog lists commits one per line.
+func formatGitLog(log *gitinfo.Log) string {
+	if len(log.Commits) == 0 {
+		return "No commits\n"
+	}
+	var b strings.Builder
+	for _, c := range log.Commits {
+		fmt.Fprintf(&b, "%s %s (%s, %s)\n", shortHash(c.Hash), c.Subject, c.Author, c.AuthorDate.Format("2006-01-02"))
+		for _, f := range c.Files {
+			fmt.Fprintf(&b, "  %s %s\n", f.Change, f.Path)
+		}
+	}
+	if log.More {
+		b.WriteString("... (older commits not shown; use skip)\n")
+	}
+	return b.String()
+}
+
+// shortHash abbreviates a commit hash.
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
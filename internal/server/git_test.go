@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/internal/gitinfo"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_gitTools(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "Initial commit"},
+	} {
+		git := exec.Command("git", args...)
+		git.Dir = repo
+		if out, err := git.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, "new.txt"), []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Security.AllowedPaths = []string{repo}
+	srv, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "git_status", Arguments: map[string]any{"workdir": repo}})
+	if err != nil || res.IsError {
+		t.Fatalf("git_status = %v, %v", res, err)
+	}
+	var status gitinfo.Status
+	if data, err := json.Marshal(res.StructuredContent); err == nil {
+		json.Unmarshal(data, &status)
+	}
+	if status.Clean || len(status.Files) != 1 || !status.Files[0].Untracked || status.Files[0].Path != "new.txt" {
+		t.Errorf("git_status = %+v", status)
+	}
+
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "git_log", Arguments: map[string]any{"workdir": repo}})
+	if err != nil || res.IsError {
+		t.Fatalf("git_log = %v, %v", res, err)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Initial commit") {
+		t.Errorf("git_log text = %q", text)
+	}
+
+	// Workdirs outside allowed_paths are refused
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "git_diff", Arguments: map[string]any{"workdir": t.TempDir()}})
+	if err != nil || !res.IsError {
+		t.Errorf("git_diff outside allowed_paths = %v, %v, want an error result", res, err)
+	}
+
+	// Git does not run while an operator pauses executions
+	if err := srv.executor.SetSwitch(executor.SwitchPaused, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"git_status", "git_diff", "git_log"} {
+		res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: map[string]any{"workdir": repo}})
+		if err != nil || !res.IsError || !strings.Contains(res.Content[0].(*mcp.TextContent).Text, "paused") {
+			t.Errorf("%s while paused = %v, %v, want an error result", name, res, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/internal/history"
+	"github.com/mjmorales/simple-mcp-runner/internal/security"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// recordExecution stores an execution in the history and returns the result
+// annotated with its history ID. A nil result (execution rejected before it
+// started) is still recorded with the error.
+func (s *Server) recordExecution(ctx context.Context, tool string, req types.CommandExecutionRequest, result *types.CommandExecutionResult, err error) *types.CommandExecutionResult {
+	return s.recordDecision(ctx, tool, req, result, err, history.Decision(err))
+}
+
+// recordDecision is recordExecution with the policy decision given, for
+// errors that no longer carry their type.
+func (s *Server) recordDecision(ctx context.Context, tool string, req types.CommandExecutionRequest, result *types.CommandExecutionResult, err error, decision string) *types.CommandExecutionResult {
+	rec := types.ExecutionRecord{
+		Source:   types.ExecutionSourceTool,
+		Tool:     tool,
+		Request:  req,
+		Result:   result,
+		Decision: decision,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) {
+			if name, ok := appErr.GetContext(executor.TripwireContextKey); ok {
+				rec.Tripwire, _ = name.(string)
+			}
+			if heuristic, ok := appErr.GetContext(executor.ScreeningContextKey); ok {
+				rec.Screening, _ = heuristic.(string)
+			}
+		}
+	}
+	if result != nil {
+		result.Provenance = s.provenance(tool, req)
+	}
+	if sc := security.FromContext(ctx); sc != nil {
+		rec.Session = sc.SessionID
+		rec.Client = sc.ClientName
+		rec.User = sc.Principal
+	}
+
+	s.usage.Run(req.Command, err, result != nil && result.ExitCode != 0)
+	s.telemetry.Run(req.Command, err, result != nil && result.ExitCode != 0)
+	s.emitDecision(tool, req, err, decision, rec.Session)
+
+	stored := s.history.Add(rec)
+	return stored.Result
+}
+
+// configCommandRequest describes the request a configured command expands
+// to, in the working directory its run was inferred to when there is one.
+func configCommandRequest(cmd *config.Command, workDir string, result *types.CommandExecutionResult) types.CommandExecutionRequest {
+	req := types.CommandExecutionRequest{
+		Command: cmd.Command,
+		Args:    cmd.Args,
+		WorkDir: workDir,
+		Timeout: cmd.Timeout.String(),
+	}
+	switch {
+	case result != nil && result.WorkDir != "":
+		req.WorkDir = result.WorkDir
+	case cmd.WorkDir != "" && (cmd.WorkDirMode == "" || workDir == ""):
+		req.WorkDir = cmd.WorkDir
+	}
+	for k, v := range cmd.Env {
+		req.Env = append(req.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	return req
+}
@@ -0,0 +1,245 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/internal/security"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// State is a stage of the server lifecycle. A server moves from idle
+// through starting, running and stopping to stopped, and may be run again
+// once stopped.
+type State int
+
+// Server lifecycle states.
+const (
+	StateIdle     State = iota // Created, not run yet
+	StateStarting              // Setting up the transport
+	StateRunning               // Serving requests
+	StateStopping              // Shutdown requested, finishing requests
+	StateStopped               // Run has returned
+)
+
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// ToolCallEvent describes a finished tool call.
+type ToolCallEvent struct {
+	Tool     string
+	Session  string
+	Client   string
+	Started  time.Time
+	Duration time.Duration
+	Failed   bool   // The call returned an error or an error result
+	Error    string // The error, if the call returned one
+}
+
+// hooks are the lifecycle subscriptions of embedders.
+type hooks struct {
+	mu         sync.Mutex
+	nextID     int
+	onStart    map[int]func()
+	onShutdown map[int]func(error)
+	onToolCall map[int]func(ToolCallEvent)
+}
+
+// subscribe adds fn to subs and returns a function removing it.
+func subscribe[F any](h *hooks, subs *map[int]F, fn F) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if *subs == nil {
+		*subs = make(map[int]F)
+	}
+	h.nextID++
+	id := h.nextID
+	(*subs)[id] = fn
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(*subs, id)
+	}
+}
+
+// snapshot returns the functions subscribed, so they are called without
+// holding the lock.
+func snapshot[F any](h *hooks, subs *map[int]F) []F {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fns := make([]F, 0, len(*subs))
+	for _, fn := range *subs {
+		fns = append(fns, fn)
+	}
+	return fns
+}
+
+// OnStart registers fn to be called each time the server starts serving
+// requests. It returns a function cancelling the subscription.
+func (s *Server) OnStart(fn func()) func() {
+	return subscribe(&s.hooks, &s.hooks.onStart, fn)
+}
+
+// OnShutdown registers fn to be called each time Run returns, with the
+// error it returns. It returns a function cancelling the subscription.
+func (s *Server) OnShutdown(fn func(error)) func() {
+	return subscribe(&s.hooks, &s.hooks.onShutdown, fn)
+}
+
+// OnToolCall registers fn to be called after every tool call. It is
+// called on the request goroutine, so it should return quickly. It
+// returns a function cancelling the subscription.
+func (s *Server) OnToolCall(fn func(ToolCallEvent)) func() {
+	return subscribe(&s.hooks, &s.hooks.onToolCall, fn)
+}
+
+// State returns the lifecycle state of the server.
+func (s *Server) State() State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+// begin moves an idle or stopped server to starting. cancel stops the
+// run.
+func (s *Server) begin(cancel context.CancelFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != StateIdle && s.state != StateStopped {
+		return apperrors.InternalError("server is already running")
+	}
+	s.state = StateStarting
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	return nil
+}
+
+// started moves a starting server to running and notifies subscribers,
+// unless it was stopped meanwhile.
+func (s *Server) started() {
+	s.mu.Lock()
+	if s.state != StateStarting {
+		s.mu.Unlock()
+		return
+	}
+	s.state = StateRunning
+	s.since = time.Now()
+	s.mu.Unlock()
+
+	s.emit(types.EventServerStarted, "server started", nil)
+
+	for _, fn := range snapshot(&s.hooks, &s.hooks.onStart) {
+		fn()
+	}
+}
+
+// stop moves a starting or running server to stopping and cancels its
+// run. It reports whether it did.
+func (s *Server) stop() bool {
+	s.mu.Lock()
+	if s.state != StateStarting && s.state != StateRunning {
+		s.mu.Unlock()
+		return false
+	}
+	s.state = StateStopping
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.mu.Unlock()
+
+	s.emit(types.EventServerStopping, "server stopping", nil)
+	return true
+}
+
+// end moves the server to stopped once Run returns err, and notifies
+// subscribers.
+func (s *Server) end(err error) {
+	s.mu.Lock()
+	s.state = StateStopped
+	s.cancel = nil
+	close(s.done)
+	s.mu.Unlock()
+
+	for _, fn := range snapshot(&s.hooks, &s.hooks.onShutdown) {
+		fn(err)
+	}
+}
+
+// toolCallMiddleware reports finished tool calls to OnToolCall
+// subscribers and counts them for usage analytics, under their names
+// without the tool prefix, with the size of their results. Resource reads
+// are counted with the size of their contents.
+func (s *Server) toolCallMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, ss *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		if p, ok := params.(*mcp.ReadResourceParams); ok {
+			result, err := next(ctx, ss, method, params)
+			if res, ok := result.(*mcp.ReadResourceResult); ok && res != nil && err == nil {
+				size := jsonSize(res)
+				s.returned.Add(size)
+				s.usage.ResourceRead(p.URI, size)
+			}
+			return result, err
+		}
+		p, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if !ok {
+			return next(ctx, ss, method, params)
+		}
+
+		// Tell the client where its commands wait for a slot
+		ctx = executor.WithQueueFunc(ctx, s.queueNotifier(ctx, ss, p.Name, p.GetProgressToken()))
+
+		start := time.Now()
+		result, err := next(ctx, ss, method, params)
+		duration := time.Since(start)
+		failed := err != nil
+		var size int64
+		if res, ok := result.(*mcp.CallToolResult); ok && res != nil {
+			failed = failed || res.IsError
+			size = jsonSize(res)
+			s.returned.Add(size)
+		}
+		s.usage.ToolCall(strings.TrimPrefix(p.Name, s.config.Server.ToolPrefix), duration, failed, size)
+		s.telemetry.ToolCall(strings.TrimPrefix(p.Name, s.config.Server.ToolPrefix), failed)
+
+		subs := snapshot(&s.hooks, &s.hooks.onToolCall)
+		if len(subs) == 0 {
+			return result, err
+		}
+		sc := security.FromContext(ctx)
+		event := ToolCallEvent{
+			Tool:     p.Name,
+			Session:  sc.SessionID,
+			Client:   sc.Client(),
+			Started:  start,
+			Duration: duration,
+			Failed:   failed,
+		}
+		if err != nil {
+			event.Error = err.Error()
+		}
+		for _, fn := range subs {
+			fn(event)
+		}
+		return result, err
+	}
+}
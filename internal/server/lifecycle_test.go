@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_lifecycle(t *testing.T) {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	srv, err := New(Options{Config: config.Default(), Transport: serverTransport})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var events []string
+	var calls []ToolCallEvent
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	started := make(chan struct{})
+	srv.OnStart(func() {
+		record("start")
+		close(started)
+	})
+	srv.OnShutdown(func(err error) { record("shutdown") })
+	cancel := srv.OnToolCall(func(e ToolCallEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, e)
+	})
+
+	if got := srv.State(); got != StateIdle {
+		t.Errorf("State() = %s, want idle", got)
+	}
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- srv.Run(context.Background()) }()
+	<-started
+	if got := srv.State(); got != StateRunning {
+		t.Errorf("State() = %s, want running", got)
+	}
+
+	ctx := context.Background()
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil).Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("client connect error = %v", err)
+	}
+	defer cs.Close()
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "execute_command", Arguments: map[string]any{"command": "rm"}}); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	cancel()
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "execute_command", Arguments: map[string]any{"command": "echo"}}); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+
+	// Shutdown returns once Run has
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-runErr; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+	if got := srv.State(); got != StateStopped {
+		t.Errorf("State() = %s, want stopped", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 || events[0] != "start" || events[1] != "shutdown" {
+		t.Errorf("events = %v, want [start shutdown]", events)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("got %d tool call events, want 1 before unsubscribing", len(calls))
+	}
+	if calls[0].Tool != "execute_command" || !calls[0].Failed {
+		t.Errorf("unexpected tool call event %+v", calls[0])
+	}
+}
+
+func TestServer_Drain(t *testing.T) {
+	serverTransport, _ := mcp.NewInMemoryTransports()
+	srv, err := New(Options{Config: config.Default(), Transport: serverTransport})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	started := make(chan struct{})
+	srv.OnStart(func() { close(started) })
+	runErr := make(chan error, 1)
+	go func() { runErr <- srv.Run(context.Background()) }()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if err := <-runErr; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+	if got := srv.State(); got != StateStopped {
+		t.Errorf("State() = %s, want stopped", got)
+	}
+
+	// Executions are allowed again once drained
+	if srv.executor.SwitchStates()["paused"] {
+		t.Error("executions still paused after draining")
+	}
+	var drained bool
+	for _, event := range srv.events.list() {
+		drained = drained || event.Type == types.EventServerDraining
+	}
+	if !drained {
+		t.Error("no server_draining event")
+	}
+}
@@ -0,0 +1,384 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/diff"
+	"github.com/mjmorales/simple-mcp-runner/internal/lint"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Limits of linter and formatter results.
+const (
+	maxDiagnostics     = 500
+	maxFormatFiles     = 200
+	maxFormatFileSize  = 1 << 20
+	maxFormatDiffLines = 500
+	maxToolOutput      = 4096
+)
+
+// RunLinterParams represents parameters for linting a project.
+type RunLinterParams struct {
+	WorkDir string   `json:"workdir"`          // Absolute directory inside the project
+	Linter  string   `json:"linter,omitempty"` // Detected when empty
+	Paths   []string `json:"paths,omitempty"`  // Files or directories to lint, relative to workdir
+	Timeout string   `json:"timeout,omitempty"`
+}
+
+// FormatCodeParams represents parameters for formatting a project's code.
+type FormatCodeParams struct {
+	WorkDir   string   `json:"workdir"`             // Absolute directory inside the project
+	Formatter string   `json:"formatter,omitempty"` // Detected when empty
+	Paths     []string `json:"paths,omitempty"`     // Files or directories to format, relative to workdir
+	Check     bool     `json:"check,omitempty"`     // List the files that would change without changing them
+	Timeout   string   `json:"timeout,omitempty"`
+}
+
+// checkLintPaths validates the workdir and paths of a linter or formatter
+// run against the path policy.
+func (s *Server) checkLintPaths(workDir string, paths []string) string {
+	if workDir == "" || !filepath.IsAbs(workDir) {
+		return s.msg.T("workdir must be an absolute path")
+	}
+	if !s.config.IsPathAllowed(workDir) {
+		return s.msg.Sprintf("path not allowed: %s", workDir)
+	}
+	for _, p := range paths {
+		if p == "" || strings.HasPrefix(p, "-") {
+			return s.msg.Sprintf("Invalid path: %s", p)
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(workDir, p)
+		}
+		if !s.config.IsPathAllowed(p) {
+			return s.msg.Sprintf("path not allowed: %s", p)
+		}
+	}
+	return ""
+}
+
+// runLintCommand runs a linter or formatter command through the executor
+// and records it under tool. Output spilled to files is read back, up to
+// maxParsedOutput bytes, for parsing.
+func (s *Server) runLintCommand(ctx context.Context, tool, name string, args []string, workDir, timeout string) (*types.CommandExecutionResult, error) {
+	req := types.CommandExecutionRequest{
+		Command: name,
+		Args:    args,
+		WorkDir: workDir,
+		Timeout: timeout,
+	}
+	s.logger.Info("running "+tool,
+		"command", req.Command,
+		"args", req.Args,
+		"workdir", req.WorkDir,
+	)
+	result, err := s.executor.Execute(ctx, &req)
+	result = s.recordExecution(ctx, tool, req, result, err)
+	if err != nil {
+		s.logger.WithError(err).Error(tool + " failed")
+		return nil, err
+	}
+	result.Stdout, _ = parsedOutput(result.Stdout, result.StdoutFile)
+	result.Stderr, _ = parsedOutput(result.Stderr, result.StderrFile)
+	return result, nil
+}
+
+// outputTail returns the end of a run's output, for runs that failed
+// without results.
+func outputTail(result *types.CommandExecutionResult) string {
+	out := strings.TrimSpace(strings.TrimSpace(result.Stderr) + "\n" + strings.TrimSpace(result.Stdout))
+	if len(out) > maxToolOutput {
+		out = "..." + out[len(out)-maxToolOutput:]
+	}
+	return out
+}
+
+// lintError returns the error result of a linter or formatter run that
+// could not start.
+func lintError[T any](text string) *mcp.CallToolResultFor[T] {
+	return &mcp.CallToolResultFor[T]{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+		IsError: true,
+	}
+}
+
+// runLinter runs the linter of the project containing a workdir and
+// parses its diagnostics.
+func (s *Server) runLinter(ctx context.Context, params RunLinterParams) (*mcp.CallToolResultFor[lint.Report], error) {
+	if msg := s.checkLintPaths(params.WorkDir, params.Paths); msg != "" {
+		return lintError[lint.Report](msg), nil
+	}
+
+	tool := lint.Tool{Name: params.Linter}
+	if tool.Name == "" {
+		var err error
+		if tool, err = lint.DetectLinter(s.config, params.WorkDir); err != nil {
+			return lintError[lint.Report](s.msg.Sprintf("Could not detect the linter: %s", err.Error())), nil
+		}
+	} else {
+		tool.Root = lint.Root(s.config, params.WorkDir, tool.Name, false)
+	}
+	name, args, err := lint.LintCommand(tool, params.Paths)
+	if err != nil {
+		return lintError[lint.Report](err.Error()), nil
+	}
+
+	result, err := s.runLintCommand(ctx, "run_linter", name, args, params.WorkDir, params.Timeout)
+	if err != nil {
+		return lintError[lint.Report](s.msg.Sprintf("Command execution failed: %s", err.Error())), nil
+	}
+
+	report := lint.Report{
+		Linter:      tool.Name,
+		Command:     name,
+		Args:        args,
+		WorkDir:     params.WorkDir,
+		ExitCode:    result.ExitCode,
+		Diagnostics: []lint.Diagnostic{},
+		HistoryID:   result.HistoryID,
+		Duration:    result.Duration,
+	}
+	for _, d := range lint.ParseLint(tool.Name, params.WorkDir, result.Stdout, result.Stderr) {
+		// Diagnostics of files outside the allowed paths are left out
+		if path := d.File; path != "" && !s.config.IsPathAllowed(absPath(params.WorkDir, path)) {
+			continue
+		}
+		if len(report.Diagnostics) == maxDiagnostics {
+			report.Truncated = true
+			break
+		}
+		report.Diagnostics = append(report.Diagnostics, d)
+	}
+	report.Clean = result.ExitCode == 0 && !result.TimedOut && len(report.Diagnostics) == 0
+	if !report.Clean && len(report.Diagnostics) == 0 {
+		report.Output = outputTail(result)
+	}
+
+	return &mcp.CallToolResultFor[lint.Report]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: s.formatLintReport(&report)}},
+		StructuredContent: report,
+	}, nil
+}
+
+// absPath returns path made absolute against workDir.
+func absPath(workDir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(workDir, path)
+}
+
+// formatLintReport lists a linter's diagnostics.
+func (s *Server) formatLintReport(report *lint.Report) string {
+	var b strings.Builder
+	switch {
+	case report.Clean:
+		b.WriteString(s.msg.Sprintf("No problems found by %s", report.Linter) + "\n")
+	case len(report.Diagnostics) == 0:
+		b.WriteString(s.msg.Sprintf("%s failed with exit code %d", report.Linter, report.ExitCode) + "\n" + report.Output + "\n")
+	default:
+		b.WriteString(s.msg.Sprintf("%d problems found by %s", len(report.Diagnostics), report.Linter) + "\n")
+	}
+	for _, d := range report.Diagnostics {
+		location := d.File
+		if d.Line > 0 {
+			location += fmt.Sprintf(":%d", d.Line)
+			if d.Column > 0 {
+				location += fmt.Sprintf(":%d", d.Column)
+			}
+		}
+		if location != "" {
+			b.WriteString(location + ": ")
+		}
+		if d.Severity != "" {
+			b.WriteString(d.Severity + ": ")
+		}
+		b.WriteString(d.Message)
+		if d.Rule != "" {
+			fmt.Fprintf(&b, " [%s]", d.Rule)
+		}
+		b.WriteString("\n")
+	}
+	if report.Truncated {
+		b.WriteString("... (more problems not shown)\n")
+	}
+	return b.String()
+}
+
+// formatCode runs the formatter of the project containing a workdir:
+// the files it would change are listed first, then those inside the
+// allowed paths are formatted and diffed against their previous content.
+func (s *Server) formatCode(ctx context.Context, params FormatCodeParams) (*mcp.CallToolResultFor[lint.FormatReport], error) {
+	if msg := s.checkLintPaths(params.WorkDir, params.Paths); msg != "" {
+		return lintError[lint.FormatReport](msg), nil
+	}
+
+	tool := lint.Tool{Name: params.Formatter}
+	if tool.Name == "" {
+		var err error
+		if tool, err = lint.DetectFormatter(s.config, params.WorkDir); err != nil {
+			return lintError[lint.FormatReport](s.msg.Sprintf("Could not detect the formatter: %s", err.Error())), nil
+		}
+	} else {
+		tool.Root = lint.Root(s.config, params.WorkDir, tool.Name, true)
+	}
+	name, args, err := lint.CheckCommand(tool, params.Paths)
+	if err != nil {
+		return lintError[lint.FormatReport](err.Error()), nil
+	}
+
+	result, err := s.runLintCommand(ctx, "format_code", name, args, params.WorkDir, params.Timeout)
+	if err != nil {
+		return lintError[lint.FormatReport](s.msg.Sprintf("Command execution failed: %s", err.Error())), nil
+	}
+
+	report := lint.FormatReport{
+		Formatter:  tool.Name,
+		WorkDir:    params.WorkDir,
+		Check:      params.Check,
+		Files:      []lint.FormattedFile{},
+		HistoryIDs: []string{result.HistoryID},
+	}
+	listed := lint.ListFiles(tool.Name, params.WorkDir, result.Stdout, result.Stderr, params.Paths)
+	if len(listed) == 0 && result.ExitCode != 0 {
+		// The formatter failed, e.g. on a syntax error
+		report.Output = outputTail(result)
+		return &mcp.CallToolResultFor[lint.FormatReport]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: s.formatFormatReport(&report)}},
+			StructuredContent: report,
+			IsError:           true,
+		}, nil
+	}
+	if len(listed) > maxFormatFiles {
+		listed, report.Truncated = listed[:maxFormatFiles], true
+	}
+
+	// Files outside the allowed paths, and files too large to diff, are
+	// not formatted
+	var files []string
+	before := make(map[string]string)
+	for _, file := range listed {
+		f := lint.FormattedFile{Path: file}
+		path := absPath(params.WorkDir, file)
+		info, err := os.Stat(path)
+		switch {
+		case !s.config.IsPathAllowed(path):
+			f.Skipped = "path not allowed"
+		case err != nil || !info.Mode().IsRegular():
+			f.Skipped = "not a regular file"
+		case info.Size() > maxFormatFileSize:
+			f.Skipped = "file too large"
+		}
+		if f.Skipped == "" && !params.Check {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				f.Skipped = "file not readable"
+			} else {
+				before[file] = string(data)
+				files = append(files, file)
+			}
+		}
+		report.Files = append(report.Files, f)
+	}
+
+	if len(files) > 0 {
+		name, args, err := lint.FormatCommand(tool, files)
+		if err != nil {
+			return lintError[lint.FormatReport](err.Error()), nil
+		}
+		result, err := s.runLintCommand(ctx, "format_code", name, args, params.WorkDir, params.Timeout)
+		if err != nil {
+			return lintError[lint.FormatReport](s.msg.Sprintf("Command execution failed: %s", err.Error())), nil
+		}
+		report.HistoryIDs = append(report.HistoryIDs, result.HistoryID)
+		if result.ExitCode != 0 {
+			report.Output = outputTail(result)
+		}
+
+		changed := report.Files[:0]
+		for _, f := range report.Files {
+			if old, ok := before[f.Path]; ok {
+				data, err := os.ReadFile(absPath(params.WorkDir, f.Path))
+				if err != nil {
+					continue
+				}
+				d := diff.Lines(old, string(data), 3, maxFormatDiffLines)
+				if d.Identical {
+					continue
+				}
+				f.Added, f.Removed = d.Added, d.Removed
+				f.Diff = diff.Unified(d)
+				if d.Truncated {
+					f.Diff += "...\n"
+				}
+			}
+			changed = append(changed, f)
+		}
+		report.Files = changed
+	}
+
+	return &mcp.CallToolResultFor[lint.FormatReport]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: s.formatFormatReport(&report)}},
+		StructuredContent: report,
+	}, nil
+}
+
+// formatFormatReport renders a formatter's changes as a unified diff.
+func (s *Server) formatFormatReport(report *lint.FormatReport) string {
+	var b strings.Builder
+	switch {
+	case len(report.Files) == 0 && report.Output != "":
+		b.WriteString(s.msg.Sprintf("%s failed", report.Formatter) + "\n" + report.Output + "\n")
+		return b.String()
+	case len(report.Files) == 0:
+		b.WriteString(s.msg.Sprintf("All files are formatted according to %s", report.Formatter) + "\n")
+		return b.String()
+	case report.Check:
+		b.WriteString(s.msg.Sprintf("%d files would be reformatted by %s", len(report.Files), report.Formatter) + "\n")
+	default:
+		b.WriteString(s.msg.Sprintf("%d files reformatted by %s", len(report.Files), report.Formatter) + "\n")
+	}
+	for _, f := range report.Files {
+		switch {
+		case f.Skipped != "":
+			fmt.Fprintf(&b, "%s (skipped: %s)\n", f.Path, f.Skipped)
+		case f.Diff != "":
+			fmt.Fprintf(&b, "--- %s\n+++ %s\n%s", f.Path, f.Path, f.Diff)
+		default:
+			b.WriteString(f.Path + "\n")
+		}
+	}
+	if report.Truncated {
+		b.WriteString("... (more files not shown)\n")
+	}
+	if report.Output != "" {
+		b.WriteString(report.Output + "\n")
+	}
+	return b.String()
+}
+
+// registerLintTools registers the linter and formatter tools.
+func (s *Server) registerLintTools() error {
+	addTool(s, &mcp.Tool{
+		Name:        "run_linter",
+		Description: "Run the linter of the project containing an absolute workdir and get its diagnostics as JSON: file, line, column, severity, rule and message. The linter (golangci-lint, go-vet, clippy, eslint, ruff or flake8) is detected from the project's configuration unless given; set paths to lint some files or directories. The run goes through the same policy as execute_command, and its raw output is recorded under history_id.",
+	}, func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[RunLinterParams]) (*mcp.CallToolResultFor[lint.Report], error) {
+		return s.runLinter(ctx, params.Arguments)
+	})
+
+	addTool(s, &mcp.Tool{
+		Name:        "format_code",
+		Description: "Format the code of the project containing an absolute workdir and get the changes as unified diffs per file. The formatter (gofmt, rustfmt, prettier, ruff or black) is detected from the project's configuration unless given; set paths to format some files or directories, and check to only list the files that would change. Only files inside the allowed paths are changed, and the runs go through the same policy as execute_command.",
+	}, func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[FormatCodeParams]) (*mcp.CallToolResultFor[lint.FormatReport], error) {
+		return s.formatCode(ctx, params.Arguments)
+	})
+
+	s.logger.Debug("registered lint tools")
+
+	return nil
+}
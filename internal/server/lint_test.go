@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/lint"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_lintTools(t *testing.T) {
+	if _, err := exec.LookPath("gofmt"); err != nil {
+		t.Skip("go not available")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":      "module example.com/m\n\ngo 1.21\n",
+		"main.go":     "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Printf(\"%d\\n\", \"x\")\n}\n",
+		"util.go":     "package main\n\nfunc  add(a,b int) int {\nreturn a+b\n}\n",
+		"gen/code.go": "package gen\n\nvar  X = 1\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Security.AllowedPaths = []string{dir}
+	cfg.Security.DeniedPaths = []string{filepath.Join(dir, "gen")}
+	cfg.Execution.DefaultTimeout = config.Duration(2 * time.Minute)
+	_, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	call := func(name string, args map[string]any, out any) *mcp.CallToolResult {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+		if err != nil {
+			t.Fatalf("%s error = %v", name, err)
+		}
+		if data, err := json.Marshal(res.StructuredContent); err == nil {
+			json.Unmarshal(data, out)
+		}
+		return res
+	}
+
+	var report lint.Report
+	if res := call("run_linter", map[string]any{"workdir": dir, "paths": []string{"."}}, &report); res.IsError {
+		t.Fatalf("run_linter = %v", res.Content)
+	}
+	if report.Linter != lint.LinterGoVet || report.Clean || len(report.Diagnostics) != 1 {
+		t.Fatalf("run_linter = %+v", report)
+	}
+	if d := report.Diagnostics[0]; d.File != "main.go" || d.Line != 6 || !strings.Contains(d.Message, "Printf") {
+		t.Errorf("diagnostic = %+v", d)
+	}
+
+	// Check lists the files without changing them
+	var formatted lint.FormatReport
+	if res := call("format_code", map[string]any{"workdir": dir, "check": true}, &formatted); res.IsError {
+		t.Fatalf("format_code = %v", res.Content)
+	}
+	if !formatted.Check || len(formatted.Files) != 2 {
+		t.Errorf("format_code(check) = %+v", formatted)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "util.go")); string(data) != files["util.go"] {
+		t.Error("format_code(check) changed util.go")
+	}
+
+	formatted = lint.FormatReport{}
+	if res := call("format_code", map[string]any{"workdir": dir}, &formatted); res.IsError {
+		t.Fatalf("format_code = %v", res.Content)
+	}
+	got := make(map[string]lint.FormattedFile)
+	for _, f := range formatted.Files {
+		got[filepath.ToSlash(f.Path)] = f
+	}
+	if f := got["util.go"]; f.Skipped != "" || !strings.Contains(f.Diff, "+func add(a, b int) int {") || f.Added == 0 {
+		t.Errorf("util.go = %+v", f)
+	}
+	if f := got["gen/code.go"]; f.Skipped == "" {
+		t.Errorf("gen/code.go = %+v, want it skipped as denied", f)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "gen", "code.go")); string(data) != files["gen/code.go"] {
+		t.Error("format_code changed a denied file")
+	}
+	if len(formatted.HistoryIDs) != 2 {
+		t.Errorf("history_ids = %v, want the check and format runs", formatted.HistoryIDs)
+	}
+
+	// Paths cannot pass options or leave the allowed paths
+	for _, paths := range [][]string{{"-w"}, {"../"}} {
+		if res := call("format_code", map[string]any{"workdir": dir, "paths": paths}, &formatted); !res.IsError {
+			t.Errorf("format_code(paths %v) = %v, want an error result", paths, res.Content)
+		}
+	}
+}
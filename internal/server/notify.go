@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/notify"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// NotifyUserParams represents parameters for a desktop notification.
+type NotifyUserParams struct {
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message"`
+	Urgency string `json:"urgency,omitempty"` // low, normal or critical
+}
+
+// registerNotifyTool registers the desktop notification tool unless
+// notifications are disabled.
+func (s *Server) registerNotifyTool() error {
+	if s.config.Notifications.Disabled {
+		s.logger.Debug("desktop notifications disabled")
+		return nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "notify_user",
+		Description: "Show a native desktop notification to the user, e.g. when a long-running task finishes or needs attention. Notifications are rate limited; use sparingly.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[NotifyUserParams]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		err := s.notifier.Notify(ctx, notify.Notification{
+			Title:   args.Title,
+			Message: args.Message,
+			Urgency: args.Urgency,
+		})
+		if err != nil {
+			s.logger.WithError(err).Warn("notification failed")
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Notification failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Notification shown"}},
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered notify tool")
+
+	return nil
+}
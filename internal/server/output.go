@@ -0,0 +1,195 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// GetOutputPageParams represents parameters for reading recorded output.
+type GetOutputPageParams struct {
+	HistoryID string `json:"history_id"`
+	Stream    string `json:"stream,omitempty"` // stdout (default) or stderr
+	Offset    int    `json:"offset,omitempty"` // 0-based line to start at
+	MaxLines  int    `json:"max_lines,omitempty"`
+	MaxBytes  int    `json:"max_bytes,omitempty"`
+	Pattern   string `json:"pattern,omitempty"` // Only lines matching this regular expression
+}
+
+// Limits of output pages.
+const (
+	defaultOutputPageLines = 200
+	defaultOutputPageBytes = 64 << 10
+	maxOutputPageBytes     = 1 << 20
+)
+
+// outputPageQuery selects the lines of an output page.
+type outputPageQuery struct {
+	offset   int
+	maxLines int
+	maxBytes int
+	pattern  *regexp.Regexp // Nil for every line
+}
+
+// readOutputPage reads the lines selected by q from r into page.
+func readOutputPage(r io.Reader, q outputPageQuery, page *types.OutputPage) error {
+	br := bufio.NewReader(r)
+	used := 0
+	for n := 0; ; n++ {
+		text, truncated, err := readLine(br, q.maxBytes)
+		if errors.Is(err, io.EOF) && text == "" {
+			page.NextOffset, page.EOF = n, true
+			return nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		if n >= q.offset && (q.pattern == nil || q.pattern.MatchString(text)) {
+			if len(page.Lines) >= q.maxLines || (len(page.Lines) > 0 && used+len(text) > q.maxBytes) {
+				page.NextOffset = n
+				return nil
+			}
+			page.Lines = append(page.Lines, types.OutputLine{Number: n, Text: text, Truncated: truncated})
+			used += len(text)
+		}
+
+		if err != nil {
+			page.NextOffset, page.EOF = n+1, true
+			return nil
+		}
+	}
+}
+
+// readLine reads a line, without its line ending, keeping at most limit
+// bytes of it and skipping the rest.
+func readLine(br *bufio.Reader, limit int) (string, bool, error) {
+	var b strings.Builder
+	truncated := false
+	for {
+		part, isPrefix, err := br.ReadLine()
+		if err != nil {
+			return b.String(), truncated, err
+		}
+		if room := limit - b.Len(); len(part) > room {
+			part = part[:max(room, 0)]
+			truncated = true
+		}
+		b.Write(part)
+		if !isPrefix {
+			return strings.ToValidUTF8(b.String(), "�"), truncated, nil
+		}
+	}
+}
+
+// openOutput returns the output of a stream of an execution record: the
+// spill file holding the whole output when it is still there, otherwise
+// the recorded output. Spill files are only read from the spill directory.
+func (s *Server) openOutput(result *types.CommandExecutionResult, stream string) (io.ReadCloser, bool) {
+	output, file := result.Stdout, result.StdoutFile
+	if stream == "stderr" {
+		output, file = result.Stderr, result.StderrFile
+	}
+	if file != "" {
+		rel, err := filepath.Rel(s.executor.SpillDir(), file)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			if f, err := os.Open(file); err == nil {
+				return f, true
+			}
+		}
+	}
+	return io.NopCloser(strings.NewReader(output)), false
+}
+
+// formatOutputPage renders an output page as text, each line prefixed by
+// its line number.
+func formatOutputPage(page *types.OutputPage) string {
+	var b strings.Builder
+	for _, line := range page.Lines {
+		fmt.Fprintf(&b, "%d: %s\n", line.Number, line.Text)
+	}
+	if page.EOF {
+		b.WriteString("(end of output)")
+	} else {
+		fmt.Fprintf(&b, "(more lines from offset %d)", page.NextOffset)
+	}
+	return b.String()
+}
+
+// registerOutputPageTool registers the tool paging through recorded
+// output.
+func (s *Server) registerOutputPageTool() error {
+	tool := &mcp.Tool{
+		Name:        "get_output_page",
+		Description: "Read the output of a recorded execution by its history_id one page of lines at a time, including output beyond what the result returned when it was spilled to a file. Start at a line offset, limit the page with max_lines and max_bytes, and pass a regular expression as pattern to only return matching lines, e.g. to jump to the errors of a huge log. Lines carry their numbers; pass next_offset as offset to continue.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[GetOutputPageParams]) (*mcp.CallToolResultFor[types.OutputPage], error) {
+		args := params.Arguments
+		fail := func(text string) (*mcp.CallToolResultFor[types.OutputPage], error) {
+			return &mcp.CallToolResultFor[types.OutputPage]{
+				Content: []mcp.Content{&mcp.TextContent{Text: text}},
+				IsError: true,
+			}, nil
+		}
+
+		stream := args.Stream
+		if stream == "" {
+			stream = "stdout"
+		}
+		if stream != "stdout" && stream != "stderr" {
+			return fail(s.msg.Sprintf("Invalid stream %q: must be stdout or stderr", stream))
+		}
+		q := outputPageQuery{offset: max(args.Offset, 0), maxLines: args.MaxLines, maxBytes: args.MaxBytes}
+		if q.maxLines <= 0 {
+			q.maxLines = defaultOutputPageLines
+		}
+		if q.maxBytes <= 0 {
+			q.maxBytes = defaultOutputPageBytes
+		}
+		q.maxBytes = min(q.maxBytes, maxOutputPageBytes)
+		if args.Pattern != "" {
+			re, err := regexp.Compile(args.Pattern)
+			if err != nil {
+				return fail(s.msg.Sprintf("Invalid pattern: %s", err.Error()))
+			}
+			q.pattern = re
+		}
+
+		rec, ok := s.history.Get(args.HistoryID)
+		if !ok {
+			return fail(s.msg.Sprintf("Unknown execution: %s", args.HistoryID))
+		}
+		if rec.Result == nil {
+			return fail(s.msg.Sprintf("Execution %s has no output", args.HistoryID))
+		}
+
+		r, spilled := s.openOutput(rec.Result, stream)
+		defer r.Close()
+		page := types.OutputPage{HistoryID: rec.ID, Stream: stream, Lines: []types.OutputLine{}, Spilled: spilled}
+		if err := readOutputPage(r, q, &page); err != nil {
+			s.logger.WithError(err).Warn("failed to read output", "history_id", rec.ID, "stream", stream)
+			return fail(s.msg.Sprintf("Failed to read output: %s", err.Error()))
+		}
+
+		return &mcp.CallToolResultFor[types.OutputPage]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatOutputPage(&page)}},
+			StructuredContent: page,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered output page tool")
+
+	return nil
+}
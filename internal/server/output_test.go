@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestReadOutputPage(t *testing.T) {
+	output := "one\ntwo\nerror: three\nfour\nerror: " + strings.Repeat("x", 20) + "\nsix"
+	read := func(q outputPageQuery) types.OutputPage {
+		t.Helper()
+		var page types.OutputPage
+		if err := readOutputPage(strings.NewReader(output), q, &page); err != nil {
+			t.Fatalf("readOutputPage() error = %v", err)
+		}
+		return page
+	}
+
+	page := read(outputPageQuery{offset: 1, maxLines: 2, maxBytes: 100})
+	if len(page.Lines) != 2 || page.Lines[0].Text != "two" || page.Lines[1].Number != 2 || page.NextOffset != 3 || page.EOF {
+		t.Errorf("page = %+v", page)
+	}
+
+	page = read(outputPageQuery{maxLines: 10, maxBytes: 10, pattern: regexp.MustCompile(`^error`)})
+	if len(page.Lines) != 1 || page.Lines[0].Number != 2 || page.NextOffset != 4 || page.EOF {
+		t.Errorf("filtered page = %+v", page)
+	}
+	page = read(outputPageQuery{offset: page.NextOffset, maxLines: 10, maxBytes: 10, pattern: regexp.MustCompile(`^error`)})
+	if len(page.Lines) != 1 || !page.Lines[0].Truncated || len(page.Lines[0].Text) != 10 || !page.EOF || page.NextOffset != 6 {
+		t.Errorf("filtered page = %+v", page)
+	}
+
+	page = read(outputPageQuery{offset: 10, maxLines: 10, maxBytes: 100})
+	if len(page.Lines) != 0 || !page.EOF {
+		t.Errorf("page past the end = %+v", page)
+	}
+}
+
+func TestServer_getOutputPage(t *testing.T) {
+	cfg := config.Default()
+	cfg.Execution.SpillThreshold = 64
+	cfg.Execution.SpillDir = t.TempDir()
+	_, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "execute_command", Arguments: map[string]any{"command": "seq", "args": []string{"1", "500"}}})
+	if err != nil || res.IsError {
+		t.Fatalf("execute_command = %v, %v", res, err)
+	}
+	result, _ := res.StructuredContent.(map[string]any)
+	id, _ := result["history_id"].(string)
+	if result["stdout_file"] == nil {
+		t.Fatalf("expected the output to be spilled: %v", result)
+	}
+
+	page := func(args map[string]any) (*mcp.CallToolResult, types.OutputPage) {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "get_output_page", Arguments: args})
+		if err != nil {
+			t.Fatalf("get_output_page error = %v", err)
+		}
+		var p types.OutputPage
+		if data, err := json.Marshal(res.StructuredContent); err == nil {
+			json.Unmarshal(data, &p)
+		}
+		return res, p
+	}
+
+	res, p := page(map[string]any{"history_id": id, "pattern": "^49[0-9]$", "max_lines": 5})
+	if res.IsError || !p.Spilled || len(p.Lines) != 5 || p.Lines[0].Text != "490" || p.Lines[0].Number != 489 || p.EOF {
+		t.Errorf("page = %+v", p)
+	}
+	res, p = page(map[string]any{"history_id": id, "offset": p.NextOffset, "pattern": "^49[0-9]$"})
+	if res.IsError || len(p.Lines) != 5 || p.Lines[0].Text != "495" || !p.EOF {
+		t.Errorf("next page = %+v", p)
+	}
+
+	if res, _ := page(map[string]any{"history_id": id, "pattern": "("}); !res.IsError {
+		t.Error("expected an error result for an invalid pattern")
+	}
+	if res, _ := page(map[string]any{"history_id": "missing"}); !res.IsError {
+		t.Error("expected an error result for an unknown execution")
+	}
+}
+
+func TestServer_outputBudget(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.SessionOutputBudget = 4 << 10
+	_, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	run := func(args ...string) (*mcp.CallToolResult, map[string]any) {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "execute_command", Arguments: map[string]any{"command": "seq", "args": args}})
+		if err != nil || res.IsError {
+			t.Fatalf("execute_command = %v, %v", res, err)
+		}
+		result, _ := res.StructuredContent.(map[string]any)
+		return res, result
+	}
+
+	// Small results fit in the budget
+	if _, result := run("1", "3"); result["stdout"] != "1\n2\n3\n" {
+		t.Fatalf("expected the output inline, got %v", result["stdout"])
+	}
+
+	// The result over the budget leaves its output out
+	res, result := run("1", "1000")
+	id, _ := result["history_id"].(string)
+	text := res.Content[0].(*mcp.TextContent).Text
+	if result["stdout"] != "" || result["exit_code"] != float64(0) || !strings.Contains(text, "get_output_page") || !strings.Contains(text, id) {
+		t.Fatalf("expected the output to be withheld, got %q and %v", text, result)
+	}
+
+	// So does every later one, however small
+	if _, result := run("1", "3"); result["stdout"] != "" {
+		t.Errorf("expected the output to be withheld once the budget is used up, got %v", result["stdout"])
+	}
+
+	// The output is still there to page through
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "get_output_page", Arguments: map[string]any{"history_id": id, "offset": 998}})
+	if err != nil || res.IsError || !strings.Contains(res.Content[0].(*mcp.TextContent).Text, "999: 1000") {
+		t.Errorf("get_output_page = %v, %v", res, err)
+	}
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// outputBudgetMiddleware counts the bytes of tool results returned to each
+// session. Once server.session_output_budget is used up, the output of
+// recorded executions is left out of results, and clients page through it
+// with get_output_page instead. Results of other tools, which are bounded
+// or paged already, are counted but returned as they are.
+func (s *Server) outputBudgetMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, ss *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		result, err := next(ctx, ss, method, params)
+		budget := int64(s.config.Server.SessionOutputBudget)
+		res, ok := result.(*mcp.CallToolResult)
+		if budget == 0 || !ok || res == nil {
+			return result, err
+		}
+		v, ok := s.sessions.Load(ss)
+		if !ok {
+			return result, err
+		}
+		info := v.(*sessionInfo)
+
+		size := jsonSize(res)
+		info.mu.Lock()
+		if info.returned+size <= budget {
+			info.returned += size
+			info.mu.Unlock()
+			return result, err
+		}
+		structured := jsonValue(res.StructuredContent)
+		ids := withholdOutput(structured)
+		if len(ids) == 0 {
+			info.returned += size
+			info.mu.Unlock()
+			return result, err
+		}
+		usedUp := info.returned < budget
+		withheld := &mcp.CallToolResult{
+			Content:           []mcp.Content{&mcp.TextContent{Text: s.outputWithheld(ids)}},
+			StructuredContent: structured,
+			IsError:           res.IsError,
+		}
+		info.returned = max(info.returned, budget) + jsonSize(withheld)
+		info.mu.Unlock()
+
+		if usedUp {
+			s.outputBudgetUsedUp(info)
+		}
+		return withheld, err
+	}
+}
+
+// budgetNotification counts a log notification carrying tool output, such
+// as a watch change, against the session's output budget. Once the budget
+// is used up, the output of recorded executions is left out of it as it is
+// out of results, and a withheld field says where to read it.
+func (s *Server) budgetNotification(ss *mcp.ServerSession, params *mcp.LoggingMessageParams) {
+	budget := int64(s.config.Server.SessionOutputBudget)
+	if budget == 0 {
+		return
+	}
+	v, ok := s.sessions.Load(ss)
+	if !ok {
+		return
+	}
+	info := v.(*sessionInfo)
+
+	size := jsonSize(params)
+	info.mu.Lock()
+	if info.returned+size <= budget {
+		info.returned += size
+		info.mu.Unlock()
+		return
+	}
+	ids := withholdOutput(params.Data)
+	data, ok := params.Data.(map[string]any)
+	if len(ids) == 0 || !ok {
+		info.returned += size
+		info.mu.Unlock()
+		return
+	}
+	usedUp := info.returned < budget
+	data["withheld"] = s.outputWithheld(ids)
+	info.returned = max(info.returned, budget) + jsonSize(params)
+	info.mu.Unlock()
+
+	if usedUp {
+		s.outputBudgetUsedUp(info)
+	}
+}
+
+// outputWithheld tells the client where to read output left out once its
+// session used up its output budget.
+func (s *Server) outputWithheld(ids []string) string {
+	return s.msg.Sprintf("Output withheld: this session used up its output budget of %s. Read it with get_output_page and history_id %s", s.config.Server.SessionOutputBudget, strings.Join(ids, ", "))
+}
+
+// outputBudgetUsedUp logs and reports a session using up its output budget.
+func (s *Server) outputBudgetUsedUp(info *sessionInfo) {
+	s.logger.Warn("session output budget used up",
+		"session", info.id,
+		"client", info.clientName,
+		"budget", s.config.Server.SessionOutputBudget.String(),
+	)
+	s.emit(types.EventOutputBudget, "session "+info.id+" used up its output budget", map[string]any{
+		"session": info.id,
+		"client":  info.clientName,
+		"budget":  int64(s.config.Server.SessionOutputBudget),
+	})
+}
+
+// jsonSize returns the size of a result or notification as sent to the
+// client.
+func jsonSize(res any) int64 {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
+
+// withholdOutput empties the output of the recorded executions in a
+// structured tool result, such as that of execute_command or each result
+// of a batch, and returns their history IDs.
+func withholdOutput(v any) []string {
+	var ids []string
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			ids = append(ids, withholdOutput(item)...)
+		}
+	case map[string]any:
+		id, _ := v["history_id"].(string)
+		stdout, hasStdout := v["stdout"].(string)
+		stderr, hasStderr := v["stderr"].(string)
+		if id != "" && (hasStdout || hasStderr) {
+			if stdout != "" || stderr != "" {
+				ids = append(ids, id)
+			}
+			if hasStdout {
+				v["stdout"] = ""
+			}
+			if hasStderr {
+				v["stderr"] = ""
+			}
+			return ids
+		}
+		for _, item := range v {
+			ids = append(ids, withholdOutput(item)...)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ExplainPolicyParams represents parameters for explaining the policy.
+type ExplainPolicyParams struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	WorkDir string   `json:"workdir,omitempty"`
+}
+
+// registerPolicyTool registers the policy explanation tool.
+func (s *Server) registerPolicyTool() error {
+	tool := &mcp.Tool{
+		Name:        "explain_policy",
+		Description: "Explain whether the security policy would allow a command with the given args and workdir, without running it. Lists every rule in evaluation order (command length, workdir, blocked commands, allowed commands, denied paths, allowed paths, shell metacharacters, time window and run count conditions) with its outcome, and marks the first rule that denies.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ExplainPolicyParams]) (*mcp.CallToolResultFor[types.PolicyExplanation], error) {
+		args := params.Arguments
+
+		if args.Command == "" {
+			return &mcp.CallToolResultFor[types.PolicyExplanation]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Policy explanation failed: command is required"},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		exp := s.executor.ExplainPolicy(&types.CommandExecutionRequest{
+			Command: args.Command,
+			Args:    args.Args,
+			WorkDir: args.WorkDir,
+		})
+
+		var b strings.Builder
+		b.WriteString(strings.ToUpper(exp.Decision[:1]) + exp.Decision[1:])
+		for _, rule := range exp.Rules {
+			marker := " "
+			if rule.Decisive {
+				marker = ">"
+			}
+			fmt.Fprintf(&b, "\n%s [%s] %s: %s", marker, rule.Outcome, rule.Name, rule.Detail)
+		}
+
+		return &mcp.CallToolResultFor[types.PolicyExplanation]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: b.String()}},
+			StructuredContent: *exp,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered policy tool")
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/process"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ListProcessesParams represents parameters for listing processes.
+type ListProcessesParams struct {
+	Name   string `json:"name,omitempty"`    // Substring of the process name or command line
+	SortBy string `json:"sort_by,omitempty"` // pid, cpu, memory or start
+	Limit  int    `json:"limit,omitempty"`
+}
+
+// GetProcessInfoParams represents parameters for inspecting a process.
+type GetProcessInfoParams struct {
+	PID int32 `json:"pid"`
+}
+
+// ListListeningPortsParams represents parameters for listing listening ports.
+type ListListeningPortsParams struct {
+	Protocol string `json:"protocol,omitempty"` // tcp or udp; both when empty
+	Port     uint32 `json:"port,omitempty"`
+}
+
+// TerminateProcessParams represents parameters for terminating a process.
+type TerminateProcessParams struct {
+	PID   int32 `json:"pid"`
+	Force bool  `json:"force,omitempty"` // Kill instead of asking the process to exit
+}
+
+// ListeningPortsResult lists listening sockets.
+type ListeningPortsResult struct {
+	Listeners []process.Listener `json:"listeners"`
+}
+
+// registerProcessTools registers the process inspection tools.
+func (s *Server) registerProcessTools() error {
+	s.registerListProcessesTool()
+	s.registerGetProcessInfoTool()
+	s.registerListeningPortsTool()
+	s.registerTerminateProcessTool()
+
+	s.logger.Debug("registered process tools")
+
+	return nil
+}
+
+func (s *Server) registerListProcessesTool() {
+	tool := &mcp.Tool{
+		Name:        "list_processes",
+		Description: "List running processes with pid, parent pid, command line, CPU and memory usage, and start time. Only processes owned by the server's user are shown unless the configuration allows all users. Filter with name; order with sort_by (pid, cpu, memory or start).",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ListProcessesParams]) (*mcp.CallToolResultFor[process.ListResult], error) {
+		args := params.Arguments
+
+		result, err := s.processes.List(ctx, process.ListOptions{
+			Name:   args.Name,
+			SortBy: args.SortBy,
+			Limit:  args.Limit,
+		})
+		if err != nil {
+			s.logger.WithError(err).Error("process listing failed")
+			return &mcp.CallToolResultFor[process.ListResult]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Process listing failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[process.ListResult]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatProcessList(result)}},
+			StructuredContent: *result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerGetProcessInfoTool() {
+	tool := &mcp.Tool{
+		Name:        "get_process_info",
+		Description: "Get details of a process by pid: name, command line, owner, status, parent pid, CPU and memory usage, and start time.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[GetProcessInfoParams]) (*mcp.CallToolResultFor[process.Info], error) {
+		info, err := s.processes.Get(ctx, params.Arguments.PID)
+		if err != nil {
+			s.logger.WithError(err).Debug("process inspection failed", "pid", params.Arguments.PID)
+			return &mcp.CallToolResultFor[process.Info]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Process inspection failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[process.Info]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatProcessInfo(info)}},
+			StructuredContent: *info,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerListeningPortsTool() {
+	tool := &mcp.Tool{
+		Name:        "list_listening_ports",
+		Description: "List local listening TCP and UDP sockets with the owning process where permissions allow. Use port to find what holds an address that is already in use.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ListListeningPortsParams]) (*mcp.CallToolResultFor[ListeningPortsResult], error) {
+		listeners, err := s.processes.ListeningPorts(ctx, process.PortOptions{
+			Protocol: params.Arguments.Protocol,
+			Port:     params.Arguments.Port,
+		})
+		if err != nil {
+			s.logger.WithError(err).Error("port listing failed")
+			return &mcp.CallToolResultFor[ListeningPortsResult]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Port listing failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		result := ListeningPortsResult{Listeners: listeners}
+
+		return &mcp.CallToolResultFor[ListeningPortsResult]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatListeners(listeners)}},
+			StructuredContent: result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerTerminateProcessTool() {
+	tool := &mcp.Tool{
+		Name:        "terminate_process",
+		Description: "Stop a process the server started, such as a command run by execute_command or one of its children, instead of running kill. Sends SIGTERM, or kills the process with force, and waits a few seconds for it to exit. Other processes cannot be signalled.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[TerminateProcessParams]) (*mcp.CallToolResultFor[process.Termination], error) {
+		args := params.Arguments
+
+		result, err := s.processes.Terminate(ctx, args.PID, args.Force)
+		if err != nil {
+			s.logger.WithError(err).Warn("process termination failed", "pid", args.PID)
+			return &mcp.CallToolResultFor[process.Termination]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Termination failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+		s.logger.Info("terminated process", "pid", result.PID, "name", result.Name, "signal", result.Signal, "exited", result.Exited)
+
+		text := fmt.Sprintf("Sent %s to %d (%s); it exited", result.Signal, result.PID, result.Name)
+		if !result.Exited {
+			text = fmt.Sprintf("Sent %s to %d (%s); it is still running, terminate it with force to kill it", result.Signal, result.PID, result.Name)
+		}
+
+		return &mcp.CallToolResultFor[process.Termination]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+			StructuredContent: *result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+// formatListeners renders listening sockets as text.
+func formatListeners(listeners []process.Listener) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Listening sockets (%d):\n", len(listeners))
+	for _, l := range listeners {
+		owner := "unknown process"
+		switch {
+		case l.Process != "":
+			owner = fmt.Sprintf("%s (pid %d)", l.Process, l.PID)
+		case l.PID > 0:
+			owner = fmt.Sprintf("pid %d", l.PID)
+		}
+		fmt.Fprintf(&b, "- %s %s: %s\n", l.Protocol, net.JoinHostPort(l.Address, strconv.FormatUint(uint64(l.Port), 10)), owner)
+	}
+
+	return b.String()
+}
+
+// formatProcessList renders a process listing as text.
+func formatProcessList(result *process.ListResult) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Found %d processes", result.Total)
+	if result.Truncated {
+		fmt.Fprintf(&b, " (showing %d)", len(result.Processes))
+	}
+	b.WriteString(":\n")
+
+	for _, p := range result.Processes {
+		cmd := p.Cmdline
+		if cmd == "" {
+			cmd = p.Name
+		}
+		fmt.Fprintf(&b, "- %d %s (cpu %.1f%%, mem %.1f%%)\n", p.PID, cmd, p.CPUPercent, p.MemoryPercent)
+	}
+
+	return b.String()
+}
+
+// formatProcessInfo renders a single process as text.
+func formatProcessInfo(p *process.Info) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "PID: %d\n", p.PID)
+	fmt.Fprintf(&b, "Parent PID: %d\n", p.PPID)
+	fmt.Fprintf(&b, "Name: %s\n", p.Name)
+	if p.Cmdline != "" {
+		fmt.Fprintf(&b, "Command: %s\n", p.Cmdline)
+	}
+	if p.Username != "" {
+		fmt.Fprintf(&b, "User: %s\n", p.Username)
+	}
+	if p.Status != "" {
+		fmt.Fprintf(&b, "Status: %s\n", p.Status)
+	}
+	fmt.Fprintf(&b, "CPU: %.1f%%\n", p.CPUPercent)
+	fmt.Fprintf(&b, "Memory: %d bytes (%.1f%%)\n", p.MemoryRSS, p.MemoryPercent)
+	if !p.StartTime.IsZero() {
+		fmt.Fprintf(&b, "Started: %s\n", p.StartTime.Format(time.RFC3339))
+	}
+
+	return b.String()
+}
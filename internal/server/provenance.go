@@ -0,0 +1,84 @@
+package server
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// binaryVersions caches the versions binaries report, until they change.
+type binaryVersions struct {
+	mu       sync.Mutex
+	versions map[string]binaryVersion
+}
+
+// binaryVersion is the version of a binary as it was when it was asked.
+type binaryVersion struct {
+	modTime time.Time
+	size    int64
+	version string
+}
+
+// get returns the version of the binary at path, asking it with args
+// (--version by default) unless it is cached.
+func (v *binaryVersions) get(path string, args []string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+
+	v.mu.Lock()
+	cached, ok := v.versions[path]
+	v.mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.version
+	}
+
+	version := commandVersion(path, args)
+	v.mu.Lock()
+	if v.versions == nil {
+		v.versions = make(map[string]binaryVersion)
+	}
+	v.versions[path] = binaryVersion{modTime: info.ModTime(), size: info.Size(), version: version}
+	v.mu.Unlock()
+	return version
+}
+
+// configRevision returns the revision of the configuration, computed once
+// per change of the configured commands.
+func (s *Server) configRevision() string {
+	s.revisionMu.Lock()
+	defer s.revisionMu.Unlock()
+	if s.revision == "" {
+		s.commandsMu.RLock()
+		s.revision = s.config.Revision()
+		s.commandsMu.RUnlock()
+	}
+	return s.revision
+}
+
+// provenance describes what ran a request with a tool: the configuration
+// revision and security profile in effect, and the binary it resolved to.
+func (s *Server) provenance(tool string, req types.CommandExecutionRequest) *types.Provenance {
+	var versionArgs []string
+	s.commandsMu.RLock()
+	if cmd := s.config.FindCommand(tool); cmd != nil {
+		versionArgs = cmd.VersionArgs
+	}
+	s.commandsMu.RUnlock()
+
+	security := securityProfile(s.config)
+	p := &types.Provenance{
+		Tool:           tool,
+		ConfigRevision: s.configRevision(),
+		Security:       &security,
+	}
+	if path, err := executor.BinaryPath(req.Command, req.WorkDir); err == nil {
+		p.BinaryPath = path
+		p.BinaryVersion = s.versions.get(path, versionArgs)
+	}
+	return p
+}
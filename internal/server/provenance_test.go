@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_provenance(t *testing.T) {
+	cfg := config.Default()
+	srv, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	run := func() *types.Provenance {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "execute_command", Arguments: map[string]any{"command": "echo", "args": []string{"hi"}}})
+		if err != nil || res.IsError {
+			t.Fatalf("execute_command = %v, %v", res, err)
+		}
+		var result types.CommandExecutionResult
+		data, _ := json.Marshal(res.StructuredContent)
+		if err := json.Unmarshal(data, &result); err != nil || result.Provenance == nil {
+			t.Fatalf("no provenance in %s", data)
+		}
+		return result.Provenance
+	}
+
+	p := run()
+	if p.Tool != "execute_command" || p.ConfigRevision != cfg.Revision() {
+		t.Errorf("unexpected provenance %+v, want revision %s", p, cfg.Revision())
+	}
+	if name := strings.TrimSuffix(filepath.Base(p.BinaryPath), ".exe"); name != "echo" {
+		t.Errorf("binary path = %s, want echo", p.BinaryPath)
+	}
+	if p.Security == nil || p.Security.Policy != config.PolicyEnforce {
+		t.Errorf("unexpected security profile %+v", p.Security)
+	}
+
+	// A catalog update changes the revision of later results
+	srv.updateCommands([]config.Command{{Name: "added", Description: "Added", Command: "echo"}})
+	if revision := run().ConfigRevision; revision == p.ConfigRevision || revision == "" {
+		t.Errorf("expected a new revision after the commands changed, got %s", revision)
+	}
+}
+
+func TestBinaryVersions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	bin := filepath.Join(t.TempDir(), "tool")
+	write := func(version string) {
+		t.Helper()
+		if err := os.WriteFile(bin, []byte("#!/bin/sh\necho tool "+version+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var versions binaryVersions
+	write("1.2.3")
+	if v := versions.get(bin, nil); v != "1.2.3" {
+		t.Fatalf("version = %q, want 1.2.3", v)
+	}
+
+	// A replaced binary is asked again
+	write("1.10.0")
+	if v := versions.get(bin, nil); v != "1.10.0" {
+		t.Errorf("version = %q, want 1.10.0", v)
+	}
+}
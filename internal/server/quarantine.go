@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/quarantine"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ClearQuarantineParams represents parameters for clearing the quarantine
+// of a binary.
+type ClearQuarantineParams struct {
+	Command string `json:"command"` // Command name or path of the binary
+
+	// ApprovalID clears the quarantine under an approved request
+	ApprovalID string `json:"approval_id,omitempty"`
+}
+
+// registerQuarantineTool registers the tool clearing the quarantine of
+// downloaded binaries, on macOS when the configuration allows it.
+func (s *Server) registerQuarantineTool() error {
+	if !quarantine.Supported || !s.config.Security.AllowClearQuarantine {
+		return nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "clear_quarantine",
+		Description: "Remove the macOS quarantine attribute (com.apple.quarantine) of a downloaded binary, by command name or path, so Gatekeeper stops refusing to run it. Only allowed commands can be cleared. Requires approval by two operators: the first call creates an approval request and fails with its ID; call again with approval_id once it is approved.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ClearQuarantineParams]) (*mcp.CallToolResultFor[types.Quarantine], error) {
+		cleared, err := s.executor.ClearQuarantine(ctx, params.Arguments.Command, params.Arguments.ApprovalID)
+		if err != nil {
+			s.logger.WithError(err).Warn("clear quarantine failed", "command", params.Arguments.Command)
+			return &mcp.CallToolResultFor[types.Quarantine]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Clear quarantine failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[types.Quarantine]{
+			Content: []mcp.Content{&mcp.TextContent{
+				Text: fmt.Sprintf("Cleared the quarantine of %s", cleared.Path),
+			}},
+			StructuredContent: *cleared,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered quarantine tool")
+
+	return nil
+}
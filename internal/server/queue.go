@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// queueNotifier returns the function telling a client where a command of
+// its tool call waits for an execution slot: as progress notifications
+// when the call asked for progress, and as log messages otherwise.
+func (s *Server) queueNotifier(ctx context.Context, ss *mcp.ServerSession, tool string, token any) executor.QueueFunc {
+	// Steps of a batch wait in parallel
+	var mu sync.Mutex
+	first := 0
+	return func(position int, wait time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		first = max(first, position)
+		msg := fmt.Sprintf("queued at position %d", position)
+		data := map[string]any{"tool": tool, "position": position}
+		if wait > 0 {
+			msg += fmt.Sprintf(", estimated wait %s", wait.Round(time.Second))
+			data["estimated_wait_ms"] = wait.Milliseconds()
+		}
+
+		var err error
+		if token != nil {
+			// Progress is how far the command moved up the queue
+			err = ss.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: token,
+				Progress:      float64(first - position),
+				Total:         float64(first),
+				Message:       msg,
+			})
+		} else {
+			err = ss.Log(ctx, &mcp.LoggingMessageParams{
+				Level:  "info",
+				Logger: "queue",
+				Data:   data,
+			})
+		}
+		if err != nil {
+			s.logger.WithError(err).Debug("failed to send queue notification", "tool", tool)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// recoverMiddleware turns a panic in a request handler into an internal
+// error, so one bad request does not kill the session. The stack trace is
+// logged and not returned to the client. A panicking tool call gets an
+// error result, like other tool failures.
+func (s *Server) recoverMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, ss *mcp.ServerSession, method string, params mcp.Params) (result mcp.Result, err error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			s.crashes.Add(1)
+
+			var tool string
+			if p, ok := params.(*mcp.CallToolParamsFor[json.RawMessage]); ok {
+				tool = p.Name
+			}
+			s.logger.Error("recovered from panic in request handler",
+				"method", method,
+				"tool", tool,
+				"panic", fmt.Sprint(r),
+				"stack", string(debug.Stack()),
+			)
+
+			appErr := apperrors.InternalError("internal error handling " + method)
+			if tool != "" {
+				result, err = &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{Text: appErr.Error()}},
+					IsError: true,
+				}, nil
+				return
+			}
+			result, err = nil, appErr
+		}()
+		return next(ctx, ss, method, params)
+	}
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_recoverMiddleware(t *testing.T) {
+	srv := newTestServer(t, config.Default())
+
+	mcp.AddTool(srv.mcpServer, &mcp.Tool{Name: "explode"}, func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[struct{}], error) {
+		panic("boom")
+	})
+
+	ctx := context.Background()
+	cs := connectClient(t, srv, nil)
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "explode", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if !res.IsError {
+		t.Fatal("expected an error result")
+	}
+	text := res.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "internal error") || strings.Contains(text, "boom") || strings.Contains(text, "goroutine") {
+		t.Errorf("expected an internal error without panic details, got %q", text)
+	}
+
+	// The session survives
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "execute_command", Arguments: map[string]any{"command": "echo"}}); err != nil {
+		t.Errorf("CallTool() after panic error = %v", err)
+	}
+	if got := srv.GetStats().Crashes; got != 1 {
+		t.Errorf("Crashes = %d, want 1", got)
+	}
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/repl"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// StartREPLParams represents parameters for starting an interpreter.
+type StartREPLParams struct {
+	Interpreter string   `json:"interpreter"`
+	Args        []string `json:"args,omitempty"`
+	WorkDir     string   `json:"workdir,omitempty"`
+
+	// ApprovalID starts an interpreter held for approval under an
+	// approved request
+	ApprovalID string `json:"approval_id,omitempty"`
+}
+
+// SendToREPLParams represents parameters for sending input to an
+// interpreter.
+type SendToREPLParams struct {
+	Session   string `json:"session"`
+	Input     string `json:"input"`
+	Timeout   string `json:"timeout,omitempty"`
+	Interrupt bool   `json:"interrupt,omitempty"` // Send Ctrl-C first
+}
+
+// StopREPLParams represents parameters for stopping an interpreter.
+type StopREPLParams struct {
+	Session string `json:"session"`
+}
+
+// REPLStart is the result of start_repl.
+type REPLStart struct {
+	repl.Info
+	Banner string `json:"banner"`
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// registerREPLTools registers the REPL session tools unless they are
+// disabled.
+func (s *Server) registerREPLTools() error {
+	if s.repls == nil {
+		s.logger.Debug("REPL tools disabled")
+		return nil
+	}
+
+	s.registerStartREPLTool()
+	s.registerSendToREPLTool()
+	s.registerStopREPLTool()
+
+	s.logger.Debug("registered REPL tools")
+
+	return nil
+}
+
+func (s *Server) registerStartREPLTool() {
+	tool := &mcp.Tool{
+		Name:        "start_repl",
+		Description: "Start an interactive interpreter that keeps its state between calls, so data can be loaded once and explored over many send_to_repl calls instead of re-running scripts. interpreter names a configured interpreter (python, node and psql by default); args are appended to its configured arguments, and the interpreter is checked against the security policy like execute_command, including approvals (pass approval_id once approved). Returns a session ID and the banner; idle sessions are stopped after a while.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[StartREPLParams]) (*mcp.CallToolResultFor[REPLStart], error) {
+		args := params.Arguments
+
+		// The interpreter goes through the whole policy, as if run by
+		// execute_command
+		if interp := s.repls.Interpreter(args.Interpreter); interp != nil {
+			if _, err := s.executor.Admit(ctx, &types.CommandExecutionRequest{
+				Command:    interp.Command,
+				Args:       append(append([]string{}, interp.Args...), args.Args...),
+				WorkDir:    args.WorkDir,
+				ApprovalID: args.ApprovalID,
+			}); err != nil {
+				s.logger.WithError(err).Warn("REPL session denied", "interpreter", args.Interpreter)
+				return &mcp.CallToolResultFor[REPLStart]{
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Starting REPL failed: %s", err.Error())}},
+					IsError: true,
+				}, nil
+			}
+		}
+
+		info, out, err := s.repls.Start(ctx, repl.StartRequest{
+			Interpreter: args.Interpreter,
+			Args:        args.Args,
+			WorkDir:     args.WorkDir,
+		})
+		if err != nil {
+			s.logger.WithError(err).Error("REPL start failed", "interpreter", args.Interpreter)
+			return &mcp.CallToolResultFor[REPLStart]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Starting REPL failed: %s", err.Error())}},
+				IsError: true,
+			}, nil
+		}
+
+		text := fmt.Sprintf("Started %s session %s\n%s", info.Interpreter, info.ID, out.Output+out.Prompt)
+		if out.Exited {
+			text = fmt.Sprintf("%s exited right after starting with code %d\n%s", info.Interpreter, *out.ExitCode, out.Output)
+		} else if out.TimedOut {
+			text += "\nNo prompt appeared yet"
+		}
+		return &mcp.CallToolResultFor[REPLStart]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+			StructuredContent: REPLStart{Info: *info, Banner: out.Output, Prompt: out.Prompt},
+			IsError:           out.Exited,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerSendToREPLTool() {
+	tool := &mcp.Tool{
+		Name:        "send_to_repl",
+		Description: "Send input to a session started by start_repl and return its output. Input is sent a line at a time, each waiting for the interpreter's prompt, so multi-line blocks work as typed; end Python blocks with an empty line. If no prompt comes within timeout (default from the configuration), the output so far is returned, the remaining lines are not sent, and the next call first waits for the prompt; interrupt sends Ctrl-C before the input.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[SendToREPLParams]) (*mcp.CallToolResultFor[repl.Output], error) {
+		args := params.Arguments
+
+		var timeout time.Duration
+		if args.Timeout != "" {
+			d, err := time.ParseDuration(args.Timeout)
+			if err != nil || d <= 0 {
+				return &mcp.CallToolResultFor[repl.Output]{
+					Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Sending to REPL failed: %s", apperrors.ValidationError("invalid timeout", "timeout").Error())}},
+					IsError: true,
+				}, nil
+			}
+			if max := s.config.Execution.MaxTimeout.Std(); max > 0 && d > max {
+				d = max
+			}
+			timeout = d
+		}
+
+		if err := s.executor.AdmitInput(args.Session); err != nil {
+			return &mcp.CallToolResultFor[repl.Output]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Sending to REPL failed: %s", err.Error())}},
+				IsError: true,
+			}, nil
+		}
+		out, err := s.repls.Send(ctx, repl.SendRequest{
+			Session:   args.Session,
+			Input:     args.Input,
+			Timeout:   timeout,
+			Interrupt: args.Interrupt,
+		})
+		if err != nil {
+			s.logger.WithError(err).Debug("REPL send failed", "session", args.Session)
+			return &mcp.CallToolResultFor[repl.Output]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Sending to REPL failed: %s", err.Error())}},
+				IsError: true,
+			}, nil
+		}
+
+		text := out.Output
+		if out.Truncated {
+			text = "[start of output truncated]\n" + text
+		}
+		switch {
+		case out.Exited:
+			text += fmt.Sprintf("\n[session exited with code %d]", *out.ExitCode)
+		case out.TimedOut:
+			text += fmt.Sprintf("\n[no prompt yet; %d lines not sent]", out.Unsent)
+		default:
+			text += out.Prompt
+		}
+		return &mcp.CallToolResultFor[repl.Output]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+			StructuredContent: *out,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerStopREPLTool() {
+	tool := &mcp.Tool{
+		Name:        "stop_repl",
+		Description: "Stop a session started by start_repl and its interpreter, discarding its state.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[StopREPLParams]) (*mcp.CallToolResultFor[any], error) {
+		if err := s.repls.Stop(params.Arguments.Session); err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Stopping REPL failed: %s", err.Error())}},
+				IsError: true,
+			}, nil
+		}
+		s.logger.Info("REPL session stopped", "session", params.Arguments.Session)
+
+		return &mcp.CallToolResultFor[any]{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Stopped REPL session " + params.Arguments.Session}},
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
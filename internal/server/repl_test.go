@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_replTools(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil || runtime.GOOS == "windows" {
+		t.Skip("python3 under a pseudo terminal not available")
+	}
+	cfg := config.Default()
+	cfg.REPL.Enabled = true
+	srv, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "start_repl", Arguments: map[string]any{"interpreter": "python"}})
+	if err != nil {
+		t.Fatalf("start_repl error = %v", err)
+	}
+	start, _ := res.StructuredContent.(map[string]any)
+	if res.IsError || start["id"] != "python-1" {
+		t.Fatalf("start_repl = %v (error %v), want session python-1", start, res.IsError)
+	}
+
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "send_to_repl", Arguments: map[string]any{"session": "python-1", "input": "x = 6\nx * 7"}})
+	if err != nil {
+		t.Fatalf("send_to_repl error = %v", err)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; res.IsError || text != "42\n>>> " {
+		t.Errorf("send_to_repl = %q (error %v), want 42", text, res.IsError)
+	}
+
+	// Input is refused while an operator pauses executions
+	if err := srv.executor.SetSwitch(executor.SwitchPaused, true); err != nil {
+		t.Fatal(err)
+	}
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "send_to_repl", Arguments: map[string]any{"session": "python-1", "input": "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IsError {
+		t.Error("send_to_repl while paused succeeded")
+	}
+	if err := srv.executor.SetSwitch(executor.SwitchPaused, false); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "stop_repl", Arguments: map[string]any{"session": "python-1"}})
+	if err != nil || res.IsError {
+		t.Fatalf("stop_repl = %v, %v", res, err)
+	}
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "send_to_repl", Arguments: map[string]any{"session": "python-1", "input": "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; !res.IsError || !strings.Contains(text, "no such REPL session") {
+		t.Errorf("send_to_repl after stop = %q, want an error", text)
+	}
+
+	// Interpreters blocked by the security policy do not start
+	srv.config.Security.BlockedCommands = append(srv.config.Security.BlockedCommands, "python3")
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "start_repl", Arguments: map[string]any{"interpreter": "python"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IsError {
+		t.Error("start_repl of a blocked interpreter succeeded")
+	}
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// RerunExecutionParams represents parameters for running a recorded
+// execution again.
+type RerunExecutionParams struct {
+	HistoryID string   `json:"history_id"`
+	ExtraArgs []string `json:"extra_args,omitempty"` // Appended to the recorded args
+	WorkDir   string   `json:"workdir,omitempty"`    // Replaces the recorded workdir
+	Timeout   string   `json:"timeout,omitempty"`    // Replaces the recorded timeout
+
+	// ApprovalID runs a command requiring approval under an approved
+	// request, which the original approval does not cover
+	ApprovalID string `json:"approval_id,omitempty"`
+}
+
+// rerunError returns the error result of a re-run that could not start.
+func rerunError(text string) *mcp.CallToolResultFor[types.CommandExecutionResult] {
+	return &mcp.CallToolResultFor[types.CommandExecutionResult]{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+		StructuredContent: types.CommandExecutionResult{
+			ExitCode:     -1,
+			ErrorMessage: text,
+			StartTime:    time.Now(),
+			EndTime:      time.Now(),
+		},
+		IsError: true,
+	}
+}
+
+// rerunExecution runs a recorded execution again with the overrides of
+// params. Configured commands are looked up again, so the re-run gets the
+// command as currently configured, and both kinds go through the policy
+// like a new tool call.
+func (s *Server) rerunExecution(ctx context.Context, params RerunExecutionParams) (*mcp.CallToolResultFor[types.CommandExecutionResult], error) {
+	rec, ok := s.history.Get(params.HistoryID)
+	if !ok {
+		return rerunError(s.msg.Sprintf("Unknown execution: %s", params.HistoryID)), nil
+	}
+	if params.Timeout != "" {
+		if _, err := time.ParseDuration(params.Timeout); err != nil {
+			return rerunError(s.msg.Sprintf("Invalid timeout: %s", params.Timeout)), nil
+		}
+	}
+
+	if cmd := s.findCommand(rec.Tool); cmd != nil {
+		// The recorded args are the configured ones followed by the
+		// client's
+		n := len(cmd.Args)
+		if len(rec.Request.Args) < n || !slices.Equal(rec.Request.Args[:n], cmd.Args) {
+			return rerunError(s.msg.Sprintf("Command %s changed since execution %s", cmd.Name, rec.ID)), nil
+		}
+		if len(params.ExtraArgs) > 0 && !cmd.AllowArgs {
+			return rerunError(s.msg.Sprintf("Command %s does not accept arguments", cmd.Name)), nil
+		}
+
+		execCmd := *cmd
+		execCmd.Args = slices.Clone(cmd.Args)
+		if params.Timeout != "" {
+			d, _ := time.ParseDuration(params.Timeout)
+			execCmd.Timeout = config.Duration(d)
+		}
+		workDir := rec.Request.WorkDir
+		if params.WorkDir != "" {
+			workDir = params.WorkDir
+		}
+		args := append(slices.Clone(rec.Request.Args[n:]), params.ExtraArgs...)
+		res, err := s.runConfigCommand(ctx, execCmd, ConfigCommandParams{WorkDir: workDir, Args: args, ApprovalID: params.ApprovalID})
+		return s.noteRerun(res, rec.ID), err
+	}
+
+	switch rec.Tool {
+	case "execute_command", "execute_batch", "run_tests", "run_linter", "format_code":
+		// Batch steps run as execute_command; test, linter and
+		// formatter runs re-run their command with its raw output
+		tool := rec.Tool
+		if tool == "execute_batch" {
+			tool = "execute_command"
+		}
+		if !s.toolEnabled(tool) {
+			return rerunError(s.msg.Sprintf("Execution %s cannot be re-run: %s is not enabled", rec.ID, tool)), nil
+		}
+		req := types.CommandExecutionRequest{
+			Command:    rec.Request.Command,
+			Args:       append(slices.Clone(rec.Request.Args), params.ExtraArgs...),
+			WorkDir:    rec.Request.WorkDir,
+			Env:        slices.Clone(rec.Request.Env),
+			Timeout:    rec.Request.Timeout,
+			ApprovalID: params.ApprovalID,
+		}
+		if params.WorkDir != "" {
+			req.WorkDir = params.WorkDir
+		}
+		if params.Timeout != "" {
+			req.Timeout = params.Timeout
+		}
+		res, err := s.runCommand(ctx, req)
+		return s.noteRerun(res, rec.ID), err
+	}
+	return rerunError(s.msg.Sprintf("Execution %s cannot be re-run", rec.ID)), nil
+}
+
+// noteRerun prefixes the text of a re-run's result with the execution it
+// re-ran.
+func (s *Server) noteRerun(res *mcp.CallToolResultFor[types.CommandExecutionResult], id string) *mcp.CallToolResultFor[types.CommandExecutionResult] {
+	if res == nil || len(res.Content) == 0 {
+		return res
+	}
+	if text, ok := res.Content[0].(*mcp.TextContent); ok {
+		text.Text = s.msg.Sprintf("Re-run of execution %s", id) + "\n" + text.Text
+	}
+	return res
+}
+
+// registerRerunTool registers the execution re-run tool.
+func (s *Server) registerRerunTool() error {
+	tool := &mcp.Tool{
+		Name:        "rerun_execution",
+		Description: "Run an execution recorded in the history again by its history_id, optionally with extra_args appended, another workdir or a different timeout, e.g. to run a failed command again with -v. The re-run goes through the same policy as a new call and is recorded with its own history_id.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[RerunExecutionParams]) (*mcp.CallToolResultFor[types.CommandExecutionResult], error) {
+		return s.rerunExecution(ctx, params.Arguments)
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered rerun tool")
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_rerunExecution(t *testing.T) {
+	cfg := config.Default()
+	cfg.Commands = []config.Command{
+		{Name: "greet", Command: "echo", Args: []string{"hello"}, AllowArgs: true},
+		{Name: "fixed", Command: "echo", Args: []string{"fixed"}},
+	}
+	srv, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	call := func(name string, args map[string]any) (*mcp.CallToolResult, string) {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+		if err != nil {
+			t.Fatalf("%s error = %v", name, err)
+		}
+		result, _ := res.StructuredContent.(map[string]any)
+		id, _ := result["history_id"].(string)
+		return res, id
+	}
+
+	_, first := call("execute_command", map[string]any{"command": "echo", "args": []string{"one"}})
+	res, second := call("rerun_execution", map[string]any{"history_id": first, "extra_args": []string{"two"}})
+	if res.IsError || second == "" || second == first {
+		t.Fatalf("rerun_execution = %v, history_id %q", res.Content, second)
+	}
+	rec, _ := srv.history.Get(second)
+	if rec.Tool != "execute_command" || strings.Join(rec.Request.Args, " ") != "one two" || rec.Result == nil || rec.Result.Stdout != "one two\n" {
+		t.Errorf("re-run recorded as %+v", rec)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, first) {
+		t.Errorf("result text %q does not name the original execution", text)
+	}
+
+	// Configured commands keep their configured args, followed by the
+	// client's
+	_, greet := call("greet", map[string]any{"args": []string{"world"}})
+	res, rerun := call("rerun_execution", map[string]any{"history_id": greet, "extra_args": []string{"again"}})
+	if res.IsError {
+		t.Fatalf("rerun_execution = %v", res.Content)
+	}
+	if rec, _ := srv.history.Get(rerun); rec.Tool != "greet" || rec.Result == nil || rec.Result.Stdout != "hello world again\n" {
+		t.Errorf("re-run recorded as %+v", rec)
+	}
+
+	_, fixed := call("fixed", nil)
+	if res, _ := call("rerun_execution", map[string]any{"history_id": fixed, "extra_args": []string{"-v"}}); !res.IsError {
+		t.Error("expected an error result for extra args to a command without allow_args")
+	}
+	if res, _ := call("rerun_execution", map[string]any{"history_id": fixed, "timeout": "soon"}); !res.IsError {
+		t.Error("expected an error result for an invalid timeout")
+	}
+	if res, _ := call("rerun_execution", map[string]any{"history_id": "missing"}); !res.IsError {
+		t.Error("expected an error result for an unknown execution")
+	}
+}
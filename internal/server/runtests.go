@@ -0,0 +1,206 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/testrun"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// RunTestsParams represents parameters for running a project's tests.
+type RunTestsParams struct {
+	WorkDir   string   `json:"workdir"`             // Absolute directory inside the project
+	Framework string   `json:"framework,omitempty"` // go, cargo, npm or pytest; detected when empty
+	Filter    string   `json:"filter,omitempty"`    // Name pattern of the tests to run
+	Args      []string `json:"args,omitempty"`      // Extra arguments of the test command
+	Timeout   string   `json:"timeout,omitempty"`
+}
+
+// testsError returns the error result of a test run that could not start.
+func testsError(text string) *mcp.CallToolResultFor[testrun.Report] {
+	return &mcp.CallToolResultFor[testrun.Report]{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+		IsError: true,
+	}
+}
+
+// runTests runs the tests of the project containing a workdir through the
+// executor and parses their failures. The execution is recorded like any
+// other, so the raw output stays available from the history.
+func (s *Server) runTests(ctx context.Context, params RunTestsParams) (*mcp.CallToolResultFor[testrun.Report], error) {
+	if params.WorkDir == "" || !filepath.IsAbs(params.WorkDir) {
+		return testsError(s.msg.T("workdir must be an absolute path")), nil
+	}
+	if !s.config.IsPathAllowed(params.WorkDir) {
+		return testsError(s.msg.Sprintf("Path not allowed: %s", params.WorkDir)), nil
+	}
+
+	framework := params.Framework
+	if framework == "" {
+		var err error
+		if framework, err = testrun.Detect(s.config, params.WorkDir); err != nil {
+			return testsError(s.msg.Sprintf("Could not detect the test framework: %s", err.Error())), nil
+		}
+	}
+	name, args, err := testrun.Command(framework, testrun.Options{Filter: params.Filter, Args: params.Args})
+	if err != nil {
+		return testsError(err.Error()), nil
+	}
+
+	req := types.CommandExecutionRequest{
+		Command: name,
+		Args:    args,
+		WorkDir: params.WorkDir,
+		Timeout: params.Timeout,
+	}
+	s.logger.Info("running tests",
+		"framework", framework,
+		"command", req.Command,
+		"args", req.Args,
+		"workdir", req.WorkDir,
+	)
+
+	result, err := s.executor.Execute(ctx, &req)
+	result = s.recordExecution(ctx, "run_tests", req, result, err)
+	if err != nil {
+		s.logger.WithError(err).Error("test run failed")
+		return testsError(s.msg.Sprintf("Command execution failed: %s", err.Error())), nil
+	}
+
+	report := testrun.Report{
+		Framework: framework,
+		Command:   name,
+		Args:      args,
+		WorkDir:   params.WorkDir,
+		Passed:    result.ExitCode == 0 && !result.TimedOut,
+		ExitCode:  result.ExitCode,
+		HistoryID: result.HistoryID,
+		Duration:  result.Duration,
+	}
+	stdout, stdoutCut := parsedOutput(result.Stdout, result.StdoutFile)
+	stderr, stderrCut := parsedOutput(result.Stderr, result.StderrFile)
+	testrun.Parse(&report, stdout, stderr)
+	report.OutputTruncated = stdoutCut || stderrCut
+
+	return &mcp.CallToolResultFor[testrun.Report]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: s.formatTestReport(&report, result)}},
+		StructuredContent: report,
+	}, nil
+}
+
+// maxParsedOutput bounds the output of a test run read back from its
+// spill file to be parsed, so huge outputs are not read into memory whole.
+const maxParsedOutput = 8 << 20
+
+// parsedOutput returns the output of a test run to parse: the output
+// itself, or when it was truncated, what its spill file holds. Of a spill
+// file larger than maxParsedOutput, only the start and end are read, where
+// the first failures and the summaries are, and whether part of the output
+// was left out is returned too.
+func parsedOutput(output, spillFile string) (string, bool) {
+	if spillFile == "" {
+		return output, false
+	}
+	f, err := os.Open(spillFile)
+	if err != nil {
+		return output, true
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return output, true
+	}
+	if info.Size() <= maxParsedOutput {
+		data, err := io.ReadAll(io.LimitReader(f, maxParsedOutput))
+		if err != nil {
+			return output, true
+		}
+		return string(data), false
+	}
+
+	// Keep the whole lines of each half
+	half := int64(maxParsedOutput / 2)
+	head := make([]byte, half, maxParsedOutput)
+	if _, err := io.ReadFull(f, head); err != nil {
+		return output, true
+	}
+	head = head[:bytes.LastIndexByte(head, '\n')+1]
+	tail := make([]byte, half)
+	if _, err := f.ReadAt(tail, info.Size()-half); err != nil {
+		return output, true
+	}
+	if i := bytes.IndexByte(tail, '\n'); i >= 0 {
+		tail = tail[i+1:]
+	}
+	return string(append(head, tail...)), true
+}
+
+// formatTestReport summarizes a test run and lists its failures.
+func (s *Server) formatTestReport(report *testrun.Report, result *types.CommandExecutionResult) string {
+	var b strings.Builder
+	switch {
+	case result.TimedOut:
+		b.WriteString(s.msg.Sprintf("Tests timed out after %s", report.Duration))
+	case report.Passed:
+		b.WriteString(s.msg.T("Tests passed"))
+	default:
+		b.WriteString(s.msg.Sprintf("Tests failed with exit code %d", report.ExitCode))
+	}
+	fmt.Fprintf(&b, " (%s %s)\n", report.Command, strings.Join(report.Args, " "))
+	if c := report.Counts; c != nil {
+		fmt.Fprintf(&b, "%d passed, %d failed, %d skipped\n", c.Passed, c.Failed, c.Skipped)
+	}
+	for _, f := range report.Failures {
+		name := f.Test
+		if name == "" {
+			name = f.Package
+		}
+		location := f.File
+		if f.Line > 0 {
+			location = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		fmt.Fprintf(&b, "\nFAIL %s", name)
+		if location != "" {
+			fmt.Fprintf(&b, " (%s)", location)
+		}
+		b.WriteString("\n")
+		if f.Message != "" {
+			b.WriteString(f.Message + "\n")
+		}
+	}
+	if report.FailuresTruncated {
+		b.WriteString("\n... (more failures not shown)\n")
+	}
+	if report.OutputTruncated && report.HistoryID != "" {
+		b.WriteString("\n" + s.msg.Sprintf("Only the start and end of the output were parsed; the whole output is in execution %s", report.HistoryID) + "\n")
+	}
+	if !report.Passed && len(report.Failures) == 0 && report.HistoryID != "" {
+		b.WriteString(s.msg.Sprintf("No failures could be parsed; the raw output is in execution %s", report.HistoryID) + "\n")
+	}
+	return b.String()
+}
+
+// registerRunTestsTool registers the test runner tool.
+func (s *Server) registerRunTestsTool() error {
+	tool := &mcp.Tool{
+		Name:        "run_tests",
+		Description: "Run the tests of the project containing an absolute workdir and get the failures as JSON: test name, file, line and message, with passed, failed and skipped counts. The framework (go, cargo, npm or pytest) is detected from go.mod, Cargo.toml, package.json or the pytest configuration unless given; set filter to a name pattern to run some of them. The run goes through the same policy as execute_command, and its raw output is recorded under history_id.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[RunTestsParams]) (*mcp.CallToolResultFor[testrun.Report], error) {
+		return s.runTests(ctx, params.Arguments)
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered run_tests tool")
+
+	return nil
+}
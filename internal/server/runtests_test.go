@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/testrun"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_runTests(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.21\n",
+		"m_test.go": `package m
+
+import "testing"
+
+func TestOK(t *testing.T) {}
+
+func TestBad(t *testing.T) { t.Fatal("broken") }
+`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.Security.AllowedPaths = []string{dir}
+	cfg.Execution.DefaultTimeout = config.Duration(2 * time.Minute)
+	srv, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "run_tests", Arguments: map[string]any{"workdir": dir}})
+	if err != nil || res.IsError {
+		t.Fatalf("run_tests = %v, %v", res, err)
+	}
+	var report testrun.Report
+	if data, err := json.Marshal(res.StructuredContent); err == nil {
+		json.Unmarshal(data, &report)
+	}
+	if report.Framework != testrun.FrameworkGo || report.Passed || report.Counts == nil || report.Counts.Passed != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Failures) != 1 || report.Failures[0].Test != "TestBad" || report.Failures[0].Line != 7 {
+		t.Errorf("failures = %+v", report.Failures)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "FAIL TestBad (m_test.go:7)") {
+		t.Errorf("run_tests text = %q", text)
+	}
+
+	// The raw output is recorded
+	rec, ok := srv.history.Get(report.HistoryID)
+	if !ok || rec.Tool != "run_tests" || rec.Result == nil || !strings.Contains(rec.Result.Stdout, `"Action":"fail"`) {
+		t.Errorf("history record = %+v, %v", rec, ok)
+	}
+
+	// Workdirs outside allowed_paths are refused
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "run_tests", Arguments: map[string]any{"workdir": t.TempDir()}})
+	if err != nil || !res.IsError {
+		t.Errorf("run_tests outside allowed_paths = %v, %v, want an error result", res, err)
+	}
+}
+
+func TestParsedOutput(t *testing.T) {
+	dir := t.TempDir()
+	spill := func(name string, lines int) (string, string) {
+		t.Helper()
+		var b strings.Builder
+		for i := 0; i < lines; i++ {
+			fmt.Fprintf(&b, "line %d\n", i)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path, b.String()
+	}
+
+	// Output that was not spilled is parsed as it is
+	if out, cut := parsedOutput("ok\n", ""); out != "ok\n" || cut {
+		t.Errorf("parsedOutput() = %q, %v, want the output", out, cut)
+	}
+
+	// Small spill files are read whole
+	path, want := spill("small.log", 1000)
+	if out, cut := parsedOutput("line 0\n", path); out != want || cut {
+		t.Errorf("parsedOutput() of a small spill file = %d bytes, %v, want %d bytes", len(out), cut, len(want))
+	}
+
+	// Of large ones only whole lines of the start and end are read
+	path, want = spill("large.log", 1<<20)
+	if len(want) <= maxParsedOutput {
+		t.Fatalf("spill file of %d bytes is not larger than %d", len(want), maxParsedOutput)
+	}
+	out, cut := parsedOutput("line 0\n", path)
+	if !cut || len(out) > maxParsedOutput {
+		t.Fatalf("parsedOutput() of a large spill file = %d bytes, %v, want at most %d", len(out), cut, maxParsedOutput)
+	}
+	if !strings.HasPrefix(out, "line 0\nline 1\n") || !strings.HasSuffix(out, fmt.Sprintf("line %d\n", 1<<20-1)) {
+		t.Errorf("parsedOutput() = %.20q...%q, want the start and end", out, out[len(out)-20:])
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if !strings.HasPrefix(line, "line ") {
+			t.Fatalf("parsedOutput() has a partial line %q", line)
+		}
+	}
+}
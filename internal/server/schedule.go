@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/history"
+	"github.com/mjmorales/simple-mcp-runner/internal/scheduler"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultScheduleRunsLimit is the number of runs returned when no limit is given.
+const defaultScheduleRunsLimit = 20
+
+// ListScheduleRunsParams represents parameters for listing scheduled runs.
+type ListScheduleRunsParams struct {
+	Schedule string `json:"schedule,omitempty"` // Only runs of this schedule
+	Limit    int    `json:"limit,omitempty"`
+}
+
+// ScheduleRunsResult lists configured schedules and their recent runs.
+type ScheduleRunsResult struct {
+	Schedules []scheduler.ScheduleInfo `json:"schedules"`
+	Runs      []types.ExecutionRecord  `json:"runs"`
+}
+
+// registerScheduleTool registers the scheduled runs listing tool.
+func (s *Server) registerScheduleTool() error {
+	tool := &mcp.Tool{
+		Name:        "list_schedule_runs",
+		Description: "List configured command schedules with their next run time, and recent scheduled runs (newest first) with exit codes and output. Use schedule to filter by schedule name.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ListScheduleRunsParams]) (*mcp.CallToolResultFor[ScheduleRunsResult], error) {
+		limit := params.Arguments.Limit
+		if limit <= 0 {
+			limit = defaultScheduleRunsLimit
+		}
+
+		result := ScheduleRunsResult{
+			Schedules: s.scheduler.Schedules(),
+			Runs: s.history.List(history.Filter{
+				Source:   types.ExecutionSourceSchedule,
+				Schedule: params.Arguments.Schedule,
+				Limit:    limit,
+			}),
+		}
+
+		return &mcp.CallToolResultFor[ScheduleRunsResult]{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: formatScheduleRuns(&result)},
+			},
+			StructuredContent: result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered schedule tool")
+
+	return nil
+}
+
+// formatScheduleRuns renders schedules and runs as text.
+func formatScheduleRuns(result *ScheduleRunsResult) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Schedules (%d):\n", len(result.Schedules))
+	for _, sched := range result.Schedules {
+		next := "never"
+		if !sched.NextRun.IsZero() {
+			next = sched.NextRun.Format(time.RFC3339)
+		}
+		fmt.Fprintf(&b, "- %s: %s runs %s, next %s\n", sched.Name, sched.Cron, sched.Command, next)
+	}
+
+	fmt.Fprintf(&b, "\nRecent runs (%d):\n", len(result.Runs))
+	for _, run := range result.Runs {
+		status := run.Error
+		if run.Result != nil {
+			status = fmt.Sprintf("exit code %d", run.Result.ExitCode)
+			if run.Result.TimedOut {
+				status = "timed out"
+			}
+		}
+		fmt.Fprintf(&b, "- [%s] %s at %s: %s\n", run.ID, run.Schedule, run.Timestamp.Format(time.RFC3339), status)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/internal/script"
+	"github.com/mjmorales/simple-mcp-runner/internal/transfer"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerScriptTool compiles a script tool and registers it with a schema
+// built from its parameters.
+func (s *Server) registerScriptTool(def config.ScriptTool) error {
+	prog, err := script.Compile(def)
+	if err != nil {
+		return err
+	}
+
+	tool := &mcp.Tool{
+		Name:        def.Name,
+		Title:       def.DisplayName,
+		Description: def.Description,
+		InputSchema: scriptSchema(def),
+	}
+
+	env := script.Env{Run: s.runFromScript, ReadFile: s.readFromScript}
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		result, err := prog.Call(ctx, env, params.Arguments)
+		if err != nil {
+			s.logger.WithError(err).Error("script tool failed", "tool", def.Name)
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: s.msg.Sprintf("Script failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		text := result.Text
+		if len(result.Log) > 0 {
+			text += s.msg.Sprintf("\n\nScript log:\n%s", strings.Join(result.Log, "\n"))
+		}
+		return &mcp.CallToolResultFor[any]{
+			Content: []mcp.Content{&mcp.TextContent{Text: text}},
+		}, nil
+	}
+
+	addConfiguredTool(s, tool, handler)
+
+	s.logger.Debug("registered script tool", "name", def.Name)
+	return nil
+}
+
+// scriptSchema returns the input schema of a script tool.
+func scriptSchema(def config.ScriptTool) *jsonschema.Schema {
+	schema := &jsonschema.Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*jsonschema.Schema),
+		AdditionalProperties: &jsonschema.Schema{Not: &jsonschema.Schema{}},
+	}
+	for _, param := range def.Params {
+		prop := &jsonschema.Schema{Type: param.Type, Description: param.Description}
+		switch param.Type {
+		case "":
+			prop.Type = "string"
+		case "array":
+			prop.Items = &jsonschema.Schema{Type: "string"}
+		}
+		schema.Properties[param.Name] = prop
+		if param.Required {
+			schema.Required = append(schema.Required, param.Name)
+		}
+	}
+	return schema
+}
+
+// runFromScript runs a configured command for a script, as its tool would
+// with the same arguments.
+func (s *Server) runFromScript(ctx context.Context, name string, args []string, workDir string) (*types.CommandExecutionResult, error) {
+	cmd := s.findCommand(name)
+	if cmd == nil {
+		return nil, apperrors.ValidationError("unknown command: "+name, "command")
+	}
+
+	execCmd := *cmd
+	if execCmd.AllowArgs && len(args) > 0 {
+		execCmd.Args = append(append([]string(nil), execCmd.Args...), args...)
+	}
+
+	result, err := s.executor.ExecuteConfigCommandWithOptions(ctx, &execCmd, workDir, executor.ConfigCommandOptions{})
+	result = s.recordExecution(ctx, execCmd.Name, configCommandRequest(&execCmd, workDir, result), result, err)
+	return result, err
+}
+
+// readFromScript reads a file for a script within the transfer limits.
+func (s *Server) readFromScript(path string, offset, length int64) (*transfer.Chunk, error) {
+	return s.transfer.ReadChunk(transfer.ChunkRequest{Path: path, Offset: offset, Length: length})
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_scriptTool(t *testing.T) {
+	cfg := config.Default()
+	cfg.Commands = []config.Command{
+		{Name: "hello", Description: "Hello", Command: "echo", Args: []string{"hello"}, AllowArgs: true},
+	}
+	cfg.ScriptTools = []config.ScriptTool{{
+		Name:        "greet",
+		Description: "Greet someone",
+		Params:      []config.ScriptParam{{Name: "who", Required: true}, {Name: "loud", Type: "boolean"}},
+		Script: `
+def main(params):
+    r = run("hello", [params["who"]])
+    if not r.ok:
+        fail(r.error)
+    out = r.stdout.strip()
+    if params.get("loud"):
+        out = out.upper()
+    return out
+`,
+	}}
+	_, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	tools, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	found := false
+	for _, tool := range tools.Tools {
+		if tool.Name == "greet" {
+			found = true
+			if tool.InputSchema.Properties["loud"].Type != "boolean" || len(tool.InputSchema.Required) != 1 {
+				t.Errorf("greet schema = %+v", tool.InputSchema)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("greet not listed")
+	}
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "greet", Arguments: map[string]any{"who": "world", "loud": true}})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; res.IsError || text != "HELLO WORLD" {
+		t.Errorf("greet = %q (error %v), want HELLO WORLD", text, res.IsError)
+	}
+
+	// Arguments are checked against the schema before the script runs
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "greet", Arguments: map[string]any{}}); err == nil {
+		t.Error("greet without who succeeded")
+	}
+}
+
+func TestServer_scriptToolInvalid(t *testing.T) {
+	cfg := config.Default()
+	cfg.ScriptTools = []config.ScriptTool{{Name: "broken", Description: "Broken", Script: "def main(params)\n"}}
+	_, err := New(Options{Config: cfg})
+	if err == nil || !strings.Contains(err.Error(), "invalid script of tool broken") {
+		t.Errorf("New() error = %v, want invalid script", err)
+	}
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SearchToolsParams represents parameters for searching tools.
+type SearchToolsParams struct {
+	Query      string `json:"query"`
+	MaxResults int    `json:"max_results,omitempty"`
+}
+
+// defaultSearchResults is the number of tools returned by default.
+const defaultSearchResults = 10
+
+// Weights of a search term found in each part of a tool.
+const (
+	nameWeight        = 3
+	groupWeight       = 2
+	descriptionWeight = 1
+)
+
+// searchTerms splits a query into lowercase terms, dropping a plural "s"
+// so "tests" finds "test".
+func searchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !(r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > 127)
+	}) {
+		if len(field) > 3 && strings.HasSuffix(field, "s") && !strings.HasSuffix(field, "ss") {
+			field = strings.TrimSuffix(field, "s")
+		}
+		terms = append(terms, field)
+	}
+	return slices.Compact(slices.Sorted(slices.Values(terms)))
+}
+
+// scoreTool returns how well a tool matches the search terms: each term
+// found counts for the best part of the tool it is found in.
+func scoreTool(terms []string, name, description string, groups []string) int {
+	name = strings.ToLower(name)
+	description = strings.ToLower(description)
+	score := 0
+	for _, term := range terms {
+		switch {
+		case strings.Contains(name, term):
+			score += nameWeight
+		case slices.ContainsFunc(groups, func(g string) bool { return strings.Contains(strings.ToLower(g), term) }):
+			score += groupWeight
+		case strings.Contains(description, term):
+			score += descriptionWeight
+		}
+	}
+	return score
+}
+
+// searchTools searches the registered tools by name, description and tool
+// group, best matches first. The search tool itself is left out.
+func (s *Server) searchTools(ss *mcp.ServerSession, query string, max int) types.ToolSearchResult {
+	result := types.ToolSearchResult{Query: query, Tools: []types.ToolMatch{}}
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return result
+	}
+
+	s.toolNames.Range(func(key, value any) bool {
+		name, description := key.(string), value.(string)
+		if name == s.config.Server.ToolPrefix+"search_tools" {
+			return true
+		}
+		var groups []string
+		if cmd := s.findCommand(name); cmd != nil {
+			groups = s.groupsOf(cmd.Name)
+		}
+		if score := scoreTool(terms, name, description, groups); score > 0 {
+			result.Tools = append(result.Tools, types.ToolMatch{
+				Name:        name,
+				Description: description,
+				Groups:      groups,
+				Hidden:      s.hiddenTool(ss, name) != nil,
+				Score:       score,
+			})
+		}
+		return true
+	})
+	slices.SortFunc(result.Tools, func(a, b types.ToolMatch) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Name, b.Name))
+	})
+
+	result.TotalFound = len(result.Tools)
+	if len(result.Tools) > max {
+		result.Tools = result.Tools[:max]
+	}
+	return result
+}
+
+// toolSearchText renders a tool search for the text content of results.
+func (s *Server) toolSearchText(result types.ToolSearchResult) string {
+	if len(result.Tools) == 0 {
+		return s.msg.Sprintf("No tools match %q", result.Query)
+	}
+	var b strings.Builder
+	b.WriteString(s.msg.Sprintf("Found %d tools matching %q:", result.TotalFound, result.Query))
+	for _, tool := range result.Tools {
+		fmt.Fprintf(&b, "\n%s: %s", tool.Name, tool.Description)
+		if tool.Hidden {
+			b.WriteString(s.msg.Sprintf(" (select_toolset with %s to use it)", strings.Join(tool.Groups, " or ")))
+		}
+	}
+	return b.String()
+}
+
+// registerSearchTool registers the tool search tool.
+func (s *Server) registerSearchTool() error {
+	tool := &mcp.Tool{
+		Name:        "search_tools",
+		Description: "Search the available tools by keywords matched against their names, descriptions and tool groups, e.g. 'integration tests'. Returns the best matching tools first, including those of unselected tool groups.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[SearchToolsParams]) (*mcp.CallToolResultFor[types.ToolSearchResult], error) {
+		max := params.Arguments.MaxResults
+		if max <= 0 {
+			max = defaultSearchResults
+		}
+		result := s.searchTools(ss, params.Arguments.Query, max)
+		return &mcp.CallToolResultFor[types.ToolSearchResult]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: s.toolSearchText(result)}},
+			StructuredContent: result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered search tool")
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSearchTerms(t *testing.T) {
+	got := searchTerms("Run integration-tests, tests & GIT status")
+	want := []string{"git", "integration-test", "run", "statu", "test"}
+	if !slices.Equal(got, want) {
+		t.Errorf("searchTerms() = %v, want %v", got, want)
+	}
+}
+
+func TestServer_searchTools(t *testing.T) {
+	cfg := config.Default()
+	cfg.Commands = []config.Command{
+		{Name: "integration", Description: "Run the integration tests against a local database", Command: "echo"},
+		{Name: "unit", Description: "Run the unit tests", Command: "echo"},
+		{Name: "deploy", Description: "Deploy to staging", Command: "echo"},
+	}
+	cfg.ToolGroups = []config.ToolGroup{{Name: "release", Commands: []string{"deploy"}}}
+	_, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	search := func(args map[string]any) types.ToolSearchResult {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "search_tools", Arguments: args})
+		if err != nil || res.IsError {
+			t.Fatalf("search_tools = %v, %v", res, err)
+		}
+		data, err := json.Marshal(res.StructuredContent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result types.ToolSearchResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	result := search(map[string]any{"query": "integration tests"})
+	// run_tests matches tests by name, ranking above a description match
+	if len(result.Tools) < 3 || result.Tools[0].Name != "integration" || result.Tools[1].Name != "run_tests" || result.Tools[2].Name != "unit" {
+		t.Errorf("tools = %+v, want integration, run_tests then unit first", result.Tools)
+	}
+
+	result = search(map[string]any{"query": "release"})
+	if len(result.Tools) != 1 || result.Tools[0].Name != "deploy" || !result.Tools[0].Hidden {
+		t.Errorf("tools = %+v, want the hidden deploy tool", result.Tools)
+	}
+
+	result = search(map[string]any{"query": "run", "max_results": 1})
+	if len(result.Tools) != 1 || result.TotalFound < 2 {
+		t.Errorf("max_results 1 = %d tools of %d found", len(result.Tools), result.TotalFound)
+	}
+
+	if result := search(map[string]any{"query": "zzqx"}); len(result.Tools) != 0 {
+		t.Errorf("tools = %+v, want none", result.Tools)
+	}
+}
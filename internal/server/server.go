@@ -9,35 +9,120 @@ import (
 	"os/signal"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/internal/archive"
+	"github.com/mjmorales/simple-mcp-runner/internal/backup"
+	"github.com/mjmorales/simple-mcp-runner/internal/catalog"
+	"github.com/mjmorales/simple-mcp-runner/internal/container"
+	"github.com/mjmorales/simple-mcp-runner/internal/control"
+	"github.com/mjmorales/simple-mcp-runner/internal/debug"
 	"github.com/mjmorales/simple-mcp-runner/internal/discovery"
+	"github.com/mjmorales/simple-mcp-runner/internal/dlp"
 	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
 	"github.com/mjmorales/simple-mcp-runner/internal/executor"
+	"github.com/mjmorales/simple-mcp-runner/internal/fileops"
+	"github.com/mjmorales/simple-mcp-runner/internal/history"
+	"github.com/mjmorales/simple-mcp-runner/internal/i18n"
 	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/internal/notify"
+	"github.com/mjmorales/simple-mcp-runner/internal/outbound"
+	"github.com/mjmorales/simple-mcp-runner/internal/plugin"
+	"github.com/mjmorales/simple-mcp-runner/internal/process"
+	"github.com/mjmorales/simple-mcp-runner/internal/receipt"
+	"github.com/mjmorales/simple-mcp-runner/internal/recording"
+	"github.com/mjmorales/simple-mcp-runner/internal/repl"
+	"github.com/mjmorales/simple-mcp-runner/internal/scheduler"
+	"github.com/mjmorales/simple-mcp-runner/internal/security"
+	"github.com/mjmorales/simple-mcp-runner/internal/tmux"
+	"github.com/mjmorales/simple-mcp-runner/internal/transfer"
+	"github.com/mjmorales/simple-mcp-runner/internal/telemetry"
+	"github.com/mjmorales/simple-mcp-runner/internal/usage"
+	"github.com/mjmorales/simple-mcp-runner/internal/watcher"
 	"github.com/mjmorales/simple-mcp-runner/pkg/types"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// shutdownTimeout bounds how long a shutdown waits for requests to finish.
+const shutdownTimeout = 10 * time.Second
+
 // Server represents the MCP server.
 type Server struct {
 	config     *config.Config
 	logger     *logger.Logger
 	executor   *executor.Executor
 	discoverer *discovery.Discoverer
+	history    *history.Store
+	scheduler  *scheduler.Scheduler
+	watches    *watcher.Manager
+	processes  *process.Inspector
+	transfer   *transfer.Transfer
+	archiver   *archive.Archiver
+	notifier   *notify.Notifier
+	backups    *backup.Store
+	fileOps    *fileops.Ops
+	plugins    *plugin.Host
+	containers *container.Manager // Docker container tools, if enabled
+	tmux       *tmux.Manager      // tmux session tools, if enabled
+	repls      *repl.Manager      // REPL session tools, if enabled
+	usage      *usage.Recorder
+	telemetry  *telemetry.Reporter
+	dlp        *dlp.Scanner  // Scans results for sensitive data
+	msg        *i18n.Printer // Translates tool descriptions and results
 	mcpServer  *mcp.Server
 
-	mu       sync.RWMutex
-	running  bool
-	shutdown chan struct{}
+	principal  string   // Authenticated identity of stdio sessions
+	sessions   sync.Map // *mcp.ServerSession to *sessionInfo
+	toolNames  sync.Map // Descriptions of the registered tools, by name
+	recordFile string   // Session recording, if any
+	debugAddr  string   // Address of the debug endpoints, if enabled
+	transport  mcp.Transport // Set by embedders instead of the configured transport
+
+	crashes  atomic.Int64 // Panics recovered in request handlers
+	returned atomic.Int64 // Bytes of tool results and resources returned to clients
+	events   *eventLog    // Recent lifecycle and policy events
+
+	toolsChanged func() // Notifies clients that their tool lists changed
+
+	catalog       *catalog.Fetcher // Remote command catalog, if configured
+	localCommands []config.Command // Commands of the configuration file
+	configFile    string           // File the configuration was loaded from
+	commandsMu    sync.RWMutex     // Guards config.Commands, which catalog refreshes replace
+	revisionMu    sync.Mutex
+	revision      string         // Revision of the configuration; empty until computed
+	versions      binaryVersions // Versions of the binaries commands ran
+	brokenMu      sync.Mutex
+	broken        map[string]bool // Commands not registered because they cannot run
+
+	mu     sync.RWMutex
+	state  State
+	since  time.Time          // When the current run started serving
+	cancel context.CancelFunc // Stops the current run
+	done   chan struct{}      // Closed when the current run returns
+	hooks  hooks
 }
 
 // Options for creating a new server.
 type Options struct {
 	Config *config.Config
 	Logger *logger.Logger
+
+	// RecordSession is a file the MCP session is recorded to for replay
+	RecordSession string
+
+	// DebugAddr is a loopback address to serve pprof, expvar and runtime
+	// state on
+	DebugAddr string
+
+	// Transport replaces the configured transport, for embedding the
+	// server behind a connection of its own
+	Transport mcp.Transport
+
+	// ConfigFile is the file Config was loaded from, checked for drift
+	ConfigFile string
 }
 
 // New creates a new MCP server instance.
@@ -50,53 +135,159 @@ func New(opts Options) (*Server, error) {
 		opts.Logger = logger.Default()
 	}
 
+	// Outbound features would ignore network settings they cannot apply
+	if _, err := outbound.Transport(opts.Config.Network); err != nil {
+		return nil, err
+	}
+
 	// Create executor
 	exec := executor.New(opts.Config, opts.Logger)
 
 	// Create discoverer
 	disc := discovery.New(opts.Config, opts.Logger)
 
+	// Open execution history
+	hist, err := history.Open(opts.Config.History.Path, opts.Config.History.MaxEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sign execution records
+	if opts.Config.History.SigningKey != "" {
+		signer, err := receipt.LoadSigner(opts.Config.History.SigningKey)
+		if err != nil {
+			hist.Close()
+			return nil, err
+		}
+		hist.SetSigner(signer)
+	}
+
+	// Compile the plugins of configured commands
+	plugins, err := plugin.New(context.Background(), opts.Config, opts.Logger)
+	if err != nil {
+		hist.Close()
+		return nil, err
+	}
+	exec.SetPlugins(plugins)
+
+	// Create scheduler
+	sched, err := scheduler.New(opts.Config, exec, hist, opts.Logger)
+	if err != nil {
+		hist.Close()
+		plugins.Close(context.Background())
+		return nil, err
+	}
+
+	// Create the Docker client of the container tools
+	var containers *container.Manager
+	if opts.Config.Containers.Enabled {
+		containers, err = container.New(opts.Config, opts.Logger)
+		if err != nil {
+			hist.Close()
+			plugins.Close(context.Background())
+			return nil, err
+		}
+	}
+
+	// Sessions run with the environment executed commands get
+	var tmuxSessions *tmux.Manager
+	if opts.Config.Tmux.Enabled {
+		tmuxSessions = tmux.New(opts.Config, exec.InheritedEnv(), opts.Logger)
+	}
+	var repls *repl.Manager
+	if opts.Config.REPL.Enabled {
+		repls = repl.New(opts.Config, exec.InheritedEnv(), opts.Logger)
+	}
+
 	// Create MCP implementation
 	impl := &mcp.Implementation{
 		Name:    opts.Config.App,
 		Version: "1.0.0",
 	}
 
-	// Create MCP server
-	mcpServer := mcp.NewServer(impl, nil)
+	// Create MCP server, telling clients the limits to plan within
+	mcpServer := mcp.NewServer(impl, &mcp.ServerOptions{
+		Instructions: capabilitiesText(capabilities(opts.Config)),
+		PageSize:     opts.Config.Server.PageSize,
+	})
 
 	s := &Server{
 		config:     opts.Config,
 		logger:     opts.Logger,
 		executor:   exec,
 		discoverer: disc,
+		history:    hist,
+		scheduler:  sched,
+		watches:    watcher.NewManager(opts.Config, exec, hist, opts.Logger),
+		processes:  process.New(opts.Config),
+		transfer:   transfer.New(opts.Config, opts.Logger),
+		archiver:   archive.New(opts.Config, opts.Logger),
+		notifier:   notify.New(opts.Config, opts.Logger),
+		backups:    backup.New(opts.Config, opts.Logger),
+		fileOps:    fileops.New(opts.Config, opts.Logger),
+		plugins:    plugins,
+		containers: containers,
+		tmux:       tmuxSessions,
+		repls:      repls,
+		usage:      usage.NewRecorder(usageFile(opts.Config)),
+		telemetry:  telemetry.New(opts.Config, opts.Logger),
+		msg:        i18n.New(opts.Config.Server.Locale),
 		mcpServer:  mcpServer,
-		shutdown:   make(chan struct{}),
+		principal:  security.LocalPrincipal(),
+		recordFile: opts.RecordSession,
+		debugAddr:  opts.DebugAddr,
+		transport:  opts.Transport,
+		configFile: opts.ConfigFile,
+		events:     newEventLog(opts.Config.Server.EventLogSize),
+		dlp:        dlp.New(opts.Config),
+	}
+
+	// Survive panicking handlers, identify the client behind each request,
+	// hide the tools of unselected groups, report tool calls to subscribers
+	// with the size of the results sent, compress large results, hold
+	// output over the session budget back and keep sensitive data from
+	// clients
+	mcpServer.AddReceivingMiddleware(s.recoverMiddleware, s.securityMiddleware, s.welcomeMiddleware, s.completionMiddleware, s.toolsetMiddleware, s.toolCallMiddleware, s.compressMiddleware, s.outputBudgetMiddleware, s.dlpMiddleware)
+
+	// Add the commands of the remote catalog
+	if opts.Config.Catalog.URL != "" {
+		if err := s.loadCatalog(); err != nil {
+			hist.Close()
+			s.usage.Close()
+			s.telemetry.Close()
+			plugins.Close(context.Background())
+			containers.Close()
+			return nil, err
+		}
 	}
 
 	// Register tools
 	if err := s.registerTools(); err != nil {
+		hist.Close()
+		s.usage.Close()
+		s.telemetry.Close()
+		plugins.Close(context.Background())
+		containers.Close()
 		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to register tools")
 	}
 
+	// Keep the discovery index up to date
+	disc.WatchPaths()
+
 	return s, nil
 }
 
-// Run starts the MCP server.
-func (s *Server) Run(ctx context.Context) error {
-	s.mu.Lock()
-	if s.running {
-		s.mu.Unlock()
-		return apperrors.InternalError("server is already running")
-	}
-	s.running = true
-	s.mu.Unlock()
+// Run starts the MCP server and serves requests until the client
+// disconnects, the process is signalled, ctx is cancelled or Shutdown is
+// called.
+func (s *Server) Run(ctx context.Context) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	defer func() {
-		s.mu.Lock()
-		s.running = false
-		s.mu.Unlock()
-	}()
+	if err := s.begin(cancel); err != nil {
+		return err
+	}
+	defer func() { s.end(err) }()
 
 	s.logger.Info("starting MCP server",
 		"app", s.config.App,
@@ -109,96 +300,213 @@ func (s *Server) Run(ctx context.Context) error {
 		return err
 	}
 
-	// Setup graceful shutdown
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	// Anything else writing to stdout would corrupt the stdio stream
+	if s.transport == nil && s.config.Transport == "stdio" {
+		restore, err := s.guardStdout()
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+
+	// Log protocol traffic for debugging client interop
+	if s.config.Logging.LogProtocol {
+		tap, err := newWireTap(transport, s.config, s.executor.IsSensitiveEnv)
+		if err != nil {
+			return err
+		}
+		defer tap.Close()
+		transport = tap
+		s.logger.Info("logging protocol traffic", "file", ProtocolLogFile(s.config))
+	}
+
+	// Serve profiling and runtime state
+	if s.debugAddr != "" {
+		dbg, err := debug.Start(s.debugAddr, s.executor, s.logger)
+		if err != nil {
+			return err
+		}
+		defer dbg.Close()
+		s.logger.Info("serving debug endpoints", "addr", dbg.Addr())
+	}
+
+	// Serve the operator control API
+	if s.config.Control.Addr != "" {
+		ctl, err := control.Start(s.config, controlBackend{s}, s.logger)
+		if err != nil {
+			return err
+		}
+		defer ctl.Close()
+		s.logger.Info("serving control API", "addr", ctl.Addr(), "token_file", control.TokenFile(s.config))
+	}
+
+	// Record the session for replay
+	if s.recordFile != "" {
+		rec, err := recording.NewTransport(transport, s.recordFile, s.executor.IsSensitiveEnv)
+		if err != nil {
+			return err
+		}
+		defer rec.Close()
+		transport = rec
+		s.logger.Info("recording session", "file", s.recordFile)
+	}
+
+	// Start scheduled commands
+	s.scheduler.Start(ctx)
+	defer s.scheduler.Stop()
+
+	// Keep the commands of the remote catalog current
+	if s.catalog != nil && s.config.Catalog.Refresh > 0 {
+		defer s.refreshCatalog(ctx)()
+	}
 
 	// Handle signals
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
 
-	// Run server in goroutine
+	// Serve the session in a goroutine; subscribers learn of the start
+	// once it is connected
+	ss, err := s.mcpServer.Connect(ctx, transport)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to connect transport")
+	}
 	errChan := make(chan error, 1)
 	go func() {
-		errChan <- s.mcpServer.Run(ctx, transport)
+		errChan <- serve(ctx, ss)
 	}()
+	s.started()
 
 	// Wait for shutdown signal or error
 	select {
 	case sig := <-sigChan:
 		s.logger.Info("received shutdown signal", "signal", sig)
-		s.shutdown <- struct{}{}
-		cancel()
-
-		// Wait for graceful shutdown with timeout
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer shutdownCancel()
-
-		select {
-		case err := <-errChan:
-			if err != nil && !errors.Is(err, context.Canceled) {
-				return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "server error during shutdown")
-			}
-		case <-shutdownCtx.Done():
-			s.logger.Warn("shutdown timeout exceeded")
+		s.stop()
+		if err := s.awaitStop(errChan); err != nil {
+			return err
 		}
 
 	case err := <-errChan:
-		if err != nil {
+		stopping := s.State() == StateStopping && errors.Is(err, context.Canceled)
+		if err != nil && !stopping {
 			return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "server error")
 		}
 
 	case <-ctx.Done():
-		s.logger.Info("context cancelled")
-		return ctx.Err()
+		if s.State() != StateStopping {
+			s.logger.Info("context cancelled")
+			return ctx.Err()
+		}
+		// Shutdown was called
+		if err := s.awaitStop(errChan); err != nil {
+			return err
+		}
 	}
 
 	s.logger.Info("MCP server stopped")
 	return nil
 }
 
-// Shutdown gracefully shuts down the server.
+// serve serves a connected session until the client disconnects or ctx is
+// cancelled, like mcp.Server.Run. A blocked read on stdin cannot be
+// interrupted, so it does not wait for the session to finish closing.
+func serve(ctx context.Context, ss *mcp.ServerSession) error {
+	closed := make(chan error, 1)
+	go func() {
+		closed <- ss.Wait()
+	}()
+
+	select {
+	case <-ctx.Done():
+		ss.Close()
+		return ctx.Err()
+	case err := <-closed:
+		return err
+	}
+}
+
+// awaitStop waits for the MCP server to finish after its context was
+// cancelled, for at most the shutdown timeout.
+func (s *Server) awaitStop(errChan <-chan error) error {
+	timer := time.NewTimer(shutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-errChan:
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "server error during shutdown")
+		}
+	case <-timer.C:
+		s.logger.Warn("shutdown timeout exceeded")
+	}
+	return nil
+}
+
+// Shutdown gracefully shuts down the server, waiting until Run returns or
+// ctx is done.
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.mu.RLock()
-	running := s.running
+	state, done := s.state, s.done
 	s.mu.RUnlock()
 
-	if !running {
+	if state != StateStarting && state != StateRunning && state != StateStopping {
 		return nil
 	}
 
 	s.logger.Info("shutting down MCP server")
+	s.stop()
 
-	// Signal shutdown
+	// Wait for Run to return
 	select {
-	case s.shutdown <- struct{}{}:
+	case <-done:
+		return nil
 	case <-ctx.Done():
 		return ctx.Err()
 	}
+}
 
-	// Wait for server to stop
-	deadline := time.Now().Add(10 * time.Second)
-	for {
-		s.mu.RLock()
-		running = s.running
-		s.mu.RUnlock()
-
-		if !running {
-			break
-		}
-
-		if time.Now().After(deadline) {
-			return apperrors.TimeoutError("shutdown timeout", "10s")
-		}
-
-		time.Sleep(100 * time.Millisecond)
+// Close releases resources held by the server, such as active watches and
+// the history file.
+func (s *Server) Close() error {
+	s.watches.Close()
+	if err := s.usage.Close(); err != nil {
+		s.logger.WithError(err).Warn("failed to save usage analytics")
 	}
+	if err := s.telemetry.Close(); err != nil {
+		s.logger.WithError(err).Warn("failed to save telemetry counts")
+	}
+	if err := s.plugins.Close(context.Background()); err != nil {
+		s.logger.WithError(err).Warn("failed to close plugins")
+	}
+	if err := s.containers.Close(); err != nil {
+		s.logger.WithError(err).Warn("failed to close Docker client")
+	}
+	if err := s.tmux.Close(); err != nil {
+		s.logger.WithError(err).Warn("failed to kill tmux sessions")
+	}
+	s.repls.Close()
+	s.executor.WaitAlerts()
+	if err := s.discoverer.Close(); err != nil {
+		s.logger.WithError(err).Warn("failed to stop watching search paths")
+	}
+	return s.history.Close()
+}
 
-	return nil
+// usageFile returns the file usage analytics are saved to, or "" if they
+// are disabled.
+func usageFile(cfg *config.Config) string {
+	if !cfg.Usage.Enabled {
+		return ""
+	}
+	return usage.File(cfg)
 }
 
 // createTransport creates the appropriate transport based on configuration.
 func (s *Server) createTransport() (mcp.Transport, error) {
+	if s.transport != nil {
+		return s.transport, nil
+	}
+
 	switch s.config.Transport {
 	case "stdio":
 		return mcp.NewStdioTransport(), nil
@@ -216,6 +524,13 @@ func (s *Server) registerTools() error {
 		}
 	}
 
+	// Register script tools
+	for _, tool := range s.config.ScriptTools {
+		if err := s.registerScriptTool(tool); err != nil {
+			return err
+		}
+	}
+
 	// Register discovery tool
 	if err := s.registerDiscoveryTool(); err != nil {
 		return err
@@ -226,84 +541,352 @@ func (s *Server) registerTools() error {
 		return err
 	}
 
+	// Register batch execution tool
+	if err := s.registerBatchTool(); err != nil {
+		return err
+	}
+
+	// Register command check tool
+	if err := s.registerCheckCommandsTool(); err != nil {
+		return err
+	}
+
+	// Register schedule listing tool
+	if err := s.registerScheduleTool(); err != nil {
+		return err
+	}
+
+	// Register file watching tools
+	if err := s.registerWatchTools(); err != nil {
+		return err
+	}
+
+	// Register log tailing tool
+	if err := s.registerTailTool(); err != nil {
+		return err
+	}
+
+	// Register process inspection tools
+	if err := s.registerProcessTools(); err != nil {
+		return err
+	}
+
+	// Register environment inspection tool
+	if err := s.registerEnvironmentTool(); err != nil {
+		return err
+	}
+
+	// Register file transfer tools
+	if err := s.registerTransferTools(); err != nil {
+		return err
+	}
+
+	// Register HTTP request tool
+	if err := s.registerHTTPRequestTool(); err != nil {
+		return err
+	}
+
+	// Register archive tools
+	if err := s.registerArchiveTools(); err != nil {
+		return err
+	}
+
+	// Register file metadata tools
+	if err := s.registerFileInfoTools(); err != nil {
+		return err
+	}
+
+	// Register git tools
+	if err := s.registerGitTools(); err != nil {
+		return err
+	}
+
+	// Register desktop notification tool
+	if err := s.registerNotifyTool(); err != nil {
+		return err
+	}
+
+	// Register undo tool
+	if err := s.registerBackupTool(); err != nil {
+		return err
+	}
+
+	// Register quarantine tool
+	if err := s.registerQuarantineTool(); err != nil {
+		return err
+	}
+
+	// Register file deletion and permission tools
+	if err := s.registerFileOpsTools(); err != nil {
+		return err
+	}
+
+	// Register Docker container tools
+	if err := s.registerContainerTools(); err != nil {
+		return err
+	}
+
+	// Register tmux session tools
+	if err := s.registerTmuxTools(); err != nil {
+		return err
+	}
+
+	// Register REPL session tools
+	if err := s.registerREPLTools(); err != nil {
+		return err
+	}
+
+	// Register policy explanation tool
+	if err := s.registerPolicyTool(); err != nil {
+		return err
+	}
+
+	// Register capabilities tool
+	if err := s.registerCapabilitiesTool(); err != nil {
+		return err
+	}
+
+	// Register tool group selection tools
+	if err := s.registerToolsetTools(); err != nil {
+		return err
+	}
+
+	// Register execution comparison tool
+	if err := s.registerCompareTool(); err != nil {
+		return err
+	}
+
+	// Register test runner tool
+	if err := s.registerRunTestsTool(); err != nil {
+		return err
+	}
+
+	// Register linter and formatter tools
+	if err := s.registerLintTools(); err != nil {
+		return err
+	}
+
+	// Register execution re-run tool
+	if err := s.registerRerunTool(); err != nil {
+		return err
+	}
+
+	// Register output paging tool
+	if err := s.registerOutputPageTool(); err != nil {
+		return err
+	}
+
+	// Register tool search
+	if err := s.registerSearchTool(); err != nil {
+		return err
+	}
+
+	// Register the configuration summary resource
+	s.registerSummaryResource()
+
+	// Register the server event resource
+	s.registerEventsResource()
+
 	return nil
 }
 
 // registerConfigCommand registers a configured command as a tool.
 func (s *Server) registerConfigCommand(cmd config.Command) error {
+	if !s.checkCommand(cmd) {
+		return nil
+	}
+
 	// Create a copy of cmd for the closure
 	cmdCopy := cmd
 
 	tool := &mcp.Tool{
 		Name:        cmd.Name,
-		Description: cmd.Description,
+		Title:       cmd.DisplayName,
+		Description: s.describeCommand(cmd),
+	}
+	if cmd.RequiresSecondApproval {
+		tool.Description += s.msg.T(" Requires approval by two operators: the first call creates an approval request and fails with its ID; call again with approval_id once it is approved.")
 	}
 
 	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ConfigCommandParams]) (*mcp.CallToolResultFor[types.CommandExecutionResult], error) {
-		// Create a copy of the command to avoid modifying the original
-		execCmd := cmdCopy
-		
-		// If allow_args is true and client provided args, append them
-		if execCmd.AllowArgs && len(params.Arguments.Args) > 0 {
-			// Append client args to configured args
-			execCmd.Args = append(execCmd.Args, params.Arguments.Args...)
-		}
-		
-		// Execute the configured command
-		result, err := s.executor.ExecuteConfigCommand(ctx, &execCmd, params.Arguments.WorkDir)
-		if err != nil {
-			s.logger.WithError(err).Error("config command execution failed",
-				"command", execCmd.Name,
-			)
-
-			// Return error result instead of failing
-			errorContent := []mcp.Content{
-				&mcp.TextContent{
-					Text: fmt.Sprintf("Command execution failed: %s", err.Error()),
-				},
-			}
-			
-			return &mcp.CallToolResultFor[types.CommandExecutionResult]{
-				Content: errorContent,
-				StructuredContent: types.CommandExecutionResult{
-					ExitCode:     -1,
-					ErrorMessage: err.Error(),
-					StartTime:    time.Now(),
-					EndTime:      time.Now(),
-				},
-				IsError: true,
-			}, nil
-		}
+		return s.runConfigCommand(ctx, cmdCopy, params.Arguments)
+	}
 
-		// Create content array with text representation
-		content := []mcp.Content{
+	addConfiguredTool(s, tool, handler)
+	s.registerCommandPrompt(cmd)
+
+	s.logger.Debug("registered config command tool",
+		"name", cmd.Name,
+		"command", cmd.Command,
+	)
+
+	return nil
+}
+
+// runConfigCommand runs a configured command for a tool call, appending
+// the client's args when the command allows them.
+func (s *Server) runConfigCommand(ctx context.Context, cmd config.Command, args ConfigCommandParams) (*mcp.CallToolResultFor[types.CommandExecutionResult], error) {
+	// cmd is a copy, so the original is not modified
+	execCmd := cmd
+	
+	// If allow_args is true and client provided args, append them
+	if execCmd.AllowArgs && len(args.Args) > 0 {
+		// Append client args to configured args
+		execCmd.Args = append(execCmd.Args, args.Args...)
+	}
+	
+	// Execute the configured command
+	result, err := s.executor.ExecuteConfigCommandWithOptions(ctx, &execCmd, args.WorkDir,
+		executor.ConfigCommandOptions{Force: args.Force, ApprovalID: args.ApprovalID})
+	result = s.recordExecution(ctx, execCmd.Name, configCommandRequest(&execCmd, args.WorkDir, result), result, err)
+	if err != nil {
+		s.logger.WithError(err).Error("config command execution failed",
+			"command", execCmd.Name,
+		)
+
+		// Return error result instead of failing
+		errorContent := []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("Command executed successfully.\nStdout: %s\nStderr: %s\nExit Code: %d", 
-					result.Stdout, result.Stderr, result.ExitCode),
+				Text: s.msg.Sprintf("Command execution failed: %s", err.Error()),
 			},
 		}
-
+		
 		return &mcp.CallToolResultFor[types.CommandExecutionResult]{
-			Content:           content,
-			StructuredContent: *result,
+			Content: errorContent,
+			StructuredContent: types.CommandExecutionResult{
+				ExitCode:     -1,
+				ErrorMessage: err.Error(),
+				StartTime:    time.Now(),
+				EndTime:      time.Now(),
+				Preflight:    executor.PreflightFailureOf(err),
+			},
+			IsError: true,
 		}, nil
 	}
 
-	mcp.AddTool(s.mcpServer, tool, handler)
+	// Create content array with text representation
+	text := s.msg.Sprintf("Command executed successfully.\nStdout: %s\nStderr: %s\nExit Code: %d", 
+		result.Stdout, result.Stderr, result.ExitCode)
+	if result.WorkDir != "" {
+		text += fmt.Sprintf("\nRan in %s", result.WorkDir)
+	}
+	if result.LockWait > 0 {
+		text += fmt.Sprintf("\nWaited %s for workdir lock", result.LockWait.Round(time.Millisecond))
+	}
+	text += formatRunEnvironment(result) + formatSpilledOutput(result) + formatOutputSummary(result.Summary) + formatQuarantine(result)
+	if result.Changes != nil {
+		text += "\n" + formatFileChanges(result.Changes)
+	}
+	if result.SnapshotRef != "" {
+		text += fmt.Sprintf("\nGit snapshot: %s (restore with: git restore --source=%s --worktree -- .)", result.SnapshotRef, result.SnapshotRef)
+	}
+	content := []mcp.Content{
+		&mcp.TextContent{
+			Text: text,
+		},
+	}
 
-	s.logger.Debug("registered config command tool",
-		"name", cmd.Name,
-		"command", cmd.Command,
-	)
+	return &mcp.CallToolResultFor[types.CommandExecutionResult]{
+		Content:           content,
+		StructuredContent: *result,
+	}, nil
+}
 
-	return nil
+// formatSpilledOutput points to the files holding output that exceeded
+// the spill threshold.
+func formatSpilledOutput(result *types.CommandExecutionResult) string {
+	var b strings.Builder
+	if result.StdoutFile != "" {
+		fmt.Fprintf(&b, "\nStdout was truncated; full output in %s", result.StdoutFile)
+	}
+	if result.StderrFile != "" {
+		fmt.Fprintf(&b, "\nStderr was truncated; full output in %s", result.StderrFile)
+	}
+	return b.String()
+}
+
+// formatQuarantine explains a run that failed because macOS quarantines
+// its binary.
+func formatQuarantine(result *types.CommandExecutionResult) string {
+	if result.Quarantine == nil {
+		return ""
+	}
+	return "\n" + result.ErrorMessage
+}
+
+// formatRunEnvironment names the project environment a command ran in
+// and the toolchains pinned for it.
+func formatRunEnvironment(result *types.CommandExecutionResult) string {
+	var b strings.Builder
+	if env := result.DevEnvironment; env != nil {
+		fmt.Fprintf(&b, "\nRan in the %s environment of %s", env.Kind, env.Root)
+	}
+	if len(result.Toolchains) > 0 {
+		pins := make([]string, len(result.Toolchains))
+		for i, tc := range result.Toolchains {
+			pins[i] = fmt.Sprintf("%s (%s)", tc.Name, tc.Bin)
+		}
+		b.WriteString("\nPinned toolchains: " + strings.Join(pins, ", "))
+	}
+	return b.String()
+}
+
+// formatOutputSummary renders the digest of oversized output streams.
+func formatOutputSummary(summary *types.OutputSummary) string {
+	if summary == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, stream := range []struct {
+		name    string
+		summary *types.StreamSummary
+	}{{"Stdout", summary.Stdout}, {"Stderr", summary.Stderr}} {
+		s := stream.summary
+		if s == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s summary: %d bytes, %d lines, %d errors, %d warnings", stream.name, s.Bytes, s.Lines, s.Errors, s.Warnings)
+		for _, line := range s.ErrorLines {
+			fmt.Fprintf(&b, "\n  error at line %d: %s", line.Number, line.Text)
+		}
+		for _, line := range s.WarningLines {
+			fmt.Fprintf(&b, "\n  warning at line %d: %s", line.Number, line.Text)
+		}
+		if len(s.Tail) > 0 {
+			b.WriteString("\n  last lines:")
+			for _, line := range s.Tail {
+				fmt.Fprintf(&b, "\n  %d: %s", line.Number, line.Text)
+			}
+		}
+	}
+	return b.String()
+}
+
+// formatFileChanges summarizes the files a command touched.
+func formatFileChanges(c *types.FileChanges) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Files changed: %d created, %d modified, %d deleted",
+		len(c.Created), len(c.Modified), len(c.Deleted))
+	if c.Truncated {
+		b.WriteString(" (truncated)")
+	}
+	for _, path := range c.Created {
+		fmt.Fprintf(&b, "\n  + %s", path)
+	}
+	for _, path := range c.Modified {
+		fmt.Fprintf(&b, "\n  ~ %s", path)
+	}
+	for _, path := range c.Deleted {
+		fmt.Fprintf(&b, "\n  - %s", path)
+	}
+	return b.String()
 }
 
 // registerDiscoveryTool registers the command discovery tool.
 func (s *Server) registerDiscoveryTool() error {
 	tool := &mcp.Tool{
 		Name:        "discover_commands",
-		Description: "Discover available system commands. Use pattern parameter to filter commands (e.g., 'git*', 'npm'). Returns command names, paths, and descriptions.",
+		Description: "Discover available system commands. Use pattern parameter to filter commands (e.g., 'git*', 'npm'). Returns command names, paths, and descriptions; with include_desc, also usage examples and common flags. Results are sorted stably; when truncated, pass next_cursor as cursor to get the next page.",
 	}
 
 	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[types.CommandDiscoveryRequest]) (*mcp.CallToolResultFor[types.CommandDiscoveryResult], error) {
@@ -317,11 +900,26 @@ func (s *Server) registerDiscoveryTool() error {
 		var commandList []string
 		for _, cmd := range result.Commands {
 			commandList = append(commandList, fmt.Sprintf("%s: %s (%s)", cmd.Name, cmd.Description, cmd.Path))
+			for _, example := range cmd.UsageExamples {
+				commandList = append(commandList, "  $ "+example)
+			}
+			if len(cmd.CommonFlags) > 0 {
+				flags := make([]string, len(cmd.CommonFlags))
+				for i, f := range cmd.CommonFlags {
+					flags[i] = f.Flag
+				}
+				commandList = append(commandList, "  flags: "+strings.Join(flags, ", "))
+			}
 		}
 		
+		text := fmt.Sprintf("Found %d commands:\n%s", result.TotalFound, strings.Join(commandList, "\n"))
+		if result.Truncated {
+			text += fmt.Sprintf("\nShowing %d-%d; pass cursor %q for the next page.",
+				result.Offset+1, result.Offset+len(result.Commands), result.NextCursor)
+		}
 		content := []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("Found %d commands:\n%s", result.TotalFound, strings.Join(commandList, "\n")),
+				Text: text,
 			},
 		}
 
@@ -331,7 +929,7 @@ func (s *Server) registerDiscoveryTool() error {
 		}, nil
 	}
 
-	mcp.AddTool(s.mcpServer, tool, handler)
+	addTool(s, tool, handler)
 
 	s.logger.Debug("registered discovery tool")
 
@@ -346,80 +944,98 @@ func (s *Server) registerExecutionTool() error {
 	}
 
 	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[types.CommandExecutionRequest]) (*mcp.CallToolResultFor[types.CommandExecutionResult], error) {
-		// Log the request
-		s.logger.Info("executing command",
-			"command", params.Arguments.Command,
-			"args", params.Arguments.Args,
-			"workdir", params.Arguments.WorkDir,
-		)
+		return s.runCommand(ctx, params.Arguments)
+	}
 
-		result, err := s.executor.Execute(ctx, &params.Arguments)
-		if err != nil {
-			s.logger.WithError(err).Error("command execution failed")
+	addTool(s, tool, handler)
 
-			// Return error result instead of failing
-			errorContent := []mcp.Content{
-				&mcp.TextContent{
-					Text: fmt.Sprintf("Command execution failed: %s", err.Error()),
-				},
-			}
-			
-			return &mcp.CallToolResultFor[types.CommandExecutionResult]{
-				Content: errorContent,
-				StructuredContent: types.CommandExecutionResult{
-					ExitCode:     -1,
-					ErrorMessage: err.Error(),
-					StartTime:    time.Now(),
-					EndTime:      time.Now(),
-				},
-				IsError: true,
-			}, nil
-		}
+	s.logger.Debug("registered execution tool")
 
-		// Create content array with text representation
-		content := []mcp.Content{
+	return nil
+}
+
+// runCommand runs a command for a tool call through the policy.
+func (s *Server) runCommand(ctx context.Context, req types.CommandExecutionRequest) (*mcp.CallToolResultFor[types.CommandExecutionResult], error) {
+	// Log the request
+	s.logger.Info("executing command",
+		"command", req.Command,
+		"args", req.Args,
+		"workdir", req.WorkDir,
+	)
+
+	result, err := s.executor.Execute(ctx, &req)
+	result = s.recordExecution(ctx, "execute_command", req, result, err)
+	if err != nil {
+		s.logger.WithError(err).Error("command execution failed")
+
+		// Return error result instead of failing
+		errorContent := []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("Command executed successfully.\nStdout: %s\nStderr: %s\nExit Code: %d", 
-					result.Stdout, result.Stderr, result.ExitCode),
+				Text: s.msg.Sprintf("Command execution failed: %s", err.Error()),
 			},
 		}
-
+		
 		return &mcp.CallToolResultFor[types.CommandExecutionResult]{
-			Content:           content,
-			StructuredContent: *result,
+			Content: errorContent,
+			StructuredContent: types.CommandExecutionResult{
+				ExitCode:     -1,
+				ErrorMessage: err.Error(),
+				StartTime:    time.Now(),
+				EndTime:      time.Now(),
+			},
+			IsError: true,
 		}, nil
 	}
 
-	mcp.AddTool(s.mcpServer, tool, handler)
-
-	s.logger.Debug("registered execution tool")
+	// Create content array with text representation
+	content := []mcp.Content{
+		&mcp.TextContent{
+			Text: s.msg.Sprintf("Command executed successfully.\nStdout: %s\nStderr: %s\nExit Code: %d", 
+				result.Stdout, result.Stderr, result.ExitCode) + formatRunEnvironment(result) + formatSpilledOutput(result) + formatOutputSummary(result.Summary) + formatQuarantine(result),
+		},
+	}
 
-	return nil
+	return &mcp.CallToolResultFor[types.CommandExecutionResult]{
+		Content:           content,
+		StructuredContent: *result,
+	}, nil
 }
 
 // GetStats returns server statistics.
 func (s *Server) GetStats() ServerStats {
 	return ServerStats{
 		Running:        s.IsRunning(),
+		State:          s.State(),
 		ActiveCommands: s.executor.GetActiveCount(),
+		Crashes:        s.crashes.Load() + s.executor.Panics(),
 	}
 }
 
 // IsRunning returns true if the server is running.
 func (s *Server) IsRunning() bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.running
+	switch s.State() {
+	case StateStarting, StateRunning, StateStopping:
+		return true
+	default:
+		return false
+	}
 }
 
 // ServerStats contains server statistics.
 type ServerStats struct {
 	Running        bool
+	State          State
 	ActiveCommands int
+	Crashes        int64 // Panics recovered in handlers and executions
 }
 
 // ConfigCommandParams represents parameters for configured commands.
 type ConfigCommandParams struct {
 	WorkDir string   `json:"workdir,omitempty"`
 	Args    []string `json:"args,omitempty"` // Only if AllowArgs is true
+	Force   bool     `json:"force,omitempty"` // Skip the workdir lock of mutating commands
+
+	// ApprovalID runs a command requiring a second approval under an
+	// approved request
+	ApprovalID string `json:"approval_id,omitempty"`
 }
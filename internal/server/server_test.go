@@ -7,6 +7,7 @@ import (
 
 	"github.com/mjmorales/simple-mcp-runner/pkg/config"
 	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func TestNew(t *testing.T) {
@@ -93,7 +94,7 @@ func TestServer_Shutdown(t *testing.T) {
 	}
 
 	// Test shutdown timeout
-	srv.running = true // Simulate running state
+	srv.state = StateRunning // Simulate running state
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
 	
@@ -119,7 +120,7 @@ func TestServer_IsRunning(t *testing.T) {
 		t.Error("new server should not be running")
 	}
 
-	srv.running = true
+	srv.state = StateRunning
 	if !srv.IsRunning() {
 		t.Error("server should report as running")
 	}
@@ -158,4 +159,46 @@ func TestConfigCommandParams(t *testing.T) {
 	if len(params.Args) != 2 {
 		t.Error("Args not set correctly")
 	}
-}
\ No newline at end of file
+}
+
+// newTestServer creates a server with cfg, closed when the test ends.
+func newTestServer(t *testing.T, cfg *config.Config) *Server {
+	t.Helper()
+	srv, err := New(Options{Config: cfg})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return srv
+}
+
+// connectClient connects a client to srv in memory, a plain test client
+// when client is nil. Both ends of the session are closed when the test
+// ends.
+func connectClient(t *testing.T, srv *Server, client *mcp.Client) *mcp.ClientSession {
+	t.Helper()
+	if client == nil {
+		client = mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	}
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := srv.mcpServer.Connect(ctx, serverTransport)
+	if err != nil {
+		t.Fatalf("server connect error = %v", err)
+	}
+	t.Cleanup(func() { ss.Close() })
+	cs, err := client.Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("client connect error = %v", err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
+// newTestSession creates a server with cfg and connects a test client to
+// it, for tests that call tools and read resources like a client would.
+func newTestSession(t *testing.T, cfg *config.Config) (*Server, *mcp.ClientSession) {
+	t.Helper()
+	srv := newTestServer(t, cfg)
+	return srv, connectClient(t, srv, nil)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/security"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sessionInfo is what a client reported when it initialized its session.
+type sessionInfo struct {
+	id            string
+	clientName    string
+	clientVersion string
+	started       time.Time
+
+	mu       sync.Mutex
+	groups   []string // Selected tool groups
+	welcomed bool     // Sent the welcome message
+	returned int64    // Bytes of tool results returned, for the output budget
+	encoding string   // Encoding of compressed results the client offered
+}
+
+// securityMiddleware records each session's client on initialize and
+// attaches the caller's security context to every request, so handlers
+// pass it on to the executor with their context.
+func (s *Server) securityMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, ss *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		if p, ok := params.(*mcp.InitializeParams); ok {
+			s.recordSession(ss, p)
+		}
+		return next(security.WithContext(ctx, s.securityContext(ss)), ss, method, params)
+	}
+}
+
+// recordSession stores the client a session belongs to.
+func (s *Server) recordSession(ss *mcp.ServerSession, params *mcp.InitializeParams) {
+	info := &sessionInfo{id: ss.ID(), started: time.Now(), groups: slices.Clone(s.config.Server.DefaultToolGroups)}
+	if info.id == "" {
+		// stdio sessions have no transport ID
+		info.id = newSessionID()
+	}
+	if params.ClientInfo != nil {
+		info.clientName = params.ClientInfo.Name
+		info.clientVersion = params.ClientInfo.Version
+	}
+	info.encoding = negotiateEncoding(params)
+	s.sessions.Store(ss, info)
+
+	s.logger.Info("client session started",
+		"session", info.id,
+		"client", info.clientName,
+		"client_version", info.clientVersion,
+	)
+	s.emit(types.EventSessionStarted, "client session started", map[string]any{
+		"session":        info.id,
+		"client":         info.clientName,
+		"client_version": info.clientVersion,
+	})
+}
+
+// securityContext returns the security context of requests on a session.
+func (s *Server) securityContext(ss *mcp.ServerSession) *security.Context {
+	sc := &security.Context{
+		SessionID: ss.ID(),
+		Principal: s.principal,
+	}
+	if v, ok := s.sessions.Load(ss); ok {
+		info := v.(*sessionInfo)
+		sc.SessionID = info.id
+		sc.ClientName = info.clientName
+		sc.ClientVersion = info.clientVersion
+	}
+	return sc
+}
+
+// newSessionID returns a random session identifier.
+func newSessionID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to time
+		return "s" + time.Now().Format("20060102150405.000000000")
+	}
+	return "s" + hex.EncodeToString(b)
+}
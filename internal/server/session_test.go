@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/history"
+	"github.com/mjmorales/simple-mcp-runner/internal/security"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_securityContext(t *testing.T) {
+	srv := newTestServer(t, config.Default())
+
+	// A probe tool reports the security context its handler receives
+	var got *security.Context
+	mcp.AddTool(srv.mcpServer, &mcp.Tool{Name: "probe"}, func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[struct{}]) (*mcp.CallToolResultFor[struct{}], error) {
+		got = security.FromContext(ctx)
+		return &mcp.CallToolResultFor[struct{}]{}, nil
+	})
+
+	ctx := context.Background()
+	cs := connectClient(t, srv, mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.2.3"}, nil))
+
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "probe", Arguments: map[string]any{}}); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+
+	if got == nil {
+		t.Fatal("expected a security context")
+	}
+	if got.ClientName != "test-client" || got.ClientVersion != "1.2.3" {
+		t.Errorf("unexpected client %q %q", got.ClientName, got.ClientVersion)
+	}
+	if got.SessionID == "" {
+		t.Error("expected a session ID")
+	}
+	if got.Principal != security.LocalPrincipal() {
+		t.Errorf("expected local principal %q, got %q", security.LocalPrincipal(), got.Principal)
+	}
+
+	// Executions are recorded with the session and policy decision
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "execute_command", Arguments: map[string]any{"command": "rm"}}); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	records := srv.history.List(history.Filter{Limit: 1})
+	if len(records) != 1 {
+		t.Fatalf("expected a history record, got %d", len(records))
+	}
+	rec := records[0]
+	if rec.Session != got.SessionID || rec.Client != "test-client" || rec.User != got.Principal {
+		t.Errorf("unexpected record identity %q %q %q", rec.Session, rec.Client, rec.User)
+	}
+	if rec.Decision != types.PolicyDecisionDenied {
+		t.Errorf("expected a denied decision, got %q", rec.Decision)
+	}
+}
+
+func TestServer_tripwire(t *testing.T) {
+	cfg := config.Default()
+	cfg.Security.Tripwires = []config.Tripwire{{Name: "shadow", Commands: []string{"cat"}, ArgsPattern: "/etc/shadow"}}
+	srv, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "execute_command", Arguments: map[string]any{"command": "cat", "args": []string{"/etc/shadow"}}})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if !res.IsError {
+		t.Error("expected the tripwire to be denied")
+	}
+
+	// The record names the tripwire; the events clients can read do not
+	records := srv.history.List(history.Filter{Limit: 1})
+	if len(records) != 1 || records[0].Tripwire != "shadow" || records[0].Decision != types.PolicyDecisionDenied {
+		t.Fatalf("unexpected records %+v", records)
+	}
+	for _, ev := range srv.events.list() {
+		if strings.Contains(fmt.Sprint(ev), "shadow") && strings.Contains(fmt.Sprint(ev), "tripwire") {
+			t.Errorf("event %+v reveals the tripwire", ev)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// maxStrayOutput limits the stray stdout output logged per line.
+const maxStrayOutput = 200
+
+// guardStdout replaces os.Stdout, which carries the JSON-RPC stream of the
+// stdio transport, with a pipe whose output is logged as warnings instead
+// of corrupting the stream. The transport must be created first, as it
+// keeps the original stdout. It returns a function restoring os.Stdout.
+func (s *Server) guardStdout() (func(), error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to guard stdout")
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if len(line) > maxStrayOutput {
+				line = line[:maxStrayOutput] + "..."
+			}
+			s.logger.Warn("discarded write to stdout, which carries the MCP protocol", "output", line)
+		}
+		// Keep draining after an overlong line so writers never block
+		_, _ = io.Copy(io.Discard, r)
+	}()
+
+	return func() {
+		os.Stdout = stdout
+		w.Close()
+		<-done
+		r.Close()
+	}, nil
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func TestServer_guardStdout(t *testing.T) {
+	var logs bytes.Buffer
+	log, err := logger.New(logger.Options{Level: "info", Output: &logs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := New(Options{Config: config.Default(), Logger: log})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	stdout := os.Stdout
+	restore, err := srv.guardStdout()
+	if err != nil {
+		t.Fatalf("guardStdout() error = %v", err)
+	}
+	if os.Stdout == stdout {
+		t.Fatal("expected stdout to be replaced")
+	}
+	fmt.Println("stray output")
+	fmt.Println(strings.Repeat("x", 100000))
+	restore()
+
+	if os.Stdout != stdout {
+		t.Error("expected stdout to be restored")
+	}
+	if !strings.Contains(logs.String(), `output="stray output"`) {
+		t.Errorf("expected the stray output to be logged, got %q", logs.String())
+	}
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// configSummaryURI is the resource summarizing the configuration.
+const configSummaryURI = "runner://config-summary"
+
+// configSummary describes what the server will and will not do, without
+// secrets.
+func (s *Server) configSummary() types.ConfigSummary {
+	s.commandsMu.RLock()
+	commands := make([]string, 0, len(s.config.Commands))
+	for _, cmd := range s.config.Commands {
+		commands = append(commands, cmd.Name)
+	}
+	s.commandsMu.RUnlock()
+
+	sec := s.config.Security
+	return types.ConfigSummary{
+		Capabilities:    capabilities(s.config),
+		Tools:           s.registeredTools(),
+		Commands:        commands,
+		AllowedPaths:    redactHome(sec.AllowedPaths),
+		DeniedPaths:     redactHome(sec.DeniedPaths),
+		AllowedCommands: sec.AllowedCommands,
+		BlockedCommands: sec.BlockedCommands,
+	}
+}
+
+// redactHome shortens paths under the home directory to start with ~, so
+// the summary does not reveal the user name.
+func redactHome(paths []string) []string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return paths
+	}
+	redacted := make([]string, 0, len(paths))
+	for _, path := range paths {
+		rel, err := filepath.Rel(home, path)
+		switch {
+		case err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)):
+			redacted = append(redacted, path)
+		case rel == ".":
+			redacted = append(redacted, "~")
+		default:
+			redacted = append(redacted, "~"+string(filepath.Separator)+rel)
+		}
+	}
+	return redacted
+}
+
+// summaryText renders a configuration summary for people.
+func summaryText(sum types.ConfigSummary) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s on %s: %d tools, %d configured commands.\n", sum.App, sum.OS, len(sum.Tools), len(sum.Commands))
+	b.WriteString(capabilitiesText(sum.Capabilities))
+	b.WriteString("\n")
+	if len(sum.AllowedPaths) > 0 {
+		fmt.Fprintf(&b, "Allowed paths: %s.\n", strings.Join(sum.AllowedPaths, ", "))
+	} else {
+		b.WriteString("Allowed paths: any.\n")
+	}
+	if len(sum.DeniedPaths) > 0 {
+		fmt.Fprintf(&b, "Denied paths: %s.\n", strings.Join(sum.DeniedPaths, ", "))
+	}
+	if len(sum.AllowedCommands) > 0 {
+		fmt.Fprintf(&b, "Allowed commands: %s.\n", strings.Join(sum.AllowedCommands, ", "))
+	}
+	if len(sum.BlockedCommands) > 0 {
+		fmt.Fprintf(&b, "Blocked commands: %s.\n", strings.Join(sum.BlockedCommands, ", "))
+	}
+	fmt.Fprintf(&b, "Tools: %s.", strings.Join(sum.Tools, ", "))
+
+	return b.String()
+}
+
+// registerSummaryResource registers the configuration summary resource.
+func (s *Server) registerSummaryResource() {
+	resource := &mcp.Resource{
+		URI:         configSummaryURI,
+		Name:        "config-summary",
+		Title:       s.msg.T("Server configuration summary"),
+		Description: s.msg.T("What this server will and won't do: its tools, configured commands, security profile, allowed paths and limits, without secrets."),
+		MIMEType:    "application/json",
+	}
+
+	s.mcpServer.AddResource(resource, func(ctx context.Context, ss *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+		data, err := json.MarshalIndent(s.configSummary(), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: configSummaryURI, MIMEType: "application/json", Text: string(data)}},
+		}, nil
+	})
+
+	s.logger.Debug("registered config summary resource")
+}
+
+// welcomeMiddleware sends the configuration summary as a log message
+// once per session, when the client first sets a log level: log messages
+// are only sent to clients that did.
+func (s *Server) welcomeMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, ss *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		result, err := next(ctx, ss, method, params)
+		if err != nil || method != "logging/setLevel" || !s.config.Server.WelcomeMessage {
+			return result, err
+		}
+
+		v, ok := s.sessions.Load(ss)
+		if !ok {
+			return result, err
+		}
+		info := v.(*sessionInfo)
+		info.mu.Lock()
+		welcomed := info.welcomed
+		info.welcomed = true
+		info.mu.Unlock()
+		if welcomed {
+			return result, err
+		}
+
+		if logErr := ss.Log(ctx, &mcp.LoggingMessageParams{
+			Level:  "notice",
+			Logger: "runner",
+			Data:   summaryText(s.configSummary()),
+		}); logErr != nil {
+			s.logger.WithError(logErr).Debug("failed to send welcome message", "session", info.id)
+		}
+		return result, err
+	}
+}
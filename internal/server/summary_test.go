@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_configSummary(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	cfg := config.Default()
+	cfg.Server.WelcomeMessage = true
+	cfg.Security.AllowedPaths = []string{filepath.Join(home, "projects"), os.TempDir()}
+	cfg.Commands = []config.Command{{Name: "build", Description: "Build", Command: "go"}}
+	srv := newTestServer(t, cfg)
+
+	logs := make(chan *mcp.LoggingMessageParams, 4)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(ctx context.Context, cs *mcp.ClientSession, params *mcp.LoggingMessageParams) {
+			logs <- params
+		},
+	})
+	cs := connectClient(t, srv, client)
+	ctx := context.Background()
+
+	res, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: configSummaryURI})
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	var sum types.ConfigSummary
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &sum); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(sum.Tools, "execute_command") || !slices.Contains(sum.Tools, "build") {
+		t.Errorf("tools = %v", sum.Tools)
+	}
+	if !slices.Equal(sum.Commands, []string{"build"}) {
+		t.Errorf("commands = %v", sum.Commands)
+	}
+	want := "~" + string(filepath.Separator) + "projects"
+	if len(sum.AllowedPaths) != 2 || sum.AllowedPaths[0] != want || strings.Contains(res.Contents[0].Text, home+string(filepath.Separator)) {
+		t.Errorf("allowed paths = %v, want the home directory redacted", sum.AllowedPaths)
+	}
+
+	// The welcome message is sent once log messages can be
+	for range 2 {
+		if err := cs.SetLevel(ctx, &mcp.SetLevelParams{Level: "info"}); err != nil {
+			t.Fatalf("SetLevel() error = %v", err)
+		}
+	}
+	select {
+	case msg := <-logs:
+		if msg.Logger != "runner" || !strings.Contains(msg.Data.(string), "Allowed paths: "+want) {
+			t.Errorf("welcome message = %+v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no welcome message")
+	}
+	select {
+	case msg := <-logs:
+		t.Errorf("unexpected second welcome message %+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/tail"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TailFileParams represents parameters for tailing a file.
+type TailFileParams struct {
+	Path   string `json:"path"`
+	Lines  int    `json:"lines,omitempty"`  // Lines to read; 10 by default
+	Offset int64  `json:"offset,omitempty"` // Read lines after this offset instead of the last lines
+	Follow string `json:"follow,omitempty"` // How long to follow appended lines, e.g. "30s"
+}
+
+// registerTailTool registers the log tailing tool.
+func (s *Server) registerTailTool() error {
+	tool := &mcp.Tool{
+		Name:        "tail_file",
+		Description: "Read the last lines of a file by absolute path, such as an application log, without tail. Set follow to a duration such as 30s to keep watching: appended lines are sent to the client as log notifications and returned when following ends. Pass the returned offset back to continue where a previous call stopped.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[TailFileParams]) (*mcp.CallToolResultFor[tail.Result], error) {
+		args := params.Arguments
+
+		opts := tail.Options{Lines: args.Lines, Offset: args.Offset}
+		if args.Follow != "" {
+			d, err := time.ParseDuration(args.Follow)
+			if err != nil || d < 0 {
+				return tailErrorResult(apperrors.ValidationError("invalid follow duration: "+args.Follow, "follow")), nil
+			}
+			opts.Follow = d
+		}
+
+		result, err := tail.Tail(ctx, s.config, args.Path, opts, func(ctx context.Context, lines []string) {
+			if err := s.logToolOutput(ctx, ss, "tail_file", "tail", map[string]any{"path": args.Path, "lines": lines}); err != nil {
+				s.logger.WithError(err).Debug("failed to send tail notification", "path", args.Path)
+			}
+		})
+		if err != nil {
+			s.logger.WithError(err).Debug("tail failed", "path", args.Path)
+			return tailErrorResult(err), nil
+		}
+
+		return &mcp.CallToolResultFor[tail.Result]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatTail(result)}},
+			StructuredContent: *result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered tail tool")
+
+	return nil
+}
+
+// formatTail renders tailed lines as text.
+func formatTail(result *tail.Result) string {
+	var b strings.Builder
+
+	for _, line := range result.Lines {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	if result.Stopped != "" {
+		fmt.Fprintf(&b, "--- followed %d lines, stopped at %s\n", len(result.Followed), result.Stopped)
+		for _, line := range result.Followed {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	if result.Reset {
+		b.WriteString("--- the file was truncated or replaced and read from the start\n")
+	}
+	fmt.Fprintf(&b, "--- offset %d of %d bytes\n", result.Offset, result.Size)
+
+	return b.String()
+}
+
+// tailErrorResult converts an error into a tool error result.
+func tailErrorResult(err error) *mcp.CallToolResultFor[tail.Result] {
+	return &mcp.CallToolResultFor[tail.Result]{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Tail failed: %s", err.Error())},
+		},
+		IsError: true,
+	}
+}
@@ -0,0 +1,269 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/tmux"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxSendWait limits how long send_tmux_keys waits before capturing.
+const maxSendWait = 10 * time.Second
+
+// CreateTmuxSessionParams represents parameters for starting a session.
+type CreateTmuxSessionParams struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command"`
+	WorkDir string   `json:"workdir,omitempty"`
+	Width   int      `json:"width,omitempty"`
+	Height  int      `json:"height,omitempty"`
+
+	// ApprovalID starts a program held for approval under an approved
+	// request
+	ApprovalID string `json:"approval_id,omitempty"`
+}
+
+// ListTmuxSessionsParams represents parameters for listing sessions.
+type ListTmuxSessionsParams struct{}
+
+// SendTmuxKeysParams represents parameters for typing into a session.
+type SendTmuxKeysParams struct {
+	Session string   `json:"session"`
+	Text    string   `json:"text,omitempty"`
+	Keys    []string `json:"keys,omitempty"`
+	Enter   bool     `json:"enter,omitempty"`
+	Wait    string   `json:"wait,omitempty"` // Capture the screen after this long
+}
+
+// CaptureTmuxPaneParams represents parameters for reading a session.
+type CaptureTmuxPaneParams struct {
+	Session string `json:"session"`
+	Lines   int    `json:"lines,omitempty"`
+}
+
+// KillTmuxSessionParams represents parameters for ending a session.
+type KillTmuxSessionParams struct {
+	Session string `json:"session"`
+}
+
+// TmuxSessionList lists sessions.
+type TmuxSessionList struct {
+	Sessions []tmux.Session `json:"sessions"`
+}
+
+// registerTmuxTools registers the tmux session tools unless they are
+// disabled.
+func (s *Server) registerTmuxTools() error {
+	if s.tmux == nil {
+		s.logger.Debug("tmux tools disabled")
+		return nil
+	}
+
+	s.registerCreateTmuxSessionTool()
+	s.registerListTmuxSessionsTool()
+	s.registerSendTmuxKeysTool()
+	s.registerCaptureTmuxPaneTool()
+	s.registerKillTmuxSessionTool()
+
+	s.logger.Debug("registered tmux tools")
+
+	return nil
+}
+
+// tmuxError returns the error result of a tmux tool.
+func tmuxError[T any](format string, err error) *mcp.CallToolResultFor[T] {
+	return &mcp.CallToolResultFor[T]{
+		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf(format, err.Error())}},
+		IsError: true,
+	}
+}
+
+func (s *Server) registerCreateTmuxSessionTool() {
+	tool := &mcp.Tool{
+		Name:        "create_tmux_session",
+		Description: "Start a long-lived interactive program, such as a REPL or dev server, in a new detached tmux session owned by the server. command is the program and its arguments, checked against the security policy like execute_command, including approvals (pass approval_id once approved). Type into it with send_tmux_keys and read its screen with capture_tmux_pane; the screen remains readable after the program exits.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[CreateTmuxSessionParams]) (*mcp.CallToolResultFor[tmux.Session], error) {
+		args := params.Arguments
+
+		// The program goes through the whole policy, as if run by
+		// execute_command
+		req := &types.CommandExecutionRequest{WorkDir: args.WorkDir, ApprovalID: args.ApprovalID}
+		if len(args.Command) > 0 {
+			req.Command, req.Args = args.Command[0], args.Command[1:]
+		}
+		if _, err := s.executor.Admit(ctx, req); err != nil {
+			s.logger.WithError(err).Warn("tmux session denied", "session", args.Name)
+			return tmuxError[tmux.Session]("Creating tmux session failed: %s", err), nil
+		}
+
+		session, err := s.tmux.Create(ctx, tmux.CreateRequest{
+			Name:    args.Name,
+			Command: args.Command,
+			WorkDir: args.WorkDir,
+			Width:   args.Width,
+			Height:  args.Height,
+		})
+		if err != nil {
+			s.logger.WithError(err).Error("tmux session creation failed", "session", args.Name)
+			return tmuxError[tmux.Session]("Creating tmux session failed: %s", err), nil
+		}
+		s.logger.Info("tmux session created", "session", session.Name, "command", args.Command)
+
+		return &mcp.CallToolResultFor[tmux.Session]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Created tmux session %s running %s", session.Name, strings.Join(args.Command, " "))}},
+			StructuredContent: *session,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerListTmuxSessionsTool() {
+	tool := &mcp.Tool{
+		Name:        "list_tmux_sessions",
+		Description: "List the tmux sessions owned by the server with the program each runs, its pid, and whether it exited and with which status.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ListTmuxSessionsParams]) (*mcp.CallToolResultFor[TmuxSessionList], error) {
+		sessions, err := s.tmux.List(ctx)
+		if err != nil {
+			s.logger.WithError(err).Error("tmux session listing failed")
+			return tmuxError[TmuxSessionList]("Listing tmux sessions failed: %s", err), nil
+		}
+
+		return &mcp.CallToolResultFor[TmuxSessionList]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatTmuxSessions(sessions)}},
+			StructuredContent: TmuxSessionList{Sessions: sessions},
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerSendTmuxKeysTool() {
+	tool := &mcp.Tool{
+		Name:        "send_tmux_keys",
+		Description: "Type into a tmux session created by create_tmux_session: text is typed as is, then keys are pressed by tmux name (such as C-c, Up or Escape), then Enter if enter is set. With wait, such as 2s, the screen is captured after waiting and returned. Keys are not checked against the security policy: the session's program can do whatever it allows, so a shell in a session is an unrestricted shell.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[SendTmuxKeysParams]) (*mcp.CallToolResultFor[any], error) {
+		args := params.Arguments
+
+		var wait time.Duration
+		if args.Wait != "" {
+			d, err := time.ParseDuration(args.Wait)
+			if err != nil || d < 0 {
+				return tmuxError[any]("Sending keys failed: %s", apperrors.ValidationError("invalid wait duration", "wait")), nil
+			}
+			wait = min(d, maxSendWait)
+		}
+
+		if err := s.executor.AdmitInput(args.Session); err != nil {
+			return tmuxError[any]("Sending keys failed: %s", err), nil
+		}
+		if err := s.tmux.SendKeys(ctx, args.Session, args.Text, args.Keys, args.Enter); err != nil {
+			s.logger.WithError(err).Debug("tmux send-keys failed", "session", args.Session)
+			return tmuxError[any]("Sending keys failed: %s", err), nil
+		}
+		if wait == 0 {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: "Keys sent to " + args.Session}},
+			}, nil
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		capture, err := s.tmux.Capture(ctx, args.Session, 0)
+		if err != nil {
+			return tmuxError[any]("Capturing pane failed: %s", err), nil
+		}
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatTmuxCapture(capture)}},
+			StructuredContent: capture,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerCaptureTmuxPaneTool() {
+	tool := &mcp.Tool{
+		Name:        "capture_tmux_pane",
+		Description: "Read the screen of a tmux session with up to lines lines of scrollback (capped by the configuration), and whether its program exited and with which status.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[CaptureTmuxPaneParams]) (*mcp.CallToolResultFor[tmux.Capture], error) {
+		capture, err := s.tmux.Capture(ctx, params.Arguments.Session, params.Arguments.Lines)
+		if err != nil {
+			s.logger.WithError(err).Debug("tmux capture failed", "session", params.Arguments.Session)
+			return tmuxError[tmux.Capture]("Capturing pane failed: %s", err), nil
+		}
+
+		return &mcp.CallToolResultFor[tmux.Capture]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatTmuxCapture(capture)}},
+			StructuredContent: *capture,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerKillTmuxSessionTool() {
+	tool := &mcp.Tool{
+		Name:        "kill_tmux_session",
+		Description: "End a tmux session created by create_tmux_session and the program running in it.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[KillTmuxSessionParams]) (*mcp.CallToolResultFor[any], error) {
+		if err := s.tmux.Kill(ctx, params.Arguments.Session); err != nil {
+			s.logger.WithError(err).Debug("tmux kill failed", "session", params.Arguments.Session)
+			return tmuxError[any]("Killing tmux session failed: %s", err), nil
+		}
+		s.logger.Info("tmux session killed", "session", params.Arguments.Session)
+
+		return &mcp.CallToolResultFor[any]{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Killed tmux session " + params.Arguments.Session}},
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+// formatTmuxSessions renders sessions one per line.
+func formatTmuxSessions(sessions []tmux.Session) string {
+	if len(sessions) == 0 {
+		return "No tmux sessions"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d tmux sessions", len(sessions))
+	for _, session := range sessions {
+		fmt.Fprintf(&b, "\n  %s: %s", session.Name, formatTmuxState(session))
+	}
+	return b.String()
+}
+
+// formatTmuxCapture renders a session's screen below its state.
+func formatTmuxCapture(c *tmux.Capture) string {
+	return fmt.Sprintf("Session %s: %s\n%s", c.Name, formatTmuxState(c.Session), c.Content)
+}
+
+// formatTmuxState describes whether a session's program runs.
+func formatTmuxState(session tmux.Session) string {
+	if session.Dead {
+		if session.ExitStatus != nil {
+			return fmt.Sprintf("%s exited with status %d", session.Command, *session.ExitStatus)
+		}
+		return session.Command + " exited"
+	}
+	return fmt.Sprintf("%s running (pid %d)", session.Command, session.PID)
+}
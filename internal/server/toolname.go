@@ -0,0 +1,88 @@
+package server
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// builtinTools are the names of the built-in tools, which the descriptions
+// of other tools may refer to.
+var builtinTools = config.BuiltinTools
+
+// builtinToolRef matches a built-in tool name in a description.
+var builtinToolRef = regexp.MustCompile(`\b(` + strings.Join(builtinTools, "|") + `)\b`)
+
+// findCommand returns the configured command called name. The name may
+// carry the tool prefix, as clients know configured commands by their tool
+// names.
+func (s *Server) findCommand(name string) *config.Command {
+	s.commandsMu.RLock()
+	defer s.commandsMu.RUnlock()
+	if cmd := s.config.FindCommand(name); cmd != nil {
+		return cmd
+	}
+	if trimmed, ok := strings.CutPrefix(name, s.config.Server.ToolPrefix); ok && trimmed != name {
+		return s.config.FindCommand(trimmed)
+	}
+	return nil
+}
+
+// addTool registers a tool under the configured prefix, with its
+// description in the configured locale. The built-in tool names its
+// description refers to are prefixed too, so they name tools the client
+// can call.
+func addTool[In, Out any](s *Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	if !s.toolEnabled(tool.Name) {
+		s.logger.Debug("built-in tool not enabled", "tool", tool.Name)
+		return
+	}
+	addConfiguredTool(s, tool, handler)
+}
+
+// addConfiguredTool registers a configured command or script tool like
+// addTool. Validation has already kept them off the names of the built-in
+// tools the configuration registers, and they may take the others.
+func addConfiguredTool[In, Out any](s *Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	tool.Description = s.msg.T(tool.Description)
+	if prefix := s.config.Server.ToolPrefix; prefix != "" {
+		tool.Name = prefix + tool.Name
+		tool.Description = builtinToolRef.ReplaceAllString(tool.Description, prefix+"$1")
+	}
+	mcp.AddTool(s.mcpServer, tool, handler)
+	s.toolNames.Store(tool.Name, tool.Description)
+	s.emit(types.EventToolRegistered, "registered tool "+tool.Name, map[string]any{"tool": tool.Name})
+}
+
+// toolEnabled reports whether a tool may be registered: built-in tools
+// must be registered under the configuration (see
+// config.RegistersBuiltinTool), which also reserves their names.
+func (s *Server) toolEnabled(name string) bool {
+	return !slices.Contains(builtinTools, name) || s.config.RegistersBuiltinTool(name)
+}
+
+// removeTools unregisters tools by their registered names, and the
+// prompts of configured commands among them.
+func (s *Server) removeTools(names ...string) {
+	s.mcpServer.RemoveTools(names...)
+	s.mcpServer.RemovePrompts(names...)
+	for _, name := range names {
+		s.toolNames.Delete(name)
+		s.emit(types.EventToolRemoved, "removed tool "+name, map[string]any{"tool": name})
+	}
+}
+
+// registeredTools returns the names of the registered tools, sorted.
+func (s *Server) registeredTools() []string {
+	var names []string
+	s.toolNames.Range(func(name, _ any) bool {
+		names = append(names, name.(string))
+		return true
+	})
+	slices.Sort(names)
+	return names
+}
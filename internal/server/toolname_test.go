@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/i18n"
+	"github.com/mjmorales/simple-mcp-runner/internal/quarantine"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_toolPrefix(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.ToolPrefix = "runner_"
+	cfg.Commands = []config.Command{
+		{Name: "test_echo", Description: "Test echo command", Command: "echo"},
+	}
+	srv, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	res, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	tools := make(map[string]*mcp.Tool)
+	for _, tool := range res.Tools {
+		if !strings.HasPrefix(tool.Name, "runner_") {
+			t.Errorf("tool %s is not prefixed", tool.Name)
+		}
+		tools[tool.Name] = tool
+	}
+	if tools["runner_execute_command"] == nil || tools["runner_test_echo"] == nil {
+		t.Fatalf("expected prefixed built-in and configured tools, got %v", res.Tools)
+	}
+	watch := tools["runner_watch_path"]
+	if watch == nil || !strings.Contains(watch.Description, "runner_list_watches and runner_stop_watch") {
+		t.Errorf("expected the description to refer to prefixed tools, got %+v", watch)
+	}
+
+	if cmd := srv.findCommand("runner_test_echo"); cmd == nil || cmd.Name != "test_echo" {
+		t.Errorf("findCommand() = %v, want test_echo", cmd)
+	}
+}
+
+func TestServer_locale(t *testing.T) {
+	t.Setenv(i18n.EnvVar, "")
+	descriptions := func(locale string) map[string]string {
+		cfg := config.Default()
+		cfg.Server.Locale = locale
+		cfg.Containers.Enabled = true
+		cfg.Tmux.Enabled = true
+		cfg.REPL.Enabled = true
+		cfg.Transfer.DownloadEnabled = true
+		cfg.HTTP.Enabled = true
+		cfg.Security.AllowClearQuarantine = true
+		_, cs := newTestSession(t, cfg)
+
+		ctx := context.Background()
+
+		res, err := cs.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListTools() error = %v", err)
+		}
+		out := make(map[string]string)
+		for _, tool := range res.Tools {
+			out[tool.Name] = tool.Description
+		}
+		return out
+	}
+
+	english := descriptions("")
+	japanese := descriptions("ja")
+	for _, name := range builtinTools {
+		if name == "clear_quarantine" && !quarantine.Supported {
+			continue
+		}
+		if _, ok := english[name]; !ok {
+			t.Errorf("built-in tool %s is not registered", name)
+			continue
+		}
+		if japanese[name] == english[name] {
+			t.Errorf("description of %s is not translated", name)
+		}
+	}
+}
+
+func TestServer_displayName(t *testing.T) {
+	cfg := config.Default()
+	cfg.Commands = []config.Command{
+		{Name: "run-tests", DisplayName: "Run Tests", Description: "Run the tests", Command: "echo"},
+	}
+	srv, cs := newTestSession(t, cfg)
+
+	ctx := context.Background()
+
+	res, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	var tool *mcp.Tool
+	for _, listed := range res.Tools {
+		if listed.Name == "run-tests" {
+			tool = listed
+		}
+	}
+	if tool == nil || tool.Title != "Run Tests" {
+		t.Fatalf("expected run-tests titled Run Tests, got %+v", tool)
+	}
+
+	res2, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "run-tests"})
+	if err != nil || res2.IsError {
+		t.Fatalf("CallTool() = %+v, %v", res2, err)
+	}
+
+	if cmd := srv.findCommand("run_tests"); cmd == nil || cmd.Name != "run-tests" {
+		t.Errorf("findCommand() = %v, want run-tests", cmd)
+	}
+}
+
+func TestServer_tools(t *testing.T) {
+	cfg, err := config.Preset(config.PresetSafeDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Commands may take the names of built-in tools that are not
+	// registered: run_tests is not listed and http_request is not enabled
+	cfg.Commands = []config.Command{
+		{Name: "test_echo", Description: "Test echo command", Command: "echo"},
+		{Name: "run_tests", Description: "Run the tests", Command: "echo"},
+		{Name: "http_request", Description: "Fetch a page", Command: "echo"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	srv, err := New(Options{Config: cfg})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	// Only the listed built-in tools are registered, with the commands
+	got := srv.registeredTools()
+	want := append(slices.Clone(cfg.Server.Tools), "test_echo", "run_tests", "http_request")
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("registered tools = %v, want %v", got, want)
+	}
+
+	// A registered built-in tool keeps its name
+	clash := *cfg
+	clash.Commands = []config.Command{{Name: "git_status", Description: "Show status", Command: "git"}}
+	if err := clash.Validate(); err == nil {
+		t.Error("expected command named like a registered built-in tool to fail validation")
+	}
+
+	cfg.Server.Tools = []string{"read_file"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected unknown tool to fail validation")
+	}
+}
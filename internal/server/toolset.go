@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ListToolGroupsParams represents parameters for listing tool groups.
+type ListToolGroupsParams struct{}
+
+// SelectToolsetParams represents parameters for selecting tool groups.
+type SelectToolsetParams struct {
+	Groups []string `json:"groups"`
+}
+
+// selectedGroups returns the tool groups selected in a session.
+func (s *Server) selectedGroups(ss *mcp.ServerSession) []string {
+	v, ok := s.sessions.Load(ss)
+	if !ok {
+		return s.config.Server.DefaultToolGroups
+	}
+	info := v.(*sessionInfo)
+	info.mu.Lock()
+	defer info.mu.Unlock()
+	return slices.Clone(info.groups)
+}
+
+// groupsOf returns the tool groups a configured command belongs to.
+func (s *Server) groupsOf(command string) []string {
+	var groups []string
+	for _, group := range s.config.ToolGroups {
+		if slices.Contains(group.Commands, command) {
+			groups = append(groups, group.Name)
+		}
+	}
+	return groups
+}
+
+// hiddenTool returns the groups of a tool that is hidden in a session
+// because none of them is selected, or nil if the tool is listed.
+// Built-in tools and commands in no group are always listed.
+func (s *Server) hiddenTool(ss *mcp.ServerSession, name string) []string {
+	if len(s.config.ToolGroups) == 0 {
+		return nil
+	}
+	cmd := s.findCommand(name)
+	if cmd == nil {
+		return nil
+	}
+	groups := s.groupsOf(cmd.Name)
+	selected := s.selectedGroups(ss)
+	for _, group := range groups {
+		if slices.Contains(selected, group) {
+			return nil
+		}
+	}
+	return groups
+}
+
+// toolsetMiddleware hides the tools of unselected groups from tool
+// listings and refuses calls to them.
+func (s *Server) toolsetMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, ss *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		if len(s.config.ToolGroups) == 0 {
+			return next(ctx, ss, method, params)
+		}
+
+		if p, ok := params.(*mcp.CallToolParamsFor[json.RawMessage]); ok {
+			if groups := s.hiddenTool(ss, p.Name); groups != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{
+						Text: s.msg.Sprintf("Tool %s is not selected: call select_toolset with one of the groups %s first", p.Name, strings.Join(groups, ", ")),
+					}},
+					IsError: true,
+				}, nil
+			}
+			return next(ctx, ss, method, params)
+		}
+
+		result, err := next(ctx, ss, method, params)
+		if res, ok := result.(*mcp.ListToolsResult); ok && err == nil {
+			tools := make([]*mcp.Tool, 0, len(res.Tools))
+			for _, tool := range res.Tools {
+				if s.hiddenTool(ss, tool.Name) == nil {
+					tools = append(tools, tool)
+				}
+			}
+			res.Tools = tools
+		}
+		return result, err
+	}
+}
+
+// toolGroupList lists the tool groups and those selected in a session.
+func (s *Server) toolGroupList(ss *mcp.ServerSession) types.ToolGroupList {
+	selected := s.selectedGroups(ss)
+	list := types.ToolGroupList{
+		Groups:   make([]types.ToolGroupInfo, 0, len(s.config.ToolGroups)),
+		Selected: selected,
+	}
+	if list.Selected == nil {
+		list.Selected = []string{}
+	}
+	for _, group := range s.config.ToolGroups {
+		tools := make([]string, len(group.Commands))
+		for i, name := range group.Commands {
+			tools[i] = s.config.Server.ToolPrefix + name
+		}
+		list.Groups = append(list.Groups, types.ToolGroupInfo{
+			Name:        group.Name,
+			Description: group.Description,
+			Tools:       tools,
+			Selected:    slices.Contains(selected, group.Name),
+		})
+	}
+	return list
+}
+
+// toolGroupText renders tool groups for the text content of results.
+func toolGroupText(list types.ToolGroupList) string {
+	if len(list.Groups) == 0 {
+		return "No tool groups are configured; all tools are listed"
+	}
+	var b strings.Builder
+	for i, group := range list.Groups {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		marker := " "
+		if group.Selected {
+			marker = "*"
+		}
+		fmt.Fprintf(&b, "%s %s (%d tools)", marker, group.Name, len(group.Tools))
+		if group.Description != "" {
+			fmt.Fprintf(&b, ": %s", group.Description)
+		}
+	}
+	return b.String()
+}
+
+// registerToolsetTools registers the tool group listing and selection
+// tools.
+func (s *Server) registerToolsetTools() error {
+	listTool := &mcp.Tool{
+		Name:        "list_tool_groups",
+		Description: "List the configured tool groups with their description and tools, marking the groups selected in this session. Tools of unselected groups are hidden from the tool list; use select_toolset to select groups.",
+	}
+
+	listHandler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ListToolGroupsParams]) (*mcp.CallToolResultFor[types.ToolGroupList], error) {
+		list := s.toolGroupList(ss)
+		return &mcp.CallToolResultFor[types.ToolGroupList]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: toolGroupText(list)}},
+			StructuredContent: list,
+		}, nil
+	}
+
+	addTool(s, listTool, listHandler)
+
+	selectTool := &mcp.Tool{
+		Name:        "select_toolset",
+		Description: "Select the tool groups whose tools are listed in this session, replacing the current selection; an empty list hides all grouped tools. Clients are notified to list tools again. See list_tool_groups for the available groups.",
+	}
+
+	var selectHandler mcp.ToolHandlerFor[SelectToolsetParams, types.ToolGroupList]
+	selectHandler = func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[SelectToolsetParams]) (*mcp.CallToolResultFor[types.ToolGroupList], error) {
+		groups := slices.Compact(slices.Sorted(slices.Values(params.Arguments.Groups)))
+		for _, name := range groups {
+			if !slices.ContainsFunc(s.config.ToolGroups, func(g config.ToolGroup) bool { return g.Name == name }) {
+				return &mcp.CallToolResultFor[types.ToolGroupList]{
+					Content: []mcp.Content{&mcp.TextContent{Text: s.msg.Sprintf("Unknown tool group: %s", name)}},
+					IsError: true,
+				}, nil
+			}
+		}
+
+		v, ok := s.sessions.Load(ss)
+		if !ok {
+			return &mcp.CallToolResultFor[types.ToolGroupList]{
+				Content: []mcp.Content{&mcp.TextContent{Text: "Tool groups cannot be selected before the session is initialized"}},
+				IsError: true,
+			}, nil
+		}
+		info := v.(*sessionInfo)
+		info.mu.Lock()
+		info.groups = groups
+		info.mu.Unlock()
+
+		s.logger.Info("selected tool groups", "session", info.id, "groups", groups)
+		s.toolsChanged()
+
+		list := s.toolGroupList(ss)
+		return &mcp.CallToolResultFor[types.ToolGroupList]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: toolGroupText(list)}},
+			StructuredContent: list,
+		}, nil
+	}
+
+	addTool(s, selectTool, selectHandler)
+
+	// The SDK only notifies sessions when the server's tools change, so
+	// replacing a tool with itself tells clients to list tools again. The
+	// schemas are inferred afresh, as resolved schemas cannot be reused
+	s.toolsChanged = func() {
+		tool := *selectTool
+		tool.InputSchema, tool.OutputSchema = nil, nil
+		mcp.AddTool(s.mcpServer, &tool, selectHandler)
+	}
+
+	s.logger.Debug("registered toolset tools")
+
+	return nil
+}
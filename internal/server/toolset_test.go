@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_toolGroups(t *testing.T) {
+	cfg := config.Default()
+	cfg.Commands = []config.Command{
+		{Name: "build", Description: "Build", Command: "echo"},
+		{Name: "deploy", Description: "Deploy", Command: "echo"},
+		{Name: "hello", Description: "Hello", Command: "echo"},
+	}
+	cfg.ToolGroups = []config.ToolGroup{
+		{Name: "dev", Commands: []string{"build"}},
+		{Name: "ops", Commands: []string{"deploy"}},
+	}
+	cfg.Server.DefaultToolGroups = []string{"dev"}
+	srv := newTestServer(t, cfg)
+
+	changed := make(chan struct{}, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ClientSession, *mcp.ToolListChangedParams) {
+			changed <- struct{}{}
+		},
+	})
+	cs := connectClient(t, srv, client)
+	ctx := context.Background()
+
+	listed := func() []string {
+		res, err := cs.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListTools() error = %v", err)
+		}
+		var names []string
+		for _, tool := range res.Tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+
+	names := listed()
+	if !slices.Contains(names, "build") || !slices.Contains(names, "hello") || slices.Contains(names, "deploy") {
+		t.Errorf("tools = %v, want build and hello but not deploy", names)
+	}
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "deploy"})
+	if err != nil || !res.IsError {
+		t.Errorf("calling a hidden tool = %v, %v; want an error result", res, err)
+	}
+
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "select_toolset", Arguments: map[string]any{"groups": []string{"ops"}}})
+	if err != nil || res.IsError {
+		t.Fatalf("select_toolset = %v, %v", res, err)
+	}
+	<-changed
+	names = listed()
+	if slices.Contains(names, "build") || !slices.Contains(names, "deploy") {
+		t.Errorf("tools = %v, want deploy but not build", names)
+	}
+
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "select_toolset", Arguments: map[string]any{"groups": []string{"nope"}}})
+	if err != nil || !res.IsError {
+		t.Errorf("selecting an unknown group = %v, %v; want an error result", res, err)
+	}
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/transfer"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DownloadFileParams represents parameters for downloading a file.
+type DownloadFileParams struct {
+	URL       string `json:"url"`
+	Path      string `json:"path"`             // Absolute destination path
+	SHA256    string `json:"sha256,omitempty"` // Expected hex digest
+	Overwrite bool   `json:"overwrite,omitempty"`
+}
+
+// ReadFileChunkParams represents parameters for reading part of a file.
+type ReadFileChunkParams struct {
+	Path     string `json:"path"`
+	Offset   int64  `json:"offset,omitempty"`
+	Length   int64  `json:"length,omitempty"`
+	Checksum bool   `json:"checksum,omitempty"` // Include the whole file's SHA-256
+}
+
+// registerTransferTools registers the file transfer tools. download_file is
+// only registered when transfer.download_enabled is set.
+func (s *Server) registerTransferTools() error {
+	if s.config.Transfer.DownloadEnabled {
+		s.registerDownloadFileTool()
+	}
+	s.registerReadFileChunkTool()
+
+	s.logger.Debug("registered transfer tools")
+
+	return nil
+}
+
+func (s *Server) registerDownloadFileTool() {
+	tool := &mcp.Tool{
+		Name:        "download_file",
+		Description: "Download a URL to an absolute local path without curl or wget. The URL scheme and host must be allowed by the configuration, the file size is limited, and the download is verified against sha256 when given. Existing files are only replaced with overwrite.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[DownloadFileParams]) (*mcp.CallToolResultFor[transfer.DownloadResult], error) {
+		args := params.Arguments
+
+		gen := s.backups.Begin("download_file")
+		err := gen.Save(args.Path)
+
+		var result *transfer.DownloadResult
+		if err == nil {
+			result, err = s.transfer.Download(ctx, transfer.DownloadRequest{
+				URL:       args.URL,
+				Path:      args.Path,
+				SHA256:    args.SHA256,
+				Overwrite: args.Overwrite,
+			})
+		}
+		s.finishBackup(gen, err == nil)
+		if err != nil {
+			s.logger.WithError(err).Error("download failed", "url", args.URL)
+			return &mcp.CallToolResultFor[transfer.DownloadResult]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Download failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		text := fmt.Sprintf("Downloaded %s to %s (%d bytes, sha256 %s)", result.URL, result.Path, result.Size, result.SHA256)
+
+		return &mcp.CallToolResultFor[transfer.DownloadResult]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+			StructuredContent: *result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerReadFileChunkTool() {
+	tool := &mcp.Tool{
+		Name:        "read_file_chunk",
+		Description: "Read part of a file by absolute path, starting at offset, up to length bytes (capped by the configuration). Text is returned as is and binary data as base64; eof tells whether the end was reached. Set checksum to get the whole file's SHA-256.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ReadFileChunkParams]) (*mcp.CallToolResultFor[transfer.Chunk], error) {
+		args := params.Arguments
+
+		chunk, err := s.transfer.ReadChunk(transfer.ChunkRequest{
+			Path:     args.Path,
+			Offset:   args.Offset,
+			Length:   args.Length,
+			Checksum: args.Checksum,
+		})
+		if err != nil {
+			s.logger.WithError(err).Debug("file read failed", "path", args.Path)
+			return &mcp.CallToolResultFor[transfer.Chunk]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Read failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[transfer.Chunk]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: chunk.Data}},
+			StructuredContent: *chunk,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+// HTTPRequestParams represents parameters for an HTTP request.
+type HTTPRequestParams struct {
+	Method  string            `json:"method,omitempty"` // Defaults to GET
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// registerHTTPRequestTool registers the http_request tool unless it is
+// disabled.
+func (s *Server) registerHTTPRequestTool() error {
+	if !s.config.HTTP.Enabled {
+		s.logger.Debug("HTTP request tool disabled")
+		return nil
+	}
+
+	tool := &mcp.Tool{
+		Name:        "http_request",
+		Description: "Send an HTTP request to an allowed host instead of running curl, and return the status, headers and body. Only https is used unless the configuration allows http, certificates are always verified, and the method must be allowed. Credentials configured for the host are added by the server and redacted from the response, so never pass tokens in headers. Bodies are limited in size; binary responses are returned as base64.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[HTTPRequestParams]) (*mcp.CallToolResultFor[transfer.HTTPResponse], error) {
+		args := params.Arguments
+
+		resp, err := s.transfer.Request(ctx, transfer.HTTPRequest{
+			Method:  args.Method,
+			URL:     args.URL,
+			Headers: args.Headers,
+			Body:    args.Body,
+		})
+		if err != nil {
+			s.logger.WithError(err).Error("HTTP request failed", "url", args.URL)
+			return &mcp.CallToolResultFor[transfer.HTTPResponse]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("HTTP request failed: %s", err.Error())},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		text := fmt.Sprintf("%s %s\n%s", resp.Status, resp.URL, resp.Body)
+		if resp.Truncated {
+			text += fmt.Sprintf("\n[body truncated to %d bytes]", resp.Size)
+		}
+
+		return &mcp.CallToolResultFor[transfer.HTTPResponse]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+			StructuredContent: *resp,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+
+	s.logger.Debug("registered HTTP request tool")
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/watcher"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// WatchPathParams represents parameters for starting a watch.
+type WatchPathParams struct {
+	Path      string   `json:"path"`
+	Recursive bool     `json:"recursive,omitempty"`
+	Command   string   `json:"command,omitempty"` // Configured command to run on changes
+	Ignore    []string `json:"ignore,omitempty"`  // Glob patterns for base names to ignore
+}
+
+// StopWatchParams represents parameters for stopping a watch.
+type StopWatchParams struct {
+	ID string `json:"id"`
+}
+
+// ListWatchesParams represents parameters for listing watches.
+type ListWatchesParams struct{}
+
+// WatchListResult lists active watches.
+type WatchListResult struct {
+	Watches []watcher.Info `json:"watches"`
+}
+
+// registerWatchTools registers the file watching tools.
+func (s *Server) registerWatchTools() error {
+	s.registerWatchPathTool()
+	s.registerListWatchesTool()
+	s.registerStopWatchTool()
+
+	s.logger.Debug("registered watch tools")
+
+	return nil
+}
+
+func (s *Server) registerWatchPathTool() {
+	tool := &mcp.Tool{
+		Name:        "watch_path",
+		Description: "Watch a file or directory (absolute path) for changes. Changes are debounced and sent to the client as log notifications; if command names a configured command, it is run on each batch of changes, subject to a rate limit. Returns the watch id for list_watches and stop_watch.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[WatchPathParams]) (*mcp.CallToolResultFor[watcher.Info], error) {
+		args := params.Arguments
+
+		spec, err := s.watchSpec(&args)
+		if err != nil {
+			return watchErrorResult(err), nil
+		}
+
+		info, err := s.watches.Add(spec, func(ctx context.Context, change watcher.Change) {
+			if err := s.logToolOutput(ctx, ss, "watch_path", "watch", change); err != nil {
+				s.logger.WithError(err).Debug("failed to send watch notification", "id", change.WatchID)
+			}
+		})
+		if err != nil {
+			s.logger.WithError(err).Error("failed to start watch", "path", args.Path)
+			return watchErrorResult(err), nil
+		}
+
+		text := fmt.Sprintf("Watching %s (id %s, %d directories)", info.Path, info.ID, info.Directories)
+		if info.Command != "" {
+			text += fmt.Sprintf("; running %s on changes", info.Command)
+		}
+
+		return &mcp.CallToolResultFor[watcher.Info]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+			StructuredContent: info,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+// watchSpec validates watch parameters against the security settings.
+func (s *Server) watchSpec(args *WatchPathParams) (watcher.Spec, error) {
+	if args.Path == "" {
+		return watcher.Spec{}, apperrors.ValidationError("path is required", "path")
+	}
+	if !filepath.IsAbs(args.Path) {
+		return watcher.Spec{}, apperrors.ValidationError("path must be absolute", "path")
+	}
+	if !s.config.IsPathAllowed(args.Path) {
+		return watcher.Spec{}, apperrors.PermissionError("path not allowed: "+args.Path, args.Path)
+	}
+
+	spec := watcher.Spec{
+		Path:      filepath.Clean(args.Path),
+		Recursive: args.Recursive,
+		Ignore:    args.Ignore,
+	}
+
+	if args.Command != "" {
+		cmd := s.findCommand(args.Command)
+		if cmd == nil {
+			return watcher.Spec{}, apperrors.NotFoundError("configured command not found: "+args.Command, args.Command)
+		}
+		spec.Command = cmd
+	}
+
+	return spec, nil
+}
+
+func (s *Server) registerListWatchesTool() {
+	tool := &mcp.Tool{
+		Name:        "list_watches",
+		Description: "List active file watches with their recent change events and trigger counts.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[ListWatchesParams]) (*mcp.CallToolResultFor[WatchListResult], error) {
+		result := WatchListResult{Watches: s.watches.List()}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Active watches (%d):\n", len(result.Watches))
+		for _, w := range result.Watches {
+			fmt.Fprintf(&b, "- %s: %s (%d recent events, %d triggers)\n",
+				w.ID, w.Path, len(w.RecentEvents), w.Triggers)
+		}
+
+		return &mcp.CallToolResultFor[WatchListResult]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: b.String()}},
+			StructuredContent: result,
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+func (s *Server) registerStopWatchTool() {
+	tool := &mcp.Tool{
+		Name:        "stop_watch",
+		Description: "Stop an active file watch by id.",
+	}
+
+	handler := func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[StopWatchParams]) (*mcp.CallToolResultFor[any], error) {
+		if err := s.watches.Remove(params.Arguments.ID); err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to stop watch: %s", err.Error())}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Stopped watch " + params.Arguments.ID}},
+		}, nil
+	}
+
+	addTool(s, tool, handler)
+}
+
+// watchErrorResult converts an error into a tool error result.
+func watchErrorResult(err error) *mcp.CallToolResultFor[watcher.Info] {
+	return &mcp.CallToolResultFor[watcher.Info]{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Failed to start watch: %s", err.Error())},
+		},
+		IsError: true,
+	}
+}
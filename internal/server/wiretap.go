@@ -0,0 +1,215 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/internal/recording"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Directions of protocol messages.
+const (
+	directionIn  = "in"  // From the client
+	directionOut = "out" // To the client
+)
+
+// ProtocolLogFile returns the file protocol traffic is logged to.
+func ProtocolLogFile(cfg *config.Config) string {
+	if cfg.Logging.ProtocolFile != "" {
+		return cfg.Logging.ProtocolFile
+	}
+	return filepath.Join(config.StateDir(), "protocol.jsonl")
+}
+
+// wireTap is a transport that logs the JSON-RPC messages of the
+// connection it wraps: methods, tool names, sizes, latencies and masked,
+// truncated bodies.
+type wireTap struct {
+	delegate  mcp.Transport
+	file      *os.File
+	log       *logger.Logger
+	maxBody   int
+	sensitive func(name string) bool
+}
+
+// newWireTap opens the protocol log and wraps a transport.
+func newWireTap(delegate mcp.Transport, cfg *config.Config, sensitive func(string) bool) (*wireTap, error) {
+	path := ProtocolLogFile(cfg)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to create protocol log directory")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to open protocol log")
+	}
+
+	log, err := logger.New(logger.Options{Level: "debug", Output: f, JSONOutput: true})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &wireTap{
+		delegate:  delegate,
+		file:      f,
+		log:       log,
+		maxBody:   cfg.Logging.ProtocolMaxBody,
+		sensitive: sensitive,
+	}, nil
+}
+
+// Connect connects the wrapped transport and taps the connection.
+func (t *wireTap) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.delegate.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tapConn{Connection: conn, tap: t}, nil
+}
+
+// Close closes the protocol log.
+func (t *wireTap) Close() error {
+	return t.file.Close()
+}
+
+// tapConn logs the messages of a connection.
+type tapConn struct {
+	mcp.Connection
+	tap *wireTap
+
+	// pending holds the requests awaiting a response, by direction and ID
+	mu      sync.Mutex
+	pending map[string]pendingCall
+}
+
+// pendingCall is a request awaiting its response.
+type pendingCall struct {
+	method string
+	tool   string
+	start  time.Time
+}
+
+// Read logs messages from the client.
+func (c *tapConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	msg, err := c.Connection.Read(ctx)
+	if err == nil {
+		c.logMessage(directionIn, msg)
+	}
+	return msg, err
+}
+
+// Write logs messages to the client.
+func (c *tapConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	err := c.Connection.Write(ctx, msg)
+	if err != nil {
+		c.tap.log.Warn("write failed", "direction", directionOut, "error", err)
+		return err
+	}
+	c.logMessage(directionOut, msg)
+	return nil
+}
+
+// logMessage logs a request, notification or response.
+func (c *tapConn) logMessage(direction string, msg jsonrpc.Message) {
+	attrs := []any{"direction", direction}
+	if id := c.SessionID(); id != "" {
+		attrs = append(attrs, "session", id)
+	}
+
+	switch m := msg.(type) {
+	case *jsonrpc.Request:
+		attrs = append(attrs, "method", m.Method)
+		tool := toolName(m)
+		if tool != "" {
+			attrs = append(attrs, "tool", tool)
+		}
+		attrs = append(attrs, "params_bytes", len(m.Params))
+		attrs = c.appendBody(attrs, m.Params)
+
+		if !m.ID.IsValid() {
+			c.tap.log.Info("notification", attrs...)
+			return
+		}
+		c.track(direction, m.ID, pendingCall{method: m.Method, tool: tool, start: time.Now()})
+		c.tap.log.Info("request", append(attrs, "id", m.ID.Raw())...)
+
+	case *jsonrpc.Response:
+		attrs = append(attrs, "id", m.ID.Raw())
+		// A response answers a request that went the other way
+		requestDirection := directionIn
+		if direction == directionIn {
+			requestDirection = directionOut
+		}
+		if call, ok := c.complete(requestDirection, m.ID); ok {
+			attrs = append(attrs, "method", call.method)
+			if call.tool != "" {
+				attrs = append(attrs, "tool", call.tool)
+			}
+			attrs = append(attrs, "latency_ms", time.Since(call.start).Milliseconds())
+		}
+		attrs = append(attrs, "result_bytes", len(m.Result))
+		if m.Error != nil {
+			attrs = append(attrs, "error", m.Error.Error())
+		}
+		attrs = c.appendBody(attrs, m.Result)
+		c.tap.log.Info("response", attrs...)
+	}
+}
+
+// track records a request awaiting its response.
+func (c *tapConn) track(direction string, id jsonrpc.ID, call pendingCall) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == nil {
+		c.pending = make(map[string]pendingCall)
+	}
+	c.pending[callKey(direction, id)] = call
+}
+
+// complete removes and returns the request a response answers.
+func (c *tapConn) complete(direction string, id jsonrpc.ID) (pendingCall, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := callKey(direction, id)
+	call, ok := c.pending[key]
+	delete(c.pending, key)
+	return call, ok
+}
+
+func callKey(direction string, id jsonrpc.ID) string {
+	return fmt.Sprintf("%s:%v", direction, id.Raw())
+}
+
+// toolName returns the tool a tools/call request calls.
+func toolName(req *jsonrpc.Request) string {
+	if req.Method != "tools/call" {
+		return ""
+	}
+	var params struct {
+		Name string `json:"name"`
+	}
+	_ = json.Unmarshal(req.Params, &params)
+	return params.Name
+}
+
+// appendBody adds the masked body, truncated to the configured size.
+func (c *tapConn) appendBody(attrs []any, raw json.RawMessage) []any {
+	if c.tap.maxBody == 0 || len(raw) == 0 {
+		return attrs
+	}
+	body := string(recording.Mask(raw, c.tap.sensitive))
+	if len(body) > c.tap.maxBody {
+		body = fmt.Sprintf("%s... (%d bytes truncated)", body[:c.tap.maxBody], len(body)-c.tap.maxBody)
+	}
+	return append(attrs, "body", body)
+}
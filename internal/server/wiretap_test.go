@@ -0,0 +1,95 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServer_wireTap(t *testing.T) {
+	cfg := config.Default()
+	cfg.Logging.LogProtocol = true
+	cfg.Logging.ProtocolFile = filepath.Join(t.TempDir(), "protocol.jsonl")
+	cfg.Logging.ProtocolMaxBody = 200
+
+	srv, err := New(Options{Config: cfg})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer srv.Close()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	tap, err := newWireTap(serverTransport, cfg, srv.executor.IsSensitiveEnv)
+	if err != nil {
+		t.Fatalf("newWireTap() error = %v", err)
+	}
+	ss, err := srv.mcpServer.Connect(ctx, tap)
+	if err != nil {
+		t.Fatalf("server connect error = %v", err)
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	cs, err := client.Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("client connect error = %v", err)
+	}
+
+	_, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "execute_command", Arguments: map[string]any{
+		"command": "echo",
+		"args":    []string{strings.Repeat("x", 500)},
+		"env":     []string{"API_TOKEN=hunter2"},
+	}})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	cs.Close()
+	ss.Wait()
+	tap.Close()
+
+	data, err := os.ReadFile(cfg.Logging.ProtocolFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Error("expected the secret to be masked")
+	}
+
+	var request, response map[string]any
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		var entry map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", scanner.Text(), err)
+		}
+		if entry["tool"] != "execute_command" {
+			continue
+		}
+		switch entry["msg"] {
+		case "request":
+			request = entry
+		case "response":
+			response = entry
+		}
+	}
+
+	if request == nil || response == nil {
+		t.Fatalf("expected a logged tools/call request and response, got:\n%s", data)
+	}
+	if request["direction"] != directionIn || request["method"] != "tools/call" {
+		t.Errorf("unexpected request entry %v", request)
+	}
+	if body, _ := request["body"].(string); !strings.Contains(body, "bytes truncated") {
+		t.Errorf("expected a truncated body, got %q", body)
+	}
+	if response["direction"] != directionOut || response["latency_ms"] == nil || response["id"] != request["id"] {
+		t.Errorf("unexpected response entry %v", response)
+	}
+}
@@ -0,0 +1,295 @@
+// Package tail reads the last lines of files and follows what is appended
+// to them, so agents can watch logs without an interactive shell
+package tail
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+const (
+	// defaultLines is the number of lines returned when a call does not
+	// say, as with tail(1).
+	defaultLines = 10
+
+	// maxLineLen is the longest line returned; longer lines are cut.
+	maxLineLen = 64 * 1024
+
+	// chunkSize is how much is read at a time when looking for the last
+	// lines.
+	chunkSize = 8 * 1024
+)
+
+// pollInterval is how often a followed file is checked for new lines.
+var pollInterval = 250 * time.Millisecond
+
+// Reasons following stopped.
+const (
+	StopDuration = "duration"
+	StopMaxLines = "max_lines"
+	StopCanceled = "canceled"
+)
+
+// Options select what to read.
+type Options struct {
+	Lines  int           // Lines to read, capped by tail.max_lines; 10 by default
+	Offset int64         // Read lines from this offset instead of the end
+	Follow time.Duration // How long to follow appended lines, capped by tail.max_follow
+}
+
+// Result holds the lines read from a file.
+type Result struct {
+	Path     string   `json:"path"`
+	Lines    []string `json:"lines"`
+	Followed []string `json:"followed,omitempty"` // Lines appended while following
+	Size     int64    `json:"size"`
+
+	// Offset is where a later call with this offset continues
+	Offset int64 `json:"offset"`
+
+	// Reset reports that the file was truncated or replaced, e.g. by log
+	// rotation, and was read again from the start
+	Reset bool `json:"reset,omitempty"`
+
+	// Stopped says why following ended
+	Stopped string `json:"stopped,omitempty"`
+}
+
+// NotifyFunc receives lines appended to a followed file.
+type NotifyFunc func(ctx context.Context, lines []string)
+
+// Tail reads the last lines of a file, or the lines after an offset, then
+// follows it for opts.Follow, passing appended lines to notify as they
+// are written. Following stops early when ctx is done or tail.max_lines
+// lines were followed. Only complete lines are followed; a last line
+// without a newline is returned, but read again once it is complete.
+func Tail(ctx context.Context, cfg *config.Config, path string, opts Options, notify NotifyFunc) (*Result, error) {
+	if path == "" {
+		return nil, apperrors.ValidationError("path is required", "path")
+	}
+	if !filepath.IsAbs(path) {
+		return nil, apperrors.ValidationError("path must be absolute", "path")
+	}
+	if !cfg.IsPathAllowed(path) {
+		return nil, apperrors.PermissionError("path not allowed: "+path, path)
+	}
+	if opts.Offset < 0 {
+		return nil, apperrors.ValidationError("offset cannot be negative", "offset")
+	}
+
+	maxLines := cfg.Tail.MaxLines
+	if opts.Lines <= 0 {
+		opts.Lines = defaultLines
+	}
+	if maxLines > 0 && opts.Lines > maxLines {
+		opts.Lines = maxLines
+	}
+	if maxFollow := cfg.Tail.MaxFollow.Std(); maxFollow > 0 && opts.Follow > maxFollow {
+		opts.Follow = maxFollow
+	}
+
+	info, err := stat(path)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Path: path, Lines: []string{}, Size: info.Size()}
+
+	if opts.Offset > 0 {
+		if opts.Offset > info.Size() {
+			result.Reset = true
+			opts.Offset = 0
+		}
+		result.Lines, result.Offset, err = readFrom(path, opts.Offset, opts.Lines)
+	} else {
+		result.Lines, result.Offset, err = readLast(path, info.Size(), opts.Lines)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.Follow <= 0 {
+		return result, nil
+	}
+
+	follow(ctx, path, info, opts.Follow, maxLines, result, notify)
+	return result, nil
+}
+
+// follow polls a file for complete lines appended after result.Offset.
+func follow(ctx context.Context, path string, info os.FileInfo, d time.Duration, maxLines int, result *Result, notify NotifyFunc) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			result.Stopped = StopCanceled
+			return
+		case <-timer.C:
+			result.Stopped = StopDuration
+			return
+		case <-ticker.C:
+		}
+
+		current, err := os.Stat(path)
+		if err != nil {
+			// Rotated away; wait for the file to come back
+			continue
+		}
+		if !os.SameFile(info, current) || current.Size() < result.Offset {
+			result.Reset = true
+			result.Offset = 0
+		}
+		info = current
+		result.Size = current.Size()
+		if current.Size() == result.Offset {
+			continue
+		}
+
+		limit := 0
+		if maxLines > 0 {
+			limit = maxLines - len(result.Followed)
+		}
+		lines, offset, err := readFrom(path, result.Offset, limit)
+		if err != nil || len(lines) == 0 {
+			continue
+		}
+		result.Offset = offset
+		result.Followed = append(result.Followed, lines...)
+		if notify != nil {
+			notify(ctx, lines)
+		}
+		if maxLines > 0 && len(result.Followed) >= maxLines {
+			result.Stopped = StopMaxLines
+			return
+		}
+	}
+}
+
+// stat returns the metadata of a regular file.
+func stat(path string) (os.FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, apperrors.NotFoundError("file not found", path)
+		}
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to stat file")
+	}
+	if !info.Mode().IsRegular() {
+		return nil, apperrors.ValidationError("not a regular file", "path")
+	}
+	return info, nil
+}
+
+// readFrom reads up to limit complete lines (0 for no limit) starting at
+// offset, returning the offset after the last of them.
+func readFrom(path string, offset int64, limit int) ([]string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, offset, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to open file")
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to seek file")
+	}
+
+	lines := []string{}
+	r := bufio.NewReaderSize(f, maxLineLen)
+	for limit <= 0 || len(lines) < limit {
+		line, n, complete, err := readLine(r)
+		if !complete {
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, offset, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read file")
+			}
+			break
+		}
+		lines = append(lines, line)
+		offset += n
+	}
+	return lines, offset, nil
+}
+
+// readLine reads a line without its newline, cut at maxLineLen, and the
+// bytes it took up. A line is complete once its newline was read.
+func readLine(r *bufio.Reader) (string, int64, bool, error) {
+	var line []byte
+	var n int64
+	for {
+		chunk, err := r.ReadSlice('\n')
+		n += int64(len(chunk))
+		if len(line) < maxLineLen {
+			line = append(line, chunk[:min(len(chunk), maxLineLen-len(line))]...)
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil {
+			return "", n, false, err
+		}
+		return string(trimNewline(line)), n, true, nil
+	}
+}
+
+// readLast reads the last lines of a file and returns the offset after
+// its last complete line.
+func readLast(path string, size int64, lines int) ([]string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to open file")
+	}
+	defer f.Close()
+
+	// Read backwards until the chunk holds more newlines than lines, or
+	// enough bytes for lines of the longest length
+	var data []byte
+	pos := size
+	for pos > 0 && bytes.Count(data, []byte{'\n'}) <= lines && int64(len(data)) < int64(lines+1)*maxLineLen {
+		n := min(int64(chunkSize), pos)
+		pos -= n
+		chunk := make([]byte, n)
+		if _, err := f.ReadAt(chunk, pos); err != nil && !errors.Is(err, io.EOF) {
+			return nil, 0, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read file")
+		}
+		data = append(chunk, data...)
+	}
+
+	offset := size
+	parts := bytes.Split(data, []byte{'\n'})
+	if last := parts[len(parts)-1]; len(last) == 0 {
+		parts = parts[:len(parts)-1]
+	} else {
+		offset -= int64(len(last))
+	}
+	if pos > 0 && len(parts) > 0 {
+		// The first part may start mid-line
+		parts = parts[1:]
+	}
+	if len(parts) > lines {
+		parts = parts[len(parts)-lines:]
+	}
+
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if len(part) > maxLineLen {
+			part = part[:maxLineLen]
+		}
+		result = append(result, string(trimNewline(part)))
+	}
+	return result, offset, nil
+}
+
+// trimNewline removes a trailing newline and carriage return.
+func trimNewline(line []byte) []byte {
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+	return bytes.TrimSuffix(line, []byte{'\r'})
+}
@@ -0,0 +1,119 @@
+package tail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// appendLines appends text to a file.
+func appendLines(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTail_Last(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	var b strings.Builder
+	for i := 1; i <= 5000; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	appendLines(t, path, b.String()+"partial")
+	cfg := config.Default()
+
+	result, err := Tail(context.Background(), cfg, path, Options{Lines: 3}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(result.Lines, ",") != "line 4999,line 5000,partial" {
+		t.Errorf("unexpected lines %q", result.Lines)
+	}
+	if result.Offset != result.Size-int64(len("partial")) {
+		t.Errorf("expected the offset before the partial line, got %d of %d", result.Offset, result.Size)
+	}
+
+	// The partial line is read again once complete
+	appendLines(t, path, " done\nnext\n")
+	result, err = Tail(context.Background(), cfg, path, Options{Offset: result.Offset}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(result.Lines, ",") != "partial done,next" || result.Offset != result.Size {
+		t.Errorf("unexpected lines %q at %d", result.Lines, result.Offset)
+	}
+
+	// Requests are capped by max_lines
+	cfg.Tail.MaxLines = 100
+	result, err = Tail(context.Background(), cfg, path, Options{Lines: 1000}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Lines) != 100 || result.Lines[99] != "next" {
+		t.Errorf("expected the last 100 lines, got %d", len(result.Lines))
+	}
+
+	if _, err := Tail(context.Background(), cfg, "relative.log", Options{}, nil); err == nil {
+		t.Error("expected relative paths to be refused")
+	}
+	if _, err := Tail(context.Background(), cfg, filepath.Dir(path), Options{}, nil); err == nil {
+		t.Error("expected directories to be refused")
+	}
+}
+
+func TestTail_Follow(t *testing.T) {
+	pollInterval = 10 * time.Millisecond
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendLines(t, path, "old\n")
+	cfg := config.Default()
+	cfg.Tail.MaxLines = 3
+
+	var mu sync.Mutex
+	var notified []string
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		appendLines(t, path, "one\ntwo\n")
+		time.Sleep(50 * time.Millisecond)
+		// Rotation truncates the file
+		if err := os.WriteFile(path, []byte("three\nfour\n"), 0o644); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	result, err := Tail(context.Background(), cfg, path, Options{Follow: 5 * time.Second}, func(ctx context.Context, lines []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		notified = append(notified, lines...)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Stopped != StopMaxLines || !result.Reset {
+		t.Errorf("expected to stop at max_lines after a reset, got %+v", result)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(notified, ",") != "one,two,three" || strings.Join(result.Followed, ",") != "one,two,three" {
+		t.Errorf("unexpected followed lines %q (notified %q)", result.Followed, notified)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err = Tail(ctx, cfg, path, Options{Follow: time.Minute}, nil)
+	if err != nil || result.Stopped != StopCanceled {
+		t.Errorf("expected following to stop with the context, got %+v (%v)", result, err)
+	}
+}
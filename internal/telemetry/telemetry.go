@@ -0,0 +1,370 @@
+// Package telemetry sends anonymous usage reports to the maintainers when
+// explicitly enabled. Reports hold aggregated counts only: calls of the
+// built-in tools, errors by category, and the version, OS and
+// architecture of the server. Names of configured commands, arguments,
+// paths, output and error messages are never sent.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/internal/outbound"
+	"github.com/mjmorales/simple-mcp-runner/internal/usage"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// Schema is the version of the report format, increased on incompatible
+// changes.
+const Schema = 1
+
+const (
+	// defaultInterval applies when telemetry.interval is not set.
+	defaultInterval = 24 * time.Hour
+
+	// checkInterval is how often the reporter checks whether a report is
+	// due.
+	checkInterval = 10 * time.Minute
+
+	// sendTimeout limits how long a report may take to send, unless
+	// network.timeout is set.
+	sendTimeout = 10 * time.Second
+)
+
+// OptOutEnv turns telemetry off when set to off, false or 0, whatever the
+// configuration says, as does DO_NOT_TRACK.
+const OptOutEnv = "SIMPLE_MCP_RUNNER_TELEMETRY"
+
+// Error categories.
+const (
+	ErrorDenied  = "denied"  // Requests the security policy denied
+	ErrorInvalid = "invalid" // Requests failing validation
+	ErrorFailed  = "failed"  // Commands failing to run or exiting non-zero
+)
+
+// Report is the body of a telemetry report.
+type Report struct {
+	Schema int `json:"schema"`
+
+	// InstallID is a random ID generated on the first report, telling
+	// installations apart without identifying them
+	InstallID string `json:"install_id"`
+
+	Version string `json:"version"`
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+
+	// From and To bound the period counted, to the day
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// Tools are the calls of built-in tools by name
+	Tools map[string]Counts `json:"tools,omitempty"`
+
+	// OtherTools are the calls of configured commands and script tools,
+	// whose names are not sent
+	OtherTools Counts `json:"other_tools"`
+
+	// Errors are the rejected or failed command runs by category:
+	// denied, invalid or failed
+	Errors map[string]int64 `json:"errors,omitempty"`
+}
+
+// Counts are the calls of a tool.
+type Counts struct {
+	Calls    int64 `json:"calls"`
+	Failures int64 `json:"failures"`
+}
+
+// OptedOut returns the environment variable turning telemetry off, or ""
+// when there is none.
+func OptedOut() string {
+	if v := os.Getenv("DO_NOT_TRACK"); v != "" && v != "0" {
+		return "DO_NOT_TRACK"
+	}
+	switch strings.ToLower(os.Getenv(OptOutEnv)) {
+	case "off", "false", "0":
+		return OptOutEnv
+	}
+	return ""
+}
+
+// Enabled reports whether reports are sent: telemetry is enabled with an
+// endpoint and no environment variable opts out.
+func Enabled(cfg *config.Config) bool {
+	return cfg.Telemetry.Enabled && cfg.Telemetry.Endpoint != "" && OptedOut() == ""
+}
+
+// File returns the file counts are kept in until they are sent.
+func File(cfg *config.Config) string {
+	if cfg.Telemetry.File != "" {
+		return cfg.Telemetry.File
+	}
+	return filepath.Join(config.StateDir(), "telemetry.json")
+}
+
+// Interval returns how often reports are sent.
+func Interval(cfg *config.Config) time.Duration {
+	if cfg.Telemetry.Interval > 0 {
+		return cfg.Telemetry.Interval.Std()
+	}
+	return defaultInterval
+}
+
+// Build returns the report of usage counts.
+func Build(stats *usage.Stats, installID string) *Report {
+	r := &Report{
+		Schema:    Schema,
+		InstallID: installID,
+		Version:   version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		From:      stats.Since.UTC().Truncate(24 * time.Hour),
+		To:        stats.Updated.UTC().Truncate(24 * time.Hour),
+	}
+	for name, t := range stats.Tools {
+		if !slices.Contains(config.BuiltinTools, name) {
+			r.OtherTools.Calls += t.Calls
+			r.OtherTools.Failures += t.Failures
+			continue
+		}
+		if r.Tools == nil {
+			r.Tools = make(map[string]Counts)
+		}
+		r.Tools[name] = Counts{Calls: t.Calls, Failures: t.Failures}
+	}
+	for _, c := range stats.Commands {
+		for category, n := range map[string]int64{ErrorDenied: c.Denials, ErrorInvalid: c.ValidationFailures, ErrorFailed: c.Failures} {
+			if n == 0 {
+				continue
+			}
+			if r.Errors == nil {
+				r.Errors = make(map[string]int64)
+			}
+			r.Errors[category] += n
+		}
+	}
+	return r
+}
+
+// version returns the version the server was built as.
+func version() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// Preview returns the report that would be sent next, without sending it
+// or creating an installation ID.
+func Preview(cfg *config.Config) (*Report, error) {
+	path := File(cfg)
+	stats, err := usage.Load(sendingFile(path))
+	if err == nil && stats.Since.IsZero() {
+		stats, err = usage.Load(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	id, err := installID(path, false)
+	if err != nil {
+		return nil, err
+	}
+	return Build(stats, id), nil
+}
+
+// Reporter counts usage when telemetry is enabled and sends a report once
+// per interval. A disabled reporter counts nothing.
+type Reporter struct {
+	path     string
+	endpoint string
+	interval time.Duration
+	client   *http.Client
+	recorder *usage.Recorder
+	logger   *logger.Logger
+
+	mu   sync.Mutex // Serializes sends
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New returns the configured reporter. Reports are sent without the
+// network settings if they cannot be applied.
+func New(cfg *config.Config, log *logger.Logger) *Reporter {
+	if !Enabled(cfg) {
+		return &Reporter{recorder: usage.NewRecorder("")}
+	}
+	client, err := outbound.New(cfg.Network, sendTimeout)
+	if err != nil {
+		log.WithError(err).Warn("telemetry ignores the network settings")
+		client = &http.Client{Timeout: sendTimeout}
+	}
+	r := &Reporter{
+		path:     File(cfg),
+		endpoint: cfg.Telemetry.Endpoint,
+		interval: Interval(cfg),
+		client:   client,
+		recorder: usage.NewRecorder(File(cfg)),
+		logger:   log,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// ToolCall counts a call of tool.
+func (r *Reporter) ToolCall(tool string, failed bool) {
+	r.recorder.ToolCall(tool, 0, failed, 0)
+}
+
+// Run counts a requested command run, like usage.Recorder.Run.
+func (r *Reporter) Run(command string, err error, failed bool) {
+	r.recorder.Run(command, err, failed)
+}
+
+// run sends reports as they fall due until the reporter is closed.
+func (r *Reporter) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.Send(context.Background(), false); err != nil {
+			r.logger.WithError(err).Debug("failed to send telemetry report")
+		}
+		select {
+		case <-ticker.C:
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// Send sends the counts of the last interval, or all counts with force.
+// Counts being sent are moved aside so new ones accumulate separately,
+// and are sent again on the next try when sending fails.
+func (r *Reporter) Send(ctx context.Context, force bool) error {
+	if r.path == "" {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sending := sendingFile(r.path)
+	stats, err := usage.Load(sending)
+	if err != nil {
+		return err
+	}
+	if stats.Since.IsZero() {
+		if err := r.recorder.Flush(); err != nil {
+			return err
+		}
+		current, err := usage.Load(r.path)
+		if err != nil {
+			return err
+		}
+		if current.Since.IsZero() || (!force && time.Since(current.Since) < r.interval) {
+			return nil
+		}
+		if err := os.Rename(r.path, sending); err != nil {
+			return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to set telemetry counts aside")
+		}
+		stats = current
+	}
+
+	id, err := installID(r.path, true)
+	if err != nil {
+		return err
+	}
+	if err := r.post(ctx, Build(stats, id)); err != nil {
+		return err
+	}
+	if err := os.Remove(sending); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to remove sent telemetry counts")
+	}
+	return nil
+}
+
+// post sends a report to the endpoint.
+func (r *Reporter) post(ctx context.Context, report *Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to encode telemetry report")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "invalid telemetry endpoint")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to send telemetry report")
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return apperrors.New(apperrors.ErrorTypeExecution, fmt.Sprintf("telemetry endpoint returned %s", resp.Status))
+	}
+	return nil
+}
+
+// Close stops sending reports and saves the counts not sent yet.
+func (r *Reporter) Close() error {
+	if r.stop != nil {
+		close(r.stop)
+		<-r.done
+	}
+	return r.recorder.Close()
+}
+
+// sendingFile returns the file counts being sent are moved to.
+func sendingFile(path string) string {
+	return path + ".sending"
+}
+
+// installID returns the installation ID kept next to the counts file,
+// generating it with create when there is none.
+func installID(path string, create bool) (string, error) {
+	idFile := filepath.Join(filepath.Dir(path), "telemetry-id")
+	data, err := os.ReadFile(idFile)
+	if err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read telemetry installation ID")
+	}
+	if !create {
+		return "", nil
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to generate telemetry installation ID")
+	}
+	id := hex.EncodeToString(b)
+	if err := os.MkdirAll(filepath.Dir(idFile), 0o700); err != nil {
+		return "", apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to create telemetry directory")
+	}
+	if err := os.WriteFile(idFile, []byte(id+"\n"), 0o600); err != nil {
+		return "", apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to write telemetry installation ID")
+	}
+	return id, nil
+}
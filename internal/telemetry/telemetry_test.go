@@ -0,0 +1,122 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+func TestEnabled(t *testing.T) {
+	t.Setenv("DO_NOT_TRACK", "")
+	t.Setenv(OptOutEnv, "")
+	cfg := config.Default()
+	if Enabled(cfg) {
+		t.Error("telemetry is on by default")
+	}
+	cfg.Telemetry.Enabled = true
+	cfg.Telemetry.Endpoint = "https://telemetry.example.com/v1"
+	if !Enabled(cfg) {
+		t.Error("Enabled() = false with an endpoint")
+	}
+	for _, env := range []string{"DO_NOT_TRACK", OptOutEnv} {
+		t.Run(env, func(t *testing.T) {
+			value := "1"
+			if env == OptOutEnv {
+				value = "off"
+			}
+			t.Setenv(env, value)
+			if Enabled(cfg) || OptedOut() != env {
+				t.Errorf("Enabled() = true, OptedOut() = %q with %s=%s", OptedOut(), env, value)
+			}
+		})
+	}
+}
+
+func TestReporter(t *testing.T) {
+	t.Setenv("DO_NOT_TRACK", "")
+	t.Setenv(OptOutEnv, "")
+	var received []Report
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report Report
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			t.Errorf("decode report: %v", err)
+		}
+		received = append(received, report)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	cfg := config.Default()
+	cfg.Telemetry.Enabled = true
+	cfg.Telemetry.Endpoint = srv.URL
+	cfg.Telemetry.File = filepath.Join(dir, "telemetry.json")
+	r := New(cfg, logger.Default())
+	defer r.Close()
+
+	r.ToolCall("execute_command", false)
+	r.ToolCall("execute_command", true)
+	r.ToolCall("deploy-prod", false)
+	r.Run("rm", apperrors.PermissionError("command not allowed: rm", "rm"), false)
+	r.Run("make", nil, true)
+
+	// Counts are only sent once the interval has passed
+	if err := r.Send(context.Background(), false); err != nil || len(received) != 0 {
+		t.Fatalf("Send() = %v, sent %d reports before the interval", err, len(received))
+	}
+	if err := r.Send(context.Background(), true); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(received) != 1 {
+		t.Fatalf("sent %d reports, want 1", len(received))
+	}
+	report := received[0]
+	if report.Schema != Schema || len(report.InstallID) != 32 || report.OS == "" || report.Arch == "" {
+		t.Errorf("report = %+v", report)
+	}
+	if got := report.Tools["execute_command"]; got != (Counts{Calls: 2, Failures: 1}) {
+		t.Errorf("execute_command = %+v", got)
+	}
+	if report.OtherTools != (Counts{Calls: 1}) {
+		t.Errorf("other_tools = %+v, want the configured command counted anonymously", report.OtherTools)
+	}
+	if report.Errors[ErrorDenied] != 1 || report.Errors[ErrorFailed] != 1 {
+		t.Errorf("errors = %v", report.Errors)
+	}
+	data, _ := json.Marshal(report)
+	for _, name := range []string{"deploy-prod", "rm", "make", "not allowed"} {
+		if strings.Contains(string(data), name) {
+			t.Errorf("report contains %q: %s", name, data)
+		}
+	}
+
+	// Sent counts are gone; the installation ID stays
+	if _, err := os.Stat(sendingFile(cfg.Telemetry.File)); !os.IsNotExist(err) {
+		t.Errorf("sent counts kept: %v", err)
+	}
+	if preview, err := Preview(cfg); err != nil || preview.InstallID != report.InstallID || preview.Tools != nil {
+		t.Errorf("Preview() = %+v, %v", preview, err)
+	}
+}
+
+func TestReporter_disabled(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Default()
+	cfg.Telemetry.File = filepath.Join(dir, "telemetry.json")
+	r := New(cfg, logger.Default())
+	r.ToolCall("execute_command", false)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("disabled telemetry wrote %v", entries)
+	}
+}
@@ -0,0 +1,396 @@
+package testrun
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// goLocation matches the file and line t.Error and friends prefix
+	// messages with, and compiler errors.
+	goLocation = regexp.MustCompile(`^\s*(\S+\.go):(\d+)(?::\d+)?: (.*)$`)
+
+	// pytestSummary matches a line of the short test summary of -rfE.
+	pytestSummary = regexp.MustCompile(`^(FAILED|ERROR) (\S+?)(?: - (.*))?$`)
+	// pytestCount matches a count of the final line, e.g. "2 failed".
+	pytestCount = regexp.MustCompile(`(\d+) (passed|failed|skipped|errors?|xfailed|xpassed)`)
+	// pytestLocation matches the location pytest ends a failure with.
+	pytestLocation = regexp.MustCompile(`^(\S+\.py):(\d+): `)
+
+	// cargoRunning matches the test binary cargo starts, naming the crate.
+	cargoRunning = regexp.MustCompile(`^\s*Running (?:unittests )?\S+ \(.*[/\\]([A-Za-z0-9_]+)-[0-9a-f]+(?:\.exe)?\)`)
+	// cargoPanic matches where a test panicked, in the formats of Rust
+	// 1.73 and later and of older releases.
+	cargoPanic    = regexp.MustCompile(`panicked at (?:'.*', )?([^\s:]+\.rs):(\d+):\d+`)
+	cargoResult   = regexp.MustCompile(`^test result: \w+\. (\d+) passed; (\d+) failed; (\d+) ignored`)
+	cargoFailTest = regexp.MustCompile(`^test (\S+) \.\.\. FAILED$`)
+
+	// jestFile matches the result line of a test file.
+	jestFile = regexp.MustCompile(`^\s*FAIL\s+(\S+)`)
+	// jestTests matches the tests line of the summary.
+	jestTests = regexp.MustCompile(`^Tests:\s+(.*) total`)
+	jestCount = regexp.MustCompile(`(\d+) (passed|failed|skipped|todo)`)
+	// jestLocation matches a stack frame, e.g. "(src/sum.test.js:4:17)".
+	jestLocation = regexp.MustCompile(`\(?([^\s()]+\.[cm]?[jt]sx?):(\d+):\d+\)?`)
+	// tapNotOK matches a failed TAP test point, as node --test prints.
+	tapNotOK = regexp.MustCompile(`^\s*not ok \d+ - (.*)$`)
+)
+
+// goEvent is an event of go test -json, as documented by go doc test2json.
+type goEvent struct {
+	Action      string
+	Package     string
+	Test        string
+	Output      string
+	ImportPath  string // Of build-output and build-fail events
+	FailedBuild string // Of the fail event of a package that did not build
+}
+
+// goTest identifies a test, or a package when test is empty.
+type goTest struct{ pkg, test string }
+
+// parseGo parses the output of go test -json. Build errors printed on
+// stderr by releases before Go 1.24 are reported as package failures.
+func parseGo(stdout, stderr string) ([]Failure, *Counts) {
+	outputs := make(map[goTest][]string)
+	var failed []goTest
+	counts := &Counts{}
+	failedPkgs := make(map[string]bool)
+	var sawEvents bool
+
+	for _, line := range strings.Split(stdout, "\n") {
+		var e goEvent
+		if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &e) != nil {
+			continue
+		}
+		sawEvents = true
+		k := goTest{e.Package, e.Test}
+		switch e.Action {
+		case "output":
+			outputs[k] = append(outputs[k], strings.TrimRight(e.Output, "\n"))
+		case "build-output":
+			build := goTest{e.ImportPath, ""}
+			outputs[build] = append(outputs[build], strings.TrimRight(e.Output, "\n"))
+		case "pass":
+			if e.Test != "" {
+				counts.Passed++
+			}
+		case "skip":
+			if e.Test != "" {
+				counts.Skipped++
+			}
+		case "fail":
+			if e.Test != "" {
+				counts.Failed++
+				failedPkgs[e.Package] = true
+			}
+			if e.FailedBuild != "" {
+				// Report the compiler errors of the package
+				outputs[k] = append(outputs[goTest{e.FailedBuild, ""}], outputs[k]...)
+			}
+			failed = append(failed, k)
+		}
+	}
+
+	var failures []Failure
+	for _, k := range failed {
+		if k.test == "" {
+			// A package fails when one of its tests does; it is only
+			// reported when it failed on its own, e.g. to build
+			if failedPkgs[k.pkg] {
+				continue
+			}
+			failures = append(failures, goFailure(k.pkg, "", outputs[k]))
+			continue
+		}
+		if hasFailedSubtest(failed, k.pkg, k.test) {
+			continue
+		}
+		failures = append(failures, goFailure(k.pkg, k.test, outputs[k]))
+	}
+
+	if build := goBuildErrors(stderr); len(build) > 0 {
+		failures = append(failures, build...)
+	}
+	if !sawEvents {
+		return failures, nil
+	}
+	return failures, counts
+}
+
+// hasFailedSubtest reports whether a subtest of test failed, in which case
+// the subtest is reported instead.
+func hasFailedSubtest(failed []goTest, pkg, test string) bool {
+	for _, k := range failed {
+		if k.pkg == pkg && strings.HasPrefix(k.test, test+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// goFailure builds the failure of a test, or of a package when test is
+// empty, from its output.
+func goFailure(pkg, test string, output []string) Failure {
+	f := Failure{Test: test, Package: pkg}
+	var message []string
+	for _, line := range output {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "", trimmed == "FAIL", trimmed == "PASS",
+			strings.HasPrefix(trimmed, "=== "),
+			strings.HasPrefix(trimmed, "--- FAIL"),
+			strings.HasPrefix(trimmed, "FAIL\t"), strings.HasPrefix(trimmed, "ok "),
+			strings.HasPrefix(trimmed, "# "):
+			continue
+		}
+		if m := goLocation.FindStringSubmatch(line); m != nil && f.File == "" {
+			f.File = m[1]
+			f.Line, _ = strconv.Atoi(m[2])
+		}
+		message = append(message, trimmed)
+	}
+	f.Message = strings.Join(message, "\n")
+	return f
+}
+
+// goBuildErrors parses the compiler errors go test prints on stderr,
+// grouped by the package headers "# pkg".
+func goBuildErrors(stderr string) []Failure {
+	var failures []Failure
+	var pkg string
+	for _, line := range strings.Split(stderr, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			pkg = strings.TrimPrefix(line, "# ")
+			continue
+		}
+		if m := goLocation.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[2])
+			failures = append(failures, Failure{Package: pkg, File: m[1], Line: n, Message: m[3]})
+		}
+	}
+	return failures
+}
+
+// parsePytest parses the output of pytest -rfE: the short test summary
+// lists the failures, their sections locate them.
+func parsePytest(out string) ([]Failure, *Counts) {
+	lines := strings.Split(out, "\n")
+
+	// Sections are headed "____ test_name ____" and end with the
+	// location of the failure
+	locations := make(map[string]Failure)
+	var section string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "___") && strings.HasSuffix(trimmed, "___") {
+			section = strings.Trim(trimmed, "_ ")
+			continue
+		}
+		if m := pytestLocation.FindStringSubmatch(line); m != nil && section != "" {
+			n, _ := strconv.Atoi(m[2])
+			locations[section] = Failure{File: m[1], Line: n}
+		}
+	}
+
+	var failures []Failure
+	var counts *Counts
+	for _, line := range lines {
+		if m := pytestSummary.FindStringSubmatch(line); m != nil {
+			f := Failure{Message: m[3]}
+			file, test, ok := strings.Cut(m[2], "::")
+			f.File = file
+			if ok {
+				f.Test = test
+				// Sections name tests without their file, classes
+				// joined with a dot
+				if loc, ok := locations[strings.ReplaceAll(test, "::", ".")]; ok {
+					f.Line = loc.Line
+				}
+			}
+			if f.Message == "" && m[1] == "ERROR" {
+				f.Message = "error"
+			}
+			failures = append(failures, f)
+			continue
+		}
+		if trimmed := strings.Trim(line, "= "); strings.Contains(line, "===") && strings.Contains(trimmed, " in ") {
+			if matches := pytestCount.FindAllStringSubmatch(trimmed, -1); matches != nil {
+				counts = &Counts{}
+				for _, m := range matches {
+					n, _ := strconv.Atoi(m[1])
+					switch m[2] {
+					case "passed", "xpassed":
+						counts.Passed += n
+					case "failed", "error", "errors":
+						counts.Failed += n
+					case "skipped", "xfailed":
+						counts.Skipped += n
+					}
+				}
+			}
+		}
+	}
+	return failures, counts
+}
+
+// parseCargo parses the output of cargo test: failed tests are listed as
+// "test name ... FAILED", their output follows in "---- name stdout ----"
+// blocks. Compiler errors are reported as failures of their file.
+func parseCargo(out string) ([]Failure, *Counts) {
+	var failures []Failure
+	var counts *Counts
+	index := make(map[string]int) // Failure of each test, by crate and name
+	var crate string
+	var block *Failure
+	var message []string
+
+	flush := func() {
+		if block != nil {
+			block.Message = strings.TrimSpace(strings.Join(message, "\n"))
+			if i, ok := index[block.Package+" "+block.Test]; ok {
+				failures[i] = *block
+			} else {
+				failures = append(failures, *block)
+			}
+		}
+		block, message = nil, nil
+	}
+
+	lines := strings.Split(out, "\n")
+	for i, line := range lines {
+		if m := cargoRunning.FindStringSubmatch(line); m != nil {
+			flush()
+			crate = m[1]
+			continue
+		}
+		if strings.HasPrefix(line, "---- ") && strings.HasSuffix(line, " ----") {
+			flush()
+			name := strings.TrimSuffix(strings.TrimPrefix(line, "---- "), " ----")
+			name = strings.TrimSuffix(strings.TrimSuffix(name, " stdout"), " stderr")
+			block = &Failure{Test: name, Package: crate}
+			continue
+		}
+		if m := cargoFailTest.FindStringSubmatch(line); m != nil {
+			index[crate+" "+m[1]] = len(failures)
+			failures = append(failures, Failure{Test: m[1], Package: crate})
+			continue
+		}
+		if m := cargoResult.FindStringSubmatch(line); m != nil {
+			flush()
+			if counts == nil {
+				counts = &Counts{}
+			}
+			passed, _ := strconv.Atoi(m[1])
+			failed, _ := strconv.Atoi(m[2])
+			ignored, _ := strconv.Atoi(m[3])
+			counts.Passed += passed
+			counts.Failed += failed
+			counts.Skipped += ignored
+			continue
+		}
+		if strings.HasPrefix(line, "failures:") {
+			flush()
+			continue
+		}
+		if strings.HasPrefix(line, "error") && i+1 < len(lines) {
+			// error[E0425]: cannot find value `x` in this scope
+			//   --> src/lib.rs:3:5
+			if loc := strings.TrimSpace(lines[i+1]); strings.HasPrefix(loc, "--> ") {
+				f := Failure{Package: crate, Message: line}
+				if parts := strings.Split(strings.TrimPrefix(loc, "--> "), ":"); len(parts) >= 2 {
+					f.File = parts[0]
+					f.Line, _ = strconv.Atoi(parts[1])
+				}
+				failures = append(failures, f)
+			}
+			continue
+		}
+		if block != nil {
+			if m := cargoPanic.FindStringSubmatch(line); m != nil && block.File == "" {
+				block.File = m[1]
+				block.Line, _ = strconv.Atoi(m[2])
+			}
+			if !strings.HasPrefix(line, "note: run with `RUST_BACKTRACE") {
+				message = append(message, line)
+			}
+		}
+	}
+	flush()
+	return failures, counts
+}
+
+// parseNpm parses the output of npm test, understanding Jest and TAP
+// output such as node --test prints.
+func parseNpm(out string) ([]Failure, *Counts) {
+	var failures []Failure
+	var counts *Counts
+	var file string
+	var block *Failure
+	var message []string
+
+	flush := func() {
+		if block != nil {
+			block.Message = strings.TrimSpace(strings.Join(message, "\n"))
+			failures = append(failures, *block)
+		}
+		block, message = nil, nil
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if m := jestFile.FindStringSubmatch(line); m != nil {
+			flush()
+			file = m[1]
+			continue
+		}
+		if strings.HasPrefix(trimmed, "● ") {
+			flush()
+			name := strings.TrimPrefix(trimmed, "● ")
+			if name == "Test suite failed to run" {
+				block = &Failure{File: file}
+			} else {
+				block = &Failure{Test: name, File: file}
+			}
+			continue
+		}
+		if m := tapNotOK.FindStringSubmatch(line); m != nil {
+			flush()
+			failures = append(failures, Failure{Test: m[1]})
+			continue
+		}
+		if m := jestTests.FindStringSubmatch(trimmed); m != nil {
+			flush()
+			counts = &Counts{}
+			for _, c := range jestCount.FindAllStringSubmatch(m[1], -1) {
+				n, _ := strconv.Atoi(c[1])
+				switch c[2] {
+				case "passed":
+					counts.Passed = n
+				case "failed":
+					counts.Failed = n
+				default:
+					counts.Skipped += n
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "Test Suites:") || strings.HasPrefix(trimmed, "Snapshots:") || strings.HasPrefix(trimmed, "Time:") {
+			flush()
+			continue
+		}
+		if block != nil {
+			if m := jestLocation.FindStringSubmatch(trimmed); m != nil && block.Line == 0 && strings.HasPrefix(trimmed, "at ") && !strings.Contains(m[1], "node_modules") {
+				if block.File == "" || strings.HasSuffix(m[1], block.File) {
+					block.File = m[1]
+					block.Line, _ = strconv.Atoi(m[2])
+				}
+			}
+			message = append(message, trimmed)
+		}
+	}
+	flush()
+	return failures, counts
+}
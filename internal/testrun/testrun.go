@@ -0,0 +1,191 @@
+// Package testrun detects the test framework of a project, builds the
+// command running its tests and parses their failures from the output.
+package testrun
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// Test frameworks.
+const (
+	FrameworkGo     = "go"
+	FrameworkCargo  = "cargo"
+	FrameworkNpm    = "npm"
+	FrameworkPytest = "pytest"
+)
+
+// Frameworks are the supported test frameworks, in detection order.
+var Frameworks = []string{FrameworkGo, FrameworkCargo, FrameworkNpm, FrameworkPytest}
+
+// pytestMarkers are the files marking a Python project pytest can test.
+var pytestMarkers = []string{"pytest.ini", "conftest.py", "pyproject.toml", "setup.cfg", "tox.ini", "setup.py"}
+
+// Limits of a report.
+const (
+	maxFailures       = 100
+	maxFailureMessage = 4096
+)
+
+// Report is the outcome of a test run.
+type Report struct {
+	Framework string    `json:"framework"`
+	Command   string    `json:"command"`
+	Args      []string  `json:"args"`
+	WorkDir   string    `json:"workdir"`
+	Passed    bool      `json:"passed"`
+	ExitCode  int       `json:"exit_code"`
+	Counts    *Counts   `json:"counts,omitempty"` // When the output reports them
+	Failures  []Failure `json:"failures"`
+
+	// FailuresTruncated is set when failures were left out
+	FailuresTruncated bool `json:"failures_truncated,omitempty"`
+	// OutputTruncated is set when only the start and end of a huge output
+	// were parsed, missing failures and counts in between
+	OutputTruncated bool `json:"output_truncated,omitempty"`
+
+	// HistoryID is the execution holding the raw output
+	HistoryID string        `json:"history_id,omitempty"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// Counts are the numbers of tests by outcome.
+type Counts struct {
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+// Failure is a failed test, or a package or file that failed to build or
+// load.
+type Failure struct {
+	Test    string `json:"test,omitempty"`
+	Package string `json:"package,omitempty"` // Go package or Rust crate
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Options select the tests to run.
+type Options struct {
+	Filter string   // Name pattern of the tests to run
+	Args   []string // Extra arguments of the test command
+}
+
+// Detect returns the framework of the project dir is in, looking in dir
+// and its parents up to the root of the git repository. Parents outside
+// allowed_paths are not looked in.
+func Detect(cfg *config.Config, dir string) (string, error) {
+	for d := dir; ; {
+		if framework := detectIn(d); framework != "" {
+			return framework, nil
+		}
+		parent := filepath.Dir(d)
+		if parent == d || config.Exists(filepath.Join(d, ".git")) || !cfg.IsPathAllowed(parent) {
+			return "", apperrors.NotFoundError("no go.mod, Cargo.toml, package.json or pytest configuration found", dir)
+		}
+		d = parent
+	}
+}
+
+// detectIn returns the framework of a project rooted in dir, or "".
+func detectIn(dir string) string {
+	switch {
+	case config.Exists(filepath.Join(dir, "go.mod")):
+		return FrameworkGo
+	case config.Exists(filepath.Join(dir, "Cargo.toml")):
+		return FrameworkCargo
+	case hasTestScript(filepath.Join(dir, "package.json")):
+		return FrameworkNpm
+	}
+	for _, marker := range pytestMarkers {
+		if config.Exists(filepath.Join(dir, marker)) {
+			return FrameworkPytest
+		}
+	}
+	return ""
+}
+
+// hasTestScript reports whether a package.json defines a test script.
+func hasTestScript(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	return json.Unmarshal(data, &pkg) == nil && pkg.Scripts["test"] != ""
+}
+
+// Command returns the command line running the tests of a framework, with
+// output the parser of the framework reads.
+func Command(framework string, opts Options) (string, []string, error) {
+	switch framework {
+	case FrameworkGo:
+		args := []string{"test", "-json"}
+		if opts.Filter != "" {
+			args = append(args, "-run", opts.Filter)
+		}
+		if len(opts.Args) == 0 {
+			return "go", append(args, "./..."), nil
+		}
+		return "go", append(args, opts.Args...), nil
+	case FrameworkCargo:
+		args := []string{"test"}
+		if opts.Filter != "" {
+			args = append(args, opts.Filter)
+		}
+		return "cargo", append(args, opts.Args...), nil
+	case FrameworkNpm:
+		args := []string{"test"}
+		if opts.Filter != "" || len(opts.Args) > 0 {
+			args = append(args, "--")
+		}
+		if opts.Filter != "" {
+			args = append(args, "-t", opts.Filter)
+		}
+		return "npm", append(args, opts.Args...), nil
+	case FrameworkPytest:
+		args := []string{"-rfE"}
+		if opts.Filter != "" {
+			args = append(args, "-k", opts.Filter)
+		}
+		return "pytest", append(args, opts.Args...), nil
+	}
+	return "", nil, apperrors.ValidationError("unknown framework "+framework+" (must be one of "+strings.Join(Frameworks, ", ")+")", "framework")
+}
+
+// Parse fills a report with the failures and counts of a test run's
+// output.
+func Parse(report *Report, stdout, stderr string) {
+	var failures []Failure
+	switch report.Framework {
+	case FrameworkGo:
+		failures, report.Counts = parseGo(stdout, stderr)
+	case FrameworkCargo:
+		failures, report.Counts = parseCargo(stdout + "\n" + stderr)
+	case FrameworkNpm:
+		failures, report.Counts = parseNpm(stdout + "\n" + stderr)
+	case FrameworkPytest:
+		failures, report.Counts = parsePytest(stdout)
+	}
+
+	report.Failures = []Failure{}
+	for _, f := range failures {
+		if len(report.Failures) == maxFailures {
+			report.FailuresTruncated = true
+			break
+		}
+		if len(f.Message) > maxFailureMessage {
+			f.Message = f.Message[:maxFailureMessage] + "..."
+		}
+		report.Failures = append(report.Failures, f)
+	}
+}
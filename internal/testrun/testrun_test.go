@@ -0,0 +1,259 @@
+package testrun
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+func write(t *testing.T, dir, name, data string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "go/go.mod", "module example.com/m\n")
+	write(t, root, "go/internal/pkg/pkg.go", "package pkg\n")
+	write(t, root, "rust/Cargo.toml", "[package]\n")
+	write(t, root, "node/package.json", `{"scripts": {"test": "jest"}}`)
+	write(t, root, "python/pyproject.toml", "[project]\n")
+	write(t, root, "python/package.json", `{"scripts": {"build": "tsc"}}`)
+	write(t, root, "repo/.git/HEAD", "ref: refs/heads/main\n")
+	write(t, root, "repo/docs/index.md", "# Docs\n")
+	write(t, root, "package.json", `{"scripts": {"test": "jest"}}`)
+
+	cfg := config.Default()
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"go/internal/pkg", FrameworkGo},
+		{"rust", FrameworkCargo},
+		{"node", FrameworkNpm},
+		{"python", FrameworkPytest}, // No test script in package.json
+		{"repo/docs", ""},           // Detection stops at the repository root
+	}
+	for _, tt := range tests {
+		got, err := Detect(cfg, filepath.Join(root, tt.dir))
+		if got != tt.want || (err != nil) != (tt.want == "") {
+			t.Errorf("Detect(%s) = %q, %v, want %q", tt.dir, got, err, tt.want)
+		}
+	}
+
+	// Parents outside allowed_paths are not looked in
+	cfg.Security.AllowedPaths = []string{filepath.Join(root, "go", "internal")}
+	if got, err := Detect(cfg, filepath.Join(root, "go", "internal", "pkg")); err == nil {
+		t.Errorf("Detect() = %q, looked outside allowed_paths", got)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		framework string
+		opts      Options
+		want      string
+	}{
+		{FrameworkGo, Options{}, "go test -json ./..."},
+		{FrameworkGo, Options{Filter: "TestX", Args: []string{"./pkg"}}, "go test -json -run TestX ./pkg"},
+		{FrameworkCargo, Options{Filter: "parser", Args: []string{"--lib"}}, "cargo test parser --lib"},
+		{FrameworkNpm, Options{}, "npm test"},
+		{FrameworkNpm, Options{Filter: "sum"}, "npm test -- -t sum"},
+		{FrameworkPytest, Options{Filter: "slow", Args: []string{"tests"}}, "pytest -rfE -k slow tests"},
+	}
+	for _, tt := range tests {
+		name, args, err := Command(tt.framework, tt.opts)
+		if got := strings.Join(append([]string{name}, args...), " "); err != nil || got != tt.want {
+			t.Errorf("Command(%s, %+v) = %q, %v, want %q", tt.framework, tt.opts, got, err, tt.want)
+		}
+	}
+	if _, _, err := Command("make", Options{}); err == nil {
+		t.Error("Command() accepted an unknown framework")
+	}
+}
+
+func TestParse_go(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	dir := t.TempDir()
+	write(t, dir, "go.mod", "module example.com/m\n\ngo 1.21\n")
+	write(t, dir, "a_test.go", `package m
+
+import "testing"
+
+func TestOK(t *testing.T) {}
+
+func TestBad(t *testing.T) {
+	t.Run("sub", func(t *testing.T) {
+		t.Errorf("got %d, want %d", 1, 2)
+	})
+}
+
+func TestSkip(t *testing.T) { t.Skip("later") }
+`)
+	write(t, dir, "broken/b_test.go", "package broken\n\nimport \"testing\"\n\nfunc TestX(t *testing.T) { undefined() }\n")
+
+	name, args, _ := Command(FrameworkGo, Options{})
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	var stdout, stderr strings.Builder
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	cmd.Env = append(os.Environ(), "GOFLAGS=", "GOWORK=off")
+	_ = cmd.Run()
+
+	report := &Report{Framework: FrameworkGo}
+	Parse(report, stdout.String(), stderr.String())
+	if report.Counts == nil || *report.Counts != (Counts{Passed: 1, Failed: 2, Skipped: 1}) {
+		t.Errorf("Counts = %+v", report.Counts)
+	}
+	if len(report.Failures) != 2 {
+		t.Fatalf("Failures = %+v, want the subtest and the build failure", report.Failures)
+	}
+	sub := report.Failures[0]
+	if sub.Test != "TestBad/sub" || sub.Package != "example.com/m" || sub.File != "a_test.go" || sub.Line != 9 || !strings.Contains(sub.Message, "got 1, want 2") {
+		t.Errorf("subtest failure = %+v", sub)
+	}
+	build := report.Failures[1]
+	if build.Test != "" || !strings.HasSuffix(build.File, "b_test.go") || build.Line != 5 || !strings.Contains(build.Message, "undefined") {
+		t.Errorf("build failure = %+v", build)
+	}
+}
+
+func TestParse_pytest(t *testing.T) {
+	out := `============================= test session starts ==============================
+collected 4 items
+
+tests/test_math.py .F.s                                                  [100%]
+
+=================================== FAILURES ===================================
+__________________________ TestMath.test_division ___________________________
+
+    def test_division(self):
+>       assert 1 / 2 == 1
+E       assert 0.5 == 1
+
+tests/test_math.py:12: AssertionError
+=========================== short test summary info ============================
+FAILED tests/test_math.py::TestMath::test_division - assert 0.5 == 1
+=================== 1 failed, 2 passed, 1 skipped in 0.03s ====================
+`
+	report := &Report{Framework: FrameworkPytest}
+	Parse(report, out, "")
+	if report.Counts == nil || *report.Counts != (Counts{Passed: 2, Failed: 1, Skipped: 1}) {
+		t.Errorf("Counts = %+v", report.Counts)
+	}
+	want := Failure{Test: "TestMath::test_division", File: "tests/test_math.py", Line: 12, Message: "assert 0.5 == 1"}
+	if len(report.Failures) != 1 || report.Failures[0] != want {
+		t.Errorf("Failures = %+v, want %+v", report.Failures, want)
+	}
+}
+
+func TestParse_cargo(t *testing.T) {
+	out := `     Running unittests src/lib.rs (target/debug/deps/calc-3f2a1b4c5d6e7f80)
+
+running 2 tests
+test tests::adds ... ok
+test tests::divides ... FAILED
+
+failures:
+
+---- tests::divides stdout ----
+
+thread 'tests::divides' panicked at src/lib.rs:14:9:
+assertion ` + "`left == right`" + ` failed
+  left: 0
+ right: 1
+note: run with ` + "`RUST_BACKTRACE=1`" + ` environment variable to display a backtrace
+
+
+failures:
+    tests::divides
+
+test result: FAILED. 1 passed; 1 failed; 0 ignored; 0 measured; 0 filtered out; finished in 0.00s
+`
+	report := &Report{Framework: FrameworkCargo}
+	Parse(report, out, "")
+	if report.Counts == nil || *report.Counts != (Counts{Passed: 1, Failed: 1}) {
+		t.Errorf("Counts = %+v", report.Counts)
+	}
+	if len(report.Failures) != 1 {
+		t.Fatalf("Failures = %+v", report.Failures)
+	}
+	f := report.Failures[0]
+	if f.Test != "tests::divides" || f.Package != "calc" || f.File != "src/lib.rs" || f.Line != 14 || !strings.Contains(f.Message, "left: 0") {
+		t.Errorf("failure = %+v", f)
+	}
+}
+
+func TestParse_npm(t *testing.T) {
+	out := `> calc@1.0.0 test
+> jest
+
+ FAIL  src/sum.test.js
+  ● sum › adds numbers
+
+    expect(received).toBe(expected) // Object.is equality
+
+    Expected: 4
+    Received: 5
+
+      3 | test('adds numbers', () => {
+    > 4 |   expect(sum(2, 2)).toBe(4);
+        |                     ^
+
+      at Object.toBe (src/sum.test.js:4:21)
+
+ PASS  src/other.test.js
+
+Test Suites: 1 failed, 1 passed, 2 total
+Tests:       1 failed, 3 passed, 4 total
+Snapshots:   0 total
+Time:        0.5 s
+`
+	report := &Report{Framework: FrameworkNpm}
+	Parse(report, out, "")
+	if report.Counts == nil || *report.Counts != (Counts{Passed: 3, Failed: 1}) {
+		t.Errorf("Counts = %+v", report.Counts)
+	}
+	if len(report.Failures) != 1 {
+		t.Fatalf("Failures = %+v", report.Failures)
+	}
+	f := report.Failures[0]
+	if f.Test != "sum › adds numbers" || f.File != "src/sum.test.js" || f.Line != 4 || !strings.Contains(f.Message, "Received: 5") {
+		t.Errorf("failure = %+v", f)
+	}
+
+	// TAP output, as node --test prints
+	report = &Report{Framework: FrameworkNpm}
+	Parse(report, "TAP version 13\nok 1 - adds\nnot ok 2 - divides\n", "")
+	if len(report.Failures) != 1 || report.Failures[0].Test != "divides" {
+		t.Errorf("TAP failures = %+v", report.Failures)
+	}
+}
+
+func TestParse_limits(t *testing.T) {
+	var out []string
+	for range maxFailures + 1 {
+		out = append(out, "FAILED tests/test_a.py::test_a - "+strings.Repeat("x", maxFailureMessage+1))
+	}
+	report := &Report{Framework: FrameworkPytest}
+	Parse(report, strings.Join(out, "\n"), "")
+	if len(report.Failures) != maxFailures || !report.FailuresTruncated {
+		t.Errorf("Parse() = %d failures, truncated %v", len(report.Failures), report.FailuresTruncated)
+	}
+	if !slices.ContainsFunc(report.Failures, func(f Failure) bool { return strings.HasSuffix(f.Message, "...") }) {
+		t.Error("Parse() did not truncate long messages")
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package tmux
+
+import "syscall"
+
+// signalChild tells the tmux server a child process changed state.
+func signalChild(pid int) error {
+	return syscall.Kill(pid, syscall.SIGCHLD)
+}
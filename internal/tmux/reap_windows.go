@@ -0,0 +1,8 @@
+//go:build windows
+
+package tmux
+
+// signalChild does nothing: tmux does not run on Windows.
+func signalChild(pid int) error {
+	return nil
+}
@@ -0,0 +1,415 @@
+// Package tmux runs interactive programs in tmux sessions on a server of
+// their own, so tools can type into them and read their screens across
+// calls
+package tmux
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+const (
+	// defaultSocket names the tmux server when the configuration does not.
+	defaultSocket = "simple-mcp-runner"
+
+	// commandTimeout bounds a single tmux invocation.
+	commandTimeout = 10 * time.Second
+
+	// maxKeysSize limits the text sent to a session in one call.
+	maxKeysSize = 64 << 10
+
+	// sessionPollInterval is how often Create checks for a new session,
+	// and List for the status of an exited program.
+	sessionPollInterval = 20 * time.Millisecond
+
+	// reapPolls bounds how often List reads the sessions again after
+	// asking the server to reap exited programs.
+	reapPolls = 10
+)
+
+var (
+	// sessionName is the form of session names, which must not contain
+	// the characters tmux uses in targets.
+	sessionName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,50}$`)
+
+	// keyName is the form of tmux key names, such as Enter, C-c or F5.
+	keyName = regexp.MustCompile(`^[A-Za-z0-9-]{1,20}$`)
+)
+
+// Session describes a session.
+type Session struct {
+	Name       string    `json:"name"`
+	Command    string    `json:"command"` // Program running in the pane
+	PID        int       `json:"pid,omitempty"`
+	Created    time.Time `json:"created"`
+	Dead       bool      `json:"dead"`                  // The program exited
+	ExitStatus *int      `json:"exit_status,omitempty"` // Set once the program exited and was reaped
+
+	signaled bool // The program was killed by a signal, so has no status
+}
+
+// CreateRequest starts a session.
+type CreateRequest struct {
+	Name    string
+	Command []string // Program and arguments, run without a shell
+	WorkDir string
+	Width   int
+	Height  int
+}
+
+// Capture is the screen and scrollback of a session.
+type Capture struct {
+	Session
+	Lines   int    `json:"lines"` // Scrollback lines requested
+	Content string `json:"content"`
+}
+
+// Manager runs the sessions in a tmux server of its own.
+type Manager struct {
+	config *config.Config
+	bin    string   // tmux binary, or "" if not installed
+	socket string   // tmux -L socket name
+	env    []string // Environment of the tmux server and so its sessions
+	logger *logger.Logger
+}
+
+// New creates a manager whose sessions inherit env. A missing tmux binary
+// is only reported when a tool is called.
+func New(cfg *config.Config, env []string, log *logger.Logger) *Manager {
+	m := &Manager{config: cfg, socket: cfg.Tmux.Socket, env: env, logger: log}
+	if m.socket == "" {
+		m.socket = defaultSocket
+	}
+	if bin, err := exec.LookPath("tmux"); err == nil {
+		m.bin = bin
+	} else {
+		log.Warn("tmux not found, tmux session tools will fail")
+	}
+	return m
+}
+
+// Close kills the sessions unless they are configured to outlive the
+// server.
+func (m *Manager) Close() error {
+	if m == nil || m.bin == "" || m.config.Tmux.KeepOnExit {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	if _, err := m.run(ctx, "kill-server"); err != nil && !noServer(err) {
+		return err
+	}
+	return nil
+}
+
+// Create starts a program in a new detached session. The program's pane
+// remains after it exits, so its last output can still be captured.
+func (m *Manager) Create(ctx context.Context, req CreateRequest) (*Session, error) {
+	if !sessionName.MatchString(req.Name) {
+		return nil, apperrors.ValidationError("session name must be 1-50 letters, numbers, '_' or '-'", "name")
+	}
+	if len(req.Command) == 0 || req.Command[0] == "" {
+		return nil, apperrors.ValidationError("command is required", "command")
+	}
+
+	sessions, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sessions {
+		if s.Name == req.Name {
+			return nil, apperrors.ValidationError("session already exists: "+req.Name, "name")
+		}
+	}
+	if max := m.config.Tmux.MaxSessions; max > 0 && len(sessions) >= max {
+		return nil, apperrors.New(apperrors.ErrorTypeValidation,
+			fmt.Sprintf("session limit reached (%d); kill a session first", max))
+	}
+
+	args := []string{"start-server", ";", "set-option", "-wg", "remain-on-exit", "on", ";",
+		"new-session", "-d", "-s", req.Name}
+	if req.WorkDir != "" {
+		args = append(args, "-c", req.WorkDir)
+	}
+	if req.Width > 0 {
+		args = append(args, "-x", strconv.Itoa(req.Width))
+	}
+	if req.Height > 0 {
+		args = append(args, "-y", strconv.Itoa(req.Height))
+	}
+	args = append(args, "--")
+	args = append(args, req.Command...)
+	if _, err := m.run(ctx, args...); err != nil {
+		return nil, err
+	}
+	if err := m.waitSession(ctx, req.Name); err != nil {
+		return nil, err
+	}
+	return m.get(ctx, req.Name)
+}
+
+// waitSession waits until the server reports a session, which it may not
+// yet do right after new-session returns, such as while the server it
+// started is still coming up.
+func (m *Manager) waitSession(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	for {
+		_, err := m.run(ctx, "has-session", "-t", "="+name)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return apperrors.Wrap(err, apperrors.ErrorTypeExecution, "session did not start: "+name)
+		case <-time.After(sessionPollInterval):
+		}
+	}
+}
+
+// List returns the sessions, sorted by name.
+func (m *Manager) List(ctx context.Context) ([]Session, error) {
+	sessions, err := m.list(ctx)
+	if err != nil || !unreaped(sessions) {
+		return sessions, err
+	}
+
+	// tmux records the status of a program when it reaps it on SIGCHLD,
+	// which it sometimes misses, leaving the program a zombie and its
+	// dead pane without a status until another child exits
+	if err := m.reap(ctx); err != nil {
+		m.logger.WithError(err).Debug("failed to signal the tmux server")
+		return sessions, nil
+	}
+	for range reapPolls {
+		select {
+		case <-ctx.Done():
+			return sessions, nil
+		case <-time.After(sessionPollInterval):
+		}
+		if sessions, err = m.list(ctx); err != nil || !unreaped(sessions) {
+			break
+		}
+	}
+	return sessions, err
+}
+
+// unreaped reports whether a session's program exited without tmux
+// recording how.
+func unreaped(sessions []Session) bool {
+	for _, s := range sessions {
+		if s.Dead && s.ExitStatus == nil && !s.signaled {
+			return true
+		}
+	}
+	return false
+}
+
+// reap makes the tmux server reap the programs that exited.
+func (m *Manager) reap(ctx context.Context) error {
+	out, err := m.run(ctx, "display-message", "-p", "#{pid}")
+	if err != nil {
+		return err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil {
+		return err
+	}
+	return signalChild(pid)
+}
+
+// list reads the sessions, sorted by name.
+func (m *Manager) list(ctx context.Context) ([]Session, error) {
+	out, err := m.run(ctx, "list-sessions", "-F", sessionFormat)
+	if err != nil {
+		if noServer(err) {
+			return []Session{}, nil
+		}
+		return nil, err
+	}
+	sessions := []Session{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line != "" {
+			sessions = append(sessions, parseSession(line))
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Name < sessions[j].Name })
+	return sessions, nil
+}
+
+// SendKeys types text into a session, then presses the named keys, such
+// as C-c or Up, and Enter if enter is set.
+func (m *Manager) SendKeys(ctx context.Context, name, text string, keys []string, enter bool) error {
+	target, err := m.target(name)
+	if err != nil {
+		return err
+	}
+	if len(text) > maxKeysSize {
+		return apperrors.ValidationError(fmt.Sprintf("text exceeds %d bytes", maxKeysSize), "text")
+	}
+	for _, key := range keys {
+		if !keyName.MatchString(key) {
+			return apperrors.ValidationError("invalid key name: "+key, "keys")
+		}
+	}
+	if enter {
+		keys = append(keys, "Enter")
+	}
+	if text == "" && len(keys) == 0 {
+		return apperrors.ValidationError("nothing to send: give text, keys or enter", "text")
+	}
+
+	// Text is sent as hex bytes, as tmux would take an argument ending
+	// in ";" for a command separator.
+	if text != "" {
+		args := []string{"send-keys", "-t", target, "-H"}
+		for _, b := range []byte(text) {
+			args = append(args, strconv.FormatUint(uint64(b), 16))
+		}
+		if _, err := m.run(ctx, args...); err != nil {
+			return err
+		}
+	}
+	if len(keys) > 0 {
+		if _, err := m.run(ctx, append([]string{"send-keys", "-t", target}, keys...)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Capture returns the screen of a session with up to lines lines of
+// scrollback, capped by the configuration. Trailing blank lines are
+// dropped.
+func (m *Manager) Capture(ctx context.Context, name string, lines int) (*Capture, error) {
+	target, err := m.target(name)
+	if err != nil {
+		return nil, err
+	}
+	if max := m.config.Tmux.MaxCaptureLines; lines <= 0 || (max > 0 && lines > max) {
+		lines = max
+	}
+
+	session, err := m.get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	out, err := m.run(ctx, "capture-pane", "-p", "-J", "-t", target, "-S", strconv.Itoa(-lines))
+	if err != nil {
+		return nil, err
+	}
+	return &Capture{Session: *session, Lines: lines, Content: strings.TrimRight(out, "\n ")}, nil
+}
+
+// Kill ends a session and its program.
+func (m *Manager) Kill(ctx context.Context, name string) error {
+	if _, err := m.target(name); err != nil {
+		return err
+	}
+	if _, err := m.get(ctx, name); err != nil {
+		return err
+	}
+	_, err := m.run(ctx, "kill-session", "-t", "="+name)
+	return err
+}
+
+// get returns a session by name.
+func (m *Manager) get(ctx context.Context, name string) (*Session, error) {
+	sessions, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		if sessions[i].Name == name {
+			return &sessions[i], nil
+		}
+	}
+	return nil, apperrors.NotFoundError("no such session: "+name, name)
+}
+
+// target returns the tmux target of the active pane of a session,
+// matching its name exactly rather than by prefix.
+func (m *Manager) target(name string) (string, error) {
+	if !sessionName.MatchString(name) {
+		return "", apperrors.ValidationError("invalid session name: "+name, "name")
+	}
+	return "=" + name + ":", nil
+}
+
+// sessionFormat is the list-sessions format parseSession reads.
+const sessionFormat = "#{session_name}\t#{session_created}\t#{pane_current_command}\t#{pane_pid}\t#{pane_dead}\t#{pane_dead_status}\t#{pane_dead_signal}"
+
+// parseSession reads a line of list-sessions output.
+func parseSession(line string) Session {
+	fields := strings.Split(line, "\t")
+	for len(fields) < 7 {
+		fields = append(fields, "")
+	}
+	s := Session{Name: fields[0], Command: fields[2], Dead: fields[4] == "1", signaled: fields[6] != ""}
+	if created, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+		s.Created = time.Unix(created, 0)
+	}
+	if s.Dead {
+		if status, err := strconv.Atoi(fields[5]); err == nil {
+			s.ExitStatus = &status
+		}
+	} else if pid, err := strconv.Atoi(fields[3]); err == nil {
+		s.PID = pid
+	}
+	return s
+}
+
+// tmuxError is a failed tmux invocation.
+type tmuxError struct {
+	stderr string
+}
+
+func (e *tmuxError) Error() string {
+	return "tmux: " + e.stderr
+}
+
+// noServer reports whether tmux failed because no sessions exist.
+func noServer(err error) bool {
+	var te *tmuxError
+	if !errors.As(err, &te) {
+		return false
+	}
+	return strings.Contains(te.stderr, "no server running") ||
+		strings.Contains(te.stderr, "error connecting") ||
+		strings.Contains(te.stderr, "server exited")
+}
+
+// run invokes tmux on the manager's server.
+func (m *Manager) run(ctx context.Context, args ...string) (string, error) {
+	if m.bin == "" {
+		return "", apperrors.ConfigurationError("tmux is not installed")
+	}
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, m.bin, append([]string{"-L", m.socket, "-f", os.DevNull}, args...)...)
+	cmd.Env = m.env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", apperrors.Wrap(&tmuxError{stderr: msg}, apperrors.ErrorTypeExecution, "tmux "+args[0]+" failed")
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,107 @@
+package tmux
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestManager returns a manager on a socket of its own, killed when
+// the test ends.
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not in PATH")
+	}
+	cfg := config.Default()
+	cfg.Tmux.Socket = fmt.Sprintf("mcp-test-%d-%d", os.Getpid(), time.Now().UnixNano())
+	cfg.Tmux.MaxSessions = 2
+	log, err := logger.New(logger.Options{Level: "error", Output: io.Discard})
+	require.NoError(t, err)
+
+	m := New(cfg, os.Environ(), log)
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+// eventually polls a session's screen until it contains want.
+func eventually(t *testing.T, m *Manager, name, want string) *Capture {
+	t.Helper()
+	var capture *Capture
+	require.Eventually(t, func() bool {
+		var err error
+		capture, err = m.Capture(context.Background(), name, 100)
+		return err == nil && strings.Contains(capture.Content, want)
+	}, 5*time.Second, 20*time.Millisecond, "session %s never showed %q", name, want)
+	return capture
+}
+
+func TestManager_Session(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	sessions, err := m.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	session, err := m.Create(ctx, CreateRequest{Name: "repl", Command: []string{"cat"}, WorkDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.Equal(t, "repl", session.Name)
+	assert.False(t, session.Dead)
+
+	// Text ending in ";" reaches the program intact
+	require.NoError(t, m.SendKeys(ctx, "repl", `echo "$HOME" ;`, nil, true))
+	eventually(t, m, "repl", `echo "$HOME" ;`)
+
+	_, err = m.Create(ctx, CreateRequest{Name: "repl", Command: []string{"cat"}})
+	assert.ErrorContains(t, err, "already exists")
+
+	_, err = m.Create(ctx, CreateRequest{Name: "exits", Command: []string{"sh", "-c", "echo done; exit 3"}})
+	require.NoError(t, err)
+	capture := eventually(t, m, "exits", "done")
+	// tmux marks the pane dead when the program's output ends and records
+	// its status once it is reaped
+	require.Eventually(t, func() bool {
+		capture, err = m.Capture(ctx, "exits", 0)
+		return err == nil && capture.Dead && capture.ExitStatus != nil
+	}, 5*time.Second, 20*time.Millisecond)
+	require.NotNil(t, capture.ExitStatus)
+	assert.Equal(t, 3, *capture.ExitStatus)
+
+	_, err = m.Create(ctx, CreateRequest{Name: "third", Command: []string{"cat"}})
+	assert.ErrorContains(t, err, "session limit reached")
+
+	// Sessions are matched by exact name, not prefix
+	_, err = m.Capture(ctx, "rep", 10)
+	assert.ErrorContains(t, err, "no such session")
+
+	require.NoError(t, m.Kill(ctx, "exits"))
+	sessions, err = m.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "repl", sessions[0].Name)
+}
+
+func TestManager_Validation(t *testing.T) {
+	m := newTestManager(t)
+	ctx := context.Background()
+
+	_, err := m.Create(ctx, CreateRequest{Name: "bad:name", Command: []string{"cat"}})
+	assert.ErrorContains(t, err, "session name")
+
+	_, err = m.Create(ctx, CreateRequest{Name: "ok"})
+	assert.ErrorContains(t, err, "command is required")
+
+	assert.ErrorContains(t, m.SendKeys(ctx, "ok", "", []string{"C-c; kill-server"}, false), "invalid key name")
+	assert.ErrorContains(t, m.SendKeys(ctx, "ok", "", nil, false), "nothing to send")
+}
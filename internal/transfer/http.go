@@ -0,0 +1,251 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/outbound"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// redacted replaces secret header values echoed in responses.
+const redacted = "[REDACTED]"
+
+// HTTPRequest describes an HTTP request to an allowed host.
+type HTTPRequest struct {
+	Method  string // Defaults to GET
+	URL     string
+	Headers map[string]string
+	Body    string
+}
+
+// HTTPResponse is the response to an HTTP request.
+type HTTPResponse struct {
+	URL        string            `json:"url"` // Final URL after redirects
+	Status     string            `json:"status"`
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers"`
+	Body       string            `json:"body"`
+	Encoding   string            `json:"encoding"` // utf-8, or base64 for binary data
+	Size       int64             `json:"size"`     // Bytes of body returned
+	Truncated  bool              `json:"truncated,omitempty"`
+	Duration   time.Duration     `json:"duration_ms"`
+}
+
+// newHTTPClient returns the client of the http_request tool, which always
+// verifies certificates and checks redirects like the first request.
+func (t *Transfer) newHTTPClient(transport http.RoundTripper) *http.Client {
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return apperrors.ValidationError("too many redirects", "url")
+			}
+			if err := t.checkHTTPURL(req.URL); err != nil {
+				return err
+			}
+			// Secret headers are copied to the redirect; only keep
+			// those meant for its host
+			t.setSecretHeaders(req)
+			return nil
+		},
+	}
+}
+
+// transport returns the transport of the configured network settings with
+// retries, or the default transport if the settings cannot be applied.
+func (t *Transfer) transport() http.RoundTripper {
+	transport, err := outbound.Transport(t.config.Network)
+	if err != nil {
+		t.logger.WithError(err).Warn("downloads and HTTP requests ignore the network settings")
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return outbound.WithRetries(t.config.Network, transport)
+}
+
+// Request sends an HTTP request to an allowed host, adding the configured
+// secret headers, and returns the response with its body truncated to the
+// configured size. Secret values echoed in the response are redacted.
+func (t *Transfer) Request(ctx context.Context, req HTTPRequest) (*HTTPResponse, error) {
+	cfg := t.config.HTTP
+
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	if !slices.Contains(cfg.AllowedMethods, method) {
+		return nil, apperrors.PermissionError("method not allowed: "+method, req.URL)
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, apperrors.ValidationError("invalid url: "+err.Error(), "url")
+	}
+	if err := t.checkHTTPURL(u); err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxRequestSize > 0 && int64(len(req.Body)) > int64(cfg.MaxRequestSize) {
+		return nil, apperrors.ValidationError(
+			fmt.Sprintf("body exceeds the maximum of %d bytes", cfg.MaxRequestSize), "body")
+	}
+
+	timeout := 30 * time.Second
+	if cfg.Timeout > 0 {
+		timeout = cfg.Timeout.Std()
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var body io.Reader
+	if req.Body != "" {
+		body = strings.NewReader(req.Body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeValidation, "invalid request")
+	}
+	for name, value := range req.Headers {
+		if t.isSecretHeader(name) {
+			return nil, apperrors.ValidationError("header is set by the server: "+name, "headers")
+		}
+		if strings.ContainsAny(name+value, "\r\n") {
+			return nil, apperrors.ValidationError("header contains a line break: "+name, "headers")
+		}
+		httpReq.Header.Set(name, value)
+	}
+	t.setSecretHeaders(httpReq)
+
+	start := time.Now()
+	resp, err := t.httpClient.Do(httpReq)
+	if err != nil {
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, apperrors.TimeoutError("request timed out", timeout.String())
+		}
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "request failed")
+	}
+	defer resp.Body.Close()
+
+	limit := int64(cfg.MaxResponseSize)
+	if limit <= 0 {
+		limit = 1 << 62
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to read response")
+	}
+	result := &HTTPResponse{
+		URL:        resp.Request.URL.String(),
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Headers:    make(map[string]string, len(resp.Header)),
+		Encoding:   EncodingText,
+		Duration:   time.Since(start),
+	}
+	if int64(len(data)) > limit {
+		data, result.Truncated = data[:limit], true
+	}
+
+	secrets := t.secretValues()
+	for name, values := range resp.Header {
+		result.Headers[name] = redact(strings.Join(values, ", "), secrets)
+	}
+	if utf8.Valid(data) {
+		result.Body = redact(string(data), secrets)
+	} else {
+		for _, secret := range secrets {
+			data = bytes.ReplaceAll(data, []byte(secret), []byte(redacted))
+		}
+		result.Body = base64.StdEncoding.EncodeToString(data)
+		result.Encoding = EncodingBase64
+	}
+	result.Size = int64(len(data))
+
+	t.logger.Info("HTTP request", "method", method, "url", u.Redacted(), "status", resp.StatusCode, "size", result.Size)
+
+	return result, nil
+}
+
+// checkHTTPURL validates a URL of the http_request tool against its
+// allowed hosts, which deny every host when empty.
+func (t *Transfer) checkHTTPURL(u *url.URL) error {
+	cfg := t.config.HTTP
+	if u.Scheme != "https" && (u.Scheme != "http" || !cfg.AllowHTTP) {
+		return apperrors.PermissionError("url scheme not allowed: "+u.Scheme, u.Redacted())
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return apperrors.ValidationError("url has no host", "url")
+	}
+	if !hostAllowed(cfg.AllowedHosts, host) {
+		return apperrors.PermissionError("host not allowed: "+host, u.Redacted())
+	}
+	return nil
+}
+
+// setSecretHeaders sets the secret headers meant for the request's host
+// and removes the others. They are only sent over https.
+func (t *Transfer) setSecretHeaders(req *http.Request) {
+	host := strings.ToLower(req.URL.Hostname())
+	for _, secret := range t.config.HTTP.SecretHeaders {
+		req.Header.Del(secret.Header)
+	}
+	if req.URL.Scheme != "https" {
+		return
+	}
+	for _, secret := range t.config.HTTP.SecretHeaders {
+		value := os.Getenv(secret.Env)
+		if value == "" || !hostAllowed(secret.Hosts, host) {
+			continue
+		}
+		req.Header.Set(secret.Header, secret.Prefix+value)
+	}
+}
+
+// isSecretHeader reports whether callers may not set a header.
+func (t *Transfer) isSecretHeader(name string) bool {
+	for _, secret := range t.config.HTTP.SecretHeaders {
+		if strings.EqualFold(secret.Header, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretValues returns the values of the secret headers, longest first.
+func (t *Transfer) secretValues() []string {
+	var values []string
+	for _, secret := range t.config.HTTP.SecretHeaders {
+		if value := os.Getenv(secret.Env); len(value) >= 4 {
+			values = append(values, value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	return values
+}
+
+// redact replaces secret values in s.
+func redact(s string, secrets []string) string {
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
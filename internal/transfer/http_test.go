@@ -0,0 +1,140 @@
+package transfer
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// echoServer answers with the request's method, Authorization header and
+// body.
+func echoServer(t *testing.T, tls bool) *httptest.Server {
+	t.Helper()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Auth", r.Header.Get("Authorization"))
+		w.Write([]byte(r.Method + " " + r.Header.Get("Authorization") + " " + string(body)))
+	})
+	srv := httptest.NewUnstartedServer(handler)
+	if tls {
+		srv.StartTLS()
+	} else {
+		srv.Start()
+	}
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func httpTransfer(t *testing.T, srv *httptest.Server, modify func(cfg *config.HTTPConfig)) *Transfer {
+	t.Helper()
+	t.Setenv("TEST_API_TOKEN", "s3cret-token")
+	tr := testTransfer(func(cfg *config.Config) {
+		cfg.HTTP.Enabled = true
+		cfg.HTTP.AllowedHosts = []string{"127.0.0.1"}
+		cfg.HTTP.AllowedMethods = []string{"GET", "POST"}
+		cfg.HTTP.SecretHeaders = []config.SecretHeader{
+			{Hosts: []string{"127.0.0.1"}, Header: "Authorization", Env: "TEST_API_TOKEN", Prefix: "Bearer "},
+		}
+		if modify != nil {
+			modify(&cfg.HTTP)
+		}
+	})
+	if srv.TLS != nil {
+		// Trust the test server's certificate, and nothing else
+		tr.httpClient.Transport.(*http.Transport).TLSClientConfig.RootCAs =
+			srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	}
+	return tr
+}
+
+func TestTransfer_Request(t *testing.T) {
+	srv := echoServer(t, true)
+	tr := httpTransfer(t, srv, nil)
+	ctx := context.Background()
+
+	resp, err := tr.Request(ctx, HTTPRequest{Method: "post", URL: srv.URL + "/api", Body: "data"})
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Body != "POST Bearer [REDACTED] data" {
+		t.Errorf("Request() = %d %q, want the secret header sent and redacted", resp.StatusCode, resp.Body)
+	}
+	if resp.Headers["X-Auth"] != "Bearer [REDACTED]" {
+		t.Errorf("X-Auth header = %q, want it redacted", resp.Headers["X-Auth"])
+	}
+
+	if _, err := tr.Request(ctx, HTTPRequest{URL: srv.URL, Headers: map[string]string{"authorization": "mine"}}); err == nil {
+		t.Error("Request() let the caller set a secret header")
+	}
+	if _, err := tr.Request(ctx, HTTPRequest{Method: "DELETE", URL: srv.URL}); err == nil || !strings.Contains(err.Error(), "method not allowed") {
+		t.Errorf("Request(DELETE) error = %v, want method not allowed", err)
+	}
+	if _, err := tr.Request(ctx, HTTPRequest{URL: strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)}); err == nil || !strings.Contains(err.Error(), "host not allowed") {
+		t.Errorf("Request(localhost) error = %v, want host not allowed", err)
+	}
+	if _, err := tr.Request(ctx, HTTPRequest{URL: "http://127.0.0.1/"}); err == nil || !strings.Contains(err.Error(), "scheme not allowed") {
+		t.Errorf("Request(http) error = %v, want scheme not allowed", err)
+	}
+}
+
+func TestTransfer_RequestLimits(t *testing.T) {
+	srv := echoServer(t, true)
+	tr := httpTransfer(t, srv, func(cfg *config.HTTPConfig) {
+		cfg.MaxRequestSize = 100
+		cfg.MaxResponseSize = 10
+	})
+	ctx := context.Background()
+
+	resp, err := tr.Request(ctx, HTTPRequest{Method: "POST", URL: srv.URL, Body: "data"})
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if !resp.Truncated || resp.Size != 10 {
+		t.Errorf("Request() = %+v, want 10 bytes and truncated", resp)
+	}
+
+	if _, err := tr.Request(ctx, HTTPRequest{Method: "POST", URL: srv.URL, Body: strings.Repeat("x", 101)}); err == nil {
+		t.Error("Request() accepted a body over the limit")
+	}
+
+	// Certificates the system does not trust are rejected
+	untrusted := httpTransfer(t, srv, nil)
+	untrusted.httpClient = untrusted.newHTTPClient(untrusted.transport())
+	if _, err := untrusted.Request(ctx, HTTPRequest{URL: srv.URL}); err == nil {
+		t.Error("Request() accepted an untrusted certificate")
+	}
+
+	// network.ca_bundle adds certificate authorities to the system ones
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(bundle, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	untrusted.config.Network.CABundle = bundle
+	untrusted.httpClient = untrusted.newHTTPClient(untrusted.transport())
+	if _, err := untrusted.Request(ctx, HTTPRequest{URL: srv.URL}); err != nil {
+		t.Errorf("Request() error = %v with the certificate in ca_bundle", err)
+	}
+}
+
+func TestTransfer_RequestPlainHTTP(t *testing.T) {
+	srv := echoServer(t, false)
+	tr := httpTransfer(t, srv, func(cfg *config.HTTPConfig) { cfg.AllowHTTP = true })
+
+	resp, err := tr.Request(context.Background(), HTTPRequest{URL: srv.URL})
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	// Secret headers are never sent in clear text
+	if resp.Body != "GET  " {
+		t.Errorf("Request() = %q, want no Authorization header", resp.Body)
+	}
+}
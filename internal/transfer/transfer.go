@@ -0,0 +1,365 @@
+// Package transfer downloads and reads files without external tools
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/internal/outbound"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+const (
+	// maxRedirects limits the redirects followed by a download.
+	maxRedirects = 10
+
+	// Chunk encodings.
+	EncodingText   = "utf-8"
+	EncodingBase64 = "base64"
+)
+
+// DownloadRequest describes a file to download.
+type DownloadRequest struct {
+	URL       string
+	Path      string // Absolute destination path
+	SHA256    string // Optional expected hex digest
+	Overwrite bool
+}
+
+// DownloadResult describes a completed download.
+type DownloadResult struct {
+	URL         string        `json:"url"` // Final URL after redirects
+	Path        string        `json:"path"`
+	Size        int64         `json:"size"`
+	SHA256      string        `json:"sha256"`
+	ContentType string        `json:"content_type,omitempty"`
+	Duration    time.Duration `json:"duration_ms"`
+}
+
+// ChunkRequest describes a range of a file to read.
+type ChunkRequest struct {
+	Path     string
+	Offset   int64
+	Length   int64 // Defaults to the maximum chunk size
+	Checksum bool  // Also compute the SHA-256 of the whole file
+}
+
+// Chunk is a range of a file.
+type Chunk struct {
+	Path     string `json:"path"`
+	Offset   int64  `json:"offset"`
+	Length   int64  `json:"length"`
+	Size     int64  `json:"size"` // Size of the whole file
+	EOF      bool   `json:"eof"`
+	Encoding string `json:"encoding"` // utf-8, or base64 for binary data
+	Data     string `json:"data"`
+	SHA256   string `json:"sha256,omitempty"` // Digest of the whole file
+}
+
+// Transfer downloads and reads files within the configured limits.
+type Transfer struct {
+	config     *config.Config
+	logger     *logger.Logger
+	client     *http.Client
+	httpClient *http.Client // Client of the http_request tool
+}
+
+// New creates a transfer instance.
+func New(cfg *config.Config, log *logger.Logger) *Transfer {
+	t := &Transfer{
+		config: cfg,
+		logger: log,
+	}
+
+	transport := t.transport()
+	t.client = &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return apperrors.ValidationError("too many redirects", "url")
+			}
+			return t.checkURL(req.URL)
+		},
+	}
+	t.httpClient = t.newHTTPClient(transport)
+
+	return t
+}
+
+// Download fetches a URL to a local path, enforcing the size limit and
+// verifying the checksum before the file is moved into place.
+func (t *Transfer) Download(ctx context.Context, req DownloadRequest) (*DownloadResult, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, apperrors.ValidationError("invalid url: "+err.Error(), "url")
+	}
+	if err := t.checkURL(u); err != nil {
+		return nil, err
+	}
+
+	if err := t.checkPath(req.Path, true); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(req.Path); err == nil && !req.Overwrite {
+		return nil, apperrors.ValidationError("destination exists (set overwrite to replace it)", "path")
+	}
+
+	expected := strings.ToLower(strings.TrimPrefix(req.SHA256, "sha256:"))
+	if expected != "" {
+		if b, err := hex.DecodeString(expected); err != nil || len(b) != sha256.Size {
+			return nil, apperrors.ValidationError("sha256 must be a 64 character hex digest", "sha256")
+		}
+	}
+
+	timeout := outbound.Timeout(t.config.Network, 5*time.Minute)
+	if t.config.Transfer.Timeout > 0 {
+		timeout = t.config.Transfer.Timeout.Std()
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeValidation, "invalid request")
+	}
+
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, apperrors.TimeoutError("download timed out", timeout.String())
+		}
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeExecution, "download failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, apperrors.New(apperrors.ErrorTypeExecution, "download failed: "+resp.Status)
+	}
+
+	maxSize := int64(t.config.Transfer.MaxDownloadSize)
+	if maxSize > 0 && resp.ContentLength > maxSize {
+		return nil, apperrors.ValidationError(
+			fmt.Sprintf("file size %d exceeds the maximum of %d bytes", resp.ContentLength, maxSize), "url")
+	}
+
+	size, digest, err := t.save(resp.Body, req.Path, maxSize, expected)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DownloadResult{
+		URL:         resp.Request.URL.String(),
+		Path:        req.Path,
+		Size:        size,
+		SHA256:      digest,
+		ContentType: resp.Header.Get("Content-Type"),
+		Duration:    time.Since(start),
+	}
+
+	t.logger.Info("downloaded file", "url", result.URL, "path", result.Path, "size", result.Size)
+
+	return result, nil
+}
+
+// save writes body to a temporary file next to path and renames it into
+// place once the size and checksum are verified.
+func (t *Transfer) save(body io.Reader, path string, maxSize int64, expected string) (int64, string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".download-*")
+	if err != nil {
+		return 0, "", apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to create file")
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if maxSize > 0 {
+		body = io.LimitReader(body, maxSize+1)
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, "", apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to write download")
+	}
+
+	if maxSize > 0 && size > maxSize {
+		return 0, "", apperrors.ValidationError(
+			fmt.Sprintf("file exceeds the maximum of %d bytes", maxSize), "url")
+	}
+
+	digest := hex.EncodeToString(hash.Sum(nil))
+	if expected != "" && digest != expected {
+		return 0, "", apperrors.ValidationError(
+			fmt.Sprintf("checksum mismatch: expected %s, got %s", expected, digest), "sha256")
+	}
+
+	if err := os.Chmod(tmpName, 0o644); err != nil {
+		return 0, "", apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to set file mode")
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return 0, "", apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to move download into place")
+	}
+
+	return size, digest, nil
+}
+
+// ReadChunk reads a range of a file. Text is returned as is; data that is
+// not valid UTF-8 is base64 encoded.
+func (t *Transfer) ReadChunk(req ChunkRequest) (*Chunk, error) {
+	if err := t.checkPath(req.Path, false); err != nil {
+		return nil, err
+	}
+	if req.Offset < 0 {
+		return nil, apperrors.ValidationError("offset cannot be negative", "offset")
+	}
+
+	f, err := os.Open(req.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, apperrors.NotFoundError("file not found", req.Path)
+		}
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypePermission, "failed to open file")
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to stat file")
+	}
+	if !info.Mode().IsRegular() {
+		return nil, apperrors.ValidationError("not a regular file", "path")
+	}
+	if req.Offset > info.Size() {
+		return nil, apperrors.ValidationError(
+			fmt.Sprintf("offset %d is beyond the end of the file (%d bytes)", req.Offset, info.Size()), "offset")
+	}
+
+	length := req.Length
+	if maxChunk := int64(t.config.Transfer.MaxChunkSize); maxChunk > 0 && (length <= 0 || length > maxChunk) {
+		length = maxChunk
+	}
+	if remaining := info.Size() - req.Offset; length <= 0 || length > remaining {
+		length = remaining
+	}
+
+	data := make([]byte, length)
+	n, err := f.ReadAt(data, req.Offset)
+	if err != nil && err != io.EOF {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read file")
+	}
+	data = data[:n]
+
+	chunk := &Chunk{
+		Path:     req.Path,
+		Offset:   req.Offset,
+		Length:   int64(n),
+		Size:     info.Size(),
+		EOF:      req.Offset+int64(n) >= info.Size(),
+		Encoding: EncodingText,
+		Data:     string(data),
+	}
+	if !utf8.Valid(data) {
+		chunk.Encoding = EncodingBase64
+		chunk.Data = base64.StdEncoding.EncodeToString(data)
+	}
+
+	if req.Checksum {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read file")
+		}
+		hash := sha256.New()
+		if _, err := io.Copy(hash, f); err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read file")
+		}
+		chunk.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return chunk, nil
+}
+
+// checkURL validates a URL against the allowed schemes and hosts.
+func (t *Transfer) checkURL(u *url.URL) error {
+	schemes := t.config.Transfer.AllowedSchemes
+	if len(schemes) == 0 {
+		schemes = []string{"https"}
+	}
+	allowed := false
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return apperrors.PermissionError("url scheme not allowed: "+u.Scheme, u.String())
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return apperrors.ValidationError("url has no host", "url")
+	}
+	if !hostAllowed(t.config.Transfer.AllowedHosts, host) {
+		return apperrors.PermissionError("host not allowed: "+host, u.String())
+	}
+
+	return nil
+}
+
+// hostAllowed reports whether host matches the allowlist. Entries starting
+// with "*." match any subdomain; an empty allowlist matches no host.
+func hostAllowed(allowed []string, host string) bool {
+	for _, entry := range allowed {
+		entry = strings.ToLower(entry)
+		if suffix, ok := strings.CutPrefix(entry, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPath validates a local path against the security settings. Paths
+// written to are checked like every file change (see
+// config.CheckWritePath).
+func (t *Transfer) checkPath(path string, write bool) error {
+	if path == "" {
+		return apperrors.ValidationError("path is required", "path")
+	}
+	if !filepath.IsAbs(path) {
+		return apperrors.ValidationError("path must be absolute", "path")
+	}
+	if write {
+		return t.config.CheckWritePath(path)
+	}
+	if !t.config.IsPathAllowed(path) {
+		return apperrors.PermissionError("path not allowed: "+path, path)
+	}
+	return nil
+}
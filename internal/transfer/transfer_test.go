@@ -0,0 +1,245 @@
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+const payload = "hello, transfer\n"
+
+func testServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/file", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(payload))
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/file", http.StatusFound)
+	})
+	mux.HandleFunc("/external", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://example.com/file", http.StatusFound)
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testTransfer(modify func(cfg *config.Config)) *Transfer {
+	cfg := config.Default()
+	cfg.Transfer.AllowedSchemes = []string{"http"}
+	cfg.Transfer.AllowedHosts = []string{"127.0.0.1"}
+	if modify != nil {
+		modify(cfg)
+	}
+	return New(cfg, logger.Default())
+}
+
+func digest(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestTransfer_Download(t *testing.T) {
+	srv := testServer(t)
+	dir := t.TempDir()
+	tr := testTransfer(func(cfg *config.Config) { cfg.Security.AllowedPaths = []string{dir} })
+
+	dest := filepath.Join(dir, "file.txt")
+	result, err := tr.Download(context.Background(), DownloadRequest{
+		URL:    srv.URL + "/redirect",
+		Path:   dest,
+		SHA256: "sha256:" + digest(payload),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Size != int64(len(payload)) || result.SHA256 != digest(payload) {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.URL != srv.URL+"/file" {
+		t.Errorf("expected final url %s/file, got %s", srv.URL, result.URL)
+	}
+
+	data, err := os.ReadFile(dest)
+	if err != nil || string(data) != payload {
+		t.Errorf("unexpected file contents %q (%v)", data, err)
+	}
+
+	if _, err := tr.Download(context.Background(), DownloadRequest{URL: srv.URL + "/file", Path: dest}); err == nil {
+		t.Error("expected error when destination exists")
+	}
+	if _, err := tr.Download(context.Background(), DownloadRequest{URL: srv.URL + "/file", Path: dest, Overwrite: true}); err != nil {
+		t.Errorf("unexpected error overwriting: %v", err)
+	}
+}
+
+func TestTransfer_DownloadErrors(t *testing.T) {
+	srv := testServer(t)
+	dir := t.TempDir()
+
+	tests := []struct {
+		name   string
+		modify func(cfg *config.Config)
+		req    DownloadRequest
+	}{
+		{
+			name: "checksum mismatch",
+			req:  DownloadRequest{URL: srv.URL + "/file", SHA256: digest("other")},
+		},
+		{
+			name: "invalid checksum",
+			req:  DownloadRequest{URL: srv.URL + "/file", SHA256: "abc"},
+		},
+		{
+			name:   "too large",
+			modify: func(cfg *config.Config) { cfg.Transfer.MaxDownloadSize = 4 },
+			req:    DownloadRequest{URL: srv.URL + "/file"},
+		},
+		{
+			name:   "scheme not allowed",
+			modify: func(cfg *config.Config) { cfg.Transfer.AllowedSchemes = []string{"https"} },
+			req:    DownloadRequest{URL: srv.URL + "/file"},
+		},
+		{
+			name:   "host not allowed",
+			modify: func(cfg *config.Config) { cfg.Transfer.AllowedHosts = []string{"*.example.com"} },
+			req:    DownloadRequest{URL: srv.URL + "/file"},
+		},
+		{
+			name: "redirect to disallowed host",
+			req:  DownloadRequest{URL: srv.URL + "/external"},
+		},
+		{
+			name: "http error",
+			req:  DownloadRequest{URL: srv.URL + "/missing"},
+		},
+		{
+			name:   "path not allowed",
+			modify: func(cfg *config.Config) { cfg.Security.AllowedPaths = []string{"/nonexistent"} },
+			req:    DownloadRequest{URL: srv.URL + "/file"},
+		},
+		{
+			name:   "no allowed paths",
+			modify: func(cfg *config.Config) { cfg.Security.AllowedPaths = nil },
+			req:    DownloadRequest{URL: srv.URL + "/file"},
+		},
+		{
+			name:   "no allowed hosts",
+			modify: func(cfg *config.Config) { cfg.Transfer.AllowedHosts = nil },
+			req:    DownloadRequest{URL: srv.URL + "/file"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := testTransfer(func(cfg *config.Config) {
+				cfg.Security.AllowedPaths = []string{dir}
+				if tt.modify != nil {
+					tt.modify(cfg)
+				}
+			})
+			tt.req.Path = filepath.Join(dir, "out")
+
+			if _, err := tr.Download(context.Background(), tt.req); err == nil {
+				t.Fatal("expected error")
+			}
+			if _, err := os.Stat(tt.req.Path); !os.IsNotExist(err) {
+				t.Error("expected no file to be written")
+			}
+		})
+	}
+
+	if _, err := testTransfer(nil).Download(context.Background(), DownloadRequest{URL: srv.URL + "/file", Path: "relative"}); err == nil {
+		t.Error("expected error for relative path")
+	}
+}
+
+func TestTransfer_ReadChunk(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "text.txt")
+	binary := filepath.Join(dir, "data.bin")
+	if err := os.WriteFile(text, []byte(payload), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(binary, []byte{0xff, 0xfe, 0x00, 0x01}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := testTransfer(func(cfg *config.Config) { cfg.Transfer.MaxChunkSize = 8 })
+
+	chunk, err := tr.ReadChunk(ChunkRequest{Path: text})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if chunk.Data != payload[:8] || chunk.EOF || chunk.Size != int64(len(payload)) {
+		t.Errorf("unexpected first chunk: %+v", chunk)
+	}
+
+	chunk, err = tr.ReadChunk(ChunkRequest{Path: text, Offset: 8, Length: 100, Checksum: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if chunk.Data != payload[8:] || !chunk.EOF {
+		t.Errorf("unexpected last chunk: %+v", chunk)
+	}
+	if chunk.SHA256 != digest(payload) {
+		t.Errorf("expected file digest %s, got %s", digest(payload), chunk.SHA256)
+	}
+
+	chunk, err = tr.ReadChunk(ChunkRequest{Path: binary})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if chunk.Encoding != EncodingBase64 || chunk.Data != base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe, 0x00, 0x01}) {
+		t.Errorf("unexpected binary chunk: %+v", chunk)
+	}
+
+	errorCases := []ChunkRequest{
+		{Path: text, Offset: -1},
+		{Path: text, Offset: 1000},
+		{Path: filepath.Join(dir, "missing")},
+		{Path: dir},
+		{Path: "text.txt"},
+	}
+	for _, req := range errorCases {
+		if _, err := tr.ReadChunk(req); err == nil {
+			t.Errorf("expected error for %+v", req)
+		}
+	}
+}
+
+func TestHostAllowed(t *testing.T) {
+	tests := []struct {
+		allowed []string
+		host    string
+		want    bool
+	}{
+		{nil, "example.com", false},
+		{[]string{"example.com"}, "example.com", true},
+		{[]string{"Example.com"}, "example.com", true},
+		{[]string{"example.com"}, "sub.example.com", false},
+		{[]string{"*.example.com"}, "sub.example.com", true},
+		{[]string{"*.example.com"}, "example.com", false},
+		{[]string{"*.example.com"}, "badexample.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := hostAllowed(tt.allowed, tt.host); got != tt.want {
+			t.Errorf("hostAllowed(%v, %q) = %v, want %v", tt.allowed, tt.host, got, tt.want)
+		}
+	}
+}
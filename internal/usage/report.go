@@ -0,0 +1,139 @@
+package usage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+)
+
+// Report summarizes usage counts, most used first.
+type Report struct {
+	Since   time.Time `json:"since"`
+	Updated time.Time `json:"updated"`
+
+	Tools     []ToolReport     `json:"tools"`
+	Resources []ResourceReport `json:"resources,omitempty"`
+	Commands  []CommandReport  `json:"commands"`
+
+	// Unused lists the configured commands no call was recorded of, which
+	// are candidates for removal
+	Unused []string `json:"unused,omitempty"`
+
+	Denials     []ReasonReport `json:"denials,omitempty"`
+	Validations []ReasonReport `json:"validation_failures,omitempty"`
+}
+
+// ToolReport is the usage of a tool.
+type ToolReport struct {
+	Tool string `json:"tool"`
+	ToolStats
+	AverageDuration time.Duration `json:"average_duration"`
+	AverageBytes    int64         `json:"average_bytes"`
+}
+
+// ResourceReport is the usage of a resource.
+type ResourceReport struct {
+	URI string `json:"uri"`
+	ResourceStats
+}
+
+// CommandReport is the usage of a command.
+type CommandReport struct {
+	Command string `json:"command"`
+	CommandStats
+}
+
+// ReasonReport is a rejection reason with the commands rejected for it,
+// most rejected first.
+type ReasonReport struct {
+	Reason   string         `json:"reason"`
+	Count    int64          `json:"count"`
+	Commands []CommandCount `json:"commands"`
+}
+
+// CommandCount is how often a command was rejected for a reason.
+type CommandCount struct {
+	Command string `json:"command"`
+	Count   int64  `json:"count"`
+}
+
+// Summarize builds the report of stats. Configured commands of cfg that
+// were never called are listed as unused.
+func Summarize(stats *Stats, cfg *config.Config) *Report {
+	r := &Report{Since: stats.Since, Updated: stats.Updated}
+
+	for name, t := range stats.Tools {
+		tr := ToolReport{Tool: name, ToolStats: *t}
+		if t.Calls > 0 {
+			tr.AverageDuration = t.Duration / time.Duration(t.Calls)
+			tr.AverageBytes = t.Bytes / t.Calls
+		}
+		r.Tools = append(r.Tools, tr)
+	}
+	sort.Slice(r.Tools, func(i, j int) bool {
+		a, b := r.Tools[i], r.Tools[j]
+		if a.Calls != b.Calls {
+			return a.Calls > b.Calls
+		}
+		return a.Tool < b.Tool
+	})
+
+	for uri, res := range stats.Resources {
+		r.Resources = append(r.Resources, ResourceReport{URI: uri, ResourceStats: *res})
+	}
+	sort.Slice(r.Resources, func(i, j int) bool {
+		a, b := r.Resources[i], r.Resources[j]
+		if a.Reads != b.Reads {
+			return a.Reads > b.Reads
+		}
+		return a.URI < b.URI
+	})
+
+	for name, c := range stats.Commands {
+		r.Commands = append(r.Commands, CommandReport{Command: name, CommandStats: *c})
+	}
+	sort.Slice(r.Commands, func(i, j int) bool {
+		a, b := r.Commands[i], r.Commands[j]
+		if a.Runs != b.Runs {
+			return a.Runs > b.Runs
+		}
+		return a.Command < b.Command
+	})
+
+	for _, cmd := range cfg.Commands {
+		if t := stats.Tools[cmd.Name]; t == nil || t.Calls == 0 {
+			r.Unused = append(r.Unused, cmd.Name)
+		}
+	}
+
+	r.Denials = reasons(stats.Denials)
+	r.Validations = reasons(stats.Validations)
+	return r
+}
+
+// reasons sorts rejection reasons and their commands, most frequent first.
+func reasons(m map[string]*ReasonStats) []ReasonReport {
+	var out []ReasonReport
+	for reason, rs := range m {
+		rr := ReasonReport{Reason: reason, Count: rs.Count}
+		for command, n := range rs.Commands {
+			rr.Commands = append(rr.Commands, CommandCount{Command: command, Count: n})
+		}
+		sort.Slice(rr.Commands, func(i, j int) bool {
+			a, b := rr.Commands[i], rr.Commands[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return a.Command < b.Command
+		})
+		out = append(out, rr)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Reason < out[j].Reason
+	})
+	return out
+}
@@ -0,0 +1,388 @@
+// Package usage counts tool calls, resource reads, command runs,
+// validation failures and policy denials, and the bytes returned to
+// clients, so operators can prune unused tools, fix frequently denied
+// patterns and find the tools flooding slow links.
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// flushInterval is how often counts are added to the usage file.
+const flushInterval = 30 * time.Second
+
+// Stats are the usage counts kept in the usage file.
+type Stats struct {
+	Since   time.Time `json:"since"`
+	Updated time.Time `json:"updated"`
+
+	Tools     map[string]*ToolStats     `json:"tools,omitempty"`
+	Resources map[string]*ResourceStats `json:"resources,omitempty"`
+	Commands  map[string]*CommandStats  `json:"commands,omitempty"`
+
+	// Denials and validation failures by reason, such as "command not
+	// allowed" or "path denied"
+	Denials     map[string]*ReasonStats `json:"denials,omitempty"`
+	Validations map[string]*ReasonStats `json:"validation_failures,omitempty"`
+}
+
+// ToolStats counts the calls of a tool.
+type ToolStats struct {
+	Calls    int64         `json:"calls"`
+	Failures int64         `json:"failures"` // Calls returning an error or an error result
+	Duration time.Duration `json:"duration"` // Total time spent in calls
+	Bytes    int64         `json:"bytes"`    // Total size of the results and notifications returned
+	LastCall time.Time     `json:"last_call"`
+}
+
+// ResourceStats counts the reads of a resource.
+type ResourceStats struct {
+	Reads    int64     `json:"reads"`
+	Bytes    int64     `json:"bytes"` // Total size of the contents returned
+	LastRead time.Time `json:"last_read"`
+}
+
+// CommandStats counts the runs requested of a command.
+type CommandStats struct {
+	Runs               int64     `json:"runs"`
+	Failures           int64     `json:"failures"` // Admitted runs that failed or exited non-zero
+	ValidationFailures int64     `json:"validation_failures"`
+	Denials            int64     `json:"denials"`
+	LastRun            time.Time `json:"last_run"`
+}
+
+// ReasonStats counts the requests rejected for a reason, by command.
+type ReasonStats struct {
+	Count    int64            `json:"count"`
+	Commands map[string]int64 `json:"commands,omitempty"`
+}
+
+// File returns the file usage counts are kept in.
+func File(cfg *config.Config) string {
+	if cfg.Usage.File != "" {
+		return cfg.Usage.File
+	}
+	return filepath.Join(config.StateDir(), "usage.json")
+}
+
+// Load reads the usage file. A missing file has no counts.
+func Load(path string) (*Stats, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Stats{}, nil
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to read usage file")
+	}
+	var s Stats
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to parse usage file")
+	}
+	return &s, nil
+}
+
+// Recorder counts usage in memory and periodically adds the counts to the
+// usage file, so counts survive restarts and accumulate across servers
+// sharing the file.
+type Recorder struct {
+	path string
+
+	mu      sync.Mutex
+	pending *Stats
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewRecorder returns a recorder adding to the usage file at path. An
+// empty path disables recording.
+func NewRecorder(path string) *Recorder {
+	r := &Recorder{path: path}
+	if path == "" {
+		return r
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.run()
+	return r
+}
+
+// run flushes the counts until the recorder is closed.
+func (r *Recorder) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = r.Flush()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// ToolCall counts a call of tool returning a result of size bytes.
+func (r *Recorder) ToolCall(tool string, d time.Duration, failed bool, size int64) {
+	if r.path == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := r.stats().tool(tool)
+	t.Calls++
+	t.Duration += d
+	t.Bytes += size
+	t.LastCall = time.Now().UTC()
+	if failed {
+		t.Failures++
+	}
+}
+
+// ToolOutput counts size bytes a tool sent to the client outside its
+// result, such as followed lines in log notifications.
+func (r *Recorder) ToolOutput(tool string, size int64) {
+	if r.path == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stats().tool(tool).Bytes += size
+}
+
+// ResourceRead counts a read of the resource at uri returning size bytes.
+func (r *Recorder) ResourceRead(uri string, size int64) {
+	if r.path == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := r.stats().resource(uri)
+	res.Reads++
+	res.Bytes += size
+	res.LastRead = time.Now().UTC()
+}
+
+// Run counts a requested run of command. err tells whether the request
+// was denied, failed validation (including naming a missing workdir or
+// command), or failed to run; failed whether the command exited non-zero.
+func (r *Recorder) Run(command string, err error, failed bool) {
+	if r.path == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.stats()
+	c := s.command(command)
+	c.Runs++
+	c.LastRun = time.Now().UTC()
+
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		switch appErr.Type {
+		case apperrors.ErrorTypePermission:
+			c.Denials++
+			s.Denials = addReason(s.Denials, Reason(appErr.Message), command)
+			return
+		case apperrors.ErrorTypeValidation, apperrors.ErrorTypeNotFound:
+			c.ValidationFailures++
+			s.Validations = addReason(s.Validations, Reason(appErr.Message), command)
+			return
+		}
+	}
+	if failed || err != nil {
+		c.Failures++
+	}
+}
+
+// Flush adds the counts recorded since the last flush to the usage file.
+func (r *Recorder) Flush() error {
+	if r.path == "" {
+		return nil
+	}
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+	if pending == nil {
+		return nil
+	}
+
+	stats, err := Load(r.path)
+	if err == nil {
+		stats.add(pending)
+		err = write(r.path, stats)
+	}
+	if err != nil {
+		// Keep the counts for the next flush
+		r.mu.Lock()
+		r.stats().add(pending)
+		r.mu.Unlock()
+	}
+	return err
+}
+
+// Close stops the periodic flush and flushes the remaining counts.
+func (r *Recorder) Close() error {
+	if r.path == "" {
+		return nil
+	}
+	close(r.stop)
+	<-r.done
+	return r.Flush()
+}
+
+// stats returns the counts pending a flush.
+func (r *Recorder) stats() *Stats {
+	if r.pending == nil {
+		now := time.Now().UTC()
+		r.pending = &Stats{Since: now, Updated: now}
+	}
+	r.pending.Updated = time.Now().UTC()
+	return r.pending
+}
+
+// Reason returns the reason of a rejection message, which is the message
+// up to the value it names, such as "command not allowed" for "command not
+// allowed: rm".
+func Reason(message string) string {
+	reason, _, _ := strings.Cut(message, ": ")
+	return reason
+}
+
+func (s *Stats) tool(name string) *ToolStats {
+	if s.Tools == nil {
+		s.Tools = make(map[string]*ToolStats)
+	}
+	t, ok := s.Tools[name]
+	if !ok {
+		t = &ToolStats{}
+		s.Tools[name] = t
+	}
+	return t
+}
+
+func (s *Stats) resource(uri string) *ResourceStats {
+	if s.Resources == nil {
+		s.Resources = make(map[string]*ResourceStats)
+	}
+	r, ok := s.Resources[uri]
+	if !ok {
+		r = &ResourceStats{}
+		s.Resources[uri] = r
+	}
+	return r
+}
+
+func (s *Stats) command(name string) *CommandStats {
+	if s.Commands == nil {
+		s.Commands = make(map[string]*CommandStats)
+	}
+	c, ok := s.Commands[name]
+	if !ok {
+		c = &CommandStats{}
+		s.Commands[name] = c
+	}
+	return c
+}
+
+func addReason(reasons map[string]*ReasonStats, reason, command string) map[string]*ReasonStats {
+	return addReasonCount(reasons, reason, map[string]int64{command: 1}, 1)
+}
+
+func addReasonCount(reasons map[string]*ReasonStats, reason string, commands map[string]int64, count int64) map[string]*ReasonStats {
+	if reasons == nil {
+		reasons = make(map[string]*ReasonStats)
+	}
+	rs, ok := reasons[reason]
+	if !ok {
+		rs = &ReasonStats{}
+		reasons[reason] = rs
+	}
+	if rs.Commands == nil {
+		rs.Commands = make(map[string]int64)
+	}
+	rs.Count += count
+	for command, n := range commands {
+		rs.Commands[command] += n
+	}
+	return reasons
+}
+
+// add adds the counts of other.
+func (s *Stats) add(other *Stats) {
+	if s.Since.IsZero() || (!other.Since.IsZero() && other.Since.Before(s.Since)) {
+		s.Since = other.Since
+	}
+	if other.Updated.After(s.Updated) {
+		s.Updated = other.Updated
+	}
+	for name, o := range other.Tools {
+		t := s.tool(name)
+		t.Calls += o.Calls
+		t.Failures += o.Failures
+		t.Duration += o.Duration
+		t.Bytes += o.Bytes
+		if o.LastCall.After(t.LastCall) {
+			t.LastCall = o.LastCall
+		}
+	}
+	for uri, o := range other.Resources {
+		r := s.resource(uri)
+		r.Reads += o.Reads
+		r.Bytes += o.Bytes
+		if o.LastRead.After(r.LastRead) {
+			r.LastRead = o.LastRead
+		}
+	}
+	for name, o := range other.Commands {
+		c := s.command(name)
+		c.Runs += o.Runs
+		c.Failures += o.Failures
+		c.ValidationFailures += o.ValidationFailures
+		c.Denials += o.Denials
+		if o.LastRun.After(c.LastRun) {
+			c.LastRun = o.LastRun
+		}
+	}
+	for reason, o := range other.Denials {
+		s.Denials = addReasonCount(s.Denials, reason, o.Commands, o.Count)
+	}
+	for reason, o := range other.Validations {
+		s.Validations = addReasonCount(s.Validations, reason, o.Commands, o.Count)
+	}
+}
+
+// write replaces the usage file with stats.
+func write(path string, stats *Stats) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to create usage directory")
+	}
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to encode usage")
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to write usage file")
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to write usage file")
+	}
+	return nil
+}
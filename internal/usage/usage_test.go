@@ -0,0 +1,113 @@
+package usage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+
+	// Counts of two runs accumulate in the file
+	for i := 0; i < 2; i++ {
+		r := NewRecorder(path)
+		r.ToolCall("execute_command", 10*time.Millisecond, false, 100)
+		r.ToolCall("execute_command", 30*time.Millisecond, true, 50)
+		r.ToolOutput("execute_command", 25)
+		r.ResourceRead("history://recent", 2048)
+		r.Run("ls", nil, false)
+		r.Run("make", nil, true)
+		r.Run("rm", apperrors.PermissionError("command not allowed: rm", "rm"), false)
+		r.Run("curl", apperrors.PermissionError("command not allowed: curl", "curl"), false)
+		r.Run("ls", apperrors.ValidationError("working directory does not exist: /nope", "workdir"), false)
+		r.Run("sleep", errors.New("context canceled"), false)
+		if err := r.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	stats, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	tool := stats.Tools["execute_command"]
+	if tool == nil || tool.Calls != 4 || tool.Failures != 2 || tool.Duration != 80*time.Millisecond || tool.Bytes != 350 {
+		t.Errorf("unexpected tool stats %+v", tool)
+	}
+	if res := stats.Resources["history://recent"]; res == nil || res.Reads != 2 || res.Bytes != 4096 || res.LastRead.IsZero() {
+		t.Errorf("unexpected resource stats %+v", res)
+	}
+	if ls := stats.Commands["ls"]; ls == nil || ls.Runs != 4 || ls.Failures != 0 || ls.ValidationFailures != 2 {
+		t.Errorf("unexpected ls stats %+v", ls)
+	}
+	if mk := stats.Commands["make"]; mk == nil || mk.Failures != 2 {
+		t.Errorf("unexpected make stats %+v", mk)
+	}
+	if sleep := stats.Commands["sleep"]; sleep == nil || sleep.Failures != 2 {
+		t.Errorf("unexpected sleep stats %+v", sleep)
+	}
+	denied := stats.Denials["command not allowed"]
+	if denied == nil || denied.Count != 4 || denied.Commands["rm"] != 2 || denied.Commands["curl"] != 2 {
+		t.Errorf("unexpected denial stats %+v", denied)
+	}
+	if invalid := stats.Validations["working directory does not exist"]; invalid == nil || invalid.Count != 2 {
+		t.Errorf("unexpected validation stats %+v", stats.Validations)
+	}
+}
+
+func TestRecorder_disabled(t *testing.T) {
+	r := NewRecorder("")
+	r.ToolCall("execute_command", time.Millisecond, false, 10)
+	r.ResourceRead("history://recent", 10)
+	r.Run("ls", nil, false)
+	if err := r.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	r := NewRecorder(path)
+	r.ToolCall("list_files", 2*time.Millisecond, false, 10)
+	r.ToolCall("execute_command", time.Millisecond, false, 100)
+	r.ToolCall("execute_command", 3*time.Millisecond, false, 300)
+	r.ResourceRead("config://current", 10)
+	r.ResourceRead("history://recent", 10)
+	r.ResourceRead("history://recent", 10)
+	r.Run("rm", apperrors.PermissionError("command not allowed: rm", "rm"), false)
+	r.Run("ls", apperrors.PermissionError("path denied: /etc", "/etc"), false)
+	r.Run("ls", apperrors.PermissionError("path denied: /root", "/root"), false)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Commands = []config.Command{
+		{Name: "list_files", Description: "List files", Command: "ls"},
+		{Name: "show_date", Description: "Show the date", Command: "date"},
+	}
+	report := Summarize(stats, cfg)
+
+	if len(report.Tools) != 2 || report.Tools[0].Tool != "execute_command" || report.Tools[0].AverageDuration != 2*time.Millisecond ||
+		report.Tools[0].AverageBytes != 200 {
+		t.Errorf("unexpected tools %+v", report.Tools)
+	}
+	if len(report.Resources) != 2 || report.Resources[0].URI != "history://recent" || report.Resources[0].Reads != 2 {
+		t.Errorf("unexpected resources %+v", report.Resources)
+	}
+	if len(report.Unused) != 1 || report.Unused[0] != "show_date" {
+		t.Errorf("Unused = %v, want [show_date]", report.Unused)
+	}
+	if len(report.Denials) != 2 || report.Denials[0].Reason != "path denied" || report.Denials[0].Count != 2 {
+		t.Errorf("unexpected denials %+v", report.Denials)
+	}
+}
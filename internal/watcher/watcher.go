@@ -0,0 +1,454 @@
+// Package watcher watches paths for changes and reacts to them
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mjmorales/simple-mcp-runner/internal/history"
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+const (
+	// maxRecentEvents is the number of events kept per watch for listing.
+	maxRecentEvents = 50
+
+	// defaultIgnore lists directory names never watched recursively.
+	defaultIgnore = ".git"
+)
+
+// Runner executes configured commands.
+type Runner interface {
+	ExecuteConfigCommand(ctx context.Context, cmd *config.Command, workDir string) (*types.CommandExecutionResult, error)
+}
+
+// NotifyFunc is called with every debounced batch of changes.
+type NotifyFunc func(ctx context.Context, change Change)
+
+// Spec describes a watch to create.
+type Spec struct {
+	Path      string
+	Recursive bool
+	Ignore    []string        // Glob patterns matched against base names
+	Command   *config.Command // Optional command to run on changes
+}
+
+// Event is a single filesystem change.
+type Event struct {
+	Path string    `json:"path"`
+	Op   string    `json:"op"`
+	Time time.Time `json:"time"`
+}
+
+// Change is a debounced batch of events and the command run it triggered.
+type Change struct {
+	WatchID   string                        `json:"watch_id"`
+	Events    []Event                       `json:"events"`
+	Triggered bool                          `json:"triggered"`
+	Skipped   string                        `json:"skipped,omitempty"` // Why the command did not run
+	Result    *types.CommandExecutionResult `json:"result,omitempty"`
+	Error     string                        `json:"error,omitempty"`
+}
+
+// Info describes an active watch.
+type Info struct {
+	ID           string    `json:"id"`
+	Path         string    `json:"path"`
+	Recursive    bool      `json:"recursive"`
+	Command      string    `json:"command,omitempty"`
+	Directories  int       `json:"directories"`
+	Triggers     int       `json:"triggers"`
+	CreatedAt    time.Time `json:"created_at"`
+	RecentEvents []Event   `json:"recent_events"`
+}
+
+// Manager owns all active watches.
+type Manager struct {
+	runner  Runner
+	history *history.Store
+	logger  *logger.Logger
+
+	debounce       time.Duration
+	maxTriggers    int
+	maxWatches     int
+	maxDirectories int
+
+	mu      sync.Mutex
+	watches map[string]*watch
+	seq     int
+}
+
+// watch is a single active watch.
+type watch struct {
+	id        string
+	spec      Spec
+	workDir   string
+	fsw       *fsnotify.Watcher
+	notify    NotifyFunc
+	cancel    context.CancelFunc
+	done      chan struct{}
+	createdAt time.Time
+
+	mu       sync.Mutex
+	dirs     int
+	recent   []Event
+	triggers []time.Time // Trigger times within the rate window
+	total    int
+}
+
+// NewManager creates a watch manager.
+func NewManager(cfg *config.Config, runner Runner, hist *history.Store, log *logger.Logger) *Manager {
+	debounce := 500 * time.Millisecond
+	if cfg.Watch.Debounce > 0 {
+		debounce = cfg.Watch.Debounce.Std()
+	}
+
+	maxWatches := cfg.Watch.MaxWatches
+	if maxWatches <= 0 {
+		maxWatches = 10
+	}
+
+	maxDirs := cfg.Watch.MaxDirectories
+	if maxDirs <= 0 {
+		maxDirs = 1000
+	}
+
+	return &Manager{
+		runner:         runner,
+		history:        hist,
+		logger:         log,
+		debounce:       debounce,
+		maxTriggers:    cfg.Watch.MaxTriggersPerMinute,
+		maxWatches:     maxWatches,
+		maxDirectories: maxDirs,
+		watches:        make(map[string]*watch),
+	}
+}
+
+// Add starts a new watch. notify may be nil.
+func (m *Manager) Add(spec Spec, notify NotifyFunc) (Info, error) {
+	info, err := os.Stat(spec.Path)
+	if err != nil {
+		return Info{}, apperrors.NotFoundError(fmt.Sprintf("watch path not found: %v", err), spec.Path)
+	}
+
+	for _, pattern := range spec.Ignore {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return Info{}, apperrors.ValidationError("invalid ignore pattern: "+pattern, "ignore")
+		}
+	}
+
+	m.mu.Lock()
+	if len(m.watches) >= m.maxWatches {
+		m.mu.Unlock()
+		return Info{}, apperrors.ValidationError(
+			fmt.Sprintf("too many active watches (max %d)", m.maxWatches),
+			"path",
+		)
+	}
+	m.seq++
+	id := fmt.Sprintf("w%d", m.seq)
+	m.mu.Unlock()
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return Info{}, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to create watcher")
+	}
+
+	w := &watch{
+		id:        id,
+		spec:      spec,
+		workDir:   spec.Path,
+		fsw:       fsw,
+		notify:    notify,
+		done:      make(chan struct{}),
+		createdAt: time.Now(),
+	}
+	if !info.IsDir() {
+		w.workDir = filepath.Dir(spec.Path)
+	}
+
+	if err := m.register(w, spec.Path, info.IsDir() && spec.Recursive); err != nil {
+		fsw.Close()
+		return Info{}, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	m.mu.Lock()
+	m.watches[id] = w
+	m.mu.Unlock()
+
+	go m.run(ctx, w)
+
+	m.logger.Info("watch started",
+		"id", id,
+		"path", spec.Path,
+		"recursive", spec.Recursive,
+		"directories", w.dirs,
+	)
+
+	return w.info(), nil
+}
+
+// register adds path to the watcher, walking subdirectories when recursive.
+func (m *Manager) register(w *watch, path string, recursive bool) error {
+	if !recursive {
+		return m.addDir(w, path)
+	}
+
+	return filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped rather than failing the watch
+			return filepath.SkipDir
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if p != path && w.ignored(p) {
+			return filepath.SkipDir
+		}
+		return m.addDir(w, p)
+	})
+}
+
+// addDir adds a single path to the watcher, enforcing the directory limit.
+func (m *Manager) addDir(w *watch, path string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.dirs >= m.maxDirectories {
+		return apperrors.ValidationError(
+			fmt.Sprintf("watch exceeds directory limit (max %d)", m.maxDirectories),
+			"path",
+		)
+	}
+	if err := w.fsw.Add(path); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to watch "+path)
+	}
+	w.dirs++
+	return nil
+}
+
+// Remove stops and removes a watch.
+func (m *Manager) Remove(id string) error {
+	m.mu.Lock()
+	w, ok := m.watches[id]
+	delete(m.watches, id)
+	m.mu.Unlock()
+
+	if !ok {
+		return apperrors.NotFoundError("watch not found: "+id, id)
+	}
+
+	w.cancel()
+	<-w.done
+	m.logger.Info("watch stopped", "id", id)
+	return nil
+}
+
+// List returns all active watches.
+func (m *Manager) List() []Info {
+	m.mu.Lock()
+	watches := make([]*watch, 0, len(m.watches))
+	for _, w := range m.watches {
+		watches = append(watches, w)
+	}
+	m.mu.Unlock()
+
+	infos := make([]Info, 0, len(watches))
+	for _, w := range watches {
+		infos = append(infos, w.info())
+	}
+	return infos
+}
+
+// Close stops all watches.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	ids := make([]string, 0, len(m.watches))
+	for id := range m.watches {
+		ids = append(ids, id)
+	}
+	m.mu.Unlock()
+
+	for _, id := range ids {
+		_ = m.Remove(id)
+	}
+}
+
+// run processes filesystem events for a watch until it is cancelled.
+func (m *Manager) run(ctx context.Context, w *watch) {
+	defer close(w.done)
+	defer w.fsw.Close()
+
+	var (
+		pending []Event
+		timer   = time.NewTimer(time.Hour)
+	)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			m.logger.WithError(err).Warn("watch error", "id", w.id)
+
+		case ev, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if w.ignored(ev.Name) {
+				continue
+			}
+
+			// Newly created directories join a recursive watch
+			if w.spec.Recursive && ev.Has(fsnotify.Create) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					if err := m.register(w, ev.Name, true); err != nil {
+						m.logger.WithError(err).Warn("failed to watch new directory", "id", w.id)
+					}
+				}
+			}
+
+			pending = append(pending, Event{Path: ev.Name, Op: ev.Op.String(), Time: time.Now()})
+			timer.Reset(m.debounce)
+
+		case <-timer.C:
+			events := pending
+			pending = nil
+			m.handle(ctx, w, events)
+		}
+	}
+}
+
+// handle reports a debounced batch of events and runs the watch command.
+func (m *Manager) handle(ctx context.Context, w *watch, events []Event) {
+	w.mu.Lock()
+	w.recent = append(w.recent, events...)
+	if over := len(w.recent) - maxRecentEvents; over > 0 {
+		w.recent = append(w.recent[:0:0], w.recent[over:]...)
+	}
+	w.mu.Unlock()
+
+	change := Change{WatchID: w.id, Events: events}
+
+	if w.spec.Command != nil {
+		if reason := w.allowTrigger(m.maxTriggers); reason != "" {
+			change.Skipped = reason
+			m.logger.Warn("watch trigger skipped", "id", w.id, "reason", reason)
+		} else {
+			change.Triggered = true
+			m.trigger(ctx, w, &change)
+		}
+	}
+
+	if w.notify != nil {
+		w.notify(ctx, change)
+	}
+}
+
+// allowTrigger applies the per-minute trigger rate limit and returns a
+// reason when the trigger must be skipped.
+func (w *watch) allowTrigger(maxPerMinute int) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-time.Minute)
+	kept := w.triggers[:0]
+	for _, t := range w.triggers {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	w.triggers = kept
+
+	if maxPerMinute > 0 && len(w.triggers) >= maxPerMinute {
+		return fmt.Sprintf("rate limit of %d triggers per minute reached", maxPerMinute)
+	}
+
+	w.triggers = append(w.triggers, now)
+	w.total++
+	return ""
+}
+
+// trigger runs the watch command and records it in the history.
+func (m *Manager) trigger(ctx context.Context, w *watch, change *Change) {
+	cmd := *w.spec.Command
+	result, err := m.runner.ExecuteConfigCommand(ctx, &cmd, w.workDir)
+
+	rec := types.ExecutionRecord{
+		Source: types.ExecutionSourceWatch,
+		Tool:   cmd.Name,
+		Request: types.CommandExecutionRequest{
+			Command: cmd.Command,
+			Args:    cmd.Args,
+			WorkDir: w.workDir,
+			Timeout: cmd.Timeout.String(),
+		},
+		Result:   result,
+		Decision: history.Decision(err),
+	}
+	if cmd.WorkDir != "" {
+		rec.Request.WorkDir = cmd.WorkDir
+	}
+	if err != nil {
+		rec.Error = err.Error()
+		change.Error = err.Error()
+	}
+
+	if m.history != nil {
+		result = m.history.Add(rec).Result
+	}
+	change.Result = result
+}
+
+// ignored reports whether a path matches the watch's ignore patterns.
+func (w *watch) ignored(path string) bool {
+	base := filepath.Base(path)
+	if base == defaultIgnore {
+		return true
+	}
+	for _, pattern := range w.spec.Ignore {
+		if matched, _ := filepath.Match(pattern, base); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// info returns a snapshot of the watch state.
+func (w *watch) info() Info {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info := Info{
+		ID:           w.id,
+		Path:         w.spec.Path,
+		Recursive:    w.spec.Recursive,
+		Directories:  w.dirs,
+		Triggers:     w.total,
+		CreatedAt:    w.createdAt,
+		RecentEvents: append([]Event(nil), w.recent...),
+	}
+	if w.spec.Command != nil {
+		info.Command = w.spec.Command.Name
+	}
+	return info
+}
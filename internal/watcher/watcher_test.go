@@ -0,0 +1,163 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/simple-mcp-runner/internal/history"
+	"github.com/mjmorales/simple-mcp-runner/internal/logger"
+	"github.com/mjmorales/simple-mcp-runner/pkg/config"
+	"github.com/mjmorales/simple-mcp-runner/pkg/types"
+)
+
+type fakeRunner struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (f *fakeRunner) ExecuteConfigCommand(ctx context.Context, cmd *config.Command, workDir string) (*types.CommandExecutionResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, cmd.Name)
+	return &types.CommandExecutionResult{Stdout: "ok"}, nil
+}
+
+func testManager(runner Runner, hist *history.Store) *Manager {
+	cfg := config.Default()
+	cfg.Watch.Debounce = config.Duration(50 * time.Millisecond)
+	cfg.Watch.MaxWatches = 2
+	return NewManager(cfg, runner, hist, logger.Default())
+}
+
+func TestManager_AddListRemove(t *testing.T) {
+	m := testManager(&fakeRunner{}, nil)
+	defer m.Close()
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := m.Add(Spec{Path: dir, Recursive: true}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.ID != "w1" {
+		t.Errorf("expected id w1, got %s", info.ID)
+	}
+	if info.Directories != 2 {
+		t.Errorf("expected 2 directories, got %d", info.Directories)
+	}
+
+	if got := m.List(); len(got) != 1 || got[0].ID != info.ID {
+		t.Errorf("unexpected watch list: %+v", got)
+	}
+
+	if err := m.Remove(info.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := m.List(); len(got) != 0 {
+		t.Errorf("expected no watches, got %d", len(got))
+	}
+	if err := m.Remove(info.ID); err == nil {
+		t.Error("expected error removing unknown watch")
+	}
+}
+
+func TestManager_AddErrors(t *testing.T) {
+	m := testManager(&fakeRunner{}, nil)
+	defer m.Close()
+
+	if _, err := m.Add(Spec{Path: filepath.Join(t.TempDir(), "missing")}, nil); err == nil {
+		t.Error("expected error for missing path")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := m.Add(Spec{Path: t.TempDir()}, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := m.Add(Spec{Path: t.TempDir()}, nil); err == nil {
+		t.Error("expected error when watch limit is reached")
+	}
+}
+
+func TestManager_TriggersCommand(t *testing.T) {
+	runner := &fakeRunner{}
+	hist := history.New(10)
+	m := testManager(runner, hist)
+	defer m.Close()
+
+	dir := t.TempDir()
+	changes := make(chan Change, 4)
+	spec := Spec{
+		Path:    dir,
+		Ignore:  []string{"*.tmp"},
+		Command: &config.Command{Name: "build", Command: "echo"},
+	}
+	if _, err := m.Add(spec, func(ctx context.Context, c Change) { changes <- c }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "ignored.tmp"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case c := <-changes:
+		if !c.Triggered {
+			t.Errorf("expected command to be triggered, skipped: %s", c.Skipped)
+		}
+		for _, ev := range c.Events {
+			if filepath.Base(ev.Path) == "ignored.tmp" {
+				t.Errorf("ignored file reported: %+v", ev)
+			}
+		}
+		if c.Result == nil || c.Result.HistoryID == "" {
+			t.Errorf("expected recorded result, got %+v", c.Result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for change notification")
+	}
+
+	recs := hist.List(history.Filter{Source: types.ExecutionSourceWatch})
+	if len(recs) != 1 || recs[0].Tool != "build" {
+		t.Errorf("unexpected history records: %+v", recs)
+	}
+}
+
+func TestWatch_AllowTrigger(t *testing.T) {
+	w := &watch{}
+
+	for i := 0; i < 3; i++ {
+		if reason := w.allowTrigger(3); reason != "" {
+			t.Fatalf("trigger %d unexpectedly skipped: %s", i, reason)
+		}
+	}
+	if reason := w.allowTrigger(3); reason == "" {
+		t.Error("expected rate limit to skip trigger")
+	}
+
+	// Triggers older than a minute no longer count
+	w.triggers[0] = time.Now().Add(-2 * time.Minute)
+	if reason := w.allowTrigger(3); reason != "" {
+		t.Errorf("expected trigger after window expiry, got: %s", reason)
+	}
+	if w.total != 4 {
+		t.Errorf("expected 4 total triggers, got %d", w.total)
+	}
+
+	unlimited := &watch{}
+	for i := 0; i < 10; i++ {
+		if reason := unlimited.allowTrigger(0); reason != "" {
+			t.Fatalf("unexpected skip with no limit: %s", reason)
+		}
+	}
+}
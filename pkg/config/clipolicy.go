@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CLIModules are the command line tools CLI policies understand.
+var CLIModules = []string{"aws", "az", "gcloud", "kubectl"}
+
+// CLIPolicy restricts a cloud command line tool to the operations its
+// built-in module classifies as read-only, such as aws s3 ls or kubectl
+// get, with exceptions.
+type CLIPolicy struct {
+	// CLI is the module that classifies operations: aws, az, gcloud or
+	// kubectl
+	CLI string `yaml:"cli"`
+
+	// Commands are entries in the same forms as blocked_commands naming
+	// the binaries the policy applies to; defaults to the CLI name
+	Commands []string `yaml:"commands,omitempty"`
+
+	// Allow lists operations allowed even though they mutate, as the
+	// leading words of the command line without flags, such as
+	// "s3 cp" or "rollout restart". A trailing * matches any suffix of
+	// the last word
+	Allow []string `yaml:"allow,omitempty"`
+
+	// Deny lists operations denied even though they are read-only, such
+	// as "get secrets"; Deny wins over Allow
+	Deny []string `yaml:"deny,omitempty"`
+}
+
+// Matches reports whether a policy applies to a command.
+func (p CLIPolicy) Matches(command string) bool {
+	entries := p.Commands
+	if len(entries) == 0 {
+		entries = []string{p.CLI}
+	}
+	return matchCommand(entries, normalizeCommand(command, ""), (*commandForms).blockedBy) != ""
+}
+
+// validate checks a policy.
+func (p CLIPolicy) validate() error {
+	if !slices.Contains(CLIModules, p.CLI) {
+		return fmt.Errorf("unknown cli %q: must be one of %s", p.CLI, strings.Join(CLIModules, ", "))
+	}
+	for _, entry := range p.Commands {
+		if _, err := parseCommandRule(entry); err != nil {
+			return fmt.Errorf("cli policy %s: %v", p.CLI, err)
+		}
+	}
+	for _, list := range [][]string{p.Allow, p.Deny} {
+		for _, op := range list {
+			if strings.TrimSpace(op) == "" || strings.HasPrefix(strings.TrimSpace(op), "-") {
+				return fmt.Errorf("cli policy %s: invalid operation %q", p.CLI, op)
+			}
+		}
+	}
+	return nil
+}
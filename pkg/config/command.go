@@ -0,0 +1,305 @@
+package config
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// executableExts are the extensions Windows runs without being named.
+var executableExts = []string{".exe", ".com", ".bat", ".cmd"}
+
+// commandForms are the forms of a command that policy entries are matched
+// against, so ./rm, /bin/rm, a symlink to rm, rm.exe and RM (on Windows)
+// are all recognized as rm.
+type commandForms struct {
+	raw   string
+	bare  bool     // Given without a directory, so found via PATH
+	paths []string // Absolute path found via LookPath, then with symlinks resolved
+	names []string // Normalized base names of raw and paths
+}
+
+// normalizeCommand resolves a command the way it will be executed in
+// workDir, or in the current directory when workDir is empty.
+func normalizeCommand(command, workDir string) commandForms {
+	f := commandForms{
+		raw:  command,
+		bare: !strings.ContainsAny(command, `/\`),
+	}
+	f.addName(command)
+
+	path, err := lookPath(command, workDir)
+	if err != nil {
+		return f
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	f.addPath(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		f.addPath(resolved)
+	}
+
+	return f
+}
+
+// lookPath finds a command the way exec.Cmd runs it: bare names via PATH
+// and relative paths from the directory the command runs in.
+func lookPath(command, workDir string) (string, error) {
+	if workDir != "" && strings.ContainsAny(command, `/\`) && !filepath.IsAbs(command) {
+		command = filepath.Join(workDir, command)
+	}
+	return exec.LookPath(command)
+}
+
+func (f *commandForms) addPath(path string) {
+	path = normalizeCase(path)
+	for _, p := range f.paths {
+		if p == path {
+			return
+		}
+	}
+	f.paths = append(f.paths, path)
+	f.addName(path)
+}
+
+func (f *commandForms) addName(path string) {
+	name := commandName(path)
+	for _, n := range f.names {
+		if n == name {
+			return
+		}
+	}
+	f.names = append(f.names, name)
+}
+
+// commandName returns the base name of a command, lowercased and without
+// its executable extension on Windows.
+func commandName(command string) string {
+	name := filepath.Base(strings.ReplaceAll(command, `\`, "/"))
+	if runtime.GOOS != "windows" {
+		return name
+	}
+
+	name = strings.ToLower(name)
+	for _, ext := range executableExts {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return name
+}
+
+// normalizeCase lowercases paths on Windows, whose file system is case
+// insensitive.
+func normalizeCase(path string) string {
+	if runtime.GOOS == "windows" {
+		return strings.ToLower(path)
+	}
+	return path
+}
+
+// isPathEntry reports whether a policy entry names a path rather than a
+// command name.
+func isPathEntry(entry string) bool {
+	return strings.ContainsAny(entry, `/\`)
+}
+
+// legacyMatch is the original exact or prefix comparison of the raw
+// command, where an entry naming a directory matches the commands in it.
+// On Windows either slash separates directories and case is ignored.
+func legacyMatch(entry, command string) bool {
+	if runtime.GOOS == "windows" {
+		entry, command = normalizeCase(filepath.FromSlash(entry)), normalizeCase(filepath.FromSlash(command))
+	}
+	return command == entry || strings.HasPrefix(command, entry+string(filepath.Separator))
+}
+
+// matchesPath reports whether a path entry names the command's binary.
+func (f *commandForms) matchesPath(entry string) bool {
+	paths := []string{normalizeCase(entry)}
+	if abs, err := absPath(entry); err == nil {
+		paths = append(paths, normalizeCase(abs))
+		if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+			paths = append(paths, normalizeCase(resolved))
+		}
+	}
+
+	for _, want := range paths {
+		for _, p := range f.paths {
+			if p == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Kinds of command policy entries.
+const (
+	ruleLiteral = iota
+	ruleGlob
+	ruleRegex
+)
+
+// regexPrefix marks a command policy entry as a regular expression.
+const regexPrefix = "re:"
+
+// commandRule is a parsed allowed_commands or blocked_commands entry. The
+// comment is dropped; explanations report the entry as written.
+type commandRule struct {
+	pattern string
+	kind    int
+	re      *regexp.Regexp
+}
+
+// commandRules caches parsed entries, which are matched on every command.
+var commandRules sync.Map
+
+// parseCommandRule parses a policy entry: a command name or path, a glob,
+// or a regular expression prefixed with "re:", each optionally followed by
+// " # comment".
+func parseCommandRule(entry string) (*commandRule, error) {
+	if cached, ok := commandRules.Load(entry); ok {
+		return cached.(*commandRule), nil
+	}
+
+	rule := &commandRule{pattern: strings.TrimSpace(entry)}
+	if i := strings.Index(" "+rule.pattern, " #"); i >= 0 {
+		rule.pattern = strings.TrimSpace(rule.pattern[:i])
+	}
+	if rule.pattern == "" {
+		return nil, fmt.Errorf("empty command entry: %q", entry)
+	}
+
+	switch {
+	case strings.HasPrefix(rule.pattern, regexPrefix):
+		expr := strings.TrimPrefix(rule.pattern, regexPrefix)
+		if runtime.GOOS == "windows" {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid command regex %q: %v", rule.pattern, err)
+		}
+		rule.kind, rule.re = ruleRegex, re
+	case strings.ContainsAny(rule.pattern, "*?["):
+		if _, err := filepath.Match(rule.pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid command glob %q: %v", rule.pattern, err)
+		}
+		rule.kind = ruleGlob
+	}
+
+	commandRules.Store(entry, rule)
+	return rule, nil
+}
+
+// isLiteralEntry reports whether a policy entry names a command rather
+// than a glob or regex.
+func isLiteralEntry(entry string) bool {
+	rule, err := parseCommandRule(entry)
+	return err == nil && rule.kind == ruleLiteral
+}
+
+// matchCommand returns the first entry matching the command, trying
+// literal entries before patterns. Entries that fail to parse never match;
+// Validate rejects them at load.
+func matchCommand(entries []string, f commandForms, matches func(*commandForms, *commandRule) bool) string {
+	for _, literal := range []bool{true, false} {
+		for _, entry := range entries {
+			rule, err := parseCommandRule(entry)
+			if err != nil || (rule.kind == ruleLiteral) != literal {
+				continue
+			}
+			if matches(&f, rule) {
+				return entry
+			}
+		}
+	}
+	return ""
+}
+
+// matchesPattern reports whether a glob or regex rule matches any of the
+// candidate forms of a command.
+func (r *commandRule) matchesPattern(candidates []string) bool {
+	pattern := normalizeCase(r.pattern)
+	for _, c := range candidates {
+		if r.kind == ruleRegex {
+			if r.re.MatchString(c) {
+				return true
+			}
+		} else if ok, _ := filepath.Match(pattern, c); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// blockedBy reports whether a blocked_commands entry matches the command.
+// Name entries match the base name of any form of the command, so a
+// blocked binary cannot be reached through another path or a symlink;
+// patterns are matched against every name and path.
+func (f *commandForms) blockedBy(rule *commandRule) bool {
+	if rule.kind != ruleLiteral {
+		candidates := append(append([]string{normalizeCase(f.raw)}, f.names...), f.paths...)
+		return rule.matchesPattern(candidates)
+	}
+
+	entry := rule.pattern
+	if legacyMatch(entry, f.raw) {
+		return true
+	}
+	if isPathEntry(entry) {
+		return f.matchesPath(entry)
+	}
+
+	name := commandName(entry)
+	for _, n := range f.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedBy reports whether an allowed_commands entry matches the
+// command. Name entries match a command given by the same name, or the
+// same binary the name resolves to via PATH, but not another binary that
+// happens to share the name. Patterns likewise match the name only of a
+// command found via PATH, and otherwise its full path.
+func (f *commandForms) allowedBy(rule *commandRule) bool {
+	if rule.kind != ruleLiteral {
+		candidates := f.paths
+		if f.bare {
+			candidates = append([]string{commandName(f.raw)}, candidates...)
+		} else {
+			candidates = append([]string{normalizeCase(f.raw)}, candidates...)
+		}
+		return rule.matchesPattern(candidates)
+	}
+
+	entry := rule.pattern
+	if legacyMatch(entry, f.raw) {
+		return true
+	}
+	if isPathEntry(entry) {
+		return f.matchesPath(entry)
+	}
+
+	if f.bare && commandName(f.raw) == commandName(entry) {
+		return true
+	}
+
+	path, err := exec.LookPath(entry)
+	if err != nil {
+		return false
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return f.matchesPath(path)
+}
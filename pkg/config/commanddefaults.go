@@ -0,0 +1,107 @@
+package config
+
+import (
+	"maps"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CommandDefaults are settings every entry in commands starts from. A
+// command overrides a setting by giving it, e.g. risky: false; its env is
+// merged with the default env, its own variables winning.
+type CommandDefaults struct {
+	// WorkDir is the working directory of commands without one
+	WorkDir string `yaml:"workdir,omitempty"`
+
+	// WorkDirMode and WorkDirMarkers infer the working directory of
+	// commands as in a command entry
+	WorkDirMode    string   `yaml:"workdir_mode,omitempty"`
+	WorkDirMarkers []string `yaml:"workdir_markers,omitempty"`
+
+	// Env are environment variables of every command
+	Env map[string]string `yaml:"env,omitempty"`
+
+	// Timeout, MaxTimeout and MaxOutputSize are the limits of commands as
+	// in a command entry
+	Timeout       Duration `yaml:"timeout,omitempty"`
+	MaxTimeout    Duration `yaml:"max_timeout,omitempty"`
+	MaxOutputSize ByteSize `yaml:"max_output_size,omitempty"`
+
+	// Priority is the scheduling priority of commands: low, normal or high
+	Priority string `yaml:"priority,omitempty"`
+
+	// Isolation and Category are the isolation preset and category of
+	// commands as in a command entry
+	Isolation string `yaml:"isolation,omitempty"`
+	Category  string `yaml:"category,omitempty"`
+
+	// Mutating, TrackChanges, Risky and RequiresAuth tag every command as
+	// in a command entry
+	Mutating     bool `yaml:"mutating,omitempty"`
+	TrackChanges bool `yaml:"track_changes,omitempty"`
+	Risky        bool `yaml:"risky,omitempty"`
+	RequiresAuth bool `yaml:"requires_auth,omitempty"`
+}
+
+// command returns a command with the defaults set.
+func (d CommandDefaults) command() Command {
+	return Command{
+		WorkDir:        d.WorkDir,
+		WorkDirMode:    d.WorkDirMode,
+		WorkDirMarkers: slices.Clone(d.WorkDirMarkers),
+		Env:            maps.Clone(d.Env),
+		Timeout:        d.Timeout,
+		MaxTimeout:     d.MaxTimeout,
+		MaxOutputSize:  d.MaxOutputSize,
+		Priority:       d.Priority,
+		Isolation:      d.Isolation,
+		Category:       d.Category,
+		Mutating:       d.Mutating,
+		TrackChanges:   d.TrackChanges,
+		Risky:          d.Risky,
+		RequiresAuth:   d.RequiresAuth,
+	}
+}
+
+// UnmarshalYAML decodes the configuration, then decodes each entry of
+// commands over command_defaults, so the defaults apply to the settings an
+// entry leaves out.
+func (c *Config) UnmarshalYAML(node *yaml.Node) error {
+	type plain Config
+	if err := node.Decode((*plain)(c)); err != nil {
+		return err
+	}
+
+	commands := mappingValue(node, "commands")
+	if commands != nil && commands.Kind == yaml.AliasNode {
+		commands = commands.Alias
+	}
+	if commands == nil || commands.Kind != yaml.SequenceNode {
+		return nil
+	}
+	c.Commands = make([]Command, len(commands.Content))
+	for i, entry := range commands.Content {
+		c.Commands[i] = c.CommandDefaults.command()
+		if err := entry.Decode(&c.Commands[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PolicyCondition restricts when, or how often, matching commands may run.
+type PolicyCondition struct {
+	// Name identifies the condition in denials and run counters
+	Name string `yaml:"name"`
+
+	// Commands are entries in the same forms as blocked_commands
+	Commands []string `yaml:"commands"`
+
+	// Args limits the condition to runs whose arguments start with these
+	Args []string `yaml:"args,omitempty"`
+
+	// Windows are the times matching commands may run; any time when empty
+	Windows []TimeWindow `yaml:"windows,omitempty"`
+
+	// Timezone is the IANA zone windows are in; defaults to local time
+	Timezone string `yaml:"timezone,omitempty"`
+
+	// MaxRuns limits matching runs within Period; unlimited when 0
+	MaxRuns int `yaml:"max_runs,omitempty"`
+
+	// Period is the rolling duration MaxRuns counts over, such as 24h
+	Period Duration `yaml:"period,omitempty"`
+}
+
+// TimeWindow is a daily time range on some days of the week. A window
+// whose end is before its start runs past midnight.
+type TimeWindow struct {
+	// Days are mon to sun, or weekdays, weekend; every day when empty
+	Days []string `yaml:"days,omitempty"`
+
+	// Start and End are HH:MM
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+}
+
+// dayNames maps day names to the weekdays they cover.
+var dayNames = map[string][]time.Weekday{
+	"sun":      {time.Sunday},
+	"mon":      {time.Monday},
+	"tue":      {time.Tuesday},
+	"wed":      {time.Wednesday},
+	"thu":      {time.Thursday},
+	"fri":      {time.Friday},
+	"sat":      {time.Saturday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekend":  {time.Saturday, time.Sunday},
+}
+
+// Bounds parses a window into the weekdays it starts on and its start and
+// end as offsets from midnight.
+func (w TimeWindow) Bounds() (days [7]bool, start, end time.Duration, err error) {
+	if len(w.Days) == 0 {
+		days = [7]bool{true, true, true, true, true, true, true}
+	}
+	for _, name := range w.Days {
+		weekdays, ok := dayNames[strings.ToLower(name)]
+		if !ok {
+			return days, 0, 0, fmt.Errorf("invalid day %q (must be: mon-sun, weekdays, weekend)", name)
+		}
+		for _, d := range weekdays {
+			days[d] = true
+		}
+	}
+
+	if start, err = parseClock(w.Start); err != nil {
+		return days, 0, 0, err
+	}
+	if end, err = parseClock(w.End); err != nil {
+		return days, 0, 0, err
+	}
+	if start == end {
+		return days, 0, 0, fmt.Errorf("window start and end are both %s", w.Start)
+	}
+	return days, start, end, nil
+}
+
+// String describes a window for denial messages.
+func (w TimeWindow) String() string {
+	days := "daily"
+	if len(w.Days) > 0 {
+		days = strings.Join(w.Days, ",")
+	}
+	return fmt.Sprintf("%s %s-%s", days, w.Start, w.End)
+}
+
+// parseClock parses HH:MM into an offset from midnight.
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (must be HH:MM)", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Location returns the timezone of a condition's windows.
+func (c PolicyCondition) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(c.Timezone)
+}
+
+// Matches reports whether a condition applies to a command and its
+// arguments.
+func (c PolicyCondition) Matches(command string, args []string) bool {
+	if len(args) < len(c.Args) {
+		return false
+	}
+	for i, arg := range c.Args {
+		if args[i] != arg {
+			return false
+		}
+	}
+	return matchCommand(c.Commands, normalizeCommand(command, ""), (*commandForms).blockedBy) != ""
+}
+
+// validate checks a condition.
+func (c PolicyCondition) validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("condition name is required")
+	}
+	if len(c.Commands) == 0 {
+		return fmt.Errorf("condition %s: commands are required", c.Name)
+	}
+	for _, entry := range c.Commands {
+		if _, err := parseCommandRule(entry); err != nil {
+			return fmt.Errorf("condition %s: %v", c.Name, err)
+		}
+	}
+	if len(c.Windows) == 0 && c.MaxRuns == 0 {
+		return fmt.Errorf("condition %s: windows or max_runs is required", c.Name)
+	}
+	for _, w := range c.Windows {
+		if _, _, _, err := w.Bounds(); err != nil {
+			return fmt.Errorf("condition %s: %v", c.Name, err)
+		}
+	}
+	if _, err := c.Location(); err != nil {
+		return fmt.Errorf("condition %s: invalid timezone %q", c.Name, c.Timezone)
+	}
+	if c.MaxRuns < 0 {
+		return fmt.Errorf("condition %s: max_runs cannot be negative", c.Name)
+	}
+	if c.MaxRuns > 0 {
+		if c.Period <= 0 {
+			return fmt.Errorf("condition %s: max_runs requires a positive period such as 24h", c.Name)
+		}
+	}
+	return nil
+}
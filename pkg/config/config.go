@@ -2,11 +2,16 @@
 package config
 
 import (
+	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
 
 	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
 	"gopkg.in/yaml.v3"
@@ -23,9 +28,30 @@ type Config struct {
 	// Transport type (currently only stdio is supported)
 	Transport string `yaml:"transport" validate:"required,oneof=stdio"`
 
+	// Server settings
+	Server ServerConfig `yaml:"server,omitempty"`
+
 	// Commands defines custom commands exposed by the server
 	Commands []Command `yaml:"commands,omitempty"`
 
+	// CommandDefaults are inherited by every entry in Commands
+	CommandDefaults CommandDefaults `yaml:"command_defaults,omitempty"`
+
+	// ToolGroups collect configured commands into toolsets, so clients
+	// only list the tools of the groups they select
+	ToolGroups []ToolGroup `yaml:"tool_groups,omitempty"`
+
+	// Catalog fetches more commands from a centrally managed catalog
+	Catalog CatalogConfig `yaml:"catalog,omitempty"`
+
+	// Plugins are WebAssembly modules run in a sandbox for commands that
+	// list them
+	Plugins []Plugin `yaml:"plugins,omitempty"`
+
+	// ScriptTools are tools defined by Starlark scripts that compose
+	// configured commands and file reads
+	ScriptTools []ScriptTool `yaml:"script_tools,omitempty"`
+
 	// Security settings
 	Security SecurityConfig `yaml:"security,omitempty"`
 
@@ -37,12 +63,89 @@ type Config struct {
 
 	// Discovery settings
 	Discovery DiscoveryConfig `yaml:"discovery,omitempty"`
+
+	// History settings
+	History HistoryConfig `yaml:"history,omitempty"`
+
+	// Schedules defines configured commands that run on a cron schedule
+	Schedules []Schedule `yaml:"schedules,omitempty"`
+
+	// Watch settings
+	Watch WatchConfig `yaml:"watch,omitempty"`
+
+	// Log tailing settings
+	Tail TailConfig `yaml:"tail,omitempty"`
+
+	// Process inspection settings
+	Processes ProcessConfig `yaml:"processes,omitempty"`
+
+	// File transfer settings
+	Transfer TransferConfig `yaml:"transfer,omitempty"`
+
+	// HTTP request tool
+	HTTP HTTPConfig `yaml:"http,omitempty"`
+
+	// Outbound HTTP client settings: proxy, CA bundle, timeouts, retries
+	// and IP version preference
+	Network NetworkConfig `yaml:"network,omitempty"`
+
+	// Archive settings
+	Archive ArchiveConfig `yaml:"archive,omitempty"`
+
+	// Checks of configured commands at startup
+	CommandChecks CommandCheckConfig `yaml:"command_checks,omitempty"`
+
+	// Disk usage analysis settings
+	DiskUsage DiskUsageConfig `yaml:"disk_usage,omitempty"`
+
+	// Desktop notification settings
+	Notifications NotificationConfig `yaml:"notifications,omitempty"`
+
+	// Backups for undoing file changes
+	Backup BackupConfig `yaml:"backup,omitempty"`
+
+	// Trash for files removed with delete_path
+	Trash TrashConfig `yaml:"trash,omitempty"`
+
+	// Git snapshots taken before risky commands
+	GitSnapshot GitSnapshotConfig `yaml:"git_snapshot,omitempty"`
+
+	// Operator approvals for commands requiring a second approval
+	Approvals ApprovalConfig `yaml:"approvals,omitempty"`
+
+	// Tool usage analytics
+	Usage UsageConfig `yaml:"usage,omitempty"`
+
+	// Anonymous usage reports to the maintainers, off by default
+	Telemetry TelemetryConfig `yaml:"telemetry,omitempty"`
+
+	// Instance lock settings
+	Instance InstanceConfig `yaml:"instance,omitempty"`
+
+	// Docker container tools
+	Containers ContainerConfig `yaml:"containers,omitempty"`
+
+	// tmux session tools
+	Tmux TmuxConfig `yaml:"tmux,omitempty"`
+
+	// Interactive interpreter sessions
+	REPL REPLConfig `yaml:"repl,omitempty"`
+
+	// Fault injection for testing clients
+	Chaos ChaosConfig `yaml:"chaos,omitempty"`
+
+	// Operator control API
+	Control ControlConfig `yaml:"control,omitempty"`
 }
 
 // Command represents a configured command.
 type Command struct {
-	// Name is the command identifier
-	Name string `yaml:"name" validate:"required,min=1,max=50,alphanum"`
+	// Name is the command identifier and tool name: letters, digits,
+	// underscores, hyphens and dots, starting with a letter
+	Name string `yaml:"name" validate:"required,min=1,max=50"`
+
+	// DisplayName is the title clients show for the tool, e.g. "Run Tests"
+	DisplayName string `yaml:"display_name,omitempty"`
 
 	// Description explains what the command does
 	Description string `yaml:"description" validate:"required,min=1,max=500"`
@@ -56,50 +159,352 @@ type Command struct {
 	// WorkDir is the working directory for the command
 	WorkDir string `yaml:"workdir,omitempty"`
 
+	// WorkDirMode infers the working directory from the path the client
+	// passes as workdir, or WorkDir when it passes none: "git_root" walks
+	// up to the root of its git repository, "nearest_marker" to the
+	// nearest directory holding one of WorkDirMarkers
+	WorkDirMode string `yaml:"workdir_mode,omitempty"`
+
+	// WorkDirMarkers are the files marking a project directory for
+	// nearest_marker; defaults to DefaultWorkDirMarkers
+	WorkDirMarkers []string `yaml:"workdir_markers,omitempty"`
+
 	// Env are additional environment variables
 	Env map[string]string `yaml:"env,omitempty"`
 
+	// ConcurrencyGroup serializes commands sharing the same group; runs
+	// queue until the group's previous run finishes
+	ConcurrencyGroup string `yaml:"concurrency_group,omitempty"`
+
+	// Mutating marks commands that write to their working directory; they
+	// hold an advisory lock on it, shared with other server processes
+	Mutating bool `yaml:"mutating,omitempty"`
+
+	// TrackChanges snapshots the working directory before and after a run
+	// and reports the files created, modified and deleted
+	TrackChanges bool `yaml:"track_changes,omitempty"`
+
+	// Risky marks commands whose changes may need recovering; when the
+	// working directory is a git repository it is snapshotted first
+	Risky bool `yaml:"risky,omitempty"`
+
+	// GitSnapshot overrides git_snapshot.enabled for this command
+	GitSnapshot *bool `yaml:"git_snapshot,omitempty"`
+
 	// Timeout for command execution
-	Timeout string `yaml:"timeout,omitempty"`
+	Timeout Duration `yaml:"timeout,omitempty"`
+
+	// MaxTimeout caps the timeout of this command below
+	// execution.max_timeout
+	MaxTimeout Duration `yaml:"max_timeout,omitempty"`
+
+	// MaxOutputSize caps the output of this command below
+	// execution.max_output_size, in bytes per stream
+	MaxOutputSize ByteSize `yaml:"max_output_size,omitempty"`
+
+	// Priority is the CPU and I/O scheduling priority of the command: low,
+	// normal or high
+	Priority string `yaml:"priority,omitempty"`
+
+	// Isolation is the preset isolating the command from the server's
+	// environment, home directory and network: none, env_clean, tmp_home
+	// or no_network; env_clean by default for commands with a Category
+	Isolation string `yaml:"isolation,omitempty"`
+
+	// Category is the kind of command, build, vcs or network, choosing
+	// the variables isolation keeps
+	Category string `yaml:"category,omitempty"`
+
+	// SummaryErrorPatterns and SummaryWarnPatterns replace the patterns of
+	// execution.summary for this command, e.g. to match its log format
+	SummaryErrorPatterns []string `yaml:"summary_error_patterns,omitempty"`
+	SummaryWarnPatterns  []string `yaml:"summary_warn_patterns,omitempty"`
 
 	// AllowArgs allows additional arguments from the client
 	AllowArgs bool `yaml:"allow_args,omitempty"`
+
+	// ArgValues are argument values suggested to clients completing the
+	// args of the command's prompt, e.g. the targets of a make command;
+	// requires allow_args
+	ArgValues []string `yaml:"arg_values,omitempty"`
+
+	// RequiresAuth only runs the command for requests with an
+	// authenticated principal
+	RequiresAuth bool `yaml:"requires_auth,omitempty"`
+
+	// AllowedUsers restricts the command to these principals; implies
+	// RequiresAuth
+	AllowedUsers []string `yaml:"allowed_users,omitempty"`
+
+	// RequiresSecondApproval holds each run until two distinct operators
+	// approve it with the approvals command
+	RequiresSecondApproval bool `yaml:"requires_second_approval,omitempty"`
+
+	// Plugins are the names of the plugins that decide whether the command
+	// may run and process its output, in order
+	Plugins []string `yaml:"plugins,omitempty"`
+
+	// VersionArgs are the arguments printing the version of the command,
+	// for {{.Version}} in the description; defaults to --version
+	VersionArgs []string `yaml:"version_args,omitempty"`
+
+	// InstallHint says how to install the command's binary when it is
+	// missing, e.g. "brew install ripgrep"
+	InstallHint string `yaml:"install_hint,omitempty"`
 }
 
 // SecurityConfig contains security settings.
 type SecurityConfig struct {
-	// AllowedCommands is a whitelist of commands that can be executed
+	// AllowedCommands is a whitelist of commands that can be executed.
+	// Entries are command names or paths, globs such as "git-*", or
+	// regular expressions prefixed with "re:", optionally followed by a
+	// " # comment"
 	AllowedCommands []string `yaml:"allowed_commands,omitempty"`
 
-	// BlockedCommands is a blacklist of commands that cannot be executed
+	// BlockedCommands is a blacklist of commands that cannot be executed,
+	// with entries in the same forms as AllowedCommands
 	BlockedCommands []string `yaml:"blocked_commands,omitempty"`
 
+	// CommandPrecedence decides commands matched by both lists: "block"
+	// (the default) always denies them, "explicit_allow" lets a literal
+	// allowed entry override a glob or regex blocked entry
+	CommandPrecedence string `yaml:"command_precedence,omitempty"`
+
 	// AllowedPaths restricts execution to these paths
 	AllowedPaths []string `yaml:"allowed_paths,omitempty"`
 
+	// DeniedPaths are never allowed, even inside AllowedPaths
+	DeniedPaths []string `yaml:"denied_paths,omitempty"`
+
+	// DenyPathArgs checks command arguments against DeniedPaths and
+	// AllowedPaths too: arguments, and the values of --flag=value
+	// arguments, are resolved against the working directory, and a command
+	// naming a path inside a denied path, or a directory containing one, or
+	// running in such a directory, is denied, as is one naming a path
+	// outside all allowed paths
+	DenyPathArgs bool `yaml:"deny_path_args,omitempty"`
+
+	// ResolveSymlinks checks paths by where their symlinks point, so links
+	// inside allowed paths cannot escape them
+	ResolveSymlinks bool `yaml:"resolve_symlinks,omitempty"`
+
 	// MaxCommandLength limits the command string length
 	MaxCommandLength int `yaml:"max_command_length,omitempty"`
 
 	// DisableShellExpansion prevents shell expansion in commands
 	DisableShellExpansion bool `yaml:"disable_shell_expansion,omitempty"`
+
+	// EnvAllow lists glob patterns of environment variables commands
+	// inherit from the server; all variables are inherited when empty
+	EnvAllow []string `yaml:"env_allow,omitempty"`
+
+	// EnvDeny lists glob patterns of environment variables never inherited
+	EnvDeny []string `yaml:"env_deny,omitempty"`
+
+	// SensitiveEnv lists extra glob patterns of environment variables whose
+	// values are masked when reported
+	SensitiveEnv []string `yaml:"sensitive_env,omitempty"`
+
+	// AllowForceUnlock lets callers run mutating commands with force,
+	// bypassing workdir locks held by other runs
+	AllowForceUnlock bool `yaml:"allow_force_unlock,omitempty"`
+
+	// AllowClearQuarantine registers the clear_quarantine tool on macOS,
+	// which removes the quarantine attribute of allowed binaries once two
+	// operators approve it
+	AllowClearQuarantine bool `yaml:"allow_clear_quarantine,omitempty"`
+
+	// Policy is "enforce" (the default) or "learn". In learn mode denied
+	// commands are also recorded to SuggestionsFile for policy suggest
+	Policy string `yaml:"policy,omitempty"`
+
+	// SuggestionsFile is a JSON lines file of commands denied in learn
+	// mode; defaults to a file under the user cache directory
+	SuggestionsFile string `yaml:"suggestions_file,omitempty"`
+
+	// Conditions restrict when and how often matching commands may run
+	Conditions []PolicyCondition `yaml:"conditions,omitempty"`
+
+	// StateFile keeps the run counters of conditions across restarts;
+	// defaults to a file under the user cache directory
+	StateFile string `yaml:"state_file,omitempty"`
+
+	// CLIPolicies restrict cloud command line tools to read-only
+	// operations, with exceptions
+	CLIPolicies []CLIPolicy `yaml:"cli_policies,omitempty"`
+
+	// PackagePolicies hold package installs outside an allowlist for
+	// operator approval
+	PackagePolicies []PackagePolicy `yaml:"package_policies,omitempty"`
+
+	// Screening flags requests that look like a prompt injection at work,
+	// and blocks them or holds them for approval
+	Screening ScreeningConfig `yaml:"screening,omitempty"`
+
+	// DLP scans results returned to clients for sensitive data, and
+	// alerts, redacts or blocks them
+	DLP DLPConfig `yaml:"dlp,omitempty"`
+
+	// Tripwires are decoy commands that are never run; requests for them
+	// are denied and raise a high-severity alert
+	Tripwires []Tripwire `yaml:"tripwires,omitempty"`
+
+	// TripwireWebhook receives every tripwire alert as a JSON POST; an
+	// https URL, or an http URL of a loopback host
+	TripwireWebhook string `yaml:"tripwire_webhook,omitempty"`
+}
+
+// Security policy modes.
+const (
+	PolicyEnforce = "enforce"
+	PolicyLearn   = "learn"
+)
+
+// Command precedence modes.
+const (
+	PrecedenceBlock         = "block"
+	PrecedenceExplicitAllow = "explicit_allow"
+)
+
+// Working directory inference modes.
+const (
+	WorkDirModeGitRoot       = "git_root"
+	WorkDirModeNearestMarker = "nearest_marker"
+)
+
+// DefaultWorkDirMarkers are the files marking a project directory for
+// workdir_mode nearest_marker.
+var DefaultWorkDirMarkers = []string{
+	"go.mod", "package.json", "Cargo.toml", "pyproject.toml", "setup.py",
+	"pom.xml", "build.gradle", "build.gradle.kts", "Gemfile", "composer.json",
 }
 
+// Project environments commands run in.
+const (
+	DevEnvOff          = "off"
+	DevEnvAuto         = "auto"
+	DevEnvNix          = "nix"
+	DevEnvDevcontainer = "devcontainer"
+)
+
+// Command scheduling priorities.
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+)
+
 // ExecutionConfig contains execution settings.
 type ExecutionConfig struct {
 	// DefaultTimeout is the default command timeout
-	DefaultTimeout string `yaml:"default_timeout,omitempty"`
+	DefaultTimeout Duration `yaml:"default_timeout,omitempty"`
 
 	// MaxTimeout is the maximum allowed timeout
-	MaxTimeout string `yaml:"max_timeout,omitempty"`
+	MaxTimeout Duration `yaml:"max_timeout,omitempty"`
 
 	// MaxConcurrent limits concurrent command executions
 	MaxConcurrent int `yaml:"max_concurrent,omitempty"`
 
+	// MaxQueue limits the commands waiting for one of the MaxConcurrent
+	// slots; further commands fail right away. Zero for no limit
+	MaxQueue int `yaml:"max_queue,omitempty"`
+
 	// MaxOutputSize limits the output size in bytes
-	MaxOutputSize int64 `yaml:"max_output_size,omitempty"`
+	MaxOutputSize ByteSize `yaml:"max_output_size,omitempty"`
+
+	// SpillThreshold is the output size in bytes kept in memory; output
+	// beyond it is streamed to a file in SpillDir. Zero keeps all output
+	// in memory
+	SpillThreshold ByteSize `yaml:"spill_threshold,omitempty"`
+
+	// SpillDir holds the output files of commands that exceeded
+	// SpillThreshold; defaults to a directory under the system temp
+	// directory
+	SpillDir string `yaml:"spill_dir,omitempty"`
+
+	// KillTimeout is the time to wait after SIGTERM before SIGKILL; zero
+	// kills right away
+	KillTimeout Duration `yaml:"kill_timeout,omitempty"`
+
+	// WorkDirCacheTTL is how long a validated working directory is
+	// remembered, so commands run in the same directory are not checked
+	// again each time. Zero disables the cache
+	WorkDirCacheTTL Duration `yaml:"workdir_cache_ttl,omitempty"`
+
+	// LoginShellEnv runs commands in the environment of the user's login
+	// shell ($SHELL -l), taken once at startup, so PATH and LANG are what
+	// the user's profile sets them to. Not supported on Windows
+	LoginShellEnv bool `yaml:"login_shell_env,omitempty"`
+
+	// LoginShellEnvRefresh takes the login shell environment again in the
+	// background once it is older, to pick up profile changes. Zero keeps
+	// the environment taken at startup
+	LoginShellEnvRefresh Duration `yaml:"login_shell_env_refresh,omitempty"`
+
+	// DevEnvironment runs commands in the environment of the project
+	// around their working directory: off (the default), auto, nix or
+	// devcontainer. nix runs them with "nix develop -c" where a flake.nix
+	// is found, devcontainer with "devcontainer exec" where a
+	// .devcontainer is found, and auto with whichever is found first
+	DevEnvironment string `yaml:"dev_environment,omitempty"`
+
+	// Toolchains pin tool versions per directory: commands run in a
+	// pinned directory find the pinned binaries first in PATH
+	Toolchains []Toolchain `yaml:"toolchains,omitempty"`
+
+	// LockDir holds the workdir lock files of mutating commands; defaults
+	// to a directory under the system temp directory
+	LockDir string `yaml:"lock_dir,omitempty"`
+
+	// MaxTrackedFiles limits the files scanned when tracking changes
+	MaxTrackedFiles int `yaml:"max_tracked_files,omitempty"`
+
+	// MaxReportedChanges limits the changed files reported per run
+	MaxReportedChanges int `yaml:"max_reported_changes,omitempty"`
+
+	// Summary digests outputs too large to read in full
+	Summary OutputSummaryConfig `yaml:"summary,omitempty"`
+
+	// Preflight checks the disk space and binary of build commands before
+	// running them
+	Preflight PreflightConfig `yaml:"preflight,omitempty"`
+}
+
+// PreflightConfig contains the checks run before configured commands of
+// some categories, so builds and installs fail before they start rather
+// than half way through.
+type PreflightConfig struct {
+	// Categories are the command categories checked; empty disables the
+	// checks
+	Categories []string `yaml:"categories,omitempty"`
+
+	// MinFreeDisk is the free space required on the filesystem of the
+	// working directory. Zero disables the check
+	MinFreeDisk ByteSize `yaml:"min_free_disk,omitempty"`
+
+	// MinFreeTemp is the free space required on the filesystem of the
+	// temporary directory. Zero disables the check
+	MinFreeTemp ByteSize `yaml:"min_free_temp,omitempty"`
+}
+
+// OutputSummaryConfig contains the settings of the digest added to the
+// result of commands whose output exceeds a threshold.
+type OutputSummaryConfig struct {
+	// Threshold is the size in bytes of a stream from which its digest is
+	// added to the result. Zero disables digests
+	Threshold ByteSize `yaml:"threshold,omitempty"`
+
+	// TailLines is the number of last lines kept
+	TailLines int `yaml:"tail_lines,omitempty"`
 
-	// KillTimeout is the time to wait after SIGTERM before SIGKILL
-	KillTimeout string `yaml:"kill_timeout,omitempty"`
+	// MaxMatches limits the error and warning lines kept, each
+	MaxMatches int `yaml:"max_matches,omitempty"`
+
+	// ErrorPatterns and WarnPatterns are regular expressions matching
+	// error and warning lines; defaults match common words such as
+	// "error", "failed" and "warning"
+	ErrorPatterns []string `yaml:"error_patterns,omitempty"`
+	WarnPatterns  []string `yaml:"warn_patterns,omitempty"`
 }
 
 // LoggingConfig contains logging settings.
@@ -115,6 +520,29 @@ type LoggingConfig struct {
 
 	// IncludeSource includes source file information
 	IncludeSource bool `yaml:"include_source,omitempty"`
+
+	// DebugSampleRate logs only every Nth debug line; 0 or 1 logs all
+	DebugSampleRate int `yaml:"debug_sample_rate,omitempty"`
+
+	// RepeatInterval suppresses warnings and errors repeating the same
+	// message within the interval, such as "10s"; empty logs every repeat
+	RepeatInterval Duration `yaml:"repeat_interval,omitempty"`
+
+	// MaxLineLength cuts log lines longer than this many bytes; 0 leaves
+	// them whole
+	MaxLineLength int `yaml:"max_line_length,omitempty"`
+
+	// LogProtocol logs every JSON-RPC message exchanged with the client,
+	// with secrets masked and bodies truncated, to ProtocolFile
+	LogProtocol bool `yaml:"log_protocol,omitempty"`
+
+	// ProtocolFile is the JSON lines file protocol traffic is logged to;
+	// defaults to protocol.jsonl under the user cache directory
+	ProtocolFile string `yaml:"protocol_file,omitempty"`
+
+	// ProtocolMaxBody limits the bytes of each message body logged; 0
+	// logs no bodies
+	ProtocolMaxBody int `yaml:"protocol_max_body,omitempty"`
 }
 
 // DiscoveryConfig contains command discovery settings.
@@ -130,281 +558,1944 @@ type DiscoveryConfig struct {
 
 	// CommonCommands to prioritize in discovery
 	CommonCommands []string `yaml:"common_commands,omitempty"`
-}
 
-// Default returns a default configuration.
-func Default() *Config {
-	return &Config{
-		App:       "simple-mcp-runner",
-		Version:   "1.0",
-		Transport: "stdio",
-		Security: SecurityConfig{
-			MaxCommandLength:      1000,
-			DisableShellExpansion: true,
-			BlockedCommands: []string{
-				"rm", "dd", "mkfs", "fdisk", "shutdown", "reboot",
-				"systemctl", "service", "kill", "killall", "pkill",
-			},
-		},
-		Execution: ExecutionConfig{
-			DefaultTimeout: "30s",
-			MaxTimeout:     "5m",
-			MaxConcurrent:  10,
-			MaxOutputSize:  10 * 1024 * 1024, // 10MB
-			KillTimeout:    "5s",
-		},
-		Logging: LoggingConfig{
-			Level:  "info",
-			Format: "text",
-			Output: "stderr",
-		},
-		Discovery: DiscoveryConfig{
-			MaxResults: 100,
-			CommonCommands: []string{
-				"ls", "cat", "grep", "find", "git", "npm", "go",
-				"python", "node", "curl", "wget", "echo", "pwd",
-			},
-		},
-	}
+	// IndexFile persists the executables found in each search path, so
+	// discovery is fast right after a restart; defaults to a file under
+	// the user cache directory
+	IndexFile string `yaml:"index_file,omitempty"`
+
+	// DisableIndex keeps the index in memory only
+	DisableIndex bool `yaml:"disable_index,omitempty"`
+
+	// DisableWatch stops the server from watching PATH and
+	// AdditionalPaths to keep the index up to date; they are then
+	// revalidated by their modification time
+	DisableWatch bool `yaml:"disable_watch,omitempty"`
+
+	// Knowledge adds or replaces the usage examples and common flags
+	// returned with command descriptions, by command name
+	Knowledge map[string]CommandKnowledge `yaml:"knowledge,omitempty"`
 }
 
-// LoadFromFile loads configuration from a file.
-func LoadFromFile(filename string) (*Config, error) {
-	// Start with defaults
-	cfg := Default()
+// CommandKnowledge documents how a command is typically invoked.
+type CommandKnowledge struct {
+	// UsageExamples are complete invocations, e.g. "tar -czf out.tar.gz dir"
+	UsageExamples []string `yaml:"usage_examples,omitempty"`
 
-	// Check if file exists
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		return nil, apperrors.ConfigurationError("config file not found: " + filename)
-	}
+	// CommonFlags are the flags most invocations need
+	CommonFlags []CommandFlag `yaml:"common_flags,omitempty"`
+}
 
-	// Read file
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to read config file")
-	}
+// CommandFlag describes a command-line flag.
+type CommandFlag struct {
+	Flag        string `yaml:"flag"`
+	Description string `yaml:"description,omitempty"`
+}
 
-	// Parse YAML
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to parse YAML")
-	}
+// HistoryConfig contains execution history settings.
+type HistoryConfig struct {
+	// Path is a JSON lines file used to persist history across restarts.
+	// When empty, history is only kept in memory.
+	Path string `yaml:"path,omitempty"`
 
-	// Validate
-	if err := cfg.Validate(); err != nil {
-		return nil, err
-	}
+	// MaxEntries limits the number of retained execution records
+	MaxEntries int `yaml:"max_entries,omitempty"`
 
-	return cfg, nil
+	// SigningKey is a PEM encoded ed25519 private key; when set, records
+	// are signed and the receipt is included in results and history
+	SigningKey string `yaml:"signing_key,omitempty"`
 }
 
-// LoadFromBytes loads configuration from bytes.
-func LoadFromBytes(data []byte) (*Config, error) {
-	cfg := Default()
+// WatchConfig contains file watching settings.
+type WatchConfig struct {
+	// Debounce is how long changes must settle before they are reported
+	Debounce Duration `yaml:"debounce,omitempty"`
 
-	// Parse YAML
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to parse YAML")
-	}
+	// MaxTriggersPerMinute limits how often a watch may run its command
+	MaxTriggersPerMinute int `yaml:"max_triggers_per_minute,omitempty"`
 
-	// Validate
-	if err := cfg.Validate(); err != nil {
-		return nil, err
-	}
+	// MaxWatches limits the number of active watches
+	MaxWatches int `yaml:"max_watches,omitempty"`
 
-	return cfg, nil
+	// MaxDirectories limits the directories a recursive watch may register
+	MaxDirectories int `yaml:"max_directories,omitempty"`
 }
 
-// Validate validates the configuration.
-func (c *Config) Validate() error {
-	// Validate app name
-	if c.App == "" {
-		return apperrors.ValidationError("app name is required", "app")
-	}
+// TailConfig contains settings for the tail_file tool.
+type TailConfig struct {
+	// MaxLines limits the lines returned by one call, and the lines it
+	// follows
+	MaxLines int `yaml:"max_lines,omitempty"`
 
-	if len(c.App) > 100 {
-		return apperrors.ValidationError("app name too long (max 100 chars)", "app")
-	}
+	// MaxFollow limits how long one call follows a file (e.g. "5m")
+	MaxFollow Duration `yaml:"max_follow,omitempty"`
+}
 
-	// Validate transport
-	if c.Transport != "stdio" {
-		return apperrors.ValidationError("only 'stdio' transport is supported", "transport")
-	}
+// ProcessConfig contains process inspection settings.
+type ProcessConfig struct {
+	// AllUsers allows listing and inspecting processes owned by other users
+	AllUsers bool `yaml:"all_users,omitempty"`
 
-	// Validate commands
-	seen := make(map[string]bool)
-	for i, cmd := range c.Commands {
-		if err := c.validateCommand(cmd, i); err != nil {
-			return err
-		}
+	// MaxResults limits the number of processes returned by a listing
+	MaxResults int `yaml:"max_results,omitempty"`
+}
 
-		if seen[cmd.Name] {
-			return apperrors.ValidationError("duplicate command name: "+cmd.Name, "commands")
-		}
-		seen[cmd.Name] = true
-	}
+// TransferConfig contains file download and read settings.
+type TransferConfig struct {
+	// MaxDownloadSize limits the size of downloaded files in bytes
+	MaxDownloadSize ByteSize `yaml:"max_download_size,omitempty"`
 
-	// Validate security config
-	if err := c.validateSecurity(); err != nil {
-		return err
-	}
+	// MaxChunkSize limits the bytes returned by a single file read
+	MaxChunkSize ByteSize `yaml:"max_chunk_size,omitempty"`
 
-	// Validate execution config
-	if err := c.validateExecution(); err != nil {
-		return err
-	}
+	// AllowedSchemes lists URL schemes downloads may use
+	AllowedSchemes []string `yaml:"allowed_schemes,omitempty"`
 
-	// Validate logging config
-	if err := c.validateLogging(); err != nil {
-		return err
-	}
+	// DownloadEnabled registers the download_file tool
+	DownloadEnabled bool `yaml:"download_enabled,omitempty"`
 
-	return nil
-}
+	// AllowedHosts restricts downloads to these hosts; a leading "*."
+	// matches subdomains. No host is allowed when empty
+	AllowedHosts []string `yaml:"allowed_hosts,omitempty"`
 
-func (c *Config) validateCommand(cmd Command, index int) error {
-	field := "commands[" + string(rune(index)) + "]"
+	// Timeout limits the duration of a download
+	Timeout Duration `yaml:"timeout,omitempty"`
+}
 
-	// Validate name
-	if cmd.Name == "" {
-		return apperrors.ValidationError("command name is required", field+".name")
-	}
+// HTTPConfig contains settings for the http_request tool, which calls
+// HTTP APIs on allowed hosts instead of curl.
+type HTTPConfig struct {
+	// Enabled registers the http_request tool
+	Enabled bool `yaml:"enabled,omitempty"`
 
-	if !isValidCommandName(cmd.Name) {
-		return apperrors.ValidationError(
-			"command name must be alphanumeric with underscores (1-50 chars)",
-			field+".name",
-		)
-	}
+	// AllowedHosts lists the hosts requests may go to; a leading "*."
+	// matches subdomains. No host is allowed when empty
+	AllowedHosts []string `yaml:"allowed_hosts,omitempty"`
 
-	// Validate description
-	if cmd.Description == "" {
-		return apperrors.ValidationError("command description is required", field+".description")
-	}
+	// AllowedMethods lists the HTTP methods requests may use
+	AllowedMethods []string `yaml:"allowed_methods,omitempty"`
 
-	if len(cmd.Description) > 500 {
-		return apperrors.ValidationError("command description too long (max 500 chars)", field+".description")
-	}
+	// AllowHTTP permits plain http URLs; secret headers are never sent
+	// over them
+	AllowHTTP bool `yaml:"allow_http,omitempty"`
 
-	// Validate command
-	if cmd.Command == "" {
-		return apperrors.ValidationError("command is required", field+".command")
-	}
+	// MaxRequestSize limits request bodies in bytes
+	MaxRequestSize ByteSize `yaml:"max_request_size,omitempty"`
 
-	// Validate timeout if specified
-	if cmd.Timeout != "" {
-		if _, err := time.ParseDuration(cmd.Timeout); err != nil {
-			return apperrors.ValidationError("invalid timeout format: "+err.Error(), field+".timeout")
-		}
-	}
+	// MaxResponseSize limits the response body returned in bytes; longer
+	// bodies are truncated
+	MaxResponseSize ByteSize `yaml:"max_response_size,omitempty"`
 
-	// Validate workdir if specified
-	if cmd.WorkDir != "" {
-		if !filepath.IsAbs(cmd.WorkDir) {
-			return apperrors.ValidationError("workdir must be an absolute path", field+".workdir")
-		}
-	}
+	// Timeout limits the duration of a request
+	Timeout Duration `yaml:"timeout,omitempty"`
 
-	return nil
+	// SecretHeaders are added to requests to matching hosts, so tokens
+	// reach APIs without passing through the model
+	SecretHeaders []SecretHeader `yaml:"secret_headers,omitempty"`
 }
 
-func (c *Config) validateSecurity() error {
-	// Validate max command length
-	if c.Security.MaxCommandLength < 0 {
-		return apperrors.ValidationError("max_command_length cannot be negative", "security.max_command_length")
-	}
+// SecretHeader is a header whose value is read from the server
+// environment and added to requests to some hosts.
+type SecretHeader struct {
+	// Hosts the header is sent to, matched like HTTPConfig.AllowedHosts
+	Hosts []string `yaml:"hosts"`
 
-	// Validate allowed paths
-	for i, path := range c.Security.AllowedPaths {
-		if !filepath.IsAbs(path) {
-			return apperrors.ValidationError(
-				"allowed_path must be absolute: "+path,
-				"security.allowed_paths["+string(rune(i))+"]",
-			)
-		}
-	}
+	// Header is the header name, such as Authorization
+	Header string `yaml:"header"`
 
-	return nil
+	// Env names the environment variable holding the value
+	Env string `yaml:"env"`
+
+	// Prefix is prepended to the value, such as "Bearer "
+	Prefix string `yaml:"prefix,omitempty"`
 }
 
-func (c *Config) validateExecution() error {
-	// Validate timeouts
-	if c.Execution.DefaultTimeout != "" {
-		if _, err := time.ParseDuration(c.Execution.DefaultTimeout); err != nil {
-			return apperrors.ValidationError(
-				"invalid default_timeout: "+err.Error(),
-				"execution.default_timeout",
-			)
-		}
-	}
+// ArchiveConfig contains archive creation and extraction limits.
+type ArchiveConfig struct {
+	// MaxEntries limits the number of entries in an archive
+	MaxEntries int `yaml:"max_entries,omitempty"`
 
-	if c.Execution.MaxTimeout != "" {
-		maxDur, err := time.ParseDuration(c.Execution.MaxTimeout)
-		if err != nil {
-			return apperrors.ValidationError(
-				"invalid max_timeout: "+err.Error(),
-				"execution.max_timeout",
-			)
-		}
+	// MaxSize limits the total uncompressed size in bytes
+	MaxSize ByteSize `yaml:"max_size,omitempty"`
+}
+
+// CommandCheckConfig contains settings for the checks of configured
+// commands: that their binaries exist and are executable and their working
+// directories exist.
+type CommandCheckConfig struct {
+	// Disabled skips the checks when commands are registered
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// DisableBroken does not register commands that fail the checks as
+	// tools, instead of only logging the problems
+	DisableBroken bool `yaml:"disable_broken,omitempty"`
+}
+
+// DiskUsageConfig contains settings for the analyze_disk_usage tool.
+type DiskUsageConfig struct {
+	// MaxEntries limits the files and directories visited by one analysis
+	MaxEntries int `yaml:"max_entries,omitempty"`
+
+	// MaxResults limits the number of largest files and directories
+	// returned
+	MaxResults int `yaml:"max_results,omitempty"`
+}
+
+// NotificationConfig contains desktop notification settings.
+type NotificationConfig struct {
+	// Disabled turns off the notify_user tool
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// MaxPerMinute limits how many notifications may be shown per minute
+	MaxPerMinute int `yaml:"max_per_minute,omitempty"`
+}
+
+// BackupConfig contains settings for the backups that let file-mutating
+// tools be undone.
+type BackupConfig struct {
+	// Disabled turns off backups and the undo_last_change tool
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// Dir holds the backups; defaults to a directory under the user cache
+	// directory
+	Dir string `yaml:"dir,omitempty"`
+
+	// MaxGenerations limits the number of retained changes
+	MaxGenerations int `yaml:"max_generations,omitempty"`
+
+	// MaxAge is how long backups are kept (e.g. "168h")
+	MaxAge Duration `yaml:"max_age,omitempty"`
+
+	// MaxFileSize limits the size of a file that is backed up; larger
+	// files are changed without a backup
+	MaxFileSize ByteSize `yaml:"max_file_size,omitempty"`
+}
+
+// TrashConfig contains settings for the trash delete_path moves files
+// into.
+type TrashConfig struct {
+	// Disabled turns off the delete_path tool
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// Dir holds deleted files; defaults to a directory under the user
+	// cache directory
+	Dir string `yaml:"dir,omitempty"`
+
+	// MaxAge is how long deleted paths are kept (e.g. "720h")
+	MaxAge Duration `yaml:"max_age,omitempty"`
+
+	// MaxSize limits the bytes kept in the trash; the oldest entries are
+	// purged first, but never the most recent one
+	MaxSize ByteSize `yaml:"max_size,omitempty"`
+}
+
+// GitSnapshotConfig contains settings for the git snapshots taken before
+// risky commands.
+type GitSnapshotConfig struct {
+	// Enabled snapshots the working directory of risky commands; commands
+	// can override it with git_snapshot
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// Keep limits the number of snapshot refs retained per repository
+	Keep int `yaml:"keep,omitempty"`
+}
+
+// ApprovalConfig contains settings for operator approvals.
+type ApprovalConfig struct {
+	// File is the JSON lines log of approval requests and decisions, shared
+	// by the server and the approvals command; defaults to a file under the
+	// user cache directory
+	File string `yaml:"file,omitempty"`
+
+	// Expiry is how long a request can wait for approval and, once
+	// approved, to be run
+	Expiry Duration `yaml:"expiry,omitempty"`
+
+	// Operators may approve and reject requests. Decisions are signed
+	// with the operator's ed25519 key and only count when the signature
+	// verifies, so two approvals take the keys of two operators
+	Operators []ApprovalOperator `yaml:"operators,omitempty"`
+
+	// KeyFile is the PEM encoded ed25519 private key the approvals
+	// command, and the control API for the user running the server, sign
+	// decisions with; defaults to operator.pem under the user config
+	// directory
+	KeyFile string `yaml:"key_file,omitempty"`
+}
+
+// ApprovalOperator is an operator who may decide approval requests.
+type ApprovalOperator struct {
+	// Name identifies the operator in the audit trail
+	Name string `yaml:"name"`
+
+	// PublicKey is the PEM encoded ed25519 public key, as printed by
+	// "approvals keygen", the operator's decisions are signed with
+	PublicKey string `yaml:"public_key"`
+}
+
+func (c *Config) validateApprovals() error {
+	if c.Approvals.Expiry < 0 {
+		return apperrors.ValidationError("expiry cannot be negative", "approvals.expiry")
+	}
+
+	seen := make(map[string]bool)
+	for i, op := range c.Approvals.Operators {
+		field := fmt.Sprintf("approvals.operators[%d]", i)
+		if op.Name == "" {
+			return apperrors.ValidationError("operator name is required", field+".name")
+		}
+		if seen[op.Name] {
+			return apperrors.ValidationError(fmt.Sprintf("duplicate operator %q", op.Name), field+".name")
+		}
+		seen[op.Name] = true
+		if op.PublicKey == "" {
+			return apperrors.ValidationError(fmt.Sprintf("operator %s: public_key is required", op.Name), field+".public_key")
+		}
+	}
+	if len(c.Approvals.Operators) == 1 {
+		return apperrors.ValidationError("at least two operators are required, as runs need the approval of two", "approvals.operators")
+	}
+	return nil
+}
+
+// ServerConfig contains settings for the MCP server.
+type ServerConfig struct {
+	// ToolPrefix is prepended to the names of all tools, built-in and
+	// configured, so that several servers used by one client do not clash,
+	// e.g. "runner_" exposes execute_command as runner_execute_command
+	ToolPrefix string `yaml:"tool_prefix,omitempty"`
+
+	// Locale of tool descriptions, policy denials and CLI output: one of
+	// Locales, or "auto" to follow LC_ALL, LC_MESSAGES and LANG. Defaults
+	// to English; the SIMPLE_MCP_RUNNER_LOCALE environment variable
+	// overrides it
+	Locale string `yaml:"locale,omitempty"`
+
+	// PageSize limits the tools returned by one page of a tool listing;
+	// clients fetch the rest page by page. Defaults to 1000
+	PageSize int `yaml:"page_size,omitempty"`
+
+	// DefaultToolGroups are the tool groups selected when a session
+	// starts; commands of other groups are hidden until select_toolset
+	// selects their group. Commands in no group are always listed
+	DefaultToolGroups []string `yaml:"default_tool_groups,omitempty"`
+
+	// WelcomeMessage sends clients a summary of the configuration as a log
+	// message once they enable logging
+	WelcomeMessage bool `yaml:"welcome_message,omitempty"`
+
+	// EventLogSize is how many server events, such as tools registered
+	// and executions denied, the runner://events resource keeps. Defaults
+	// to 200
+	EventLogSize int `yaml:"event_log_size,omitempty"`
+
+	// SessionOutputBudget caps the bytes of tool results returned to a
+	// session. Once it is used up, command output is left out of results
+	// and read with get_output_page instead; unlimited when zero
+	SessionOutputBudget ByteSize `yaml:"session_output_budget,omitempty"`
+
+	// CompressResults compresses tool results and resource contents over
+	// this many bytes for clients that offer gzip or zstd; disabled when
+	// zero
+	CompressResults ByteSize `yaml:"compress_results,omitempty"`
+
+	// CommandPrompts also exposes configured commands as MCP prompts,
+	// command templates whose workdir and args clients that support
+	// completion can complete
+	CommandPrompts bool `yaml:"command_prompts,omitempty"`
+
+	// Tools limits the built-in tools registered to the ones listed.
+	// Configured commands and script tools are always registered; empty
+	// registers every built-in tool the configuration enables
+	Tools []string `yaml:"tools,omitempty"`
+}
+
+// CatalogConfig contains settings for fetching a remote command catalog.
+type CatalogConfig struct {
+	// URL of a YAML document with a commands list in the format of the
+	// configuration file; must be https
+	URL string `yaml:"url,omitempty"`
+
+	// SignatureURL is the base64 ed25519 signature of the catalog, as
+	// written by "catalog sign"; defaults to URL with ".sig" appended
+	SignatureURL string `yaml:"signature_url,omitempty"`
+
+	// PublicKey is a PEM encoded ed25519 public key the catalog must be
+	// signed with
+	PublicKey string `yaml:"public_key,omitempty"`
+
+	// Refresh is how often the catalog is fetched again while the server
+	// runs (e.g. "1h"); it is only fetched at startup when empty
+	Refresh Duration `yaml:"refresh,omitempty"`
+
+	// CacheFile keeps the last verified catalog and its ETag, for
+	// conditional requests and for starting while the URL is unreachable;
+	// defaults to a file under the user cache directory
+	CacheFile string `yaml:"cache_file,omitempty"`
+}
+
+// Plugin is a WebAssembly module run in a sandbox for the commands that
+// list it. The module is a WASI command reading a JSON request from stdin
+// and writing a JSON response to stdout; it has no access to the file
+// system, network, environment or clock of the host.
+type Plugin struct {
+	// Name identifies the plugin in the plugins of commands
+	Name string `yaml:"name"`
+
+	// Path is the absolute path of the .wasm module
+	Path string `yaml:"path"`
+
+	// Hooks are what the plugin is called for: "policy" before a command
+	// runs, to allow or deny it, and "output" after it ran, to parse or
+	// redact its output
+	Hooks []string `yaml:"hooks"`
+
+	// Timeout limits a single call of the plugin; defaults to 1s
+	Timeout Duration `yaml:"timeout,omitempty"`
+
+	// MaxMemory limits the memory of the plugin in bytes; defaults to 16MiB
+	MaxMemory ByteSize `yaml:"max_memory,omitempty"`
+
+	// Config is passed to the plugin with every request
+	Config map[string]string `yaml:"config,omitempty"`
+}
+
+// Plugin hooks.
+const (
+	PluginHookPolicy = "policy"
+	PluginHookOutput = "output"
+)
+
+// FindPlugin returns the plugin called name, or nil.
+func (c *Config) FindPlugin(name string) *Plugin {
+	for i := range c.Plugins {
+		if c.Plugins[i].Name == name {
+			return &c.Plugins[i]
+		}
+	}
+	return nil
+}
+
+// ScriptTool is a tool whose logic is a Starlark script. The script
+// defines main(params), which receives the tool arguments and returns the
+// tool result, and may call run() for configured commands and read_file().
+type ScriptTool struct {
+	// Name is the tool name
+	Name string `yaml:"name"`
+
+	// DisplayName is the title clients show for the tool
+	DisplayName string `yaml:"display_name,omitempty"`
+
+	// Description tells clients what the tool does
+	Description string `yaml:"description"`
+
+	// Params are the arguments the tool takes
+	Params []ScriptParam `yaml:"params,omitempty"`
+
+	// Script is the Starlark source defining main(params)
+	Script string `yaml:"script"`
+
+	// Timeout limits a call of the tool, including the commands it runs;
+	// defaults to 5m
+	Timeout Duration `yaml:"timeout,omitempty"`
+
+	// MaxSteps limits the Starlark computation steps of a call; defaults
+	// to 1000000
+	MaxSteps uint64 `yaml:"max_steps,omitempty"`
+}
+
+// ScriptParam is an argument of a script tool.
+type ScriptParam struct {
+	// Name is the argument name
+	Name string `yaml:"name"`
+
+	// Type is string, integer, number, boolean or array (of strings);
+	// defaults to string
+	Type string `yaml:"type,omitempty"`
+
+	// Description tells clients what the argument is for
+	Description string `yaml:"description,omitempty"`
+
+	// Required arguments must be given
+	Required bool `yaml:"required,omitempty"`
+}
+
+// ScriptParamTypes are the supported script tool argument types.
+var ScriptParamTypes = []string{"string", "integer", "number", "boolean", "array"}
+
+// ToolGroup is a named collection of configured commands.
+type ToolGroup struct {
+	// Name identifies the group
+	Name string `yaml:"name"`
+
+	// Description tells clients what the group's commands are for
+	Description string `yaml:"description,omitempty"`
+
+	// Commands are the names of the configured commands in the group
+	Commands []string `yaml:"commands"`
+}
+
+// LocaleAuto selects the locale of the environment.
+const LocaleAuto = "auto"
+
+// Locales are the supported locales.
+var Locales = []string{"en", "es", "ja"}
+
+// SupportedLocale returns the supported locale of a locale name such as
+// "es", "es-MX" or "ja_JP.UTF-8", and whether there is one.
+func SupportedLocale(name string) (string, bool) {
+	base := strings.ToLower(name)
+	if i := strings.IndexAny(base, "-_.@"); i >= 0 {
+		base = base[:i]
+	}
+	for _, locale := range Locales {
+		if base == locale {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// UsageConfig contains settings for tool usage analytics.
+type UsageConfig struct {
+	// Enabled counts tool calls, command runs, validation failures and
+	// denial reasons for the "stats report" command
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// File holds the counts, accumulated across restarts; defaults to a
+	// file under the user cache directory
+	File string `yaml:"file,omitempty"`
+}
+
+// InstanceConfig contains settings for the instance lock.
+type InstanceConfig struct {
+	// Lock refuses to start a server while another one runs with the same
+	// configuration file, as they would share audit logs, history and
+	// workspaces
+	Lock bool `yaml:"lock,omitempty"`
+
+	// LockDir holds the lock files, one per configuration file; defaults to
+	// a directory under the system temporary directory
+	LockDir string `yaml:"lock_dir,omitempty"`
+}
+
+// ContainerConfig contains settings for the Docker container tools.
+// Containers are only touchable if their name or image is allowed.
+type ContainerConfig struct {
+	// Enabled registers list_containers, container_logs and
+	// exec_in_container
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// Host is the Docker daemon address, such as
+	// unix:///var/run/docker.sock; defaults to DOCKER_HOST or the local
+	// daemon
+	Host string `yaml:"host,omitempty"`
+
+	// AllowedContainers are the names of containers the tools may touch;
+	// entries may be globs
+	AllowedContainers []string `yaml:"allowed_containers,omitempty"`
+
+	// AllowedImages are the images whose containers the tools may touch,
+	// such as postgres or "ghcr.io/acme/*"; entries may be globs, and
+	// entries without a tag match every tag
+	AllowedImages []string `yaml:"allowed_images,omitempty"`
+
+	// AllowExec lets exec_in_container run commands in allowed
+	// containers. Commands are checked against security.blocked_commands
+	AllowExec bool `yaml:"allow_exec,omitempty"`
+
+	// MaxLogLines limits the lines container_logs returns
+	MaxLogLines int `yaml:"max_log_lines,omitempty"`
+}
+
+// TmuxConfig contains settings for the tmux session tools, which keep
+// interactive programs such as REPLs and dev servers running between
+// calls. The program a session starts is checked against the security
+// policy, but keys sent to it are not.
+type TmuxConfig struct {
+	// Enabled registers the tmux session tools
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// Socket names the tmux server the sessions run in, apart from the
+	// user's own sessions; defaults to simple-mcp-runner
+	Socket string `yaml:"socket,omitempty"`
+
+	// MaxSessions limits the sessions running at once
+	MaxSessions int `yaml:"max_sessions,omitempty"`
+
+	// MaxCaptureLines limits the lines capture_tmux_pane returns
+	MaxCaptureLines int `yaml:"max_capture_lines,omitempty"`
+
+	// KeepOnExit leaves the sessions running when the server stops;
+	// they are killed by default
+	KeepOnExit bool `yaml:"keep_on_exit,omitempty"`
+}
+
+// REPLConfig contains settings for the REPL session tools, which keep
+// interpreters such as python, node and psql running under a pseudo
+// terminal between calls.
+type REPLConfig struct {
+	// Enabled registers the REPL session tools
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// Interpreters lists the interpreters start_repl may launch
+	Interpreters []Interpreter `yaml:"interpreters,omitempty"`
+
+	// MaxSessions limits the sessions running at once
+	MaxSessions int `yaml:"max_sessions,omitempty"`
+
+	// IdleTimeout stops sessions that receive no input for this long
+	IdleTimeout Duration `yaml:"idle_timeout,omitempty"`
+
+	// MaxLifetime stops sessions this long after they started
+	MaxLifetime Duration `yaml:"max_lifetime,omitempty"`
+
+	// MaxMemory limits the data memory of an interpreter in bytes, on
+	// Linux only; zero means no limit
+	MaxMemory ByteSize `yaml:"max_memory,omitempty"`
+
+	// MaxOutputSize limits the output returned for one input in bytes;
+	// the end of longer output is kept
+	MaxOutputSize ByteSize `yaml:"max_output_size,omitempty"`
+
+	// ReadTimeout is how long send_to_repl waits for the prompt by default
+	ReadTimeout Duration `yaml:"read_timeout,omitempty"`
+}
+
+// Interpreter is a program start_repl may launch.
+type Interpreter struct {
+	// Name identifies the interpreter in start_repl
+	Name string `yaml:"name"`
+
+	// Command is the interpreter binary, checked against the security
+	// policy
+	Command string `yaml:"command"`
+
+	// Args are passed before the arguments given to start_repl
+	Args []string `yaml:"args,omitempty"`
+
+	// Env adds KEY=value variables, such as ones disabling colors
+	Env []string `yaml:"env,omitempty"`
+
+	// Prompt is a regular expression matching the last line of output
+	// when the interpreter waits for input, including continuation
+	// prompts
+	Prompt string `yaml:"prompt"`
+}
+
+// Schedule runs a configured command on a recurring basis.
+type Schedule struct {
+	// Name identifies the schedule
+	Name string `yaml:"name"`
+
+	// Cron is a five-field cron expression (minute hour day month weekday)
+	// or one of the @hourly, @daily, @weekly, @monthly, @yearly macros
+	Cron string `yaml:"cron"`
+
+	// Command is the name of a configured command to run
+	Command string `yaml:"command"`
+
+	// WorkDir is the working directory for scheduled runs of commands
+	// that do not set their own
+	WorkDir string `yaml:"workdir,omitempty"`
+}
+
+// Default returns a default configuration.
+func Default() *Config {
+	return &Config{
+		App:       "simple-mcp-runner",
+		Version:   "1.0",
+		Transport: "stdio",
+		Security: SecurityConfig{
+			MaxCommandLength:      1000,
+			DisableShellExpansion: true,
+			ResolveSymlinks:       true,
+			BlockedCommands: []string{
+				"rm", "dd", "mkfs", "fdisk", "shutdown", "reboot",
+				"systemctl", "service", "kill", "killall", "pkill",
+			},
+		},
+		Execution: ExecutionConfig{
+			DefaultTimeout:     Duration(30 * time.Second),
+			MaxTimeout:         Duration(5 * time.Minute),
+			MaxConcurrent:      10,
+			MaxQueue:           50,
+			MaxOutputSize:      10 * 1024 * 1024, // 10MB
+			KillTimeout:        Duration(5 * time.Second),
+			WorkDirCacheTTL:    Duration(2 * time.Second),
+			MaxTrackedFiles:    10000,
+			MaxReportedChanges: 100,
+			Summary: OutputSummaryConfig{
+				Threshold:  64 * 1024, // 64KB
+				TailLines:  20,
+				MaxMatches: 20,
+			},
+			Preflight: PreflightConfig{
+				Categories:  []string{CategoryBuild},
+				MinFreeDisk: 1 << 30,   // 1GiB
+				MinFreeTemp: 512 << 20, // 512MiB
+			},
+		},
+		Logging: LoggingConfig{
+			Level:           "info",
+			Format:          "text",
+			Output:          "stderr",
+			ProtocolMaxBody: 1024,
+		},
+		Discovery: DiscoveryConfig{
+			MaxResults: 100,
+			CommonCommands: []string{
+				"ls", "cat", "grep", "find", "git", "npm", "go",
+				"python", "node", "curl", "wget", "echo", "pwd",
+			},
+		},
+		History: HistoryConfig{
+			MaxEntries: 1000,
+		},
+		Watch: WatchConfig{
+			Debounce:             Duration(500 * time.Millisecond),
+			MaxTriggersPerMinute: 6,
+			MaxWatches:           10,
+			MaxDirectories:       1000,
+		},
+		Tail: TailConfig{
+			MaxLines:  1000,
+			MaxFollow: Duration(5 * time.Minute),
+		},
+		Processes: ProcessConfig{
+			MaxResults: 200,
+		},
+		Containers: ContainerConfig{
+			MaxLogLines: 500,
+		},
+		Tmux: TmuxConfig{
+			Socket:          "simple-mcp-runner",
+			MaxSessions:     10,
+			MaxCaptureLines: 1000,
+		},
+		REPL: REPLConfig{
+			Interpreters: []Interpreter{
+				{Name: "python", Command: "python3", Args: []string{"-i", "-q"}, Env: []string{"PYTHON_BASIC_REPL=1"}, Prompt: `^(>>>|\.\.\.) $`},
+				{Name: "node", Command: "node", Args: []string{"-i"}, Env: []string{"NODE_NO_READLINE=1", "NODE_DISABLE_COLORS=1"}, Prompt: `^(>|\.\.\.) $`},
+				{Name: "psql", Command: "psql", Args: []string{"--no-psqlrc", "--pset=pager=off"}, Prompt: `^[^ ]*[=\-'"(*!^][#>] $`},
+			},
+			MaxSessions:   5,
+			IdleTimeout:   Duration(10 * time.Minute),
+			MaxLifetime:   Duration(1 * time.Hour),
+			MaxMemory:     1024 * 1024 * 1024, // 1GB
+			MaxOutputSize: 1024 * 1024,        // 1MB
+			ReadTimeout:   Duration(30 * time.Second),
+		},
+		Transfer: TransferConfig{
+			MaxDownloadSize: 100 * 1024 * 1024, // 100MB
+			MaxChunkSize:    1024 * 1024,       // 1MB
+			AllowedSchemes:  []string{"https"},
+			Timeout:         Duration(5 * time.Minute),
+		},
+		HTTP: HTTPConfig{
+			AllowedMethods:  []string{"GET", "HEAD"},
+			MaxRequestSize:  1024 * 1024, // 1MB
+			MaxResponseSize: 1024 * 1024, // 1MB
+			Timeout:         Duration(30 * time.Second),
+		},
+		Archive: ArchiveConfig{
+			MaxEntries: 10000,
+			MaxSize:    1024 * 1024 * 1024, // 1GB
+		},
+		DiskUsage: DiskUsageConfig{
+			MaxEntries: 200000,
+			MaxResults: 100,
+		},
+		Notifications: NotificationConfig{
+			MaxPerMinute: 3,
+		},
+		Backup: BackupConfig{
+			MaxGenerations: 20,
+			MaxAge:         Duration(168 * time.Hour),
+			MaxFileSize:    10 * 1024 * 1024, // 10MB
+		},
+		Trash: TrashConfig{
+			MaxAge:  Duration(720 * time.Hour),
+			MaxSize: 1024 * 1024 * 1024, // 1GB
+		},
+		GitSnapshot: GitSnapshotConfig{
+			Enabled: true,
+			Keep:    50,
+		},
+		Approvals: ApprovalConfig{
+			Expiry: Duration(1 * time.Hour),
+		},
+	}
+}
+
+// LoadFromFile loads configuration from a file.
+func LoadFromFile(filename string) (*Config, error) {
+	// Start with defaults
+	cfg := Default()
+
+	// Check if file exists
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil, apperrors.ConfigurationError("config file not found: " + filename)
+	}
+
+	// Read file
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to read config file")
+	}
+
+	// Parse YAML
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to parse YAML")
+	}
+
+	// Validate
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// LoadFromBytes loads configuration from bytes.
+func LoadFromBytes(data []byte) (*Config, error) {
+	cfg := Default()
+
+	// Parse YAML
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeConfiguration, "failed to parse YAML")
+	}
+
+	// Validate
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Validate validates the configuration.
+func (c *Config) Validate() error {
+	// Validate app name
+	if c.App == "" {
+		return apperrors.ValidationError("app name is required", "app")
+	}
+
+	if len(c.App) > 100 {
+		return apperrors.ValidationError("app name too long (max 100 chars)", "app")
+	}
+
+	// Validate transport
+	if c.Transport != "stdio" {
+		return apperrors.ValidationError("only 'stdio' transport is supported", "transport")
+	}
+
+	// Validate server config
+	if c.Server.ToolPrefix != "" && !isValidToolPrefix(c.Server.ToolPrefix) {
+		return apperrors.ValidationError(
+			"tool prefix must start with a letter and contain only letters, numbers and underscores (max 20 chars)",
+			"server.tool_prefix",
+		)
+	}
+
+	if c.Server.Locale != "" && c.Server.Locale != LocaleAuto {
+		if _, ok := SupportedLocale(c.Server.Locale); !ok {
+			return apperrors.ValidationError(
+				fmt.Sprintf("unsupported locale %q: must be auto or one of %s", c.Server.Locale, strings.Join(Locales, ", ")),
+				"server.locale",
+			)
+		}
+	}
+
+	if c.Server.PageSize < 0 {
+		return apperrors.ValidationError("page_size cannot be negative", "server.page_size")
+	}
+
+	if c.Server.EventLogSize < 0 {
+		return apperrors.ValidationError("event_log_size cannot be negative", "server.event_log_size")
+	}
+
+	if c.Server.SessionOutputBudget < 0 {
+		return apperrors.ValidationError("session_output_budget cannot be negative", "server.session_output_budget")
+	}
+
+	if c.Server.CompressResults < 0 {
+		return apperrors.ValidationError("compress_results cannot be negative", "server.compress_results")
+	}
+
+	for _, name := range c.Server.Tools {
+		if !slices.Contains(BuiltinTools, name) {
+			return apperrors.ValidationError("unknown built-in tool: "+name, "server.tools")
+		}
+	}
+
+	// Validate commands
+	seen := make(map[string]string)
+	for i, cmd := range c.Commands {
+		if err := c.validateCommand(cmd, i); err != nil {
+			return err
+		}
+
+		if other, ok := seen[cmd.ID()]; ok {
+			if other == cmd.Name {
+				return apperrors.ValidationError("duplicate command name: "+cmd.Name, "commands")
+			}
+			return apperrors.ValidationError(
+				fmt.Sprintf("command name %s collides with %s once hyphens and dots become underscores", cmd.Name, other),
+				"commands",
+			)
+		}
+		seen[cmd.ID()] = cmd.Name
+	}
+
+	// Validate tool groups
+	if err := c.validateToolGroups(); err != nil {
+		return err
+	}
+
+	// Validate plugins
+	if err := c.validatePlugins(); err != nil {
+		return err
+	}
+
+	// Validate script tools
+	if err := c.validateScriptTools(); err != nil {
+		return err
+	}
+
+	// Validate security config
+	if err := c.validateSecurity(); err != nil {
+		return err
+	}
+
+	// Validate execution config
+	if err := c.validateExecution(); err != nil {
+		return err
+	}
+
+	// Validate logging config
+	if err := c.validateLogging(); err != nil {
+		return err
+	}
+
+	// Validate telemetry config
+	if err := c.Telemetry.validate(); err != nil {
+		return err
+	}
+
+	// Validate history config
+	if c.History.MaxEntries < 0 {
+		return apperrors.ValidationError("max_entries cannot be negative", "history.max_entries")
+	}
+
+	// Validate schedules
+	if err := c.validateSchedules(); err != nil {
+		return err
+	}
+
+	// Validate watch config
+	if err := c.validateWatch(); err != nil {
+		return err
+	}
+
+	// Validate discovery config
+	for name, k := range c.Discovery.Knowledge {
+		for _, f := range k.CommonFlags {
+			if f.Flag == "" {
+				return apperrors.ValidationError("flag is required for common_flags of "+name, "discovery.knowledge")
+			}
+		}
+	}
+
+	// Validate tail config
+	if c.Tail.MaxLines < 0 {
+		return apperrors.ValidationError("max_lines cannot be negative", "tail.max_lines")
+	}
+	if c.Tail.MaxFollow < 0 {
+		return apperrors.ValidationError("max_follow cannot be negative", "tail.max_follow")
+	}
+
+	// Validate process config
+	if c.Processes.MaxResults < 0 {
+		return apperrors.ValidationError("max_results cannot be negative", "processes.max_results")
+	}
+
+	// Validate transfer config
+	if err := c.validateTransfer(); err != nil {
+		return err
+	}
+
+	// Validate HTTP request config
+	if err := c.validateHTTP(); err != nil {
+		return err
+	}
+
+	if err := c.Network.validate(); err != nil {
+		return apperrors.ValidationError(err.Error(), "network")
+	}
+
+	// Validate archive config
+	if c.Archive.MaxEntries < 0 {
+		return apperrors.ValidationError("max_entries cannot be negative", "archive.max_entries")
+	}
+	if c.Archive.MaxSize < 0 {
+		return apperrors.ValidationError("max_size cannot be negative", "archive.max_size")
+	}
+
+	// Validate disk usage config
+	if c.DiskUsage.MaxEntries < 0 {
+		return apperrors.ValidationError("max_entries cannot be negative", "disk_usage.max_entries")
+	}
+	if c.DiskUsage.MaxResults < 0 {
+		return apperrors.ValidationError("max_results cannot be negative", "disk_usage.max_results")
+	}
+
+	// Validate notification config
+	if c.Notifications.MaxPerMinute < 0 {
+		return apperrors.ValidationError("max_per_minute cannot be negative", "notifications.max_per_minute")
+	}
+
+	// Validate backup config
+	if err := c.validateBackup(); err != nil {
+		return err
+	}
+
+	// Validate trash config
+	if err := c.validateTrash(); err != nil {
+		return err
+	}
+
+	// Validate git snapshot config
+	if c.GitSnapshot.Keep < 0 {
+		return apperrors.ValidationError("keep cannot be negative", "git_snapshot.keep")
+	}
+
+	// Validate approval config
+	if err := c.validateApprovals(); err != nil {
+		return err
+	}
+
+	// Validate catalog config
+	if err := c.validateCatalog(); err != nil {
+		return err
+	}
+
+	// Validate container config
+	if err := c.validateContainers(); err != nil {
+		return err
+	}
+
+	// Validate tmux config
+	if err := c.validateTmux(); err != nil {
+		return err
+	}
+
+	// Validate REPL config
+	if err := c.validateREPL(); err != nil {
+		return err
+	}
+
+	// Validate fault injection config
+	if err := c.validateChaos(); err != nil {
+		return err
+	}
+
+	// Validate control API config
+	if err := c.validateControl(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Config) validateCommand(cmd Command, index int) error {
+	field := "commands[" + string(rune(index)) + "]"
+
+	// Validate name
+	if cmd.Name == "" {
+		return apperrors.ValidationError("command name is required", field+".name")
+	}
+
+	if !isValidToolName(cmd.Name) {
+		return apperrors.ValidationError(
+			"command name must start with a letter and contain only letters, digits, underscores, hyphens and dots (1-50 chars)",
+			field+".name",
+		)
+	}
+	if err := c.checkBuiltinClash("command", cmd.Name, field+".name"); err != nil {
+		return err
+	}
+	if err := validateDisplayName(cmd.DisplayName, field+".display_name"); err != nil {
+		return err
+	}
+
+	// Validate description
+	if cmd.Description == "" {
+		return apperrors.ValidationError("command description is required", field+".description")
+	}
+
+	if len(cmd.Description) > 500 {
+		return apperrors.ValidationError("command description too long (max 500 chars)", field+".description")
+	}
+
+	// Catch template syntax errors and unknown variables at load
+	if _, err := cmd.RenderDescription(DescriptionFacts{}); err != nil {
+		return apperrors.ValidationError("invalid description template: "+err.Error(), field+".description")
+	}
+
+	// Validate command
+	if cmd.Command == "" {
+		return apperrors.ValidationError("command is required", field+".command")
+	}
+
+	// Validate timeout if specified
+	if cmd.Timeout < 0 {
+		return apperrors.ValidationError("timeout cannot be negative", field+".timeout")
+	}
+	if cmd.MaxTimeout < 0 {
+		return apperrors.ValidationError("max_timeout cannot be negative", field+".max_timeout")
+	}
+	if cmd.MaxOutputSize < 0 {
+		return apperrors.ValidationError("max_output_size cannot be negative", field+".max_output_size")
+	}
+	switch cmd.Priority {
+	case "", PriorityLow, PriorityNormal, PriorityHigh:
+	default:
+		return apperrors.ValidationError("invalid priority (must be: low, normal, high)", field+".priority")
+	}
+	if err := cmd.validateIsolation(field); err != nil {
+		return err
+	}
+	if err := validatePatterns(cmd.SummaryErrorPatterns, field+".summary_error_patterns"); err != nil {
+		return err
+	}
+	if err := validatePatterns(cmd.SummaryWarnPatterns, field+".summary_warn_patterns"); err != nil {
+		return err
+	}
+
+	// Validate workdir if specified
+	if cmd.WorkDir != "" {
+		if !filepath.IsAbs(cmd.WorkDir) {
+			return apperrors.ValidationError("workdir must be an absolute path", field+".workdir")
+		}
+	}
+	switch cmd.WorkDirMode {
+	case "", WorkDirModeGitRoot, WorkDirModeNearestMarker:
+	default:
+		return apperrors.ValidationError("invalid workdir_mode (must be: git_root, nearest_marker)", field+".workdir_mode")
+	}
+	for _, marker := range cmd.WorkDirMarkers {
+		if marker == "" || filepath.IsAbs(marker) {
+			return apperrors.ValidationError("workdir_markers must be relative file names", field+".workdir_markers")
+		}
+	}
+	if len(cmd.ArgValues) > 0 && !cmd.AllowArgs {
+		return apperrors.ValidationError("arg_values requires allow_args", field+".arg_values")
+	}
+
+	return nil
+}
+
+func (c *Config) validateSecurity() error {
+	// Validate max command length
+	if c.Security.MaxCommandLength < 0 {
+		return apperrors.ValidationError("max_command_length cannot be negative", "security.max_command_length")
+	}
+
+	// Validate allowed paths
+	for i, path := range c.Security.AllowedPaths {
+		if !filepath.IsAbs(path) {
+			return apperrors.ValidationError(
+				"allowed_path must be absolute: "+path,
+				"security.allowed_paths["+string(rune(i))+"]",
+			)
+		}
+	}
+
+	// Validate denied paths
+	for _, path := range c.Security.DeniedPaths {
+		if !filepath.IsAbs(path) {
+			return apperrors.ValidationError("denied_path must be absolute: "+path, "security.denied_paths")
+		}
+	}
+
+	// Validate environment patterns
+	envPatterns := []struct {
+		field    string
+		patterns []string
+	}{
+		{"security.env_allow", c.Security.EnvAllow},
+		{"security.env_deny", c.Security.EnvDeny},
+		{"security.sensitive_env", c.Security.SensitiveEnv},
+	}
+	for _, env := range envPatterns {
+		for _, pattern := range env.patterns {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return apperrors.ValidationError("invalid pattern: "+pattern, env.field)
+			}
+		}
+	}
+
+	// Validate command patterns
+	commandLists := []struct {
+		field   string
+		entries []string
+	}{
+		{"security.allowed_commands", c.Security.AllowedCommands},
+		{"security.blocked_commands", c.Security.BlockedCommands},
+	}
+	for _, list := range commandLists {
+		for _, entry := range list.entries {
+			if _, err := parseCommandRule(entry); err != nil {
+				return apperrors.ValidationError(err.Error(), list.field)
+			}
+		}
+	}
+
+	// Validate conditions
+	conditionNames := make(map[string]bool)
+	for _, cond := range c.Security.Conditions {
+		if err := cond.validate(); err != nil {
+			return apperrors.ValidationError(err.Error(), "security.conditions")
+		}
+		if conditionNames[cond.Name] {
+			return apperrors.ValidationError("duplicate condition name: "+cond.Name, "security.conditions")
+		}
+		conditionNames[cond.Name] = true
+	}
+
+	// Validate CLI policies
+	for _, p := range c.Security.CLIPolicies {
+		if err := p.validate(); err != nil {
+			return apperrors.ValidationError(err.Error(), "security.cli_policies")
+		}
+	}
+
+	// Validate package policies
+	for _, p := range c.Security.PackagePolicies {
+		if err := p.validate(); err != nil {
+			return apperrors.ValidationError(err.Error(), "security.package_policies")
+		}
+	}
+
+	if err := c.Security.Screening.validate(); err != nil {
+		return apperrors.ValidationError(err.Error(), "security.screening")
+	}
+
+	if err := c.Security.DLP.validate(); err != nil {
+		return apperrors.ValidationError(err.Error(), "security.dlp")
+	}
+
+	// Validate tripwires
+	tripwireNames := make(map[string]bool)
+	for _, t := range c.Security.Tripwires {
+		if err := t.validate(); err != nil {
+			return apperrors.ValidationError(err.Error(), "security.tripwires")
+		}
+		if tripwireNames[t.Name] {
+			return apperrors.ValidationError("duplicate tripwire name: "+t.Name, "security.tripwires")
+		}
+		tripwireNames[t.Name] = true
+	}
+	if c.Security.TripwireWebhook != "" && !isWebhookURL(c.Security.TripwireWebhook) {
+		return apperrors.ValidationError("tripwire_webhook must be an https URL, or an http URL of a loopback host", "security.tripwire_webhook")
+	}
+
+	switch c.Security.CommandPrecedence {
+	case "", PrecedenceBlock, PrecedenceExplicitAllow:
+	default:
+		return apperrors.ValidationError("invalid command_precedence (must be: block, explicit_allow)", "security.command_precedence")
+	}
+
+	// Validate policy mode
+	switch c.Security.Policy {
+	case "", PolicyEnforce, PolicyLearn:
+	default:
+		return apperrors.ValidationError("invalid policy (must be: enforce, learn)", "security.policy")
+	}
+
+	return nil
+}
+
+func (c *Config) validateExecution() error {
+	// Validate timeouts
+	if c.Execution.DefaultTimeout <= 0 {
+		return apperrors.ValidationError(
+			"default_timeout must be positive",
+			"execution.default_timeout",
+		)
+	}
+
+	if c.Execution.MaxTimeout <= 0 {
+		return apperrors.ValidationError(
+			"max_timeout must be positive",
+			"execution.max_timeout",
+		)
+	}
+
+	// Ensure max timeout is reasonable
+	if c.Execution.MaxTimeout.Std() > 1*time.Hour {
+		return apperrors.ValidationError(
+			"max_timeout cannot exceed 1 hour",
+			"execution.max_timeout",
+		)
+	}
+
+	// Validate max concurrent
+	if c.Execution.MaxConcurrent < 0 {
+		return apperrors.ValidationError("max_concurrent cannot be negative", "execution.max_concurrent")
+	}
+	if c.Execution.MaxQueue < 0 {
+		return apperrors.ValidationError("max_queue cannot be negative", "execution.max_queue")
+	}
+
+	// Validate max output size
+	if c.Execution.MaxOutputSize < 0 {
+		return apperrors.ValidationError("max_output_size cannot be negative", "execution.max_output_size")
+	}
+	if c.Execution.WorkDirCacheTTL < 0 {
+		return apperrors.ValidationError(
+			"workdir_cache_ttl cannot be negative",
+			"execution.workdir_cache_ttl",
+		)
+	}
+	if c.Execution.SpillThreshold < 0 {
+		return apperrors.ValidationError("spill_threshold cannot be negative", "execution.spill_threshold")
+	}
+	if c.Execution.KillTimeout < 0 {
+		return apperrors.ValidationError("kill_timeout cannot be negative", "execution.kill_timeout")
+	}
+	if c.Execution.LoginShellEnvRefresh < 0 {
+		return apperrors.ValidationError(
+			"login_shell_env_refresh cannot be negative",
+			"execution.login_shell_env_refresh",
+		)
+	}
+	switch c.Execution.DevEnvironment {
+	case "", DevEnvOff, DevEnvAuto, DevEnvNix, DevEnvDevcontainer:
+	default:
+		return apperrors.ValidationError(
+			"invalid dev_environment (must be: off, auto, nix, devcontainer)",
+			"execution.dev_environment",
+		)
+	}
+	for _, tc := range c.Execution.Toolchains {
+		if err := tc.validate(); err != nil {
+			return apperrors.ValidationError(err.Error(), "execution.toolchains")
+		}
+	}
+
+	// Validate change tracking limits
+	if c.Execution.MaxTrackedFiles < 0 {
+		return apperrors.ValidationError("max_tracked_files cannot be negative", "execution.max_tracked_files")
+	}
+	if c.Execution.MaxReportedChanges < 0 {
+		return apperrors.ValidationError("max_reported_changes cannot be negative", "execution.max_reported_changes")
+	}
+
+	// Validate output summaries
+	summary := c.Execution.Summary
+	if summary.Threshold < 0 {
+		return apperrors.ValidationError("threshold cannot be negative", "execution.summary.threshold")
+	}
+	if summary.TailLines < 0 {
+		return apperrors.ValidationError("tail_lines cannot be negative", "execution.summary.tail_lines")
+	}
+	if summary.MaxMatches < 0 {
+		return apperrors.ValidationError("max_matches cannot be negative", "execution.summary.max_matches")
+	}
+	if err := validatePatterns(summary.ErrorPatterns, "execution.summary.error_patterns"); err != nil {
+		return err
+	}
+	if err := validatePatterns(summary.WarnPatterns, "execution.summary.warn_patterns"); err != nil {
+		return err
+	}
+
+	// Validate pre-flight checks
+	preflight := c.Execution.Preflight
+	for _, category := range preflight.Categories {
+		switch category {
+		case CategoryBuild, CategoryVCS, CategoryNetwork:
+		default:
+			return apperrors.ValidationError(fmt.Sprintf("invalid category %q (must be: build, vcs, network)", category), "execution.preflight.categories")
+		}
+	}
+	if preflight.MinFreeDisk < 0 {
+		return apperrors.ValidationError("min_free_disk cannot be negative", "execution.preflight.min_free_disk")
+	}
+	if preflight.MinFreeTemp < 0 {
+		return apperrors.ValidationError("min_free_temp cannot be negative", "execution.preflight.min_free_temp")
+	}
+
+	return nil
+}
+
+// validatePatterns checks that patterns are valid regular expressions.
+func validatePatterns(patterns []string, field string) error {
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return apperrors.ValidationError(fmt.Sprintf("invalid pattern %q: %v", pattern, err), field)
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateLogging() error {
+	// Validate log level
+	validLevels := []string{"debug", "info", "warn", "error", ""}
+	valid := false
+	for _, level := range validLevels {
+		if c.Logging.Level == level {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return apperrors.ValidationError(
+			"invalid log level (must be: debug, info, warn, error)",
+			"logging.level",
+		)
+	}
+
+	// Validate log format
+	validFormats := []string{"text", "json", ""}
+	valid = false
+	for _, format := range validFormats {
+		if c.Logging.Format == format {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return apperrors.ValidationError(
+			"invalid log format (must be: text, json)",
+			"logging.format",
+		)
+	}
+
+	if c.Logging.ProtocolMaxBody < 0 {
+		return apperrors.ValidationError("protocol_max_body cannot be negative", "logging.protocol_max_body")
+	}
+
+	// Validate rate limits
+	if c.Logging.DebugSampleRate < 0 {
+		return apperrors.ValidationError("debug_sample_rate cannot be negative", "logging.debug_sample_rate")
+	}
+	if c.Logging.RepeatInterval < 0 {
+		return apperrors.ValidationError(
+			"repeat_interval cannot be negative",
+			"logging.repeat_interval",
+		)
+	}
+	if c.Logging.MaxLineLength < 0 {
+		return apperrors.ValidationError("max_line_length cannot be negative", "logging.max_line_length")
+	}
+
+	return nil
+}
+
+func (c *Config) validateSchedules() error {
+	seen := make(map[string]bool)
+	for i, sched := range c.Schedules {
+		field := fmt.Sprintf("schedules[%d]", i)
+
+		if sched.Name == "" {
+			return apperrors.ValidationError("schedule name is required", field+".name")
+		}
+		if seen[sched.Name] {
+			return apperrors.ValidationError("duplicate schedule name: "+sched.Name, "schedules")
+		}
+		seen[sched.Name] = true
+
+		if sched.Cron == "" {
+			return apperrors.ValidationError("schedule cron expression is required", field+".cron")
+		}
+
+		if c.FindCommand(sched.Command) == nil {
+			return apperrors.ValidationError(
+				"schedule references unknown command: "+sched.Command,
+				field+".command",
+			)
+		}
+
+		if sched.WorkDir != "" && !filepath.IsAbs(sched.WorkDir) {
+			return apperrors.ValidationError("workdir must be an absolute path", field+".workdir")
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateWatch() error {
+	if c.Watch.Debounce < 0 {
+		return apperrors.ValidationError("debounce cannot be negative", "watch.debounce")
+	}
+
+	if c.Watch.MaxTriggersPerMinute < 0 {
+		return apperrors.ValidationError("max_triggers_per_minute cannot be negative", "watch.max_triggers_per_minute")
+	}
+
+	if c.Watch.MaxWatches < 0 {
+		return apperrors.ValidationError("max_watches cannot be negative", "watch.max_watches")
+	}
+
+	if c.Watch.MaxDirectories < 0 {
+		return apperrors.ValidationError("max_directories cannot be negative", "watch.max_directories")
+	}
+
+	return nil
+}
+
+func (c *Config) validateTransfer() error {
+	if c.Transfer.MaxDownloadSize < 0 {
+		return apperrors.ValidationError("max_download_size cannot be negative", "transfer.max_download_size")
+	}
+
+	if c.Transfer.MaxChunkSize < 0 {
+		return apperrors.ValidationError("max_chunk_size cannot be negative", "transfer.max_chunk_size")
+	}
+
+	for _, scheme := range c.Transfer.AllowedSchemes {
+		if scheme != "http" && scheme != "https" {
+			return apperrors.ValidationError("unsupported scheme: "+scheme, "transfer.allowed_schemes")
+		}
+	}
 
-		// Ensure max timeout is reasonable
-		if maxDur > 1*time.Hour {
-			return apperrors.ValidationError(
-				"max_timeout cannot exceed 1 hour",
-				"execution.max_timeout",
-			)
+	if c.Transfer.Timeout < 0 {
+		return apperrors.ValidationError("timeout cannot be negative", "transfer.timeout")
+	}
+
+	return nil
+}
+
+func (c *Config) validateHTTP() error {
+	for _, host := range c.HTTP.AllowedHosts {
+		if host == "" || strings.ContainsAny(host, "/:") {
+			return apperrors.ValidationError("invalid host: "+host, "http.allowed_hosts")
 		}
 	}
 
-	// Validate max concurrent
-	if c.Execution.MaxConcurrent < 0 {
-		return apperrors.ValidationError("max_concurrent cannot be negative", "execution.max_concurrent")
+	for _, method := range c.HTTP.AllowedMethods {
+		if !isValidHTTPToken(method) || method != strings.ToUpper(method) {
+			return apperrors.ValidationError("invalid method: "+method, "http.allowed_methods")
+		}
 	}
 
-	// Validate max output size
-	if c.Execution.MaxOutputSize < 0 {
-		return apperrors.ValidationError("max_output_size cannot be negative", "execution.max_output_size")
+	if c.HTTP.MaxRequestSize < 0 {
+		return apperrors.ValidationError("max_request_size cannot be negative", "http.max_request_size")
+	}
+	if c.HTTP.MaxResponseSize < 0 {
+		return apperrors.ValidationError("max_response_size cannot be negative", "http.max_response_size")
+	}
+
+	if c.HTTP.Timeout < 0 {
+		return apperrors.ValidationError("timeout cannot be negative", "http.timeout")
+	}
+
+	for i, secret := range c.HTTP.SecretHeaders {
+		field := fmt.Sprintf("http.secret_headers[%d]", i)
+		if len(secret.Hosts) == 0 {
+			return apperrors.ValidationError("secret headers need at least one host", field+".hosts")
+		}
+		for _, host := range secret.Hosts {
+			if host == "" || strings.ContainsAny(host, "/:") {
+				return apperrors.ValidationError("invalid host: "+host, field+".hosts")
+			}
+		}
+		if !isValidHTTPToken(secret.Header) {
+			return apperrors.ValidationError("invalid header name", field+".header")
+		}
+		if secret.Env == "" {
+			return apperrors.ValidationError("env is required", field+".env")
+		}
+		if strings.ContainsAny(secret.Prefix, "\r\n") {
+			return apperrors.ValidationError("prefix cannot contain line breaks", field+".prefix")
+		}
 	}
 
 	return nil
 }
 
-func (c *Config) validateLogging() error {
-	// Validate log level
-	validLevels := []string{"debug", "info", "warn", "error", ""}
-	valid := false
-	for _, level := range validLevels {
-		if c.Logging.Level == level {
-			valid = true
-			break
+// isValidHTTPToken reports whether s is an HTTP token, the form of
+// methods and header names.
+func isValidHTTPToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r > 0x7e || r <= 0x20 || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
 		}
 	}
-	if !valid {
-		return apperrors.ValidationError(
-			"invalid log level (must be: debug, info, warn, error)",
-			"logging.level",
-		)
+	return true
+}
+
+func (c *Config) validateContainers() error {
+	if c.Containers.MaxLogLines < 0 {
+		return apperrors.ValidationError("max_log_lines cannot be negative", "containers.max_log_lines")
 	}
 
-	// Validate log format
-	validFormats := []string{"text", "json", ""}
-	valid = false
-	for _, format := range validFormats {
-		if c.Logging.Format == format {
-			valid = true
-			break
+	for _, entry := range c.Containers.AllowedContainers {
+		if _, err := filepath.Match(entry, ""); err != nil || entry == "" {
+			return apperrors.ValidationError("invalid container pattern: "+entry, "containers.allowed_containers")
 		}
 	}
-	if !valid {
+	for _, entry := range c.Containers.AllowedImages {
+		if _, err := filepath.Match(entry, ""); err != nil || entry == "" {
+			return apperrors.ValidationError("invalid image pattern: "+entry, "containers.allowed_images")
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateTmux() error {
+	if c.Tmux.Socket != "" && !isValidSocketName(c.Tmux.Socket) {
 		return apperrors.ValidationError(
-			"invalid log format (must be: text, json)",
-			"logging.format",
-		)
+			"socket must contain only letters, numbers, '.', '_' and '-' (max 50 chars)", "tmux.socket")
+	}
+	if c.Tmux.MaxSessions < 0 {
+		return apperrors.ValidationError("max_sessions cannot be negative", "tmux.max_sessions")
+	}
+	if c.Tmux.MaxCaptureLines < 0 {
+		return apperrors.ValidationError("max_capture_lines cannot be negative", "tmux.max_capture_lines")
+	}
+	return nil
+}
+
+func (c *Config) validateREPL() error {
+	names := make(map[string]bool)
+	for i, interp := range c.REPL.Interpreters {
+		field := fmt.Sprintf("repl.interpreters[%d]", i)
+		if !isValidCommandName(interp.Name) {
+			return apperrors.ValidationError("invalid interpreter name", field+".name")
+		}
+		if names[interp.Name] {
+			return apperrors.ValidationError(fmt.Sprintf("duplicate interpreter name: %s", interp.Name), field+".name")
+		}
+		names[interp.Name] = true
+		if interp.Command == "" {
+			return apperrors.ValidationError("interpreter command cannot be empty", field+".command")
+		}
+		if interp.Prompt == "" {
+			return apperrors.ValidationError("interpreter prompt cannot be empty", field+".prompt")
+		}
+		if _, err := regexp.Compile(interp.Prompt); err != nil {
+			return apperrors.ValidationError(fmt.Sprintf("invalid prompt pattern: %v", err), field+".prompt")
+		}
+		for _, kv := range interp.Env {
+			if k, _, ok := strings.Cut(kv, "="); !ok || k == "" {
+				return apperrors.ValidationError("env entries must be KEY=value", field+".env")
+			}
+		}
+	}
+
+	for _, d := range []struct {
+		name  string
+		value Duration
+	}{
+		{"idle_timeout", c.REPL.IdleTimeout},
+		{"max_lifetime", c.REPL.MaxLifetime},
+		{"read_timeout", c.REPL.ReadTimeout},
+	} {
+		if d.value < 0 {
+			return apperrors.ValidationError(d.name+" cannot be negative", "repl."+d.name)
+		}
+	}
+
+	if c.REPL.MaxSessions < 0 {
+		return apperrors.ValidationError("max_sessions cannot be negative", "repl.max_sessions")
+	}
+	if c.REPL.MaxMemory < 0 {
+		return apperrors.ValidationError("max_memory cannot be negative", "repl.max_memory")
+	}
+	if c.REPL.MaxOutputSize < 0 {
+		return apperrors.ValidationError("max_output_size cannot be negative", "repl.max_output_size")
+	}
+	return nil
+}
+
+// ControlConfig contains settings for the operator control API, a local
+// HTTP API separate from the MCP transport for listing sessions, killing
+// commands, flipping switches, draining the server and fetching stats.
+type ControlConfig struct {
+	// Addr is the loopback address the API listens on, such as
+	// 127.0.0.1:7070; empty disables the API
+	Addr string `yaml:"addr,omitempty"`
+
+	// TokenFile is where the bearer token requests must carry is written
+	// when the API starts; defaults to control-token under the user cache
+	// directory
+	TokenFile string `yaml:"token_file,omitempty"`
+}
+
+func (c *Config) validateControl() error {
+	if c.Control.Addr == "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(c.Control.Addr)
+	if err != nil {
+		return apperrors.ValidationError("invalid address: "+c.Control.Addr, "control.addr")
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return apperrors.ValidationError("address must be a loopback address: "+c.Control.Addr, "control.addr")
+	}
+	return nil
+}
+
+// ChaosConfig contains fault injection settings, for testing how clients
+// handle a slow or failing server. Never enable them in normal use.
+type ChaosConfig struct {
+	// Latency delays executions by a random duration up to this long
+	Latency Duration `yaml:"latency,omitempty"`
+
+	// LatencyRate is the percentage of executions delayed; defaults to
+	// all of them when Latency is set
+	LatencyRate float64 `yaml:"latency_rate,omitempty"`
+
+	// FailureRate is the percentage of executions failed with an injected
+	// error instead of running
+	FailureRate float64 `yaml:"failure_rate,omitempty"`
+}
+
+// Enabled reports whether any fault is injected.
+func (c ChaosConfig) Enabled() bool {
+	return c.Latency > 0 || c.FailureRate > 0
+}
+
+func (c *Config) validateChaos() error {
+	if c.Chaos.Latency < 0 {
+		return apperrors.ValidationError("latency cannot be negative", "chaos.latency")
+	}
+	for _, r := range []struct {
+		name  string
+		value float64
+	}{
+		{"latency_rate", c.Chaos.LatencyRate},
+		{"failure_rate", c.Chaos.FailureRate},
+	} {
+		if r.value < 0 || r.value > 100 {
+			return apperrors.ValidationError(r.name+" must be a percentage between 0 and 100", "chaos."+r.name)
+		}
+	}
+	return nil
+}
+
+// isValidSocketName reports whether a tmux socket name is safe to use as
+// a file name.
+func isValidSocketName(name string) bool {
+	if name == "" || len(name) > 50 || name == "." || name == ".." {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Config) validateCatalog() error {
+	if c.Catalog.URL == "" {
+		return nil
+	}
+
+	if !isHTTPSURL(c.Catalog.URL) {
+		return apperrors.ValidationError("catalog url must be an https URL", "catalog.url")
+	}
+	if c.Catalog.SignatureURL != "" && !isHTTPSURL(c.Catalog.SignatureURL) {
+		return apperrors.ValidationError("catalog signature_url must be an https URL", "catalog.signature_url")
+	}
+
+	if c.Catalog.PublicKey == "" {
+		return apperrors.ValidationError("public_key is required to verify the catalog", "catalog.public_key")
+	}
+
+	if c.Catalog.Refresh != 0 && c.Catalog.Refresh.Std() < time.Minute {
+		return apperrors.ValidationError("invalid refresh: must be a duration of at least 1m", "catalog.refresh")
+	}
+
+	return nil
+}
+
+func (c *Config) validateBackup() error {
+	if c.Backup.MaxGenerations < 0 {
+		return apperrors.ValidationError("max_generations cannot be negative", "backup.max_generations")
+	}
+
+	if c.Backup.MaxFileSize < 0 {
+		return apperrors.ValidationError("max_file_size cannot be negative", "backup.max_file_size")
 	}
 
+	if c.Backup.MaxAge < 0 {
+		return apperrors.ValidationError("max_age cannot be negative", "backup.max_age")
+	}
+
+	return nil
+}
+
+func (c *Config) validateTrash() error {
+	if c.Trash.MaxSize < 0 {
+		return apperrors.ValidationError("max_size cannot be negative", "trash.max_size")
+	}
+
+	if c.Trash.MaxAge < 0 {
+		return apperrors.ValidationError("max_age cannot be negative", "trash.max_age")
+	}
+
+	return nil
+}
+
+// FindCommand returns the configured command with the given name, or
+// nil. The name may also be the ID of the command, e.g. run_tests for
+// run-tests.
+func (c *Config) FindCommand(name string) *Command {
+	for i := range c.Commands {
+		if c.Commands[i].Name == name {
+			return &c.Commands[i]
+		}
+	}
+	for i := range c.Commands {
+		if c.Commands[i].ID() == name {
+			return &c.Commands[i]
+		}
+	}
+	return nil
+}
+
+// validateToolGroups checks that tool groups are named uniquely, only
+// refer to configured commands, and that the default groups exist.
+func (c *Config) validateToolGroups() error {
+	groups := make(map[string]bool)
+	for i, group := range c.ToolGroups {
+		field := fmt.Sprintf("tool_groups[%d]", i)
+		if !isValidCommandName(group.Name) {
+			return apperrors.ValidationError("invalid tool group name: "+group.Name, field+".name")
+		}
+		if groups[group.Name] {
+			return apperrors.ValidationError("duplicate tool group name: "+group.Name, field+".name")
+		}
+		groups[group.Name] = true
+
+		if len(group.Commands) == 0 {
+			return apperrors.ValidationError("tool group has no commands", field+".commands")
+		}
+		for _, name := range group.Commands {
+			if c.FindCommand(name) == nil {
+				return apperrors.ValidationError("tool group refers to unknown command: "+name, field+".commands")
+			}
+		}
+	}
+
+	for _, name := range c.Server.DefaultToolGroups {
+		if !groups[name] {
+			return apperrors.ValidationError("unknown default tool group: "+name, "server.default_tool_groups")
+		}
+	}
+	return nil
+}
+
+// validatePlugins checks plugin definitions and that commands only list
+// defined plugins.
+func (c *Config) validatePlugins() error {
+	names := make(map[string]bool)
+	for i, plugin := range c.Plugins {
+		field := fmt.Sprintf("plugins[%d]", i)
+		if !isValidCommandName(plugin.Name) {
+			return apperrors.ValidationError("invalid plugin name: "+plugin.Name, field+".name")
+		}
+		if names[plugin.Name] {
+			return apperrors.ValidationError("duplicate plugin name: "+plugin.Name, field+".name")
+		}
+		names[plugin.Name] = true
+
+		if !filepath.IsAbs(plugin.Path) {
+			return apperrors.ValidationError("plugin path must be absolute", field+".path")
+		}
+		if len(plugin.Hooks) == 0 {
+			return apperrors.ValidationError("plugin has no hooks", field+".hooks")
+		}
+		for _, hook := range plugin.Hooks {
+			if hook != PluginHookPolicy && hook != PluginHookOutput {
+				return apperrors.ValidationError("unknown plugin hook: "+hook, field+".hooks")
+			}
+		}
+		if plugin.Timeout < 0 {
+			return apperrors.ValidationError("timeout cannot be negative", field+".timeout")
+		}
+		if plugin.MaxMemory < 0 || plugin.MaxMemory > 4<<30 {
+			return apperrors.ValidationError("max_memory must be between 0 and 4GiB", field+".max_memory")
+		}
+	}
+
+	for i, cmd := range c.Commands {
+		for _, name := range cmd.Plugins {
+			if !names[name] {
+				return apperrors.ValidationError("unknown plugin: "+name, fmt.Sprintf("commands[%d].plugins", i))
+			}
+		}
+	}
 	return nil
 }
 
 // isValidCommandName checks if a command name is valid.
+// validateScriptTools checks script tool definitions. Scripts themselves
+// are compiled when the server starts.
+func (c *Config) validateScriptTools() error {
+	names := make(map[string]bool)
+	for _, cmd := range c.Commands {
+		names[cmd.ID()] = true
+	}
+	for i, tool := range c.ScriptTools {
+		field := fmt.Sprintf("script_tools[%d]", i)
+		if !isValidToolName(tool.Name) {
+			return apperrors.ValidationError("invalid script tool name: "+tool.Name, field+".name")
+		}
+		if names[ToolID(tool.Name)] {
+			return apperrors.ValidationError("duplicate tool name: "+tool.Name, field+".name")
+		}
+		if err := c.checkBuiltinClash("script tool", tool.Name, field+".name"); err != nil {
+			return err
+		}
+		names[ToolID(tool.Name)] = true
+		if err := validateDisplayName(tool.DisplayName, field+".display_name"); err != nil {
+			return err
+		}
+
+		if tool.Description == "" {
+			return apperrors.ValidationError("script tool description is required", field+".description")
+		}
+		if len(tool.Description) > 500 {
+			return apperrors.ValidationError("script tool description too long (max 500 chars)", field+".description")
+		}
+		if strings.TrimSpace(tool.Script) == "" {
+			return apperrors.ValidationError("script is required", field+".script")
+		}
+		if tool.Timeout < 0 {
+			return apperrors.ValidationError("timeout cannot be negative", field+".timeout")
+		}
+
+		params := make(map[string]bool)
+		for j, param := range tool.Params {
+			pfield := fmt.Sprintf("%s.params[%d]", field, j)
+			if !isValidCommandName(param.Name) {
+				return apperrors.ValidationError("invalid parameter name: "+param.Name, pfield+".name")
+			}
+			if params[param.Name] {
+				return apperrors.ValidationError("duplicate parameter name: "+param.Name, pfield+".name")
+			}
+			params[param.Name] = true
+			if param.Type != "" && !slices.Contains(ScriptParamTypes, param.Type) {
+				return apperrors.ValidationError(
+					fmt.Sprintf("unknown parameter type %q: must be one of %s", param.Type, strings.Join(ScriptParamTypes, ", ")),
+					pfield+".type",
+				)
+			}
+		}
+	}
+	return nil
+}
+
 func isValidCommandName(name string) bool {
 	if len(name) == 0 || len(name) > 50 {
 		return false
@@ -415,58 +2506,171 @@ func isValidCommandName(name string) bool {
 	return match
 }
 
-// GetTimeout returns the timeout duration for a command.
-func (c *Command) GetTimeout(defaultTimeout time.Duration) time.Duration {
-	if c.Timeout == "" {
-		return defaultTimeout
+// isValidToolName checks if a tool name is valid. Besides command names,
+// tool names may contain hyphens and dots, as MCP allows.
+func isValidToolName(name string) bool {
+	if len(name) == 0 || len(name) > 50 {
+		return false
 	}
+	match, _ := regexp.MatchString(`^[a-zA-Z][a-zA-Z0-9_.-]*$`, name)
+	return match
+}
 
-	dur, err := time.ParseDuration(c.Timeout)
-	if err != nil {
-		return defaultTimeout
+// ToolID returns a tool name as an identifier, with hyphens and dots
+// replaced by underscores. Some clients rename tools this way for models
+// that only accept identifiers, so tool names must differ in their IDs.
+func ToolID(name string) string {
+	return strings.NewReplacer("-", "_", ".", "_").Replace(name)
+}
+
+// ID returns the command name as an identifier; see ToolID.
+func (c *Command) ID() string {
+	return ToolID(c.Name)
+}
+
+// validateDisplayName checks the title of a tool.
+func validateDisplayName(name, field string) error {
+	if len(name) > 100 {
+		return apperrors.ValidationError("display_name too long (max 100 chars)", field)
+	}
+	if strings.ContainsFunc(name, unicode.IsControl) {
+		return apperrors.ValidationError("display_name cannot contain control characters", field)
+	}
+	return nil
+}
+
+// isHTTPSURL checks if a URL is an absolute https URL.
+func isHTTPSURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
+
+// isValidToolPrefix checks if a tool name prefix is valid.
+func isValidToolPrefix(prefix string) bool {
+	if len(prefix) > 20 {
+		return false
 	}
+	match, _ := regexp.MatchString("^[a-zA-Z][a-zA-Z0-9_]*$", prefix)
+	return match
+}
 
-	return dur
+// GetTimeout returns the timeout duration for a command.
+func (c *Command) GetTimeout(defaultTimeout time.Duration) time.Duration {
+	if c.Timeout <= 0 {
+		return defaultTimeout
+	}
+	return c.Timeout.Std()
 }
 
 // IsCommandAllowed checks if a command is allowed by security settings.
-func (c *Config) IsCommandAllowed(command string) bool {
+// The command is resolved via PATH, or from workDir when it is a relative
+// path, and through symlinks, so rules apply to the binary that would run,
+// by base name and by full path.
+func (c *Config) IsCommandAllowed(command, workDir string) bool {
 	// Check blocked commands
-	for _, blocked := range c.Security.BlockedCommands {
-		if command == blocked || strings.HasPrefix(command, blocked+"/") {
-			return false
-		}
+	if blocked := c.MatchBlockedCommand(command, workDir); blocked != "" {
+		return c.OverridesBlock(blocked, c.MatchAllowedCommand(command, workDir))
 	}
 
 	// If allowed list is specified, check it
 	if len(c.Security.AllowedCommands) > 0 {
-		for _, allowed := range c.Security.AllowedCommands {
-			if command == allowed || strings.HasPrefix(command, allowed+"/") {
-				return true
-			}
-		}
-		return false
+		return c.MatchAllowedCommand(command, workDir) != ""
 	}
 
 	return true
 }
 
+// MatchBlockedCommand returns the blocked_commands entry matching a
+// command run in workDir, or "" if none does. Literal entries are
+// preferred over patterns.
+func (c *Config) MatchBlockedCommand(command, workDir string) string {
+	return matchCommand(c.Security.BlockedCommands, normalizeCommand(command, workDir), (*commandForms).blockedBy)
+}
+
+// MatchAllowedCommand returns the allowed_commands entry matching a
+// command run in workDir, or "" if none does. Literal entries are
+// preferred over patterns.
+func (c *Config) MatchAllowedCommand(command, workDir string) string {
+	return matchCommand(c.Security.AllowedCommands, normalizeCommand(command, workDir), (*commandForms).allowedBy)
+}
+
+// OverridesBlock reports whether an allowed entry overrides a blocked
+// entry matching the same command under CommandPrecedence.
+func (c *Config) OverridesBlock(blocked, allowed string) bool {
+	if c.Security.CommandPrecedence != PrecedenceExplicitAllow || allowed == "" {
+		return false
+	}
+	return isLiteralEntry(allowed) && !isLiteralEntry(blocked)
+}
+
 // IsPathAllowed checks if a path is allowed by security settings.
 func (c *Config) IsPathAllowed(path string) bool {
+	if c.MatchDeniedPath(path) != "" {
+		return false
+	}
+
 	if len(c.Security.AllowedPaths) == 0 {
 		return true
 	}
 
-	absPath, err := filepath.Abs(path)
-	if err != nil {
-		return false
+	return c.MatchAllowedPath(path) != ""
+}
+
+// CheckWritePath returns an error unless tools may write to a path. All
+// tools changing files use it. Unlike IsPathAllowed, no path is writable
+// unless allowed_paths is set, and the path its symlinks point to is
+// checked even without resolve_symlinks, so links cannot redirect writes.
+func (c *Config) CheckWritePath(path string) error {
+	if len(c.Security.AllowedPaths) == 0 {
+		return apperrors.PermissionError("changing files requires security.allowed_paths", path)
 	}
+	if !c.IsPathAllowed(path) {
+		return apperrors.PermissionError("path not allowed: "+path, path)
+	}
+	p, err := ResolvePath(path)
+	if err != nil || !underAny(c.Security.AllowedPaths, p.Resolved) || underAny(c.deniedEntries(), p.Resolved) {
+		return apperrors.PermissionError("path not allowed once its links are resolved: "+path, path)
+	}
+	return nil
+}
 
-	for _, allowed := range c.Security.AllowedPaths {
-		if strings.HasPrefix(absPath, allowed) {
-			return true
+// MatchAllowedPath returns the allowed_paths entry containing a path, or
+// "" if none does. With resolve_symlinks the path symlinks point to must be
+// inside the entry.
+func (c *Config) MatchAllowedPath(path string) string {
+	return c.matchPath(c.Security.AllowedPaths, c.pathForms(path), true)
+}
+
+// MatchAllowedResolved is MatchAllowedPath for a path already resolved.
+func (c *Config) MatchAllowedResolved(path ResolvedPath) string {
+	return c.matchPath(c.Security.AllowedPaths, c.resolvedForms(path), true)
+}
+
+// MatchDeniedPath returns the denied_paths entry or state path (see
+// StatePaths) containing a path, or "" if none does. The path is denied if
+// it or the path its symlinks point to is inside the entry.
+func (c *Config) MatchDeniedPath(path string) string {
+	return c.matchPath(c.deniedEntries(), c.pathForms(path), false)
+}
+
+// MatchDeniedBelow returns the denied_paths entry inside a directory, or ""
+// if none is, so that a directory holding a denied path can be refused to
+// commands reading it recursively.
+func (c *Config) MatchDeniedBelow(dir string) string {
+	forms := c.pathForms(dir)
+	for _, entry := range c.deniedEntries() {
+		for _, root := range c.pathForms(entry) {
+			for _, form := range forms {
+				if Within(form, root) {
+					return entry
+				}
+			}
 		}
 	}
+	return ""
+}
 
-	return false
+// MatchDeniedResolved is MatchDeniedPath for a path already resolved.
+func (c *Config) MatchDeniedResolved(path ResolvedPath) string {
+	return c.matchPath(c.deniedEntries(), c.resolvedForms(path), false)
 }
\ No newline at end of file
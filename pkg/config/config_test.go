@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig_validateExecutionTimeouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     func(*ExecutionConfig)
+		wantErr bool
+	}{
+		{name: "defaults", set: func(*ExecutionConfig) {}},
+		{name: "unset default_timeout", set: func(e *ExecutionConfig) { e.DefaultTimeout = 0 }, wantErr: true},
+		{name: "negative default_timeout", set: func(e *ExecutionConfig) { e.DefaultTimeout = Duration(-time.Second) }, wantErr: true},
+		{name: "unset max_timeout", set: func(e *ExecutionConfig) { e.MaxTimeout = 0 }, wantErr: true},
+		{name: "max_timeout over an hour", set: func(e *ExecutionConfig) { e.MaxTimeout = Duration(2 * time.Hour) }, wantErr: true},
+		{name: "immediate kill", set: func(e *ExecutionConfig) { e.KillTimeout = 0 }},
+		{name: "negative kill_timeout", set: func(e *ExecutionConfig) { e.KillTimeout = Duration(-time.Second) }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.set(&cfg.Execution)
+			if err := cfg.validateExecution(); (err != nil) != tt.wantErr {
+				t.Errorf("validateExecution() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
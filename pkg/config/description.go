@@ -0,0 +1,55 @@
+package config
+
+import (
+	"strings"
+	"text/template"
+)
+
+// DescriptionFacts are the facts about the environment a command
+// description can refer to as template variables, such as
+// "Runs tests with go {{.Version}} in {{.WorkDir}}".
+type DescriptionFacts struct {
+	OS      string // Operating system, such as linux or darwin
+	Arch    string // Architecture, such as amd64 or arm64
+	WorkDir string // Directory the command runs in by default
+	Path    string // Resolved path of the command binary
+
+	// version returns the version of the command binary. It is only
+	// called if the description refers to it, as it runs the binary.
+	version func() string
+}
+
+// NewDescriptionFacts returns facts whose Version is computed by version.
+func NewDescriptionFacts(os, arch, workDir, path string, version func() string) DescriptionFacts {
+	return DescriptionFacts{OS: os, Arch: arch, WorkDir: workDir, Path: path, version: version}
+}
+
+// Version returns the version of the command binary, or "" if unknown.
+func (f DescriptionFacts) Version() string {
+	if f.version == nil {
+		return ""
+	}
+	return f.version()
+}
+
+// IsTemplate reports whether a description refers to template variables.
+func (c *Command) IsTemplate() bool {
+	return strings.Contains(c.Description, "{{")
+}
+
+// RenderDescription returns the description with its template variables
+// resolved from facts.
+func (c *Command) RenderDescription(facts DescriptionFacts) (string, error) {
+	if !c.IsTemplate() {
+		return c.Description, nil
+	}
+	tmpl, err := template.New(c.Name).Option("missingkey=error").Parse(c.Description)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, facts); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
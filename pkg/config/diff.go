@@ -0,0 +1,214 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of configuration changes.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// securityFields are the settings deciding what clients can run and
+// reach, and where results go.
+var securityFields = []string{
+	"security", "commands", "command_defaults", "script_tools", "plugins", "catalog",
+	"tool_groups", "schedules", "watch", "execution", "server.tools", "server.tool_prefix",
+	"control", "approvals", "network", "http", "transfer", "containers", "tmux", "repl",
+	"processes", "git_snapshot", "telemetry", "chaos", "transport",
+}
+
+// Change is a setting that differs between two configurations.
+type Change struct {
+	// Path names the setting, e.g. security.allowed_paths or
+	// commands[deploy].args; entries of lists of named settings are
+	// matched by name
+	Path string `json:"path"`
+	Kind string `json:"kind"` // added, removed or changed
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+
+	// Added and Removed are the items of a changed list of values
+	Added   []any `json:"added,omitempty"`
+	Removed []any `json:"removed,omitempty"`
+
+	// Security is set for settings deciding what clients can run and
+	// reach
+	Security bool `json:"security"`
+}
+
+// IsSecurityField reports whether a setting, named like Change.Path,
+// decides what clients can run and reach.
+func IsSecurityField(path string) bool {
+	for _, field := range securityFields {
+		if path == field || strings.HasPrefix(path, field+".") || strings.HasPrefix(path, field+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns the settings that differ from old to new, in the order of
+// their paths. Settings are compared as they apply, after defaults and
+// command_defaults.
+func Diff(old, new *Config) ([]Change, error) {
+	a, err := tree(old)
+	if err != nil {
+		return nil, err
+	}
+	b, err := tree(new)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	diffValues("", a, b, &changes)
+	slices.SortStableFunc(changes, func(x, y Change) int { return strings.Compare(x.Path, y.Path) })
+	return changes, nil
+}
+
+// tree returns the YAML encoding of a configuration as maps and lists.
+func tree(c *Config) (map[string]any, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to encode configuration")
+	}
+	var t map[string]any
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrorTypeInternal, "failed to decode configuration")
+	}
+	return t, nil
+}
+
+// diffValues adds the changes from a to b at path.
+func diffValues(path string, a, b any, changes *[]Change) {
+	switch {
+	case a == nil && b == nil:
+		return
+	case a == nil || b == nil:
+		// Compare added and removed sections and lists setting by
+		// setting
+		am, aMap := a.(map[string]any)
+		bm, bMap := b.(map[string]any)
+		al, aList := a.([]any)
+		bl, bList := b.([]any)
+		switch {
+		case aMap || bMap:
+			diffMaps(path, am, bm, changes)
+		case aList || bList:
+			diffLists(path, al, bl, changes)
+		case a == nil:
+			*changes = append(*changes, newChange(path, ChangeAdded, nil, b))
+		default:
+			*changes = append(*changes, newChange(path, ChangeRemoved, a, nil))
+		}
+		return
+	}
+
+	if am, ok := a.(map[string]any); ok {
+		if bm, ok := b.(map[string]any); ok {
+			diffMaps(path, am, bm, changes)
+			return
+		}
+	}
+	if al, ok := a.([]any); ok {
+		if bl, ok := b.([]any); ok {
+			diffLists(path, al, bl, changes)
+			return
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, newChange(path, ChangeChanged, a, b))
+	}
+}
+
+// diffMaps adds the changes of each key from a to b.
+func diffMaps(path string, a, b map[string]any, changes *[]Change) {
+	keys := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	for k := range keys {
+		child := k
+		if path != "" {
+			child = path + "." + k
+		}
+		diffValues(child, a[k], b[k], changes)
+	}
+}
+
+// diffLists compares lists of named settings entry by entry, and other
+// lists as a whole, listing the values added and removed.
+func diffLists(path string, a, b []any, changes *[]Change) {
+	an, aok := byName(a)
+	bn, bok := byName(b)
+	if aok && bok {
+		for name := range an {
+			diffValues(fmt.Sprintf("%s[%s]", path, name), an[name], bn[name], changes)
+		}
+		for name := range bn {
+			if _, ok := an[name]; !ok {
+				diffValues(fmt.Sprintf("%s[%s]", path, name), nil, bn[name], changes)
+			}
+		}
+		return
+	}
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	c := newChange(path, ChangeChanged, a, b)
+	switch {
+	case len(a) == 0:
+		c.Kind, c.Old = ChangeAdded, nil
+	case len(b) == 0:
+		c.Kind, c.New = ChangeRemoved, nil
+	}
+	for _, v := range b {
+		if !containsValue(a, v) {
+			c.Added = append(c.Added, v)
+		}
+	}
+	for _, v := range a {
+		if !containsValue(b, v) {
+			c.Removed = append(c.Removed, v)
+		}
+	}
+	*changes = append(*changes, c)
+}
+
+// byName indexes a list of settings by their name, reporting false when
+// an entry has no name.
+func byName(list []any) (map[string]any, bool) {
+	named := make(map[string]any, len(list))
+	for _, v := range list {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		name, ok := m["name"].(string)
+		if !ok {
+			return nil, false
+		}
+		named[name] = m
+	}
+	return named, true
+}
+
+// containsValue reports whether list holds v.
+func containsValue(list []any, v any) bool {
+	return slices.ContainsFunc(list, func(x any) bool { return reflect.DeepEqual(x, v) })
+}
+
+func newChange(path, kind string, old, new any) Change {
+	return Change{Path: path, Kind: kind, Old: old, New: new, Security: IsSecurityField(path)}
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Built-in DLP detectors.
+const (
+	DetectorCreditCard  = "credit_card"  // Card numbers passing the Luhn check
+	DetectorSSN         = "ssn"          // US social security numbers
+	DetectorHighEntropy = "high_entropy" // Random-looking tokens such as keys and passwords
+)
+
+// Detectors are the names of the built-in DLP detectors.
+var Detectors = []string{DetectorCreditCard, DetectorSSN, DetectorHighEntropy}
+
+// DLP actions, from the mildest.
+const (
+	DLPAlert  = "alert"  // Log findings and return results as they are
+	DLPRedact = "redact" // Replace findings in results
+	DLPBlock  = "block"  // Withhold results with findings
+)
+
+// DLPActions are the DLP actions, from the mildest.
+var DLPActions = []string{DLPAlert, DLPRedact, DLPBlock}
+
+// DefaultMinEntropy applies when dlp.min_entropy is not set.
+const DefaultMinEntropy = 4.0
+
+// DLPConfig scans the results returned to clients, such as file contents
+// and command output, for sensitive data.
+type DLPConfig struct {
+	// Action is what happens to results with findings of the built-in
+	// detectors: alert, redact or block. Scanning is off when empty
+	Action string `yaml:"action,omitempty"`
+
+	// Detectors are the built-in detectors to apply; credit_card and ssn
+	// when empty
+	Detectors []string `yaml:"detectors,omitempty"`
+
+	// Patterns are additional detectors
+	Patterns []DLPPattern `yaml:"patterns,omitempty"`
+
+	// MinEntropy is the Shannon entropy, in bits per character, from which
+	// tokens of 20 characters or more are high_entropy findings; defaults
+	// to 4.0
+	MinEntropy float64 `yaml:"min_entropy,omitempty"`
+}
+
+// DLPPattern is a detector matching a regular expression.
+type DLPPattern struct {
+	// Name identifies the pattern in logs and redactions
+	Name string `yaml:"name"`
+
+	// Pattern is the regular expression of sensitive data
+	Pattern string `yaml:"pattern"`
+
+	// Action overrides dlp.action for the pattern's findings
+	Action string `yaml:"action,omitempty"`
+}
+
+// Enabled reports whether results are scanned.
+func (d DLPConfig) Enabled() bool {
+	return d.Action != "" || slices.ContainsFunc(d.Patterns, func(p DLPPattern) bool { return p.Action != "" })
+}
+
+// Applies reports whether a built-in detector is applied.
+func (d DLPConfig) Applies(detector string) bool {
+	if d.Action == "" {
+		return false
+	}
+	if len(d.Detectors) == 0 {
+		return detector != DetectorHighEntropy
+	}
+	return slices.Contains(d.Detectors, detector)
+}
+
+// validate checks the DLP settings.
+func (d DLPConfig) validate() error {
+	if d.Action != "" && !slices.Contains(DLPActions, d.Action) {
+		return fmt.Errorf("invalid action %q (must be: %s)", d.Action, strings.Join(DLPActions, ", "))
+	}
+	for _, det := range d.Detectors {
+		if !slices.Contains(Detectors, det) {
+			return fmt.Errorf("unknown detector %q: must be one of %s", det, strings.Join(Detectors, ", "))
+		}
+	}
+	names := make(map[string]bool)
+	for _, p := range d.Patterns {
+		if p.Name == "" {
+			return fmt.Errorf("pattern name is required")
+		}
+		if names[p.Name] || slices.Contains(Detectors, p.Name) {
+			return fmt.Errorf("duplicate detector name: %s", p.Name)
+		}
+		names[p.Name] = true
+		if p.Pattern == "" {
+			return fmt.Errorf("pattern %s: pattern is required", p.Name)
+		}
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("pattern %s: invalid pattern: %v", p.Name, err)
+		}
+		if p.Action != "" && !slices.Contains(DLPActions, p.Action) {
+			return fmt.Errorf("pattern %s: invalid action %q (must be: %s)", p.Name, p.Action, strings.Join(DLPActions, ", "))
+		}
+		if p.Action == "" && d.Action == "" {
+			return fmt.Errorf("pattern %s: action is required when dlp.action is not set", p.Name)
+		}
+	}
+	if d.MinEntropy < 0 {
+		return fmt.Errorf("min_entropy cannot be negative")
+	}
+	return nil
+}
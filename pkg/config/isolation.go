@@ -0,0 +1,80 @@
+package config
+
+import (
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// Isolation presets of configured commands, from the weakest. Each preset
+// includes the ones before it.
+const (
+	// IsolationNone runs the command in the environment env_allow and
+	// env_deny leave
+	IsolationNone = "none"
+
+	// IsolationEnvClean only passes the variables the command's category
+	// needs, such as PATH, locale and proxy settings
+	IsolationEnvClean = "env_clean"
+
+	// IsolationTmpHome also points HOME and the XDG directories at a
+	// temporary directory removed after the run, hiding the user's
+	// dotfiles and credentials
+	IsolationTmpHome = "tmp_home"
+
+	// IsolationNoNetwork also runs the command in a network namespace
+	// without interfaces; Linux only
+	IsolationNoNetwork = "no_network"
+)
+
+// IsolationPresets are the isolation presets, from the weakest.
+var IsolationPresets = []string{IsolationNone, IsolationEnvClean, IsolationTmpHome, IsolationNoNetwork}
+
+// Command categories, choosing the variables env_clean keeps.
+const (
+	CategoryBuild   = "build"   // Compilers, package managers and test runners
+	CategoryVCS     = "vcs"     // Git and other version control
+	CategoryNetwork = "network" // Clients of remote services, such as curl
+)
+
+// EffectiveIsolation returns the isolation preset a command runs with:
+// its isolation, or env_clean for commands with a category.
+func (c Command) EffectiveIsolation() string {
+	switch {
+	case c.Isolation != "":
+		return c.Isolation
+	case c.Category != "":
+		return IsolationEnvClean
+	}
+	return IsolationNone
+}
+
+// IsolationIncludes reports whether a preset includes another, e.g.
+// whether tmp_home cleans the environment.
+func IsolationIncludes(preset, level string) bool {
+	rank := func(p string) int {
+		for i, q := range IsolationPresets {
+			if p == q {
+				return i
+			}
+		}
+		return 0
+	}
+	return rank(preset) >= rank(level)
+}
+
+// validateIsolation checks a command's isolation preset and category.
+func (c Command) validateIsolation(field string) error {
+	switch c.Isolation {
+	case "", IsolationNone, IsolationEnvClean, IsolationTmpHome, IsolationNoNetwork:
+	default:
+		return apperrors.ValidationError("invalid isolation (must be: none, env_clean, tmp_home, no_network)", field+".isolation")
+	}
+	switch c.Category {
+	case "", CategoryBuild, CategoryVCS, CategoryNetwork:
+	default:
+		return apperrors.ValidationError("invalid category (must be: build, vcs, network)", field+".category")
+	}
+	if c.Category == CategoryNetwork && c.Isolation == IsolationNoNetwork {
+		return apperrors.ValidationError("isolation no_network cannot be used with category network", field+".isolation")
+	}
+	return nil
+}
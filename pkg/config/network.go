@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// IP version preferences of outbound connections.
+const (
+	IPAuto = "auto" // Whichever address answers first, as the system resolves them
+	IPv4   = "ipv4"
+	IPv6   = "ipv6"
+)
+
+// IPPreferences lists the valid ip_preference values.
+var IPPreferences = []string{IPAuto, IPv4, IPv6}
+
+// maxRetries bounds network.retries, so an unreachable host cannot hold a
+// request for long.
+const maxRetries = 10
+
+// NetworkConfig contains the settings of the HTTP client used by outbound
+// features: catalog fetches, tripwire webhooks, downloads and the
+// http_request tool. They let those features work behind corporate
+// proxies and TLS inspection.
+type NetworkConfig struct {
+	// Proxy is the URL of an http, https or socks5 proxy all requests go
+	// through; the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment
+	// variables are used when empty
+	Proxy string `yaml:"proxy,omitempty"`
+
+	// NoProxy lists hosts reached directly when Proxy is set; a leading
+	// "*." matches subdomains
+	NoProxy []string `yaml:"no_proxy,omitempty"`
+
+	// CABundle is a PEM file of certificate authorities trusted in
+	// addition to the system ones, such as that of a TLS inspecting proxy
+	CABundle string `yaml:"ca_bundle,omitempty"`
+
+	// ConnectTimeout limits establishing a connection, TLS handshake
+	// included (10s by default)
+	ConnectTimeout Duration `yaml:"connect_timeout,omitempty"`
+
+	// Timeout limits catalog fetches and webhook deliveries, and downloads
+	// without a transfer.timeout of their own
+	Timeout Duration `yaml:"timeout,omitempty"`
+
+	// Retries is how often requests are retried after a connection
+	// failure, or a 429, 502, 503 or 504 response to a GET or HEAD
+	Retries int `yaml:"retries,omitempty"`
+
+	// IPPreference is the address family connections try first: auto
+	// (the default), ipv4 or ipv6. The other family is tried when no
+	// address of the preferred one connects
+	IPPreference string `yaml:"ip_preference,omitempty"`
+}
+
+// Bypasses reports whether host is reached without the configured proxy.
+func (n NetworkConfig) Bypasses(host string) bool {
+	host = strings.ToLower(host)
+	for _, entry := range n.NoProxy {
+		entry = strings.ToLower(entry)
+		if suffix, ok := strings.CutPrefix(entry, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks the network settings.
+func (n NetworkConfig) validate() error {
+	if n.Proxy != "" {
+		u, err := url.Parse(n.Proxy)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid proxy URL: %s", n.Proxy)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("unsupported proxy scheme %q (must be: http, https, socks5)", u.Scheme)
+		}
+	}
+	for _, host := range n.NoProxy {
+		if host == "" || strings.ContainsAny(host, "/:") {
+			return fmt.Errorf("invalid no_proxy host: %s", host)
+		}
+	}
+	if n.ConnectTimeout < 0 {
+		return fmt.Errorf("connect_timeout cannot be negative")
+	}
+	if n.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	if n.Retries < 0 || n.Retries > maxRetries {
+		return fmt.Errorf("retries must be between 0 and %d", maxRetries)
+	}
+	if n.IPPreference != "" && !slices.Contains(IPPreferences, n.IPPreference) {
+		return fmt.Errorf("invalid ip_preference %q (must be: %s)", n.IPPreference, strings.Join(IPPreferences, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// PackageManagers are the package managers package policies understand.
+var PackageManagers = []string{"apt", "brew", "npm", "pip", "winget"}
+
+// defaultPackageCommands are the binaries of each package manager.
+var defaultPackageCommands = map[string][]string{
+	"apt":    {"apt", "apt-get"},
+	"brew":   {"brew"},
+	"npm":    {"npm"},
+	"pip":    {"pip", "pip3"},
+	"winget": {"winget"},
+}
+
+// PackagePolicy lets a package manager install, upgrade and uninstall
+// allowlisted packages without approval. Other package operations are
+// held until operators approve them, like commands requiring a second
+// approval.
+type PackagePolicy struct {
+	// Manager is the module that reads command lines: apt, brew, npm, pip
+	// or winget
+	Manager string `yaml:"manager"`
+
+	// Commands are entries in the same forms as blocked_commands naming
+	// the binaries the policy applies to; defaults to the manager's
+	// binaries, such as pip and pip3. python -m pip runs are covered by
+	// listing python
+	Commands []string `yaml:"commands,omitempty"`
+
+	// Packages are allowlisted package names, which may be globs such as
+	// @types/*. A version in the manager's syntax (eslint@8.57.0,
+	// requests==2.31.0, curl=7.88.1-10; winget uses ==) pins the package:
+	// it is only installed or upgraded to exactly that version
+	Packages []string `yaml:"packages,omitempty"`
+
+	// AllowManifest allows installs of a project's declared dependencies,
+	// such as npm install, npm ci, pip install -r and brew bundle
+	AllowManifest bool `yaml:"allow_manifest,omitempty"`
+}
+
+// PackageSpec is a package named on a command line or in a policy.
+type PackageSpec struct {
+	Name    string // Normalized name; empty for paths and URLs
+	Version string // Exact version for ==, @ or =; otherwise the constraint
+}
+
+// Matches reports whether a policy applies to a command.
+func (p PackagePolicy) Matches(command string) bool {
+	entries := p.Commands
+	if len(entries) == 0 {
+		entries = defaultPackageCommands[p.Manager]
+	}
+	return matchCommand(entries, normalizeCommand(command, ""), (*commandForms).blockedBy) != ""
+}
+
+// Allows reports whether a package is allowlisted; pinned entries only
+// allow their version unless versions are ignored, as for uninstalls.
+func (p PackagePolicy) Allows(spec PackageSpec, ignoreVersion bool) bool {
+	if spec.Name == "" {
+		return false
+	}
+	for _, entry := range p.Packages {
+		want := ParsePackageSpec(p.Manager, entry)
+		if ok, _ := path.Match(want.Name, spec.Name); !ok {
+			continue
+		}
+		if ignoreVersion || want.Version == "" || want.Version == spec.Version {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePackageSpec reads a package as a manager's command line names it.
+// Paths, URLs and other sources than the registry have no name.
+func ParsePackageSpec(manager, s string) PackageSpec {
+	if strings.Contains(s, "://") || strings.HasPrefix(s, ".") || strings.HasPrefix(s, "/") ||
+		strings.HasPrefix(s, "~") || strings.Contains(s, "\\") {
+		return PackageSpec{}
+	}
+	switch manager {
+	case "npm":
+		// name@version, @scope/name@version
+		name, version := s, ""
+		if i := strings.LastIndex(s, "@"); i > 0 {
+			name, version = s[:i], s[i+1:]
+		}
+		if strings.Contains(name, ":") || strings.Count(name, "/") > 1 ||
+			(strings.Contains(name, "/") && !strings.HasPrefix(name, "@")) ||
+			strings.ContainsAny(version, ":/") {
+			return PackageSpec{} // git:, github:, file:, npm: aliases and similar
+		}
+		return PackageSpec{Name: strings.ToLower(name), Version: version}
+	case "pip":
+		// name[extras]==version, name>=version
+		i := strings.IndexAny(s, "=<>!~;@ ")
+		name, version := s, ""
+		if i >= 0 {
+			name, version = s[:i], strings.TrimSpace(s[i:])
+		}
+		if j := strings.Index(name, "["); j >= 0 {
+			name = name[:j]
+		}
+		if exact, ok := strings.CutPrefix(version, "=="); ok && !strings.ContainsAny(exact, ",;*") {
+			version = exact
+		}
+		if strings.Contains(name, "/") {
+			return PackageSpec{}
+		}
+		return PackageSpec{Name: normalizePipName(name), Version: version}
+	case "apt":
+		// name=version, name/release
+		name, version, _ := strings.Cut(s, "=")
+		name, _, _ = strings.Cut(name, "/")
+		if strings.HasSuffix(name, ".deb") {
+			return PackageSpec{}
+		}
+		return PackageSpec{Name: strings.ToLower(name), Version: version}
+	case "winget":
+		name, version, _ := strings.Cut(s, "==")
+		return PackageSpec{Name: strings.ToLower(name), Version: version}
+	}
+	// brew names, including tap/formula and name@major, have no versions
+	return PackageSpec{Name: strings.ToLower(s)}
+}
+
+// normalizePipName normalizes a Python package name as PEP 503 does.
+func normalizePipName(name string) string {
+	name = strings.ToLower(name)
+	return strings.NewReplacer("_", "-", ".", "-").Replace(name)
+}
+
+// validate checks a policy.
+func (p PackagePolicy) validate() error {
+	if !slices.Contains(PackageManagers, p.Manager) {
+		return fmt.Errorf("unknown manager %q: must be one of %s", p.Manager, strings.Join(PackageManagers, ", "))
+	}
+	for _, entry := range p.Commands {
+		if _, err := parseCommandRule(entry); err != nil {
+			return fmt.Errorf("package policy %s: %v", p.Manager, err)
+		}
+	}
+	for _, entry := range p.Packages {
+		spec := ParsePackageSpec(p.Manager, entry)
+		if spec.Name == "" {
+			return fmt.Errorf("package policy %s: invalid package %q", p.Manager, entry)
+		}
+		if _, err := path.Match(spec.Name, ""); err != nil {
+			return fmt.Errorf("package policy %s: invalid package pattern %q", p.Manager, entry)
+		}
+	}
+	return nil
+}
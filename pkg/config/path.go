@@ -0,0 +1,176 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ResolvedPath is an absolute path and the path its symlinks refer to, so
+// it can be checked against several path rules with a single resolution.
+type ResolvedPath struct {
+	Abs      string
+	Resolved string
+}
+
+// ResolvePath returns the absolute, cleaned form of a path and the path
+// its symlinks refer to. Paths that do not exist yet are resolved through
+// their nearest existing parent.
+func ResolvePath(path string) (ResolvedPath, error) {
+	abs, err := absPath(path)
+	if err != nil {
+		return ResolvedPath{}, err
+	}
+	return ResolvedPath{Abs: abs, Resolved: resolveExisting(abs)}, nil
+}
+
+// pathForms returns the absolute, cleaned path and, when symlinks are
+// resolved, the path it refers to.
+func (c *Config) pathForms(path string) []string {
+	abs, err := absPath(path)
+	if err != nil {
+		return nil
+	}
+	if !c.Security.ResolveSymlinks {
+		return []string{abs}
+	}
+	return c.resolvedForms(ResolvedPath{Abs: abs, Resolved: resolveExisting(abs)})
+}
+
+// absPath returns the absolute, cleaned form of a path. On Windows the
+// other spellings of a path are normalized first, so that C:\Secret.,
+// C:/Secret and \\?\C:\Secret are checked as C:\Secret; case is folded
+// when paths are compared.
+func absPath(path string) (string, error) {
+	if runtime.GOOS == "windows" {
+		path = windowsPath(path)
+	}
+	return filepath.Abs(path)
+}
+
+// windowsPath rewrites the forms of a Windows path that name the same file
+// as one: forward slashes become backslashes, the \\?\ and \\?\UNC\
+// prefixes of long paths are removed, and so are the trailing dots and
+// spaces and the :stream suffix of each element, which the file system
+// ignores or resolves to the file itself.
+func windowsPath(path string) string {
+	path = strings.ReplaceAll(path, "/", `\`)
+	switch {
+	case strings.HasPrefix(path, `\\?\UNC\`):
+		path = `\\` + strings.TrimPrefix(path, `\\?\UNC\`)
+	case strings.HasPrefix(path, `\\?\`):
+		path = strings.TrimPrefix(path, `\\?\`)
+	}
+
+	volume := filepath.VolumeName(path)
+	elems := strings.Split(path[len(volume):], `\`)
+	for i, elem := range elems {
+		if elem == "." || elem == ".." {
+			continue
+		}
+		elem, _, _ = strings.Cut(elem, ":")
+		if trimmed := strings.TrimRight(elem, ". "); trimmed != "" {
+			elem = trimmed
+		}
+		elems[i] = elem
+	}
+	return volume + strings.Join(elems, `\`)
+}
+
+// resolvedForms returns the forms of a resolved path the path rules check.
+func (c *Config) resolvedForms(p ResolvedPath) []string {
+	forms := []string{p.Abs}
+	if c.Security.ResolveSymlinks && p.Resolved != "" && p.Resolved != p.Abs {
+		forms = append(forms, p.Resolved)
+	}
+	return forms
+}
+
+// resolveExisting resolves symlinks in the longest existing prefix of an
+// absolute path and appends the rest unchanged.
+func resolveExisting(path string) string {
+	var missing []string
+	for p := path; ; {
+		if resolved, err := filepath.EvalSymlinks(p); err == nil {
+			if runtime.GOOS == "windows" {
+				// Resolved paths may come back in their long form
+				resolved = windowsPath(resolved)
+			}
+			return filepath.Join(append([]string{resolved}, missing...)...)
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return path
+		}
+		missing = append([]string{filepath.Base(p)}, missing...)
+		p = parent
+	}
+}
+
+// foldPathCase lowercases paths on macOS and Windows, whose file systems
+// are case insensitive by default.
+func foldPathCase(path string) string {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return strings.ToLower(path)
+	}
+	return path
+}
+
+// Within reports whether path is root or inside it. Unlike a string prefix
+// check, /tmpfoo is not within /tmp, and paths differing only in case are
+// the same on macOS and Windows.
+func Within(root, path string) bool {
+	rel, err := filepath.Rel(foldPathCase(root), foldPathCase(path))
+	if err != nil || filepath.IsAbs(rel) {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
+
+// Exists reports whether a file or directory exists.
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// underAny reports whether a resolved path is inside one of the entries,
+// as written or with their symlinks resolved.
+func underAny(entries []string, path string) bool {
+	for _, entry := range entries {
+		root, err := ResolvePath(entry)
+		if err != nil {
+			continue
+		}
+		if Within(root.Abs, path) || Within(root.Resolved, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath returns the first entry containing a path, given in the forms
+// returned by pathForms. With require set, every form of the path must be
+// inside the entry, so a symlink inside an allowed directory cannot reach
+// outside it; otherwise any form suffices.
+func (c *Config) matchPath(entries []string, forms []string, require bool) string {
+	if len(forms) == 0 {
+		return ""
+	}
+	if require {
+		// The resolved path is where the file actually is
+		forms = forms[len(forms)-1:]
+	}
+
+	for _, entry := range entries {
+		roots := c.pathForms(entry)
+		for _, form := range forms {
+			for _, root := range roots {
+				if Within(root, form) {
+					return entry
+				}
+			}
+		}
+	}
+	return ""
+}
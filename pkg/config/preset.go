@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// PresetSafeDefault is a read-only profile for first-time users: native
+// tools that read files and describe the system, and a few read-only
+// commands, without a configuration file.
+const PresetSafeDefault = "safe-default"
+
+// Presets are the names of the built-in configurations.
+var Presets = []string{PresetSafeDefault}
+
+// safeDefaultTools are the built-in tools of the safe-default preset,
+// none of which changes anything.
+var safeDefaultTools = []string{
+	"discover_commands",
+	"read_file_chunk",
+	"tail_file",
+	"stat_path",
+	"hash_file",
+	"analyze_disk_usage",
+	"get_capabilities",
+	"explain_policy",
+	"search_tools",
+	"get_output_page",
+	"git_status",
+	"git_diff",
+	"git_log",
+}
+
+// safeDefaultDeniedPaths are the credential directories under the home
+// directory the safe-default preset keeps tools and commands out of.
+var safeDefaultDeniedPaths = []string{
+	".ssh", ".gnupg", ".aws", ".azure", ".kube", ".docker",
+	filepath.Join(".config", "gcloud"), ".netrc",
+}
+
+// Preset returns the built-in configuration called name.
+func Preset(name string) (*Config, error) {
+	switch name {
+	case PresetSafeDefault:
+		return safeDefault(), nil
+	default:
+		return nil, apperrors.ConfigurationError(fmt.Sprintf("unknown preset %q: must be one of %s", name, strings.Join(Presets, ", ")))
+	}
+}
+
+// safeDefault returns the safe-default preset: the default configuration
+// limited to read-only tools and commands.
+func safeDefault() *Config {
+	cfg := Default()
+	cfg.Server.Tools = safeDefaultTools
+	cfg.Server.WelcomeMessage = true
+	cfg.Notifications.Disabled = true
+
+	cfg.Security.AllowedCommands = []string{"ls", "grep", "uname"}
+	cfg.Security.DenyPathArgs = true
+	// Tools and commands are kept to the project the server is started in
+	if root, err := os.Getwd(); err == nil {
+		cfg.Security.AllowedPaths = []string{root}
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		for _, dir := range safeDefaultDeniedPaths {
+			cfg.Security.DeniedPaths = append(cfg.Security.DeniedPaths, filepath.Join(home, dir))
+		}
+	}
+	cfg.Execution.MaxOutputSize = 1024 * 1024 // 1MB
+
+	cfg.Commands = []Command{
+		{
+			Name:        "list_directory",
+			Description: "List the files in a directory with their permissions, sizes and modification times. Pass the directory as an argument; defaults to the working directory.",
+			Command:     "ls",
+			Args:        []string{"-la"},
+			AllowArgs:   true,
+		},
+		{
+			Name:        "search_files",
+			Description: "Search text files for a pattern, recursively, printing matching lines with their file and line number. Pass the pattern, then the files or directories to search.",
+			Command:     "grep",
+			Args:        []string{"-rnI"},
+			AllowArgs:   true,
+		},
+		{
+			Name:        "system_info",
+			Description: "Show the operating system, kernel version, host name and architecture.",
+			Command:     "uname",
+			Args:        []string{"-a"},
+		},
+	}
+	return cfg
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Revision identifies the content of a configuration: the first 12 bytes
+// of the SHA-256 of its YAML encoding, in hex. Configurations with the
+// same settings have the same revision, however they were written.
+func (c *Config) Revision() string {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:12])
+}
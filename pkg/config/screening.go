@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Screening heuristics.
+const (
+	HeuristicBase64Payload = "base64_payload" // Long base64 arguments, which can hide commands or data
+	HeuristicShellRCWrite  = "shell_rc_write" // Writes to shell startup files, which persist commands
+	HeuristicPasteSite     = "paste_site"     // URLs of paste sites, also percent- or base64-encoded
+)
+
+// Heuristics are the names of the screening heuristics.
+var Heuristics = []string{HeuristicBase64Payload, HeuristicShellRCWrite, HeuristicPasteSite}
+
+// Screening actions.
+const (
+	ScreeningBlock   = "block"   // Deny flagged requests
+	ScreeningApprove = "approve" // Hold flagged requests for approval by two operators
+)
+
+// DefaultMinBase64Length applies when screening.min_base64_length is not
+// set.
+const DefaultMinBase64Length = 40
+
+// ScreeningConfig flags requests that look like a prompt injection at
+// work, such as encoded payloads or persistence through shell startup
+// files.
+type ScreeningConfig struct {
+	// Action is what happens to flagged requests: block, or approve to
+	// hold them for two operators. Screening is off when empty
+	Action string `yaml:"action,omitempty"`
+
+	// Heuristics are the heuristics to apply; all when empty
+	Heuristics []string `yaml:"heuristics,omitempty"`
+
+	// PasteSites are hosts added to the built-in paste sites
+	PasteSites []string `yaml:"paste_sites,omitempty"`
+
+	// MinBase64Length is the length from which arguments that decode as
+	// base64 are flagged; defaults to 40
+	MinBase64Length int `yaml:"min_base64_length,omitempty"`
+}
+
+// Enabled reports whether requests are screened.
+func (s ScreeningConfig) Enabled() bool {
+	return s.Action != ""
+}
+
+// Applies reports whether a heuristic is applied.
+func (s ScreeningConfig) Applies(heuristic string) bool {
+	return len(s.Heuristics) == 0 || slices.Contains(s.Heuristics, heuristic)
+}
+
+// validate checks the screening settings.
+func (s ScreeningConfig) validate() error {
+	switch s.Action {
+	case "", ScreeningBlock, ScreeningApprove:
+	default:
+		return fmt.Errorf("invalid action %q (must be: block, approve)", s.Action)
+	}
+	for _, h := range s.Heuristics {
+		if !slices.Contains(Heuristics, h) {
+			return fmt.Errorf("unknown heuristic %q: must be one of %s", h, strings.Join(Heuristics, ", "))
+		}
+	}
+	for _, host := range s.PasteSites {
+		if host == "" || strings.ContainsAny(host, "/: ") {
+			return fmt.Errorf("invalid paste site %q: must be a host name such as paste.example.com", host)
+		}
+	}
+	if s.MinBase64Length < 0 {
+		return fmt.Errorf("min_base64_length cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// StateDir returns the directory the server keeps its state in unless
+// configured otherwise: approvals, the control token, counters, caches,
+// backups and the trash.
+func StateDir() string {
+	if cache, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(cache, "simple-mcp-runner")
+	}
+	return filepath.Join(os.TempDir(), "simple-mcp-runner")
+}
+
+// KeyDir returns the directory operator keys are kept in unless
+// configured otherwise.
+func KeyDir() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "simple-mcp-runner")
+	}
+	return filepath.Join(StateDir(), "keys")
+}
+
+// StatePaths returns the files and directories holding the server's state
+// and keys. They are denied like denied_paths, so clients cannot forge
+// approvals, read tokens or rewrite the audit trail through the server's
+// own tools and commands.
+func (c *Config) StatePaths() []string {
+	paths := []string{StateDir(), KeyDir()}
+	for _, path := range []string{
+		c.Security.SuggestionsFile,
+		c.Security.StateFile,
+		c.Logging.ProtocolFile,
+		c.Discovery.IndexFile,
+		c.History.Path,
+		c.History.SigningKey,
+		c.Backup.Dir,
+		c.Trash.Dir,
+		c.Approvals.File,
+		c.Approvals.KeyFile,
+		c.Catalog.PublicKey,
+		c.Catalog.CacheFile,
+		c.Usage.File,
+		c.Instance.LockDir,
+		c.Execution.LockDir,
+		c.Control.TokenFile,
+		c.Telemetry.File,
+	} {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	for _, op := range c.Approvals.Operators {
+		if op.PublicKey != "" {
+			paths = append(paths, op.PublicKey)
+		}
+	}
+	return paths
+}
+
+// deniedEntries returns the denied_paths entries and the state paths.
+func (c *Config) deniedEntries() []string {
+	return append(append([]string(nil), c.Security.DeniedPaths...), c.StatePaths()...)
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"time"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// MinTelemetryInterval is the shortest interval between telemetry
+// reports.
+const MinTelemetryInterval = time.Hour
+
+// TelemetryConfig contains the settings of the anonymous usage reports
+// sent to help maintainers prioritize. Telemetry is off unless enabled,
+// and the DO_NOT_TRACK and SIMPLE_MCP_RUNNER_TELEMETRY=off environment
+// variables turn it off whatever the configuration says.
+type TelemetryConfig struct {
+	// Enabled sends reports to Endpoint
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// Endpoint receives the reports as JSON POSTs; an https URL, or an
+	// http URL of a loopback host. Required when enabled
+	Endpoint string `yaml:"endpoint,omitempty"`
+
+	// Interval is how often a report is sent; defaults to 24h
+	Interval Duration `yaml:"interval,omitempty"`
+
+	// File holds the counts not sent yet, the random installation ID
+	// being kept next to it; defaults to a file under the user cache
+	// directory
+	File string `yaml:"file,omitempty"`
+}
+
+// validate checks the telemetry settings.
+func (t TelemetryConfig) validate() error {
+	if t.Enabled && t.Endpoint == "" {
+		return apperrors.ValidationError("endpoint is required when telemetry is enabled", "telemetry.endpoint")
+	}
+	if t.Endpoint != "" && !isWebhookURL(t.Endpoint) {
+		return apperrors.ValidationError("endpoint must be an https URL, or an http URL of a loopback host", "telemetry.endpoint")
+	}
+	if t.Interval != 0 && t.Interval.Std() < MinTelemetryInterval {
+		return apperrors.ValidationError("interval must be at least 1h", "telemetry.interval")
+	}
+	return nil
+}
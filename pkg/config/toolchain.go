@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// Toolchain pins the version of a tool for the commands run in a
+// directory, such as go1.21 for /work/projA, by putting the directory of
+// its binaries first in PATH.
+type Toolchain struct {
+	// Path is the directory the pin applies to, subdirectories included
+	Path string `yaml:"path"`
+
+	// Name identifies the pinned tool and version in results, e.g. go1.21
+	Name string `yaml:"name"`
+
+	// Bin is the absolute directory of the pinned binaries, e.g.
+	// /opt/go1.21/bin
+	Bin string `yaml:"bin"`
+}
+
+// PinnedToolchains returns the toolchains pinned for a working directory,
+// those of the most specific path first.
+func (c *Config) PinnedToolchains(workdir string) []Toolchain {
+	forms := c.pathForms(workdir)
+	var pinned []Toolchain
+	for _, tc := range c.Execution.Toolchains {
+		if c.matchPath([]string{tc.Path}, forms, false) != "" {
+			pinned = append(pinned, tc)
+		}
+	}
+	sort.SliceStable(pinned, func(i, j int) bool {
+		return len(filepath.Clean(pinned[i].Path)) > len(filepath.Clean(pinned[j].Path))
+	})
+	return pinned
+}
+
+// validate checks a toolchain pin.
+func (t Toolchain) validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("toolchain name is required")
+	}
+	if t.Path == "" {
+		return fmt.Errorf("toolchain %s: path is required", t.Name)
+	}
+	if t.Bin == "" {
+		return fmt.Errorf("toolchain %s: bin is required", t.Name)
+	}
+	if !filepath.IsAbs(t.Bin) {
+		return fmt.Errorf("toolchain %s: bin must be an absolute path", t.Name)
+	}
+	return nil
+}
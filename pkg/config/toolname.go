@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	apperrors "github.com/mjmorales/simple-mcp-runner/pkg/errors"
+)
+
+// BuiltinTools are the names of the built-in tools. Configured commands and
+// script tools cannot use the names of those a configuration registers
+// (see RegistersBuiltinTool), as they would replace the built-in tool.
+var BuiltinTools = []string{
+	"discover_commands",
+	"execute_command",
+	"execute_batch",
+	"check_commands",
+	"list_schedule_runs",
+	"watch_path",
+	"list_watches",
+	"stop_watch",
+	"tail_file",
+	"list_processes",
+	"get_process_info",
+	"terminate_process",
+	"list_listening_ports",
+	"get_environment",
+	"download_file",
+	"read_file_chunk",
+	"http_request",
+	"extract_archive",
+	"create_archive",
+	"stat_path",
+	"hash_file",
+	"analyze_disk_usage",
+	"delete_path",
+	"restore_path",
+	"list_trash",
+	"change_permissions",
+	"notify_user",
+	"undo_last_change",
+	"clear_quarantine",
+	"list_containers",
+	"container_logs",
+	"exec_in_container",
+	"create_tmux_session",
+	"list_tmux_sessions",
+	"send_tmux_keys",
+	"capture_tmux_pane",
+	"kill_tmux_session",
+	"start_repl",
+	"send_to_repl",
+	"stop_repl",
+	"explain_policy",
+	"get_capabilities",
+	"list_tool_groups",
+	"select_toolset",
+	"search_tools",
+	"compare_executions",
+	"get_output_page",
+	"rerun_execution",
+	"git_status",
+	"git_diff",
+	"git_log",
+	"run_tests",
+	"run_linter",
+	"format_code",
+}
+
+// RegistersBuiltinTool reports whether the server registers a built-in
+// tool under the configuration: it must be listed in server.tools when that
+// is set, and opt-in tools must be enabled and others not disabled. Tools
+// that also need something on the host, such as git, or a platform, such as
+// clear_quarantine, count as registered.
+func (c *Config) RegistersBuiltinTool(name string) bool {
+	if !slices.Contains(BuiltinTools, name) {
+		return false
+	}
+	if len(c.Server.Tools) > 0 && !slices.Contains(c.Server.Tools, name) {
+		return false
+	}
+	switch name {
+	case "download_file":
+		return c.Transfer.DownloadEnabled
+	case "http_request":
+		return c.HTTP.Enabled
+	case "notify_user":
+		return !c.Notifications.Disabled
+	case "undo_last_change":
+		return !c.Backup.Disabled
+	case "delete_path", "restore_path", "list_trash":
+		return !c.Trash.Disabled
+	case "clear_quarantine":
+		return c.Security.AllowClearQuarantine
+	case "list_containers", "container_logs", "exec_in_container":
+		return c.Containers.Enabled
+	case "create_tmux_session", "list_tmux_sessions", "send_tmux_keys", "capture_tmux_pane", "kill_tmux_session":
+		return c.Tmux.Enabled
+	case "start_repl", "send_to_repl", "stop_repl":
+		return c.REPL.Enabled
+	}
+	return true
+}
+
+// checkBuiltinClash fails when a configured tool name, or its ID, is the
+// name of a built-in tool the configuration registers.
+func (c *Config) checkBuiltinClash(kind, name, field string) error {
+	id := ToolID(name)
+	if !c.RegistersBuiltinTool(id) {
+		return nil
+	}
+	if id == name {
+		return apperrors.ValidationError(fmt.Sprintf("%s name %s is the name of a built-in tool", kind, name), field)
+	}
+	return apperrors.ValidationError(
+		fmt.Sprintf("%s name %s collides with the built-in tool %s once hyphens and dots become underscores", kind, name, id),
+		field,
+	)
+}
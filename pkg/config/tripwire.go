@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Tripwire is a decoy command no legitimate client has a reason to run,
+// such as reading /etc/shadow. Matching requests are never run: they are
+// denied like any command that is not allowed, and raise an alert.
+type Tripwire struct {
+	// Name identifies the tripwire in alerts and the history
+	Name string `yaml:"name"`
+
+	// Commands are entries in the same forms as blocked_commands
+	Commands []string `yaml:"commands"`
+
+	// ArgsPattern is a regular expression matched against the arguments
+	// joined by spaces; any arguments match when empty
+	ArgsPattern string `yaml:"args_pattern,omitempty"`
+}
+
+// Matches reports whether a request trips the tripwire.
+func (t Tripwire) Matches(command string, args []string) bool {
+	if matchCommand(t.Commands, normalizeCommand(command, ""), (*commandForms).blockedBy) == "" {
+		return false
+	}
+	if t.ArgsPattern == "" {
+		return true
+	}
+	re, err := regexp.Compile(t.ArgsPattern)
+	return err == nil && re.MatchString(strings.Join(args, " "))
+}
+
+// validate checks a tripwire.
+func (t Tripwire) validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("tripwire name is required")
+	}
+	if len(t.Commands) == 0 {
+		return fmt.Errorf("tripwire %s: commands are required", t.Name)
+	}
+	for _, entry := range t.Commands {
+		if _, err := parseCommandRule(entry); err != nil {
+			return fmt.Errorf("tripwire %s: %v", t.Name, err)
+		}
+	}
+	if _, err := regexp.Compile(t.ArgsPattern); err != nil {
+		return fmt.Errorf("tripwire %s: invalid args_pattern: %v", t.Name, err)
+	}
+	return nil
+}
+
+// isWebhookURL checks that a URL is an absolute https URL, or an http URL
+// of a loopback host such as a local collector.
+func isWebhookURL(raw string) bool {
+	if isHTTPSURL(raw) {
+		return true
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "http" {
+		return false
+	}
+	ip := net.ParseIP(u.Hostname())
+	return u.Hostname() == "localhost" || (ip != nil && ip.IsLoopback())
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is a duration in the configuration, written as in Go: "30s",
+// "5m" or "1h30m". Zero, or an empty string, means unset.
+type Duration time.Duration
+
+// Std returns the duration as a time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// String formats the duration without zero trailing units, e.g. "5m"
+// rather than "5m0s", and an unset duration as "".
+func (d Duration) String() string {
+	if d == 0 {
+		return ""
+	}
+	s := time.Duration(d).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// ParseDuration parses a duration written as in Go. An empty string is
+// an unset duration.
+func ParseDuration(s string) (Duration, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: use a number with a unit such as 30s, 5m or 1h", s)
+	}
+	return Duration(d), nil
+}
+
+// UnmarshalYAML parses a duration, failing with the line it is on.
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected a duration such as 30s", node.Line)
+	}
+	parsed, err := ParseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalYAML writes the duration as it is parsed.
+func (d Duration) MarshalYAML() (any, error) {
+	return d.String(), nil
+}
+
+// ByteSize is a size in the configuration, written as a number of bytes
+// or with a unit: "512KB", "10MB", "1GiB". KB, MB, GB and TB are powers of
+// 1000; KiB, MiB, GiB and TiB powers of 1024.
+type ByteSize int64
+
+// sizeUnits are the units of sizes, longest suffixes first.
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+	{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+	{"B", 1},
+}
+
+// String formats the size with the largest binary unit it is a whole
+// number of, e.g. "10MiB".
+func (s ByteSize) String() string {
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{{"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}} {
+		if s != 0 && int64(s)%unit.bytes == 0 {
+			return strconv.FormatInt(int64(s)/unit.bytes, 10) + unit.suffix
+		}
+	}
+	return strconv.FormatInt(int64(s), 10)
+}
+
+// ParseByteSize parses a number of bytes, optionally with a unit.
+func ParseByteSize(s string) (ByteSize, error) {
+	value := strings.TrimSpace(s)
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if len(value) > len(unit.suffix) && strings.EqualFold(value[len(value)-len(unit.suffix):], unit.suffix) {
+			value, multiplier = strings.TrimSpace(value[:len(value)-len(unit.suffix)]), unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || (n != 0 && (n*multiplier)/n != multiplier) {
+		return 0, fmt.Errorf("invalid size %q: use a number of bytes or a number with a unit such as 512KB, 10MB or 1GiB", s)
+	}
+	return ByteSize(n * multiplier), nil
+}
+
+// UnmarshalYAML parses a size, failing with the line it is on.
+func (s *ByteSize) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected a size such as 10MB", node.Line)
+	}
+	parsed, err := ParseByteSize(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*s = parsed
+	return nil
+}
+
+// MarshalYAML writes the size as it is parsed.
+func (s ByteSize) MarshalYAML() (any, error) {
+	return s.String(), nil
+}
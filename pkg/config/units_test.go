@@ -0,0 +1,82 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ByteSize
+		wantErr bool
+	}{
+		{in: "0", want: 0},
+		{in: "512", want: 512},
+		{in: "512B", want: 512},
+		{in: "512KB", want: 512_000},
+		{in: "10MB", want: 10_000_000},
+		{in: "1GiB", want: 1 << 30},
+		{in: "2TiB", want: 2 << 40},
+		{in: " 10 mib ", want: 10 << 20}, // Spaces and case do not matter
+		{in: "+5KB", want: 5_000},
+		// Negative sizes parse; Validate refuses them where they are used
+		{in: "-1", want: -1},
+		{in: "-2KiB", want: -2 << 10},
+		{in: "9223372036854775807", want: 1<<63 - 1},
+		{in: "9223372036854775808", wantErr: true},   // Overflows int64
+		{in: "9223372036854775807KB", wantErr: true}, // Overflows with the unit
+		{in: "-9223372036854775808KiB", wantErr: true},
+		{in: "1.5MB", wantErr: true}, // Only whole numbers
+		{in: "1e6", wantErr: true},
+		{in: "10XB", wantErr: true}, // Unknown units
+		{in: "10 bytes", wantErr: true},
+		{in: "MB", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseByteSize(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseByteSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Duration
+		wantErr bool
+	}{
+		{in: "", want: 0}, // Unset
+		{in: "  ", want: 0},
+		{in: "0s", want: 0},
+		{in: "30s", want: Duration(30 * time.Second)},
+		{in: " 5m ", want: Duration(5 * time.Minute)},
+		{in: "1h30m", want: Duration(90 * time.Minute)},
+		{in: "1.5h", want: Duration(90 * time.Minute)}, // Decimals as in Go
+		{in: "250ms", want: Duration(250 * time.Millisecond)},
+		// Negative durations parse; Validate refuses them where they are used
+		{in: "-5s", want: Duration(-5 * time.Second)},
+		{in: "2562047h", want: Duration(2562047 * time.Hour)},
+		{in: "2562048h", wantErr: true}, // Overflows int64 nanoseconds
+		{in: "30", wantErr: true},       // Units are required
+		{in: "5d", wantErr: true},       // Unknown units
+		{in: "5 min", wantErr: true},
+		{in: "1,5h", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDuration(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tt.in, got.Std(), tt.want.Std())
+		}
+	}
+}
@@ -52,6 +52,18 @@ func (b *DiscoveryBuilder) WithDescriptions(include bool) *DiscoveryBuilder {
 	return b
 }
 
+// WithOffset skips the first results.
+func (b *DiscoveryBuilder) WithOffset(offset int) *DiscoveryBuilder {
+	b.req.Offset = offset
+	return b
+}
+
+// WithCursor continues after the page a cursor was returned with.
+func (b *DiscoveryBuilder) WithCursor(cursor string) *DiscoveryBuilder {
+	b.req.Cursor = cursor
+	return b
+}
+
 // Build returns the discovery request.
 func (b *DiscoveryBuilder) Build() *types.CommandDiscoveryRequest {
 	return b.req
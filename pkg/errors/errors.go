@@ -26,6 +26,9 @@ const (
 	ErrorTypeNotFound ErrorType = "not_found"
 	// ErrorTypeInternal indicates an internal server error.
 	ErrorTypeInternal ErrorType = "internal"
+	// ErrorTypeRateLimited indicates a request refused because too many
+	// are pending.
+	ErrorTypeRateLimited ErrorType = "rate_limited"
 )
 
 // Error represents an enhanced error with additional context.
@@ -201,4 +204,9 @@ func NotFoundError(message string, resource string) *Error {
 // InternalError creates an internal error.
 func InternalError(message string) *Error {
 	return New(ErrorTypeInternal, message)
+}
+
+// RateLimitedError creates a rate limited error.
+func RateLimitedError(message string, limit string) *Error {
+	return New(ErrorTypeRateLimited, message).WithContext("limit", limit)
 }
\ No newline at end of file
@@ -9,6 +9,18 @@ type CommandInfo struct {
 	Path        string `json:"path"`
 	Description string `json:"description,omitempty"`
 	Executable  bool   `json:"executable"`
+	Quarantined bool   `json:"quarantined,omitempty"` // macOS quarantines the binary and the user has not approved it
+
+	// UsageExamples and CommonFlags are returned with descriptions for
+	// commands whose invocation is documented.
+	UsageExamples []string      `json:"usage_examples,omitempty"`
+	CommonFlags   []CommandFlag `json:"common_flags,omitempty"`
+}
+
+// CommandFlag describes a command-line flag.
+type CommandFlag struct {
+	Flag        string `json:"flag"`
+	Description string `json:"description,omitempty"`
 }
 
 // CommandExecutionRequest represents a request to execute a command.
@@ -18,18 +30,365 @@ type CommandExecutionRequest struct {
 	WorkDir string   `json:"workdir,omitempty"`
 	Env     []string `json:"env,omitempty"`
 	Timeout string   `json:"timeout,omitempty"` // Duration string like "30s"
+
+	// ApprovalID runs a package operation held by a package policy under
+	// an approved request
+	ApprovalID string `json:"approval_id,omitempty"`
+
+	// MaxTimeout and MaxOutputSize are the limits of a configured command,
+	// applied below the global ones; clients cannot set them
+	MaxTimeout    time.Duration `json:"-"`
+	MaxOutputSize int64         `json:"-"`
+
+	// Priority is the scheduling priority of a configured command
+	Priority string `json:"-"`
+
+	// Isolation and Category are the isolation preset of a configured
+	// command and the category choosing the variables it keeps
+	Isolation string `json:"-"`
+	Category  string `json:"-"`
+
+	// SummaryErrorPatterns and SummaryWarnPatterns replace the digest
+	// patterns for a configured command
+	SummaryErrorPatterns []string `json:"-"`
+	SummaryWarnPatterns  []string `json:"-"`
 }
 
 // CommandExecutionResult represents the result of command execution.
 type CommandExecutionResult struct {
-	Stdout       string        `json:"stdout"`
-	Stderr       string        `json:"stderr"`
-	ExitCode     int           `json:"exit_code"`
-	StartTime    time.Time     `json:"start_time"`
-	EndTime      time.Time     `json:"end_time"`
-	Duration     time.Duration `json:"duration_ms"`
-	TimedOut     bool          `json:"timed_out"`
-	ErrorMessage string        `json:"error_message,omitempty"`
+	Stdout       string         `json:"stdout"`
+	Stderr       string         `json:"stderr"`
+	StdoutFile   string         `json:"stdout_file,omitempty"` // Full stdout, when it exceeded the spill threshold
+	StderrFile   string         `json:"stderr_file,omitempty"` // Full stderr, when it exceeded the spill threshold
+	ExitCode     int            `json:"exit_code"`
+	StartTime    time.Time      `json:"start_time"`
+	EndTime      time.Time      `json:"end_time"`
+	Duration     time.Duration  `json:"duration_ms"`
+	TimedOut     bool           `json:"timed_out"`
+	ErrorMessage string         `json:"error_message,omitempty"`
+	HistoryID    string         `json:"history_id,omitempty"`   // ID of the stored execution record
+	LockWait     time.Duration  `json:"lock_wait_ms,omitempty"` // Time spent waiting for the workdir lock
+	Changes      *FileChanges   `json:"changes,omitempty"`      // Files the command touched, when tracked
+	SnapshotRef  string         `json:"snapshot_ref,omitempty"` // Git ref recording the workdir before a risky run
+	Receipt      *Receipt       `json:"receipt,omitempty"`      // Signature over the execution record, when signing is configured
+	Priority     string         `json:"priority,omitempty"`     // Scheduling priority applied to the process, when configured
+	Isolation    string         `json:"isolation,omitempty"`    // Isolation preset the command ran with, when configured
+	WorkDir      string         `json:"workdir,omitempty"`      // Working directory inferred by workdir_mode
+	Summary      *OutputSummary `json:"summary,omitempty"`      // Digest of outputs over the summary threshold
+	Quarantine   *Quarantine    `json:"quarantine,omitempty"`   // Quarantine of the binary, when it failed to run quarantined
+	Provenance   *Provenance    `json:"provenance,omitempty"`   // What ran the command and under which configuration
+
+	// Preflight is the pre-flight check the command failed before
+	// starting
+	Preflight *PreflightFailure `json:"preflight,omitempty"`
+
+	// DevEnvironment is the project environment the command ran in
+	DevEnvironment *DevEnvironment `json:"dev_environment,omitempty"`
+
+	// Toolchains are the tool versions pinned for the working directory
+	Toolchains []Toolchain `json:"toolchains,omitempty"`
+}
+
+// DevEnvironment is the project environment a command ran in, as
+// execution.dev_environment detected it.
+type DevEnvironment struct {
+	Kind string `json:"kind"` // nix or devcontainer
+	Root string `json:"root"` // Directory of the flake.nix or .devcontainer
+}
+
+// Toolchain is a tool version pinned for the working directory of a
+// command.
+type Toolchain struct {
+	Name string `json:"name"` // Pinned tool and version, e.g. go1.21
+	Bin  string `json:"bin"`  // Directory put first in PATH
+}
+
+// Provenance records where a result came from, so it can be audited and
+// reproduced after the configuration changes.
+type Provenance struct {
+	Tool           string           `json:"tool"`                     // Tool the command was run with
+	ConfigRevision string           `json:"config_revision"`          // Revision of the configuration in effect
+	BinaryPath     string           `json:"binary_path,omitempty"`    // File the command resolved to
+	BinaryVersion  string           `json:"binary_version,omitempty"` // Version the binary reported
+	Security       *SecurityProfile `json:"security,omitempty"`       // Security profile in effect
+}
+
+// Quarantine describes the macOS quarantine attribute of a downloaded
+// binary, which keeps Gatekeeper from running it until it is approved or
+// the attribute is cleared.
+type Quarantine struct {
+	Path         string    `json:"path"`
+	Agent        string    `json:"agent,omitempty"` // Application that downloaded the binary
+	DownloadedAt time.Time `json:"downloaded_at,omitzero"`
+	Cleared      bool      `json:"cleared,omitempty"` // The attribute was removed by clear_quarantine
+}
+
+// OutputSummary digests the streams of a command whose output exceeded
+// the summary threshold.
+type OutputSummary struct {
+	Stdout *StreamSummary `json:"stdout,omitempty"`
+	Stderr *StreamSummary `json:"stderr,omitempty"`
+}
+
+// StreamSummary digests a whole output stream, including what did not
+// fit in the result. Line numbers are 0-based, as in get_output_page.
+type StreamSummary struct {
+	Bytes        int64        `json:"bytes"`
+	Lines        int          `json:"lines"`
+	Errors       int          `json:"errors"`   // Lines matching an error pattern
+	Warnings     int          `json:"warnings"` // Other lines matching a warning pattern
+	ErrorLines   []OutputLine `json:"error_lines,omitempty"`
+	WarningLines []OutputLine `json:"warning_lines,omitempty"`
+	Tail         []OutputLine `json:"tail,omitempty"`
+}
+
+// Receipt is a signed statement of what an execution ran and produced.
+// The payload is base64 encoded JSON, signed as is with ed25519.
+type Receipt struct {
+	KeyID     string `json:"key_id"`
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+// FileChanges lists the files a command created, modified and deleted in
+// its working directory.
+type FileChanges struct {
+	Created   []string `json:"created,omitempty"`
+	Modified  []string `json:"modified,omitempty"`
+	Deleted   []string `json:"deleted,omitempty"`
+	Truncated bool     `json:"truncated,omitempty"` // File or change limits were reached
+}
+
+// ExecutionComparison is the difference between two recorded executions.
+type ExecutionComparison struct {
+	BaseID   string        `json:"base_id"`
+	TargetID string        `json:"target_id"`
+	Changes  []FieldChange `json:"changes"` // Metadata that differs
+	Stdout   OutputDiff    `json:"stdout"`
+	Stderr   OutputDiff    `json:"stderr"`
+}
+
+// FieldChange is a metadata field that differs between two executions.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Base   string `json:"base"`
+	Target string `json:"target"`
+}
+
+// OutputDiff is the line-based difference between two outputs.
+type OutputDiff struct {
+	Identical bool       `json:"identical"`
+	Added     int        `json:"added"`
+	Removed   int        `json:"removed"`
+	Unchanged int        `json:"unchanged"`
+	Hunks     []DiffHunk `json:"hunks"`
+	Truncated bool       `json:"truncated,omitempty"` // Hunks were left out to respect the line limit
+}
+
+// DiffHunk is a run of changed lines with unchanged lines around them.
+// Lines start with "+" when added, "-" when removed and " " when
+// unchanged; starts are 1-based, as in unified diffs.
+type DiffHunk struct {
+	BaseStart   int      `json:"base_start"`
+	BaseLines   int      `json:"base_lines"`
+	TargetStart int      `json:"target_start"`
+	TargetLines int      `json:"target_lines"`
+	Lines       []string `json:"lines"`
+}
+
+// OutputPage is a page of the lines of a recorded execution's output.
+type OutputPage struct {
+	HistoryID  string       `json:"history_id"`
+	Stream     string       `json:"stream"`
+	Lines      []OutputLine `json:"lines"`
+	NextOffset int          `json:"next_offset"`       // Line to pass as offset for the next page
+	EOF        bool         `json:"eof"`               // No lines are left after this page
+	Spilled    bool         `json:"spilled,omitempty"` // Read from the spill file holding the full output
+}
+
+// OutputLine is a line of output and its 0-based line number.
+type OutputLine struct {
+	Number    int    `json:"number"`
+	Text      string `json:"text"`
+	Truncated bool   `json:"truncated,omitempty"` // The line was longer than the byte limit
+}
+
+// Execution sources recorded in the history.
+const (
+	ExecutionSourceTool     = "tool"
+	ExecutionSourceSchedule = "schedule"
+	ExecutionSourceWatch    = "watch"
+)
+
+// ExecutionRecord represents a stored command execution.
+type ExecutionRecord struct {
+	ID        string                  `json:"id"`
+	Source    string                  `json:"source"`
+	Tool      string                  `json:"tool,omitempty"`
+	Schedule  string                  `json:"schedule,omitempty"`
+	Request   CommandExecutionRequest `json:"request"`
+	Result    *CommandExecutionResult `json:"result,omitempty"`
+	Error     string                  `json:"error,omitempty"`
+	Decision  string                  `json:"decision,omitempty"`  // Policy decision, allowed or denied
+	Session   string                  `json:"session,omitempty"`   // MCP session the request arrived on
+	Client    string                  `json:"client,omitempty"`    // Client name reported by the session
+	User      string                  `json:"user,omitempty"`      // Authenticated principal
+	Tripwire  string                  `json:"tripwire,omitempty"`  // Tripwire the request tripped
+	Screening string                  `json:"screening,omitempty"` // Screening heuristic that flagged the request
+	Timestamp time.Time               `json:"timestamp"`
+}
+
+// Policy decisions recorded in the history.
+const (
+	PolicyDecisionAllowed = "allowed"
+	PolicyDecisionDenied  = "denied"
+)
+
+// Outcomes of a policy rule.
+const (
+	PolicyRulePass = "pass"
+	PolicyRuleDeny = "deny"
+	PolicyRuleSkip = "skip" // Not configured or not applicable
+)
+
+// PolicyRule is one security rule evaluated for a command.
+type PolicyRule struct {
+	Name     string `json:"name"`
+	Outcome  string `json:"outcome"`
+	Detail   string `json:"detail"`
+	Decisive bool   `json:"decisive,omitempty"` // The first rule that denies
+}
+
+// PolicyExplanation describes how the security policy treats a command,
+// with the rules in the order they are evaluated.
+type PolicyExplanation struct {
+	Command  string       `json:"command"`
+	Args     []string     `json:"args,omitempty"`
+	WorkDir  string       `json:"workdir,omitempty"`
+	Allowed  bool         `json:"allowed"`
+	Decision string       `json:"decision"`
+	Rules    []PolicyRule `json:"rules"`
+}
+
+// Capabilities describes the limits, security profile and features of the
+// server, so clients can plan within them instead of discovering them
+// through failures.
+type Capabilities struct {
+	App      string           `json:"app"`
+	OS       string           `json:"os"`
+	Limits   CapabilityLimits `json:"limits"`
+	Security SecurityProfile  `json:"security"`
+	Features []string         `json:"features"` // Optional features that are enabled
+}
+
+// ConfigSummary describes at a glance what a server will and will not do.
+// It holds no secrets: no environment values, credentials or keys, and
+// paths under the home directory start with ~.
+type ConfigSummary struct {
+	Capabilities
+	Tools           []string `json:"tools"`    // Registered tools
+	Commands        []string `json:"commands"` // Configured commands
+	AllowedPaths    []string `json:"allowed_paths"`
+	DeniedPaths     []string `json:"denied_paths,omitempty"`
+	AllowedCommands []string `json:"allowed_commands,omitempty"`
+	BlockedCommands []string `json:"blocked_commands,omitempty"`
+}
+
+// Types of server events.
+const (
+	EventServerStarted   = "server_started"
+	EventServerStopping  = "server_stopping"
+	EventServerDraining  = "server_draining"
+	EventSessionStarted  = "session_started"
+	EventToolRegistered  = "tool_registered"
+	EventToolRemoved     = "tool_removed"
+	EventCommandsUpdated = "commands_updated"
+	EventExecutionDenied = "execution_denied"
+	EventBudgetExceeded  = "budget_exceeded"
+	EventSwitchChanged   = "switch_changed"
+	EventCommandKilled   = "command_killed"
+	EventOutputBudget    = "output_budget_used_up"
+)
+
+// ServerEvent is an entry of the server's activity feed: a lifecycle or
+// policy event. Seq increases by one per event, so clients can tell which
+// events they have seen.
+type ServerEvent struct {
+	Seq     int64          `json:"seq"`
+	Time    time.Time      `json:"time"`
+	Type    string         `json:"type"`
+	Message string         `json:"message"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+// TripwireAlert reports a request for a tripwire command. Text summarizes
+// it, so chat webhooks can post the alert as it is.
+type TripwireAlert struct {
+	Severity string    `json:"severity"`
+	Tripwire string    `json:"tripwire"`
+	Command  string    `json:"command"`
+	Args     []string  `json:"args,omitempty"`
+	WorkDir  string    `json:"workdir,omitempty"`
+	Session  string    `json:"session,omitempty"`
+	Client   string    `json:"client,omitempty"`
+	User     string    `json:"user,omitempty"`
+	Time     time.Time `json:"time"`
+	Text     string    `json:"text"`
+}
+
+// CapabilityLimits are the limits commands and tools run within.
+type CapabilityLimits struct {
+	DefaultTimeout   string `json:"default_timeout"`
+	MaxTimeout       string `json:"max_timeout"`
+	MaxOutputSize    int64  `json:"max_output_size"`
+	MaxConcurrent    int    `json:"max_concurrent"`
+	MaxQueue         int    `json:"max_queue"`
+	MaxCommandLength int    `json:"max_command_length"`
+	MaxBatchSteps    int    `json:"max_batch_steps"`
+	MaxWatches       int    `json:"max_watches"`
+	MaxDownloadSize  int64  `json:"max_download_size"`
+	MaxChunkSize     int64  `json:"max_chunk_size"`
+}
+
+// SecurityProfile summarizes the security policy.
+type SecurityProfile struct {
+	Policy             string `json:"policy"`              // enforce or learn
+	ShellExpansion     bool   `json:"shell_expansion"`     // Shell metacharacters are allowed in arguments
+	RestrictedCommands bool   `json:"restricted_commands"` // Only allowed commands may run
+	BlockedCommands    int    `json:"blocked_commands"`    // Number of blocked command entries
+	RestrictedPaths    bool   `json:"restricted_paths"`    // Paths are limited to allowed paths
+	Conditions         int    `json:"conditions"`          // Number of time window and run count conditions
+	ForceUnlock        bool   `json:"force_unlock"`        // Workdir locks may be bypassed with force
+}
+
+// ToolGroupInfo describes a configured tool group.
+type ToolGroupInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Tools       []string `json:"tools"`
+	Selected    bool     `json:"selected"` // The group's tools are listed in this session
+}
+
+// ToolSearchResult lists the tools matching a search, best first.
+type ToolSearchResult struct {
+	Query      string      `json:"query"`
+	Tools      []ToolMatch `json:"tools"`
+	TotalFound int         `json:"total_found"`
+}
+
+// ToolMatch is a tool matching a search.
+type ToolMatch struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Groups      []string `json:"groups,omitempty"`
+	Hidden      bool     `json:"hidden,omitempty"` // None of its groups is selected in this session
+	Score       int      `json:"score"`
+}
+
+// ToolGroupList lists the tool groups and those selected in a session.
+type ToolGroupList struct {
+	Groups   []ToolGroupInfo `json:"groups"`
+	Selected []string        `json:"selected"`
 }
 
 // CommandDiscoveryRequest represents a request to discover commands.
@@ -37,13 +396,87 @@ type CommandDiscoveryRequest struct {
 	Pattern     string   `json:"pattern,omitempty"`
 	Paths       []string `json:"paths,omitempty"`        // Additional paths to search
 	MaxResults  int      `json:"max_results,omitempty"`  // Limit number of results
-	IncludeDesc bool     `json:"include_desc,omitempty"` // Include descriptions
+	IncludeDesc bool     `json:"include_desc,omitempty"` // Include descriptions, usage examples and common flags
+
+	// Offset skips this many results; Cursor continues after the page it
+	// was returned with instead, unaffected by commands added or removed
+	// since
+	Offset int    `json:"offset,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // CommandDiscoveryResult represents the result of command discovery.
+// Commands are sorted by relevance: exact matches of the pattern, then
+// common commands, then by name; the order is stable across calls.
 type CommandDiscoveryResult struct {
 	Commands    []CommandInfo `json:"commands"`
 	TotalFound  int           `json:"total_found"`
-	Truncated   bool          `json:"truncated"`
+	Offset      int           `json:"offset"`                // Position of the first command in all results
+	Truncated   bool          `json:"truncated"`             // More results follow this page
+	NextCursor  string        `json:"next_cursor,omitempty"` // Cursor of the next page
 	SearchPaths []string      `json:"search_paths"`
 }
+
+// BatchStep represents a single command within a batch execution request.
+type BatchStep struct {
+	ID        string   `json:"id"`
+	Command   string   `json:"command"`
+	Args      []string `json:"args,omitempty"`
+	WorkDir   string   `json:"workdir,omitempty"`
+	Env       []string `json:"env,omitempty"`
+	Timeout   string   `json:"timeout,omitempty"`
+	DependsOn []string `json:"depends_on,omitempty"` // IDs of steps that must succeed first
+
+	// Capture extracts named values from this step's stdout. Each expression
+	// is either a regular expression (the first submatch, or the whole match
+	// when there are no groups) or a JSON path starting with "$" such as
+	// "$.items[0].name". Later steps reference values as {{step_id.name}}
+	// in their args and workdir.
+	Capture map[string]string `json:"capture,omitempty"`
+}
+
+// BatchExecutionRequest represents a request to execute several commands.
+// Steps without dependencies run in parallel; dependent steps wait for the
+// steps they reference.
+type BatchExecutionRequest struct {
+	Steps []BatchStep `json:"steps"`
+}
+
+// Batch step statuses.
+const (
+	BatchStepSucceeded = "succeeded"
+	BatchStepFailed    = "failed"
+	BatchStepSkipped   = "skipped"
+)
+
+// BatchStepResult represents the outcome of a single batch step.
+type BatchStepResult struct {
+	ID        string                  `json:"id"`
+	DependsOn []string                `json:"depends_on,omitempty"`
+	Level     int                     `json:"level"` // Depth in the dependency graph
+	Status    string                  `json:"status"`
+	Result    *CommandExecutionResult `json:"result,omitempty"`
+	Captures  map[string]string       `json:"captures,omitempty"`
+	Error     string                  `json:"error,omitempty"`
+	Denied    bool                    `json:"denied,omitempty"` // The security policy refused the step
+	Err       error                   `json:"-"`                // The error of a step that failed to run
+}
+
+// BatchExecutionResult represents the result of a batch execution.
+type BatchExecutionResult struct {
+	Steps     []BatchStepResult `json:"steps"`
+	Levels    [][]string        `json:"levels"` // Step IDs grouped by dependency depth
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Skipped   int               `json:"skipped"`
+	Duration  time.Duration     `json:"duration_ms"`
+}
+
+// PreflightFailure is the pre-flight check that kept a configured command
+// from starting.
+type PreflightFailure struct {
+	Check     string `json:"check"` // disk_space or binary
+	Path      string `json:"path"`  // Directory whose filesystem was checked, or the command not found
+	Available int64  `json:"available_bytes,omitempty"`
+	Required  int64  `json:"required_bytes,omitempty"`
+}